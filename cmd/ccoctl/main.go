@@ -2,18 +2,44 @@ package main
 
 import (
 	"log"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/alibabacloud"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/aws"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/external"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/gcp"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/ibmcloud"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/multicloud"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/nutanix"
 )
 
 func main() {
+	var otlpEndpoint string
+	var noColor bool
+	var debug bool
+	var output string
+	var userAgentSuffix string
+	var dumpConfig bool
+	var timeout time.Duration
+	var configFile string
+	var maxRetries int
+	var retryBaseDelay time.Duration
+	var retryMaxDelay time.Duration
+	var disableRetries bool
+	var maxConcurrentClouds int
+	var signingKeySize int
+	var jwksPEMBundle bool
+	var dumpCloudRequests string
+	var auditLog string
+	var strict bool
+	var nameTemplates []string
+	var descriptionTemplate string
+
 	rootCmd := &cobra.Command{
 		Use:   "ccoctl",
 		Short: "OpenShift credentials provisioning tool",
@@ -25,8 +51,103 @@ func main() {
 	rootCmd.AddCommand(alibabacloud.NewAliababaCloudCmd())
 	rootCmd.AddCommand(nutanix.NewNutanixCmd())
 	rootCmd.AddCommand(azure.NewAzureCmd())
+	rootCmd.AddCommand(multicloud.NewMultiCloudCmd())
+	rootCmd.AddCommand(external.NewExternalCmd())
+	rootCmd.AddCommand(provisioning.NewInventoryCmd())
+
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "Export OpenTelemetry traces of the operations performed by ccoctl via OTLP over HTTP to this endpoint, either a host:port pair to which traces are sent over HTTPS or an http:// or https:// URL. Tracing is disabled when not specified")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored warnings and errors in logs. Logs are only colored when written to a terminal and the NO_COLOR environment variable is not set")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Log debug messages, eg. the API versions requested of Azure resource providers")
+	rootCmd.PersistentFlags().StringVar(&output, "output", provisioning.OutputText, "Output format, either \"text\", \"json-stream\" or \"yaml\". With json-stream a JSON object is written to stdout on its own line as each resource is created, updated or deleted, followed by a final summary object. With yaml the fields to set within install-config.yaml are written to stdout once create-all has succeeded. Logs are always written to stderr")
+	rootCmd.PersistentFlags().StringVar(&userAgentSuffix, "user-agent-suffix", "", "Suffix appended to the ccoctl/<version> User-Agent with which AWS, Azure and GCP API requests are made, eg. to identify the pipeline running ccoctl in cloud audit logs")
+	rootCmd.PersistentFlags().BoolVar(&dumpConfig, "dump-config", false, "Write the effective configuration of the command as JSON to stderr before doing any work: the value of every flag, defaults included, and the values resolved by create-all, such as the cloud identity and the names of the resources derived from --name. Sensitive values are redacted")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Bound the entire run of the command, eg. 30m. Once exceeded in-flight cloud API calls are abandoned, the phase which was active and the steps completed beforehand are logged, and ccoctl exits in failure. The inventory within the output directory records the steps completed before the timeout. The run is not bounded when not specified")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "YAML file providing the values of flags not specified on the command line. Keys are either flags or the names of subcommands whose section holds the flags of the subcommand, eg. the flags of \"ccoctl aws create-all\" within the create-all section of the aws section. Unknown keys are rejected. "+
+		"Every flag may also be provided by the environment variable CCOCTL_<FLAG>, eg. CCOCTL_CREDENTIALS_REQUESTS_DIR, the command line taking precedence over the environment and the environment over this file")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", -1, "Maximum number of times a cloud API call which failed transiently, eg. as it was throttled, is retried. Defaults to the retry policy of the cloud provider when negative")
+	rootCmd.PersistentFlags().DurationVar(&retryBaseDelay, "retry-base-delay", 0, "Delay before the first retry of a cloud API call, eg. 1s, doubling with every retry. Defaults to the retry policy of the cloud provider when not specified")
+	rootCmd.PersistentFlags().DurationVar(&retryMaxDelay, "retry-max-delay", 0, "Maximum delay before a retry of a cloud API call, eg. 30s. Defaults to the retry policy of the cloud provider when not specified")
+	rootCmd.PersistentFlags().BoolVar(&disableRetries, "disable-retries", false, "Never retry failed cloud API calls, eg. to debug the first failure of a call")
+	rootCmd.PersistentFlags().IntVar(&maxConcurrentClouds, "max-concurrent-clouds", provisioning.DefaultMaxConcurrentClouds, "Maximum number of cloud scopes processed in parallel, ie. the cloud providers of multicloud create-all and the subscriptions, projects or accounts of delete, so that a single run does not overwhelm a shared network or hit rate limits across accounts. The parallelism within each scope, eg. tagging resources, is bounded separately")
+	rootCmd.PersistentFlags().IntVar(&signingKeySize, "signing-key-size", 0, "Require the service account signing keys generated or provided to be RSA keys of this size in bits, either 2048, 3072 or 4096, eg. for compliance. When not specified, generated keys are 4096 bits and provided keys must be RSA keys of at least 2048 bits")
+	rootCmd.PersistentFlags().BoolVar(&jwksPEMBundle, "jwks-pem-bundle", false, "Also write the public keys of the JSON web key set (JWKS) published by create as a PEM bundle, keys.pem, within the output directory, eg. for consumers of service account tokens which do not read JWKS. The bundle holds exactly the keys of the JWKS, in the same order")
+	rootCmd.PersistentFlags().StringVar(&dumpCloudRequests, "dump-cloud-requests", "", "Write every AWS, Azure and GCP API request and its response, ie. their method, URL, status, headers and body, to this file as JSON Lines, eg. to attach to a support case. Credentials, tokens and the values of sensitive headers are redacted. Requests are not dumped when not specified")
+	rootCmd.PersistentFlags().StringVar(&auditLog, "audit-log", "", "Append a JSON record of every resource created, updated or deleted to this file, ie. the time, the operation, the resource, its result and the cloud identity performing it, followed by a record of the outcome of the command. "+
+		"Records are synced to disk as they are written so that the file remains usable when ccoctl is interrupted. Nothing is recorded when not specified")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Fail once the command has completed when any warning was logged, eg. about deprecated permissions or skipped checks, so that pipelines may enforce clean runs. Missing permissions to delete resources fail before anything is deleted")
+	rootCmd.PersistentFlags().StringArrayVar(&nameTemplates, "name-template", nil, "Go template of the names of the resources of a type created for the CredentialsRequests, as <resource type>=<template>, eg. "+
+		"aws-iam-role={{.ClusterName}}-{{.Region}}-{{.Component}}. May be repeated once for each resource type, either aws-iam-role or azure-managed-identity. "+
+		"The variables are .ClusterName, the --name, .Component, the <secret namespace>-<secret name> of the CredentialsRequest, .Namespace, .SecretName and .Region. "+
+		"Names too long for the cloud provider, and the names of templates which do not distinguish components, are suffixed with a hash. Resources are named after --name when not specified")
+	rootCmd.PersistentFlags().StringVar(&descriptionTemplate, "resource-description-template", "", "Go template of the description of the IAM Roles and of the GCP IAM service accounts and custom roles created for the CredentialsRequests, "+
+		"eg. \"{{.Component}} credentials of cluster {{.ClusterName}}\", with the variables of --name-template. Descriptions exceeding the limits of the cloud provider fail before any resource is created. "+
+		"Descriptions are informational only and are never used to find the resources to delete. Defaults to the description of ccoctl when not specified")
+	cobra.OnInitialize(func() {
+		cmd, _, err := rootCmd.Find(os.Args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		// The command line takes precedence over the environment, which takes precedence over the configuration file
+		if err := provisioning.ApplyEnvironment(cmd); err != nil {
+			log.Fatal(err)
+		}
+		if configFile != "" {
+			if err := provisioning.ApplyConfigFile(cmd, configFile); err != nil {
+				log.Fatal(err)
+			}
+		}
+		provisioning.InitLogging(noColor, debug)
+		provisioning.InitStrict(strict)
+		provisioning.InitDumpConfig(dumpConfig)
+		if err := provisioning.InitTimeout(timeout); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitOutput(output); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitNameTemplates(nameTemplates); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitDescriptionTemplate(descriptionTemplate); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitUserAgent(userAgentSuffix); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitRetryPolicy(maxRetries, retryBaseDelay, retryMaxDelay, disableRetries); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitSigningKeySize(signingKeySize); err != nil {
+			log.Fatal(err)
+		}
+		provisioning.InitJWKSPEMBundle(jwksPEMBundle)
+		if err := provisioning.InitMaxConcurrentClouds(maxConcurrentClouds); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitCloudRequestDump(dumpCloudRequests); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitAuditLog(auditLog, cmd.CommandPath()); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.InitTracing(otlpEndpoint); err != nil {
+			log.Fatal(err)
+		}
+	})
+	provisioning.TimeoutCommands(rootCmd)
+	provisioning.TraceCommands(rootCmd)
+	provisioning.DumpConfigCommands(rootCmd)
+	provisioning.NameTemplateCommands(rootCmd)
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if err == nil {
+		err = provisioning.StrictError()
+	}
+	provisioning.FinishOutput(err)
+	provisioning.FinishAuditLog(err)
+	provisioning.ShutdownTracing()
+	provisioning.CloseCloudRequestDump()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
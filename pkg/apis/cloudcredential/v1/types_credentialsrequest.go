@@ -37,6 +37,11 @@ const (
 	// AWS credentials to view the actual state.
 	AnnotationAWSPolicyLastApplied string = "cloudcredential.openshift.io/aws-policy-last-applied"
 
+	// AnnotationSecretTemplateKeys is added to target Secrets listing the comma-separated keys which were
+	// rendered from the CredentialsRequest's spec.secretTemplate, so that keys dropped from the template are
+	// removed from the Secret.
+	AnnotationSecretTemplateKeys string = "cloudcredential.openshift.io/secret-template-keys"
+
 	// CloudCredOperatorNamespace is the namespace where the credentials operator runs.
 	CloudCredOperatorNamespace = "openshift-cloud-credential-operator"
 )
@@ -68,6 +73,22 @@ type CredentialsRequestSpec struct {
 	// token based authentication methods such as with the AWS Secure Token Service (STS).
 	// +optional
 	CloudTokenPath string `json:"cloudTokenPath,omitempty"`
+
+	// secretTemplate renders additional keys into the secret referenced by secretRef from the credentials
+	// in the default format of the cloud provider, for components which consume credentials in another format.
+	// The secret only contains the default format when omitted.
+	// +optional
+	SecretTemplate *SecretTemplate `json:"secretTemplate,omitempty"`
+}
+
+// SecretTemplate controls how credentials are rendered into the secret referenced by a CredentialsRequest
+type SecretTemplate struct {
+	// data maps additional keys of the secret to Go text/template templates which are rendered with the
+	// data of the secret in the default format, eg. "{{ .aws_access_key_id }}" or
+	// '{{ index . "service_account.json" }}'. The keys of the default format may not be overridden,
+	// referring to a key missing from the default format is an error.
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
 }
 
 // CredentialsRequestStatus defines the observed state of CredentialsRequest
@@ -178,6 +199,15 @@ const (
 	// OrphanedCloudResource is true when CCO was unable to delete a previously created
 	// App Registration / Service Principal while pivoting from Mint mode to Passthrough
 	OrphanedCloudResource CredentialsRequestConditionType = "OrphanedCloudResource"
+	// Paused is true when credential minting has been paused by an admin on the operator config, the
+	// credentials are neither minted nor rotated until credential minting is unpaused
+	Paused CredentialsRequestConditionType = "Paused"
+	// Conflict is true when other CredentialsRequests target the same secret as the CredentialsRequest, the
+	// credentials of the conflicting CredentialsRequests are not minted until all but one target the secret
+	Conflict CredentialsRequestConditionType = "Conflict"
+	// DryRun is true when an admin has requested a dry run of the CredentialsRequest, the changes which syncing the
+	// CredentialsRequest would make are reported by the condition's message without being made
+	DryRun CredentialsRequestConditionType = "DryRun"
 )
 
 var (
@@ -188,5 +218,6 @@ var (
 		MissingTargetNamespace,
 		CredentialsProvisionFailure,
 		CredentialsDeprovisionFailure,
+		Conflict,
 	}
 )
@@ -150,6 +150,24 @@ spec:
                   uid:
                     description: 'UID of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#uids'
                     type: string
+              secretTemplate:
+                description: secretTemplate renders additional keys into the secret
+                  referenced by secretRef from the credentials in the default format
+                  of the cloud provider, for components which consume credentials
+                  in another format. The secret only contains the default format
+                  when omitted.
+                type: object
+                properties:
+                  data:
+                    description: 'data maps additional keys of the secret to Go text/template
+                      templates which are rendered with the data of the secret in the
+                      default format, eg. "{{ .aws_access_key_id }}" or ''{{ index .
+                      "service_account.json" }}''. The keys of the default format may
+                      not be overridden, referring to a key missing from the default
+                      format is an error.'
+                    type: object
+                    additionalProperties:
+                      type: string
               serviceAccountNames:
                 description: ServiceAccountNames contains a list of ServiceAccounts
                   that will use permissions associated with this CredentialsRequest.
@@ -72,6 +72,16 @@ type AWSActuator struct {
 	AWSClientBuilder                   func(accessKeyID, secretAccessKey []byte, c client.Client) (ccaws.Client, error)
 	Scheme                             *runtime.Scheme
 	AWSSecurityTokenServiceGateEnabled bool
+	// SecretSink stores the minted credentials, they are stored as Secrets in the cluster when nil.
+	SecretSink actuatoriface.SecretSink
+}
+
+// GetSecretSink returns the SecretSink through which credentials are stored.
+func (a *AWSActuator) GetSecretSink() actuatoriface.SecretSink {
+	if a.SecretSink == nil {
+		return actuatoriface.NewInClusterSecretSink(a.Client)
+	}
+	return a.SecretSink
 }
 
 // NewAWSActuator creates a new AWSActuator.
@@ -137,7 +147,7 @@ func (a *AWSActuator) Exists(ctx context.Context, cr *minterv1.CredentialsReques
 	}
 
 	existingSecret := &corev1.Secret{}
-	err = a.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, existingSecret)
+	err = a.GetSecretSink().Get(context.TODO(), cr, existingSecret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Debug("target secret does not exist")
@@ -165,7 +175,7 @@ func (a *AWSActuator) needsUpdate(ctx context.Context, cr *minterv1.CredentialsR
 	}
 
 	// Various checks for the kinds of reasons that would trigger a needed update
-	_, existingAccessKey, existingSecretKey, existingCredentialsKey := a.loadExistingSecret(cr)
+	existingSecret, existingAccessKey, existingSecretKey, existingCredentialsKey := a.loadExistingSecret(cr)
 	awsClient, err := a.AWSClientBuilder([]byte(existingAccessKey), []byte(existingSecretKey), a.Client)
 	if err != nil {
 		return true, err
@@ -177,6 +187,11 @@ func (a *AWSActuator) needsUpdate(ctx context.Context, cr *minterv1.CredentialsR
 		return true, nil
 	}
 
+	if actuatoriface.SecretTemplateOutdated(cr, existingSecret) {
+		logger.Info("Secret keys rendered from spec.secretTemplate need updating, will update Secret contents")
+		return true, nil
+	}
+
 	awsSpec, err := DecodeProviderSpec(a.Codec, cr)
 	if err != nil {
 		return true, err
@@ -362,7 +377,7 @@ func (a *AWSActuator) sync(ctx context.Context, cr *minterv1.CredentialsRequest)
 			cloudTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
 		}
 		if awsSTSIAMRoleARN != "" {
-			err = a.createSTSSecret(awsSTSIAMRoleARN, cloudTokenPath, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace, logger, ctx)
+			err = a.createSTSSecret(ctx, cr, awsSTSIAMRoleARN, cloudTokenPath, logger)
 			if err != nil {
 				return err
 			}
@@ -416,19 +431,20 @@ func (a *AWSActuator) sync(ctx context.Context, cr *minterv1.CredentialsRequest)
 // a path to the JWT token: spec.cloudTokenPath
 // a spec.SecretRef.Name
 // a cr.Spec.SecretRef.Namespace
-func (a *AWSActuator) createSTSSecret(awsSTSIAMRoleARN string, cloudTokenPath string, secretRef string, secretRefNamespace string, log log.FieldLogger, ctx context.Context) error {
+// The Secret is stored through the SecretSink like minted credentials.
+func (a *AWSActuator) createSTSSecret(ctx context.Context, cr *minterv1.CredentialsRequest, awsSTSIAMRoleARN string, cloudTokenPath string, log log.FieldLogger) error {
 	log.Infof("creating secret")
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretRef,
-			Namespace: secretRefNamespace,
+			Name:      cr.Spec.SecretRef.Name,
+			Namespace: cr.Spec.SecretRef.Namespace,
 		},
 		StringData: map[string]string{
 			"credentials": fmt.Sprintf(awsSTSCredsTemplate, awsSTSIAMRoleARN, cloudTokenPath),
 		},
 		Type: corev1.SecretTypeOpaque,
 	}
-	err := a.Client.Create(ctx, secret)
+	err := a.GetSecretSink().Create(ctx, cr, secret)
 	if err != nil {
 		log.Errorf("error creating secret")
 		return err
@@ -857,7 +873,7 @@ func (a *AWSActuator) loadExistingSecret(cr *minterv1.CredentialsRequest) (*core
 
 	// Check if the credentials secret exists, if not we need to inform the syncer to generate a new one:
 	existingSecret := &corev1.Secret{}
-	err := a.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, existingSecret)
+	err := a.GetSecretSink().Get(context.TODO(), cr, existingSecret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Debug("secret does not exist")
@@ -1026,8 +1042,12 @@ func (a *AWSActuator) syncAccessKeySecret(cr *minterv1.CredentialsRequest, acces
 				constants.AWSSecretDataCredentialsKey: generateAWSCredentialsConfig(accessKeyID, secretAccessKey),
 			},
 		}
+		if err := actuatoriface.ApplySecretTemplate(cr, secret); err != nil {
+			sLog.WithError(err).Error("error applying secret template")
+			return err
+		}
 
-		err := a.Client.Create(context.TODO(), secret)
+		err := a.GetSecretSink().Create(context.TODO(), cr, secret)
 		if err != nil {
 			sLog.WithError(err).Error("error creating secret")
 			return err
@@ -1051,10 +1071,14 @@ func (a *AWSActuator) syncAccessKeySecret(cr *minterv1.CredentialsRequest, acces
 
 	// Make sure credentials config data is synced with the stored access key / secret key
 	existingSecret.Data[constants.AWSSecretDataCredentialsKey] = generateAWSCredentialsConfig(string(existingSecret.Data[secretDataAccessKey]), string(existingSecret.Data[secretDataSecretKey]))
+	if err := actuatoriface.ApplySecretTemplate(cr, existingSecret); err != nil {
+		sLog.WithError(err).Error("error applying secret template")
+		return err
+	}
 
 	if !reflect.DeepEqual(existingSecret, origSecret) {
 		sLog.Info("target secret has changed, updating")
-		err := a.Client.Update(context.TODO(), existingSecret)
+		err := a.GetSecretSink().Update(context.TODO(), cr, existingSecret)
 		if err != nil {
 			msg := "error updating secret"
 			sLog.WithError(err).Error(msg)
@@ -31,6 +31,7 @@ import (
 	schemeutils "github.com/openshift/cloud-credential-operator/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -404,6 +405,9 @@ func TestSecretFormat(t *testing.T) {
 		accessKeyID     string
 		secretAccessKey string
 		existingSecret  *corev1.Secret
+		secretTemplate  *minterv1.SecretTemplate
+		// expectedTemplatedData are the keys expected to be rendered from secretTemplate
+		expectedTemplatedData map[string]string
 	}{
 		{
 			name:            "new secret with credentials field",
@@ -441,6 +445,47 @@ func TestSecretFormat(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:            "new secret rendered from secret template",
+			accessKeyID:     "AKFIRSTKEY",
+			secretAccessKey: "FIRSTSECRET",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"access-key.env": "AWS_ACCESS_KEY_ID={{ .aws_access_key_id }}\nAWS_SECRET_ACCESS_KEY={{ .aws_secret_access_key }}",
+				},
+			},
+			expectedTemplatedData: map[string]string{
+				"access-key.env": "AWS_ACCESS_KEY_ID=AKFIRSTKEY\nAWS_SECRET_ACCESS_KEY=FIRSTSECRET",
+			},
+		},
+		{
+			name:            "existing secret rendered from secret template",
+			accessKeyID:     "AKFIRSTKEY",
+			secretAccessKey: "FIRSTSECRET",
+			existingSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testTargetSecret,
+					Namespace: testTargetNamespace,
+					Annotations: map[string]string{
+						minterv1.AnnotationSecretTemplateKeys: "access-key-id,stale-key",
+					},
+				},
+				Data: map[string][]byte{
+					"aws_access_key_id":     []byte("SOMEACCESSKEY"),
+					"aws_secret_access_key": []byte("SOMESECRETKEY"),
+					"access-key-id":         []byte("SOMEACCESSKEY"),
+					"stale-key":             []byte("SOMEACCESSKEY"),
+				},
+			},
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"access-key-id": "{{ .aws_access_key_id }}",
+				},
+			},
+			expectedTemplatedData: map[string]string{
+				"access-key-id": "AKFIRSTKEY",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -457,6 +502,7 @@ func TestSecretFormat(t *testing.T) {
 			}
 
 			cr := testCredentialsRequest()
+			cr.Spec.SecretTemplate = test.secretTemplate
 			logger := a.getLogger(cr)
 			err := a.syncAccessKeySecret(cr, test.accessKeyID, test.secretAccessKey, test.existingSecret, "exampleAWSPolicy", logger)
 
@@ -477,6 +523,11 @@ func TestSecretFormat(t *testing.T) {
 			credentialsConfig := string(secret.Data["credentials"])
 			assert.Contains(t, credentialsConfig, fmt.Sprintf("aws_access_key_id = %s", test.accessKeyID))
 			assert.Contains(t, credentialsConfig, fmt.Sprintf("aws_secret_access_key = %s", test.secretAccessKey))
+
+			assert.Len(t, secret.Data, 3+len(test.expectedTemplatedData), "unexpected keys within Secret")
+			for key, value := range test.expectedTemplatedData {
+				assert.Equal(t, value, string(secret.Data[key]), "unexpected value of key %s rendered from secret template", key)
+			}
 		})
 	}
 }
@@ -731,3 +782,78 @@ func testAuthentication(issuer string) *configv1.Authentication {
 	}
 	return conf
 }
+
+// fakeSecretSink stores credentials secrets in memory, keyed by the namespace and name of their CredentialsRequest
+type fakeSecretSink struct {
+	secrets map[types.NamespacedName]*corev1.Secret
+	creates int
+	updates int
+}
+
+func (s *fakeSecretSink) Get(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	stored, ok := s.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}]
+	if !ok {
+		return apierrors.NewNotFound(corev1.Resource("secrets"), cr.Spec.SecretRef.Name)
+	}
+	stored.DeepCopyInto(secret)
+	return nil
+}
+
+func (s *fakeSecretSink) Create(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.creates++
+	s.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}] = secret.DeepCopy()
+	return nil
+}
+
+func (s *fakeSecretSink) Update(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.updates++
+	s.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}] = secret.DeepCopy()
+	return nil
+}
+
+func (s *fakeSecretSink) Delete(ctx context.Context, cr *minterv1.CredentialsRequest) error {
+	delete(s.secrets, types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name})
+	return nil
+}
+
+func TestSecretSink(t *testing.T) {
+	apis.AddToScheme(scheme.Scheme)
+
+	fakeClient := fake.NewClientBuilder().Build()
+	sink := &fakeSecretSink{secrets: map[types.NamespacedName]*corev1.Secret{}}
+	a := &AWSActuator{
+		Client:     fakeClient,
+		SecretSink: sink,
+	}
+	codec, err := minterv1.NewCodec()
+	require.NoError(t, err, "failed to create codec")
+	cr := testCredentialsRequest()
+	cr.Spec.ProviderSpec, err = testAWSProviderConfig(codec, "")
+	require.NoError(t, err, "failed to create provider spec")
+	logger := a.getLogger(cr)
+
+	exists, err := a.Exists(context.TODO(), cr)
+	require.NoError(t, err, "unexpected error checking whether credentials exist")
+	assert.False(t, exists, "expected credentials not to exist before being minted")
+
+	err = a.syncAccessKeySecret(cr, "AKFIRSTKEY", "FIRSTSECRET", nil, "exampleAWSPolicy", logger)
+	require.NoError(t, err, "unexpected error creating credentials")
+	assert.Equal(t, 1, sink.creates, "expected credentials to be created in the sink")
+
+	existingSecret, accessKeyID, secretAccessKey, _ := a.loadExistingSecret(cr)
+	assert.Equal(t, "AKFIRSTKEY", accessKeyID, "unexpected access key ID read from the sink")
+	assert.Equal(t, "FIRSTSECRET", secretAccessKey, "unexpected secret access key read from the sink")
+
+	err = a.syncAccessKeySecret(cr, "AKSECONDKEY", "SECONDSECRET", existingSecret, "exampleAWSPolicy", logger)
+	require.NoError(t, err, "unexpected error updating credentials")
+	assert.Equal(t, 1, sink.updates, "expected credentials to be updated in the sink")
+	stored := sink.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}]
+	assert.Equal(t, "AKSECONDKEY", string(stored.Data["aws_access_key_id"]), "unexpected access key ID stored in the sink")
+
+	exists, err = a.Exists(context.TODO(), cr)
+	require.NoError(t, err, "unexpected error checking whether credentials exist")
+	assert.True(t, exists, "expected credentials stored in the sink to exist")
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err), "expected no secret to be written to the cluster")
+}
@@ -38,6 +38,7 @@ import (
 // Client is a wrapper object for actual AWS SDK clients to allow for easier testing.
 type Client interface {
 	//IAM
+	AddClientIDToOpenIDConnectProvider(*iam.AddClientIDToOpenIDConnectProviderInput) (*iam.AddClientIDToOpenIDConnectProviderOutput, error)
 	CreateAccessKey(*iam.CreateAccessKeyInput) (*iam.CreateAccessKeyOutput, error)
 	CreateOpenIDConnectProvider(*iam.CreateOpenIDConnectProviderInput) (*iam.CreateOpenIDConnectProviderOutput, error)
 	CreateRole(*iam.CreateRoleInput) (*iam.CreateRoleOutput, error)
@@ -45,12 +46,22 @@ type Client interface {
 	DeleteAccessKey(*iam.DeleteAccessKeyInput) (*iam.DeleteAccessKeyOutput, error)
 	DeleteUser(*iam.DeleteUserInput) (*iam.DeleteUserOutput, error)
 	DeleteUserPolicy(*iam.DeleteUserPolicyInput) (*iam.DeleteUserPolicyOutput, error)
+	GetAccountSummary(*iam.GetAccountSummaryInput) (*iam.GetAccountSummaryOutput, error)
 	GetOpenIDConnectProvider(input *iam.GetOpenIDConnectProviderInput) (*iam.GetOpenIDConnectProviderOutput, error)
 	GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error)
 	ListRoles(input *iam.ListRolesInput) (*iam.ListRolesOutput, error)
 	DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleOutput, error)
 	ListRolePolicies(input *iam.ListRolePoliciesInput) (*iam.ListRolePoliciesOutput, error)
 	DeleteRolePolicy(input *iam.DeleteRolePolicyInput) (*iam.DeleteRolePolicyOutput, error)
+	AttachRolePolicy(input *iam.AttachRolePolicyInput) (*iam.AttachRolePolicyOutput, error)
+	CreatePolicy(input *iam.CreatePolicyInput) (*iam.CreatePolicyOutput, error)
+	CreatePolicyVersion(input *iam.CreatePolicyVersionInput) (*iam.CreatePolicyVersionOutput, error)
+	DeletePolicy(input *iam.DeletePolicyInput) (*iam.DeletePolicyOutput, error)
+	DeletePolicyVersion(input *iam.DeletePolicyVersionInput) (*iam.DeletePolicyVersionOutput, error)
+	DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.DetachRolePolicyOutput, error)
+	GetPolicy(input *iam.GetPolicyInput) (*iam.GetPolicyOutput, error)
+	ListAttachedRolePolicies(input *iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error)
+	ListPolicyVersions(input *iam.ListPolicyVersionsInput) (*iam.ListPolicyVersionsOutput, error)
 	GetUser(*iam.GetUserInput) (*iam.GetUserOutput, error)
 	GetUserPolicy(*iam.GetUserPolicyInput) (*iam.GetUserPolicyOutput, error)
 	ListAccessKeys(*iam.ListAccessKeysInput) (*iam.ListAccessKeysOutput, error)
@@ -63,7 +74,9 @@ type Client interface {
 	SimulatePrincipalPolicyPages(*iam.SimulatePrincipalPolicyInput, func(*iam.SimulatePolicyResponse, bool) bool) error
 	TagOpenIDConnectProvider(*iam.TagOpenIDConnectProviderInput) (*iam.TagOpenIDConnectProviderOutput, error)
 	TagUser(*iam.TagUserInput) (*iam.TagUserOutput, error)
+	UntagOpenIDConnectProvider(*iam.UntagOpenIDConnectProviderInput) (*iam.UntagOpenIDConnectProviderOutput, error)
 	UpdateAssumeRolePolicy(*iam.UpdateAssumeRolePolicyInput) (*iam.UpdateAssumeRolePolicyOutput, error)
+	UpdateRole(*iam.UpdateRoleInput) (*iam.UpdateRoleOutput, error)
 
 	//S3
 	CreateBucket(*s3.CreateBucketInput) (*s3.CreateBucketOutput, error)
@@ -128,6 +141,10 @@ func (c *awsClient) DeleteUser(input *iam.DeleteUserInput) (*iam.DeleteUserOutpu
 func (c *awsClient) DeleteUserPolicy(input *iam.DeleteUserPolicyInput) (*iam.DeleteUserPolicyOutput, error) {
 	return c.iamClient.DeleteUserPolicy(input)
 }
+func (c *awsClient) GetAccountSummary(input *iam.GetAccountSummaryInput) (*iam.GetAccountSummaryOutput, error) {
+	return c.iamClient.GetAccountSummary(input)
+}
+
 func (c *awsClient) GetUser(input *iam.GetUserInput) (*iam.GetUserOutput, error) {
 	return c.iamClient.GetUser(input)
 }
@@ -180,10 +197,22 @@ func (c *awsClient) TagOpenIDConnectProvider(input *iam.TagOpenIDConnectProvider
 	return c.iamClient.TagOpenIDConnectProvider(input)
 }
 
+func (c *awsClient) UntagOpenIDConnectProvider(input *iam.UntagOpenIDConnectProviderInput) (*iam.UntagOpenIDConnectProviderOutput, error) {
+	return c.iamClient.UntagOpenIDConnectProvider(input)
+}
+
+func (c *awsClient) AddClientIDToOpenIDConnectProvider(input *iam.AddClientIDToOpenIDConnectProviderInput) (*iam.AddClientIDToOpenIDConnectProviderOutput, error) {
+	return c.iamClient.AddClientIDToOpenIDConnectProvider(input)
+}
+
 func (c *awsClient) UpdateAssumeRolePolicy(input *iam.UpdateAssumeRolePolicyInput) (*iam.UpdateAssumeRolePolicyOutput, error) {
 	return c.iamClient.UpdateAssumeRolePolicy(input)
 }
 
+func (c *awsClient) UpdateRole(input *iam.UpdateRoleInput) (*iam.UpdateRoleOutput, error) {
+	return c.iamClient.UpdateRole(input)
+}
+
 func (c *awsClient) GetOpenIDConnectProvider(input *iam.GetOpenIDConnectProviderInput) (*iam.GetOpenIDConnectProviderOutput, error) {
 	return c.iamClient.GetOpenIDConnectProvider(input)
 }
@@ -208,6 +237,42 @@ func (c *awsClient) DeleteRolePolicy(input *iam.DeleteRolePolicyInput) (*iam.Del
 	return c.iamClient.DeleteRolePolicy(input)
 }
 
+func (c *awsClient) AttachRolePolicy(input *iam.AttachRolePolicyInput) (*iam.AttachRolePolicyOutput, error) {
+	return c.iamClient.AttachRolePolicy(input)
+}
+
+func (c *awsClient) CreatePolicy(input *iam.CreatePolicyInput) (*iam.CreatePolicyOutput, error) {
+	return c.iamClient.CreatePolicy(input)
+}
+
+func (c *awsClient) CreatePolicyVersion(input *iam.CreatePolicyVersionInput) (*iam.CreatePolicyVersionOutput, error) {
+	return c.iamClient.CreatePolicyVersion(input)
+}
+
+func (c *awsClient) DeletePolicy(input *iam.DeletePolicyInput) (*iam.DeletePolicyOutput, error) {
+	return c.iamClient.DeletePolicy(input)
+}
+
+func (c *awsClient) DeletePolicyVersion(input *iam.DeletePolicyVersionInput) (*iam.DeletePolicyVersionOutput, error) {
+	return c.iamClient.DeletePolicyVersion(input)
+}
+
+func (c *awsClient) DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.DetachRolePolicyOutput, error) {
+	return c.iamClient.DetachRolePolicy(input)
+}
+
+func (c *awsClient) GetPolicy(input *iam.GetPolicyInput) (*iam.GetPolicyOutput, error) {
+	return c.iamClient.GetPolicy(input)
+}
+
+func (c *awsClient) ListAttachedRolePolicies(input *iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error) {
+	return c.iamClient.ListAttachedRolePolicies(input)
+}
+
+func (c *awsClient) ListPolicyVersions(input *iam.ListPolicyVersionsInput) (*iam.ListPolicyVersionsOutput, error) {
+	return c.iamClient.ListPolicyVersions(input)
+}
+
 func (c *awsClient) CreateBucket(input *s3.CreateBucketInput) (*s3.CreateBucketOutput, error) {
 	return c.s3Client.CreateBucket(input)
 }
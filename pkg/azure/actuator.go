@@ -47,6 +47,16 @@ type Actuator struct {
 	client                  *clientWrapper
 	codec                   *minterv1.ProviderCodec
 	credentialMinterBuilder credentialMinterBuilder
+	// SecretSink stores the credentials, they are stored as Secrets in the cluster when nil.
+	SecretSink actuatoriface.SecretSink
+}
+
+// GetSecretSink returns the SecretSink through which credentials are stored.
+func (a *Actuator) GetSecretSink() actuatoriface.SecretSink {
+	if a.SecretSink == nil {
+		return actuatoriface.NewInClusterSecretSink(a.client.Client)
+	}
+	return a.SecretSink
 }
 
 func (a *Actuator) STSFeatureGateEnabled() bool {
@@ -138,9 +148,8 @@ func (a *Actuator) needsUpdate(ctx context.Context, cr *minterv1.CredentialsRequ
 
 	// If the target Secret data doesn't match the cloud credentials secret (we haven't yet pivoted to passthrough from mint)
 	// then we need an update
-	targetSecretKey := types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}
 	targetSecret := &corev1.Secret{}
-	if err := a.client.Get(ctx, targetSecretKey, targetSecret); err != nil {
+	if err := a.GetSecretSink().Get(ctx, cr, targetSecret); err != nil {
 		logger.WithError(err).Error("failed to fetch target secret")
 		return true, err
 	}
@@ -150,6 +159,11 @@ func (a *Actuator) needsUpdate(ctx context.Context, cr *minterv1.CredentialsRequ
 		return true, nil
 	}
 
+	if actuatoriface.SecretTemplateOutdated(cr, targetSecret) {
+		logger.Debug("secret keys rendered from spec.secretTemplate need updating, update is needed")
+		return true, nil
+	}
+
 	// If we still have lingering App Registration info, we should try to clean it up if possible
 	azureStatus, err := decodeProviderStatus(a.codec, cr)
 	if err != nil {
@@ -406,20 +420,25 @@ func (a *Actuator) cleanupAfterPassthroughPivot(ctx context.Context, cr *minterv
 }
 func (a *Actuator) syncCredentialSecrets(ctx context.Context, cr *minterv1.CredentialsRequest, cloudCredsSecret *corev1.Secret, logger log.FieldLogger) error {
 	existing := &corev1.Secret{}
-	key := client.ObjectKey{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}
-	err := a.client.Get(ctx, key, existing)
+	err := a.GetSecretSink().Get(ctx, cr, existing)
 	if err != nil && kerrors.IsNotFound(err) {
 		s := &corev1.Secret{}
 		copyCredentialsSecret(cr, cloudCredsSecret, s)
-		return a.client.Create(ctx, s)
+		if err := actuatoriface.ApplySecretTemplate(cr, s); err != nil {
+			return err
+		}
+		return a.GetSecretSink().Create(ctx, cr, s)
 	} else if err != nil {
 		return err
 	}
 
 	updated := existing.DeepCopy()
 	copyCredentialsSecret(cr, cloudCredsSecret, updated)
+	if err := actuatoriface.ApplySecretTemplate(cr, updated); err != nil {
+		return err
+	}
 	if !reflect.DeepEqual(existing, updated) {
-		err := a.client.Update(ctx, updated)
+		err := a.GetSecretSink().Update(ctx, cr, updated)
 		if err != nil {
 			return &actuatoriface.ActuatorError{
 				ErrReason: minterv1.CredentialsProvisionFailure,
@@ -485,7 +504,7 @@ func (a *Actuator) Exists(ctx context.Context, cr *minterv1.CredentialsRequest)
 	}
 
 	existingSecret := &corev1.Secret{}
-	err := a.client.Get(ctx, types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, existingSecret)
+	err := a.GetSecretSink().Get(ctx, cr, existingSecret)
 	if err != nil {
 		if kerrors.IsNotFound(err) {
 			return false, nil
@@ -0,0 +1,86 @@
+package azure
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+)
+
+const (
+	// ResourcesNamespace is the namespace of the resource provider managing resource groups
+	ResourcesNamespace = "Microsoft.Resources"
+	// StorageNamespace is the namespace of the resource provider managing storage accounts and blob containers
+	StorageNamespace = "Microsoft.Storage"
+	// ManagedIdentityNamespace is the namespace of the resource provider managing user-assigned managed identities
+	// and their federated identity credentials
+	ManagedIdentityNamespace = "Microsoft.ManagedIdentity"
+	// AuthorizationNamespace is the namespace of the resource provider managing role definitions and role assignments
+	AuthorizationNamespace = "Microsoft.Authorization"
+)
+
+// APIVersionNamespaces are the namespaces of the resource providers called by an AzureClientWrapper whose API
+// versions may be pinned
+var APIVersionNamespaces = []string{ResourcesNamespace, StorageNamespace, ManagedIdentityNamespace, AuthorizationNamespace}
+
+// apiVersionFormat matches the API versions of resource providers, eg. 2023-01-01 or 2023-01-01-preview
+var apiVersionFormat = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(-preview)?$`)
+
+// APIVersions pins the API versions requested of resource providers, keyed by the namespace of the resource provider
+// such as "Microsoft.Storage". Namespaces are matched case-insensitively.
+type APIVersions map[string]string
+
+// Validate ensures that every pinned namespace is one of APIVersionNamespaces and that every API version is well formed
+func (v APIVersions) Validate() error {
+	namespaces := make([]string, 0, len(v))
+	for namespace := range v {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		if v.canonicalNamespace(namespace) == "" {
+			return fmt.Errorf("unsupported resource provider namespace %q for API version pinning, expected one of %s", namespace, strings.Join(APIVersionNamespaces, ", "))
+		}
+		if !apiVersionFormat.MatchString(v[namespace]) {
+			return fmt.Errorf("invalid API version %q for %s, expected an API version of the form YYYY-MM-DD or YYYY-MM-DD-preview", v[namespace], namespace)
+		}
+	}
+	return nil
+}
+
+// Get returns the API version pinned for the resource provider namespace, if any
+func (v APIVersions) Get(namespace string) (string, bool) {
+	for pinnedNamespace, version := range v {
+		if strings.EqualFold(pinnedNamespace, namespace) {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// canonicalNamespace returns the namespace within APIVersionNamespaces matching namespace, if any
+func (v APIVersions) canonicalNamespace(namespace string) string {
+	for _, supportedNamespace := range APIVersionNamespaces {
+		if strings.EqualFold(supportedNamespace, namespace) {
+			return supportedNamespace
+		}
+	}
+	return ""
+}
+
+// clientOptions returns options for a client of the resource provider namespace requesting the API version pinned
+// for namespace, or options themselves when no API version is pinned for namespace
+func (v APIVersions) clientOptions(options *policy.ClientOptions, namespace string) *policy.ClientOptions {
+	version, ok := v.Get(namespace)
+	if !ok {
+		return options
+	}
+	pinnedOptions := options.Clone()
+	if pinnedOptions == nil {
+		pinnedOptions = &policy.ClientOptions{}
+	}
+	pinnedOptions.APIVersion = version
+	return pinnedOptions
+}
@@ -0,0 +1,116 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	azpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIVersionsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiVersions APIVersions
+		expectErr   bool
+	}{
+		{
+			name: "no pinned API versions",
+		},
+		{
+			name:        "pinned API versions",
+			apiVersions: APIVersions{StorageNamespace: "2022-09-01", "microsoft.managedidentity": "2023-01-31-preview"},
+		},
+		{
+			name:        "unsupported namespace",
+			apiVersions: APIVersions{"Microsoft.Compute": "2022-09-01"},
+			expectErr:   true,
+		},
+		{
+			name:        "malformed API version",
+			apiVersions: APIVersions{StorageNamespace: "latest"},
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.apiVersions.Validate()
+			if test.expectErr {
+				require.Error(t, err, "expected error validating API versions")
+			} else {
+				require.NoError(t, err, "unexpected error validating API versions")
+			}
+		})
+	}
+}
+
+func TestAPIVersionsClientOptions(t *testing.T) {
+	options := &policy.ClientOptions{DisableRPRegistration: true}
+	apiVersions := APIVersions{"microsoft.storage": "2022-09-01"}
+
+	pinnedOptions := apiVersions.clientOptions(options, StorageNamespace)
+	assert.Equal(t, "2022-09-01", pinnedOptions.APIVersion, "expected the pinned API version to be requested")
+	assert.True(t, pinnedOptions.DisableRPRegistration, "expected the other options to be kept")
+	assert.Empty(t, options.APIVersion, "expected the provided options not to be modified")
+
+	assert.Same(t, options, apiVersions.clientOptions(options, ResourcesNamespace), "expected the provided options for a namespace which isn't pinned")
+	assert.Equal(t, "2022-09-01", apiVersions.clientOptions(nil, StorageNamespace).APIVersion, "expected the pinned API version to be requested without options")
+}
+
+type staticTokenCredential struct{}
+
+func (staticTokenCredential) GetToken(ctx context.Context, options azpolicy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestNewAzureClientWrapperWithAPIVersions(t *testing.T) {
+	requestedAPIVersions := map[string]string{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedAPIVersions[r.URL.Path] = r.URL.Query().Get("api-version")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	options := &policy.ClientOptions{
+		ClientOptions: azpolicy.ClientOptions{
+			Cloud: cloud.Configuration{
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Endpoint: server.URL, Audience: server.URL},
+				},
+			},
+			Transport: server.Client(),
+		},
+		DisableRPRegistration: true,
+	}
+	wrapper, err := NewAzureClientWrapperWithAPIVersions("subscription", staticTokenCredential{}, options, APIVersions{ResourcesNamespace: "2020-01-01"}, false)
+	require.NoError(t, err, "unexpected error creating clients")
+
+	_, err = wrapper.ResourceGroupsClient.Get(context.Background(), "resourcegroup", &armresources.ResourceGroupsClientGetOptions{})
+	require.NoError(t, err, "unexpected error getting resource group")
+	_, err = wrapper.BlobContainerClient.Get(context.Background(), "resourcegroup", "account", "container", &armstorage.BlobContainersClientGetOptions{})
+	require.NoError(t, err, "unexpected error getting blob container")
+
+	require.Len(t, requestedAPIVersions, 2, "expected a request of each client")
+	for path, version := range requestedAPIVersions {
+		if path == "/subscriptions/subscription/resourcegroups/resourcegroup" {
+			assert.Equal(t, "2020-01-01", version, "expected the pinned API version to be requested of %s", path)
+		} else {
+			assert.NotEqual(t, "2020-01-01", version, "expected the default API version to be requested of %s", path)
+			assert.NotEmpty(t, version, "expected the default API version to be requested of %s", path)
+		}
+	}
+
+	_, err = NewAzureClientWrapperWithAPIVersions("subscription", staticTokenCredential{}, options, APIVersions{"Microsoft.Compute": "2020-01-01"}, false)
+	require.Error(t, err, "expected error pinning the API version of an unsupported namespace")
+}
@@ -138,6 +138,7 @@ func (accountsClient *accountsClient) Update(ctx context.Context, resourceGroupN
 type BlobContainersClient interface {
 	Get(ctx context.Context, resourceGroupName string, accountName string, containerName string, options *armstorage.BlobContainersClientGetOptions) (armstorage.BlobContainersClientGetResponse, error)
 	Create(ctx context.Context, resourceGroupName string, accountName string, containerName string, blobContainer armstorage.BlobContainer, options *armstorage.BlobContainersClientCreateOptions) (armstorage.BlobContainersClientCreateResponse, error)
+	Update(ctx context.Context, resourceGroupName string, accountName string, containerName string, blobContainer armstorage.BlobContainer, options *armstorage.BlobContainersClientUpdateOptions) (armstorage.BlobContainersClientUpdateResponse, error)
 }
 
 type blobContainersClient struct {
@@ -145,7 +146,7 @@ type blobContainersClient struct {
 }
 
 func NewBlobContainersClient(subscriptionID string, cred azcore.TokenCredential, options *policy.ClientOptions) (*blobContainersClient, error) {
-	client, err := armstorage.NewBlobContainersClient(subscriptionID, cred, nil)
+	client, err := armstorage.NewBlobContainersClient(subscriptionID, cred, options)
 	if err != nil {
 		return nil, err
 	}
@@ -160,6 +161,10 @@ func (blobContainersClient *blobContainersClient) Create(ctx context.Context, re
 	return blobContainersClient.client.Create(ctx, resourceGroupName, accountName, containerName, blobContainer, options)
 }
 
+func (blobContainersClient *blobContainersClient) Update(ctx context.Context, resourceGroupName string, accountName string, containerName string, blobContainer armstorage.BlobContainer, options *armstorage.BlobContainersClientUpdateOptions) (armstorage.BlobContainersClientUpdateResponse, error) {
+	return blobContainersClient.client.Update(ctx, resourceGroupName, accountName, containerName, blobContainer, options)
+}
+
 type AZBlobClient interface {
 	UploadBuffer(ctx context.Context, containerName string, blobName string, buffer []byte, o *blockblob.UploadBufferOptions) (blockblob.UploadBufferResponse, error)
 }
@@ -262,9 +267,31 @@ func (roleAssignmentsClient *roleAssignmentsClient) Delete(ctx context.Context,
 	return roleAssignmentsClient.client.Delete(ctx, scope, roleAssignmentName, options)
 }
 
+type PermissionsClient interface {
+	NewListForResourceGroupPager(resourceGroupName string, options *armauthorization.PermissionsClientListForResourceGroupOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceGroupResponse]
+}
+
+type permissionsClient struct {
+	client *armauthorization.PermissionsClient
+}
+
+func NewPermissionsClient(subscriptionID string, cred azcore.TokenCredential, options *policy.ClientOptions) (*permissionsClient, error) {
+	client, err := armauthorization.NewPermissionsClient(subscriptionID, cred, options)
+	if err != nil {
+		return nil, err
+	}
+	return &permissionsClient{client: client}, err
+}
+
+func (permissionsClient *permissionsClient) NewListForResourceGroupPager(resourceGroupName string, options *armauthorization.PermissionsClientListForResourceGroupOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceGroupResponse] {
+	return permissionsClient.client.NewListForResourceGroupPager(resourceGroupName, options)
+}
+
 type FederatedIdentityCredentialsClient interface {
 	CreateOrUpdate(ctx context.Context, resourceGroupName string, resourceName string, federatedIdentityCredentialResourceName string, parameters armmsi.FederatedIdentityCredential, options *armmsi.FederatedIdentityCredentialsClientCreateOrUpdateOptions) (armmsi.FederatedIdentityCredentialsClientCreateOrUpdateResponse, error)
 	Get(ctx context.Context, resourceGroupName string, resourceName string, federatedIdentityCredentialResourceName string, options *armmsi.FederatedIdentityCredentialsClientGetOptions) (armmsi.FederatedIdentityCredentialsClientGetResponse, error)
+	Delete(ctx context.Context, resourceGroupName string, resourceName string, federatedIdentityCredentialResourceName string, options *armmsi.FederatedIdentityCredentialsClientDeleteOptions) (armmsi.FederatedIdentityCredentialsClientDeleteResponse, error)
+	NewListPager(resourceGroupName string, resourceName string, options *armmsi.FederatedIdentityCredentialsClientListOptions) *runtime.Pager[armmsi.FederatedIdentityCredentialsClientListResponse]
 }
 
 type federatedIdentityCredentialsClient struct {
@@ -287,6 +314,14 @@ func (federatedIdentityCredentialsClient *federatedIdentityCredentialsClient) Ge
 	return federatedIdentityCredentialsClient.client.Get(ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, options)
 }
 
+func (federatedIdentityCredentialsClient *federatedIdentityCredentialsClient) Delete(ctx context.Context, resourceGroupName string, resourceName string, federatedIdentityCredentialResourceName string, options *armmsi.FederatedIdentityCredentialsClientDeleteOptions) (armmsi.FederatedIdentityCredentialsClientDeleteResponse, error) {
+	return federatedIdentityCredentialsClient.client.Delete(ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, options)
+}
+
+func (federatedIdentityCredentialsClient *federatedIdentityCredentialsClient) NewListPager(resourceGroupName string, resourceName string, options *armmsi.FederatedIdentityCredentialsClientListOptions) *runtime.Pager[armmsi.FederatedIdentityCredentialsClientListResponse] {
+	return federatedIdentityCredentialsClient.client.NewListPager(resourceGroupName, resourceName, options)
+}
+
 type AzureClientWrapper struct {
 	cred                               azcore.TokenCredential
 	ResourceGroupsClient               ResourceGroupsClient
@@ -296,6 +331,7 @@ type AzureClientWrapper struct {
 	UserAssignedIdentitiesClient       UserAssignedIdentitiesClient
 	RoleDefinitionsClient              RoleDefinitionsClient
 	RoleAssignmentClient               RoleAssignmentsClient
+	PermissionsClient                  PermissionsClient
 	FederatedIdentityCredentialsClient FederatedIdentityCredentialsClient
 	// Mock field is used to create a PollerWrapper to facilitate testing
 	// Azure client operations that return a runtime.Poller
@@ -307,47 +343,63 @@ type AzureClientWrapper struct {
 }
 
 func NewAzureClientWrapper(subscriptionID string, cred azcore.TokenCredential, options *policy.ClientOptions, mock bool) (*AzureClientWrapper, error) {
+	return NewAzureClientWrapperWithAPIVersions(subscriptionID, cred, options, nil, mock)
+}
+
+// NewAzureClientWrapperWithAPIVersions returns an AzureClientWrapper whose clients request the API versions pinned by
+// apiVersions of the resource providers they call, the SDK's default API version is requested of the resource
+// providers which are not pinned.
+func NewAzureClientWrapperWithAPIVersions(subscriptionID string, cred azcore.TokenCredential, options *policy.ClientOptions, apiVersions APIVersions, mock bool) (*AzureClientWrapper, error) {
+	if err := apiVersions.Validate(); err != nil {
+		return nil, err
+	}
 	wrapper := &AzureClientWrapper{
 		cred: cred,
 	}
 
-	resourceGroupClient, err := NewResourceGroupsClient(subscriptionID, cred, options)
+	resourceGroupClient, err := NewResourceGroupsClient(subscriptionID, cred, apiVersions.clientOptions(options, ResourcesNamespace))
 	if err != nil {
 		return nil, err
 	}
 	wrapper.ResourceGroupsClient = resourceGroupClient.client
 
-	storageAccountClient, err := NewAccountsClient(subscriptionID, cred, options)
+	storageAccountClient, err := NewAccountsClient(subscriptionID, cred, apiVersions.clientOptions(options, StorageNamespace))
 	if err != nil {
 		return nil, err
 	}
 	wrapper.StorageAccountClient = storageAccountClient.client
 
-	blobContainerClient, err := NewBlobContainersClient(subscriptionID, cred, options)
+	blobContainerClient, err := NewBlobContainersClient(subscriptionID, cred, apiVersions.clientOptions(options, StorageNamespace))
 	if err != nil {
 		return nil, err
 	}
 	wrapper.BlobContainerClient = blobContainerClient.client
 
-	userAssignedIdentitiesClient, err := NewUserAssignedIdentitiesClient(subscriptionID, cred, options)
+	userAssignedIdentitiesClient, err := NewUserAssignedIdentitiesClient(subscriptionID, cred, apiVersions.clientOptions(options, ManagedIdentityNamespace))
 	if err != nil {
 		return nil, err
 	}
 	wrapper.UserAssignedIdentitiesClient = userAssignedIdentitiesClient.client
 
-	roleDefinitionsClient, err := NewRoleDefinitionsClient(cred, options)
+	roleDefinitionsClient, err := NewRoleDefinitionsClient(cred, apiVersions.clientOptions(options, AuthorizationNamespace))
 	if err != nil {
 		return nil, err
 	}
 	wrapper.RoleDefinitionsClient = roleDefinitionsClient.client
 
-	roleAssignmentsClient, err := NewRoleAssignmentsClient(subscriptionID, cred, options)
+	roleAssignmentsClient, err := NewRoleAssignmentsClient(subscriptionID, cred, apiVersions.clientOptions(options, AuthorizationNamespace))
 	if err != nil {
 		return nil, err
 	}
 	wrapper.RoleAssignmentClient = roleAssignmentsClient.client
 
-	federatedIdentityCredentialsClient, err := NewFederatedIdentityCredentialsClient(subscriptionID, cred, options)
+	permissionsClient, err := NewPermissionsClient(subscriptionID, cred, apiVersions.clientOptions(options, AuthorizationNamespace))
+	if err != nil {
+		return nil, err
+	}
+	wrapper.PermissionsClient = permissionsClient.client
+
+	federatedIdentityCredentialsClient, err := NewFederatedIdentityCredentialsClient(subscriptionID, cred, apiVersions.clientOptions(options, ManagedIdentityNamespace))
 	if err != nil {
 		return nil, err
 	}
@@ -303,6 +303,21 @@ func (mr *MockBlobContainersClientMockRecorder) Get(ctx, resourceGroupName, acco
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockBlobContainersClient)(nil).Get), ctx, resourceGroupName, accountName, containerName, options)
 }
 
+// Update mocks base method.
+func (m *MockBlobContainersClient) Update(ctx context.Context, resourceGroupName, accountName, containerName string, blobContainer armstorage.BlobContainer, options *armstorage.BlobContainersClientUpdateOptions) (armstorage.BlobContainersClientUpdateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, resourceGroupName, accountName, containerName, blobContainer, options)
+	ret0, _ := ret[0].(armstorage.BlobContainersClientUpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockBlobContainersClientMockRecorder) Update(ctx, resourceGroupName, accountName, containerName, blobContainer, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockBlobContainersClient)(nil).Update), ctx, resourceGroupName, accountName, containerName, blobContainer, options)
+}
+
 // MockAZBlobClient is a mock of AZBlobClient interface.
 type MockAZBlobClient struct {
 	ctrl     *gomock.Controller
@@ -542,6 +557,43 @@ func (mr *MockRoleAssignmentsClientMockRecorder) NewListForScopePager(scope, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewListForScopePager", reflect.TypeOf((*MockRoleAssignmentsClient)(nil).NewListForScopePager), scope, options)
 }
 
+// MockPermissionsClient is a mock of PermissionsClient interface.
+type MockPermissionsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockPermissionsClientMockRecorder
+}
+
+// MockPermissionsClientMockRecorder is the mock recorder for MockPermissionsClient.
+type MockPermissionsClientMockRecorder struct {
+	mock *MockPermissionsClient
+}
+
+// NewMockPermissionsClient creates a new mock instance.
+func NewMockPermissionsClient(ctrl *gomock.Controller) *MockPermissionsClient {
+	mock := &MockPermissionsClient{ctrl: ctrl}
+	mock.recorder = &MockPermissionsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPermissionsClient) EXPECT() *MockPermissionsClientMockRecorder {
+	return m.recorder
+}
+
+// NewListForResourceGroupPager mocks base method.
+func (m *MockPermissionsClient) NewListForResourceGroupPager(resourceGroupName string, options *armauthorization.PermissionsClientListForResourceGroupOptions) *runtime.Pager[armauthorization.PermissionsClientListForResourceGroupResponse] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewListForResourceGroupPager", resourceGroupName, options)
+	ret0, _ := ret[0].(*runtime.Pager[armauthorization.PermissionsClientListForResourceGroupResponse])
+	return ret0
+}
+
+// NewListForResourceGroupPager indicates an expected call of NewListForResourceGroupPager.
+func (mr *MockPermissionsClientMockRecorder) NewListForResourceGroupPager(resourceGroupName, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewListForResourceGroupPager", reflect.TypeOf((*MockPermissionsClient)(nil).NewListForResourceGroupPager), resourceGroupName, options)
+}
+
 // MockFederatedIdentityCredentialsClient is a mock of FederatedIdentityCredentialsClient interface.
 type MockFederatedIdentityCredentialsClient struct {
 	ctrl     *gomock.Controller
@@ -580,6 +632,21 @@ func (mr *MockFederatedIdentityCredentialsClientMockRecorder) CreateOrUpdate(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdate", reflect.TypeOf((*MockFederatedIdentityCredentialsClient)(nil).CreateOrUpdate), ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, parameters, options)
 }
 
+// Delete mocks base method.
+func (m *MockFederatedIdentityCredentialsClient) Delete(ctx context.Context, resourceGroupName, resourceName, federatedIdentityCredentialResourceName string, options *armmsi.FederatedIdentityCredentialsClientDeleteOptions) (armmsi.FederatedIdentityCredentialsClientDeleteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, options)
+	ret0, _ := ret[0].(armmsi.FederatedIdentityCredentialsClientDeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFederatedIdentityCredentialsClientMockRecorder) Delete(ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFederatedIdentityCredentialsClient)(nil).Delete), ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, options)
+}
+
 // Get mocks base method.
 func (m *MockFederatedIdentityCredentialsClient) Get(ctx context.Context, resourceGroupName, resourceName, federatedIdentityCredentialResourceName string, options *armmsi.FederatedIdentityCredentialsClientGetOptions) (armmsi.FederatedIdentityCredentialsClientGetResponse, error) {
 	m.ctrl.T.Helper()
@@ -595,6 +662,20 @@ func (mr *MockFederatedIdentityCredentialsClientMockRecorder) Get(ctx, resourceG
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFederatedIdentityCredentialsClient)(nil).Get), ctx, resourceGroupName, resourceName, federatedIdentityCredentialResourceName, options)
 }
 
+// NewListPager mocks base method.
+func (m *MockFederatedIdentityCredentialsClient) NewListPager(resourceGroupName, resourceName string, options *armmsi.FederatedIdentityCredentialsClientListOptions) *runtime.Pager[armmsi.FederatedIdentityCredentialsClientListResponse] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewListPager", resourceGroupName, resourceName, options)
+	ret0, _ := ret[0].(*runtime.Pager[armmsi.FederatedIdentityCredentialsClientListResponse])
+	return ret0
+}
+
+// NewListPager indicates an expected call of NewListPager.
+func (mr *MockFederatedIdentityCredentialsClientMockRecorder) NewListPager(resourceGroupName, resourceName, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewListPager", reflect.TypeOf((*MockFederatedIdentityCredentialsClient)(nil).NewListPager), resourceGroupName, resourceName, options)
+}
+
 // MockMockablePoller is a mock of MockablePoller interface.
 type MockMockablePoller[T any] struct {
 	ctrl     *gomock.Controller
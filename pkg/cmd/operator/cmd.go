@@ -19,6 +19,7 @@ package cmd
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	golog "log"
 	"os"
@@ -43,17 +44,21 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	controller "github.com/openshift/cloud-credential-operator/pkg/operator"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/credentialsrequest"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/credentialsrequest/actuator"
 	"github.com/openshift/cloud-credential-operator/pkg/operator/platform"
 	"github.com/openshift/cloud-credential-operator/pkg/util"
 
 	"github.com/openshift/library-go/pkg/controller/fileobserver"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
@@ -63,12 +68,24 @@ const (
 	defaultLogLevel        = "info"
 	leaderElectionLockName = "cloud-credential-operator-leader"
 
+	// The defaults applied by controller-runtime to rest configs which don't set a rate limit
+	defaultKubeAPIQPS   = 20
+	defaultKubeAPIBurst = 30
+
+	defaultMaxConcurrentReconciles = 1
+
 	caConfigMapMountPath = "/var/run/configmaps/trusted-ca-bundle"
 	caConfigMapName      = "tls-ca-bundle.pem"
 )
 
 type ControllerManagerOptions struct {
-	LogLevel string
+	LogLevel                   string
+	CloudRevalidationInterval  time.Duration
+	CredentialsBackupNamespace string
+	SecretSink                 string
+	KubeAPIQPS                 float32
+	KubeAPIBurst               int
+	MaxConcurrentReconciles    int
 }
 
 func NewOperator() *cobra.Command {
@@ -85,12 +102,31 @@ func NewOperator() *cobra.Command {
 			log.SetLevel(level)
 			log.Debug("debug logging enabled")
 
+			if opts.CloudRevalidationInterval < 0 {
+				log.Fatal("Cloud re-validation interval cannot be negative")
+			}
+			credentialsrequest.RevalidationInterval = opts.CloudRevalidationInterval
+			credentialsrequest.BackupSecretNamespace = opts.CredentialsBackupNamespace
+			if !sets.NewString(actuator.SecretSinkNames()...).Has(opts.SecretSink) {
+				log.Fatalf("Unknown secret sink %q, must be one of %v", opts.SecretSink, actuator.SecretSinkNames())
+			}
+			if opts.KubeAPIQPS <= 0 {
+				log.Fatal("Kubernetes API QPS must be positive")
+			}
+			if opts.KubeAPIBurst < 1 {
+				log.Fatal("Kubernetes API burst must be at least 1")
+			}
+			if opts.MaxConcurrentReconciles < 1 {
+				log.Fatal("Maximum number of concurrent reconciles must be at least 1")
+			}
+
 			// Get a config to talk to the apiserver
 			log.Info("setting up client for manager")
 			cfg, err := config.GetConfig()
 			if err != nil {
 				log.WithError(err).Fatal("unable to set up client config")
 			}
+			setKubeAPIRateLimits(cfg, opts)
 
 			run := func(ctx context.Context) {
 				// This is required because controller-runtime expects its consumers to
@@ -101,21 +137,7 @@ func NewOperator() *cobra.Command {
 
 				// Create a new Cmd to provide shared dependencies and start components
 				log.Info("setting up manager")
-				mgr, err := manager.New(cfg, manager.Options{
-					MetricsBindAddress: ":2112",
-					NewCache: func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
-						if opts.ByObject == nil {
-							opts.ByObject = map[client.Object]cache.ByObject{}
-						}
-						opts.ByObject[&corev1.ConfigMap{}] = cache.ByObject{
-							Field: fields.SelectorFromSet(fields.Set{
-								"metadata.namespace": minterv1.CloudCredOperatorNamespace,
-								"metadata.name":      constants.CloudCredOperatorConfigMap,
-							}),
-						}
-						return cache.New(config, opts)
-					},
-				})
+				mgr, err := manager.New(cfg, newManagerOptions(opts))
 				if err != nil {
 					log.WithError(err).Fatal("unable to set up overall controller manager")
 				}
@@ -134,7 +156,7 @@ func NewOperator() *cobra.Command {
 				// Setup all Controllers
 				log.Info("setting up controller")
 				kubeconfigCommandLinePath := cmd.PersistentFlags().Lookup("kubeconfig").Value.String()
-				if err := controller.AddToManager(mgr, kubeconfigCommandLinePath, awsSecurityTokenServiveGateEnaled); err != nil {
+				if err := controller.AddToManager(mgr, kubeconfigCommandLinePath, awsSecurityTokenServiveGateEnaled, opts.SecretSink); err != nil {
 					log.WithError(err).Fatal("unable to register controllers to the manager")
 				}
 
@@ -223,6 +245,12 @@ func NewOperator() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVar(&opts.LogLevel, "log-level", defaultLogLevel, "Log level (debug,info,warn,error,fatal)")
+	cmd.PersistentFlags().DurationVar(&opts.CloudRevalidationInterval, "cloud-revalidation-interval", 0, "Interval at which provisioned credentials are re-validated against the cloud between the hourly syncs (0 disables re-validation)")
+	cmd.PersistentFlags().StringVar(&opts.CredentialsBackupNamespace, "credentials-backup-namespace", "", "Namespace to which the secrets provisioned for CredentialsRequests are mirrored as a backup for disaster recovery (empty disables mirroring)")
+	cmd.PersistentFlags().StringVar(&opts.SecretSink, "secret-sink", actuator.InClusterSecretSinkName, fmt.Sprintf("Store in which the credentials minted for CredentialsRequests are stored, one of %v", actuator.SecretSinkNames()))
+	cmd.PersistentFlags().Float32Var(&opts.KubeAPIQPS, "kube-api-qps", defaultKubeAPIQPS, "Maximum sustained queries per second of the operator's clients against the Kubernetes API")
+	cmd.PersistentFlags().IntVar(&opts.KubeAPIBurst, "kube-api-burst", defaultKubeAPIBurst, "Maximum burst of queries of the operator's clients against the Kubernetes API above the sustained QPS")
+	cmd.PersistentFlags().IntVar(&opts.MaxConcurrentReconciles, "max-concurrent-reconciles", defaultMaxConcurrentReconciles, "Maximum number of objects, eg. CredentialsRequests, which each controller reconciles concurrently")
 	cmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	initializeGlog(cmd.PersistentFlags())
 	flag.CommandLine.Parse([]string{})
@@ -230,6 +258,35 @@ func NewOperator() *cobra.Command {
 	return cmd
 }
 
+// setKubeAPIRateLimits sets the client-side rate limit of the requests made with cfg against the Kubernetes API
+func setKubeAPIRateLimits(cfg *rest.Config, opts *ControllerManagerOptions) {
+	cfg.QPS = opts.KubeAPIQPS
+	cfg.Burst = opts.KubeAPIBurst
+}
+
+// newManagerOptions returns the options of the controller manager, whose controllers reconcile up to
+// opts.MaxConcurrentReconciles objects concurrently
+func newManagerOptions(opts *ControllerManagerOptions) manager.Options {
+	return manager.Options{
+		MetricsBindAddress: ":2112",
+		Controller: ctrlconfig.Controller{
+			MaxConcurrentReconciles: opts.MaxConcurrentReconciles,
+		},
+		NewCache: func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+			if opts.ByObject == nil {
+				opts.ByObject = map[client.Object]cache.ByObject{}
+			}
+			opts.ByObject[&corev1.ConfigMap{}] = cache.ByObject{
+				Field: fields.SelectorFromSet(fields.Set{
+					"metadata.namespace": minterv1.CloudCredOperatorNamespace,
+					"metadata.name":      constants.CloudCredOperatorConfigMap,
+				}),
+			}
+			return cache.New(config, opts)
+		},
+	}
+}
+
 func initializeGlog(flags *pflag.FlagSet) {
 	golog.SetOutput(glogWriter{}) // Redirect all regular go log output to glog
 	golog.SetFlags(0)
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func TestManagerSetup(t *testing.T) {
+	tests := []struct {
+		name                            string
+		opts                            ControllerManagerOptions
+		expectedQPS                     float32
+		expectedBurst                   int
+		expectedMaxConcurrentReconciles int
+	}{
+		{
+			name: "defaults",
+			opts: ControllerManagerOptions{
+				KubeAPIQPS:              defaultKubeAPIQPS,
+				KubeAPIBurst:            defaultKubeAPIBurst,
+				MaxConcurrentReconciles: defaultMaxConcurrentReconciles,
+			},
+			expectedQPS:                     20,
+			expectedBurst:                   30,
+			expectedMaxConcurrentReconciles: 1,
+		},
+		{
+			name: "configured",
+			opts: ControllerManagerOptions{
+				KubeAPIQPS:              50,
+				KubeAPIBurst:            100,
+				MaxConcurrentReconciles: 5,
+			},
+			expectedQPS:                     50,
+			expectedBurst:                   100,
+			expectedMaxConcurrentReconciles: 5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &rest.Config{Host: "https://127.0.0.1:6443"}
+			setKubeAPIRateLimits(cfg, &test.opts)
+
+			mgrOpts := newManagerOptions(&test.opts)
+			// Don't bind the metrics endpoint
+			mgrOpts.MetricsBindAddress = "0"
+			mgr, err := manager.New(cfg, mgrOpts)
+			require.NoError(t, err, "unexpected error setting up the manager")
+
+			assert.Equal(t, test.expectedQPS, mgr.GetConfig().QPS, "unexpected QPS")
+			assert.Equal(t, test.expectedBurst, mgr.GetConfig().Burst, "unexpected burst")
+			assert.Equal(t, test.expectedMaxConcurrentReconciles, mgr.GetControllerOptions().MaxConcurrentReconciles, "unexpected maximum number of concurrent reconciles")
+		})
+	}
+}
@@ -5,11 +5,12 @@ import (
 )
 
 type options struct {
-	TargetDir         string
-	Name              string
-	Region            string
-	CredRequestDir    string
-	EnableTechPreview bool
+	TargetDir               string
+	Name                    string
+	Region                  string
+	CredRequestDirs         []string
+	EnableTechPreview       bool
+	AllowInsecureSecretsDir bool
 }
 
 // NewAliababaCloudCmd implements the "alibabacloud" subcommand for the credentials provisioning
@@ -3,7 +3,6 @@ package alibabacloud
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -29,7 +28,8 @@ stringData:
     access_key_secret = %s
 kind: Secret
 metadata:
-  name: %s
+  annotations:
+%s  name: %s
   namespace: %s
 type: Opaque`
 
@@ -45,10 +45,10 @@ var (
 	// CreateRAMUsersOpts captures the options that affect creation/updating
 	// of the RAM Uers/Policies.
 	CreateRAMUsersOpts = options{
-		Region:         "",
-		TargetDir:      "",
-		Name:           "",
-		CredRequestDir: "",
+		Region:          "",
+		TargetDir:       "",
+		Name:            "",
+		CredRequestDirs: []string{},
 	}
 )
 
@@ -58,7 +58,7 @@ func createRAMUsersCmd(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to create a client: %v", err)
 	}
 
-	err = createRAMUsers(client, CreateRAMUsersOpts.Name, CreateRAMUsersOpts.CredRequestDir,
+	err = createRAMUsers(client, CreateRAMUsersOpts.Name, CreateRAMUsersOpts.CredRequestDirs,
 		CreateRAMUsersOpts.TargetDir, CreateRAMUsersOpts.EnableTechPreview)
 	if err != nil {
 		log.Fatalf(err.Error())
@@ -66,9 +66,9 @@ func createRAMUsersCmd(cmd *cobra.Command, args []string) {
 }
 
 // createRAMUsers will create a ram user for the given credenital request and attach the specific ram policy
-func createRAMUsers(client alibabacloud.Client, name, credReqDir, targetDir string, enableTechPreview bool) error {
+func createRAMUsers(client alibabacloud.Client, name string, credReqDirs []string, targetDir string, enableTechPreview bool) error {
 	// Process directory
-	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDir, enableTechPreview)
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
@@ -290,9 +290,9 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, accessKeyId
 	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
 	filePath := filepath.Join(manifestsDir, fileName)
 
-	fileData := fmt.Sprintf(secretManifestsTemplate, accessKeyId, accessKeySecret, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
+	fileData := fmt.Sprintf(secretManifestsTemplate, accessKeyId, accessKeySecret, provisioning.ProvenanceAnnotations(cr, ""), cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
 
-	if err := ioutil.WriteFile(filePath, []byte(fileData), 0600); err != nil {
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
 		return errors.Wrap(err, "Failed to save Secret file")
 	}
 
@@ -329,6 +329,8 @@ func initEnvForCreateRAMUsersCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("Failed to create manifests directory at %s", manifestsDir)
 	}
+
+	provisioning.EnsureSecretsDir(manifestsDir, CreateRAMUsersOpts.AllowInsecureSecretsDir)
 }
 
 // NewCreateRAMUsersCmd provides the "create-ram-users" subcommand
@@ -342,11 +344,12 @@ func NewCreateRAMUsersCmd() *cobra.Command {
 
 	createRAMUsersCmd.PersistentFlags().StringVar(&CreateRAMUsersOpts.Name, "name", "", "User-defined name for all created Alibaba Cloud resources (can be separate from the cluster's infra-id)")
 	createRAMUsersCmd.MarkPersistentFlagRequired("name")
-	createRAMUsersCmd.PersistentFlags().StringVar(&CreateRAMUsersOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to create RAM AK for (can be created by running 'oc adm release extract --credentials-requests --cloud=alibabacloud' against an OpenShift release image)")
+	createRAMUsersCmd.PersistentFlags().StringSliceVar(&CreateRAMUsersOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create RAM AK for (can be created by running 'oc adm release extract --credentials-requests --cloud=alibabacloud' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createRAMUsersCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createRAMUsersCmd.PersistentFlags().StringVar(&CreateRAMUsersOpts.Region, "region", "", "Alibaba Cloud region endpoint only required for GovCloud")
 	createRAMUsersCmd.PersistentFlags().StringVar(&CreateRAMUsersOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
 	createRAMUsersCmd.PersistentFlags().BoolVar(&CreateRAMUsersOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	provisioning.AddAllowInsecureSecretsDirFlag(createRAMUsersCmd.PersistentFlags(), &CreateRAMUsersOpts.AllowInsecureSecretsDir)
 
 	return createRAMUsersCmd
 }
@@ -0,0 +1,168 @@
+package provisioning
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// archiveModTime is the modification time of every entry of an output archive, which is fixed so that archives of
+// the same outputs are identical
+var archiveModTime = time.Unix(0, 0)
+
+// ValidateOutputArchivePath validates the path provided to --output-archive and returns its absolute path. The archive
+// may not be written within outputDir's manifests directory, which is removed once the archive has been written.
+func ValidateOutputArchivePath(archivePath, outputDir string) (string, error) {
+	if archivePath == "" {
+		return "", nil
+	}
+	absArchivePath, err := filepath.Abs(archivePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve full path of the output archive %s", archivePath)
+	}
+	absManifestsDir, err := filepath.Abs(filepath.Join(outputDir, ManifestsDirName))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve full path of the output directory %s", outputDir)
+	}
+	if strings.HasPrefix(absArchivePath, absManifestsDir+string(filepath.Separator)) {
+		return "", errors.Errorf("--output-archive %s may not be within the manifests directory %s", archivePath, absManifestsDir)
+	}
+	if info, err := os.Stat(absArchivePath); err == nil && info.IsDir() {
+		return "", errors.Errorf("--output-archive %s is a directory", archivePath)
+	}
+	return absArchivePath, nil
+}
+
+// ArchiveOutputs packages the manifests and tls directories, the inventory and the terraform import commands within outputDir into a gzipped tar
+// archive written to archivePath, so that they may be transported as a single file, eg. into a disconnected environment.
+//
+// The archive is reproducible: entries are ordered by path and carry no timestamps or ownership so that archives of
+// the same outputs are identical. The permissions of the files are preserved, eg. secrets remain readable only by
+// their owner, and the archive itself is only readable by its owner.
+//
+// Once the archive has been written the manifests directory, the inventory and the terraform import commands are removed from outputDir. The tls
+// directory is left in place as the key pair it contains is reused by later runs of ccoctl.
+func ArchiveOutputs(outputDir, archivePath string) error {
+	paths, err := outputArchivePaths(outputDir)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := archivePath + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create output archive %s", archivePath)
+	}
+	defer os.Remove(tmpPath)
+	if err := writeOutputArchive(f, outputDir, paths); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to write output archive %s", archivePath)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to write output archive %s", archivePath)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write output archive %s", archivePath)
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		return errors.Wrapf(err, "failed to write output archive %s", archivePath)
+	}
+
+	for _, name := range []string{ManifestsDirName, InventoryFileName, TerraformImportFileName} {
+		if err := os.RemoveAll(filepath.Join(outputDir, name)); err != nil {
+			return errors.Wrapf(err, "failed to remove %s which was written to output archive %s", name, archivePath)
+		}
+	}
+	return nil
+}
+
+// outputArchivePaths returns the paths, relative to outputDir, of the directories and regular files to be archived in
+// lexical order
+func outputArchivePaths(outputDir string) ([]string, error) {
+	paths := []string{}
+	for _, dir := range []string{ManifestsDirName, TLSDirName} {
+		root := filepath.Join(outputDir, dir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && !entry.Type().IsRegular() {
+				return nil
+			}
+			relPath, err := filepath.Rel(outputDir, path)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, relPath)
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list files within %s", root)
+		}
+	}
+	for _, name := range []string{InventoryFileName, TerraformImportFileName} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err == nil {
+			paths = append(paths, name)
+		}
+	}
+	return paths, nil
+}
+
+// writeOutputArchive writes the gzipped tar archive of the paths within outputDir to w
+func writeOutputArchive(w io.Writer, outputDir string, paths []string) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, path := range paths {
+		info, err := os.Stat(filepath.Join(outputDir, path))
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Name:    filepath.ToSlash(path),
+			Mode:    int64(info.Mode().Perm()),
+			ModTime: archiveModTime,
+			Format:  tar.FormatUSTAR,
+		}
+		if info.IsDir() {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Size = info.Size()
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if err := copyFile(tarWriter, filepath.Join(outputDir, path)); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,150 @@
+package provisioning
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveEntry struct {
+	name    string
+	mode    int64
+	content string
+}
+
+func TestArchiveOutputs(t *testing.T) {
+	outputDir := t.TempDir()
+	writeArchiveTestOutputs(t, outputDir)
+	archivePath := filepath.Join(t.TempDir(), "outputs.tar.gz")
+
+	err := ArchiveOutputs(outputDir, archivePath)
+	require.NoError(t, err, "unexpected error writing output archive")
+
+	info, err := os.Stat(archivePath)
+	require.NoError(t, err, "output archive not written")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "unexpected output archive permissions")
+
+	entries := readArchiveEntries(t, archivePath)
+	assert.Equal(t, []archiveEntry{
+		{name: "manifests/", mode: 0700},
+		{name: "manifests/cluster-authentication-02-config.yaml", mode: 0644, content: "config"},
+		{name: "manifests/openshift-test-secret-credentials.yaml", mode: 0600, content: "secret"},
+		{name: "tls/", mode: 0700},
+		{name: "tls/bound-service-account-signing-key.key", mode: 0600, content: "private key"},
+		{name: "tls/bound-service-account-signing-key.pub", mode: 0600, content: "public key"},
+		{name: InventoryFileName, mode: 0600, content: "{}"},
+	}, entries, "unexpected output archive entries")
+
+	_, err = os.Stat(filepath.Join(outputDir, ManifestsDirName))
+	assert.True(t, os.IsNotExist(err), "expected manifests directory to be removed")
+	_, err = os.Stat(filepath.Join(outputDir, InventoryFileName))
+	assert.True(t, os.IsNotExist(err), "expected inventory to be removed")
+	_, err = os.Stat(filepath.Join(outputDir, TLSDirName, "bound-service-account-signing-key.key"))
+	assert.NoError(t, err, "expected tls directory to be kept")
+	_, err = os.Stat(archivePath + ".tmp")
+	assert.True(t, os.IsNotExist(err), "expected temporary archive to be removed")
+}
+
+func TestArchiveOutputsReproducible(t *testing.T) {
+	archives := [][]byte{}
+	for i := 0; i < 2; i++ {
+		outputDir := t.TempDir()
+		writeArchiveTestOutputs(t, outputDir)
+		archivePath := filepath.Join(t.TempDir(), "outputs.tar.gz")
+		require.NoError(t, ArchiveOutputs(outputDir, archivePath), "unexpected error writing output archive")
+		archive, err := os.ReadFile(archivePath)
+		require.NoError(t, err, "failed to read output archive")
+		archives = append(archives, archive)
+	}
+	assert.Equal(t, archives[0], archives[1], "expected identical archives of identical outputs")
+}
+
+func TestValidateOutputArchivePath(t *testing.T) {
+	outputDir := t.TempDir()
+	tests := []struct {
+		name        string
+		archivePath string
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "No output archive",
+		},
+		{
+			name:        "Output archive within output directory",
+			archivePath: filepath.Join(outputDir, "outputs.tar.gz"),
+			expected:    filepath.Join(outputDir, "outputs.tar.gz"),
+		},
+		{
+			name:        "Output archive within manifests directory",
+			archivePath: filepath.Join(outputDir, ManifestsDirName, "outputs.tar.gz"),
+			expectError: true,
+		},
+		{
+			name:        "Output archive is a directory",
+			archivePath: outputDir,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archivePath, err := ValidateOutputArchivePath(test.archivePath, outputDir)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expected, archivePath, "unexpected output archive path")
+			}
+		})
+	}
+}
+
+func writeArchiveTestOutputs(t *testing.T, outputDir string) {
+	files := []archiveEntry{
+		{name: "manifests/openshift-test-secret-credentials.yaml", mode: 0600, content: "secret"},
+		{name: "manifests/cluster-authentication-02-config.yaml", mode: 0644, content: "config"},
+		{name: "tls/bound-service-account-signing-key.pub", mode: 0600, content: "public key"},
+		{name: "tls/bound-service-account-signing-key.key", mode: 0600, content: "private key"},
+		{name: InventoryFileName, mode: 0600, content: "{}"},
+	}
+	for _, file := range files {
+		path := filepath.Join(outputDir, filepath.FromSlash(file.name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700), "failed to create test output directory")
+		require.NoError(t, os.WriteFile(path, []byte(file.content), os.FileMode(file.mode)), "failed to write test output")
+		// Ensure the mode is not altered by the umask
+		require.NoError(t, os.Chmod(path, os.FileMode(file.mode)), "failed to set test output permissions")
+	}
+	for _, dir := range []string{ManifestsDirName, TLSDirName} {
+		require.NoError(t, os.Chmod(filepath.Join(outputDir, dir), 0700), "failed to set test output directory permissions")
+	}
+}
+
+func readArchiveEntries(t *testing.T, archivePath string) []archiveEntry {
+	f, err := os.Open(archivePath)
+	require.NoError(t, err, "failed to open output archive")
+	defer f.Close()
+	gzipReader, err := gzip.NewReader(f)
+	require.NoError(t, err, "failed to read output archive")
+	tarReader := tar.NewReader(gzipReader)
+
+	entries := []archiveEntry{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "failed to read output archive entry")
+		assert.True(t, header.ModTime.Equal(archiveModTime), "unexpected modification time of %s", header.Name)
+		content, err := io.ReadAll(tarReader)
+		require.NoError(t, err, "failed to read output archive entry")
+		entries = append(entries, archiveEntry{name: header.Name, mode: header.Mode, content: string(content)})
+	}
+	return entries
+}
@@ -0,0 +1,241 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	// AuditResultSucceeded and AuditResultFailed are the results of the operations recorded within the audit log
+	AuditResultSucceeded = "succeeded"
+	AuditResultFailed    = "failed"
+
+	// auditCommandResourceType is the resource type of the record written once the command has completed
+	auditCommandResourceType = "Command"
+)
+
+// auditOperations are the operations recorded within the audit log, keyed by the status of the resource event
+var auditOperations = map[string]string{
+	ResourceCreated: "create",
+	ResourceUpdated: "update",
+	ResourceDeleted: "delete",
+}
+
+// AuditRecord is appended to the file of --audit-log as a single line of JSON for each resource which is created,
+// updated or deleted, followed by a final record of the run of the command. Fields are only ever added to the record so
+// that the trail remains readable by the tools processing it.
+type AuditRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Command is the command which performed the operation, eg. "ccoctl aws create-all"
+	Command string `json:"command"`
+	// Operation is "create", "update" or "delete", or "run" for the final record of the command
+	Operation string `json:"operation"`
+	// ResourceType is the type of the resource, eg. "IAMRole", or "Command" for the final record of the command
+	ResourceType string `json:"resourceType"`
+	// ResourceID identifies the resource, eg. its ARN or name
+	ResourceID string `json:"resourceID"`
+	// Result is AuditResultSucceeded or AuditResultFailed
+	Result string `json:"result"`
+	// Identity is the cloud identity with which the operation was performed, eg. the ARN of the AWS caller identity.
+	// When several cloud providers are used by the command the identity of each is listed as provider=identity.
+	Identity string `json:"identity,omitempty"`
+	// Error is the error with which the command failed, only set on the final record of a failed command
+	Error string `json:"error,omitempty"`
+}
+
+// auditIdentity resolves the cloud identity of a provider the first time it is recorded
+type auditIdentity struct {
+	once     sync.Once
+	resolve  func() string
+	identity string
+}
+
+// auditLog appends audit records to out. Operations may complete concurrently so writes are serialized, and every
+// record is synced to disk as it is written so that the trail survives a crash of ccoctl.
+type auditLog struct {
+	mu       sync.Mutex
+	out      *os.File
+	command  string
+	finished bool
+	now      func() time.Time
+
+	identitiesMu sync.Mutex
+	identities   map[string]*auditIdentity
+}
+
+// audit is the audit log of --audit-log, it is nil unless the audit log is enabled
+var audit *auditLog
+
+// InitAuditLog configures ccoctl to append a record of every resource created, updated or deleted by command to the
+// file at path, which is created when it doesn't exist. Nothing is recorded when path is empty.
+func InitAuditLog(path, command string) error {
+	if path == "" {
+		audit = nil
+		return nil
+	}
+	// The records identify the resources of the account and are only readable by their owner
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "failed to open --audit-log file %s", path)
+	}
+	audit = &auditLog{out: f, command: command, now: time.Now, identities: map[string]*auditIdentity{}}
+	log.SetOutput(&fatalAuditWriter{out: log.Writer(), flags: log.Flags()})
+	return nil
+}
+
+// AuditLogEnabled returns true when --audit-log was provided
+func AuditLogEnabled() bool {
+	return audit != nil
+}
+
+// RegisterAuditIdentity registers resolve as the resolver of the cloud identity with which the API calls of provider
+// are made, eg. "aws". The identity is only resolved once a resource is recorded, so that no cloud API is called unless
+// the audit log is enabled and the command mutates resources. The first resolver registered for a provider is kept.
+func RegisterAuditIdentity(provider string, resolve func() string) {
+	if audit == nil {
+		return
+	}
+	audit.identitiesMu.Lock()
+	defer audit.identitiesMu.Unlock()
+	if _, found := audit.identities[provider]; !found {
+		audit.identities[provider] = &auditIdentity{resolve: resolve}
+	}
+}
+
+// identity returns the identities of the providers registered. Identities not yet resolved are resolved unless
+// resolve is false, in which case they are omitted.
+func (a *auditLog) identity(resolve bool) string {
+	a.identitiesMu.Lock()
+	identities := make(map[string]*auditIdentity, len(a.identities))
+	providers := make([]string, 0, len(a.identities))
+	for provider, identity := range a.identities {
+		identities[provider] = identity
+		providers = append(providers, provider)
+	}
+	a.identitiesMu.Unlock()
+	sort.Strings(providers)
+
+	resolved := []string{}
+	for _, provider := range providers {
+		identity := identities[provider]
+		if resolve {
+			identity.once.Do(func() { identity.identity = identity.resolve() })
+		}
+		if identity.identity == "" {
+			continue
+		}
+		if len(providers) == 1 {
+			return identity.identity
+		}
+		resolved = append(resolved, provider+"="+identity.identity)
+	}
+	return strings.Join(resolved, ",")
+}
+
+// auditResource records that the resource of resourceType identified by id was created, updated or deleted, as
+// indicated by status. Nothing is recorded unless the audit log is enabled.
+func auditResource(resourceType, id, status string) {
+	if audit == nil {
+		return
+	}
+	operation, found := auditOperations[status]
+	if !found {
+		operation = status
+	}
+	record := AuditRecord{
+		Operation:    operation,
+		ResourceType: resourceType,
+		ResourceID:   id,
+		Result:       AuditResultSucceeded,
+		Identity:     audit.identity(true),
+	}
+	if err := audit.append(record, false); err != nil {
+		Warnf("Failed to write audit record: %s", err)
+	}
+}
+
+// FinishAuditLog appends the final record of the run of the command, reporting whether it failed with err, and closes
+// the audit log. It is safe to call more than once, only the first record is written.
+func FinishAuditLog(err error) {
+	if audit == nil {
+		return
+	}
+	if err := audit.finish(err, audit.identity(true)); err != nil {
+		Warnf("Failed to write audit record: %s", err)
+	}
+}
+
+func (a *auditLog) finish(err error, identity string) error {
+	record := AuditRecord{
+		Operation:    "run",
+		ResourceType: auditCommandResourceType,
+		ResourceID:   a.command,
+		Result:       AuditResultSucceeded,
+		Identity:     identity,
+	}
+	if err != nil {
+		record.Result = AuditResultFailed
+		record.Error = err.Error()
+	}
+	return a.append(record, true)
+}
+
+// append writes record to the audit log unless the final record was already written, which is written when final is
+// true. Nothing may be logged while mu is held since the final record is written from within the standard logger when
+// ccoctl exits through log.Fatal. The secret material within the identifiers and errors recorded is redacted.
+func (a *auditLog) append(record AuditRecord, final bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.finished {
+		return nil
+	}
+	record.Timestamp = a.now().UTC()
+	record.Command = a.command
+	record.ResourceID = Redact(record.ResourceID)
+	record.Error = Redact(record.Error)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// The record is written with a single write so that it is never interleaved with the records of another run
+	// appending to the same file
+	if _, err := a.out.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := a.out.Sync(); err != nil {
+		return fmt.Errorf("failed to sync --audit-log file: %w", err)
+	}
+	if final {
+		a.finished = true
+		return a.out.Close()
+	}
+	return nil
+}
+
+// fatalAuditWriter wraps the output of the standard logger to append the final record of a failed run to the audit
+// log before the message logged by log.Fatal, after which ccoctl exits without returning to its caller
+type fatalAuditWriter struct {
+	out io.Writer
+	// flags are the flags of the standard logger, which may not be read while it is writing
+	flags int
+}
+
+func (w *fatalAuditWriter) Write(p []byte) (int, error) {
+	if loggedByFatalOrPanic() && audit != nil {
+		// The standard logger is locked while writing so neither the identity may be resolved, as resolving it may
+		// log, nor errors writing the record be logged
+		message := (&fatalSummaryWriter{flags: w.flags}).message(p)
+		audit.finish(errors.New(message), audit.identity(false))
+	}
+	return w.out.Write(p)
+}
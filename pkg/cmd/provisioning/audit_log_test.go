@@ -0,0 +1,128 @@
+package provisioning
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initTestAuditLog(t *testing.T, path, command string) {
+	logOutput := log.Writer()
+	require.NoError(t, InitAuditLog(path, command), "unexpected error initializing the audit log")
+	audit.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() {
+		audit = nil
+		log.SetOutput(logOutput)
+	})
+}
+
+func readAuditRecords(t *testing.T, path string) []AuditRecord {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read audit log")
+	records := []AuditRecord{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		record := AuditRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "expected each line to be a JSON object")
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	// Records are appended to those of previous runs
+	require.NoError(t, os.WriteFile(path, []byte(`{"command":"ccoctl aws create-all"}`+"\n"), 0600))
+	initTestAuditLog(t, path, "ccoctl aws delete")
+
+	resolved := 0
+	RegisterAuditIdentity("aws", func() string {
+		resolved++
+		return "arn:aws:iam::123456789012:user/admin"
+	})
+	RegisterAuditIdentity("aws", func() string { return "ignored" })
+
+	EmitResourceEvent("IAMRole", "arn:aws:iam::123456789012:role/test", ResourceDeleted)
+	// Records are written as they are appended
+	records := readAuditRecords(t, path)
+	require.Len(t, records, 2, "expected the record to be written immediately")
+
+	EmitResourceEvent("OIDCProvider", "password=hunter2", ResourceCreated)
+	FinishAuditLog(errors.New("failed to delete bucket"))
+	FinishAuditLog(nil)
+	EmitResourceEvent("IAMRole", "ignored", ResourceDeleted)
+
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	identity := "arn:aws:iam::123456789012:user/admin"
+	assert.Equal(t, []AuditRecord{
+		{Command: "ccoctl aws create-all"},
+		{Timestamp: timestamp, Command: "ccoctl aws delete", Operation: "delete", ResourceType: "IAMRole", ResourceID: "arn:aws:iam::123456789012:role/test", Result: AuditResultSucceeded, Identity: identity},
+		{Timestamp: timestamp, Command: "ccoctl aws delete", Operation: "create", ResourceType: "OIDCProvider", ResourceID: "password=" + RedactedFingerprint("hunter2"), Result: AuditResultSucceeded, Identity: identity},
+		{Timestamp: timestamp, Command: "ccoctl aws delete", Operation: "run", ResourceType: "Command", ResourceID: "ccoctl aws delete", Result: AuditResultFailed, Identity: identity, Error: "failed to delete bucket"},
+	}, readAuditRecords(t, path), "unexpected audit records")
+	assert.Equal(t, 1, resolved, "expected the identity to be resolved once")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "expected the audit log to only be readable by its owner")
+}
+
+func TestAuditLogIdentities(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	initTestAuditLog(t, path, "ccoctl multicloud create-all")
+
+	RegisterAuditIdentity("gcp", func() string { return "admin@project.iam.gserviceaccount.com" })
+	RegisterAuditIdentity("aws", func() string { return "arn:aws:iam::123456789012:user/admin" })
+	RegisterAuditIdentity("azure", func() string { return "" })
+	EmitResourceEvent("IAMRole", "test", ResourceUpdated)
+	FinishAuditLog(nil)
+
+	records := readAuditRecords(t, path)
+	require.Len(t, records, 2, "unexpected audit records")
+	assert.Equal(t, "update", records[0].Operation, "unexpected operation")
+	assert.Equal(t, "aws=arn:aws:iam::123456789012:user/admin,gcp=admin@project.iam.gserviceaccount.com", records[0].Identity,
+		"expected the identity of each provider resolved")
+	assert.Equal(t, AuditResultSucceeded, records[1].Result, "unexpected result")
+	assert.Empty(t, records[1].Error, "unexpected error")
+}
+
+func TestAuditLogFatal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logs := &bytes.Buffer{}
+	log.SetOutput(logs)
+	initTestAuditLog(t, path, "ccoctl aws create-all")
+	RegisterAuditIdentity("aws", func() string { return "arn:aws:iam::123456789012:user/admin" })
+
+	EmitResourceEvent("IAMRole", "test", ResourceCreated)
+	assert.Panics(t, func() { log.Panic("failed to create role") }, "expected log.Panic to panic")
+
+	records := readAuditRecords(t, path)
+	require.Len(t, records, 2, "expected a final record to be written")
+	assert.Equal(t, AuditResultFailed, records[1].Result, "unexpected result")
+	assert.Equal(t, "failed to create role", records[1].Error, "unexpected error")
+	assert.Equal(t, "arn:aws:iam::123456789012:user/admin", records[1].Identity, "expected the identity already resolved")
+	assert.Contains(t, logs.String(), "failed to create role", "expected message to be logged")
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	require.NoError(t, InitAuditLog("", "ccoctl aws create-all"))
+	assert.False(t, AuditLogEnabled(), "expected the audit log to be disabled")
+	RegisterAuditIdentity("aws", func() string {
+		t.Fatal("expected the identity not to be resolved")
+		return ""
+	})
+	EmitResourceEvent("IAMRole", "test", ResourceCreated)
+	FinishAuditLog(nil)
+
+	err := InitAuditLog(filepath.Join(t.TempDir(), "missing", "audit.log"), "ccoctl aws create-all")
+	assert.ErrorContains(t, err, "failed to open --audit-log file", "unexpected error")
+}
@@ -1,25 +1,69 @@
 package aws
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 )
 
+// defaultRetryPolicy is the retry policy of AWS API calls unless overridden with --max-retries, --retry-base-delay
+// and --retry-max-delay. IAM is a global service whose low request rate limits are shared by every caller of the
+// account, so throttled calls are retried more often and with longer delays than by default in the SDK.
+var defaultRetryPolicy = provisioning.RetryPolicy{
+	MaxRetries: 8,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
 type options struct {
-	TargetDir              string
-	PublicKeyPath          string
-	Region                 string
-	Name                   string
-	CredRequestDir         string
-	IdentityProviderARN    string
-	PermissionsBoundaryARN string
-	DryRun                 bool
-	EnableTechPreview      bool
-	CreatePrivateS3Bucket  bool
+	TargetDir                      string
+	PublicKeyPath                  string
+	PreviousPublicKeyPath          string
+	Region                         string
+	Regions                        []string
+	InventoryPath                  string
+	Name                           string
+	CredRequestDirs                []string
+	IdentityProviderARN            string
+	PermissionsBoundaryARN         string
+	DryRun                         bool
+	EnableTechPreview              bool
+	Force                          bool
+	AllowInsecureSecretsDir        bool
+	CreatePrivateS3Bucket          bool
+	MaxSessionDuration             int64
+	PolicyStyle                    string
+	ClusterID                      string
+	SkipQuotaCheck                 bool
+	SkipSTSEndpointCheck           bool
+	SkipDeprecatedPermissionsCheck bool
+	SkipRegionConsistencyCheck     bool
+	SkipCostEstimate               bool
+	SharedIdentityProvider         bool
+	OutputArchive                  string
+	OutputImport                   bool
+	VerifyIssuerReachable          bool
+	DiscoveryDocument              provisioning.DiscoveryDocumentExtensions
+	IssuerURL                      string
+	Profiles                       []string
+	ContinueOnError                bool
+	OnlyMissing                    bool
+	FailOnInsufficientPermissions  bool
+	NameFromKubeconfig             bool
+	ConfirmNameFromKubeconfig      bool
+	Yes                            bool
+	ConfirmThreshold               int
+	VerifyAfterDelete              bool
 }
 
 // NewAWSCmd implements the "aws" subcommand for the credentials provisioning
@@ -35,17 +79,97 @@ func NewAWSCmd() *cobra.Command {
 	createCmd.AddCommand(NewCreateIAMRolesCmd())
 	createCmd.AddCommand(NewCreateAllCmd())
 	createCmd.AddCommand(NewDeleteCmd())
+	createCmd.AddCommand(NewReconcileDeleteCmd())
+	createCmd.AddCommand(NewReconcileTagsCmd())
+	createCmd.AddCommand(NewRotateSigningKeyCmd())
+	createCmd.AddCommand(NewFinalizeRotationCmd())
+	createCmd.AddCommand(NewPrintRequiredPermissionsCmd())
 
 	return createCmd
 }
 
-func awsSession(region string) (*session.Session, error) {
+// awsSession returns a session for region. Credentials are loaded from the named profile of the shared configuration
+// files when profile is provided, otherwise from the default locations. Every AWS API call made through the session
+// is bound to ctx, so that the calls are abandoned once the deadline of --timeout is exceeded.
+func awsSession(ctx context.Context, region, profile string) (*session.Session, error) {
 	cfg := awssdk.Config{
 		Region: awssdk.String(region),
+		// Record a span for every AWS API call when tracing is enabled
+		HTTPClient: provisioning.NewTracingHTTPClient(),
+		Retryer:    newRetryer(provisioning.EffectiveRetryPolicy(defaultRetryPolicy)),
 	}
 
-	return session.NewSessionWithOptions(session.Options{
+	s, err := session.NewSessionWithOptions(session.Options{
 		Config:            cfg,
 		SharedConfigState: session.SharedConfigEnable,
+		Profile:           profile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Bind every AWS API call to ctx, the clients of the session do not take a context
+	s.Handlers.Build.PushFrontNamed(request.NamedHandler{
+		Name: "openshift.io/ccoctl/context",
+		Fn:   func(r *request.Request) { r.SetContext(ctx) },
+	})
+	// Identify ccoctl in the User-Agent of every AWS API call, after the User-Agent of the SDK
+	s.Handlers.Build.PushBackNamed(request.NamedHandler{
+		Name: "openshift.io/ccoctl",
+		Fn:   request.MakeAddToUserAgentFreeFormHandler(provisioning.UserAgent()),
 	})
+	if provisioning.CloudRequestDumpEnabled() {
+		s.Handlers.Send.PushBackNamed(request.NamedHandler{
+			Name: "openshift.io/ccoctl/dump-cloud-requests",
+			Fn:   dumpCloudRequest,
+		})
+	}
+	provisioning.RegisterAuditIdentity("aws", func() string {
+		return resolvedIdentity(s)["callerIdentityARN"]
+	})
+	return s, nil
+}
+
+// dumpCloudRequest writes the attempt of the AWS API request r which was just sent, and its response, to the file of
+// --dump-cloud-requests. It runs after the request was sent, including when sending it failed.
+func dumpCloudRequest(r *request.Request) {
+	var body []byte
+	if r.Body != nil {
+		// The body is rewound to where the SDK expects it, in case the request is retried
+		if _, err := r.Body.Seek(r.BodyStart, io.SeekStart); err == nil {
+			body, _ = io.ReadAll(r.Body)
+			r.Body.Seek(r.BodyStart, io.SeekStart)
+		}
+	}
+	var err error
+	if r.HTTPResponse == nil {
+		err = r.Error
+	}
+	provisioning.DumpHTTPRequest("aws", r.HTTPRequest, body, r.HTTPResponse, err, time.Since(r.AttemptTime))
+}
+
+// newRetryer returns the retryer of AWS API calls following retryPolicy. Calls failing with a server error and
+// throttled calls are retried with the same delays.
+func newRetryer(retryPolicy provisioning.RetryPolicy) client.DefaultRetryer {
+	return client.DefaultRetryer{
+		NumMaxRetries:    retryPolicy.MaxRetries,
+		MinRetryDelay:    retryPolicy.BaseDelay,
+		MaxRetryDelay:    retryPolicy.MaxDelay,
+		MinThrottleDelay: retryPolicy.BaseDelay,
+		MaxThrottleDelay: retryPolicy.MaxDelay,
+	}
+}
+
+// resolvedIdentity returns the identity with which AWS API calls are made within the session s, which is written by
+// --dump-config. No identity is returned when it can't be resolved.
+func resolvedIdentity(s *session.Session) map[string]string {
+	output, err := sts.New(s).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		provisioning.Warnf("Failed to resolve the AWS caller identity: %s", err)
+		return map[string]string{}
+	}
+	return map[string]string{
+		"callerIdentityARN": awssdk.StringValue(output.Arn),
+		"accountID":         awssdk.StringValue(output.Account),
+	}
 }
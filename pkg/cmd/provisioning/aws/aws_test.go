@@ -0,0 +1,40 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSSessionTimeout(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	// The endpoint does not respond until the test completes, as an unresponsive endpoint would
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s, err := awsSession(ctx, "us-east-1", "")
+	require.NoError(t, err, "unexpected error creating session")
+
+	_, err = iam.New(s, &awssdk.Config{Endpoint: awssdk.String(server.URL)}).GetUser(&iam.GetUserInput{})
+	require.Error(t, err, "expected error")
+	var awsErr awserr.Error
+	require.ErrorAs(t, err, &awsErr, "expected an AWS error")
+	assert.Equal(t, request.CanceledErrorCode, awsErr.Code(), "expected the call to be canceled")
+	assert.ErrorIs(t, awsErr.OrigErr(), context.DeadlineExceeded, "expected the call to be abandoned once the deadline passed")
+}
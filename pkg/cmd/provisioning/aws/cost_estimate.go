@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// createAllResourceCounts returns the number of resources of each type created by ccoctl aws create-all for the
+// CredentialsRequests within credReqDirs. Resources which already exist, eg. a shared IAM Identity Provider, are counted.
+func createAllResourceCounts(credReqDirs []string, enableTechPreview, createPrivateS3Bucket bool) (map[string]int, error) {
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	counts := map[string]int{
+		s3BucketInventoryResourceType:         1,
+		identityProviderInventoryResourceType: 1,
+		iamRoleInventoryResourceType:          len(credReqs),
+	}
+	if createPrivateS3Bucket {
+		counts["CloudFrontDistribution"] = 1
+		counts["CloudFrontOriginAccessIdentity"] = 1
+	}
+	return counts, nil
+}
@@ -0,0 +1,22 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAllResourceCounts(t *testing.T) {
+	credReqDir := t.TempDir()
+	require.NoError(t, testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false))
+	require.NoError(t, testCredentialsRequest(t, "secondcredreq", "namespace2", "secretName2", credReqDir, false))
+
+	counts, err := createAllResourceCounts([]string{credReqDir}, false, false)
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string]int{"S3Bucket": 1, "IAMIdentityProvider": 1, "IAMRole": 2}, counts)
+
+	counts, err = createAllResourceCounts([]string{credReqDir}, false, true)
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string]int{"S3Bucket": 1, "IAMIdentityProvider": 1, "IAMRole": 2, "CloudFrontDistribution": 1, "CloudFrontOriginAccessIdentity": 1}, counts)
+}
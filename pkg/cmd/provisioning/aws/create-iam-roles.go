@@ -1,15 +1,20 @@
 package aws
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -32,155 +37,412 @@ stringData:
     web_identity_token_file = %s
 kind: Secret
 metadata:
-  name: %s
+  annotations:
+%s  name: %s
   namespace: %s
 type: Opaque`
 
+	// iamRoleNameAnnotation is the annotation applied to the secret manifests generated with --dry-run which records
+	// the name of the IAM Role that would have been created
+	iamRoleNameAnnotation = "ccoctl.openshift.io/iam-role-name"
+
 	// Generated role files
 	roleFilenameFormat       = "05-%d-%s-role.json"
 	rolePolicyFilenameFormat = "06-%d-%s-policy.json"
 	// fileModeCcoctlDryRun represents a mode and permission bits of the files created by ccoctl in dry run
 	fileModeCcoctlDryRun = 0644
+
+	// defaultMaxSessionDuration is the AWS default maximum session duration (in seconds) for a role
+	defaultMaxSessionDuration = 3600
+	// minMaxSessionDuration and maxMaxSessionDuration are the bounds (in seconds) accepted by AWS
+	// for the maximum session duration of a role
+	minMaxSessionDuration = 3600
+	maxMaxSessionDuration = 43200
+
+	// maxSessionDurationInventorySetting is the inventory setting recording the maximum session duration of created roles
+	maxSessionDurationInventorySetting = "maxSessionDuration"
+	// policyStyleInventorySetting is the inventory setting recording the --policy-style of created roles, the style
+	// applied to each role is recorded by the type of the policies within the inventory step of the role
+	policyStyleInventorySetting = "policyStyle"
+	// iamRoleStepPrefix prefixes the inventory step recorded for the IAM Role of each CredentialsRequest
+	iamRoleStepPrefix            = "create-iam-role/"
+	iamRoleInventoryResourceType = "IAMRole"
+
+	// maxRoleNameLength is the maximum length of the name of an IAM Role
+	maxRoleNameLength = 64
+	// iamRolesSummaryKey and iamRolesQuotaSummaryKey are the keys of the account summary reporting
+	// the number of IAM Roles in the account and the quota of IAM Roles per account
+	iamRolesSummaryKey      = "Roles"
+	iamRolesQuotaSummaryKey = "RolesQuota"
 )
 
 var (
 	// CreateIAMRolesOpts captures the options that affect creation/updating
 	// of the IAM Roles.
 	CreateIAMRolesOpts = options{
-		TargetDir:         "",
-		EnableTechPreview: false,
+		TargetDir:          "",
+		EnableTechPreview:  false,
+		MaxSessionDuration: defaultMaxSessionDuration,
+		PolicyStyle:        policyStyleAuto,
+	}
+
+	// iamRoleNameConstraints are the constraints of IAM on the names of IAM Roles
+	iamRoleNameConstraints = provisioning.NameConstraints{
+		MaxLength:   maxRoleNameLength,
+		Pattern:     regexp.MustCompile(`^[\w+=,.@-]+$`),
+		Description: "alphanumeric characters or any of _+=,.@-",
+	}
+
+	// iamRoleDescriptionConstraints are the constraints of IAM on the descriptions of IAM Roles
+	iamRoleDescriptionConstraints = provisioning.DescriptionConstraints{
+		MaxLength:   1000,
+		Pattern:     regexp.MustCompile(`^[\t\n\r\x{20}-\x{7E}\x{A1}-\x{FF}]*$`),
+		Description: "printable Latin-1 characters, tabs or line breaks",
 	}
 )
 
-func createIAMRoles(client aws.Client, identityProviderARN, PermissionsBoundaryARN, name, credReqDir, targetDir string, enableTechPreview, generateOnly bool) error {
+// validateMaxSessionDuration ensures that the maximum session duration (in seconds) is within the range accepted by AWS
+func validateMaxSessionDuration(maxSessionDuration int64) error {
+	if maxSessionDuration < minMaxSessionDuration || maxSessionDuration > maxMaxSessionDuration {
+		return fmt.Errorf("max session duration must be between %d and %d seconds, got %d", minMaxSessionDuration, maxMaxSessionDuration, maxSessionDuration)
+	}
+	return nil
+}
+
+// loadOrCreateInventory returns the inventory previously persisted within targetDir for the named
+// AWS resources or a new inventory if none was found
+func loadOrCreateInventory(targetDir, name string) *provisioning.Inventory {
+	inventory, err := provisioning.LoadInventory(targetDir)
+	if err != nil || inventory.Provider != "aws" || inventory.Name != name {
+		return provisioning.NewInventory(targetDir, "aws", name)
+	}
+	return inventory
+}
+
+// iamRoleName returns the name of the IAM Role for credReq, rendered from the --name-template of IAM Roles when
+// provided and derived from name otherwise
+func iamRoleName(name string, credReq *credreqv1.CredentialsRequest) (string, error) {
+	return provisioning.ComponentName(provisioning.NameTemplateAWSIAMRole, name, credReq, iamRoleNameConstraints, defaultIAMRoleName(name, credReq))
+}
+
+// iamRoleDescription returns the description of the IAM Role for credReq, rendered from --resource-description-template
+// when provided
+func iamRoleDescription(name string, credReq *credreqv1.CredentialsRequest) (string, error) {
+	return provisioning.ComponentDescription(name, credReq, iamRoleDescriptionConstraints,
+		fmt.Sprintf("OpenShift role for %s/%s", credReq.Spec.SecretRef.Namespace, credReq.Spec.SecretRef.Name))
+}
+
+// defaultIAMRoleName returns the name of the IAM Role for credReq derived from name, "name-targetNamespace-targetSecretName",
+// shortened to the maximum length of IAM Role names
+func defaultIAMRoleName(name string, credReq *credreqv1.CredentialsRequest) string {
+	roleName := fmt.Sprintf("%s-%s-%s", name, credReq.Spec.SecretRef.Namespace, credReq.Spec.SecretRef.Name)
+	if len(roleName) > maxRoleNameLength {
+		return roleName[0:maxRoleNameLength]
+	}
+	return roleName
+}
+
+// checkIAMRoleQuota ensures that the IAM Roles quota of the account allows creating the IAM Roles which do not exist
+// yet for the CredentialsRequests within credReqDirs. The check is best-effort and is skipped when the account summary
+// or existing IAM Roles cannot be read.
+func checkIAMRoleQuota(client aws.Client, name string, credReqDirs []string, enableTechPreview bool) error {
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+
+	summary, err := client.GetAccountSummary(&iam.GetAccountSummaryInput{})
+	if err != nil {
+		provisioning.Warnf("Skipping IAM Role quota check, failed to get the account summary: %s", err)
+		return nil
+	}
+	usage, limit := summary.SummaryMap[iamRolesSummaryKey], summary.SummaryMap[iamRolesQuotaSummaryKey]
+	if usage == nil || limit == nil {
+		provisioning.Warnf("Skipping IAM Role quota check, the account summary does not report the IAM Roles quota")
+		return nil
+	}
+
+	required := 0
+	for _, cr := range credRequests {
+		roleName, err := iamRoleName(name, cr)
+		if err != nil {
+			return err
+		}
+		_, err = client.GetRole(&iam.GetRoleInput{
+			RoleName: awssdk.String(roleName),
+		})
+		if err == nil {
+			continue
+		}
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+			provisioning.Warnf("Skipping IAM Role quota check, failed to get IAM Role: %s", err)
+			return nil
+		}
+		required++
+	}
+
+	log.Printf("%d of %d IAM Roles allowed in the account are in use, %d IAM Roles will be created", *usage, *limit, required)
+	return provisioning.CheckQuota(provisioning.Quota{
+		Name:  "IAM Roles per account",
+		Usage: int(*usage),
+		Limit: int(*limit),
+	}, required)
+}
+
+// createIAMRoles creates the IAM Roles for the CredentialsRequests within credReqDirs. When onlyMissing is set, the
+// CredentialsRequests whose IAM Role already exists are skipped, leaving the IAM Role and its policies untouched.
+func createIAMRoles(client aws.Client, identityProviderARN, PermissionsBoundaryARN, name, clusterID string, credReqDirs []string, targetDir string, maxSessionDuration int64, policyStyle string, enableTechPreview, generateOnly, onlyMissing bool, inventory *provisioning.Inventory) error {
+	if err := validateMaxSessionDuration(maxSessionDuration); err != nil {
+		return err
+	}
+	if err := validatePolicyStyle(policyStyle); err != nil {
+		return err
+	}
+
 	// Process directory
-	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDir, enableTechPreview)
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
 
+	if onlyMissing {
+		credRequests, err = credentialsRequestsWithoutIAMRole(client, name, credRequests)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create IAM Roles (with policies)
-	if err := processCredentialsRequests(client, credRequests, identityProviderARN, PermissionsBoundaryARN, name, targetDir, generateOnly); err != nil {
+	if err := processCredentialsRequests(client, credRequests, identityProviderARN, PermissionsBoundaryARN, name, clusterID, targetDir, maxSessionDuration, policyStyle, generateOnly, inventory); err != nil {
 		return errors.Wrap(err, "Failed while processing each CredentialsRequest")
 	}
 
+	if generateOnly {
+		log.Printf("Dry run complete, no AWS resources were created or modified. Manifests which would have been applied were saved to %s", filepath.Join(targetDir, provisioning.ManifestsDirName))
+	}
+
 	return nil
 }
 
-func processCredentialsRequests(awsClient aws.Client, credReqs []*credreqv1.CredentialsRequest, identityProviderARN, PermissionsBoundaryARN, name, targetDir string, generateOnly bool) error {
+// credentialsRequestsWithoutIAMRole returns the CredentialsRequests of credReqs for which no IAM Role exists, logging
+// the CredentialsRequests skipped as their IAM Role carrying ccoctl's "owned" tag for name exists. An error is returned
+// when an IAM Role named after a CredentialsRequest exists without the tag, as it was not created by ccoctl for name
+// and may be neither created nor adopted.
+func credentialsRequestsWithoutIAMRole(client aws.Client, name string, credReqs []*credreqv1.CredentialsRequest) ([]*credreqv1.CredentialsRequest, error) {
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)
+	missing := []*credreqv1.CredentialsRequest{}
+	for _, cr := range credReqs {
+		roleName, err := iamRoleName(name, cr)
+		if err != nil {
+			return nil, err
+		}
+		roleOutput, err := client.GetRole(&iam.GetRoleInput{
+			RoleName: awssdk.String(roleName),
+		})
+		if err != nil {
+			var aerr awserr.Error
+			if errors.As(err, &aerr) && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+				log.Printf("Creating IAM Role %s for CredentialsRequest %s/%s which has no IAM Role", roleName, cr.Namespace, cr.Name)
+				missing = append(missing, cr)
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to fetch IAM Role %s", roleName)
+		}
+		if iamTagMap(roleOutput.Role.Tags)[ownedTagKey] != ownedCcoctlAWSResourceTagValue {
+			return nil, fmt.Errorf("IAM Role %s of CredentialsRequest %s/%s exists but does not carry the tag %s=%s, it was not created by ccoctl for %s",
+				roleName, cr.Namespace, cr.Name, ownedTagKey, ownedCcoctlAWSResourceTagValue, name)
+		}
+		log.Printf("Skipping CredentialsRequest %s/%s, IAM Role %s already exists", cr.Namespace, cr.Name, roleName)
+	}
+	log.Printf("Creating IAM Roles for %d CredentialsRequests, skipped %d CredentialsRequests whose IAM Roles exist", len(missing), len(credReqs)-len(missing))
+	return missing, nil
+}
+
+func processCredentialsRequests(awsClient aws.Client, credReqs []*credreqv1.CredentialsRequest, identityProviderARN, PermissionsBoundaryARN, name, clusterID, targetDir string, maxSessionDuration int64, policyStyle string, generateOnly bool, inventory *provisioning.Inventory) error {
 
 	issuerURL, err := getIssuerURLFromIdentityProvider(awsClient, identityProviderARN)
 	if err != nil {
 		return err
 	}
 
+	// The trust policies depend on the issuer URL, they are validated before any IAM Role is created
+	if err := validateRolePolicyLimits(credReqs, name, identityProviderARN, issuerURL, policyStyle); err != nil {
+		return err
+	}
+	for _, cr := range credReqs {
+		if _, err := iamRoleDescription(name, cr); err != nil {
+			return err
+		}
+	}
+
+	if inventory != nil && !generateOnly {
+		inventory.Settings[maxSessionDurationInventorySetting] = strconv.FormatInt(maxSessionDuration, 10)
+		inventory.Settings[policyStyleInventorySetting] = policyStyle
+		if err := inventory.Save(); err != nil {
+			return err
+		}
+	}
+
 	for i, cr := range credReqs {
+		step := iamRoleStepPrefix + cr.Spec.SecretRef.Namespace + "/" + cr.Spec.SecretRef.Name
+		provisioning.SetPhase(fmt.Sprintf("creating the IAM Role for %s/%s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name))
+
+		// The policies recorded for the IAM Role by a previous run are replaced by the policies created by this run
+		var previousPolicies []provisioning.InventoryResource
+		if inventory != nil {
+			if previousStep, found := inventory.Step(step); found {
+				previousPolicies = previousStep.Resources
+			}
+		}
+
 		// infraName-targetNamespace-targetSecretName
-		_, err = createRole(awsClient, name, cr, i, identityProviderARN, issuerURL, PermissionsBoundaryARN, targetDir, generateOnly)
+		roleARN, policies, err := createRole(awsClient, name, clusterID, cr, i, identityProviderARN, issuerURL, PermissionsBoundaryARN, targetDir, maxSessionDuration, policyStyle, previousPolicies, generateOnly)
 		if err != nil {
 			return err
 		}
 
+		if inventory != nil && !generateOnly {
+			roleName, err := iamRoleName(name, cr)
+			if err != nil {
+				return err
+			}
+			resources := append([]provisioning.InventoryResource{{
+				Type: iamRoleInventoryResourceType,
+				Name: roleName,
+				ID:   roleARN,
+			}}, policies...)
+			if err := inventory.CompleteStep(step, resources...); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-func createRole(awsClient aws.Client, name string, credReq *credreqv1.CredentialsRequest, roleNum int, oidcProviderARN, issuerURL, PermissionsBoundaryARN, targetDir string, generateOnly bool) (string, error) {
-	roleName := fmt.Sprintf("%s-%s-%s", name, credReq.Spec.SecretRef.Namespace, credReq.Spec.SecretRef.Name)
-
+// createRole creates the IAM Role for credReq, or updates the existing IAM Role, granting the permissions of credReq
+// with policies of policyStyle. The ARN of the IAM Role is returned along with the policies granting its permissions,
+// which replace the previously created policies previousPolicies.
+func createRole(awsClient aws.Client, name, clusterID string, credReq *credreqv1.CredentialsRequest, roleNum int, oidcProviderARN, issuerURL, PermissionsBoundaryARN, targetDir string, maxSessionDuration int64, policyStyle string, previousPolicies []provisioning.InventoryResource, generateOnly bool) (string, []provisioning.InventoryResource, error) {
 	// Decode AWSProviderSpec
 	codec, err := credreqv1.NewCodec()
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to create credReq codec")
+		return "", nil, errors.Wrap(err, "Failed to create credReq codec")
 	}
 
 	awsProviderSpec := credreqv1.AWSProviderSpec{}
 	if err := codec.DecodeProviderSpec(credReq.Spec.ProviderSpec, &awsProviderSpec); err != nil {
-		return "", errors.Wrap(err, "Failed to decode the provider spec")
+		return "", nil, errors.Wrap(err, "Failed to decode the provider spec")
 	}
 
 	if awsProviderSpec.Kind != "AWSProviderSpec" {
-		return "", fmt.Errorf("CredentialsRequest %s/%s is not of type AWS", credReq.Namespace, credReq.Name)
+		return "", nil, fmt.Errorf("CredentialsRequest %s/%s is not of type AWS", credReq.Namespace, credReq.Name)
 	}
 
 	// Ensure role name is no longer than 64 charactters
-	var shortenedRoleName string
-	if len(roleName) > 64 {
-		shortenedRoleName = roleName[0:64]
-	} else {
-		shortenedRoleName = roleName
+	roleName, err := iamRoleName(name, credReq)
+	if err != nil {
+		return "", nil, err
 	}
 
 	rolePolicyDocument, err := createRolePolicyDocument(oidcProviderARN, issuerURL, credReq.Spec.SecretRef.Namespace, credReq.Spec.ServiceAccountNames)
 	if err != nil {
-		return "", errors.Wrapf(err, "error while creating Role policy document for %s", credReq.Name)
+		return "", nil, errors.Wrapf(err, "error while creating Role policy document for %s", credReq.Name)
+	}
+
+	roleDescription, err := iamRoleDescription(name, credReq)
+	if err != nil {
+		return "", nil, err
 	}
+	// roleLabel names the IAM Role within logs whatever its description
+	roleLabel := fmt.Sprintf("OpenShift role for %s/%s", credReq.Spec.SecretRef.Namespace, credReq.Spec.SecretRef.Name)
 
-	roleDescription := fmt.Sprintf("OpenShift role for %s/%s", credReq.Spec.SecretRef.Namespace, credReq.Spec.SecretRef.Name)
+	_, span := provisioning.StartSpan(context.Background(), "aws.EnsureIAMRole",
+		provisioning.ProviderAttribute.String("aws"),
+		provisioning.ResourceTypeAttribute.String("iamRole"),
+		provisioning.ResourceNameAttribute.String(roleName),
+	)
+	defer span.End()
 
-	rolePolicy := createRolePolicy(awsProviderSpec.StatementEntries)
+	appliedPolicyStyle, rolePolicies, err := planRolePolicies(roleName, policyStyle, awsProviderSpec.StatementEntries)
+	if err != nil {
+		return "", nil, err
+	}
 
 	switch generateOnly {
 	case true:
 		// Generate Role
 		// Generated JSON must be valid input for AWS IAM CreateRole API
 		roleTemplate := map[string]interface{}{
-			"RoleName":                 shortenedRoleName,
+			"RoleName":                 roleName,
 			"Description":              roleDescription,
 			"AssumeRolePolicyDocument": rolePolicyDocument,
-			"Tags": []map[string]string{
-				{
-					"Key":   fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name),
-					"Value": ownedCcoctlAWSResourceTagValue,
-				},
-				{
-					"Key":   nameTagKey,
-					"Value": name,
-				},
-			},
+			"MaxSessionDuration":       maxSessionDuration,
+			"Tags":                     roleTags(name, clusterID, credReq),
 		}
 		if PermissionsBoundaryARN != "" {
 			roleTemplate["PermissionsBoundary"] = PermissionsBoundaryARN
 		}
 		roleJSON, err := json.Marshal(&roleTemplate)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to convert Role to JSON")
+			return "", nil, errors.Wrap(err, "failed to convert Role to JSON")
 		}
 		roleFilename := fmt.Sprintf(roleFilenameFormat, roleNum, roleName)
 		roleFullPath := filepath.Join(targetDir, roleFilename)
-		log.Printf("Saving %s locally at %s", roleDescription, roleFullPath)
+		log.Printf("Saving %s locally at %s", roleLabel, roleFullPath)
 		if err := ioutil.WriteFile(roleFullPath, roleJSON, fileModeCcoctlDryRun); err != nil {
-			return "", errors.Wrap(err, fmt.Sprintf("Failed to save %s locally at %s", roleDescription, roleFullPath))
+			return "", nil, errors.Wrap(err, fmt.Sprintf("Failed to save %s locally at %s", roleLabel, roleFullPath))
 		}
 
-		// Generate Role Policy
-		// Generated JSON must be valid input for AWS IAM PutRolePolicy API
-		rolePolicyTemplate := map[string]string{
-			"PolicyDocument": rolePolicy,
-			"PolicyName":     shortenedRoleName,
-			"RoleName":       shortenedRoleName,
-		}
-		rolePolicyJSON, err := json.Marshal(&rolePolicyTemplate)
-		if err != nil {
-			return "", errors.Wrap(err, "failed to convert Role Policy to JSON")
-		}
-		rolePolicyFilename := fmt.Sprintf(rolePolicyFilenameFormat, roleNum, roleName)
-		rolePolicyFullPath := filepath.Join(targetDir, rolePolicyFilename)
-		log.Printf("Saving policy for %s locally at %s", roleDescription, rolePolicyFullPath)
-		if err := ioutil.WriteFile(rolePolicyFullPath, rolePolicyJSON, fileModeCcoctlDryRun); err != nil {
-			return "", errors.Wrap(err, fmt.Sprintf("Failed to save policy for %s locally at %s", roleDescription, rolePolicyFullPath))
+		if appliedPolicyStyle == policyStyleManaged {
+			// Generate managed policies
+			// Generated JSON must be valid input for AWS IAM CreatePolicy API, the policies are to be attached to the Role
+			for i, document := range rolePolicies {
+				managedPolicyTemplate := map[string]interface{}{
+					"PolicyDocument": document,
+					"PolicyName":     managedPolicyName(roleName, i, len(rolePolicies)),
+					"Tags":           resourceTags(name, clusterID),
+				}
+				managedPolicyJSON, err := json.Marshal(&managedPolicyTemplate)
+				if err != nil {
+					return "", nil, errors.Wrap(err, "failed to convert managed policy to JSON")
+				}
+				managedPolicyFullPath := filepath.Join(targetDir, fmt.Sprintf(managedPolicyFilenameFormat, roleNum, roleName, i+1))
+				log.Printf("Saving managed policy for %s locally at %s", roleLabel, managedPolicyFullPath)
+				if err := ioutil.WriteFile(managedPolicyFullPath, managedPolicyJSON, fileModeCcoctlDryRun); err != nil {
+					return "", nil, errors.Wrap(err, fmt.Sprintf("Failed to save managed policy for %s locally at %s", roleLabel, managedPolicyFullPath))
+				}
+			}
+		} else {
+			// Generate Role Policy
+			// Generated JSON must be valid input for AWS IAM PutRolePolicy API
+			rolePolicyTemplate := map[string]string{
+				"PolicyDocument": rolePolicies[0],
+				"PolicyName":     roleName,
+				"RoleName":       roleName,
+			}
+			rolePolicyJSON, err := json.Marshal(&rolePolicyTemplate)
+			if err != nil {
+				return "", nil, errors.Wrap(err, "failed to convert Role Policy to JSON")
+			}
+			rolePolicyFilename := fmt.Sprintf(rolePolicyFilenameFormat, roleNum, roleName)
+			rolePolicyFullPath := filepath.Join(targetDir, rolePolicyFilename)
+			log.Printf("Saving policy for %s locally at %s", roleLabel, rolePolicyFullPath)
+			if err := ioutil.WriteFile(rolePolicyFullPath, rolePolicyJSON, fileModeCcoctlDryRun); err != nil {
+				return "", nil, errors.Wrap(err, fmt.Sprintf("Failed to save policy for %s locally at %s", roleLabel, rolePolicyFullPath))
+			}
 		}
 
-		if err := writeCredReqSecret(credReq, targetDir, ""); err != nil {
-			return "", errors.Wrap(err, "failed to save Secret for install manifests")
+		if err := writeDryRunCredReqSecret(credReq, targetDir, roleName, issuerURL); err != nil {
+			return "", nil, errors.Wrap(err, "failed to save Secret for install manifests")
 		}
 
-		return "", nil
+		return "", nil, nil
 
 	default:
 		var role *iam.Role
 		outRole, err := awsClient.GetRole(&iam.GetRoleInput{
-			RoleName: awssdk.String(shortenedRoleName),
+			RoleName: awssdk.String(roleName),
 		})
 
 		if err != nil {
@@ -190,56 +452,60 @@ func createRole(awsClient aws.Client, name string, credReq *credreqv1.Credential
 				case iam.ErrCodeNoSuchEntityException:
 
 					roleInput := &iam.CreateRoleInput{
-						RoleName:                 awssdk.String(shortenedRoleName),
+						RoleName:                 awssdk.String(roleName),
 						Description:              awssdk.String(roleDescription),
 						AssumeRolePolicyDocument: awssdk.String(rolePolicyDocument),
-						Tags: []*iam.Tag{
-							{
-								Key:   awssdk.String(fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)),
-								Value: awssdk.String(ownedCcoctlAWSResourceTagValue),
-							},
-							{
-								Key:   awssdk.String(nameTagKey),
-								Value: awssdk.String(name),
-							},
-						},
+						MaxSessionDuration:       awssdk.Int64(maxSessionDuration),
+						Tags:                     iamTags(roleTags(name, clusterID, credReq)),
 					}
 					if PermissionsBoundaryARN != "" {
 						roleInput.PermissionsBoundary = awssdk.String(PermissionsBoundaryARN)
 					}
 					roleOutput, err := awsClient.CreateRole(roleInput)
 					if err != nil {
-						return "", errors.Wrap(err, "Failed to create role")
+						return "", nil, errors.Wrap(err, "Failed to create role")
 					}
 
 					role = roleOutput.Role
 					log.Printf("Role %s created", *role.Arn)
+					provisioning.EmitResourceEvent(iamRoleInventoryResourceType, *role.Arn, provisioning.ResourceCreated)
 
-					if err := writeCredReqSecret(credReq, targetDir, *role.Arn); err != nil {
-						return "", errors.Wrap(err, "failed to save Secret for install manifests")
+					if err := writeCredReqSecret(credReq, targetDir, *role.Arn, issuerURL); err != nil {
+						return "", nil, errors.Wrap(err, "failed to save Secret for install manifests")
 					}
 
 				default:
-					return "", err
+					return "", nil, err
 				}
 
 			}
 		} else {
 			role = outRole.Role
 			log.Printf("Existing role %s found", *role.Arn)
+			if role.MaxSessionDuration != nil && *role.MaxSessionDuration != maxSessionDuration {
+				_, err := awsClient.UpdateRole(&iam.UpdateRoleInput{
+					RoleName:           awssdk.String(roleName),
+					MaxSessionDuration: awssdk.Int64(maxSessionDuration),
+				})
+				if err != nil {
+					return "", nil, errors.Wrapf(err, "failed to update the max session duration of role %s", *role.Arn)
+				}
+				log.Printf("Updated the max session duration of role %s from %d to %d seconds", *role.Arn, *role.MaxSessionDuration, maxSessionDuration)
+				role.MaxSessionDuration = awssdk.Int64(maxSessionDuration)
+			}
 		}
 
-		_, err = awsClient.PutRolePolicy(&iam.PutRolePolicyInput{
-			PolicyName:     awssdk.String(shortenedRoleName),
-			RoleName:       role.RoleName,
-			PolicyDocument: awssdk.String(rolePolicy),
-		})
+		var policies []provisioning.InventoryResource
+		if appliedPolicyStyle == policyStyleManaged {
+			policies, err = putManagedRolePolicies(awsClient, role, roleName, rolePolicies, iamTags(resourceTags(name, clusterID)), previousPolicies)
+		} else {
+			policies, err = putInlineRolePolicy(awsClient, role, roleName, rolePolicies[0], previousPolicies)
+		}
 		if err != nil {
-			return "", errors.Wrap(err, "Failed to put role policy")
+			return "", nil, err
 		}
-		log.Printf("Updated Role policy for Role %s", *role.RoleName)
 
-		return *role.Arn, nil
+		return *role.Arn, policies, nil
 	}
 }
 
@@ -283,18 +549,41 @@ func getIssuerURLFromIdentityProvider(awsClient aws.Client, idProviderARN string
 }
 
 func createIAMRolesCmd(cmd *cobra.Command, args []string) {
-	s, err := awsSession(CreateIAMRolesOpts.Region)
+	s, err := awsSession(cmd.Context(), CreateIAMRolesOpts.Region, "")
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	awsClient := aws.NewClientFromSession(s)
 
-	err = createIAMRoles(awsClient, CreateIAMRolesOpts.IdentityProviderARN, CreateIAMRolesOpts.PermissionsBoundaryARN, CreateIAMRolesOpts.Name,
-		CreateIAMRolesOpts.CredRequestDir, CreateIAMRolesOpts.TargetDir, CreateIAMRolesOpts.EnableTechPreview, CreateIAMRolesOpts.DryRun)
+	if !CreateIAMRolesOpts.DryRun && !CreateIAMRolesOpts.SkipQuotaCheck {
+		if err := checkIAMRoleQuota(awsClient, CreateIAMRolesOpts.Name, CreateIAMRolesOpts.CredRequestDirs, CreateIAMRolesOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if !CreateIAMRolesOpts.DryRun && !CreateIAMRolesOpts.SkipSTSEndpointCheck {
+		if err := checkSTSEndpoint(s, awsClient, CreateIAMRolesOpts.Region, CreateIAMRolesOpts.IdentityProviderARN); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var inventory *provisioning.Inventory
+	if !CreateIAMRolesOpts.DryRun {
+		inventory = loadOrCreateInventory(CreateIAMRolesOpts.TargetDir, CreateIAMRolesOpts.Name)
+	}
+
+	err = createIAMRoles(awsClient, CreateIAMRolesOpts.IdentityProviderARN, CreateIAMRolesOpts.PermissionsBoundaryARN, CreateIAMRolesOpts.Name, CreateIAMRolesOpts.ClusterID,
+		CreateIAMRolesOpts.CredRequestDirs, CreateIAMRolesOpts.TargetDir, CreateIAMRolesOpts.MaxSessionDuration, CreateIAMRolesOpts.PolicyStyle, CreateIAMRolesOpts.EnableTechPreview, CreateIAMRolesOpts.DryRun, CreateIAMRolesOpts.OnlyMissing, inventory)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if CreateIAMRolesOpts.OutputImport && inventory != nil {
+		if err := writeTerraformImports(inventory); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 // StatementEntry is a simple type used to serialize to AWS' PolicyDocument format.
@@ -337,28 +626,71 @@ func createRolePolicy(statements []credreqv1.StatementEntry) string {
 	return string(b)
 }
 
+// ValidateProviderSpec validates the statement entries of an AWSProviderSpec, from which the policy of the
+// CredentialsRequest's IAM role is created, without contacting AWS. Errors are identified by their path below fldPath.
+func ValidateProviderSpec(spec *credreqv1.AWSProviderSpec, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	entriesPath := fldPath.Child("statementEntries")
+	if len(spec.StatementEntries) == 0 {
+		errs = append(errs, field.Required(entriesPath, "the permissions to grant"))
+	}
+	for i, entry := range spec.StatementEntries {
+		entryPath := entriesPath.Index(i)
+		if entry.Effect != "Allow" && entry.Effect != "Deny" {
+			errs = append(errs, field.NotSupported(entryPath.Child("effect"), entry.Effect, []string{"Allow", "Deny"}))
+		}
+		if len(entry.Action) == 0 {
+			errs = append(errs, field.Required(entryPath.Child("action"), ""))
+		}
+		for j, action := range entry.Action {
+			if action != "*" && !strings.Contains(action, ":") {
+				errs = append(errs, field.Invalid(entryPath.Child("action").Index(j), action, "actions must be \"*\" or of the form <service>:<action>"))
+			}
+		}
+		if entry.Resource == "" {
+			errs = append(errs, field.Required(entryPath.Child("resource"), ""))
+		}
+	}
+	return errs
+}
+
 // writeCredReqSecret will take a credentialsRequest and a Role ARN and store
 // a Secret with an AWS config in the 'credentials' field of the Secret.
-func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, roleARN string) error {
+func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, roleARN, issuerURL string) error {
 	manifestsDir := filepath.Join(targetDir, provisioning.ManifestsDirName)
 
 	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
 	filePath := filepath.Join(manifestsDir, fileName)
 
-	fileData := fmt.Sprintf(secretManifestsTemplate, roleARN, provisioning.OidcTokenPath, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
+	fileData := fmt.Sprintf(secretManifestsTemplate, roleARN, provisioning.OidcTokenPath, provisioning.ProvenanceAnnotations(cr, issuerURL), cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
 
-	// roleARN would be an empty string if ccoctl was in --dry-run mode
-	// so lets make sure we have an invalide Secret until the user
-	// has populated the Secret manually.
-	if roleARN == "" {
-		fileData = fileData + "\nPOPULATE ROLE ARN AND DELETE THIS LINE"
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
+		return errors.Wrap(err, "Failed to save Secret file")
 	}
 
-	if err := ioutil.WriteFile(filePath, []byte(fileData), 0600); err != nil {
+	log.Printf("Saved credentials configuration to: %s", filePath)
+
+	return nil
+}
+
+// writeDryRunCredReqSecret writes a secret file within the manifests directory (targetDir/manifests/) for the IAM Role
+// that would have been created with roleName. The secret is annotated as a dry-run artifact and must have its role ARN
+// populated before it can be applied.
+func writeDryRunCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, roleName, issuerURL string) error {
+	manifestsDir := filepath.Join(targetDir, provisioning.ManifestsDirName)
+
+	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+	filePath := filepath.Join(manifestsDir, fileName)
+
+	annotations := provisioning.ProvenanceAnnotations(cr, issuerURL) + provisioning.DryRunAnnotations(map[string]string{iamRoleNameAnnotation: roleName})
+	fileData := fmt.Sprintf(secretManifestsTemplate, "", provisioning.OidcTokenPath, annotations, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
+	fileData = fileData + "\nPOPULATE ROLE ARN AND DELETE THIS LINE"
+
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
 		return errors.Wrap(err, "Failed to save Secret file")
 	}
 
-	log.Printf("Saved credentials configuration to: %s", filePath)
+	log.Printf("Saved dry-run credentials configuration for IAM Role %s to: %s", roleName, filePath)
 
 	return nil
 }
@@ -366,6 +698,38 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, roleARN str
 // initEnvForCreateIAMRolesCmd will ensure the destination directory is ready to receive the generated
 // files, and will create the directory if necessary.
 func initEnvForCreateIAMRolesCmd(cmd *cobra.Command, args []string) {
+	if err := validateMaxSessionDuration(CreateIAMRolesOpts.MaxSessionDuration); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validatePolicyStyle(CreateIAMRolesOpts.PolicyStyle); err != nil {
+		log.Fatal(err)
+	}
+
+	// Report every generated policy exceeding the limits of AWS before any cloud call
+	if err := checkRolePolicyLimits(CreateIAMRolesOpts.CredRequestDirs, CreateIAMRolesOpts.Name, CreateIAMRolesOpts.IdentityProviderARN, CreateIAMRolesOpts.PolicyStyle, CreateIAMRolesOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if !CreateIAMRolesOpts.SkipDeprecatedPermissionsCheck {
+		if err := provisioning.WarnDeprecatedPermissions(CreateIAMRolesOpts.CredRequestDirs, CreateIAMRolesOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := provisioning.ValidateClusterID(CreateIAMRolesOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+
+	// The IAM Roles are global, --region is only provided within GovCloud
+	if !CreateIAMRolesOpts.SkipRegionConsistencyCheck && CreateIAMRolesOpts.Region != "" {
+		if err := checkRegionConsistency(CreateIAMRolesOpts.CredRequestDirs, "", CreateIAMRolesOpts.Name, []string{CreateIAMRolesOpts.Region}, CreateIAMRolesOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if CreateIAMRolesOpts.TargetDir == "" && CreateIAMRolesOpts.DryRun {
+		CreateIAMRolesOpts.TargetDir = provisioning.DryRunOutputDir("aws")
+	}
 	if CreateIAMRolesOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -392,6 +756,8 @@ func initEnvForCreateIAMRolesCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("failed to create manifests directory at %s", manifestsDir)
 	}
+
+	provisioning.EnsureSecretsDir(manifestsDir, CreateIAMRolesOpts.AllowInsecureSecretsDir)
 }
 
 // NewCreateIAMRolesCmd provides the "create-iam-roles" subcommand
@@ -405,15 +771,25 @@ func NewCreateIAMRolesCmd() *cobra.Command {
 
 	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.Name, "name", "", "User-define name for all created AWS resources (can be separate from the cluster's infra-id)")
 	createIAMRolesCmd.MarkPersistentFlagRequired("name")
-	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to create IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=aws' against an OpenShift release image)")
+	createIAMRolesCmd.PersistentFlags().StringSliceVar(&CreateIAMRolesOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=aws' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createIAMRolesCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.IdentityProviderARN, "identity-provider-arn", "", "ARN of IAM Identity provider for IAM Role trust relationship (can be created with the 'create identity-provider' sub-command)")
 	createIAMRolesCmd.MarkPersistentFlagRequired("identity-provider-arn")
 	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.PermissionsBoundaryARN, "permissions-boundary-arn", "", "ARN of IAM policy to use as the permissions boundary for created roles")
+	createIAMRolesCmd.PersistentFlags().Int64Var(&CreateIAMRolesOpts.MaxSessionDuration, "max-session-duration", defaultMaxSessionDuration, "Maximum session duration in seconds (between 3600 and 43200) for created roles")
+	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.PolicyStyle, "policy-style", policyStyleAuto, "Style of the policies granting the permissions of created roles: inline policies, managed policies attached to the roles, or auto to use an inline policy unless the policy exceeds the AWS size limit of inline policies")
 	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.Region, "region", "", "AWS region endpoint only required for GovCloud")
 	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
-	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
+	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory, or a temporary directory when --dry-run is specified)")
+	createIAMRolesCmd.PersistentFlags().StringVar(&CreateIAMRolesOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag created AWS resources, the resources will be tagged with 'kubernetes.io/cluster/<cluster ID> = owned'")
 	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.SkipQuotaCheck, "skip-quota-check", false, "Skip checking that the IAM Roles quota of the account allows creating the IAM Roles before creating them")
+	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.SkipDeprecatedPermissionsCheck, "skip-deprecated-permissions-check", false, "Skip warning about the permissions requested by the CredentialsRequests which are known to be deprecated by AWS")
+	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.SkipRegionConsistencyCheck, "skip-region-consistency-check", false, "Skip checking that the regions named by the CredentialsRequests, ie. the regions of the ARNs of their resources and of their aws:RequestedRegion conditions, are the region provided to --region")
+	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.SkipSTSEndpointCheck, "skip-sts-endpoint-check", false, "Skip checking that the regional STS endpoint used by the pods of the cluster is reachable and active for the account, and that the IAM Identity Provider trusts the audience of their tokens, before creating the IAM Roles")
+	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.OutputImport, "output-import", false, fmt.Sprintf("Write a terraform import command for every created IAM Role and policy to %s within the output directory, so that the resources may be brought under terraform management. Ignored with --dry-run", provisioning.TerraformImportFileName))
+	provisioning.AddAllowInsecureSecretsDirFlag(createIAMRolesCmd.PersistentFlags(), &CreateIAMRolesOpts.AllowInsecureSecretsDir)
+	createIAMRolesCmd.PersistentFlags().BoolVar(&CreateIAMRolesOpts.OnlyMissing, "only-missing", false, "Only create the IAM Roles of the CredentialsRequests which do not have an IAM Role yet, eg. of newly introduced components. Existing IAM Roles carrying the owned tag for --name are left untouched and no secret manifests are written for them")
 
 	return createIAMRolesCmd
 }
@@ -1,10 +1,12 @@
 package aws
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -14,7 +16,10 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/iam"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 )
@@ -24,6 +29,7 @@ const (
 	testPermissionsBoundaryARN = "arn:aws:iam::123456789012:policy/testing123-permissions-boundary"
 	testIdentityProviderURL    = "testing123-oidc.s3.amazonaws.com"
 	testNamePrefix             = "test-cluster1"
+	testClusterID              = "5a1c7b3e-0d6f-4c2a-9b8e-1f2d3c4b5a69"
 
 	credReqTemplate = `---
 apiVersion: cloudcredential.openshift.io/v1
@@ -76,6 +82,14 @@ func TestIAMRoles(t *testing.T) {
 		cleanup       func(*testing.T)
 		generateOnly  bool
 		expectError   bool
+		// maxSessionDuration defaults to defaultMaxSessionDuration when unset
+		maxSessionDuration int64
+		clusterID          string
+		recordInventory    bool
+		// policyStyle defaults to policyStyleAuto when unset
+		policyStyle         string
+		onlyMissing         bool
+		descriptionTemplate string
 	}{
 		{
 			name:         "No CredReqs",
@@ -125,6 +139,11 @@ func TestIAMRoles(t *testing.T) {
 				files, err = ioutil.ReadDir(manifestsDir)
 				require.NoError(t, err, "unexpected error listing files in manifestsDir")
 				assert.Equal(t, 1, provisioning.CountNonDirectoryFiles(files), "Should be exactly 1 secret in manifestsDir for one CredReq")
+
+				secretData, err := os.ReadFile(filepath.Join(manifestsDir, "namespace1-secretName1-credentials.yaml"))
+				require.NoError(t, err, "unexpected error reading dry-run secret")
+				assert.Contains(t, string(secretData), fmt.Sprintf("%s: \"true\"", provisioning.DryRunAnnotation), "Dry-run secret should be annotated as a dry-run artifact")
+				assert.Contains(t, string(secretData), fmt.Sprintf("%s: \"%s-namespace1-secretName1\"", iamRoleNameAnnotation, testNamePrefix), "Dry-run secret should record the IAM Role name")
 			},
 		},
 		{
@@ -237,6 +256,329 @@ func TestIAMRoles(t *testing.T) {
 			},
 			verify: func(t *testing.T, targetDir, manifestsDir string) {},
 		},
+		{
+			name:               "Existing Role with another max session duration updated",
+			generateOnly:       false,
+			maxSessionDuration: 43200,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				mockGetRoleExistsWithMaxSessionDuration(mockAWSClient, roleName, 3600)
+				mockUpdateRoleMaxSessionDuration(mockAWSClient, roleName, 43200)
+				mockPutRolePolicy(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:        "Only missing Roles created",
+			onlyMissing: true,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				existingRoleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				mockGetTaggedRole(mockAWSClient, existingRoleName, map[string]string{fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testNamePrefix): ownedCcoctlAWSResourceTagValue})
+				// The missing Role is looked up when filtering the CredentialsRequests and again when creating it
+				mockGetRole(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				mockCreateRole(mockAWSClient, fmt.Sprintf("%s-namespace2-secretName2", testNamePrefix))
+				mockPutRolePolicy(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				err = testCredentialsRequest(t, "secondcredreq", "namespace2", "secretName2", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {
+				assert.NoFileExists(t, filepath.Join(manifestsDir, "namespace1-secretName1-credentials.yaml"), "expected no secret for the skipped CredReq")
+				assert.FileExists(t, filepath.Join(manifestsDir, "namespace2-secretName2-credentials.yaml"), "expected a secret for the created Role")
+			},
+		},
+		{
+			name:        "Only missing refuses existing Role without the owned tag",
+			onlyMissing: true,
+			expectError: true,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetTaggedRole(mockAWSClient, fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix), map[string]string{})
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:         "Create with cluster ID tag",
+			generateOnly: false,
+			clusterID:    testClusterID,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				mockCreateRoleWithClusterTag(mockAWSClient, roleName, testClusterID)
+				mockPutRolePolicy(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:         "Generate role tagged with cluster ID",
+			generateOnly: true,
+			clusterID:    testClusterID,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {
+				roleJSON, err := ioutil.ReadFile(filepath.Join(targetDir, fmt.Sprintf(roleFilenameFormat, 0, fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix))))
+				require.NoError(t, err, "unexpected error reading generated role")
+				assert.Contains(t, string(roleJSON), fmt.Sprintf(`{"Key":"kubernetes.io/cluster/%s","Value":"owned"}`, testClusterID), "expected cluster tag in generated role")
+			},
+		},
+		{
+			name:               "Create with max session duration recorded in inventory",
+			generateOnly:       false,
+			maxSessionDuration: 43200,
+			recordInventory:    true,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				mockCreateRoleWithMaxSessionDuration(mockAWSClient, roleName, 43200)
+				mockPutRolePolicy(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {
+				inventory, err := provisioning.LoadInventory(targetDir)
+				require.NoError(t, err, "unexpected error loading inventory")
+				assert.Equal(t, "43200", inventory.Settings[maxSessionDurationInventorySetting], "unexpected max session duration recorded in inventory")
+				step, found := inventory.Step(iamRoleStepPrefix + "namespace1/secretName1")
+				require.True(t, found, "expected IAM Role step to be recorded in inventory")
+				assert.Equal(t, policyStyleAuto, inventory.Settings[policyStyleInventorySetting], "unexpected policy style recorded in inventory")
+				assert.Equal(t, []provisioning.InventoryResource{{
+					Type: iamRoleInventoryResourceType,
+					Name: fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix),
+					ID:   "test-role-arn",
+				}, {
+					Type: inlinePolicyInventoryResourceType,
+					Name: fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix),
+				}}, step.Resources)
+			},
+		},
+		{
+			name:            "Create with managed policy style recorded in inventory",
+			generateOnly:    false,
+			policyStyle:     policyStyleManaged,
+			recordInventory: true,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				mockCreateRole(mockAWSClient, roleName)
+				mockCreatePolicy(mockAWSClient, roleName)
+				mockAttachRolePolicy(mockAWSClient, roleName, "test-role-arn:policy/"+roleName)
+				mockDeleteRolePolicyNotFound(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {
+				inventory, err := provisioning.LoadInventory(targetDir)
+				require.NoError(t, err, "unexpected error loading inventory")
+				assert.Equal(t, policyStyleManaged, inventory.Settings[policyStyleInventorySetting], "unexpected policy style recorded in inventory")
+				step, found := inventory.Step(iamRoleStepPrefix + "namespace1/secretName1")
+				require.True(t, found, "expected IAM Role step to be recorded in inventory")
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				// Resources are persisted ordered by type
+				assert.Equal(t, []provisioning.InventoryResource{{
+					Type: managedPolicyInventoryResourceType,
+					Name: roleName,
+					ID:   "test-role-arn:policy/" + roleName,
+				}, {
+					Type: iamRoleInventoryResourceType,
+					Name: roleName,
+					ID:   "test-role-arn",
+				}}, step.Resources)
+			},
+		},
+		{
+			name:         "Generate managed policy for one CredReq",
+			generateOnly: true,
+			policyStyle:  policyStyleManaged,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				assert.FileExists(t, filepath.Join(targetDir, fmt.Sprintf(managedPolicyFilenameFormat, 0, roleName, 1)), "expected generated managed policy")
+				assert.NoFileExists(t, filepath.Join(targetDir, fmt.Sprintf(rolePolicyFilenameFormat, 0, roleName)), "expected no generated inline policy")
+			},
+		},
+		{
+			name:         "Unsupported policy style",
+			expectError:  true,
+			generateOnly: true,
+			policyStyle:  "attached",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:                "Create with a description template",
+			generateOnly:        false,
+			clusterID:           testClusterID,
+			descriptionTemplate: "{{.Namespace}}/{{.SecretName}} credentials of cluster {{.ClusterName}}",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				roleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+				mockCreateRoleWithDescription(mockAWSClient, roleName, "namespace1/secretName1 credentials of cluster "+testNamePrefix, testClusterID)
+				mockPutRolePolicy(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:                "Description template exceeding the maximum length",
+			expectError:         true,
+			generateOnly:        false,
+			descriptionTemplate: strings.Repeat("{{.Component}}", 100),
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProvider(mockAWSClient)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", tempDirName, false)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:               "Max session duration too short",
+			expectError:        true,
+			generateOnly:       false,
+			maxSessionDuration: 3599,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
+		{
+			name:               "Max session duration too long",
+			expectError:        true,
+			generateOnly:       true,
+			maxSessionDuration: 43201,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+				return tempDirName
+			},
+			verify: func(t *testing.T, targetDir, manifestsDir string) {},
+		},
 	}
 
 	for _, test := range tests {
@@ -258,7 +600,23 @@ func TestIAMRoles(t *testing.T) {
 			require.NoError(t, err, "unexpected error creating manifests dir for test")
 			defer os.RemoveAll(manifestsDir)
 
-			err = createIAMRoles(mockAWSClient, testIdentityProviderARN, testPermissionsBoundaryARN, testNamePrefix, credReqDir, targetDir, false, test.generateOnly)
+			maxSessionDuration := test.maxSessionDuration
+			if maxSessionDuration == 0 {
+				maxSessionDuration = defaultMaxSessionDuration
+			}
+			policyStyle := test.policyStyle
+			if policyStyle == "" {
+				policyStyle = policyStyleAuto
+			}
+			var inventory *provisioning.Inventory
+			if test.recordInventory {
+				inventory = provisioning.NewInventory(targetDir, "aws", testNamePrefix)
+			}
+
+			require.NoError(t, provisioning.InitDescriptionTemplate(test.descriptionTemplate), "unexpected error parsing description template")
+			defer provisioning.InitDescriptionTemplate("")
+
+			err = createIAMRoles(mockAWSClient, testIdentityProviderARN, testPermissionsBoundaryARN, testNamePrefix, test.clusterID, []string{credReqDir}, targetDir, maxSessionDuration, policyStyle, false, test.generateOnly, test.onlyMissing, inventory)
 
 			if test.expectError {
 				require.Error(t, err, "expected error returned")
@@ -269,6 +627,79 @@ func TestIAMRoles(t *testing.T) {
 	}
 }
 
+func TestCheckIAMRoleQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockAWSClient func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		expectError   bool
+	}{
+		{
+			name: "Quota allows creating roles",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetAccountSummary(mockAWSClient, 998, 1000)
+				mockGetRole(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Quota exceeded",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetAccountSummary(mockAWSClient, 999, 1000)
+				mockGetRole(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				return mockAWSClient
+			},
+			expectError: true,
+		},
+		{
+			name: "Existing roles do not count against quota",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetAccountSummary(mockAWSClient, 1000, 1000)
+				mockGetRoleExists(mockAWSClient, fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix))
+				mockGetRoleExists(mockAWSClient, fmt.Sprintf("%s-namespace2-secretName2", testNamePrefix))
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Check skipped when account summary is unavailable",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockAWSClient.EXPECT().GetAccountSummary(gomock.Any()).Return(
+					nil, awserr.New("AccessDenied", "not authorized to perform iam:GetAccountSummary", fmt.Errorf("fake error")),
+				).Times(1)
+				return mockAWSClient
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockAWSClient := test.mockAWSClient(mockCtrl)
+
+			credReqDir, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+			require.NoError(t, err, "Failed to create temp directory")
+			defer os.RemoveAll(credReqDir)
+			err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+			require.NoError(t, err, "errored while setting up test CredReq files")
+			err = testCredentialsRequest(t, "secondcredreq", "namespace2", "secretName2", credReqDir, false)
+			require.NoError(t, err, "errored while setting up test CredReq files")
+
+			err = checkIAMRoleQuota(mockAWSClient, testNamePrefix, []string{credReqDir}, false)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
 func testCredentialsRequest(t *testing.T, crName, targetSecretNamespace, targetSecretName, targetDir string, isMarkedForDeletion bool) error {
 	var credReq string
 	if isMarkedForDeletion {
@@ -294,6 +725,17 @@ func mockGetOpenIDConnectProvider(mockAWSClient *mockaws.MockClient) {
 		}, nil).AnyTimes()
 }
 
+func mockGetAccountSummary(mockAWSClient *mockaws.MockClient, roles, rolesQuota int64) {
+	mockAWSClient.EXPECT().GetAccountSummary(gomock.Any()).Return(
+		&iam.GetAccountSummaryOutput{
+			SummaryMap: map[string]*int64{
+				iamRolesSummaryKey:      awssdk.Int64(roles),
+				iamRolesQuotaSummaryKey: awssdk.Int64(rolesQuota),
+			},
+		}, nil,
+	).Times(1)
+}
+
 func mockGetRole(mockAWSClient *mockaws.MockClient) {
 	mockAWSClient.EXPECT().GetRole(gomock.Any()).Return(
 		nil, awserr.New(iam.ErrCodeNoSuchEntityException, "Role does not exist", fmt.Errorf("fake error")),
@@ -311,6 +753,29 @@ func mockGetRoleExists(mockAWSClient *mockaws.MockClient, roleName string) {
 	).Times(1)
 }
 
+func mockGetRoleExistsWithMaxSessionDuration(mockAWSClient *mockaws.MockClient, roleName string, maxSessionDuration int64) {
+	mockAWSClient.EXPECT().GetRole(gomock.Any()).Return(
+		&iam.GetRoleOutput{
+			Role: &iam.Role{
+				Arn:                awssdk.String("test-role-arn"),
+				RoleName:           awssdk.String(roleName),
+				MaxSessionDuration: awssdk.Int64(maxSessionDuration),
+			},
+		}, nil,
+	).Times(1)
+}
+
+func mockUpdateRoleMaxSessionDuration(mockAWSClient *mockaws.MockClient, roleName string, maxSessionDuration int64) {
+	mockAWSClient.EXPECT().UpdateRole(gomock.Any()).DoAndReturn(
+		func(input *iam.UpdateRoleInput) (*iam.UpdateRoleOutput, error) {
+			if awssdk.StringValue(input.RoleName) != roleName || input.MaxSessionDuration == nil || *input.MaxSessionDuration != maxSessionDuration {
+				return nil, fmt.Errorf("unexpected update of role %v to max session duration %v", input.RoleName, input.MaxSessionDuration)
+			}
+			return &iam.UpdateRoleOutput{}, nil
+		},
+	).Times(1)
+}
+
 func mockCreateRole(mockAWSClient *mockaws.MockClient, roleName string) {
 	mockAWSClient.EXPECT().CreateRole(gomock.Any()).Return(
 		&iam.CreateRoleOutput{
@@ -322,6 +787,63 @@ func mockCreateRole(mockAWSClient *mockaws.MockClient, roleName string) {
 	).Times(1)
 }
 
+func mockCreateRoleWithMaxSessionDuration(mockAWSClient *mockaws.MockClient, roleName string, maxSessionDuration int64) {
+	mockAWSClient.EXPECT().CreateRole(gomock.Any()).DoAndReturn(
+		func(input *iam.CreateRoleInput) (*iam.CreateRoleOutput, error) {
+			if input.MaxSessionDuration == nil || *input.MaxSessionDuration != maxSessionDuration {
+				return nil, fmt.Errorf("unexpected max session duration %v", input.MaxSessionDuration)
+			}
+			return &iam.CreateRoleOutput{
+				Role: &iam.Role{
+					Arn:                awssdk.String("test-role-arn"),
+					RoleName:           awssdk.String(roleName),
+					MaxSessionDuration: input.MaxSessionDuration,
+				},
+			}, nil
+		},
+	).Times(1)
+}
+
+func mockCreateRoleWithClusterTag(mockAWSClient *mockaws.MockClient, roleName, clusterID string) {
+	mockAWSClient.EXPECT().CreateRole(gomock.Any()).DoAndReturn(
+		func(input *iam.CreateRoleInput) (*iam.CreateRoleOutput, error) {
+			if !hasClusterResourceTag(iamTagMap(input.Tags), clusterID) {
+				return nil, fmt.Errorf("role is not tagged with cluster ID %s", clusterID)
+			}
+			return &iam.CreateRoleOutput{
+				Role: &iam.Role{
+					Arn:      awssdk.String("test-role-arn"),
+					RoleName: awssdk.String(roleName),
+					Tags:     input.Tags,
+				},
+			}, nil
+		},
+	).Times(1)
+}
+
+// mockCreateRoleWithDescription expects the IAM Role to be created with description, tagged as any other IAM Role
+// created by ccoctl for clusterID so that its deletion remains scoped by tags
+func mockCreateRoleWithDescription(mockAWSClient *mockaws.MockClient, roleName, description, clusterID string) {
+	mockAWSClient.EXPECT().CreateRole(gomock.Any()).DoAndReturn(
+		func(input *iam.CreateRoleInput) (*iam.CreateRoleOutput, error) {
+			if awssdk.StringValue(input.Description) != description {
+				return nil, fmt.Errorf("unexpected role description %q", awssdk.StringValue(input.Description))
+			}
+			tags := iamTagMap(input.Tags)
+			if _, owned := tags[fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testNamePrefix)]; !owned || !hasClusterResourceTag(tags, clusterID) {
+				return nil, fmt.Errorf("role is not tagged as owned by %s for cluster ID %s", testNamePrefix, clusterID)
+			}
+			return &iam.CreateRoleOutput{
+				Role: &iam.Role{
+					Arn:      awssdk.String("test-role-arn"),
+					RoleName: awssdk.String(roleName),
+					Tags:     input.Tags,
+				},
+			}, nil
+		},
+	).Times(1)
+}
+
 func mockFailedCreateRole(mockAWSClient *mockaws.MockClient, roleName string) {
 	mockAWSClient.EXPECT().CreateRole(gomock.Any()).Return(
 		&iam.CreateRoleOutput{}, fmt.Errorf("test error on role create"),
@@ -339,3 +861,53 @@ func mockUpdateAssumeRolePolicy(mockAWSClient *mockaws.MockClient) {
 		&iam.UpdateAssumeRolePolicyOutput{}, nil,
 	).Times(1)
 }
+
+func mockCreatePolicy(mockAWSClient *mockaws.MockClient, policyName string) {
+	mockAWSClient.EXPECT().CreatePolicy(gomock.Any()).DoAndReturn(func(input *iam.CreatePolicyInput) (*iam.CreatePolicyOutput, error) {
+		if *input.PolicyName != policyName {
+			return nil, fmt.Errorf("unexpected policy name %s", *input.PolicyName)
+		}
+		return &iam.CreatePolicyOutput{Policy: &iam.Policy{PolicyName: input.PolicyName}}, nil
+	}).Times(1)
+}
+
+func mockAttachRolePolicy(mockAWSClient *mockaws.MockClient, roleName, policyARN string) {
+	mockAWSClient.EXPECT().AttachRolePolicy(&iam.AttachRolePolicyInput{
+		RoleName:  awssdk.String(roleName),
+		PolicyArn: awssdk.String(policyARN),
+	}).Return(&iam.AttachRolePolicyOutput{}, nil).Times(1)
+}
+
+func mockDeleteRolePolicyNotFound(mockAWSClient *mockaws.MockClient) {
+	mockAWSClient.EXPECT().DeleteRolePolicy(gomock.Any()).Return(
+		nil, awserr.New(iam.ErrCodeNoSuchEntityException, "Policy does not exist", fmt.Errorf("fake error")),
+	).Times(1)
+}
+
+var updateGolden = flag.Bool("update", false, "update the golden files within testdata")
+
+func TestSecretManifestGolden(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	targetDir := t.TempDir()
+	require.NoError(t, provisioning.EnsureDir(filepath.Join(targetDir, provisioning.ManifestsDirName)), "unexpected error creating manifests dir")
+
+	cr := &credreqv1.CredentialsRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "openshift-image-registry", Namespace: "openshift-cloud-credential-operator"},
+		Spec: credreqv1.CredentialsRequestSpec{
+			SecretRef: corev1.ObjectReference{Name: "installer-cloud-credentials", Namespace: "openshift-image-registry"},
+		},
+	}
+	err := writeCredReqSecret(cr, targetDir, "arn:aws:iam::123456789012:role/test-cluster1-openshift-image-registry-installer-cloud-credentials", "https://"+testIdentityProviderURL)
+	require.NoError(t, err, "unexpected error writing secret manifest")
+
+	rendered, err := os.ReadFile(filepath.Join(targetDir, provisioning.ManifestsDirName, "openshift-image-registry-installer-cloud-credentials-credentials.yaml"))
+	require.NoError(t, err, "unexpected error reading secret manifest")
+	goldenFile := filepath.Join("testdata", "secret-manifest.golden")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0755), "unexpected error creating testdata dir")
+		require.NoError(t, os.WriteFile(goldenFile, rendered, 0644), "unexpected error updating golden file")
+	}
+	golden, err := os.ReadFile(goldenFile)
+	require.NoError(t, err, "unexpected error reading golden file, run the test with -update to create it")
+	assert.Equal(t, string(golden), string(rendered), "rendered secret manifest does not match %s, run the test with -update if the change is intended", goldenFile)
+}
@@ -1,11 +1,19 @@
 package aws
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift/cloud-credential-operator/pkg/aws"
@@ -16,12 +24,67 @@ var (
 	// CreateAllOpts captures the options that affect creation/updating
 	// of the generated objects.
 	CreateAllOpts = options{
-		TargetDir: "",
+		TargetDir:          "",
+		MaxSessionDuration: defaultMaxSessionDuration,
+		PolicyStyle:        policyStyleAuto,
 	}
 )
 
+// findPriorProvisioning returns descriptions of the resources created by ccoctl for name by a prior provisioning, the
+// OIDC S3 bucket and the IAM Roles for the CredentialsRequests within credReqDirs which carry ccoctl's "owned" tag for
+// name. The bucket is not considered when sharedIdentityProvider is provided since clusters sharing the Identity
+// Provider are expected to share it.
+func findPriorProvisioning(client aws.Client, name string, credReqDirs []string, enableTechPreview, sharedIdentityProvider bool) ([]string, error) {
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)
+	resources := []string{}
+
+	if !sharedIdentityProvider {
+		bucketName := fmt.Sprintf("%s-oidc", name)
+		bucketTags, err := client.GetBucketTagging(&s3.GetBucketTaggingInput{
+			Bucket: awssdk.String(bucketName),
+		})
+		if err != nil {
+			var aerr awserr.Error
+			if !errors.As(err, &aerr) || (aerr.Code() != s3.ErrCodeNoSuchBucket && aerr.Code() != "NoSuchTagSet") {
+				return nil, errors.Wrapf(err, "failed to fetch tags of the bucket %s", bucketName)
+			}
+		} else if _, owned := s3TagMap(bucketTags.TagSet)[ownedTagKey]; owned {
+			resources = append(resources, "S3 bucket "+bucketName)
+		}
+	}
+
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	for _, cr := range credRequests {
+		roleName, err := iamRoleName(name, cr)
+		if err != nil {
+			return nil, err
+		}
+		roleOutput, err := client.GetRole(&iam.GetRoleInput{
+			RoleName: awssdk.String(roleName),
+		})
+		if err != nil {
+			var aerr awserr.Error
+			if errors.As(err, &aerr) && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to fetch IAM Role %s", roleName)
+		}
+		if _, owned := iamTagMap(roleOutput.Role.Tags)[ownedTagKey]; owned {
+			resources = append(resources, "IAM Role "+roleName)
+		}
+	}
+	return resources, nil
+}
+
 func createAllCmd(cmd *cobra.Command, args []string) {
-	s, err := awsSession(CreateAllOpts.Region)
+	if err := CreateAllOpts.DiscoveryDocument.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := awsSession(cmd.Context(), CreateAllOpts.Region, "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -33,25 +96,150 @@ func createAllCmd(cmd *cobra.Command, args []string) {
 		publicKeyPath = path.Join(CreateAllOpts.TargetDir, provisioning.PublicKeyFile)
 	}
 
+	provisioning.DumpConfig(cmd, func() map[string]string {
+		resolved := resolvedIdentity(s)
+		resolved["oidcBucketName"] = fmt.Sprintf("%s-oidc", CreateAllOpts.Name)
+		resolved["publicKeyPath"] = publicKeyPath
+		resolved["outputDir"] = CreateAllOpts.TargetDir
+		return resolved
+	})
+
+	// Refuse to adopt or overwrite the resources of another cluster provisioned with the same --name
+	if !CreateAllOpts.Force {
+		provisioning.SetPhase("checking for a prior provisioning")
+		resources, err := findPriorProvisioning(awsClient, CreateAllOpts.Name, CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview, CreateAllOpts.SharedIdentityProvider)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.CheckPriorProvisioning(CreateAllOpts.Name, resources, "--force to proceed regardless"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if !CreateAllOpts.SkipQuotaCheck {
+		provisioning.SetPhase("checking the IAM Role quota")
+		if err := checkIAMRoleQuota(awsClient, CreateAllOpts.Name, CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if err := provisioning.CreateKeys(CreateAllOpts.TargetDir); err != nil {
 		log.Fatalf("Failed to create public/private key pair: %s", err)
 	}
 
-	identityProviderARN, err := createIdentityProvider(awsClient, CreateAllOpts.Name, CreateAllOpts.Region, publicKeyPath, CreateAllOpts.TargetDir, CreateAllOpts.CreatePrivateS3Bucket, false)
+	// The public key is validated as a signing key before any resource trusting it is created
+	signingKeySize, err := provisioning.SigningKeySize(publicKeyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	inventory := loadOrCreateInventory(CreateAllOpts.TargetDir, CreateAllOpts.Name)
+	// The regions are recorded so that ccoctl aws delete --inventory deletes within each of them
+	inventory.Settings[regionsInventorySetting] = strings.Join(CreateAllOpts.Regions, ",")
+	inventory.Settings[provisioning.SigningKeySizeInventorySetting] = strconv.Itoa(signingKeySize)
+
+	provisioning.SetPhase("creating the IAM Identity Provider")
+	identityProviderARN, err := createIdentityProvider(awsClient, CreateAllOpts.Name, CreateAllOpts.ClusterID, CreateAllOpts.Region, publicKeyPath, CreateAllOpts.TargetDir, CreateAllOpts.CreatePrivateS3Bucket, false, CreateAllOpts.SharedIdentityProvider, CreateAllOpts.VerifyIssuerReachable, CreateAllOpts.DiscoveryDocument, inventory)
 	if err != nil {
 		log.Fatalf("Failed to create Identity provider: %s", err)
 	}
 
-	err = createIAMRoles(awsClient, identityProviderARN, CreateAllOpts.PermissionsBoundaryARN, CreateAllOpts.Name,
-		CreateAllOpts.CredRequestDir, CreateAllOpts.TargetDir, CreateAllOpts.EnableTechPreview, false)
+	if err := inventory.CompleteStep(identityProviderStep, identityProviderResources(CreateAllOpts.Name, identityProviderARN, CreateAllOpts.SharedIdentityProvider)...); err != nil {
+		log.Fatal(err)
+	}
+
+	if !CreateAllOpts.SkipSTSEndpointCheck {
+		// The STS endpoint is regional, pods running within each region exchange their tokens with its endpoint
+		for _, region := range CreateAllOpts.Regions {
+			provisioning.SetPhase("verifying the STS endpoint of region " + region)
+			regionalSession := s
+			if region != CreateAllOpts.Region {
+				if regionalSession, err = awsSession(cmd.Context(), region, ""); err != nil {
+					log.Fatal(err)
+				}
+			}
+			if err := checkSTSEndpoint(regionalSession, awsClient, region, identityProviderARN); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	err = createIAMRoles(awsClient, identityProviderARN, CreateAllOpts.PermissionsBoundaryARN, CreateAllOpts.Name, CreateAllOpts.ClusterID,
+		CreateAllOpts.CredRequestDirs, CreateAllOpts.TargetDir, CreateAllOpts.MaxSessionDuration, CreateAllOpts.PolicyStyle, CreateAllOpts.EnableTechPreview, false, false, inventory)
 	if err != nil {
 		log.Fatalf("Failed to process IAM Roles: %s", err)
 	}
+
+	if CreateAllOpts.OutputImport {
+		if err := writeTerraformImports(inventory); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The next steps are derived from the output directory before the manifests are moved into the archive
+	nextSteps, err := provisioning.NewNextSteps("aws", CreateAllOpts.TargetDir, publicKeyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if CreateAllOpts.OutputArchive != "" {
+		if err := provisioning.ArchiveOutputs(CreateAllOpts.TargetDir, CreateAllOpts.OutputArchive); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Wrote output archive %s", CreateAllOpts.OutputArchive)
+		nextSteps.OutputArchive = CreateAllOpts.OutputArchive
+	}
+	provisioning.PrintNextSteps(nextSteps)
 }
 
 // initEnvForCreateAllCmd will ensure the destination directory is ready to receive the generated
 // files, and will create the directory if necessary.
 func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
+	// The OIDC bucket is created within the first region
+	regions, err := validateRegions(CreateAllOpts.Regions)
+	if err != nil {
+		log.Fatal(err)
+	}
+	CreateAllOpts.Regions = regions
+	CreateAllOpts.Region = regions[0]
+
+	if err := validateMaxSessionDuration(CreateAllOpts.MaxSessionDuration); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validatePolicyStyle(CreateAllOpts.PolicyStyle); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := provisioning.ValidateClusterID(CreateAllOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateSharedIdentityProvider(CreateAllOpts); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := provisioning.ValidateCredentialsRequests(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+
+	// The trust policies are validated once the IAM Identity Provider is created, before any IAM Role
+	if err := checkRolePolicyLimits(CreateAllOpts.CredRequestDirs, CreateAllOpts.Name, "", CreateAllOpts.PolicyStyle, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if !CreateAllOpts.SkipDeprecatedPermissionsCheck {
+		if err := provisioning.WarnDeprecatedPermissions(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if !CreateAllOpts.SkipCostEstimate {
+		counts, err := createAllResourceCounts(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview, CreateAllOpts.CreatePrivateS3Bucket)
+		if err != nil {
+			log.Fatal(err)
+		}
+		provisioning.PrintCostEstimate("aws", CreateAllOpts.Region, counts)
+	}
+
 	if CreateAllOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -61,6 +249,14 @@ func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
 		CreateAllOpts.TargetDir = pwd
 	}
 
+	// Report every input naming another region than --region, eg. copied from the manifests of another cluster,
+	// before any resource is created
+	if !CreateAllOpts.SkipRegionConsistencyCheck {
+		if err := checkRegionConsistency(CreateAllOpts.CredRequestDirs, CreateAllOpts.TargetDir, CreateAllOpts.Name, CreateAllOpts.Regions, CreateAllOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	fPath, err := filepath.Abs(CreateAllOpts.TargetDir)
 	if err != nil {
 		log.Fatalf("Failed to resolve full path: %s", err)
@@ -79,12 +275,19 @@ func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
 		log.Fatalf("failed to create manifests directory at %s", manifestsDir)
 	}
 
+	provisioning.EnsureSecretsDir(manifestsDir, CreateAllOpts.AllowInsecureSecretsDir)
+
 	// create tls dir if necessary
 	tlsDir := filepath.Join(fPath, provisioning.TLSDirName)
 	err = provisioning.EnsureDir(tlsDir)
 	if err != nil {
 		log.Fatalf("failed to create tls directory at %s", tlsDir)
 	}
+
+	CreateAllOpts.OutputArchive, err = provisioning.ValidateOutputArchivePath(CreateAllOpts.OutputArchive, fPath)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 // NewCreateAllCmd provides the "create-all" subcommand
@@ -95,17 +298,34 @@ func NewCreateAllCmd() *cobra.Command {
 		Run:              createAllCmd,
 		PersistentPreRun: initEnvForCreateAllCmd,
 	}
+	provisioning.DumpsResolvedConfig(createAllCmd)
 
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Name, "name", "", "User-defined name for all created AWS resources (can be separate from the cluster's infra-id)")
 	createAllCmd.MarkPersistentFlagRequired("name")
-	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Region, "region", "", "AWS region where the S3 OpenID Connect endpoint will be created")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.Regions, "region", nil, "AWS region where the S3 OpenID Connect endpoint will be created. May be specified multiple times, or as a comma-separated list, for clusters running within several regions, the S3 OpenID Connect endpoint is created within the first region and the regional STS endpoint is verified within each region")
 	createAllCmd.MarkPersistentFlagRequired("region")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.PermissionsBoundaryARN, "permissions-boundary-arn", "", "ARN of IAM policy to use as the permissions boundary for created roles")
-	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to create IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=aws' against an OpenShift release image)")
+	createAllCmd.PersistentFlags().Int64Var(&CreateAllOpts.MaxSessionDuration, "max-session-duration", defaultMaxSessionDuration, "Maximum session duration in seconds (between 3600 and 43200) for created roles")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.PolicyStyle, "policy-style", policyStyleAuto, "Style of the policies granting the permissions of created roles: inline policies, managed policies attached to the roles, or auto to use an inline policy unless the policy exceeds the AWS size limit of inline policies")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=aws' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createAllCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
 	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.VerifyIssuerReachable, "verify-issuer-reachable", false, "Fetch the OIDC discovery document and JSON web key set from the issuer URL over the public network once they have been uploaded, honoring the HTTPS_PROXY and NO_PROXY environment variables, and fail if they are not publicly served")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.DiscoveryDocument.Audiences, "oidc-extra-audience", nil, "Audience to append to the audiences_supported of the OIDC discovery document, eg. for an identity consumer of the issuer other than the cluster. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.DiscoveryDocument.Claims, "oidc-extra-claim", nil, "Claim to append to the claims_supported of the OIDC discovery document. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.OutputArchive, "output-archive", "", "Package the generated manifests, the tls directory and the inventory into a reproducible gzipped tar archive at this path, eg. to transport them into a disconnected environment. The manifests directory and the inventory are removed from the output directory once the archive has been written")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.OutputImport, "output-import", false, fmt.Sprintf("Write a terraform import command for every created AWS resource to %s within the output directory, so that the resources may be brought under terraform management", provisioning.TerraformImportFileName))
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.Force, "force", false, "Proceed even if resources created by ccoctl for --name by a prior provisioning exist")
+	provisioning.AddAllowInsecureSecretsDirFlag(createAllCmd.PersistentFlags(), &CreateAllOpts.AllowInsecureSecretsDir)
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipQuotaCheck, "skip-quota-check", false, "Skip checking that the IAM Roles quota of the account allows creating the IAM Roles before creating any resources")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipDeprecatedPermissionsCheck, "skip-deprecated-permissions-check", false, "Skip warning about the permissions requested by the CredentialsRequests which are known to be deprecated by AWS")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipRegionConsistencyCheck, "skip-region-consistency-check", false, "Skip checking that the regions named by the CredentialsRequests, ie. the regions of the ARNs of their resources and of their aws:RequestedRegion conditions, are regions provided to --region, and that the first region is the region of the OIDC bucket recorded within the inventory of the output directory")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipCostEstimate, provisioning.SkipCostEstimateFlag, false, provisioning.SkipCostEstimateFlagUsage)
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipSTSEndpointCheck, "skip-sts-endpoint-check", false, "Skip checking that the regional STS endpoint used by the pods of the cluster is reachable and active for the account, and that the IAM Identity Provider trusts the audience of their tokens, before creating the IAM Roles")
 	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.CreatePrivateS3Bucket, "create-private-s3-bucket", false, "Create private S3 bucket with public CloudFront OIDC endpoint")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag created AWS resources, the resources will be tagged with 'kubernetes.io/cluster/<cluster ID> = owned'")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SharedIdentityProvider, "shared-identity-provider", false, sharedIdentityProviderFlagUsage)
 
 	return createAllCmd
 }
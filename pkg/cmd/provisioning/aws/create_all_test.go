@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestFindPriorProvisioning(t *testing.T) {
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testNamePrefix)
+	firstRoleName := fmt.Sprintf("%s-namespace1-secretName1", testNamePrefix)
+	secondRoleName := fmt.Sprintf("%s-namespace2-secretName2", testNamePrefix)
+
+	tests := []struct {
+		name                   string
+		mockAWSClient          func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		sharedIdentityProvider bool
+		expectError            bool
+		expectedResources      []string
+	}{
+		{
+			name: "No prior provisioning",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTaggingError(mockAWSClient, s3.ErrCodeNoSuchBucket)
+				mockGetRole(mockAWSClient)
+				mockGetRole(mockAWSClient)
+				return mockAWSClient
+			},
+			expectedResources: []string{},
+		},
+		{
+			name: "Prior provisioning of another cluster",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, map[string]string{
+					ownedTagKey:                       ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey("other-id"): provisioning.ClusterResourceTagValue,
+				})
+				mockGetTaggedRole(mockAWSClient, firstRoleName, map[string]string{ownedTagKey: ownedCcoctlAWSResourceTagValue})
+				mockGetRole(mockAWSClient)
+				return mockAWSClient
+			},
+			expectedResources: []string{fmt.Sprintf("S3 bucket %s-oidc", testNamePrefix), "IAM Role " + firstRoleName},
+		},
+		{
+			name: "Existing resources not created by ccoctl",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTaggingError(mockAWSClient, "NoSuchTagSet")
+				mockGetTaggedRole(mockAWSClient, firstRoleName, map[string]string{})
+				mockGetTaggedRole(mockAWSClient, secondRoleName, map[string]string{fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, "other-name"): ownedCcoctlAWSResourceTagValue})
+				return mockAWSClient
+			},
+			expectedResources: []string{},
+		},
+		{
+			name: "Bucket not considered with shared identity provider",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				// The roles may be fetched in either order, the expectation of the tagged role is matched first
+				mockGetTaggedRole(mockAWSClient, secondRoleName, map[string]string{ownedTagKey: ownedCcoctlAWSResourceTagValue})
+				mockGetRole(mockAWSClient)
+				return mockAWSClient
+			},
+			sharedIdentityProvider: true,
+			expectedResources:      []string{"IAM Role " + secondRoleName},
+		},
+		{
+			name: "Bucket tags cannot be fetched",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTaggingError(mockAWSClient, "AccessDenied")
+				return mockAWSClient
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockAWSClient := test.mockAWSClient(mockCtrl)
+
+			credReqDir := t.TempDir()
+			err := testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+			require.NoError(t, err, "errored while setting up test CredReq files")
+			err = testCredentialsRequest(t, "secondcredreq", "namespace2", "secretName2", credReqDir, false)
+			require.NoError(t, err, "errored while setting up test CredReq files")
+
+			resources, err := findPriorProvisioning(mockAWSClient, testNamePrefix, []string{credReqDir}, false, test.sharedIdentityProvider)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectedResources, resources, "unexpected resources of a prior provisioning")
+			}
+		})
+	}
+}
+
+func mockGetBucketTagging(mockAWSClient *mockaws.MockClient, tags map[string]string) {
+	tagSet := []*s3.Tag{}
+	for key, value := range tags {
+		tagSet = append(tagSet, &s3.Tag{Key: awssdk.String(key), Value: awssdk.String(value)})
+	}
+	mockAWSClient.EXPECT().GetBucketTagging(gomock.Any()).Return(&s3.GetBucketTaggingOutput{TagSet: tagSet}, nil).Times(1)
+}
+
+func mockGetBucketTaggingError(mockAWSClient *mockaws.MockClient, code string) {
+	mockAWSClient.EXPECT().GetBucketTagging(gomock.Any()).Return(nil, awserr.New(code, "fake error", fmt.Errorf("fake error"))).Times(1)
+}
+
+func mockGetTaggedRole(mockAWSClient *mockaws.MockClient, roleName string, tags map[string]string) {
+	iamTags := []*iam.Tag{}
+	for key, value := range tags {
+		iamTags = append(iamTags, &iam.Tag{Key: awssdk.String(key), Value: awssdk.String(value)})
+	}
+	mockAWSClient.EXPECT().GetRole(&iam.GetRoleInput{RoleName: awssdk.String(roleName)}).Return(
+		&iam.GetRoleOutput{
+			Role: &iam.Role{
+				Arn:      awssdk.String("test-role-arn"),
+				RoleName: awssdk.String(roleName),
+				Tags:     iamTags,
+			},
+		}, nil,
+	).Times(1)
+}
@@ -2,8 +2,10 @@ package aws
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha1"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -22,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	jose "gopkg.in/square/go-jose.v2"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/cloud-credential-operator/pkg/aws"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
@@ -188,12 +191,7 @@ var (
          }
       },
       "Tags":{
-         "Items":[
-            {
-               "Key":"Name",
-               "Value":"%s"
-            }
-         ]
+         "Items":%s
       }
    }
 }`
@@ -221,6 +219,17 @@ var (
 	ownedCcoctlAWSResourceTagValue = "owned"
 	// nameTagKey is the key of the "Name" tag applied to the AWS resources created by ccoctl
 	nameTagKey = "Name"
+	// componentTagKey is the key of the tag identifying the component of the CredentialsRequest for which an IAM Role
+	// was created, its value is "<secret namespace>/<secret name>"
+	componentTagKey = ccoctlAWSResourceTagKeyPrefix + ".component"
+	// clusterAWSResourceTagKeyPrefix is the prefix of the tag key identifying the OpenShift cluster which owns an
+	// AWS resource, the tag key is "kubernetes.io/cluster/<cluster ID>"
+	clusterAWSResourceTagKeyPrefix = "kubernetes.io/cluster"
+	// sharedIdentityProviderFlagUsage is the usage of the --shared-identity-provider flag of the commands creating the
+	// IAM Identity Provider
+	sharedIdentityProviderFlagUsage = "Reuse an existing IAM Identity Provider for the issuer URL, eg. one shared by several clusters using the same S3 OIDC endpoint, " +
+		"rather than failing. Missing client IDs are added to the Identity Provider and it is tagged with --cluster-id, which is required, " +
+		"so that ccoctl aws delete only deletes it along with the last cluster sharing it"
 	// cloudFrontCachingDisabledPolicyID is the ID of the policy that disables caching for a CloudFront distribution
 	cloudFrontCachingDisabledPolicyID = "4135ea2d-6df8-44a3-9df3-4b5a84be39ad"
 	// cloudFrontDistributionDeployedStatus is status of the CloudFront when it is fully deployed
@@ -243,36 +252,62 @@ var (
 	cloudFrontDistributionFilename                     = "08-cloudfront-distribution.json"
 )
 
+// identityProviderClientIDs are the client IDs, or audiences, of the IAM Identity Provider
+var identityProviderClientIDs = []string{"openshift", "sts.amazonaws.com"}
+
 type JSONWebKeySet struct {
 	Keys []jose.JSONWebKey `json:"keys"`
 }
 
-func createIdentityProvider(client aws.Client, name, region, publicKeyPath, targetDir string, createPrivateS3, generateOnly bool) (string, error) {
+// createIdentityProvider creates the OIDC endpoint and the IAM Identity Provider for name. When inventory is provided the
+// S3 bucket and the IAM Identity Provider are recorded within it before they are created, as they are tagged separately
+// from their creation and would otherwise be left untagged and unknown to ccoctl when create is interrupted in between.
+func createIdentityProvider(client aws.Client, name, clusterID, region, publicKeyPath, targetDir string, createPrivateS3, generateOnly, sharedIdentityProvider, verifyIssuerReachable bool, discoveryDocument provisioning.DiscoveryDocumentExtensions, inventory *provisioning.Inventory) (string, error) {
+	recordIntent := inventory != nil && !generateOnly
+
 	// Create the S3 bucket and (if specified) a CloudFront Distribution to serve OIDC endpoint
 	bucketName := fmt.Sprintf("%s-oidc", name)
-	issuerURL, err := createOIDCEndpoint(client, bucketName, name, region, targetDir, createPrivateS3, generateOnly)
+	if recordIntent {
+		if err := inventory.RecordIntended(provisioning.InventoryResource{Type: s3BucketInventoryResourceType, Name: bucketName}); err != nil {
+			return "", err
+		}
+	}
+	issuerURL, err := createOIDCEndpoint(client, bucketName, name, clusterID, region, targetDir, createPrivateS3, generateOnly)
 	if err != nil {
 		return "", err
 	}
 
 	// Create the OIDC config file
-	if err := createOIDCConfiguration(client, bucketName, issuerURL, name, targetDir, createPrivateS3, generateOnly); err != nil {
+	if err := createOIDCConfiguration(client, bucketName, issuerURL, name, clusterID, targetDir, createPrivateS3, generateOnly, discoveryDocument); err != nil {
 		return "", err
 	}
 
 	// Create the OIDC key list
-	if err := createJSONWebKeySet(client, publicKeyPath, bucketName, name, targetDir, createPrivateS3, generateOnly); err != nil {
+	if err := createJSONWebKeySet(client, publicKeyPath, bucketName, name, clusterID, targetDir, createPrivateS3, generateOnly); err != nil {
 		return "", err
 	}
 
-	// Create the IAM Identity Provider
-	identityProviderARN, err := createIAMIdentityProvider(client, issuerURL, name, targetDir, generateOnly)
+	// Ensure the OIDC documents are publicly served before the IAM Identity Provider trusts the issuer
+	if verifyIssuerReachable && !generateOnly {
+		if err := provisioning.VerifyIssuerReachable(issuerURL); err != nil {
+			return "", err
+		}
+	}
+
+	// Create the IAM Identity Provider. A shared Identity Provider already exists and is tagged as it is found.
+	if recordIntent && !sharedIdentityProvider {
+		inventory.Settings[issuerURLInventorySetting] = issuerURL
+		if err := inventory.RecordIntended(provisioning.InventoryResource{Type: identityProviderInventoryResourceType, Name: name}); err != nil {
+			return "", err
+		}
+	}
+	identityProviderARN, err := createIAMIdentityProvider(client, issuerURL, name, clusterID, targetDir, generateOnly, sharedIdentityProvider)
 	if err != nil {
 		return "", err
 	}
 
 	// Create the installer manifest file
-	if err := provisioning.CreateClusterAuthentication(issuerURL, targetDir); err != nil {
+	if err := provisioning.CreateClusterAuthentication(issuerURL, targetDir, generateOnly); err != nil {
 		return "", err
 	}
 
@@ -303,7 +338,14 @@ func getTLSFingerprint(bucketURL string) (string, error) {
 	return buf.String(), nil
 }
 
-func createIAMIdentityProvider(client aws.Client, issuerURL, name, targetDir string, generateOnly bool) (string, error) {
+func createIAMIdentityProvider(client aws.Client, issuerURL, name, clusterID, targetDir string, generateOnly, shared bool) (string, error) {
+	_, span := provisioning.StartSpan(context.Background(), "aws.EnsureIAMIdentityProvider",
+		provisioning.ProviderAttribute.String("aws"),
+		provisioning.ResourceTypeAttribute.String("iamIdentityProvider"),
+		provisioning.ResourceNameAttribute.String(issuerURL),
+	)
+	defer span.End()
+
 	var providerARN string
 
 	if generateOnly {
@@ -315,18 +357,13 @@ func createIAMIdentityProvider(client aws.Client, issuerURL, name, targetDir str
 		}
 
 	} else {
-		fingerprint, err := getTLSFingerprint(issuerURL)
-		if err != nil {
-			return "", errors.Wrap(err, "failed to get fingerprint")
-		}
-
 		oidcProviderList, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
 		if err != nil {
 			return "", errors.Wrap(err, "failed to fetch list of Identity Providers")
 		}
 
 		for _, provider := range oidcProviderList.OpenIDConnectProviderList {
-			ok, err := isExistingIdentifyProvider(client, *provider.Arn, name)
+			ok, err := isExistingIdentifyProvider(client, *provider.Arn, name, "")
 			if err != nil {
 				return "", errors.Wrapf(err, "failed to check existing Identity Provider %s", *provider.Arn)
 			}
@@ -338,52 +375,173 @@ func createIAMIdentityProvider(client aws.Client, issuerURL, name, targetDir str
 			}
 		}
 
+		if len(providerARN) == 0 && shared {
+			providerARN, err = findIdentityProviderForIssuerURL(client, oidcProviderList.OpenIDConnectProviderList, issuerURL)
+			if err != nil {
+				return "", err
+			}
+			if len(providerARN) != 0 {
+				log.Printf("Existing Identity Provider for issuer URL %s found with ARN: %s", issuerURL, providerARN)
+			}
+		}
+
+		if len(providerARN) != 0 && shared {
+			if err := shareIdentityProvider(client, providerARN, name, clusterID); err != nil {
+				return "", err
+			}
+		}
+
 		if len(providerARN) == 0 {
+			fingerprint, err := getTLSFingerprint(issuerURL)
+			if err != nil {
+				return "", errors.Wrap(err, "failed to get fingerprint")
+			}
+
 			oidcOutput, err := client.CreateOpenIDConnectProvider(&iam.CreateOpenIDConnectProviderInput{
-				ClientIDList: []*string{
-					awssdk.String("openshift"),
-					awssdk.String("sts.amazonaws.com"),
-				},
+				ClientIDList: awssdk.StringSlice(identityProviderClientIDs),
 				ThumbprintList: []*string{
 					awssdk.String(fingerprint),
 				},
 				Url: awssdk.String(issuerURL),
 			})
 			if err != nil {
+				if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeEntityAlreadyExistsException {
+					return "", errors.Wrapf(err, "an Identity Provider for issuer URL %s already exists, "+
+						"use --shared-identity-provider to share it with the clusters using it", issuerURL)
+				}
 				return "", errors.Wrap(err, "failed to create Identity Provider")
 			}
 
 			providerARN = *oidcOutput.OpenIDConnectProviderArn
 
-			_, err = client.TagOpenIDConnectProvider(&iam.TagOpenIDConnectProviderInput{
-				OpenIDConnectProviderArn: &providerARN,
-				Tags: []*iam.Tag{
-					{
-						Key:   awssdk.String(fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)),
-						Value: awssdk.String(ownedCcoctlAWSResourceTagValue),
-					},
-					{
-						Key:   awssdk.String(nameTagKey),
-						Value: awssdk.String(name),
-					},
+			err = provisioning.TagResource(provisioning.TagOperation{
+				ResourceType: "IAMIdentityProvider",
+				ResourceName: providerARN,
+				Tag: func() error {
+					_, err := client.TagOpenIDConnectProvider(&iam.TagOpenIDConnectProviderInput{
+						OpenIDConnectProviderArn: &providerARN,
+						Tags:                     iamTags(resourceTags(name, clusterID)),
+					})
+					return err
 				},
-			})
+			}, isThrottlingError)
 			if err != nil {
 				return "", errors.Wrapf(err, "failed to tag the identity provider with arn: %s", providerARN)
 			}
 
 			log.Printf("Identity Provider created with ARN: %s", providerARN)
+			provisioning.EmitResourceEvent("IAMIdentityProvider", providerARN, provisioning.ResourceCreated)
 		}
 	}
 	return providerARN, nil
 }
 
-func createJSONWebKeySet(client aws.Client, publicKeyFilepath, bucketName, name, targetDir string, createPrivateS3, generateOnly bool) error {
+// findIdentityProviderForIssuerURL returns the ARN of the Identity Provider within providers for issuerURL, or an empty
+// string when there is none. IAM reports the URL of Identity Providers without the "https://" scheme.
+func findIdentityProviderForIssuerURL(client aws.Client, providers []*iam.OpenIDConnectProviderListEntry, issuerURL string) (string, error) {
+	for _, provider := range providers {
+		output, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+			OpenIDConnectProviderArn: provider.Arn,
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get Identity Provider with ARN %s", *provider.Arn)
+		}
+		if strings.TrimPrefix(awssdk.StringValue(output.Url), "https://") == strings.TrimPrefix(issuerURL, "https://") {
+			return *provider.Arn, nil
+		}
+	}
+	return "", nil
+}
+
+// shareIdentityProvider prepares the existing Identity Provider identified by providerARN for use by the OpenShift
+// cluster with ID clusterID. The client IDs required by the cluster are added to the Identity Provider and the
+// Identity Provider is tagged as owned by name and by the cluster, the cluster tags record the clusters sharing the
+// Identity Provider so that it is only deleted along with the last of them. The "Name" tag of the Identity Provider
+// is left unchanged.
+func shareIdentityProvider(client aws.Client, providerARN, name, clusterID string) error {
+	provider, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(providerARN),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get Identity Provider with ARN %s", providerARN)
+	}
+
+	existingClientIDs := sets.NewString(awssdk.StringValueSlice(provider.ClientIDList)...)
+	for _, clientID := range identityProviderClientIDs {
+		if existingClientIDs.Has(clientID) {
+			continue
+		}
+		_, err := client.AddClientIDToOpenIDConnectProvider(&iam.AddClientIDToOpenIDConnectProviderInput{
+			OpenIDConnectProviderArn: awssdk.String(providerARN),
+			ClientID:                 awssdk.String(clientID),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to add client ID %s to Identity Provider with ARN %s", clientID, providerARN)
+		}
+		log.Printf("Added client ID %s to Identity Provider with ARN %s", clientID, providerARN)
+	}
+
+	existingTags := iamTagMap(provider.Tags)
+	if len(sharingClusterIDs(existingTags)) == 0 {
+		provisioning.Warnf("Identity Provider with ARN %s is not tagged with the ID of any cluster, the clusters already using it "+
+			"are not tracked and it will be deleted along with the last cluster sharing it which is tagged", providerARN)
+	}
+	missingTags := []resourceTag{}
+	for _, tag := range resourceTags(name, clusterID) {
+		if tag.Key == nameTagKey {
+			continue
+		}
+		if value, found := existingTags[tag.Key]; !found || value != tag.Value {
+			missingTags = append(missingTags, tag)
+		}
+	}
+	if len(missingTags) == 0 {
+		return nil
+	}
+	err = provisioning.TagResource(provisioning.TagOperation{
+		ResourceType: "IAMIdentityProvider",
+		ResourceName: providerARN,
+		Tag: func() error {
+			_, err := client.TagOpenIDConnectProvider(&iam.TagOpenIDConnectProviderInput{
+				OpenIDConnectProviderArn: awssdk.String(providerARN),
+				Tags:                     iamTags(missingTags),
+			})
+			return err
+		},
+	}, isThrottlingError)
+	if err != nil {
+		return errors.Wrapf(err, "failed to tag the identity provider with arn: %s", providerARN)
+	}
+	log.Printf("Identity Provider with ARN %s shared with cluster %s", providerARN, clusterID)
+	return nil
+}
+
+// sharingClusterIDs returns the sorted IDs of the OpenShift clusters which tags identify as owners of an
+// Identity Provider
+func sharingClusterIDs(tags map[string]string) []string {
+	clusterIDs := sets.NewString()
+	for key, value := range tags {
+		if clusterID := strings.TrimPrefix(key, clusterAWSResourceTagKeyPrefix+"/"); clusterID != key && value == provisioning.ClusterResourceTagValue {
+			clusterIDs.Insert(clusterID)
+		}
+	}
+	return clusterIDs.List()
+}
+
+func createJSONWebKeySet(client aws.Client, publicKeyFilepath, bucketName, name, clusterID, targetDir string, createPrivateS3, generateOnly bool) error {
 	jwks, err := provisioning.BuildJsonWebKeySet(publicKeyFilepath)
 	if err != nil {
 		return errors.Wrap(err, "failed to build JSON web key set from the public key")
 	}
+	if err := provisioning.WriteJWKSPEMBundle(jwks, targetDir); err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(client, jwks, bucketName, name, clusterID, targetDir, generateOnly)
+}
 
+// publishJSONWebKeySet uploads the JSON web key set jwks to the OIDC S3 bucket identified by bucketName or, when
+// generateOnly is provided, saves it within targetDir
+func publishJSONWebKeySet(client aws.Client, jwks []byte, bucketName, name, clusterID, targetDir string, generateOnly bool) error {
 	if generateOnly {
 		oidcKeysFullPath := filepath.Join(targetDir, oidcKeysFilename)
 		log.Printf("Saving JSON web key set (JWKS) locally at %s", oidcKeysFullPath)
@@ -391,23 +549,27 @@ func createJSONWebKeySet(client aws.Client, publicKeyFilepath, bucketName, name,
 			return errors.Wrap(err, fmt.Sprintf("Failed to save JSON web key set (JWKS) locally at %s", oidcKeysFullPath))
 		}
 	} else {
-		_, err = client.PutObject(&s3.PutObjectInput{
+		_, err := client.PutObject(&s3.PutObjectInput{
 			Body:    awssdk.ReadSeekCloser(bytes.NewReader(jwks)),
 			Bucket:  awssdk.String(bucketName),
 			Key:     awssdk.String(provisioning.KeysURI),
-			Tagging: awssdk.String(fmt.Sprintf("%s/%s=%s&%s=%s", ccoctlAWSResourceTagKeyPrefix, name, ownedCcoctlAWSResourceTagValue, nameTagKey, name)),
+			Tagging: awssdk.String(objectTagging(resourceTags(name, clusterID))),
 		})
 
 		if err != nil {
 			return errors.Wrapf(err, "failed to upload JSON web key set (JWKS) in the S3 bucket %s", bucketName)
 		}
 		log.Printf("JSON web key set (JWKS) in the S3 bucket %s at %s updated", bucketName, provisioning.KeysURI)
+		provisioning.EmitResourceEvent("S3Object", bucketName+"/"+provisioning.KeysURI, provisioning.ResourceUpdated)
 	}
 	return nil
 }
 
-func createOIDCConfiguration(client aws.Client, bucketName, issuerURL, name, targetDir string, createPrivateS3, generateOnly bool) error {
-	discoveryDocumentJSON := fmt.Sprintf(provisioning.DiscoveryDocumentTemplate, issuerURL, issuerURL, provisioning.KeysURI)
+func createOIDCConfiguration(client aws.Client, bucketName, issuerURL, name, clusterID, targetDir string, createPrivateS3, generateOnly bool, discoveryDocument provisioning.DiscoveryDocumentExtensions) error {
+	discoveryDocumentJSON, err := provisioning.ExtendDiscoveryDocument(fmt.Sprintf(provisioning.DiscoveryDocumentTemplate, issuerURL, issuerURL, provisioning.KeysURI), discoveryDocument)
+	if err != nil {
+		return err
+	}
 	if generateOnly {
 		oidcConfigurationFullPath := filepath.Join(targetDir, oidcConfigurationFilename)
 		log.Printf("Saving discovery document locally at %s", oidcConfigurationFullPath)
@@ -419,17 +581,26 @@ func createOIDCConfiguration(client aws.Client, bucketName, issuerURL, name, tar
 			Body:    awssdk.ReadSeekCloser(strings.NewReader(discoveryDocumentJSON)),
 			Bucket:  awssdk.String(bucketName),
 			Key:     awssdk.String(provisioning.DiscoveryDocumentURI),
-			Tagging: awssdk.String(fmt.Sprintf("%s/%s=%s&%s=%s", ccoctlAWSResourceTagKeyPrefix, name, ownedCcoctlAWSResourceTagValue, nameTagKey, name)),
+			Tagging: awssdk.String(objectTagging(resourceTags(name, clusterID))),
 		})
 		if err != nil {
 			return errors.Wrapf(err, "failed to upload discovery document in the S3 bucket %s", bucketName)
 		}
 		log.Printf("OpenID Connect discovery document in the S3 bucket %s at %s updated", bucketName, provisioning.DiscoveryDocumentURI)
+		provisioning.EmitResourceEvent("S3Object", bucketName+"/"+provisioning.DiscoveryDocumentURI, provisioning.ResourceUpdated)
 	}
 	return nil
 }
 
-func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir string, createPrivateS3, generateOnly bool) (string, error) {
+func createOIDCEndpoint(client aws.Client, bucketName, name, clusterID, region, targetDir string, createPrivateS3, generateOnly bool) (string, error) {
+	_, span := provisioning.StartSpan(context.Background(), "aws.EnsureOIDCBucket",
+		provisioning.ProviderAttribute.String("aws"),
+		provisioning.ResourceTypeAttribute.String("s3Bucket"),
+		provisioning.ResourceNameAttribute.String(bucketName),
+		provisioning.RegionAttribute.String(region),
+	)
+	defer span.End()
+
 	s3BucketURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucketName, region)
 	if generateOnly {
 		oidcBucketFilepath := filepath.Join(targetDir, oidcBucketFilename)
@@ -468,8 +639,12 @@ func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir s
 				return "", errors.Wrap(err, fmt.Sprintf("Failed to save JSON to block public access to OIDC S3 bucket locally at %s", blockPublicAccessToOidcBucketFilepath))
 			}
 
+			cloudFrontDistributionTagsJSON, err := json.Marshal(append([]resourceTag{{Key: nameTagKey, Value: name}}, clusterResourceTags(clusterID)...))
+			if err != nil {
+				return "", errors.Wrap(err, "failed to convert CloudFront Distribution tags to JSON")
+			}
 			cloudFrontDistributionFilepath := filepath.Join(targetDir, cloudFrontDistributionFilename)
-			cloudFrontDistributionJSON := fmt.Sprintf(cloudFrontDistributionWithTagsTemplate, name, bucketName, region, bucketName, region, bucketName, region, fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name), name)
+			cloudFrontDistributionJSON := fmt.Sprintf(cloudFrontDistributionWithTagsTemplate, name, bucketName, region, bucketName, region, bucketName, region, fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name), cloudFrontDistributionTagsJSON)
 			log.Printf("Saving JSON to create CloudFront Distribution locally at %s", cloudFrontDistributionFilepath)
 			if err := ioutil.WriteFile(cloudFrontDistributionFilepath, []byte(cloudFrontDistributionJSON), fileModeCcoctlDryRun); err != nil {
 				return "", errors.Wrap(err, fmt.Sprintf("Failed to save JSON to create CloudFront Distribution locally at %s", cloudFrontDistributionFilepath))
@@ -503,21 +678,20 @@ func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir s
 			}
 		} else {
 			log.Print("Bucket ", bucketName, " created")
-			_, err = client.PutBucketTagging(&s3.PutBucketTaggingInput{
-				Bucket: awssdk.String(bucketName),
-				Tagging: &s3.Tagging{
-					TagSet: []*s3.Tag{
-						{
-							Key:   awssdk.String(fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)),
-							Value: awssdk.String(ownedCcoctlAWSResourceTagValue),
-						},
-						{
-							Key:   awssdk.String(nameTagKey),
-							Value: awssdk.String(name),
+			provisioning.EmitResourceEvent("S3Bucket", bucketName, provisioning.ResourceCreated)
+			err = provisioning.TagResource(provisioning.TagOperation{
+				ResourceType: "S3Bucket",
+				ResourceName: bucketName,
+				Tag: func() error {
+					_, err := client.PutBucketTagging(&s3.PutBucketTaggingInput{
+						Bucket: awssdk.String(bucketName),
+						Tagging: &s3.Tagging{
+							TagSet: s3Tags(resourceTags(name, clusterID)),
 						},
-					},
+					})
+					return err
 				},
-			})
+			}, isThrottlingError)
 			if err != nil {
 				return "", errors.Wrapf(err, "failed to tag the bucket %s", bucketName)
 			}
@@ -534,6 +708,7 @@ func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir s
 				}
 				originAccessIdentityID := *createOriginAccessIdentityOutput.CloudFrontOriginAccessIdentity.Id
 				log.Printf("CloudFront origin access identity created with ID %s, waiting %s for it to become active", originAccessIdentityID, cloudFrontOriginAccessIdentityActivationGracePeriod)
+				provisioning.EmitResourceEvent("CloudFrontOriginAccessIdentity", originAccessIdentityID, provisioning.ResourceCreated)
 				// CloudFront origin access identity takes some time to become active. Adding policy to bucket before
 				// it gets active results in an error. Introducing a delay to avoid it.
 				time.Sleep(cloudFrontOriginAccessIdentityActivationGracePeriod)
@@ -606,16 +781,7 @@ func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir s
 							},
 						},
 						Tags: &cloudfront.Tags{
-							Items: []*cloudfront.Tag{
-								{
-									Key:   awssdk.String(fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)),
-									Value: awssdk.String(ownedCcoctlAWSResourceTagValue),
-								},
-								{
-									Key:   awssdk.String(nameTagKey),
-									Value: awssdk.String(name),
-								},
-							},
+							Items: cloudFrontTags(resourceTags(name, clusterID)),
 						},
 					},
 				})
@@ -624,6 +790,7 @@ func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir s
 				}
 				distributionID := *createCloudFrontDistributionOutput.Distribution.Id
 				log.Printf("CloudFront distribution created with ID %s", distributionID)
+				provisioning.EmitResourceEvent("CloudFrontDistribution", distributionID, provisioning.ResourceCreated)
 
 				for {
 					getCloudFrontDistributionOutput, err := client.GetCloudFrontDistribution(&cloudfront.GetDistributionInput{
@@ -677,14 +844,27 @@ func createOIDCEndpoint(client aws.Client, bucketName, name, region, targetDir s
 	return s3BucketURL, nil
 }
 
-// isExistingIdentifyProvider checks if given identity provider is owned by given name prefix
-func isExistingIdentifyProvider(client aws.Client, providerARN, namePrefix string) (bool, error) {
+// validateSharedIdentityProvider validates that the ID of the cluster is provided with --shared-identity-provider,
+// since the cluster tags of a shared Identity Provider record the clusters which reference it
+func validateSharedIdentityProvider(opts options) error {
+	if opts.SharedIdentityProvider && opts.ClusterID == "" {
+		return errors.New("--cluster-id is required with --shared-identity-provider to record the clusters sharing the Identity Provider")
+	}
+	return nil
+}
+
+// isExistingIdentifyProvider checks if given identity provider is owned by given name prefix and, when clusterID is
+// provided, is tagged as owned by the OpenShift cluster with ID clusterID
+func isExistingIdentifyProvider(client aws.Client, providerARN, namePrefix, clusterID string) (bool, error) {
 	provider, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
 		OpenIDConnectProviderArn: awssdk.String(providerARN),
 	})
 	if err != nil {
 		return false, errors.Wrapf(err, "failed to get Identity Provider with ARN %s", providerARN)
 	}
+	if !hasClusterResourceTag(iamTagMap(provider.Tags), clusterID) {
+		return false, nil
+	}
 
 	for _, tag := range provider.Tags {
 		if *tag.Key == fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix) {
@@ -695,7 +875,11 @@ func isExistingIdentifyProvider(client aws.Client, providerARN, namePrefix strin
 }
 
 func createIdentityProviderCmd(cmd *cobra.Command, args []string) {
-	s, err := awsSession(CreateIdentityProviderOpts.Region)
+	if err := CreateIdentityProviderOpts.DiscoveryDocument.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := awsSession(cmd.Context(), CreateIdentityProviderOpts.Region, "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -707,7 +891,7 @@ func createIdentityProviderCmd(cmd *cobra.Command, args []string) {
 		publicKeyPath = filepath.Join(CreateIdentityProviderOpts.TargetDir, provisioning.PublicKeyFile)
 	}
 
-	_, err = createIdentityProvider(awsClient, CreateIdentityProviderOpts.Name, CreateIdentityProviderOpts.Region, publicKeyPath, CreateIdentityProviderOpts.TargetDir, CreateIdentityProviderOpts.CreatePrivateS3Bucket, CreateIdentityProviderOpts.DryRun)
+	_, err = createIdentityProvider(awsClient, CreateIdentityProviderOpts.Name, CreateIdentityProviderOpts.ClusterID, CreateIdentityProviderOpts.Region, publicKeyPath, CreateIdentityProviderOpts.TargetDir, CreateIdentityProviderOpts.CreatePrivateS3Bucket, CreateIdentityProviderOpts.DryRun, CreateIdentityProviderOpts.SharedIdentityProvider, CreateIdentityProviderOpts.VerifyIssuerReachable, CreateIdentityProviderOpts.DiscoveryDocument, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -716,6 +900,16 @@ func createIdentityProviderCmd(cmd *cobra.Command, args []string) {
 // initEnvForCreateIdentityProviderCmd will ensure the destination directory is ready to receive the generated
 // files, and will create the directory if necessary.
 func initEnvForCreateIdentityProviderCmd(cmd *cobra.Command, args []string) {
+	if err := provisioning.ValidateClusterID(CreateIdentityProviderOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+	if err := validateSharedIdentityProvider(CreateIdentityProviderOpts); err != nil {
+		log.Fatal(err)
+	}
+
+	if CreateIdentityProviderOpts.TargetDir == "" && CreateIdentityProviderOpts.DryRun {
+		CreateIdentityProviderOpts.TargetDir = provisioning.DryRunOutputDir("aws")
+	}
 	if CreateIdentityProviderOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -759,8 +953,13 @@ func NewCreateIdentityProviderCmd() *cobra.Command {
 	createIdentityProviderCmd.MarkPersistentFlagRequired("region")
 	createIdentityProviderCmd.PersistentFlags().StringVar(&CreateIdentityProviderOpts.PublicKeyPath, "public-key-file", "", "Path to public ServiceAccount signing key")
 	createIdentityProviderCmd.PersistentFlags().BoolVar(&CreateIdentityProviderOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
-	createIdentityProviderCmd.PersistentFlags().StringVar(&CreateIdentityProviderOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
+	createIdentityProviderCmd.PersistentFlags().BoolVar(&CreateIdentityProviderOpts.VerifyIssuerReachable, "verify-issuer-reachable", false, "Fetch the OIDC discovery document and JSON web key set from the issuer URL over the public network once they have been uploaded, honoring the HTTPS_PROXY and NO_PROXY environment variables, and fail if they are not publicly served. Skipped with --dry-run")
+	createIdentityProviderCmd.PersistentFlags().StringSliceVar(&CreateIdentityProviderOpts.DiscoveryDocument.Audiences, "oidc-extra-audience", nil, "Audience to append to the audiences_supported of the OIDC discovery document, eg. for an identity consumer of the issuer other than the cluster. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createIdentityProviderCmd.PersistentFlags().StringSliceVar(&CreateIdentityProviderOpts.DiscoveryDocument.Claims, "oidc-extra-claim", nil, "Claim to append to the claims_supported of the OIDC discovery document. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createIdentityProviderCmd.PersistentFlags().StringVar(&CreateIdentityProviderOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory, or a temporary directory when --dry-run is specified)")
 	createIdentityProviderCmd.PersistentFlags().BoolVar(&CreateIdentityProviderOpts.CreatePrivateS3Bucket, "create-private-s3-bucket", false, "Create private S3 bucket with public CloudFront OIDC endpoint")
+	createIdentityProviderCmd.PersistentFlags().StringVar(&CreateIdentityProviderOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag created AWS resources, the resources will be tagged with 'kubernetes.io/cluster/<cluster ID> = owned'")
+	createIdentityProviderCmd.PersistentFlags().BoolVar(&CreateIdentityProviderOpts.SharedIdentityProvider, "shared-identity-provider", false, sharedIdentityProviderFlagUsage)
 
 	return createIdentityProviderCmd
 }
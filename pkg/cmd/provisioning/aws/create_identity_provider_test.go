@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/service/cloudfront"
 	"github.com/aws/aws-sdk-go/service/iam"
@@ -57,6 +58,7 @@ func TestCreateIdentityProvider(t *testing.T) {
 		cleanup         func(*testing.T)
 		createPrivateS3 bool
 		generateOnly    bool
+		clusterID       string
 		expectError     bool
 	}{
 		{
@@ -245,6 +247,44 @@ func TestCreateIdentityProvider(t *testing.T) {
 			generateOnly:    true,
 			expectError:     false,
 		},
+		{
+			name: "generate files only, with private S3 bucket and CloudFront distribution tagged with cluster ID",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				return mockAWSClient
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory")
+
+				err = ioutil.WriteFile(filepath.Join(tempDirName, testPublicKeyFile), []byte(testPublicKeyData), 0600)
+				require.NoError(t, err, "errored while setting up environment for test")
+
+				return tempDirName
+			},
+			verify: func(t *testing.T, tempDirName string) {
+				cloudFrontDistribution, err := ioutil.ReadFile(filepath.Join(tempDirName, cloudFrontDistributionFilename))
+				require.NoError(t, err, "error reading in CloudFront distribution")
+
+				var cloudFrontDistributionJSON struct {
+					DistributionConfigWithTags struct {
+						Tags struct {
+							Items []resourceTag
+						}
+					}
+				}
+				err = json.Unmarshal(cloudFrontDistribution, &cloudFrontDistributionJSON)
+				require.NoError(t, err, "CloudFront distribution is not a JSON")
+				assert.Equal(t, []resourceTag{
+					{Key: nameTagKey, Value: testInfraName},
+					{Key: fmt.Sprintf("kubernetes.io/cluster/%s", testClusterID), Value: "owned"},
+				}, cloudFrontDistributionJSON.DistributionConfigWithTags.Tags.Items, "unexpected CloudFront distribution tags")
+			},
+			createPrivateS3: true,
+			generateOnly:    true,
+			clusterID:       testClusterID,
+			expectError:     false,
+		},
 	}
 
 	for _, test := range tests {
@@ -259,7 +299,7 @@ func TestCreateIdentityProvider(t *testing.T) {
 
 			testPublicKeyPath := filepath.Join(tempDirName, testPublicKeyFile)
 
-			_, err := createIdentityProvider(mockAWSClient, testInfraName, testRegionName, testPublicKeyPath, tempDirName, test.createPrivateS3, test.generateOnly)
+			_, err := createIdentityProvider(mockAWSClient, testInfraName, test.clusterID, testRegionName, testPublicKeyPath, tempDirName, test.createPrivateS3, test.generateOnly, false, false, provisioning.DiscoveryDocumentExtensions{}, nil)
 
 			if test.expectError {
 				require.Error(t, err, "expected error returned")
@@ -344,6 +384,143 @@ func mockPutBucketPolicy(mockAWSClient *mockaws.MockClient) {
 		&s3.PutBucketPolicyOutput{}, nil).AnyTimes()
 }
 
+func TestCreateSharedIAMIdentityProvider(t *testing.T) {
+	const (
+		testProviderARN = "arn:aws:iam::123456789012:oidc-provider/test-infra-name-oidc.s3.test-region.amazonaws.com"
+		testIssuerURL   = "https://test-infra-name-oidc.s3.test-region.amazonaws.com"
+	)
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testInfraName)
+
+	tests := []struct {
+		name               string
+		mockAWSClient      func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		expectError        bool
+		expectTaggingError bool
+	}{
+		{
+			name: "Identity provider for the issuer URL created by another name shared",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, "test-infra-name-oidc.s3.test-region.amazonaws.com", []string{"openshift"}, map[string]string{
+					fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, "other-name"): ownedCcoctlAWSResourceTagValue,
+					nameTagKey:                        "other-name",
+					clusterResourceTagKey("other-id"): provisioning.ClusterResourceTagValue,
+				})
+				mockAWSClient.EXPECT().AddClientIDToOpenIDConnectProvider(&iam.AddClientIDToOpenIDConnectProviderInput{
+					OpenIDConnectProviderArn: awssdk.String(testProviderARN),
+					ClientID:                 awssdk.String("sts.amazonaws.com"),
+				}).Return(&iam.AddClientIDToOpenIDConnectProviderOutput{}, nil)
+				mockAWSClient.EXPECT().TagOpenIDConnectProvider(&iam.TagOpenIDConnectProviderInput{
+					OpenIDConnectProviderArn: awssdk.String(testProviderARN),
+					Tags: iamTags([]resourceTag{
+						{Key: ownedTagKey, Value: ownedCcoctlAWSResourceTagValue},
+						{Key: clusterResourceTagKey(testClusterID), Value: provisioning.ClusterResourceTagValue},
+					}),
+				}).Return(&iam.TagOpenIDConnectProviderOutput{}, nil)
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Identity provider already shared with the cluster left unchanged",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, "test-infra-name-oidc.s3.test-region.amazonaws.com", identityProviderClientIDs, map[string]string{
+					ownedTagKey:                          ownedCcoctlAWSResourceTagValue,
+					nameTagKey:                           testInfraName,
+					clusterResourceTagKey(testClusterID): provisioning.ClusterResourceTagValue,
+				})
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Throttled tagging of the shared identity provider retried",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, "test-infra-name-oidc.s3.test-region.amazonaws.com", identityProviderClientIDs, map[string]string{})
+				gomock.InOrder(
+					mockAWSClient.EXPECT().TagOpenIDConnectProvider(gomock.Any()).Return(nil, awserr.New("Throttling", "Rate exceeded", nil)),
+					mockAWSClient.EXPECT().TagOpenIDConnectProvider(gomock.Any()).Return(&iam.TagOpenIDConnectProviderOutput{}, nil),
+				)
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Failure to tag the shared identity provider reported as a tagging failure",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, "test-infra-name-oidc.s3.test-region.amazonaws.com", identityProviderClientIDs, map[string]string{})
+				mockAWSClient.EXPECT().TagOpenIDConnectProvider(gomock.Any()).Return(nil, awserr.New("AccessDenied", "access denied", nil))
+				return mockAWSClient
+			},
+			expectError:        true,
+			expectTaggingError: true,
+		},
+		{
+			name: "Failure to add client ID",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, "test-infra-name-oidc.s3.test-region.amazonaws.com", []string{}, map[string]string{})
+				mockAWSClient.EXPECT().AddClientIDToOpenIDConnectProvider(gomock.Any()).Return(nil, fmt.Errorf("access denied"))
+				return mockAWSClient
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			providerARN, err := createIAMIdentityProvider(test.mockAWSClient(mockCtrl), testIssuerURL, testInfraName, testClusterID, "", false, true)
+			if test.expectError {
+				require.Error(t, err, "expected error returned")
+				assert.Equal(t, test.expectTaggingError, provisioning.IsTaggingError(err), "unexpected kind of error returned")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, testProviderARN, providerARN, "unexpected Identity Provider ARN")
+			}
+		})
+	}
+}
+
+func TestValidateSharedIdentityProvider(t *testing.T) {
+	assert.NoError(t, validateSharedIdentityProvider(options{}))
+	assert.NoError(t, validateSharedIdentityProvider(options{SharedIdentityProvider: true, ClusterID: testClusterID}))
+	assert.Error(t, validateSharedIdentityProvider(options{SharedIdentityProvider: true}))
+}
+
+func mockListOpenIDConnectProvidersWithARNs(mockAWSClient *mockaws.MockClient, providerARNs ...string) {
+	providers := []*iam.OpenIDConnectProviderListEntry{}
+	for _, providerARN := range providerARNs {
+		providers = append(providers, &iam.OpenIDConnectProviderListEntry{Arn: awssdk.String(providerARN)})
+	}
+	mockAWSClient.EXPECT().ListOpenIDConnectProviders(gomock.Any()).Return(
+		&iam.ListOpenIDConnectProvidersOutput{
+			OpenIDConnectProviderList: providers,
+		}, nil).AnyTimes()
+}
+
+func mockGetTaggedOpenIDConnectProvider(mockAWSClient *mockaws.MockClient, providerARN, url string, clientIDs []string, tags map[string]string) {
+	providerTags := []resourceTag{}
+	for key, value := range tags {
+		providerTags = append(providerTags, resourceTag{Key: key, Value: value})
+	}
+	mockAWSClient.EXPECT().GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(providerARN),
+	}).Return(
+		&iam.GetOpenIDConnectProviderOutput{
+			Url:          awssdk.String(url),
+			ClientIDList: awssdk.StringSlice(clientIDs),
+			Tags:         iamTags(providerTags),
+		}, nil).AnyTimes()
+}
+
 func TestDeterminePartitionForRegion(t *testing.T) {
 	tests := []struct {
 		region string
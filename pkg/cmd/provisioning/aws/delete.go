@@ -1,18 +1,25 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudfront"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	configv1 "github.com/openshift/api/config/v1"
+
 	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 )
 
 var (
@@ -21,8 +28,9 @@ var (
 	DeleteOpts = options{}
 )
 
-// deleteOIDCObjectsFromBucket deletes the OIDC objects from the S3 bucket
-func deleteOIDCObjectsFromBucket(client aws.Client, bucketName, namePrefix string) error {
+// deleteOIDCObjectsFromBucket deletes the OIDC objects from the S3 bucket. When clusterID is provided only the
+// objects tagged as owned by the OpenShift cluster with ID clusterID are deleted.
+func deleteOIDCObjectsFromBucket(client aws.Client, bucketName, namePrefix, clusterID string) error {
 	objectsMetadata, err := client.ListObjects(&s3.ListObjectsInput{
 		Bucket: awssdk.String(bucketName),
 	})
@@ -38,6 +46,10 @@ func deleteOIDCObjectsFromBucket(client aws.Client, bucketName, namePrefix strin
 		if err != nil {
 			return errors.Wrapf(err, "failed to fetch tags of Identity Provider object %s in the bucket %s", *objectMetadata.Key, bucketName)
 		}
+		if !hasClusterResourceTag(s3TagMap(objectTags.TagSet), clusterID) {
+			log.Printf("Skipping Identity Provider object %s in the bucket %s which is not tagged with %s", *objectMetadata.Key, bucketName, clusterResourceTagKey(clusterID))
+			continue
+		}
 
 		for _, tag := range objectTags.TagSet {
 			if *tag.Key == fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix) {
@@ -49,6 +61,7 @@ func deleteOIDCObjectsFromBucket(client aws.Client, bucketName, namePrefix strin
 					return errors.Wrapf(err, "failed to delete Identity Provider object %s in the bucket %s", *objectMetadata.Key, bucketName)
 				}
 				log.Printf("Identity Provider object %s deleted from the bucket %s", *objectMetadata.Key, bucketName)
+				provisioning.EmitResourceEvent("S3Object", bucketName+"/"+*objectMetadata.Key, provisioning.ResourceDeleted)
 				break
 			}
 		}
@@ -57,14 +70,26 @@ func deleteOIDCObjectsFromBucket(client aws.Client, bucketName, namePrefix strin
 	return nil
 }
 
-// deleteOIDCBucket deletes the OIDC S3 bucket
-func deleteOIDCBucket(client aws.Client, bucketName, namePrefix string) error {
+// deleteOIDCBucket deletes the OIDC S3 bucket. When clusterID is provided the bucket is only deleted if it is
+// tagged as owned by the OpenShift cluster with ID clusterID.
+func deleteOIDCBucket(client aws.Client, bucketName, namePrefix, clusterID string) error {
+	_, span := provisioning.StartSpan(context.Background(), "aws.DeleteOIDCBucket",
+		provisioning.ProviderAttribute.String("aws"),
+		provisioning.ResourceTypeAttribute.String("s3Bucket"),
+		provisioning.ResourceNameAttribute.String(bucketName),
+	)
+	defer span.End()
+
 	bucketTags, err := client.GetBucketTagging(&s3.GetBucketTaggingInput{
 		Bucket: awssdk.String(bucketName),
 	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to fetch tags of the bucket %s", bucketName)
 	}
+	if !hasClusterResourceTag(s3TagMap(bucketTags.TagSet), clusterID) {
+		log.Printf("Skipping Identity Provider bucket %s which is not tagged with %s", bucketName, clusterResourceTagKey(clusterID))
+		return nil
+	}
 
 	for _, tag := range bucketTags.TagSet {
 		if *tag.Key == fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix) {
@@ -75,6 +100,7 @@ func deleteOIDCBucket(client aws.Client, bucketName, namePrefix string) error {
 				return errors.Wrapf(err, "failed to delete the Identity Provider bucket %s", bucketName)
 			}
 			log.Printf("Identity Provider bucket %s deleted", bucketName)
+			provisioning.EmitResourceEvent("S3Bucket", bucketName, provisioning.ResourceDeleted)
 			break
 		}
 	}
@@ -104,13 +130,15 @@ func deleteCloudFrontOriginAccessIdentity(client aws.Client, namePrefix string)
 				return errors.Wrapf(err, "failed to delete the CloudFront origin access identity with ID %s", *originAccessIdentity.Id)
 			}
 			log.Printf("CloudFront origin access identity with ID %s deleted", *originAccessIdentity.Id)
+			provisioning.EmitResourceEvent("CloudFrontOriginAccessIdentity", *originAccessIdentity.Id, provisioning.ResourceDeleted)
 		}
 	}
 	return nil
 }
 
-// deleteCloudFrontDistribution deletes the CloudFront distribution if created
-func deleteCloudFrontDistribution(client aws.Client, namePrefix string) error {
+// deleteCloudFrontDistribution deletes the CloudFront distribution if created. When clusterID is provided the
+// distribution is only deleted if it is tagged as owned by the OpenShift cluster with ID clusterID.
+func deleteCloudFrontDistribution(client aws.Client, namePrefix, clusterID string) error {
 	ListCloudFrontDistributionsOutput, err := client.ListCloudFrontDistributions(&cloudfront.ListDistributionsInput{})
 	if err != nil {
 		return errors.Wrapf(err, "failed to fetch a list of CloudFront distributions")
@@ -122,6 +150,9 @@ func deleteCloudFrontDistribution(client aws.Client, namePrefix string) error {
 		if err != nil {
 			return errors.Wrapf(err, "failed to fetch tags for CloudFront distribution with ID %s", *distribution.Id)
 		}
+		if !hasClusterResourceTag(cloudFrontTagMap(listTagsForCloudFrontResourceOutput.Tags.Items), clusterID) {
+			continue
+		}
 
 		for _, tag := range listTagsForCloudFrontResourceOutput.Tags.Items {
 			if *tag.Key == fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix) {
@@ -167,6 +198,7 @@ func deleteCloudFrontDistribution(client aws.Client, namePrefix string) error {
 					return errors.Wrapf(err, "failed to delete CloudFront distribution with ID %s", *distribution.Id)
 				}
 				log.Printf("CloudFront distribution with ID %s deleted", *distribution.Id)
+				provisioning.EmitResourceEvent("CloudFrontDistribution", *distribution.Id, provisioning.ResourceDeleted)
 				break
 			}
 		}
@@ -175,8 +207,9 @@ func deleteCloudFrontDistribution(client aws.Client, namePrefix string) error {
 	return nil
 }
 
-// deleteIAMRoles deletes the IAM Roles created by ccoctl
-func deleteIAMRoles(client aws.Client, namePrefix string, paginationMarker *string) error {
+// deleteIAMRoles deletes the IAM Roles created by ccoctl. When clusterID is provided only the roles tagged as owned
+// by the OpenShift cluster with ID clusterID are deleted.
+func deleteIAMRoles(client aws.Client, namePrefix, clusterID string, paginationMarker *string) error {
 	// iam.ListRolesInput results are paginated to 100 items by default, if result is truncated we need to
 	// fetch next set of items and perform delete operation
 	roleList, err := client.ListRoles(&iam.ListRolesInput{
@@ -193,34 +226,54 @@ func deleteIAMRoles(client aws.Client, namePrefix string, paginationMarker *stri
 		if err != nil {
 			return errors.Wrapf(err, "failed to fetch IAM role %s", *roleMetadata.RoleName)
 		}
+		if !hasClusterResourceTag(iamTagMap(roleOutput.Role.Tags), clusterID) {
+			continue
+		}
 
 		for _, tag := range roleOutput.Role.Tags {
 			if *tag.Key == fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix) {
-				if err := deleteRolePolicies(client, *roleOutput.Role.RoleName); err != nil {
-					return errors.Wrapf(err, "failed to delete policies associated with IAM Role %s", *roleOutput.Role.RoleName)
+				if err := deleteIAMRole(client, roleOutput.Role, namePrefix); err != nil {
+					return err
 				}
-
-				_, err := client.DeleteRole(&iam.DeleteRoleInput{
-					RoleName: roleOutput.Role.RoleName,
-				})
-				if err != nil {
-					return errors.Wrapf(err, "failed to delete IAM Role %s", *roleOutput.Role.RoleName)
-				}
-				log.Printf("IAM Role %s deleted", *roleOutput.Role.RoleName)
 				break
 			}
 		}
 	}
 
 	if *roleList.IsTruncated {
-		return deleteIAMRoles(client, namePrefix, roleList.Marker)
+		return deleteIAMRoles(client, namePrefix, clusterID, roleList.Marker)
 	}
 
 	return nil
 }
 
-// deleteRolePolicies deletes the Polices associated with IAM Role created by ccoctl
-func deleteRolePolicies(client aws.Client, roleName string) error {
+// deleteIAMRole deletes the IAM Role created by ccoctl for namePrefix along with its policies
+func deleteIAMRole(client aws.Client, role *iam.Role, namePrefix string) error {
+	_, span := provisioning.StartSpan(context.Background(), "aws.DeleteIAMRole",
+		provisioning.ProviderAttribute.String("aws"),
+		provisioning.ResourceTypeAttribute.String("iamRole"),
+		provisioning.ResourceNameAttribute.String(*role.RoleName),
+	)
+	defer span.End()
+
+	if err := deleteRolePolicies(client, *role.RoleName, namePrefix); err != nil {
+		return errors.Wrapf(err, "failed to delete policies associated with IAM Role %s", *role.RoleName)
+	}
+
+	_, err := client.DeleteRole(&iam.DeleteRoleInput{
+		RoleName: role.RoleName,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete IAM Role %s", *role.RoleName)
+	}
+	log.Printf("IAM Role %s deleted", *role.RoleName)
+	provisioning.EmitResourceEvent(iamRoleInventoryResourceType, awssdk.StringValue(role.Arn), provisioning.ResourceDeleted)
+	return nil
+}
+
+// deleteRolePolicies deletes the Polices associated with IAM Role created by ccoctl. Every managed policy is detached
+// from the IAM Role, the managed policies created by ccoctl for namePrefix with --policy-style are deleted.
+func deleteRolePolicies(client aws.Client, roleName, namePrefix string) error {
 	policies, err := client.ListRolePolicies(&iam.ListRolePoliciesInput{
 		RoleName: awssdk.String(roleName),
 	})
@@ -239,30 +292,70 @@ func deleteRolePolicies(client aws.Client, roleName string) error {
 		log.Printf("Policy %s associated with IAM Role %s deleted", *policyName, roleName)
 	}
 
+	attachedPolicies, err := client.ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{
+		RoleName: awssdk.String(roleName),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch a list of policies attached to IAM role %s", roleName)
+	}
+
+	for _, attachedPolicy := range attachedPolicies.AttachedPolicies {
+		_, err := client.DetachRolePolicy(&iam.DetachRolePolicyInput{
+			RoleName:  awssdk.String(roleName),
+			PolicyArn: attachedPolicy.PolicyArn,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to detach policy %s from IAM Role %s", *attachedPolicy.PolicyArn, roleName)
+		}
+		log.Printf("Policy %s detached from IAM Role %s", *attachedPolicy.PolicyArn, roleName)
+
+		policyOutput, err := client.GetPolicy(&iam.GetPolicyInput{
+			PolicyArn: attachedPolicy.PolicyArn,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch policy %s", *attachedPolicy.PolicyArn)
+		}
+		if _, owned := iamTagMap(policyOutput.Policy.Tags)[fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix)]; !owned {
+			continue
+		}
+		if err := deleteManagedPolicy(client, *attachedPolicy.PolicyArn); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// deleteIAMIdentityProvider deletes the IAM Identity Provider
-func deleteIAMIdentityProvider(client aws.Client, namePrefix string) error {
+// deleteIAMIdentityProvider deletes the IAM Identity Provider. When clusterID is provided the Identity Provider is
+// only deleted if it is tagged as owned by the OpenShift cluster with ID clusterID.
+//
+// An Identity Provider shared with --shared-identity-provider is tagged as owned by every cluster sharing it and is
+// only deleted along with the last of them, otherwise only the tag of the cluster with ID clusterID is removed.
+func deleteIAMIdentityProvider(client aws.Client, namePrefix, clusterID string) error {
 	oidcProviderList, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
 	if err != nil {
 		return errors.Wrap(err, "failed to fetch list of Identity Providers")
 	}
 
 	for _, provider := range oidcProviderList.OpenIDConnectProviderList {
-		ok, err := isExistingIdentifyProvider(client, *provider.Arn, namePrefix)
+		ok, err := isExistingIdentifyProvider(client, *provider.Arn, namePrefix, clusterID)
 		if err != nil {
 			return errors.Wrapf(err, "failed to check for existing Identity Provider")
 		}
 
 		if ok {
-			_, err := client.DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{
+			shared, err := unshareIdentityProvider(client, *provider.Arn, clusterID)
+			if err != nil || shared {
+				return err
+			}
+			_, err = client.DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{
 				OpenIDConnectProviderArn: awssdk.String(*provider.Arn),
 			})
 			if err != nil {
 				return errors.Wrapf(err, "failed to delete Identity Provider with ARN %s", *provider.Arn)
 			}
 			log.Printf("Identity Provider with ARN %s deleted", *provider.Arn)
+			provisioning.EmitResourceEvent("IAMIdentityProvider", *provider.Arn, provisioning.ResourceDeleted)
 			break
 		}
 	}
@@ -270,38 +363,197 @@ func deleteIAMIdentityProvider(client aws.Client, namePrefix string) error {
 	return nil
 }
 
-func deleteCmd(cmd *cobra.Command, args []string) {
-	s, err := awsSession(DeleteOpts.Region)
+// unshareIdentityProvider returns true when the Identity Provider identified by providerARN is still shared with
+// OpenShift clusters other than the cluster with ID clusterID and must not be deleted, in which case the tag of the
+// cluster with ID clusterID is removed from the Identity Provider. An error is returned when the Identity Provider is
+// shared by several clusters and clusterID is not provided since it cannot be known which cluster is being deleted.
+func unshareIdentityProvider(client aws.Client, providerARN, clusterID string) (bool, error) {
+	provider, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(providerARN),
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get Identity Provider with ARN %s", providerARN)
+	}
+
+	otherClusterIDs := []string{}
+	for _, sharingClusterID := range sharingClusterIDs(iamTagMap(provider.Tags)) {
+		if sharingClusterID != clusterID {
+			otherClusterIDs = append(otherClusterIDs, sharingClusterID)
+		}
+	}
+	switch {
+	case clusterID == "" && len(otherClusterIDs) > 1:
+		return true, fmt.Errorf("refusing to delete Identity Provider with ARN %s which is shared by clusters %s, "+
+			"use --cluster-id to identify the cluster whose use of the Identity Provider should be removed",
+			providerARN, strings.Join(otherClusterIDs, ", "))
+	case clusterID == "" || len(otherClusterIDs) == 0:
+		return false, nil
+	}
+
+	_, err = client.UntagOpenIDConnectProvider(&iam.UntagOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(providerARN),
+		TagKeys:                  []*string{awssdk.String(clusterResourceTagKey(clusterID))},
+	})
+	if err != nil {
+		return true, errors.Wrapf(err, "failed to untag Identity Provider with ARN %s", providerARN)
+	}
+	log.Printf("Identity Provider with ARN %s is still shared by clusters %s, removed its tag for cluster %s rather than deleting it",
+		providerARN, strings.Join(otherClusterIDs, ", "), clusterID)
+	return true, nil
+}
+
+// discoverIssuerURL returns the URL of the IAM Identity Provider which will be deleted for namePrefix and, when
+// clusterID is provided, the OpenShift cluster with ID clusterID, or an empty string when none is found
+func discoverIssuerURL(client aws.Client, namePrefix, clusterID string) (string, string, error) {
+	oidcProviderList, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
 	if err != nil {
+		return "", "", errors.Wrap(err, "failed to fetch list of Identity Providers")
+	}
+
+	for _, provider := range oidcProviderList.OpenIDConnectProviderList {
+		ok, err := isExistingIdentifyProvider(client, *provider.Arn, namePrefix, clusterID)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to check for existing Identity Provider")
+		}
+		if ok {
+			output, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+				OpenIDConnectProviderArn: provider.Arn,
+			})
+			if err != nil {
+				return "", "", errors.Wrapf(err, "failed to get Identity Provider with ARN %s", *provider.Arn)
+			}
+			return *provider.Arn, awssdk.StringValue(output.Url), nil
+		}
+	}
+	return "", "", nil
+}
+
+// verifyIssuerURL ensures that the IAM Identity Provider which will be deleted for namePrefix was created for
+// issuerURL before any resource is deleted
+func verifyIssuerURL(client aws.Client, namePrefix, clusterID, issuerURL string) error {
+	providerARN, discoveredIssuerURL, err := discoverIssuerURL(client, namePrefix, clusterID)
+	if err != nil {
+		return err
+	}
+	return provisioning.VerifyDiscoveredIssuerURL(issuerURL, discoveredIssuerURL, fmt.Sprintf("Identity Provider with ARN %s", providerARN))
+}
+
+func deleteCmd(cmd *cobra.Command, args []string) {
+	if err := provisioning.ResolveNameFromKubeconfig(cmd.Context(), &DeleteOpts.Name, DeleteOpts.NameFromKubeconfig, DeleteOpts.ConfirmNameFromKubeconfig, configv1.AWSPlatformType); err != nil {
+		log.Fatal(err)
+	}
+	if err := provisioning.ValidateClusterID(DeleteOpts.ClusterID); err != nil {
 		log.Fatal(err)
 	}
 
-	awsClient := aws.NewClientFromSession(s)
-	bucketName := fmt.Sprintf("%s-oidc", DeleteOpts.Name)
+	// Credentials are loaded from the default locations unless profiles of other accounts are provided
+	profiles := []string{""}
+	if len(DeleteOpts.Profiles) > 0 {
+		var err error
+		profiles, err = provisioning.UniqueScopes("--profile", DeleteOpts.Profiles)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	if err := deleteOIDCObjectsFromBucket(awsClient, bucketName, DeleteOpts.Name); err != nil {
-		log.Print(err)
+	// Resources are also deleted within the regions recorded by create-all within the inventory
+	regions := DeleteOpts.Regions
+	if DeleteOpts.InventoryPath != "" {
+		recordedRegions, err := regionsFromInventory(DeleteOpts.InventoryPath, DeleteOpts.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		regions = append(regions, recordedRegions...)
+	}
+	regions, err := validateRegions(regions)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	if err := deleteOIDCBucket(awsClient, bucketName, DeleteOpts.Name); err != nil {
-		log.Print(err)
+	err = provisioning.DeleteAcrossScopes("profile", profiles, DeleteOpts.ContinueOnError, func(profile string) error {
+		// Global resources are deleted through the client of the first region
+		clients := make([]regionalClient, 0, len(regions))
+		var s *session.Session
+		for _, region := range regions {
+			regionalSession, err := awsSession(cmd.Context(), region, profile)
+			if err != nil {
+				return err
+			}
+			if s == nil {
+				s = regionalSession
+			}
+			clients = append(clients, regionalClient{region: region, client: aws.NewClientFromSession(regionalSession)})
+		}
+		awsClient := clients[0].client
+		// Resources are only deleted once they have been attributed to the issuer URL, the failure to do so is
+		// returned even when deleting within a single account since nothing has been deleted
+		if DeleteOpts.IssuerURL != "" {
+			if err := verifyIssuerURL(awsClient, DeleteOpts.Name, DeleteOpts.ClusterID, DeleteOpts.IssuerURL); err != nil {
+				return err
+			}
+		}
+		// Likewise with --fail-on-insufficient-permissions when the identity lacks permissions required for deletion
+		if err := checkDeletionPermissions(sts.New(s), awsClient, DeleteOpts.Name, DeleteOpts.FailOnInsufficientPermissions); err != nil {
+			return err
+		}
+		err = deleteWithinAccount(clients, DeleteOpts.Name, DeleteOpts.ClusterID)
+		// Resources remaining once deletion completes fail the deletion even within a single account
+		if DeleteOpts.VerifyAfterDelete {
+			provisioning.SetPhase("verifying that no resources remain")
+			if err := provisioning.VerifyDeletion(func() ([]string, error) {
+				return listRemainingResources(clients, DeleteOpts.Name, DeleteOpts.ClusterID)
+			}); err != nil {
+				return err
+			}
+		}
+		// Errors deleting the resources of a single account have already been logged and, as deletion is expected
+		// to be re-run after a partial failure, only result in a failure when deleting across several accounts
+		if len(profiles) == 1 {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// deleteWithinAccount deletes the resources created by ccoctl for name within the account of the clients. Regional
+// resources are deleted within the region of each client, global resources once through the first client. Deletion
+// carries on after an error so that as many resources as possible are deleted, the errors encountered are returned.
+func deleteWithinAccount(clients []regionalClient, name, clusterID string) error {
+	errs := []error{}
+	for _, client := range clients {
+		errs = append(errs, deleteRegionalResources(client, name, clusterID, len(clients) > 1)...)
 	}
 
-	if err := deleteCloudFrontDistribution(awsClient, DeleteOpts.Name); err != nil {
+	awsClient := clients[0].client
+
+	provisioning.SetPhase("deleting the CloudFront distribution")
+	if err := deleteCloudFrontDistribution(awsClient, name, clusterID); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteCloudFrontOriginAccessIdentity(awsClient, DeleteOpts.Name); err != nil {
+	provisioning.SetPhase("deleting the CloudFront origin access identity")
+	if err := deleteCloudFrontOriginAccessIdentity(awsClient, name); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteIAMRoles(awsClient, DeleteOpts.Name, nil); err != nil {
+	provisioning.SetPhase("deleting IAM Roles")
+	if err := deleteIAMRoles(awsClient, name, clusterID, nil); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteIAMIdentityProvider(awsClient, DeleteOpts.Name); err != nil {
+	provisioning.SetPhase("deleting the IAM Identity Provider")
+	if err := deleteIAMIdentityProvider(awsClient, name, clusterID); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
+
+	return provisioning.JoinDeletionErrors(errs)
 }
 
 // NewDeleteCmd implements the "delete" command for the credentials provisioning
@@ -313,10 +565,17 @@ func NewDeleteCmd() *cobra.Command {
 		Run:   deleteCmd,
 	}
 
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Name, "name", "", "User-defined name for all created AWS resources (can be separate from the cluster's infra-id)")
-	deleteCmd.MarkPersistentFlagRequired("name")
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Region, "region", "", "AWS region where the resources were created")
-	deleteCmd.MarkPersistentFlagRequired("region")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Name, "name", "", "User-defined name for all created AWS resources (can be separate from the cluster's infra-id). Required unless --name-from-kubeconfig is specified")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.NameFromKubeconfig, "name-from-kubeconfig", false, "Delete the resources named after the infrastructure name of the cluster of the current context of the kubeconfig, read from its infrastructures.config.openshift.io object, rather than --name. Requires --confirm-name-from-kubeconfig")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.ConfirmNameFromKubeconfig, "confirm-name-from-kubeconfig", false, "Confirm the deletion of the resources named after the infrastructure name discovered with --name-from-kubeconfig")
+	deleteCmd.PersistentFlags().StringSliceVar(&DeleteOpts.Regions, "region", nil, "AWS region where the resources were created. May be specified multiple times, or as a comma-separated list, to delete the regional resources within each region. Global resources are deleted through the first region. Required unless --inventory is specified")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.InventoryPath, "inventory", "", fmt.Sprintf("Path of the %s written by create-all, or of the output directory containing it, to also delete resources within the regions it records", provisioning.InventoryFileName))
+	deleteCmd.PersistentFlags().StringSliceVar(&DeleteOpts.Profiles, "profile", nil, "Profile of the shared AWS configuration files with which to authenticate. May be specified multiple times, or as a comma-separated list, to delete the resources within the account of each profile in turn. If not specified, credentials are loaded from the default locations")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.ContinueOnError, "continue-on-error", false, "Continue deleting resources within the accounts of the remaining profiles when deletion within an account fails. Deletion fails overall if it failed within any account")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.ClusterID, "cluster-id", "", "Only delete resources which are tagged with 'kubernetes.io/cluster/<cluster ID> = owned' by ccoctl when the resources were created with --cluster-id")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.IssuerURL, "issuer-url", "", "Only delete resources when the IAM Identity Provider created for --name was created for this OIDC issuer URL, eg. https://<name>-oidc.s3.<region>.amazonaws.com. Guards against deleting the resources of another cluster created with the same name")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.VerifyAfterDelete, provisioning.VerifyAfterDeleteFlag, false, provisioning.VerifyAfterDeleteFlagUsage)
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.FailOnInsufficientPermissions, "fail-on-insufficient-permissions", false, "Exit without deleting any resource when the IAM policy simulator finds that the current identity lacks permissions required to delete them, or cannot be run. Otherwise the missing permissions are reported as warnings before deletion proceeds")
 
 	return deleteCmd
 }
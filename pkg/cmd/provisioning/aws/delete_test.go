@@ -0,0 +1,200 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestDeleteSharedIAMIdentityProvider(t *testing.T) {
+	const (
+		testProviderARN = "arn:aws:iam::123456789012:oidc-provider/test-infra-name-oidc.s3.test-region.amazonaws.com"
+		testURL         = "test-infra-name-oidc.s3.test-region.amazonaws.com"
+	)
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testInfraName)
+
+	tests := []struct {
+		name          string
+		mockAWSClient func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		clusterID     string
+		expectError   bool
+	}{
+		{
+			name: "Identity provider shared by another cluster untagged",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey:                          ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey(testClusterID): provisioning.ClusterResourceTagValue,
+					clusterResourceTagKey("other-id"):    provisioning.ClusterResourceTagValue,
+				})
+				mockAWSClient.EXPECT().UntagOpenIDConnectProvider(&iam.UntagOpenIDConnectProviderInput{
+					OpenIDConnectProviderArn: awssdk.String(testProviderARN),
+					TagKeys:                  []*string{awssdk.String(clusterResourceTagKey(testClusterID))},
+				}).Return(&iam.UntagOpenIDConnectProviderOutput{}, nil)
+				return mockAWSClient
+			},
+			clusterID: testClusterID,
+		},
+		{
+			name: "Identity provider of the last cluster sharing it deleted",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey:                          ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey(testClusterID): provisioning.ClusterResourceTagValue,
+				})
+				mockDeleteOpenIDConnectProvider(mockAWSClient, testProviderARN)
+				return mockAWSClient
+			},
+			clusterID: testClusterID,
+		},
+		{
+			name: "Identity provider of a single cluster deleted without cluster ID",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey:                          ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey(testClusterID): provisioning.ClusterResourceTagValue,
+				})
+				mockDeleteOpenIDConnectProvider(mockAWSClient, testProviderARN)
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Identity provider shared by several clusters not deleted without cluster ID",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey:                          ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey(testClusterID): provisioning.ClusterResourceTagValue,
+					clusterResourceTagKey("other-id"):    provisioning.ClusterResourceTagValue,
+				})
+				return mockAWSClient
+			},
+			expectError: true,
+		},
+		{
+			name: "Identity provider of another cluster not deleted",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey:                       ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey("other-id"): provisioning.ClusterResourceTagValue,
+				})
+				return mockAWSClient
+			},
+			clusterID: testClusterID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := deleteIAMIdentityProvider(test.mockAWSClient(mockCtrl), testInfraName, test.clusterID)
+			if test.expectError {
+				require.Error(t, err, "expected error returned")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestVerifyIssuerURL(t *testing.T) {
+	const (
+		testProviderARN = "arn:aws:iam::123456789012:oidc-provider/test-infra-name-oidc.s3.test-region.amazonaws.com"
+		testURL         = "test-infra-name-oidc.s3.test-region.amazonaws.com"
+	)
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testInfraName)
+
+	tests := []struct {
+		name          string
+		mockAWSClient func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		issuerURL     string
+		expectError   bool
+	}{
+		{
+			name: "Issuer URL of the identity provider matches",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey: ownedCcoctlAWSResourceTagValue,
+				})
+				return mockAWSClient
+			},
+			issuerURL: "https://" + testURL,
+		},
+		{
+			name: "Issuer URL of the identity provider does not match",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					ownedTagKey: ownedCcoctlAWSResourceTagValue,
+				})
+				return mockAWSClient
+			},
+			issuerURL:   "https://other-infra-name-oidc.s3.test-region.amazonaws.com",
+			expectError: true,
+		},
+		{
+			name: "Identity provider of another name ignored",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, testURL, identityProviderClientIDs, map[string]string{
+					fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, "other-infra-name"): ownedCcoctlAWSResourceTagValue,
+				})
+				return mockAWSClient
+			},
+			issuerURL:   "https://" + testURL,
+			expectError: true,
+		},
+		{
+			name: "No identity provider found",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient)
+				return mockAWSClient
+			},
+			issuerURL:   "https://" + testURL,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := verifyIssuerURL(test.mockAWSClient(mockCtrl), testInfraName, "", test.issuerURL)
+			if test.expectError {
+				require.Error(t, err, "expected error returned")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func mockDeleteOpenIDConnectProvider(mockAWSClient *mockaws.MockClient, providerARN string) {
+	mockAWSClient.EXPECT().DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(providerARN),
+	}).Return(&iam.DeleteOpenIDConnectProviderOutput{}, nil)
+}
@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+)
+
+// listRemainingResources returns the resources which ccoctl aws delete would delete for namePrefix and clusterID
+// within the account of the clients, the OIDC bucket within the region of each client and the global resources
+// through the first client. Resources skipped by the deletion, eg. those not tagged with clusterID, are not listed.
+func listRemainingResources(clients []regionalClient, namePrefix, clusterID string) ([]string, error) {
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, namePrefix)
+	remaining := []string{}
+
+	bucketName := fmt.Sprintf("%s-oidc", namePrefix)
+	for _, client := range clients {
+		bucketTags, err := client.client.GetBucketTagging(&s3.GetBucketTaggingInput{
+			Bucket: awssdk.String(bucketName),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && (aerr.Code() == s3.ErrCodeNoSuchBucket || aerr.Code() == bucketRegionErrorCode) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to fetch tags of the bucket %s", bucketName)
+		}
+		tags := s3TagMap(bucketTags.TagSet)
+		if _, owned := tags[ownedTagKey]; owned && hasClusterResourceTag(tags, clusterID) {
+			remaining = append(remaining, fmt.Sprintf("S3 bucket %s within region %s", bucketName, client.region))
+		}
+	}
+
+	awsClient := clients[0].client
+	distributions, err := awsClient.ListCloudFrontDistributions(&cloudfront.ListDistributionsInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch a list of CloudFront distributions")
+	}
+	for _, distribution := range distributions.DistributionList.Items {
+		tagsOutput, err := awsClient.ListTagsForCloudFrontResource(&cloudfront.ListTagsForResourceInput{
+			Resource: distribution.ARN,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch tags for CloudFront distribution with ID %s", *distribution.Id)
+		}
+		tags := cloudFrontTagMap(tagsOutput.Tags.Items)
+		if _, owned := tags[ownedTagKey]; owned && hasClusterResourceTag(tags, clusterID) {
+			remaining = append(remaining, "CloudFront distribution "+awssdk.StringValue(distribution.Id))
+		}
+	}
+
+	originAccessIdentities, err := awsClient.ListCloudFrontOriginAccessIdentities(&cloudfront.ListCloudFrontOriginAccessIdentitiesInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch a list of CloudFront origin access identities")
+	}
+	for _, originAccessIdentity := range originAccessIdentities.CloudFrontOriginAccessIdentityList.Items {
+		if awssdk.StringValue(originAccessIdentity.Comment) == ownedTagKey {
+			remaining = append(remaining, "CloudFront origin access identity "+awssdk.StringValue(originAccessIdentity.Id))
+		}
+	}
+
+	var marker *string
+	for {
+		roleList, err := awsClient.ListRoles(&iam.ListRolesInput{Marker: marker})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch a list of IAM roles")
+		}
+		for _, roleMetadata := range roleList.Roles {
+			roleOutput, err := awsClient.GetRole(&iam.GetRoleInput{
+				RoleName: roleMetadata.RoleName,
+			})
+			if err != nil {
+				var aerr awserr.Error
+				if errors.As(err, &aerr) && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to fetch IAM role %s", *roleMetadata.RoleName)
+			}
+			tags := iamTagMap(roleOutput.Role.Tags)
+			if _, owned := tags[ownedTagKey]; owned && hasClusterResourceTag(tags, clusterID) {
+				remaining = append(remaining, "IAM Role "+awssdk.StringValue(roleOutput.Role.RoleName))
+			}
+		}
+		if !awssdk.BoolValue(roleList.IsTruncated) {
+			break
+		}
+		marker = roleList.Marker
+	}
+
+	providerARN, err := findIdentityProvider(awsClient, namePrefix, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if providerARN != "" {
+		remaining = append(remaining, "IAM Identity Provider "+providerARN)
+	}
+	return remaining, nil
+}
+
+// findIdentityProvider returns the ARN of the IAM Identity Provider owned by namePrefix and, when clusterID is
+// provided, the OpenShift cluster with ID clusterID, or an empty string when none is found
+func findIdentityProvider(client aws.Client, namePrefix, clusterID string) (string, error) {
+	oidcProviderList, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to fetch list of Identity Providers")
+	}
+	for _, provider := range oidcProviderList.OpenIDConnectProviderList {
+		ok, err := isExistingIdentifyProvider(client, *provider.Arn, namePrefix, clusterID)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to check for existing Identity Provider")
+		}
+		if ok {
+			return *provider.Arn, nil
+		}
+	}
+	return "", nil
+}
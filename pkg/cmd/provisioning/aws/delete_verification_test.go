@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func mockListCloudFrontResources(mockAWSClient *mockaws.MockClient, originAccessIdentityComments ...string) {
+	mockAWSClient.EXPECT().ListCloudFrontDistributions(gomock.Any()).Return(&cloudfront.ListDistributionsOutput{
+		DistributionList: &cloudfront.DistributionList{},
+	}, nil).Times(1)
+	originAccessIdentities := []*cloudfront.OriginAccessIdentitySummary{}
+	for i, comment := range originAccessIdentityComments {
+		originAccessIdentities = append(originAccessIdentities, &cloudfront.OriginAccessIdentitySummary{
+			Id:      awssdk.String(fmt.Sprintf("E%d", i)),
+			Comment: awssdk.String(comment),
+		})
+	}
+	mockAWSClient.EXPECT().ListCloudFrontOriginAccessIdentities(gomock.Any()).Return(&cloudfront.ListCloudFrontOriginAccessIdentitiesOutput{
+		CloudFrontOriginAccessIdentityList: &cloudfront.OriginAccessIdentityList{Items: originAccessIdentities},
+	}, nil).Times(1)
+}
+
+func TestListRemainingResources(t *testing.T) {
+	const testProviderARN = "arn:aws:iam::123456789012:oidc-provider/test-infra-name-oidc.s3.test-region.amazonaws.com"
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testInfraName)
+
+	tests := []struct {
+		name            string
+		mockAWSClient   func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		clusterID       string
+		expectRemaining []string
+	}{
+		{
+			name: "No resources remain",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTaggingError(mockAWSClient, s3.ErrCodeNoSuchBucket)
+				mockListCloudFrontResources(mockAWSClient)
+				mockListRoleNames(mockAWSClient)
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient)
+				return mockAWSClient
+			},
+			expectRemaining: []string{},
+		},
+		{
+			name: "Owned resources remain",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, map[string]string{ownedTagKey: ownedCcoctlAWSResourceTagValue})
+				mockListCloudFrontResources(mockAWSClient, ownedTagKey, "other")
+				mockListRoleNames(mockAWSClient, "test-role", "other-role")
+				mockGetTaggedRole(mockAWSClient, "test-role", map[string]string{ownedTagKey: ownedCcoctlAWSResourceTagValue})
+				mockGetTaggedRole(mockAWSClient, "other-role", map[string]string{})
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient, testProviderARN)
+				mockGetTaggedOpenIDConnectProvider(mockAWSClient, testProviderARN, "", nil, map[string]string{ownedTagKey: ownedCcoctlAWSResourceTagValue})
+				return mockAWSClient
+			},
+			expectRemaining: []string{
+				fmt.Sprintf("S3 bucket %s-oidc within region %s", testInfraName, testRegionName),
+				"CloudFront origin access identity E0",
+				"IAM Role test-role",
+				"IAM Identity Provider " + testProviderARN,
+			},
+		},
+		{
+			name: "Resources of other clusters skipped",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, map[string]string{
+					ownedTagKey:                       ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey("other-id"): provisioning.ClusterResourceTagValue,
+				})
+				mockListCloudFrontResources(mockAWSClient)
+				mockListRoleNames(mockAWSClient, "test-role")
+				mockGetTaggedRole(mockAWSClient, "test-role", map[string]string{
+					ownedTagKey:                       ownedCcoctlAWSResourceTagValue,
+					clusterResourceTagKey("other-id"): provisioning.ClusterResourceTagValue,
+				})
+				mockListOpenIDConnectProvidersWithARNs(mockAWSClient)
+				return mockAWSClient
+			},
+			clusterID:       testClusterID,
+			expectRemaining: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			clients := []regionalClient{{region: testRegionName, client: test.mockAWSClient(mockCtrl)}}
+			remaining, err := listRemainingResources(clients, testInfraName, test.clusterID)
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectRemaining, remaining, "unexpected remaining resources")
+		})
+	}
+}
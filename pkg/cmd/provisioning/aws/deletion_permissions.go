@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// deletionPermissions returns the permissions required to delete the resources created by ccoctl for name within
+// the account with ID accountID of partition. Resources whose names are only known once they are listed, eg. IAM
+// Roles, are checked against an ARN matching the names ccoctl gives them.
+func deletionPermissions(partition, accountID, name string) []provisioning.DeletionPermission {
+	bucketName := fmt.Sprintf("%s-oidc", name)
+	bucketARN := fmt.Sprintf("arn:%s:s3:::%s", partition, bucketName)
+	roleARN := fmt.Sprintf("arn:%s:iam::%s:role/%s-*", partition, accountID, name)
+	policyARN := fmt.Sprintf("arn:%s:iam::%s:policy/%s-*", partition, accountID, name)
+	distributionARN := fmt.Sprintf("arn:%s:cloudfront::%s:distribution/*", partition, accountID)
+	originAccessIdentityARN := fmt.Sprintf("arn:%s:cloudfront::%s:origin-access-identity/*", partition, accountID)
+	identityProviderARN := fmt.Sprintf("arn:%s:iam::%s:oidc-provider/*", partition, accountID)
+
+	objects := fmt.Sprintf("the objects of the OIDC bucket %s", bucketName)
+	bucket := fmt.Sprintf("the OIDC bucket %s", bucketName)
+	distribution := fmt.Sprintf("the CloudFront distribution of %s", name)
+	originAccessIdentity := fmt.Sprintf("the CloudFront origin access identity of %s", name)
+	roles := fmt.Sprintf("the IAM Roles of %s", name)
+	identityProvider := fmt.Sprintf("the IAM Identity Provider of %s", name)
+	return []provisioning.DeletionPermission{
+		{Permission: "s3:DeleteObject", Resource: bucketARN + "/*", Blocks: objects},
+		{Permission: "s3:DeleteBucket", Resource: bucketARN, Blocks: bucket},
+		{Permission: "cloudfront:UpdateDistribution", Resource: distributionARN, Blocks: distribution},
+		{Permission: "cloudfront:DeleteDistribution", Resource: distributionARN, Blocks: distribution},
+		{Permission: "cloudfront:DeleteCloudFrontOriginAccessIdentity", Resource: originAccessIdentityARN, Blocks: originAccessIdentity},
+		{Permission: "iam:DeleteRolePolicy", Resource: roleARN, Blocks: roles},
+		{Permission: "iam:DetachRolePolicy", Resource: roleARN, Blocks: roles},
+		{Permission: "iam:DeletePolicy", Resource: policyARN, Blocks: roles},
+		{Permission: "iam:DeleteRole", Resource: roleARN, Blocks: roles},
+		{Permission: "iam:DeleteOpenIDConnectProvider", Resource: identityProviderARN, Blocks: identityProvider},
+	}
+}
+
+// simulationPrincipalARN returns the ARN of the IAM principal whose policies are simulated for the caller identity
+// with ARN callerARN, the IAM Role of an assumed role session. rootUser is true for the root user of the account,
+// whose permissions cannot be simulated and which is permitted to delete any resource.
+func simulationPrincipalARN(callerARN string) (principalARN string, rootUser bool, err error) {
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to parse the ARN %s of the caller identity", callerARN)
+	}
+	switch {
+	case parsed.Service == "iam" && parsed.Resource == "root":
+		return "", true, nil
+	case parsed.Service == "sts" && strings.HasPrefix(parsed.Resource, "assumed-role/"):
+		// The resource of an assumed role session is assumed-role/<role name>/<session name>
+		parts := strings.Split(parsed.Resource, "/")
+		if len(parts) != 3 {
+			return "", false, fmt.Errorf("unexpected ARN %s of the caller identity", callerARN)
+		}
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", parsed.Partition, parsed.AccountID, parts[1]), false, nil
+	}
+	return callerARN, false, nil
+}
+
+// checkDeletionPermissions simulates the policies of the identity with which deletion is performed, resolved through
+// stsClient, to verify that it is permitted to delete the resources created by ccoctl for name before any of them is
+// deleted. The IAM policy simulator is the only means to check permissions without mutating resources, when it is
+// not available to the identity the check is reported as skipped, which fails it when failOnInsufficient is provided.
+func checkDeletionPermissions(stsClient callerIdentityClient, client aws.Client, name string, failOnInsufficient bool) error {
+	identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return provisioning.ReportUncheckedDeletionPermissions(errors.Wrap(err, "failed to resolve the AWS caller identity"), failOnInsufficient)
+	}
+	principalARN, rootUser, err := simulationPrincipalARN(awssdk.StringValue(identity.Arn))
+	if err != nil {
+		return provisioning.ReportUncheckedDeletionPermissions(err, failOnInsufficient)
+	}
+	if rootUser {
+		log.Print("Using the root user of the AWS account, which is permitted to delete any resource")
+		return nil
+	}
+
+	parsed, _ := arn.Parse(principalARN)
+	missing, err := simulateDeletionPermissions(client, principalARN, deletionPermissions(parsed.Partition, awssdk.StringValue(identity.Account), name))
+	if err != nil {
+		return provisioning.ReportUncheckedDeletionPermissions(errors.Wrapf(err, "failed to simulate the policies of %s", principalARN), failOnInsufficient)
+	}
+	return provisioning.ReportMissingDeletionPermissions(missing, failOnInsufficient)
+}
+
+// simulateDeletionPermissions returns the permissions of required which the IAM policy simulator does not allow the
+// principal with ARN principalARN. Permissions are simulated together for each resource they are checked against.
+func simulateDeletionPermissions(client aws.Client, principalARN string, required []provisioning.DeletionPermission) ([]provisioning.DeletionPermission, error) {
+	resources := []string{}
+	byResource := map[string][]provisioning.DeletionPermission{}
+	for _, permission := range required {
+		if _, found := byResource[permission.Resource]; !found {
+			resources = append(resources, permission.Resource)
+		}
+		byResource[permission.Resource] = append(byResource[permission.Resource], permission)
+	}
+
+	missing := []provisioning.DeletionPermission{}
+	for _, resource := range resources {
+		actions := []*string{}
+		for _, permission := range byResource[resource] {
+			actions = append(actions, awssdk.String(permission.Permission))
+		}
+		allowed := map[string]bool{}
+		err := client.SimulatePrincipalPolicyPages(&iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: awssdk.String(principalARN),
+			ActionNames:     actions,
+			ResourceArns:    []*string{awssdk.String(resource)},
+		}, func(response *iam.SimulatePolicyResponse, lastPage bool) bool {
+			for _, result := range response.EvaluationResults {
+				if awssdk.StringValue(result.EvalDecision) == iam.PolicyEvaluationDecisionTypeAllowed {
+					allowed[awssdk.StringValue(result.EvalActionName)] = true
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range byResource[resource] {
+			if !allowed[permission.Permission] {
+				missing = append(missing, permission)
+			}
+		}
+	}
+	return missing, nil
+}
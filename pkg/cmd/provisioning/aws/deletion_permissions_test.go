@@ -0,0 +1,196 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+)
+
+type fakeCallerARNClient struct {
+	arn string
+}
+
+func (c *fakeCallerARNClient) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Account: awssdk.String("123456789012"), Arn: awssdk.String(c.arn)}, nil
+}
+
+func TestSimulationPrincipalARN(t *testing.T) {
+	tests := []struct {
+		name              string
+		callerARN         string
+		expectedPrincipal string
+		expectRootUser    bool
+		expectError       bool
+	}{
+		{
+			name:              "IAM User",
+			callerARN:         "arn:aws:iam::123456789012:user/admin",
+			expectedPrincipal: "arn:aws:iam::123456789012:user/admin",
+		},
+		{
+			name:              "Assumed role session",
+			callerARN:         "arn:aws-us-gov:sts::123456789012:assumed-role/installer/session",
+			expectedPrincipal: "arn:aws-us-gov:iam::123456789012:role/installer",
+		},
+		{
+			name:           "Root user",
+			callerARN:      "arn:aws:iam::123456789012:root",
+			expectRootUser: true,
+		},
+		{
+			name:        "Invalid ARN",
+			callerARN:   "admin",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principalARN, rootUser, err := simulationPrincipalARN(test.callerARN)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedPrincipal, principalARN, "unexpected principal")
+			assert.Equal(t, test.expectRootUser, rootUser, "unexpected root user")
+		})
+	}
+}
+
+func TestCheckDeletionPermissions(t *testing.T) {
+	tests := []struct {
+		name               string
+		callerARN          string
+		denied             []string
+		simulationErr      error
+		failOnInsufficient bool
+		expectError        bool
+	}{
+		{
+			name:               "Every permission allowed",
+			callerARN:          "arn:aws:iam::123456789012:user/admin",
+			failOnInsufficient: true,
+		},
+		{
+			name:      "Missing permissions reported without failing",
+			callerARN: "arn:aws:iam::123456789012:user/admin",
+			denied:    []string{"iam:DeleteRole", "cloudfront:DeleteDistribution"},
+		},
+		{
+			name:               "Missing permissions fail with --fail-on-insufficient-permissions",
+			callerARN:          "arn:aws:sts::123456789012:assumed-role/installer/session",
+			denied:             []string{"s3:DeleteBucket"},
+			failOnInsufficient: true,
+			expectError:        true,
+		},
+		{
+			name:          "Check skipped when the policies cannot be simulated",
+			callerARN:     "arn:aws:iam::123456789012:user/admin",
+			simulationErr: fmt.Errorf("AccessDenied: not authorized to perform iam:SimulatePrincipalPolicy"),
+		},
+		{
+			name:               "Unsimulated policies fail with --fail-on-insufficient-permissions",
+			callerARN:          "arn:aws:iam::123456789012:user/admin",
+			simulationErr:      fmt.Errorf("AccessDenied: not authorized to perform iam:SimulatePrincipalPolicy"),
+			failOnInsufficient: true,
+			expectError:        true,
+		},
+		{
+			name:               "Unparsable caller identity fails with --fail-on-insufficient-permissions",
+			callerARN:          "admin",
+			failOnInsufficient: true,
+			expectError:        true,
+		},
+		{
+			name:               "Check skipped for the root user",
+			callerARN:          "arn:aws:iam::123456789012:root",
+			failOnInsufficient: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockAWSClient := mockaws.NewMockClient(mockCtrl)
+			denied := map[string]bool{}
+			for _, action := range test.denied {
+				denied[action] = true
+			}
+			mockAWSClient.EXPECT().SimulatePrincipalPolicyPages(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(input *iam.SimulatePrincipalPolicyInput, fn func(*iam.SimulatePolicyResponse, bool) bool) error {
+					if test.simulationErr != nil {
+						return test.simulationErr
+					}
+					results := []*iam.EvaluationResult{}
+					for _, action := range input.ActionNames {
+						decision := iam.PolicyEvaluationDecisionTypeAllowed
+						if denied[*action] {
+							decision = iam.PolicyEvaluationDecisionTypeImplicitDeny
+						}
+						results = append(results, &iam.EvaluationResult{EvalActionName: action, EvalDecision: awssdk.String(decision)})
+					}
+					fn(&iam.SimulatePolicyResponse{EvaluationResults: results}, true)
+					return nil
+				}).AnyTimes()
+
+			err := checkDeletionPermissions(&fakeCallerARNClient{arn: test.callerARN}, mockAWSClient, testInfraName, test.failOnInsufficient)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				for _, action := range test.denied {
+					assert.Contains(t, err.Error(), action, "expected missing permission to be reported")
+				}
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+		})
+	}
+}
+
+func TestSimulateDeletionPermissions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+	// The permissions checked against each resource are simulated together
+	simulatedResources := []string{}
+	mockAWSClient.EXPECT().SimulatePrincipalPolicyPages(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(input *iam.SimulatePrincipalPolicyInput, fn func(*iam.SimulatePolicyResponse, bool) bool) error {
+			simulatedResources = append(simulatedResources, awssdk.StringValueSlice(input.ResourceArns)...)
+			results := []*iam.EvaluationResult{}
+			for _, action := range input.ActionNames {
+				// Detaching policies is only allowed from other roles
+				decision := iam.PolicyEvaluationDecisionTypeAllowed
+				if *action == "iam:DetachRolePolicy" {
+					decision = iam.PolicyEvaluationDecisionTypeExplicitDeny
+				}
+				results = append(results, &iam.EvaluationResult{EvalActionName: action, EvalDecision: awssdk.String(decision)})
+			}
+			fn(&iam.SimulatePolicyResponse{EvaluationResults: results}, true)
+			return nil
+		}).AnyTimes()
+
+	missing, err := simulateDeletionPermissions(mockAWSClient, "arn:aws:iam::123456789012:user/admin", deletionPermissions("aws", "123456789012", testInfraName))
+	require.NoError(t, err, "unexpected error")
+	require.Len(t, missing, 1, "expected a single missing permission")
+	assert.Equal(t, "iam:DetachRolePolicy", missing[0].Permission, "unexpected missing permission")
+	assert.Equal(t, fmt.Sprintf("arn:aws:iam::123456789012:role/%s-*", testInfraName), missing[0].Resource, "unexpected resource of missing permission")
+	assert.Equal(t, []string{
+		fmt.Sprintf("arn:aws:s3:::%s-oidc/*", testInfraName),
+		fmt.Sprintf("arn:aws:s3:::%s-oidc", testInfraName),
+		"arn:aws:cloudfront::123456789012:distribution/*",
+		"arn:aws:cloudfront::123456789012:origin-access-identity/*",
+		fmt.Sprintf("arn:aws:iam::123456789012:role/%s-*", testInfraName),
+		fmt.Sprintf("arn:aws:iam::123456789012:policy/%s-*", testInfraName),
+		"arn:aws:iam::123456789012:oidc-provider/*",
+	}, simulatedResources, "unexpected simulated resources")
+}
@@ -0,0 +1,425 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/pkg/errors"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// policyStyleInline, policyStyleManaged and policyStyleAuto are the styles accepted by --policy-style
+	// with which the permissions of the IAM Roles are granted
+	policyStyleInline  = "inline"
+	policyStyleManaged = "managed"
+	policyStyleAuto    = "auto"
+
+	// maxInlinePolicySize is the maximum size (in characters, excluding whitespace) of the inline policies of an IAM Role
+	maxInlinePolicySize = 10240
+	// maxManagedPolicySize is the maximum size (in characters, excluding whitespace) of a managed policy
+	maxManagedPolicySize = 6144
+	// maxAttachedPoliciesPerRole is the default quota of managed policies attached to an IAM Role
+	maxAttachedPoliciesPerRole = 10
+	// maxRoleTrustPolicySize is the default quota of the size (in characters, excluding whitespace) of the trust policy
+	// of an IAM Role
+	maxRoleTrustPolicySize = 2048
+	// maxPolicyVersions is the maximum number of versions kept by AWS for a managed policy
+	maxPolicyVersions = 5
+
+	// inlinePolicyInventoryResourceType and managedPolicyInventoryResourceType record within the inventory the
+	// style with which the permissions of each IAM Role were granted
+	inlinePolicyInventoryResourceType  = "IAMRolePolicy"
+	managedPolicyInventoryResourceType = "IAMPolicy"
+
+	// Generated managed policy files
+	managedPolicyFilenameFormat = "06-%d-%s-managed-policy-%d.json"
+)
+
+// validatePolicyStyle ensures that policyStyle is one of the styles accepted by --policy-style
+func validatePolicyStyle(policyStyle string) error {
+	switch policyStyle {
+	case policyStyleInline, policyStyleManaged, policyStyleAuto:
+		return nil
+	}
+	return fmt.Errorf("unsupported policy style %q, must be one of %s, %s or %s", policyStyle, policyStyleInline, policyStyleManaged, policyStyleAuto)
+}
+
+// planRolePolicies returns the style with which the permissions of the statements are granted to the IAM Role roleName
+// and the policy documents granting them. The auto style grants the permissions with an inline policy unless the policy
+// exceeds the maximum size of the inline policies of an IAM Role. The managed style splits the statements into as few
+// managed policies as possible.
+func planRolePolicies(roleName, policyStyle string, statements []credreqv1.StatementEntry) (string, []string, error) {
+	if err := validatePolicyStyle(policyStyle); err != nil {
+		return "", nil, err
+	}
+
+	policy := createRolePolicy(statements)
+	if policyStyle == policyStyleAuto {
+		policyStyle = policyStyleInline
+		if len(policy) > maxInlinePolicySize {
+			policyStyle = policyStyleManaged
+		}
+	}
+
+	if policyStyle == policyStyleInline {
+		if len(policy) > maxInlinePolicySize {
+			return "", nil, provisioning.LimitViolation{
+				Artifact: "the policy of IAM Role " + roleName,
+				Size:     len(policy),
+				Limit:    maxInlinePolicySize,
+				Unit:     "characters",
+				Limited:  "of the inline policies of an IAM Role",
+				Hint:     fmt.Sprintf("use --policy-style=%s or --policy-style=%s", policyStyleManaged, policyStyleAuto),
+			}
+		}
+		return policyStyleInline, []string{policy}, nil
+	}
+
+	documents := []string{}
+	chunk := []credreqv1.StatementEntry{}
+	for i, statement := range statements {
+		if size := len(createRolePolicy([]credreqv1.StatementEntry{statement})); size > maxManagedPolicySize {
+			return "", nil, provisioning.LimitViolation{
+				Artifact: fmt.Sprintf("statement %d of the policy of IAM Role %s", i, roleName),
+				Size:     size,
+				Limit:    maxManagedPolicySize,
+				Unit:     "characters",
+				Limited:  "of a managed policy",
+			}
+		}
+		if len(chunk) > 0 && len(createRolePolicy(append(chunk, statement))) > maxManagedPolicySize {
+			documents = append(documents, createRolePolicy(chunk))
+			chunk = []credreqv1.StatementEntry{}
+		}
+		chunk = append(chunk, statement)
+	}
+	documents = append(documents, createRolePolicy(chunk))
+
+	if len(documents) > maxAttachedPoliciesPerRole {
+		return "", nil, provisioning.LimitViolation{
+			Artifact: "the policy of IAM Role " + roleName,
+			Size:     len(documents),
+			Limit:    maxAttachedPoliciesPerRole,
+			Unit:     "managed policies",
+			Limited:  "attached to an IAM Role by default",
+			Hint:     "split the permissions of the CredentialsRequest into fewer statements or request an increase of the quota",
+		}
+	}
+	return policyStyleManaged, documents, nil
+}
+
+// checkRolePolicyLimits validates the policies generated for the CredentialsRequests within credReqDirs with
+// validateRolePolicyLimits before any cloud call. The issuer URL of the IAM Identity Provider is derived from
+// identityProviderARN, the trust policies are not validated when the IAM Identity Provider is yet to be created.
+func checkRolePolicyLimits(credReqDirs []string, name, identityProviderARN, policyStyle string, enableTechPreview bool) error {
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	return validateRolePolicyLimits(credRequests, name, identityProviderARN, issuerURLFromIdentityProviderARN(identityProviderARN), policyStyle)
+}
+
+// validateRolePolicyLimits ensures that the policies generated for the IAM Roles of credReqs are within the limits of
+// AWS, returning a LimitViolations naming every CredentialsRequest whose policies exceed a limit. The trust policies
+// are validated unless identityProviderARN or issuerURL is empty. CredentialsRequests which can't be processed are
+// ignored, they fail when their IAM Role is created.
+func validateRolePolicyLimits(credReqs []*credreqv1.CredentialsRequest, name, identityProviderARN, issuerURL, policyStyle string) error {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return errors.Wrap(err, "Failed to create credReq codec")
+	}
+
+	violations := provisioning.LimitViolations{}
+	for _, cr := range credReqs {
+		if cr.Spec.ProviderSpec == nil {
+			continue
+		}
+		awsProviderSpec := credreqv1.AWSProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &awsProviderSpec); err != nil || awsProviderSpec.Kind != "AWSProviderSpec" {
+			continue
+		}
+		roleName, err := iamRoleName(name, cr)
+		if err != nil {
+			return err
+		}
+		component := provisioning.CredentialsRequestComponent(cr)
+
+		if _, _, err := planRolePolicies(roleName, policyStyle, awsProviderSpec.StatementEntries); err != nil {
+			if err := violations.AddError(component, err); err != nil {
+				return err
+			}
+		}
+
+		if identityProviderARN == "" || issuerURL == "" {
+			continue
+		}
+		trustPolicy, err := createRolePolicyDocument(identityProviderARN, issuerURL, cr.Spec.SecretRef.Namespace, cr.Spec.ServiceAccountNames)
+		if err != nil {
+			continue
+		}
+		violations.Add(provisioning.LimitViolation{
+			Component: component,
+			Artifact:  "the trust policy of IAM Role " + roleName,
+			Size:      policySize(trustPolicy),
+			Limit:     maxRoleTrustPolicySize,
+			Unit:      "characters",
+			Limited:   "of the trust policy of an IAM Role by default",
+			Hint:      "bind fewer service accounts or request an increase of the role trust policy length quota",
+		})
+	}
+	return violations.Err()
+}
+
+// policySize returns the size of the policy document as counted by AWS against its limits, which excludes whitespace
+func policySize(document string) int {
+	size := 0
+	for _, r := range document {
+		if !unicode.IsSpace(r) {
+			size++
+		}
+	}
+	return size
+}
+
+// issuerURLFromIdentityProviderARN returns the issuer URL of the IAM Identity Provider identified by
+// identityProviderARN, which is named after its issuer URL without the scheme as within the trust policies of IAM
+// Roles, or an empty string when identityProviderARN doesn't identify an IAM Identity Provider.
+func issuerURLFromIdentityProviderARN(identityProviderARN string) string {
+	const separator = ":oidc-provider/"
+	i := strings.Index(identityProviderARN, separator)
+	if i < 0 {
+		return ""
+	}
+	return identityProviderARN[i+len(separator):]
+}
+
+// managedPolicyName returns the name of the managed policy at index of the count managed policies granting the
+// permissions of the IAM Role roleName
+func managedPolicyName(roleName string, index, count int) string {
+	if count == 1 {
+		return roleName
+	}
+	return fmt.Sprintf("%s-%d", roleName, index+1)
+}
+
+// managedPolicyARN returns the ARN of the managed policy policyName within the account of the IAM Role roleARN
+func managedPolicyARN(roleARN, policyName string) string {
+	accountARN := roleARN
+	if idx := strings.Index(roleARN, ":role/"); idx >= 0 {
+		accountARN = roleARN[:idx]
+	}
+	return fmt.Sprintf("%s:policy/%s", accountARN, policyName)
+}
+
+// isNoSuchEntity returns whether err reports that the IAM entity does not exist
+func isNoSuchEntity(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == iam.ErrCodeNoSuchEntityException
+}
+
+// putInlineRolePolicy grants the permissions of the IAM Role with the inline policy document, the managed policies
+// previously created for the IAM Role are detached and deleted
+func putInlineRolePolicy(client aws.Client, role *iam.Role, policyName, document string, previousPolicies []provisioning.InventoryResource) ([]provisioning.InventoryResource, error) {
+	_, err := client.PutRolePolicy(&iam.PutRolePolicyInput{
+		PolicyName:     awssdk.String(policyName),
+		RoleName:       role.RoleName,
+		PolicyDocument: awssdk.String(document),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to put role policy")
+	}
+	log.Printf("Updated Role policy for Role %s", *role.RoleName)
+	provisioning.EmitResourceEvent(iamRoleInventoryResourceType, awssdk.StringValue(role.Arn), provisioning.ResourceUpdated)
+
+	for _, previousPolicy := range previousPolicies {
+		if previousPolicy.Type != managedPolicyInventoryResourceType {
+			continue
+		}
+		if err := removeManagedPolicy(client, *role.RoleName, previousPolicy.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return []provisioning.InventoryResource{{
+		Type: inlinePolicyInventoryResourceType,
+		Name: policyName,
+	}}, nil
+}
+
+// putManagedRolePolicies grants the permissions of the IAM Role with managed policies created from the policy
+// documents and attached to the IAM Role. The managed policies previously created for the IAM Role which are no
+// longer required, and the inline policy policyName, are removed.
+func putManagedRolePolicies(client aws.Client, role *iam.Role, policyName string, documents []string, tags []*iam.Tag, previousPolicies []provisioning.InventoryResource) ([]provisioning.InventoryResource, error) {
+	policies := []provisioning.InventoryResource{}
+	for i, document := range documents {
+		name := managedPolicyName(policyName, i, len(documents))
+		policyARN := managedPolicyARN(*role.Arn, name)
+		if err := ensureManagedPolicy(client, policyARN, name, document, tags); err != nil {
+			return nil, err
+		}
+
+		_, err := client.AttachRolePolicy(&iam.AttachRolePolicyInput{
+			RoleName:  role.RoleName,
+			PolicyArn: awssdk.String(policyARN),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to attach policy %s to Role %s", policyARN, *role.RoleName)
+		}
+		log.Printf("Attached policy %s to Role %s", policyARN, *role.RoleName)
+		policies = append(policies, provisioning.InventoryResource{
+			Type: managedPolicyInventoryResourceType,
+			Name: name,
+			ID:   policyARN,
+		})
+	}
+
+	for _, previousPolicy := range previousPolicies {
+		if previousPolicy.Type != managedPolicyInventoryResourceType || containsInventoryResource(policies, previousPolicy) {
+			continue
+		}
+		if err := removeManagedPolicy(client, *role.RoleName, previousPolicy.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The permissions of an IAM Role previously granted with an inline policy are only granted by the managed policies
+	_, err := client.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+		RoleName:   role.RoleName,
+		PolicyName: awssdk.String(policyName),
+	})
+	if err != nil && !isNoSuchEntity(err) {
+		return nil, errors.Wrapf(err, "Failed to delete inline policy %s of Role %s", policyName, *role.RoleName)
+	}
+	provisioning.EmitResourceEvent(iamRoleInventoryResourceType, awssdk.StringValue(role.Arn), provisioning.ResourceUpdated)
+
+	return policies, nil
+}
+
+// containsInventoryResource returns whether resources contain a resource with the type and ID of resource
+func containsInventoryResource(resources []provisioning.InventoryResource, resource provisioning.InventoryResource) bool {
+	for _, r := range resources {
+		if r.Type == resource.Type && r.ID == resource.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureManagedPolicy creates the managed policy policyName with the policy document, or makes the policy document
+// the default version of the managed policy if it already exists
+func ensureManagedPolicy(client aws.Client, policyARN, policyName, document string, tags []*iam.Tag) error {
+	_, err := client.CreatePolicy(&iam.CreatePolicyInput{
+		PolicyName:     awssdk.String(policyName),
+		PolicyDocument: awssdk.String(document),
+		Description:    awssdk.String(fmt.Sprintf("OpenShift policy for role %s", policyName)),
+		Tags:           tags,
+	})
+	if err == nil {
+		log.Printf("Policy %s created", policyARN)
+		provisioning.EmitResourceEvent(managedPolicyInventoryResourceType, policyARN, provisioning.ResourceCreated)
+		return nil
+	}
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != iam.ErrCodeEntityAlreadyExistsException {
+		return errors.Wrapf(err, "Failed to create policy %s", policyName)
+	}
+
+	// AWS keeps a limited number of versions of a managed policy, the oldest version is deleted to make room
+	versions, err := client.ListPolicyVersions(&iam.ListPolicyVersionsInput{
+		PolicyArn: awssdk.String(policyARN),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to list versions of policy %s", policyARN)
+	}
+	if len(versions.Versions) >= maxPolicyVersions {
+		var oldest *iam.PolicyVersion
+		for _, version := range versions.Versions {
+			if awssdk.BoolValue(version.IsDefaultVersion) {
+				continue
+			}
+			if oldest == nil || awssdk.TimeValue(version.CreateDate).Before(awssdk.TimeValue(oldest.CreateDate)) {
+				oldest = version
+			}
+		}
+		if oldest != nil {
+			_, err := client.DeletePolicyVersion(&iam.DeletePolicyVersionInput{
+				PolicyArn: awssdk.String(policyARN),
+				VersionId: oldest.VersionId,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "Failed to delete version %s of policy %s", *oldest.VersionId, policyARN)
+			}
+		}
+	}
+
+	_, err = client.CreatePolicyVersion(&iam.CreatePolicyVersionInput{
+		PolicyArn:      awssdk.String(policyARN),
+		PolicyDocument: awssdk.String(document),
+		SetAsDefault:   awssdk.Bool(true),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to update policy %s", policyARN)
+	}
+	log.Printf("Existing policy %s updated", policyARN)
+	provisioning.EmitResourceEvent(managedPolicyInventoryResourceType, policyARN, provisioning.ResourceUpdated)
+	return nil
+}
+
+// removeManagedPolicy detaches the managed policy policyARN from the IAM Role roleName and deletes it
+func removeManagedPolicy(client aws.Client, roleName, policyARN string) error {
+	_, err := client.DetachRolePolicy(&iam.DetachRolePolicyInput{
+		RoleName:  awssdk.String(roleName),
+		PolicyArn: awssdk.String(policyARN),
+	})
+	if err != nil && !isNoSuchEntity(err) {
+		return errors.Wrapf(err, "failed to detach policy %s from IAM Role %s", policyARN, roleName)
+	}
+	return deleteManagedPolicy(client, policyARN)
+}
+
+// deleteManagedPolicy deletes the managed policy policyARN along with its versions, a managed policy which does not
+// exist is ignored
+func deleteManagedPolicy(client aws.Client, policyARN string) error {
+	versions, err := client.ListPolicyVersions(&iam.ListPolicyVersionsInput{
+		PolicyArn: awssdk.String(policyARN),
+	})
+	if err != nil {
+		if isNoSuchEntity(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to list versions of policy %s", policyARN)
+	}
+
+	// A managed policy may only be deleted once its non-default versions have been deleted
+	for _, version := range versions.Versions {
+		if awssdk.BoolValue(version.IsDefaultVersion) {
+			continue
+		}
+		_, err := client.DeletePolicyVersion(&iam.DeletePolicyVersionInput{
+			PolicyArn: awssdk.String(policyARN),
+			VersionId: version.VersionId,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete version %s of policy %s", *version.VersionId, policyARN)
+		}
+	}
+
+	_, err = client.DeletePolicy(&iam.DeletePolicyInput{
+		PolicyArn: awssdk.String(policyARN),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete policy %s", policyARN)
+	}
+	log.Printf("Policy %s deleted", policyARN)
+	provisioning.EmitResourceEvent(managedPolicyInventoryResourceType, policyARN, provisioning.ResourceDeleted)
+	return nil
+}
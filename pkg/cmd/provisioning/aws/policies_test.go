@@ -0,0 +1,433 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	testRoleName = "test-cluster1-namespace1-secretName1"
+	testRoleARN  = "arn:aws:iam::123456789012:role/" + testRoleName
+)
+
+// paddedStatements returns the statements with the resource of the last statement padded so that the policy
+// granting the statements is exactly policySize characters long
+func paddedStatements(t *testing.T, policySize int, statements ...credreqv1.StatementEntry) []credreqv1.StatementEntry {
+	last := &statements[len(statements)-1]
+	last.Resource = ""
+	padding := policySize - len(createRolePolicy(statements))
+	require.GreaterOrEqual(t, padding, 0, "policy without padding exceeds the requested size")
+	last.Resource = strings.Repeat("x", padding)
+	require.Len(t, createRolePolicy(statements), policySize, "unexpected size of padded policy")
+	return statements
+}
+
+func testStatement() credreqv1.StatementEntry {
+	return credreqv1.StatementEntry{
+		Effect: "Allow",
+		Action: []string{"s3:GetObject"},
+	}
+}
+
+// managedPolicySizedStatements returns count statements each requiring a managed policy of its own
+func managedPolicySizedStatements(t *testing.T, count int) []credreqv1.StatementEntry {
+	statements := []credreqv1.StatementEntry{}
+	for i := 0; i < count; i++ {
+		statements = append(statements, paddedStatements(t, maxManagedPolicySize, testStatement())...)
+	}
+	return statements
+}
+
+func TestPlanRolePolicies(t *testing.T) {
+	tests := []struct {
+		name              string
+		policyStyle       string
+		statements        func(t *testing.T) []credreqv1.StatementEntry
+		expectError       bool
+		expectedStyle     string
+		expectedDocuments int
+	}{
+		{
+			name:        "Auto style with policy at the inline size limit",
+			policyStyle: policyStyleAuto,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxInlinePolicySize, testStatement(), testStatement())
+			},
+			expectedStyle:     policyStyleInline,
+			expectedDocuments: 1,
+		},
+		{
+			name:        "Auto style with policy exceeding the inline size limit",
+			policyStyle: policyStyleAuto,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxInlinePolicySize+1, paddedStatements(t, maxManagedPolicySize, testStatement())[0], testStatement())
+			},
+			expectedStyle:     policyStyleManaged,
+			expectedDocuments: 2,
+		},
+		{
+			name:        "Inline style with policy at the inline size limit",
+			policyStyle: policyStyleInline,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxInlinePolicySize, testStatement(), testStatement())
+			},
+			expectedStyle:     policyStyleInline,
+			expectedDocuments: 1,
+		},
+		{
+			name:        "Inline style with policy exceeding the inline size limit",
+			policyStyle: policyStyleInline,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxInlinePolicySize+1, testStatement(), testStatement())
+			},
+			expectError: true,
+		},
+		{
+			name:        "Managed style with policy at the managed size limit",
+			policyStyle: policyStyleManaged,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxManagedPolicySize, testStatement(), testStatement())
+			},
+			expectedStyle:     policyStyleManaged,
+			expectedDocuments: 1,
+		},
+		{
+			name:        "Managed style with policy exceeding the managed size limit",
+			policyStyle: policyStyleManaged,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxManagedPolicySize+1, testStatement(), testStatement())
+			},
+			expectedStyle:     policyStyleManaged,
+			expectedDocuments: 2,
+		},
+		{
+			name:        "Managed style with statement exceeding the managed size limit",
+			policyStyle: policyStyleManaged,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxManagedPolicySize+1, testStatement())
+			},
+			expectError: true,
+		},
+		{
+			name:        "Managed style at the attached policies quota",
+			policyStyle: policyStyleManaged,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return managedPolicySizedStatements(t, maxAttachedPoliciesPerRole)
+			},
+			expectedStyle:     policyStyleManaged,
+			expectedDocuments: maxAttachedPoliciesPerRole,
+		},
+		{
+			name:        "Managed style exceeding the attached policies quota",
+			policyStyle: policyStyleManaged,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return managedPolicySizedStatements(t, maxAttachedPoliciesPerRole+1)
+			},
+			expectError: true,
+		},
+		{
+			name:        "Unsupported policy style",
+			policyStyle: "attached",
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return []credreqv1.StatementEntry{testStatement()}
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements := test.statements(t)
+			style, documents, err := planRolePolicies(testRoleName, test.policyStyle, statements)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedStyle, style, "unexpected policy style")
+			require.Len(t, documents, test.expectedDocuments, "unexpected number of policy documents")
+
+			// Every statement is granted once by a policy within the size limit of its style
+			limit := maxInlinePolicySize
+			if style == policyStyleManaged {
+				limit = maxManagedPolicySize
+			}
+			granted := 0
+			for _, document := range documents {
+				assert.LessOrEqual(t, len(document), limit, "policy document exceeds the size limit")
+				granted += strings.Count(document, `"Effect"`)
+			}
+			assert.Equal(t, len(statements), granted, "unexpected number of granted statements")
+		})
+	}
+}
+
+func TestValidateRolePolicyLimits(t *testing.T) {
+	oversizedStatements := func(t *testing.T) []credreqv1.StatementEntry {
+		return paddedStatements(t, maxInlinePolicySize+1, testStatement())
+	}
+	serviceAccountNames := func(count int) []string {
+		names := []string{}
+		for i := 0; i < count; i++ {
+			names = append(names, fmt.Sprintf("service-account-%d", i))
+		}
+		return names
+	}
+
+	tests := []struct {
+		name                string
+		policyStyle         string
+		statements          func(t *testing.T) []credreqv1.StatementEntry
+		serviceAccountNames []string
+		identityProviderARN string
+		// expectedViolations are expected within the error, one for each violation
+		expectedViolations []string
+	}{
+		{
+			name:                "Policies within the limits",
+			policyStyle:         policyStyleInline,
+			statements:          func(t *testing.T) []credreqv1.StatementEntry { return []credreqv1.StatementEntry{testStatement()} },
+			serviceAccountNames: serviceAccountNames(2),
+			identityProviderARN: testIdentityProviderARN,
+		},
+		{
+			name:                "Inline policy exceeding the inline size limit",
+			policyStyle:         policyStyleInline,
+			statements:          oversizedStatements,
+			serviceAccountNames: serviceAccountNames(2),
+			identityProviderARN: testIdentityProviderARN,
+			expectedViolations:  []string{"exceeding the limit of 10240 characters of the inline policies of an IAM Role"},
+		},
+		{
+			name:        "Auto style with policy exceeding the inline size limit",
+			policyStyle: policyStyleAuto,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return paddedStatements(t, maxInlinePolicySize+1, paddedStatements(t, maxManagedPolicySize, testStatement())[0], testStatement())
+			},
+			serviceAccountNames: serviceAccountNames(2),
+			identityProviderARN: testIdentityProviderARN,
+		},
+		{
+			name:        "Managed style with policy requiring too many managed policies",
+			policyStyle: policyStyleManaged,
+			statements: func(t *testing.T) []credreqv1.StatementEntry {
+				return managedPolicySizedStatements(t, maxAttachedPoliciesPerRole+1)
+			},
+			serviceAccountNames: serviceAccountNames(2),
+			identityProviderARN: testIdentityProviderARN,
+			expectedViolations:  []string{"requires 11 managed policies, exceeding the limit of 10 managed policies"},
+		},
+		{
+			name:                "Trust policy exceeding the trust policy size limit",
+			policyStyle:         policyStyleInline,
+			statements:          func(t *testing.T) []credreqv1.StatementEntry { return []credreqv1.StatementEntry{testStatement()} },
+			serviceAccountNames: serviceAccountNames(50),
+			identityProviderARN: testIdentityProviderARN,
+			expectedViolations:  []string{"the trust policy of IAM Role " + testRoleName + " requires"},
+		},
+		{
+			name:                "Trust policy not validated before the identity provider is created",
+			policyStyle:         policyStyleInline,
+			statements:          func(t *testing.T) []credreqv1.StatementEntry { return []credreqv1.StatementEntry{testStatement()} },
+			serviceAccountNames: serviceAccountNames(50),
+		},
+		{
+			name:                "Every violation reported",
+			policyStyle:         policyStyleInline,
+			statements:          oversizedStatements,
+			serviceAccountNames: serviceAccountNames(50),
+			identityProviderARN: testIdentityProviderARN,
+			expectedViolations:  []string{"of the inline policies of an IAM Role", "the trust policy of IAM Role"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			codec, err := credreqv1.NewCodec()
+			require.NoError(t, err, "failed to create codec")
+			providerSpec, err := codec.EncodeProviderSpec(&credreqv1.AWSProviderSpec{
+				TypeMeta:         metav1.TypeMeta{Kind: "AWSProviderSpec"},
+				StatementEntries: test.statements(t),
+			})
+			require.NoError(t, err, "failed to encode provider spec")
+			cr := &credreqv1.CredentialsRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-credreq", Namespace: "openshift-cloud-credential-operator"},
+				Spec: credreqv1.CredentialsRequestSpec{
+					SecretRef:           corev1.ObjectReference{Namespace: "namespace1", Name: "secretName1"},
+					ProviderSpec:        providerSpec,
+					ServiceAccountNames: test.serviceAccountNames,
+				},
+			}
+
+			err = validateRolePolicyLimits([]*credreqv1.CredentialsRequest{cr}, testNamePrefix, test.identityProviderARN, issuerURLFromIdentityProviderARN(test.identityProviderARN), test.policyStyle)
+			if len(test.expectedViolations) == 0 {
+				require.NoError(t, err, "unexpected error")
+				return
+			}
+			var violations provisioning.LimitViolations
+			require.ErrorAs(t, err, &violations, "expected limit violations")
+			assert.Len(t, violations, len(test.expectedViolations), "unexpected number of violations")
+			for _, expected := range test.expectedViolations {
+				assert.Contains(t, err.Error(), expected, "unexpected violation")
+			}
+			assert.Contains(t, err.Error(), "CredentialsRequest openshift-cloud-credential-operator/test-credreq", "expected the CredentialsRequest to be named")
+		})
+	}
+}
+
+func TestIssuerURLFromIdentityProviderARN(t *testing.T) {
+	assert.Equal(t, testIdentityProviderURL, issuerURLFromIdentityProviderARN(testIdentityProviderARN), "unexpected issuer URL")
+	assert.Empty(t, issuerURLFromIdentityProviderARN(""), "unexpected issuer URL without identity provider")
+	assert.Empty(t, issuerURLFromIdentityProviderARN(testRoleARN), "unexpected issuer URL of an IAM Role")
+}
+
+func TestManagedPolicyName(t *testing.T) {
+	assert.Equal(t, testRoleName, managedPolicyName(testRoleName, 0, 1), "unexpected name of a single managed policy")
+	assert.Equal(t, testRoleName+"-2", managedPolicyName(testRoleName, 1, 3), "unexpected name of one of several managed policies")
+	assert.Equal(t, "arn:aws:iam::123456789012:policy/"+testRoleName, managedPolicyARN(testRoleARN, testRoleName), "unexpected managed policy ARN")
+	assert.Equal(t, "arn:aws-us-gov:iam::123456789012:policy/"+testRoleName, managedPolicyARN("arn:aws-us-gov:iam::123456789012:role/"+testRoleName, testRoleName), "unexpected managed policy ARN within GovCloud")
+}
+
+func TestPutRolePolicies(t *testing.T) {
+	role := &iam.Role{
+		Arn:      awssdk.String(testRoleARN),
+		RoleName: awssdk.String(testRoleName),
+	}
+	firstPolicyARN := managedPolicyARN(testRoleARN, testRoleName+"-1")
+	secondPolicyARN := managedPolicyARN(testRoleARN, testRoleName+"-2")
+	previousManagedPolicies := []provisioning.InventoryResource{
+		{Type: iamRoleInventoryResourceType, Name: testRoleName, ID: testRoleARN},
+		{Type: managedPolicyInventoryResourceType, Name: testRoleName + "-1", ID: firstPolicyARN},
+		{Type: managedPolicyInventoryResourceType, Name: testRoleName + "-2", ID: secondPolicyARN},
+	}
+
+	t.Run("Inline policy replaces previous managed policies", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+		mockPutRolePolicy(mockAWSClient)
+		for _, policyARN := range []string{firstPolicyARN, secondPolicyARN} {
+			mockDetachRolePolicy(mockAWSClient, testRoleName, policyARN)
+			mockDeleteManagedPolicy(mockAWSClient, policyARN)
+		}
+
+		policies, err := putInlineRolePolicy(mockAWSClient, role, testRoleName, "{}", previousManagedPolicies)
+		require.NoError(t, err, "unexpected error")
+		assert.Equal(t, []provisioning.InventoryResource{{Type: inlinePolicyInventoryResourceType, Name: testRoleName}}, policies)
+	})
+
+	t.Run("Managed policy replaces previous managed policies and inline policy", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+		policyARN := managedPolicyARN(testRoleARN, testRoleName)
+		mockCreatePolicy(mockAWSClient, testRoleName)
+		mockAttachRolePolicy(mockAWSClient, testRoleName, policyARN)
+		for _, previousPolicyARN := range []string{firstPolicyARN, secondPolicyARN} {
+			mockDetachRolePolicy(mockAWSClient, testRoleName, previousPolicyARN)
+			mockDeleteManagedPolicy(mockAWSClient, previousPolicyARN)
+		}
+		mockAWSClient.EXPECT().DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+			RoleName:   awssdk.String(testRoleName),
+			PolicyName: awssdk.String(testRoleName),
+		}).Return(&iam.DeleteRolePolicyOutput{}, nil).Times(1)
+
+		policies, err := putManagedRolePolicies(mockAWSClient, role, testRoleName, []string{"{}"}, nil, previousManagedPolicies)
+		require.NoError(t, err, "unexpected error")
+		assert.Equal(t, []provisioning.InventoryResource{{Type: managedPolicyInventoryResourceType, Name: testRoleName, ID: policyARN}}, policies)
+	})
+
+	t.Run("Existing managed policies are updated", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockAWSClient := mockaws.NewMockClient(mockCtrl)
+		mockAWSClient.EXPECT().CreatePolicy(gomock.Any()).Return(
+			nil, awserr.New(iam.ErrCodeEntityAlreadyExistsException, "Policy exists", fmt.Errorf("fake error"))).Times(2)
+		// The first policy has the maximum number of versions, its oldest non-default version is deleted
+		now := time.Now()
+		mockAWSClient.EXPECT().ListPolicyVersions(&iam.ListPolicyVersionsInput{PolicyArn: awssdk.String(firstPolicyARN)}).Return(
+			&iam.ListPolicyVersionsOutput{Versions: []*iam.PolicyVersion{
+				{VersionId: awssdk.String("v1"), IsDefaultVersion: awssdk.Bool(true), CreateDate: awssdk.Time(now.Add(-5 * time.Hour))},
+				{VersionId: awssdk.String("v2"), IsDefaultVersion: awssdk.Bool(false), CreateDate: awssdk.Time(now.Add(-4 * time.Hour))},
+				{VersionId: awssdk.String("v3"), IsDefaultVersion: awssdk.Bool(false), CreateDate: awssdk.Time(now.Add(-3 * time.Hour))},
+				{VersionId: awssdk.String("v4"), IsDefaultVersion: awssdk.Bool(false), CreateDate: awssdk.Time(now.Add(-2 * time.Hour))},
+				{VersionId: awssdk.String("v5"), IsDefaultVersion: awssdk.Bool(false), CreateDate: awssdk.Time(now.Add(-1 * time.Hour))},
+			}}, nil).Times(1)
+		mockAWSClient.EXPECT().DeletePolicyVersion(&iam.DeletePolicyVersionInput{PolicyArn: awssdk.String(firstPolicyARN), VersionId: awssdk.String("v2")}).Return(
+			&iam.DeletePolicyVersionOutput{}, nil).Times(1)
+		mockAWSClient.EXPECT().ListPolicyVersions(&iam.ListPolicyVersionsInput{PolicyArn: awssdk.String(secondPolicyARN)}).Return(
+			&iam.ListPolicyVersionsOutput{Versions: []*iam.PolicyVersion{
+				{VersionId: awssdk.String("v1"), IsDefaultVersion: awssdk.Bool(true), CreateDate: awssdk.Time(now)},
+			}}, nil).Times(1)
+		mockAWSClient.EXPECT().CreatePolicyVersion(gomock.Any()).Return(&iam.CreatePolicyVersionOutput{}, nil).Times(2)
+		mockAttachRolePolicy(mockAWSClient, testRoleName, firstPolicyARN)
+		mockAttachRolePolicy(mockAWSClient, testRoleName, secondPolicyARN)
+		mockDeleteRolePolicyNotFound(mockAWSClient)
+
+		policies, err := putManagedRolePolicies(mockAWSClient, role, testRoleName, []string{"{}", "{}"}, nil, previousManagedPolicies)
+		require.NoError(t, err, "unexpected error")
+		assert.Equal(t, previousManagedPolicies[1:], policies, "expected the existing managed policies to be kept")
+	})
+}
+
+func TestDeleteRolePolicies(t *testing.T) {
+	ownedPolicyARN := managedPolicyARN(testRoleARN, testRoleName)
+	userPolicyARN := "arn:aws:iam::aws:policy/ReadOnlyAccess"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	mockAWSClient.EXPECT().ListRolePolicies(gomock.Any()).Return(&iam.ListRolePoliciesOutput{}, nil).Times(1)
+	mockAWSClient.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(&iam.ListAttachedRolePoliciesOutput{
+		AttachedPolicies: []*iam.AttachedPolicy{
+			{PolicyArn: awssdk.String(ownedPolicyARN)},
+			{PolicyArn: awssdk.String(userPolicyARN)},
+		},
+	}, nil).Times(1)
+	mockDetachRolePolicy(mockAWSClient, testRoleName, ownedPolicyARN)
+	mockDetachRolePolicy(mockAWSClient, testRoleName, userPolicyARN)
+	mockAWSClient.EXPECT().GetPolicy(&iam.GetPolicyInput{PolicyArn: awssdk.String(ownedPolicyARN)}).Return(&iam.GetPolicyOutput{
+		Policy: &iam.Policy{Tags: iamTags(resourceTags(testNamePrefix, ""))},
+	}, nil).Times(1)
+	mockAWSClient.EXPECT().GetPolicy(&iam.GetPolicyInput{PolicyArn: awssdk.String(userPolicyARN)}).Return(&iam.GetPolicyOutput{
+		Policy: &iam.Policy{},
+	}, nil).Times(1)
+	// Only the managed policy created by ccoctl is deleted
+	mockDeleteManagedPolicy(mockAWSClient, ownedPolicyARN)
+
+	require.NoError(t, deleteRolePolicies(mockAWSClient, testRoleName, testNamePrefix), "unexpected error")
+}
+
+func mockDetachRolePolicy(mockAWSClient *mockaws.MockClient, roleName, policyARN string) {
+	mockAWSClient.EXPECT().DetachRolePolicy(&iam.DetachRolePolicyInput{
+		RoleName:  awssdk.String(roleName),
+		PolicyArn: awssdk.String(policyARN),
+	}).Return(&iam.DetachRolePolicyOutput{}, nil).Times(1)
+}
+
+func mockDeleteManagedPolicy(mockAWSClient *mockaws.MockClient, policyARN string) {
+	mockAWSClient.EXPECT().ListPolicyVersions(&iam.ListPolicyVersionsInput{PolicyArn: awssdk.String(policyARN)}).Return(
+		&iam.ListPolicyVersionsOutput{Versions: []*iam.PolicyVersion{
+			{VersionId: awssdk.String("v1"), IsDefaultVersion: awssdk.Bool(true)},
+			{VersionId: awssdk.String("v2"), IsDefaultVersion: awssdk.Bool(false)},
+		}}, nil).Times(1)
+	mockAWSClient.EXPECT().DeletePolicyVersion(&iam.DeletePolicyVersionInput{PolicyArn: awssdk.String(policyARN), VersionId: awssdk.String("v2")}).Return(
+		&iam.DeletePolicyVersionOutput{}, nil).Times(1)
+	mockAWSClient.EXPECT().DeletePolicy(&iam.DeletePolicyInput{PolicyArn: awssdk.String(policyARN)}).Return(
+		&iam.DeletePolicyOutput{}, nil).Times(1)
+}
@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// ReconcileDeleteOpts captures the options that affect deleting the resources of decommissioned components
+	ReconcileDeleteOpts = options{}
+)
+
+// reconcileDelete deletes the IAM Roles carrying ccoctl's "owned" tag for name, and the cluster ownership tag when
+// clusterID is provided, which were not created for any of the CredentialsRequests found within credReqDirs. The IAM
+// Roles of the current CredentialsRequests, the OIDC endpoint and the IAM Identity Provider are left untouched. When
+// dryRun is set, IAM Roles which would have been deleted are logged rather than deleted. Otherwise the deletion of
+// more than confirmThreshold IAM Roles must be confirmed unless yes is set.
+func reconcileDelete(client aws.Client, name, clusterID string, credReqDirs []string, enableTechPreview, dryRun bool, confirmThreshold int, yes bool) error {
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+	// Every IAM Role would be deleted without any CredentialsRequests, which is a full teardown better done with
+	// ccoctl aws delete
+	if len(credReqs) == 0 {
+		return fmt.Errorf("refusing to delete IAM Roles, found no CredentialsRequests within %s", credReqDirs)
+	}
+	// IAM Roles are matched to the current CredentialsRequests by the tag of their component, or by their name when
+	// they were created without the tag
+	currentRoleNames := map[string]bool{}
+	currentComponents := map[string]bool{}
+	for _, credReq := range credReqs {
+		roleName, err := iamRoleName(name, credReq)
+		if err != nil {
+			return err
+		}
+		currentRoleNames[roleName] = true
+		currentComponents[provisioning.CredentialsRequestComponentTag(credReq)] = true
+	}
+
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)
+	current := 0
+	candidates := []*iam.Role{}
+	var marker *string
+	for {
+		roleList, err := client.ListRoles(&iam.ListRolesInput{Marker: marker})
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch a list of IAM roles")
+		}
+		for _, roleMetadata := range roleList.Roles {
+			roleOutput, err := client.GetRole(&iam.GetRoleInput{
+				RoleName: roleMetadata.RoleName,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to fetch IAM role %s", *roleMetadata.RoleName)
+			}
+			tags := iamTagMap(roleOutput.Role.Tags)
+			if tags[ownedTagKey] != ownedCcoctlAWSResourceTagValue || !hasClusterResourceTag(tags, clusterID) {
+				continue
+			}
+			if currentRoleNames[awssdk.StringValue(roleOutput.Role.RoleName)] || currentComponents[tags[componentTagKey]] {
+				current++
+				continue
+			}
+			candidates = append(candidates, roleOutput.Role)
+		}
+		if !awssdk.BoolValue(roleList.IsTruncated) {
+			break
+		}
+		marker = roleList.Marker
+	}
+
+	if len(candidates) == 0 {
+		log.Print("Found no IAM Roles which do not correspond to a CredentialsRequest")
+		return nil
+	}
+	// None of the owned IAM Roles corresponding to the current CredentialsRequests is most likely a sign of IAM Roles
+	// named differently than at create time, eg. with another --name-template, rather than of every component having
+	// been decommissioned
+	if current == 0 {
+		return fmt.Errorf("refusing to delete every one of the %d IAM Roles owned by %s, none of which corresponds to the CredentialsRequests within %s. "+
+			"Check that --name and --name-template are those the IAM Roles were created with, or delete every IAM Role with ccoctl aws delete",
+			len(candidates), name, credReqDirs)
+	}
+
+	if dryRun {
+		for _, role := range candidates {
+			log.Printf("Would delete IAM Role %s which does not correspond to any CredentialsRequest", *role.RoleName)
+		}
+		log.Printf("Dry run complete, %d IAM Roles would have been deleted", len(candidates))
+		return nil
+	}
+	summary, _ := provisioning.DiscoverDeletions(func() error {
+		for range candidates {
+			provisioning.RecordDeletionCandidate("IAMRole")
+		}
+		return nil
+	})
+	if err := provisioning.ConfirmDeletion(summary, confirmThreshold, yes); err != nil {
+		return err
+	}
+	for _, role := range candidates {
+		if err := deleteIAMRole(client, role, name); err != nil {
+			return err
+		}
+	}
+	log.Printf("Deleted %d IAM Roles", len(candidates))
+	return nil
+}
+
+func reconcileDeleteCmd(cmd *cobra.Command, args []string) {
+	if err := provisioning.ValidateClusterID(ReconcileDeleteOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+	if err := provisioning.ValidateConfirmThreshold(ReconcileDeleteOpts.ConfirmThreshold); err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := awsSession(cmd.Context(), ReconcileDeleteOpts.Region, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = reconcileDelete(aws.NewClientFromSession(s),
+		ReconcileDeleteOpts.Name,
+		ReconcileDeleteOpts.ClusterID,
+		ReconcileDeleteOpts.CredRequestDirs,
+		ReconcileDeleteOpts.EnableTechPreview,
+		ReconcileDeleteOpts.DryRun,
+		ReconcileDeleteOpts.ConfirmThreshold,
+		ReconcileDeleteOpts.Yes)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NewReconcileDeleteCmd provides the "reconcile-delete" subcommand
+func NewReconcileDeleteCmd() *cobra.Command {
+	reconcileDeleteCmd := &cobra.Command{
+		Use:   "reconcile-delete",
+		Short: "Delete the IAM Roles of components without a CredentialsRequest",
+		Long: "Deleting the IAM Roles created by ccoctl which do not correspond to any CredentialsRequest within --credentials-requests-dir, " +
+			"eg. once a component has been decommissioned. Only IAM Roles carrying the owned tag for --name are considered, the IAM Roles of " +
+			"the current CredentialsRequests, the OIDC endpoint and the IAM Identity Provider are left intact. Nothing is deleted when none of " +
+			"the owned IAM Roles corresponds to a current CredentialsRequest.",
+		Run: reconcileDeleteCmd,
+	}
+
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.Name, "name", "", "User-defined name for all created AWS resources (can be separate from the cluster's infra-id)")
+	reconcileDeleteCmd.MarkPersistentFlagRequired("name")
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.Region, "region", "", "AWS region where the resources were created")
+	reconcileDeleteCmd.MarkPersistentFlagRequired("region")
+	reconcileDeleteCmd.PersistentFlags().StringSliceVar(&ReconcileDeleteOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of the current set of CredentialsRequests (can be created by running 'oc adm release extract --credentials-requests --cloud=aws' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
+	reconcileDeleteCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.ClusterID, "cluster-id", "", "Only delete IAM Roles which are tagged with 'kubernetes.io/cluster/<cluster ID> = owned' by ccoctl when the resources were created with --cluster-id")
+	reconcileDeleteCmd.PersistentFlags().BoolVar(&ReconcileDeleteOpts.DryRun, "dry-run", false, "Skip deleting IAM Roles and display the IAM Roles that would have been deleted")
+	reconcileDeleteCmd.PersistentFlags().IntVar(&ReconcileDeleteOpts.ConfirmThreshold, "confirm-threshold", provisioning.DefaultConfirmThreshold, "Number of IAM Roles to delete beyond which deletion must be confirmed on the terminal, or with --yes")
+	reconcileDeleteCmd.PersistentFlags().BoolVar(&ReconcileDeleteOpts.Yes, "yes", false, "Delete the IAM Roles which do not correspond to any CredentialsRequest without asking for confirmation, however many there are")
+	reconcileDeleteCmd.PersistentFlags().BoolVar(&ReconcileDeleteOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests annotated with TechPreviewNoUpgrade")
+
+	return reconcileDeleteCmd
+}
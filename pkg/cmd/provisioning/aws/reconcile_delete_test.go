@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"os"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestReconcileDelete(t *testing.T) {
+	currentRoleName := testNamePrefix + "-namespace1-secretName1"
+
+	tests := []struct {
+		name                  string
+		mockAWSClient         func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		noCredentialsRequests bool
+		nameTemplates         []string
+		clusterID             string
+		dryRun                bool
+		confirmThreshold      int
+		expectError           string
+	}{
+		{
+			name: "Nothing to prune",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, currentRoleName)
+				mockGetTaggedRole(mockAWSClient, currentRoleName, iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Several roles of decommissioned components pruned",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, currentRoleName, testNamePrefix+"-removed-component1", testNamePrefix+"-removed-component2")
+				mockGetTaggedRole(mockAWSClient, currentRoleName, iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component1", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component2", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockDeleteRoleWithoutPolicies(mockAWSClient, testNamePrefix+"-removed-component1")
+				mockDeleteRoleWithoutPolicies(mockAWSClient, testNamePrefix+"-removed-component2")
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Roles of decommissioned components not deleted with dry run",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, currentRoleName, testNamePrefix+"-removed-component1")
+				mockGetTaggedRole(mockAWSClient, currentRoleName, iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component1", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				return mockAWSClient
+			},
+			dryRun: true,
+		},
+		{
+			name: "Roles not owned are not deleted",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, "other-name-removed-component", "user-role")
+				mockGetTaggedRole(mockAWSClient, "other-name-removed-component", iamTagMap(iamTags(resourceTags("other-name", ""))))
+				mockGetTaggedRole(mockAWSClient, "user-role", map[string]string{})
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Only roles of the cluster pruned with cluster ID",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, currentRoleName, testNamePrefix+"-removed-component1", testNamePrefix+"-removed-component2")
+				mockGetTaggedRole(mockAWSClient, currentRoleName, iamTagMap(iamTags(resourceTags(testNamePrefix, testClusterID))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component1", iamTagMap(iamTags(resourceTags(testNamePrefix, testClusterID))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component2", iamTagMap(iamTags(resourceTags(testNamePrefix, "other-cluster-id"))))
+				mockDeleteRoleWithoutPolicies(mockAWSClient, testNamePrefix+"-removed-component1")
+				return mockAWSClient
+			},
+			clusterID: testClusterID,
+		},
+		{
+			name: "No CredentialsRequests",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			noCredentialsRequests: true,
+			expectError:           "found no CredentialsRequests",
+		},
+		{
+			name: "Roles named with another name template not deleted",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, currentRoleName, testNamePrefix+"-removed-component1")
+				mockGetTaggedRole(mockAWSClient, currentRoleName, iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component1", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				return mockAWSClient
+			},
+			nameTemplates: []string{provisioning.NameTemplateAWSIAMRole + "=custom-{{.Component}}"},
+			expectError:   "refusing to delete every one of the 2 IAM Roles owned by " + testNamePrefix,
+		},
+		{
+			name: "Every role not deleted with dry run",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, testNamePrefix+"-removed-component1")
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component1", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				return mockAWSClient
+			},
+			dryRun:      true,
+			expectError: "refusing to delete every one of the 1 IAM Roles",
+		},
+		{
+			name: "Roles beyond the confirm threshold not deleted without confirmation",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListRoleNames(mockAWSClient, currentRoleName, testNamePrefix+"-removed-component1", testNamePrefix+"-removed-component2")
+				mockGetTaggedRole(mockAWSClient, currentRoleName, iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component1", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				mockGetTaggedRole(mockAWSClient, testNamePrefix+"-removed-component2", iamTagMap(iamTags(resourceTags(testNamePrefix, ""))))
+				return mockAWSClient
+			},
+			confirmThreshold: 1,
+			expectError:      "more than the --confirm-threshold of 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockAWSClient := test.mockAWSClient(mockCtrl)
+			require.NoError(t, provisioning.InitNameTemplates(test.nameTemplates), "failed to parse name templates")
+			defer provisioning.InitNameTemplates(nil)
+			confirmThreshold := test.confirmThreshold
+			if confirmThreshold == 0 {
+				confirmThreshold = provisioning.DefaultConfirmThreshold
+			}
+
+			credReqDir, err := os.MkdirTemp(os.TempDir(), "reconciledeletecredreqdir")
+			require.NoError(t, err, "failed to create temp directory")
+			defer os.RemoveAll(credReqDir)
+			if !test.noCredentialsRequests {
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+				require.NoError(t, err, "errored while setting up environment for test")
+			}
+
+			err = reconcileDelete(mockAWSClient, testNamePrefix, test.clusterID, []string{credReqDir}, false, test.dryRun, confirmThreshold, false)
+			if test.expectError != "" {
+				require.ErrorContains(t, err, test.expectError, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func mockListRoleNames(mockAWSClient *mockaws.MockClient, roleNames ...string) {
+	roles := []*iam.Role{}
+	for _, roleName := range roleNames {
+		roles = append(roles, &iam.Role{RoleName: awssdk.String(roleName)})
+	}
+	mockAWSClient.EXPECT().ListRoles(gomock.Any()).Return(&iam.ListRolesOutput{
+		Roles:       roles,
+		IsTruncated: awssdk.Bool(false),
+	}, nil).Times(1)
+}
+
+func mockDeleteRoleWithoutPolicies(mockAWSClient *mockaws.MockClient, roleName string) {
+	mockAWSClient.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: awssdk.String(roleName)}).Return(&iam.ListRolePoliciesOutput{}, nil).Times(1)
+	mockAWSClient.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: awssdk.String(roleName)}).Return(&iam.ListAttachedRolePoliciesOutput{}, nil).Times(1)
+	mockAWSClient.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: awssdk.String(roleName)}).Return(&iam.DeleteRoleOutput{}, nil).Times(1)
+}
@@ -0,0 +1,273 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// ReconcileTagsOpts captures the options that affect tagging the resources of an interrupted create
+	ReconcileTagsOpts = options{}
+)
+
+// reconcileTags applies ccoctl's tags for the name of inventory, and the cluster ownership tag when clusterID is
+// provided, to the resources recorded within inventory which lack the "owned" tag, eg. as create was interrupted
+// between creating and tagging them, so that they are found by ccoctl aws delete. Only the S3 bucket and the IAM
+// Identity Provider are tagged separately from their creation, the other resources are tagged as they are created.
+// Resources carrying the "owned" tag of another name are left untouched. When dryRun is set, resources which would
+// have been tagged are logged rather than tagged.
+func reconcileTags(client aws.Client, inventory *provisioning.Inventory, clusterID string, dryRun bool) error {
+	tagged := 0
+	for _, resource := range reconcileTagsResources(inventory) {
+		var (
+			found bool
+			err   error
+		)
+		switch resource.Type {
+		case s3BucketInventoryResourceType:
+			found, err = reconcileBucketTags(client, resource.Name, inventory.Name, clusterID, dryRun)
+		case identityProviderInventoryResourceType:
+			found, err = reconcileIdentityProviderTags(client, resource.ID, inventory.Settings[issuerURLInventorySetting], inventory.Name, clusterID, dryRun)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if found {
+			tagged++
+		}
+	}
+
+	if tagged == 0 {
+		log.Print("Found no resources lacking the owned tag")
+	} else if dryRun {
+		log.Printf("Dry run complete, %d resources would have been tagged", tagged)
+	} else {
+		log.Printf("Tagged %d resources", tagged)
+	}
+	return nil
+}
+
+// reconcileTagsResources returns the resources recorded within inventory, whether by a completed step or as intended
+// by an interrupted one, ordered by SortInventoryResources. A resource recorded with its ID is preferred.
+func reconcileTagsResources(inventory *provisioning.Inventory) []provisioning.InventoryResource {
+	resources := []provisioning.InventoryResource{}
+	indexes := map[string]int{}
+	record := func(resource provisioning.InventoryResource) {
+		key := resource.Type + "/" + resource.Name
+		if idx, found := indexes[key]; found {
+			if resources[idx].ID == "" {
+				resources[idx] = resource
+			}
+			return
+		}
+		indexes[key] = len(resources)
+		resources = append(resources, resource)
+	}
+	for _, step := range inventory.Steps {
+		for _, resource := range step.Resources {
+			record(resource)
+		}
+	}
+	for _, resource := range inventory.Intended {
+		record(resource)
+	}
+	return provisioning.SortInventoryResources(resources)
+}
+
+// missingOwnedTags returns the tags of name missing from tags, or nil when tags carry the "owned" tag of name or
+// of another name, in which case the resource is already managed and is left untouched
+func missingOwnedTags(tags map[string]string, resourceType, resourceID, name, clusterID string) []resourceTag {
+	ownedTagKey := fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name)
+	if tags[ownedTagKey] == ownedCcoctlAWSResourceTagValue {
+		return nil
+	}
+	for key, value := range tags {
+		if strings.HasPrefix(key, ccoctlAWSResourceTagKeyPrefix+"/") && value == ownedCcoctlAWSResourceTagValue {
+			provisioning.Warnf("Skipping %s %s which is owned by %s rather than %s", resourceType, resourceID,
+				strings.TrimPrefix(key, ccoctlAWSResourceTagKeyPrefix+"/"), name)
+			return nil
+		}
+	}
+	missingTags := []resourceTag{}
+	for _, tag := range resourceTags(name, clusterID) {
+		if _, found := tags[tag.Key]; !found {
+			missingTags = append(missingTags, tag)
+		}
+	}
+	return missingTags
+}
+
+// reconcileBucketTags tags the bucket identified by bucketName as owned by name when it exists without the "owned"
+// tag, returning whether it was tagged. The tags of a bucket are replaced as a whole, so its existing tags are kept.
+func reconcileBucketTags(client aws.Client, bucketName, name, clusterID string, dryRun bool) (bool, error) {
+	existingTags := []*s3.Tag{}
+	output, err := client.GetBucketTagging(&s3.GetBucketTaggingInput{
+		Bucket: awssdk.String(bucketName),
+	})
+	if err != nil {
+		aerr, ok := err.(awserr.Error)
+		switch {
+		case ok && aerr.Code() == s3.ErrCodeNoSuchBucket:
+			log.Printf("Bucket %s not found, it was never created", bucketName)
+			return false, nil
+		case ok && aerr.Code() == "NoSuchTagSet":
+		default:
+			return false, errors.Wrapf(err, "failed to fetch tags of the bucket %s", bucketName)
+		}
+	} else {
+		existingTags = output.TagSet
+	}
+
+	missingTags := missingOwnedTags(s3TagMap(existingTags), s3BucketInventoryResourceType, bucketName, name, clusterID)
+	if len(missingTags) == 0 {
+		return false, nil
+	}
+	if dryRun {
+		log.Printf("Would tag bucket %s as owned by %s", bucketName, name)
+		return true, nil
+	}
+	err = provisioning.TagResource(provisioning.TagOperation{
+		ResourceType: s3BucketInventoryResourceType,
+		ResourceName: bucketName,
+		Tag: func() error {
+			_, err := client.PutBucketTagging(&s3.PutBucketTaggingInput{
+				Bucket: awssdk.String(bucketName),
+				Tagging: &s3.Tagging{
+					TagSet: append(existingTags, s3Tags(missingTags)...),
+				},
+			})
+			return err
+		},
+	}, isThrottlingError)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to tag the bucket %s", bucketName)
+	}
+	log.Printf("Bucket %s tagged as owned by %s", bucketName, name)
+	provisioning.EmitResourceEvent(s3BucketInventoryResourceType, bucketName, provisioning.ResourceUpdated)
+	return true, nil
+}
+
+// reconcileIdentityProviderTags tags the IAM Identity Provider identified by providerARN, or found by issuerURL when
+// its ARN was not recorded, as owned by name when it exists without the "owned" tag, returning whether it was tagged
+func reconcileIdentityProviderTags(client aws.Client, providerARN, issuerURL, name, clusterID string, dryRun bool) (bool, error) {
+	if providerARN == "" {
+		if issuerURL == "" {
+			provisioning.Warnf("Skipping the Identity Provider of %s as the inventory records no issuer URL", name)
+			return false, nil
+		}
+		providers, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
+		if err != nil {
+			return false, errors.Wrap(err, "failed to fetch list of Identity Providers")
+		}
+		providerARN, err = findIdentityProviderForIssuerURL(client, providers.OpenIDConnectProviderList, issuerURL)
+		if err != nil {
+			return false, err
+		}
+		if providerARN == "" {
+			log.Printf("Identity Provider for issuer URL %s not found, it was never created", issuerURL)
+			return false, nil
+		}
+	}
+
+	provider, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(providerARN),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+			log.Printf("Identity Provider with ARN %s not found", providerARN)
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get Identity Provider with ARN %s", providerARN)
+	}
+
+	missingTags := missingOwnedTags(iamTagMap(provider.Tags), identityProviderInventoryResourceType, providerARN, name, clusterID)
+	if len(missingTags) == 0 {
+		return false, nil
+	}
+	if dryRun {
+		log.Printf("Would tag Identity Provider with ARN %s as owned by %s", providerARN, name)
+		return true, nil
+	}
+	err = provisioning.TagResource(provisioning.TagOperation{
+		ResourceType: identityProviderInventoryResourceType,
+		ResourceName: providerARN,
+		Tag: func() error {
+			_, err := client.TagOpenIDConnectProvider(&iam.TagOpenIDConnectProviderInput{
+				OpenIDConnectProviderArn: awssdk.String(providerARN),
+				Tags:                     iamTags(missingTags),
+			})
+			return err
+		},
+	}, isThrottlingError)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to tag the identity provider with arn: %s", providerARN)
+	}
+	log.Printf("Identity Provider with ARN %s tagged as owned by %s", providerARN, name)
+	provisioning.EmitResourceEvent(identityProviderInventoryResourceType, providerARN, provisioning.ResourceUpdated)
+	return true, nil
+}
+
+func reconcileTagsCmd(cmd *cobra.Command, args []string) {
+	if err := provisioning.ValidateClusterID(ReconcileTagsOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+
+	inventory, err := provisioning.LoadInventoryAt(ReconcileTagsOpts.InventoryPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if inventory.Provider != "aws" {
+		log.Fatalf("the inventory at path %s records the %s resources of %s, not aws resources", ReconcileTagsOpts.InventoryPath, inventory.Provider, inventory.Name)
+	}
+
+	// The bucket exists within the first region provided to create-all
+	region := ReconcileTagsOpts.Region
+	if region == "" {
+		region = strings.Split(inventory.Settings[regionsInventorySetting], ",")[0]
+		if region == "" {
+			log.Fatalf("the inventory at path %s records no regions, specify --region instead", ReconcileTagsOpts.InventoryPath)
+		}
+	}
+
+	s, err := awsSession(cmd.Context(), region, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := reconcileTags(aws.NewClientFromSession(s), inventory, ReconcileTagsOpts.ClusterID, ReconcileTagsOpts.DryRun); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NewReconcileTagsCmd provides the "reconcile-tags" subcommand
+func NewReconcileTagsCmd() *cobra.Command {
+	reconcileTagsCmd := &cobra.Command{
+		Use:   "reconcile-tags",
+		Short: "Tag the resources of an interrupted create which lack the owned tag",
+		Long: "Tagging the resources recorded within the inventory of create-all which exist without the owned tag, eg. as create-all " +
+			"was interrupted between creating and tagging them, so that they are managed, and deleted, by ccoctl again. Resources are " +
+			"only looked up by the names and issuer URL recorded within --inventory, resources owned by another name are left intact.",
+		Run: reconcileTagsCmd,
+	}
+
+	reconcileTagsCmd.PersistentFlags().StringVar(&ReconcileTagsOpts.InventoryPath, "inventory", "", "Path to the ccoctl-inventory.json file recorded by create-all, or to the output directory containing it")
+	reconcileTagsCmd.MarkPersistentFlagRequired("inventory")
+	reconcileTagsCmd.PersistentFlags().StringVar(&ReconcileTagsOpts.Region, "region", "", "AWS region where the OIDC bucket was created (defaults to the first region recorded within the inventory)")
+	reconcileTagsCmd.PersistentFlags().StringVar(&ReconcileTagsOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which the resources were created, the resources will also be tagged with 'kubernetes.io/cluster/<cluster ID> = owned'")
+	reconcileTagsCmd.PersistentFlags().BoolVar(&ReconcileTagsOpts.DryRun, "dry-run", false, "Skip tagging resources and display the resources that would have been tagged")
+
+	return reconcileTagsCmd
+}
@@ -0,0 +1,147 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestReconcileTags(t *testing.T) {
+	const testIssuerURL = "https://test-cluster1-oidc.s3.us-east-1.amazonaws.com"
+	bucketName := testNamePrefix + "-oidc"
+	ownedTags := resourceTags(testNamePrefix, "")
+
+	// interruptedInventory records the resources of a create interrupted before completing the identity provider step
+	interruptedInventory := func(t *testing.T) *provisioning.Inventory {
+		inventory := provisioning.NewInventory(t.TempDir(), "aws", testNamePrefix)
+		inventory.Settings[issuerURLInventorySetting] = testIssuerURL
+		require.NoError(t, inventory.RecordIntended(
+			provisioning.InventoryResource{Type: s3BucketInventoryResourceType, Name: bucketName},
+			provisioning.InventoryResource{Type: identityProviderInventoryResourceType, Name: testNamePrefix},
+		))
+		return inventory
+	}
+	mockGetBucketTagging := func(mockAWSClient *mockaws.MockClient, tags []*s3.Tag, err error) {
+		mockAWSClient.EXPECT().GetBucketTagging(&s3.GetBucketTaggingInput{Bucket: awssdk.String(bucketName)}).
+			Return(&s3.GetBucketTaggingOutput{TagSet: tags}, err)
+	}
+	mockFindIdentityProvider := func(mockAWSClient *mockaws.MockClient, tags []*iam.Tag) {
+		mockAWSClient.EXPECT().ListOpenIDConnectProviders(gomock.Any()).Return(&iam.ListOpenIDConnectProvidersOutput{
+			OpenIDConnectProviderList: []*iam.OpenIDConnectProviderListEntry{{Arn: awssdk.String(testIdentityProviderARN)}},
+		}, nil)
+		mockAWSClient.EXPECT().GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{OpenIDConnectProviderArn: awssdk.String(testIdentityProviderARN)}).
+			Return(&iam.GetOpenIDConnectProviderOutput{Url: awssdk.String("test-cluster1-oidc.s3.us-east-1.amazonaws.com"), Tags: tags}, nil).Times(2)
+	}
+
+	tests := []struct {
+		name          string
+		inventory     func(t *testing.T) *provisioning.Inventory
+		mockAWSClient func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		clusterID     string
+		dryRun        bool
+		expectError   string
+	}{
+		{
+			name:      "Untagged resources of an interrupted create are tagged",
+			inventory: interruptedInventory,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				existingTag := &s3.Tag{Key: awssdk.String("team"), Value: awssdk.String("platform")}
+				mockGetBucketTagging(mockAWSClient, []*s3.Tag{existingTag}, nil)
+				mockAWSClient.EXPECT().PutBucketTagging(&s3.PutBucketTaggingInput{
+					Bucket:  awssdk.String(bucketName),
+					Tagging: &s3.Tagging{TagSet: append([]*s3.Tag{existingTag}, s3Tags(resourceTags(testNamePrefix, testClusterID))...)},
+				}).Return(&s3.PutBucketTaggingOutput{}, nil)
+				mockFindIdentityProvider(mockAWSClient, nil)
+				mockAWSClient.EXPECT().TagOpenIDConnectProvider(&iam.TagOpenIDConnectProviderInput{
+					OpenIDConnectProviderArn: awssdk.String(testIdentityProviderARN),
+					Tags:                     iamTags(resourceTags(testNamePrefix, testClusterID)),
+				}).Return(&iam.TagOpenIDConnectProviderOutput{}, nil)
+				return mockAWSClient
+			},
+			clusterID: testClusterID,
+		},
+		{
+			name:      "Untagged resources not tagged with dry run",
+			inventory: interruptedInventory,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, nil, awserr.New("NoSuchTagSet", "The TagSet does not exist", nil))
+				mockFindIdentityProvider(mockAWSClient, nil)
+				return mockAWSClient
+			},
+			dryRun: true,
+		},
+		{
+			name:      "Resources never created are skipped",
+			inventory: interruptedInventory,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, nil, awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil))
+				mockAWSClient.EXPECT().ListOpenIDConnectProviders(gomock.Any()).Return(&iam.ListOpenIDConnectProvidersOutput{}, nil)
+				return mockAWSClient
+			},
+		},
+		{
+			name: "Tagged resources of completed steps are left untouched",
+			inventory: func(t *testing.T) *provisioning.Inventory {
+				inventory := interruptedInventory(t)
+				require.NoError(t, inventory.CompleteStep(identityProviderStep, identityProviderResources(testNamePrefix, testIdentityProviderARN, false)...))
+				require.NoError(t, inventory.CompleteStep("create-iam-roles", provisioning.InventoryResource{Type: iamRoleInventoryResourceType, Name: testNamePrefix + "-role"}))
+				return inventory
+			},
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, s3Tags(ownedTags), nil)
+				mockAWSClient.EXPECT().GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{OpenIDConnectProviderArn: awssdk.String(testIdentityProviderARN)}).
+					Return(&iam.GetOpenIDConnectProviderOutput{Tags: iamTags(ownedTags)}, nil)
+				return mockAWSClient
+			},
+		},
+		{
+			name:      "Resources owned by another name are left untouched",
+			inventory: interruptedInventory,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetBucketTagging(mockAWSClient, s3Tags(resourceTags("other-name", "")), nil)
+				mockFindIdentityProvider(mockAWSClient, iamTags(resourceTags("other-name", "")))
+				return mockAWSClient
+			},
+		},
+		{
+			name:      "Failure fetching the tags of the bucket",
+			inventory: interruptedInventory,
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockFindIdentityProvider(mockAWSClient, iamTags(ownedTags))
+				mockGetBucketTagging(mockAWSClient, nil, errors.New("access denied"))
+				return mockAWSClient
+			},
+			expectError: "failed to fetch tags of the bucket " + bucketName,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := reconcileTags(test.mockAWSClient(mockCtrl), test.inventory(t), test.clusterID, test.dryRun)
+			if test.expectError != "" {
+				assert.ErrorContains(t, err, test.expectError)
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// requestedRegionConditionKey is the global condition key restricting the regions within which the requests allowed
+// or denied by a policy statement are made
+const requestedRegionConditionKey = "aws:RequestedRegion"
+
+// RegionConflicts returns an error for every region named by spec which is not one of regions: the region of the ARN
+// of the resource of a statement, eg. arn:aws:kms:us-east-1:123456789012:key/<id>, and the regions of the
+// aws:RequestedRegion condition of a statement. The ARNs of global resources, eg. IAM Roles, carry no region and are
+// not checked, nor are regions matched with wildcards and the regions of negated conditions, eg. StringNotEquals.
+func RegionConflicts(spec *credreqv1.AWSProviderSpec, regions []string, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	providedRegions := sets.NewString(regions...)
+	conflicts := func(region string) bool {
+		return region != "" && !strings.ContainsAny(region, "*?") && !providedRegions.Has(region)
+	}
+	detail := fmt.Sprintf("not one of the regions provided to --region, %s", strings.Join(regions, ", "))
+
+	for i, entry := range spec.StatementEntries {
+		entryPath := fldPath.Child("statementEntries").Index(i)
+		if resourceARN, err := arn.Parse(entry.Resource); err == nil && conflicts(resourceARN.Region) {
+			errs = append(errs, field.Invalid(entryPath.Child("resource"), entry.Resource,
+				fmt.Sprintf("the resource is within region %s, %s", resourceARN.Region, detail)))
+		}
+
+		// The conditions are walked in order so that conflicts are reported in a stable order
+		operators := []string{}
+		for operator := range entry.PolicyCondition {
+			operators = append(operators, operator)
+		}
+		sort.Strings(operators)
+		for _, operator := range operators {
+			if strings.Contains(operator, "Not") {
+				continue
+			}
+			for key, value := range entry.PolicyCondition[operator] {
+				if !strings.EqualFold(key, requestedRegionConditionKey) {
+					continue
+				}
+				for _, region := range conditionValues(value) {
+					if conflicts(region) {
+						errs = append(errs, field.Invalid(entryPath.Child("policyCondition").Key(operator).Key(key), region,
+							fmt.Sprintf("the requests are restricted to region %s, %s", region, detail)))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// conditionValues returns the string values of a condition key, which is either a single value or a list of values
+func conditionValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// checkRegionConsistency ensures that the regions named by the inputs of create-all and create-iam-roles agree with
+// the regions provided to --region before any resource is created: the regions of the CredentialsRequests within
+// credReqDirs, as returned by RegionConflicts, and the region of the OIDC bucket recorded within the inventory of
+// targetDir by a previous create-all for name, when targetDir is provided. Every conflict is reported at once. Nothing
+// is checked when no region is provided.
+func checkRegionConsistency(credReqDirs []string, targetDir, name string, regions []string, enableTechPreview bool) error {
+	if len(regions) == 0 {
+		return nil
+	}
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	conflicts, err := findRegionConflicts(credRequests, regions)
+	if err != nil {
+		return err
+	}
+
+	if targetDir != "" {
+		inventory, err := provisioning.LoadInventory(targetDir)
+		if err == nil && inventory.Provider == "aws" && inventory.Name == name && inventory.Settings[regionsInventorySetting] != "" {
+			if bucketRegion := strings.Split(inventory.Settings[regionsInventorySetting], ",")[0]; bucketRegion != regions[0] {
+				conflicts = append(conflicts, fmt.Sprintf("the inventory within %s records the OIDC bucket of %s within region %s, while it is created within the first region provided to --region, %s",
+					targetDir, name, bucketRegion, regions[0]))
+			}
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %d inputs conflicting with the regions provided to --region:\n  %s\nreconcile them with --region, or pass --skip-region-consistency-check to proceed regardless",
+		len(conflicts), strings.Join(conflicts, "\n  "))
+}
+
+// findRegionConflicts returns the regions named by the AWS CredentialsRequests of credReqs which are not one of
+// regions, naming the CredentialsRequest. CredentialsRequests which can't be processed are ignored, they fail when
+// their IAM Role is created.
+func findRegionConflicts(credReqs []*credreqv1.CredentialsRequest, regions []string) ([]string, error) {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create credReq codec")
+	}
+
+	conflicts := []string{}
+	for _, cr := range credReqs {
+		if cr.Spec.ProviderSpec == nil {
+			continue
+		}
+		awsProviderSpec := credreqv1.AWSProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &awsProviderSpec); err != nil || awsProviderSpec.Kind != "AWSProviderSpec" {
+			continue
+		}
+		for _, fieldErr := range RegionConflicts(&awsProviderSpec, regions, field.NewPath("spec", "providerSpec")) {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", provisioning.CredentialsRequestComponent(cr), fieldErr.Error()))
+		}
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestRegionConflicts(t *testing.T) {
+	tests := []struct {
+		name           string
+		statements     []credreqv1.StatementEntry
+		regions        []string
+		expectedFields []string
+	}{
+		{
+			name: "Resources within the provided regions",
+			statements: []credreqv1.StatementEntry{
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:us-east-1:123456789012:key/test"},
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:eu-west-1:123456789012:key/test"},
+			},
+			regions: []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			name: "Global, wildcard and non-ARN resources",
+			statements: []credreqv1.StatementEntry{
+				{Effect: "Allow", Action: []string{"iam:GetRole"}, Resource: "arn:aws:iam::123456789012:role/test"},
+				{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: "arn:aws:s3:::test-bucket/*"},
+				{Effect: "Allow", Action: []string{"ec2:DescribeInstances"}, Resource: "arn:aws:ec2:*:123456789012:instance/*"},
+				{Effect: "Allow", Action: []string{"ec2:DescribeRegions"}, Resource: "*"},
+			},
+			regions: []string{"us-east-1"},
+		},
+		{
+			name: "Resource within another region",
+			statements: []credreqv1.StatementEntry{
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:us-east-1:123456789012:key/test"},
+				{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:us-west-2:123456789012:key/test"},
+			},
+			regions:        []string{"us-east-1"},
+			expectedFields: []string{"spec.providerSpec.statementEntries[1].resource"},
+		},
+		{
+			name: "Requests restricted to another region",
+			statements: []credreqv1.StatementEntry{
+				{Effect: "Allow", Action: []string{"ec2:RunInstances"}, Resource: "*", PolicyCondition: credreqv1.IAMPolicyCondition{
+					"StringEquals": credreqv1.IAMPolicyConditionKeyValue{"aws:RequestedRegion": []interface{}{"us-east-1", "us-west-2"}},
+				}},
+				{Effect: "Allow", Action: []string{"ec2:RunInstances"}, Resource: "*", PolicyCondition: credreqv1.IAMPolicyCondition{
+					"StringEquals": credreqv1.IAMPolicyConditionKeyValue{"aws:requestedregion": "eu-west-1"},
+				}},
+			},
+			regions: []string{"us-east-1"},
+			expectedFields: []string{
+				"spec.providerSpec.statementEntries[0].policyCondition[StringEquals][aws:RequestedRegion]",
+				"spec.providerSpec.statementEntries[1].policyCondition[StringEquals][aws:requestedregion]",
+			},
+		},
+		{
+			name: "Negated and wildcard region conditions",
+			statements: []credreqv1.StatementEntry{
+				{Effect: "Allow", Action: []string{"ec2:RunInstances"}, Resource: "*", PolicyCondition: credreqv1.IAMPolicyCondition{
+					"StringNotEquals": credreqv1.IAMPolicyConditionKeyValue{"aws:RequestedRegion": "us-west-2"},
+					"StringLike":      credreqv1.IAMPolicyConditionKeyValue{"aws:RequestedRegion": "us-*"},
+				}},
+			},
+			regions: []string{"us-east-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := &credreqv1.AWSProviderSpec{StatementEntries: test.statements}
+			errs := RegionConflicts(spec, test.regions, field.NewPath("spec", "providerSpec"))
+			fields := []string{}
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, test.expectedFields, fields, "unexpected region conflicts")
+		})
+	}
+}
+
+func TestCheckRegionConsistency(t *testing.T) {
+	tests := []struct {
+		name             string
+		resource         string
+		inventoryRegions string
+		regions          []string
+		expectError      []string
+	}{
+		{
+			name:             "Consistent regions",
+			resource:         "arn:aws:kms:us-east-1:123456789012:key/test",
+			inventoryRegions: "us-east-1,eu-west-1",
+			regions:          []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			name:     "No inventory",
+			resource: "arn:aws:kms:eu-west-1:123456789012:key/test",
+			regions:  []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			name:             "CredentialsRequest and inventory naming other regions",
+			resource:         "arn:aws:kms:us-west-2:123456789012:key/test",
+			inventoryRegions: "eu-west-1,us-east-1",
+			regions:          []string{"us-east-1", "eu-west-1"},
+			expectError: []string{
+				"found 2 inputs conflicting with the regions provided to --region",
+				"CredentialsRequest openshift-cloud-credential-operator/test-credreq: spec.providerSpec.statementEntries[0].resource",
+				"records the OIDC bucket of " + testNamePrefix + " within region eu-west-1",
+				"--skip-region-consistency-check",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			credReqDir := t.TempDir()
+			targetDir := t.TempDir()
+			codec, err := credreqv1.NewCodec()
+			require.NoError(t, err, "failed to create codec")
+			providerSpec, err := codec.EncodeProviderSpec(&credreqv1.AWSProviderSpec{
+				StatementEntries: []credreqv1.StatementEntry{{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: test.resource}},
+			})
+			require.NoError(t, err, "failed to encode provider spec")
+			cr := &credreqv1.CredentialsRequest{Spec: credreqv1.CredentialsRequestSpec{ProviderSpec: providerSpec}}
+			cr.APIVersion = "cloudcredential.openshift.io/v1"
+			cr.Kind = "CredentialsRequest"
+			cr.Name = "test-credreq"
+			cr.Namespace = "openshift-cloud-credential-operator"
+			cr.Spec.SecretRef.Namespace = "namespace1"
+			cr.Spec.SecretRef.Name = "secretName1"
+			data, err := yaml.Marshal(cr)
+			require.NoError(t, err, "failed to encode CredentialsRequest")
+			require.NoError(t, os.WriteFile(filepath.Join(credReqDir, "credreq.yaml"), data, 0600))
+
+			if test.inventoryRegions != "" {
+				inventory := provisioning.NewInventory(targetDir, "aws", testNamePrefix)
+				inventory.Settings[regionsInventorySetting] = test.inventoryRegions
+				require.NoError(t, inventory.Save(), "failed to save inventory")
+			}
+
+			err = checkRegionConsistency([]string{credReqDir}, targetDir, testNamePrefix, test.regions, false)
+			if len(test.expectError) == 0 {
+				require.NoError(t, err, "unexpected error")
+				return
+			}
+			require.Error(t, err, "expected region conflicts")
+			for _, expected := range test.expectError {
+				assert.Contains(t, err.Error(), expected, "unexpected error")
+			}
+		})
+	}
+
+	assert.NoError(t, checkRegionConsistency([]string{t.TempDir()}, "", testNamePrefix, nil, false), "unexpected error without regions")
+}
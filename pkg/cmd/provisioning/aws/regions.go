@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// regionsInventorySetting is the inventory setting recording the comma-separated regions provided to create-all,
+	// the first being the region of the OIDC bucket
+	regionsInventorySetting = "regions"
+
+	// bucketRegionErrorCode is the code of the error returned by the SDK when a request for a bucket is sent to
+	// the endpoint of another region than the region of the bucket
+	bucketRegionErrorCode = "BucketRegionError"
+)
+
+// regionalClient is a client of the AWS API within region
+type regionalClient struct {
+	region string
+	client aws.Client
+}
+
+// validateRegions returns the regions provided to --region with duplicates dropped, in the order they were first
+// provided. IAM Roles and the IAM Identity Provider are global within a partition, so every region must belong to
+// the same partition.
+func validateRegions(regions []string) ([]string, error) {
+	regions, err := provisioning.UniqueScopes("--region", regions)
+	if err != nil {
+		return nil, err
+	}
+	partitions := []endpoints.Partition{endpoints.AwsPartition(), endpoints.AwsCnPartition(), endpoints.AwsUsGovPartition()}
+	var partitionID string
+	for _, region := range regions {
+		partition, found := endpoints.PartitionForRegion(partitions, region)
+		if !found {
+			return nil, fmt.Errorf("could not find AWS partition for provided region %s", region)
+		}
+		if partitionID == "" {
+			partitionID = partition.ID()
+		} else if partition.ID() != partitionID {
+			return nil, fmt.Errorf("invalid --region, region %s of partition %s may not be combined with regions of partition %s", region, partition.ID(), partitionID)
+		}
+	}
+	return regions, nil
+}
+
+// regionsFromInventory returns the regions recorded by create-all within the inventory at path, which is either an
+// inventory file or the output directory within which it was saved, for the resources of name
+func regionsFromInventory(path, name string) ([]string, error) {
+	inventory, err := provisioning.LoadInventoryAt(path)
+	if err != nil {
+		return nil, err
+	}
+	if inventory.Provider != "aws" || inventory.Name != name {
+		return nil, fmt.Errorf("the inventory at path %s records the %s resources of %s, not the aws resources of %s", path, inventory.Provider, inventory.Name, name)
+	}
+	if inventory.Settings[regionsInventorySetting] == "" {
+		return nil, fmt.Errorf("the inventory at path %s records no regions, specify --region instead", path)
+	}
+	return strings.Split(inventory.Settings[regionsInventorySetting], ","), nil
+}
+
+// bucketWithinRegion returns whether the bucket identified by bucketName exists within the region of client. Requests
+// for a bucket of another region are rejected by the regional endpoint of client.
+func bucketWithinRegion(client aws.Client, bucketName string) (bool, error) {
+	_, err := client.GetBucketTagging(&s3.GetBucketTaggingInput{
+		Bucket: awssdk.String(bucketName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case s3.ErrCodeNoSuchBucket, bucketRegionErrorCode:
+				return false, nil
+			case "NoSuchTagSet":
+				return true, nil
+			}
+		}
+		return false, errors.Wrapf(err, "failed to fetch tags of the bucket %s", bucketName)
+	}
+	return true, nil
+}
+
+// deleteRegionalResources deletes the regional resources created by ccoctl for name within the region of client,
+// which are the OIDC bucket and its objects. When deleting across several regions the bucket, which exists within a
+// single region, is skipped within the other regions.
+func deleteRegionalResources(client regionalClient, name, clusterID string, multiRegion bool) []error {
+	bucketName := fmt.Sprintf("%s-oidc", name)
+	if multiRegion {
+		found, err := bucketWithinRegion(client.client, bucketName)
+		if err != nil {
+			log.Print(err)
+			return []error{err}
+		}
+		if !found {
+			log.Printf("OIDC bucket %s not found within region %s", bucketName, client.region)
+			return nil
+		}
+	}
+
+	errs := []error{}
+	provisioning.SetPhase("deleting the objects of the OIDC bucket " + bucketName)
+	if err := deleteOIDCObjectsFromBucket(client.client, bucketName, name, clusterID); err != nil {
+		log.Print(err)
+		errs = append(errs, err)
+	}
+
+	provisioning.SetPhase("deleting the OIDC bucket " + bucketName)
+	if err := deleteOIDCBucket(client.client, bucketName, name, clusterID); err != nil {
+		log.Print(err)
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestValidateRegions(t *testing.T) {
+	tests := []struct {
+		name          string
+		regions       []string
+		expectRegions []string
+		expectError   string
+	}{
+		{
+			name:          "Single region",
+			regions:       []string{"us-east-1"},
+			expectRegions: []string{"us-east-1"},
+		},
+		{
+			name:          "Several regions with duplicates",
+			regions:       []string{"us-east-1", "eu-west-1", "us-east-1"},
+			expectRegions: []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			name:        "No region",
+			expectError: "--region must be provided",
+		},
+		{
+			name:        "Regions of several partitions",
+			regions:     []string{"us-east-1", "us-gov-west-1"},
+			expectError: "region us-gov-west-1 of partition aws-us-gov may not be combined with regions of partition aws",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			regions, err := validateRegions(test.regions)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectRegions, regions, "unexpected regions")
+		})
+	}
+}
+
+func TestRegionsFromInventory(t *testing.T) {
+	dir := t.TempDir()
+	inventory := provisioning.NewInventory(dir, "aws", testInfraName)
+	inventory.Settings[regionsInventorySetting] = "us-east-1,eu-west-1"
+	require.NoError(t, inventory.Save(), "unexpected error saving inventory")
+
+	regions, err := regionsFromInventory(dir, testInfraName)
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, []string{"us-east-1", "eu-west-1"}, regions, "unexpected regions")
+
+	_, err = regionsFromInventory(dir, "other-name")
+	assert.ErrorContains(t, err, "not the aws resources of other-name", "unexpected error")
+
+	otherDir := t.TempDir()
+	require.NoError(t, provisioning.NewInventory(otherDir, "aws", testInfraName).Save(), "unexpected error saving inventory")
+	_, err = regionsFromInventory(otherDir, testInfraName)
+	assert.ErrorContains(t, err, "records no regions", "unexpected error")
+}
+
+func TestDeleteRegionalResourcesAcrossRegions(t *testing.T) {
+	bucketName := fmt.Sprintf("%s-oidc", testInfraName)
+	bucketTags := &s3.GetBucketTaggingOutput{
+		TagSet: []*s3.Tag{{
+			Key:   awssdk.String(fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testInfraName)),
+			Value: awssdk.String(ownedCcoctlAWSResourceTagValue),
+		}},
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// The bucket was created within the second region, the endpoint of the first region rejects its requests
+	otherRegionClient := mockaws.NewMockClient(mockCtrl)
+	otherRegionClient.EXPECT().GetBucketTagging(gomock.Any()).Return(nil, awserr.New(bucketRegionErrorCode, "incorrect region, the bucket is not in 'us-east-1' region", nil))
+
+	bucketRegionClient := mockaws.NewMockClient(mockCtrl)
+	bucketRegionClient.EXPECT().GetBucketTagging(&s3.GetBucketTaggingInput{Bucket: awssdk.String(bucketName)}).Return(bucketTags, nil).Times(2)
+	bucketRegionClient.EXPECT().ListObjects(gomock.Any()).Return(&s3.ListObjectsOutput{}, nil)
+	bucketRegionClient.EXPECT().DeleteBucket(&s3.DeleteBucketInput{Bucket: awssdk.String(bucketName)}).Return(&s3.DeleteBucketOutput{}, nil)
+
+	// The bucket was already deleted by a previous run within the third region
+	deletedRegionClient := mockaws.NewMockClient(mockCtrl)
+	deletedRegionClient.EXPECT().GetBucketTagging(gomock.Any()).Return(nil, awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil))
+
+	for _, client := range []regionalClient{
+		{region: "us-east-1", client: otherRegionClient},
+		{region: "eu-west-1", client: bucketRegionClient},
+		{region: "ap-south-1", client: deletedRegionClient},
+	} {
+		assert.Empty(t, deleteRegionalResources(client, testInfraName, "", true), "unexpected errors within region %s", client.region)
+	}
+}
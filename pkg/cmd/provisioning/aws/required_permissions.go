@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// requiredOperations are the operations of the AWS client performed by ccoctl with the IAM actions they require.
+// Resolving the caller identity through STS requires no permission. The operations are verified against the calls of
+// the AWS client by the tests, the list must be updated along with the code.
+var requiredOperations = []provisioning.ClientOperation{
+	// IAM
+	{Operation: "AddClientIDToOpenIDConnectProvider", Permissions: []string{"iam:AddClientIDToOpenIDConnectProvider"}, Flows: provisioning.CreateFlows},
+	{Operation: "AttachRolePolicy", Permissions: []string{"iam:AttachRolePolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreateOpenIDConnectProvider", Permissions: []string{"iam:CreateOpenIDConnectProvider"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreatePolicy", Permissions: []string{"iam:CreatePolicy", "iam:TagPolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreatePolicyVersion", Permissions: []string{"iam:CreatePolicyVersion"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreateRole", Permissions: []string{"iam:CreateRole", "iam:TagRole"}, Flows: provisioning.CreateFlows},
+	{Operation: "DeleteOpenIDConnectProvider", Permissions: []string{"iam:DeleteOpenIDConnectProvider"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeletePolicy", Permissions: []string{"iam:DeletePolicy"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "DeletePolicyVersion", Permissions: []string{"iam:DeletePolicyVersion"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "DeleteRole", Permissions: []string{"iam:DeleteRole"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteRolePolicy", Permissions: []string{"iam:DeleteRolePolicy"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "DetachRolePolicy", Permissions: []string{"iam:DetachRolePolicy"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetAccountSummary", Permissions: []string{"iam:GetAccountSummary"}, Flows: provisioning.CreateFlows},
+	{Operation: "GetOpenIDConnectProvider", Permissions: []string{"iam:GetOpenIDConnectProvider"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetPolicy", Permissions: []string{"iam:GetPolicy"}, Flows: provisioning.DeleteFlows},
+	{Operation: "GetRole", Permissions: []string{"iam:GetRole"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "ListAttachedRolePolicies", Permissions: []string{"iam:ListAttachedRolePolicies"}, Flows: provisioning.DeleteFlows},
+	{Operation: "ListOpenIDConnectProviders", Permissions: []string{"iam:ListOpenIDConnectProviders"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "ListPolicyVersions", Permissions: []string{"iam:ListPolicyVersions"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "ListRolePolicies", Permissions: []string{"iam:ListRolePolicies"}, Flows: provisioning.DeleteFlows},
+	{Operation: "ListRoles", Permissions: []string{"iam:ListRoles"}, Flows: provisioning.DeleteFlows},
+	{Operation: "PutRolePolicy", Permissions: []string{"iam:PutRolePolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "SimulatePrincipalPolicyPages", Permissions: []string{"iam:SimulatePrincipalPolicy"}, Flows: provisioning.DeleteFlows},
+	{Operation: "TagOpenIDConnectProvider", Permissions: []string{"iam:TagOpenIDConnectProvider"}, Flows: provisioning.CreateFlows},
+	{Operation: "UntagOpenIDConnectProvider", Permissions: []string{"iam:UntagOpenIDConnectProvider"}, Flows: provisioning.DeleteFlows},
+	{Operation: "UpdateRole", Permissions: []string{"iam:UpdateRole"}, Flows: provisioning.CreateFlows},
+
+	// S3
+	{Operation: "CreateBucket", Permissions: []string{"s3:CreateBucket"}, Flows: provisioning.CreateFlows},
+	{Operation: "DeleteBucket", Permissions: []string{"s3:DeleteBucket"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteObject", Permissions: []string{"s3:DeleteObject"}, Flows: provisioning.DeleteFlows},
+	{Operation: "GetBucketTagging", Permissions: []string{"s3:GetBucketTagging"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetObjectTagging", Permissions: []string{"s3:GetObjectTagging"}, Flows: provisioning.DeleteFlows},
+	{Operation: "ListObjects", Permissions: []string{"s3:ListBucket"}, Flows: provisioning.DeleteFlows},
+	{Operation: "PutBucketPolicy", Permissions: []string{"s3:PutBucketPolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "PutBucketTagging", Permissions: []string{"s3:PutBucketTagging"}, Flows: provisioning.CreateFlows},
+	{Operation: "PutObject", Permissions: []string{"s3:PutObject", "s3:PutObjectTagging"}, Flows: provisioning.CreateFlows},
+	{Operation: "PutPublicAccessBlock", Permissions: []string{"s3:PutBucketPublicAccessBlock"}, Flows: provisioning.CreateFlows},
+
+	// CloudFront
+	{Operation: "CreateCloudFrontDistributionWithTags", Permissions: []string{"cloudfront:CreateDistribution", "cloudfront:TagResource"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreateCloudFrontOriginAccessIdentity", Permissions: []string{"cloudfront:CreateCloudFrontOriginAccessIdentity"}, Flows: provisioning.CreateFlows},
+	{Operation: "DeleteCloudFrontDistribution", Permissions: []string{"cloudfront:DeleteDistribution"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteCloudFrontOriginAccessIdentity", Permissions: []string{"cloudfront:DeleteCloudFrontOriginAccessIdentity"}, Flows: provisioning.DeleteFlows},
+	{Operation: "GetCloudFrontDistribution", Permissions: []string{"cloudfront:GetDistribution"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetCloudFrontOriginAccessIdentity", Permissions: []string{"cloudfront:GetCloudFrontOriginAccessIdentity"}, Flows: provisioning.DeleteFlows},
+	{Operation: "ListCloudFrontDistributions", Permissions: []string{"cloudfront:ListDistributions"}, Flows: provisioning.DeleteFlows},
+	{Operation: "ListCloudFrontOriginAccessIdentities", Permissions: []string{"cloudfront:ListCloudFrontOriginAccessIdentities"}, Flows: provisioning.DeleteFlows},
+	{Operation: "ListTagsForCloudFrontResource", Permissions: []string{"cloudfront:ListTagsForResource"}, Flows: provisioning.DeleteFlows},
+	{Operation: "UpdateCloudFrontDistribution", Permissions: []string{"cloudfront:UpdateDistribution"}, Flows: provisioning.DeleteFlows},
+}
+
+// requiredPermissionsPolicy returns an IAM policy document allowing permissions on every resource, as the names of
+// most resources created by ccoctl are only known once they are created
+func requiredPermissionsPolicy(permissions []string) ([]byte, error) {
+	return json.MarshalIndent(PolicyDocument{
+		Version: "2012-10-17",
+		Statement: []StatementEntry{
+			{
+				Effect:   "Allow",
+				Action:   permissions,
+				Resource: "*",
+			},
+		},
+	}, "", "    ")
+}
+
+// NewPrintRequiredPermissionsCmd provides the "print-required-permissions" subcommand
+func NewPrintRequiredPermissionsCmd() *cobra.Command {
+	return provisioning.NewPrintRequiredPermissionsCmd("AWS", "an IAM policy document", requiredOperations, requiredPermissionsPolicy)
+}
@@ -0,0 +1,53 @@
+package aws
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestRequiredOperations(t *testing.T) {
+	// Every operation of the AWS client called by ccoctl is listed with the flows calling it, and nothing else
+	clientType := reflect.TypeOf((*aws.Client)(nil)).Elem()
+	called, err := provisioning.ClientOperationCalls(".", nil, func(receiver, method string) string {
+		if _, found := clientType.MethodByName(method); found {
+			return method
+		}
+		return ""
+	}, "delete.go", "reconcile_delete.go", "deletion_permissions.go")
+	require.NoError(t, err, "unexpected error")
+
+	listed := map[string][]string{}
+	for _, operation := range requiredOperations {
+		assert.NotContains(t, listed, operation.Operation, "duplicate operation %s", operation.Operation)
+		assert.NotEmpty(t, operation.Permissions, "expected permissions for operation %s", operation.Operation)
+		listed[operation.Operation] = operation.Flows
+	}
+	assert.Equal(t, called, listed, "the required operations do not match the operations of the AWS client called by ccoctl")
+}
+
+func TestRequiredPermissionsPolicy(t *testing.T) {
+	data, err := requiredPermissionsPolicy(provisioning.RequiredPermissions(requiredOperations, provisioning.FlowDelete))
+	require.NoError(t, err, "unexpected error")
+
+	policy := PolicyDocument{}
+	require.NoError(t, json.Unmarshal(data, &policy), "unexpected error decoding policy")
+	require.Len(t, policy.Statement, 1, "expected a single statement")
+	statement := policy.Statement[0]
+	assert.Equal(t, "Allow", statement.Effect, "unexpected effect")
+	assert.Equal(t, "*", statement.Resource, "unexpected resource")
+	assert.Contains(t, statement.Action, "iam:DeleteRole", "expected permission to delete roles")
+	assert.NotContains(t, statement.Action, "iam:CreateRole", "unexpected permission of the create flow")
+	assert.NotContains(t, string(data), "Condition", "unexpected condition")
+
+	// Every permission checked before deletion is required by the delete flow
+	for _, permission := range deletionPermissions("aws", "123456789012", testInfraName) {
+		assert.Contains(t, statement.Action, permission.Permission, "expected permission checked before deletion to be required")
+	}
+}
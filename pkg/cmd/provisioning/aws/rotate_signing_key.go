@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// RotateSigningKeyOpts captures the options that affect the rotation of the service account signing key
+	RotateSigningKeyOpts = options{}
+	// FinalizeRotationOpts captures the options that affect the removal of the previous service account signing key
+	FinalizeRotationOpts = options{}
+)
+
+// rotateSigningKey generates a new signing key pair within the output directory and publishes the JSON web key set
+// containing both the previous and the new public keys to the OIDC S3 bucket created for name
+func rotateSigningKey(client aws.Client, name, clusterID, previousPublicKeyPath, targetDir string, dryRun bool) error {
+	jwks, err := provisioning.RotateSigningKey(targetDir, previousPublicKeyPath)
+	if err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(client, jwks, fmt.Sprintf("%s-oidc", name), name, clusterID, targetDir, dryRun)
+}
+
+// finalizeRotation publishes the JSON web key set containing only the public key at publicKeyPath to the OIDC S3
+// bucket created for name, removing the previous public key published by rotateSigningKey
+func finalizeRotation(client aws.Client, name, clusterID, publicKeyPath, targetDir string, dryRun bool) error {
+	jwks, err := provisioning.FinalizeRotation(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(client, jwks, fmt.Sprintf("%s-oidc", name), name, clusterID, targetDir, dryRun)
+}
+
+func rotateSigningKeyCmd(cmd *cobra.Command, args []string) {
+	s, err := awsSession(cmd.Context(), RotateSigningKeyOpts.Region, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = rotateSigningKey(aws.NewClientFromSession(s), RotateSigningKeyOpts.Name, RotateSigningKeyOpts.ClusterID,
+		RotateSigningKeyOpts.PreviousPublicKeyPath, RotateSigningKeyOpts.TargetDir, RotateSigningKeyOpts.DryRun)
+	if err != nil {
+		log.Fatalf("Failed to rotate the signing key: %s", err)
+	}
+}
+
+func finalizeRotationCmd(cmd *cobra.Command, args []string) {
+	s, err := awsSession(cmd.Context(), FinalizeRotationOpts.Region, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	publicKeyPath := FinalizeRotationOpts.PublicKeyPath
+	if publicKeyPath == "" {
+		publicKeyPath = filepath.Join(FinalizeRotationOpts.TargetDir, provisioning.PublicKeyFile)
+	}
+
+	err = finalizeRotation(aws.NewClientFromSession(s), FinalizeRotationOpts.Name, FinalizeRotationOpts.ClusterID,
+		publicKeyPath, FinalizeRotationOpts.TargetDir, FinalizeRotationOpts.DryRun)
+	if err != nil {
+		log.Fatalf("Failed to finalize the signing key rotation: %s", err)
+	}
+}
+
+// initEnvForRotationCmd validates the cluster ID and ensures the output directory is ready to receive the generated files
+func initEnvForRotationCmd(opts *options) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := provisioning.ValidateClusterID(opts.ClusterID); err != nil {
+			log.Fatal(err)
+		}
+
+		targetDir, err := provisioning.InitRotationOutputDir(opts.TargetDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.TargetDir = targetDir
+	}
+}
+
+// NewRotateSigningKeyCmd provides the "rotate-signing-key" subcommand
+func NewRotateSigningKeyCmd() *cobra.Command {
+	rotateSigningKeyCmd := &cobra.Command{
+		Use:   "rotate-signing-key",
+		Short: "Publish a new service account signing key alongside the previous key",
+		Long: "Generate a new service account signing key pair within the output directory and publish a JSON web key set containing both the previous and the new public keys, " +
+			"so that tokens signed with either key are trusted while the cluster cuts over to the new key. Apply the next signing key secret manifest written to the manifests directory " +
+			"to the cluster, then run finalize-rotation once the cluster has cut over to remove the previous key",
+		Run:              rotateSigningKeyCmd,
+		PersistentPreRun: initEnvForRotationCmd(&RotateSigningKeyOpts),
+	}
+
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.Name, "name", "", "User-defined name for all created AWS resources (can be separate from the cluster's infra-id)")
+	rotateSigningKeyCmd.MarkPersistentFlagRequired("name")
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.Region, "region", "", "AWS region where the S3 OpenID Connect endpoint was created")
+	rotateSigningKeyCmd.MarkPersistentFlagRequired("region")
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.PreviousPublicKeyPath, "previous-public-key-file", "", "Path to the public ServiceAccount signing key currently used by the cluster")
+	rotateSigningKeyCmd.MarkPersistentFlagRequired("previous-public-key-file")
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.TargetDir, "output-dir", "", "Directory to place the new key pair and generated files (defaults to current directory), must not contain the previous key pair")
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag the JSON web key set, the object will be tagged with 'kubernetes.io/cluster/<cluster ID> = owned'")
+	rotateSigningKeyCmd.PersistentFlags().BoolVar(&RotateSigningKeyOpts.DryRun, "dry-run", false, "Skip uploading the JSON web key set, and just save it into a file")
+
+	return rotateSigningKeyCmd
+}
+
+// NewFinalizeRotationCmd provides the "finalize-rotation" subcommand
+func NewFinalizeRotationCmd() *cobra.Command {
+	finalizeRotationCmd := &cobra.Command{
+		Use:   "finalize-rotation",
+		Short: "Remove the previous service account signing key once the cluster has cut over to the new key",
+		Long: "Publish a JSON web key set containing only the new public key generated by rotate-signing-key, so that tokens signed with the previous key are no longer trusted. " +
+			"Run it once the cluster has cut over to the new key and tokens signed with the previous key have expired",
+		Run:              finalizeRotationCmd,
+		PersistentPreRun: initEnvForRotationCmd(&FinalizeRotationOpts),
+	}
+
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.Name, "name", "", "User-defined name for all created AWS resources (can be separate from the cluster's infra-id)")
+	finalizeRotationCmd.MarkPersistentFlagRequired("name")
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.Region, "region", "", "AWS region where the S3 OpenID Connect endpoint was created")
+	finalizeRotationCmd.MarkPersistentFlagRequired("region")
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.PublicKeyPath, "public-key-file", "", "Path to the new public ServiceAccount signing key (defaults to the public key within --output-dir)")
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.TargetDir, "output-dir", "", "Directory containing the new key pair generated by rotate-signing-key (defaults to current directory)")
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag the JSON web key set, the object will be tagged with 'kubernetes.io/cluster/<cluster ID> = owned'")
+	finalizeRotationCmd.PersistentFlags().BoolVar(&FinalizeRotationOpts.DryRun, "dry-run", false, "Skip uploading the JSON web key set, and just save it into a file")
+
+	return finalizeRotationCmd
+}
@@ -0,0 +1,58 @@
+package aws
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestRotateSigningKey(t *testing.T) {
+	previousDir, err := provisioning.InitRotationOutputDir(filepath.Join(t.TempDir(), "previous"))
+	require.NoError(t, err, "failed to prepare previous output directory")
+	require.NoError(t, provisioning.CreateKeys(previousDir), "failed to create previous key pair")
+	targetDir, err := provisioning.InitRotationOutputDir(filepath.Join(t.TempDir(), "rotation"))
+	require.NoError(t, err, "failed to prepare output directory")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+
+	// Capture the JSON web key sets uploaded to the OIDC bucket
+	uploadedKeySets := []provisioning.JSONWebKeySet{}
+	mockAWSClient.EXPECT().PutObject(gomock.Any()).DoAndReturn(func(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+		assert.Equal(t, testNamePrefix+"-oidc", *input.Bucket, "unexpected bucket")
+		assert.Equal(t, provisioning.KeysURI, *input.Key, "unexpected object key")
+		data, err := io.ReadAll(input.Body)
+		require.NoError(t, err, "failed to read uploaded object")
+		keySet := provisioning.JSONWebKeySet{}
+		require.NoError(t, json.Unmarshal(data, &keySet), "failed to decode uploaded JSON web key set")
+		uploadedKeySets = append(uploadedKeySets, keySet)
+		return &s3.PutObjectOutput{}, nil
+	}).Times(2)
+
+	err = rotateSigningKey(mockAWSClient, testNamePrefix, "", filepath.Join(previousDir, provisioning.PublicKeyFile), targetDir, false)
+	require.NoError(t, err, "unexpected error rotating signing key")
+	assert.FileExists(t, filepath.Join(targetDir, provisioning.ManifestsDirName, provisioning.NextSigningKeySecretFile), "expected next signing key secret manifest")
+
+	err = finalizeRotation(mockAWSClient, testNamePrefix, "", filepath.Join(targetDir, provisioning.PublicKeyFile), targetDir, false)
+	require.NoError(t, err, "unexpected error finalizing rotation")
+
+	require.Len(t, uploadedKeySets, 2, "expected JSON web key sets to be uploaded by rotation and finalization")
+	require.Len(t, uploadedKeySets[0].Keys, 2, "expected rotation to publish the previous and the new key")
+	require.Len(t, uploadedKeySets[1].Keys, 1, "expected finalization to publish only the new key")
+	assert.Equal(t, uploadedKeySets[0].Keys[1].KeyID, uploadedKeySets[1].Keys[0].KeyID, "expected finalization to keep the new key")
+
+	// A dry run saves the JSON web key set locally rather than uploading it
+	err = finalizeRotation(mockAWSClient, testNamePrefix, "", filepath.Join(targetDir, provisioning.PublicKeyFile), targetDir, true)
+	require.NoError(t, err, "unexpected error finalizing rotation with dry run")
+	assert.FileExists(t, filepath.Join(targetDir, oidcKeysFilename), "expected JSON web key set to be saved with dry run")
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+
+	"github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// stsTokenAudience is the audience of the service account tokens projected for pods by the pod identity webhook,
+// which the IAM Identity Provider must trust for STS to exchange the tokens
+const stsTokenAudience = "sts.amazonaws.com"
+
+// callerIdentityClient is the subset of the STS API through which the STS endpoint is verified
+type callerIdentityClient interface {
+	GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error)
+}
+
+// checkSTSEndpoint verifies the STS endpoint through which the pods of the cluster will exchange their service account
+// tokens with verifySTSEndpoint, using the credentials of the session s. The secret manifests set
+// sts_regional_endpoints = regional, so that pods use the regional STS endpoint of region.
+func checkSTSEndpoint(s *session.Session, client aws.Client, region, identityProviderARN string) error {
+	regionalEndpoint, err := endpoints.DefaultResolver().EndpointFor(sts.EndpointsID, region, endpoints.STSRegionalEndpointOption)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve the regional STS endpoint of region %s", region)
+	}
+	regionalClient := sts.New(s, awssdk.NewConfig().WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint))
+	return verifySTSEndpoint(regionalClient, client, identityProviderARN, regionalEndpoint.URL, s.ClientConfig(sts.EndpointsID).Endpoint)
+}
+
+// verifySTSEndpoint verifies that the regional STS endpoint, reached through stsClient, is reachable from the host
+// running ccoctl and active for the account, and that the IAM Identity Provider identified by identityProviderARN
+// trusts the audience of the service account tokens of pods. Otherwise pods would only fail to authenticate once the
+// cluster is installed. hostEndpoint is the STS endpoint ccoctl is configured for, eg. by AWS_STS_REGIONAL_ENDPOINTS,
+// a warning is logged when it is not the regional endpoint used by pods.
+func verifySTSEndpoint(stsClient callerIdentityClient, client aws.Client, identityProviderARN, regionalEndpoint, hostEndpoint string) error {
+	if _, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{}); err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == sts.ErrCodeRegionDisabledException {
+			return fmt.Errorf("the regional STS endpoint %s used by the pods of the cluster is not active for the account, "+
+				"activate it within the account settings of IAM before installing the cluster: %s", regionalEndpoint, aerr.Message())
+		}
+		return errors.Wrapf(err, "the regional STS endpoint %s used by the pods of the cluster is not reachable", regionalEndpoint)
+	}
+
+	output, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: awssdk.String(identityProviderARN),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get IAM Identity Provider %s", identityProviderARN)
+	}
+	trusted := false
+	for _, clientID := range output.ClientIDList {
+		if awssdk.StringValue(clientID) == stsTokenAudience {
+			trusted = true
+		}
+	}
+	if !trusted {
+		return fmt.Errorf("IAM Identity Provider %s does not trust the audience %s of the service account tokens of pods, "+
+			"the regional STS endpoint %s would reject them", identityProviderARN, stsTokenAudience, regionalEndpoint)
+	}
+
+	if hostEndpoint != regionalEndpoint {
+		provisioning.Warnf("ccoctl is configured for the STS endpoint %s while the pods of the cluster use the regional STS endpoint %s, "+
+			"set AWS_STS_REGIONAL_ENDPOINTS=regional to authenticate as the pods will", hostEndpoint, regionalEndpoint)
+	}
+	return nil
+}
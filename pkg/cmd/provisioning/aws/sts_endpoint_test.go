@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+)
+
+const testRegionalSTSEndpoint = "https://sts.us-east-2.amazonaws.com"
+
+type fakeCallerIdentityClient struct {
+	err error
+}
+
+func (c *fakeCallerIdentityClient) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &sts.GetCallerIdentityOutput{Account: awssdk.String("123456789012")}, nil
+}
+
+func TestVerifySTSEndpoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		stsErr        error
+		mockAWSClient func(mockCtrl *gomock.Controller) *mockaws.MockClient
+		expectError   string
+	}{
+		{
+			name: "Regional STS endpoint reachable and audience trusted",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProviderClientIDs(mockAWSClient, "openshift", stsTokenAudience)
+				return mockAWSClient
+			},
+		},
+		{
+			name:   "Regional STS endpoint not active for the account",
+			stsErr: awserr.New(sts.ErrCodeRegionDisabledException, "STS is not activated in this region", nil),
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			expectError: "is not active for the account",
+		},
+		{
+			name:   "Regional STS endpoint not reachable",
+			stsErr: fmt.Errorf("dial tcp: lookup sts.us-east-2.amazonaws.com: no such host"),
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			expectError: "is not reachable",
+		},
+		{
+			name: "IAM Identity Provider does not trust the audience of pods",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetOpenIDConnectProviderClientIDs(mockAWSClient, "openshift")
+				return mockAWSClient
+			},
+			expectError: "does not trust the audience",
+		},
+		{
+			name: "IAM Identity Provider not found",
+			mockAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockAWSClient.EXPECT().GetOpenIDConnectProvider(gomock.Any()).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "OpenIDConnect provider not found", nil))
+				return mockAWSClient
+			},
+			expectError: "failed to get IAM Identity Provider",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := verifySTSEndpoint(&fakeCallerIdentityClient{err: test.stsErr}, test.mockAWSClient(mockCtrl), testIdentityProviderARN, testRegionalSTSEndpoint, testRegionalSTSEndpoint)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func mockGetOpenIDConnectProviderClientIDs(mockAWSClient *mockaws.MockClient, clientIDs ...string) {
+	mockAWSClient.EXPECT().GetOpenIDConnectProvider(gomock.Any()).Return(
+		&iam.GetOpenIDConnectProviderOutput{
+			Url:          awssdk.String(testIdentityProviderURL),
+			ClientIDList: awssdk.StringSlice(clientIDs),
+		}, nil).Times(1)
+}
@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// resourceTag is a tag applied to the AWS resources created by ccoctl. The field names match those of the tags
+// within the JSON saved with --dry-run.
+type resourceTag struct {
+	Key   string
+	Value string
+}
+
+// clusterResourceTagKey returns the key of the tag identifying the OpenShift cluster with ID clusterID as the owner
+// of an AWS resource, which matches the tag applied by the OpenShift installer.
+func clusterResourceTagKey(clusterID string) string {
+	return fmt.Sprintf("%s/%s", clusterAWSResourceTagKeyPrefix, clusterID)
+}
+
+// clusterResourceTags returns the tag identifying the OpenShift cluster with ID clusterID as the owner of an AWS
+// resource, or no tags when clusterID is empty.
+func clusterResourceTags(clusterID string) []resourceTag {
+	if clusterID == "" {
+		return nil
+	}
+	return []resourceTag{{Key: clusterResourceTagKey(clusterID), Value: provisioning.ClusterResourceTagValue}}
+}
+
+// resourceTags returns the tags applied to the AWS resources created by ccoctl for name, which include the tag
+// identifying the OpenShift cluster with ID clusterID as the owner of the resources when clusterID is provided.
+func resourceTags(name, clusterID string) []resourceTag {
+	tags := []resourceTag{
+		{Key: fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, name), Value: ownedCcoctlAWSResourceTagValue},
+		{Key: nameTagKey, Value: name},
+	}
+	return append(tags, clusterResourceTags(clusterID)...)
+}
+
+// roleTags returns the tags applied to the IAM Role created by ccoctl for name for credReq, which include the tag
+// identifying the component of credReq so that the IAM Role may be matched to its component whatever its name
+func roleTags(name, clusterID string, credReq *credreqv1.CredentialsRequest) []resourceTag {
+	return append(resourceTags(name, clusterID), resourceTag{Key: componentTagKey, Value: provisioning.CredentialsRequestComponentTag(credReq)})
+}
+
+func iamTags(tags []resourceTag) []*iam.Tag {
+	iamTags := make([]*iam.Tag, 0, len(tags))
+	for _, tag := range tags {
+		iamTags = append(iamTags, &iam.Tag{Key: awssdk.String(tag.Key), Value: awssdk.String(tag.Value)})
+	}
+	return iamTags
+}
+
+func s3Tags(tags []resourceTag) []*s3.Tag {
+	s3Tags := make([]*s3.Tag, 0, len(tags))
+	for _, tag := range tags {
+		s3Tags = append(s3Tags, &s3.Tag{Key: awssdk.String(tag.Key), Value: awssdk.String(tag.Value)})
+	}
+	return s3Tags
+}
+
+func cloudFrontTags(tags []resourceTag) []*cloudfront.Tag {
+	cloudFrontTags := make([]*cloudfront.Tag, 0, len(tags))
+	for _, tag := range tags {
+		cloudFrontTags = append(cloudFrontTags, &cloudfront.Tag{Key: awssdk.String(tag.Key), Value: awssdk.String(tag.Value)})
+	}
+	return cloudFrontTags
+}
+
+// objectTagging returns tags in the query parameter form of the Tagging of S3 objects, eg. "key1=value1&key2=value2".
+// The keys and values of the tags applied by ccoctl only contain characters which need not be URL encoded.
+func objectTagging(tags []resourceTag) string {
+	pairs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", tag.Key, tag.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// hasClusterResourceTag returns true when clusterID is empty or when tags include the tag identifying the
+// OpenShift cluster with ID clusterID as the owner of the resource
+func hasClusterResourceTag(tags map[string]string, clusterID string) bool {
+	if clusterID == "" {
+		return true
+	}
+	return tags[clusterResourceTagKey(clusterID)] == provisioning.ClusterResourceTagValue
+}
+
+func iamTagMap(tags []*iam.Tag) map[string]string {
+	tagMap := map[string]string{}
+	for _, tag := range tags {
+		tagMap[awssdk.StringValue(tag.Key)] = awssdk.StringValue(tag.Value)
+	}
+	return tagMap
+}
+
+func s3TagMap(tags []*s3.Tag) map[string]string {
+	tagMap := map[string]string{}
+	for _, tag := range tags {
+		tagMap[awssdk.StringValue(tag.Key)] = awssdk.StringValue(tag.Value)
+	}
+	return tagMap
+}
+
+func cloudFrontTagMap(tags []*cloudfront.Tag) map[string]string {
+	tagMap := map[string]string{}
+	for _, tag := range tags {
+		tagMap[awssdk.StringValue(tag.Key)] = awssdk.StringValue(tag.Value)
+	}
+	return tagMap
+}
+
+// isThrottlingError returns true when err indicates that a call tagging AWS resources was throttled, in which case
+// the call is retried by provisioning.TagResources
+func isThrottlingError(err error) bool {
+	return request.IsErrorThrottle(err)
+}
@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTags(t *testing.T) {
+	tests := []struct {
+		name                  string
+		clusterID             string
+		expectedTags          []resourceTag
+		expectedObjectTagging string
+	}{
+		{
+			name: "Without cluster ID",
+			expectedTags: []resourceTag{
+				{Key: fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testNamePrefix), Value: ownedCcoctlAWSResourceTagValue},
+				{Key: nameTagKey, Value: testNamePrefix},
+			},
+			expectedObjectTagging: "openshift.io/cloud-credential-operator/test-cluster1=owned&Name=test-cluster1",
+		},
+		{
+			name:      "With cluster ID",
+			clusterID: testClusterID,
+			expectedTags: []resourceTag{
+				{Key: fmt.Sprintf("%s/%s", ccoctlAWSResourceTagKeyPrefix, testNamePrefix), Value: ownedCcoctlAWSResourceTagValue},
+				{Key: nameTagKey, Value: testNamePrefix},
+				{Key: "kubernetes.io/cluster/" + testClusterID, Value: "owned"},
+			},
+			expectedObjectTagging: "openshift.io/cloud-credential-operator/test-cluster1=owned&Name=test-cluster1&kubernetes.io/cluster/" + testClusterID + "=owned",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tags := resourceTags(testNamePrefix, test.clusterID)
+			assert.Equal(t, test.expectedTags, tags, "unexpected tags")
+			assert.Equal(t, test.expectedObjectTagging, objectTagging(tags), "unexpected S3 object tagging")
+			assert.True(t, hasClusterResourceTag(iamTagMap(iamTags(tags)), test.clusterID), "expected resource to be owned by the cluster")
+		})
+	}
+}
+
+func TestHasClusterResourceTag(t *testing.T) {
+	clusterTags := s3TagMap(s3Tags(resourceTags(testNamePrefix, testClusterID)))
+	otherClusterTags := s3TagMap(s3Tags(resourceTags(testNamePrefix, "othercluster")))
+	untaggedTags := s3TagMap(s3Tags(resourceTags(testNamePrefix, "")))
+
+	assert.True(t, hasClusterResourceTag(untaggedTags, ""), "expected every resource to match without a cluster ID")
+	assert.True(t, hasClusterResourceTag(clusterTags, testClusterID), "expected resource tagged with the cluster ID to match")
+	assert.False(t, hasClusterResourceTag(otherClusterTags, testClusterID), "expected resource of another cluster not to match")
+	assert.False(t, hasClusterResourceTag(untaggedTags, testClusterID), "expected resource without a cluster tag not to match")
+}
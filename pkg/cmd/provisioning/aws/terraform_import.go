@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// identityProviderStep is the inventory step recorded by create-all once the OIDC endpoint and the IAM Identity
+	// Provider have been created
+	identityProviderStep                       = "create-identity-provider"
+	s3BucketInventoryResourceType              = "S3Bucket"
+	identityProviderInventoryResourceType      = "IAMIdentityProvider"
+	terraformIAMRoleResourceType               = "aws_iam_role"
+	terraformIAMRolePolicyResourceType         = "aws_iam_role_policy"
+	terraformIAMPolicyResourceType             = "aws_iam_policy"
+	terraformIAMRolePolicyAttachmentType       = "aws_iam_role_policy_attachment"
+	terraformS3BucketResourceType              = "aws_s3_bucket"
+	terraformOpenIDConnectProviderResourceType = "aws_iam_openid_connect_provider"
+
+	// issuerURLInventorySetting is the inventory setting recording the issuer URL of the IAM Identity Provider, which
+	// is identified by its URL rather than by its name
+	issuerURLInventorySetting = "issuerURL"
+)
+
+// terraformImports returns the terraform imports of the AWS resources recorded within step. The policies recorded
+// within the step of an IAM Role are imported along with their attachment to the IAM Role.
+func terraformImports(step provisioning.InventoryStep) []provisioning.TerraformImport {
+	imports := []provisioning.TerraformImport{}
+	roleName := ""
+	for _, resource := range step.Resources {
+		switch resource.Type {
+		case iamRoleInventoryResourceType:
+			// The recorded name of the IAM Role is not shortened, the name of the IAM Role is the last part of its ARN
+			roleName = resource.ID[strings.LastIndex(resource.ID, "/")+1:]
+			imports = append(imports, provisioning.TerraformImport{
+				Address: provisioning.TerraformAddress(terraformIAMRoleResourceType, roleName),
+				ID:      roleName,
+			})
+		case inlinePolicyInventoryResourceType:
+			imports = append(imports, provisioning.TerraformImport{
+				Address: provisioning.TerraformAddress(terraformIAMRolePolicyResourceType, roleName),
+				ID:      roleName + ":" + resource.Name,
+			})
+		case managedPolicyInventoryResourceType:
+			imports = append(imports,
+				provisioning.TerraformImport{
+					Address: provisioning.TerraformAddress(terraformIAMPolicyResourceType, resource.Name),
+					ID:      resource.ID,
+				},
+				provisioning.TerraformImport{
+					Address: provisioning.TerraformAddress(terraformIAMRolePolicyAttachmentType, resource.Name),
+					ID:      roleName + "/" + resource.ID,
+				},
+			)
+		case s3BucketInventoryResourceType:
+			imports = append(imports, provisioning.TerraformImport{
+				Address: provisioning.TerraformAddress(terraformS3BucketResourceType, resource.Name),
+				ID:      resource.Name,
+			})
+		case identityProviderInventoryResourceType:
+			imports = append(imports, provisioning.TerraformImport{
+				Address: provisioning.TerraformAddress(terraformOpenIDConnectProviderResourceType, resource.Name),
+				ID:      resource.ID,
+			})
+		}
+	}
+	return imports
+}
+
+// identityProviderResources returns the inventory resources recording the S3 bucket serving the OIDC endpoint for name
+// and the IAM Identity Provider identityProviderARN, which is omitted when shared as it is not owned by name
+func identityProviderResources(name, identityProviderARN string, sharedIdentityProvider bool) []provisioning.InventoryResource {
+	resources := []provisioning.InventoryResource{{
+		Type: s3BucketInventoryResourceType,
+		Name: fmt.Sprintf("%s-oidc", name),
+	}}
+	if !sharedIdentityProvider {
+		resources = append(resources, provisioning.InventoryResource{
+			Type: identityProviderInventoryResourceType,
+			Name: name,
+			ID:   identityProviderARN,
+		})
+	}
+	return resources
+}
+
+// writeTerraformImports writes the terraform import commands of the AWS resources recorded within inventory
+func writeTerraformImports(inventory *provisioning.Inventory) error {
+	path, err := provisioning.WriteTerraformImports(inventory, terraformImports)
+	if err != nil {
+		return err
+	}
+	log.Printf("Saved terraform import commands at path %s", path)
+	return nil
+}
@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestTerraformImports(t *testing.T) {
+	tests := []struct {
+		name            string
+		step            provisioning.InventoryStep
+		expectedImports []provisioning.TerraformImport
+	}{
+		{
+			name: "identity provider",
+			step: provisioning.InventoryStep{
+				Name:      identityProviderStep,
+				Resources: identityProviderResources(testInfraName, testIdentityProviderARN, false),
+			},
+			expectedImports: []provisioning.TerraformImport{
+				{Address: "aws_s3_bucket." + testInfraName + "-oidc", ID: testInfraName + "-oidc"},
+				{Address: "aws_iam_openid_connect_provider." + testInfraName, ID: testIdentityProviderARN},
+			},
+		},
+		{
+			name: "shared identity provider not imported",
+			step: provisioning.InventoryStep{
+				Name:      identityProviderStep,
+				Resources: identityProviderResources(testInfraName, testIdentityProviderARN, true),
+			},
+			expectedImports: []provisioning.TerraformImport{
+				{Address: "aws_s3_bucket." + testInfraName + "-oidc", ID: testInfraName + "-oidc"},
+			},
+		},
+		{
+			name: "role with inline policy",
+			step: provisioning.InventoryStep{
+				Name: iamRoleStepPrefix + "namespace1/secret1",
+				Resources: []provisioning.InventoryResource{
+					{Type: iamRoleInventoryResourceType, Name: "role1-unshortened", ID: "arn:aws:iam::123456789012:role/role1"},
+					{Type: inlinePolicyInventoryResourceType, Name: "role1-policy"},
+				},
+			},
+			expectedImports: []provisioning.TerraformImport{
+				{Address: "aws_iam_role.role1", ID: "role1"},
+				{Address: "aws_iam_role_policy.role1", ID: "role1:role1-policy"},
+			},
+		},
+		{
+			name: "role with managed policy",
+			step: provisioning.InventoryStep{
+				Name: iamRoleStepPrefix + "namespace1/secret1",
+				Resources: []provisioning.InventoryResource{
+					{Type: iamRoleInventoryResourceType, Name: "role1", ID: "arn:aws:iam::123456789012:role/role1"},
+					{Type: managedPolicyInventoryResourceType, Name: "role1-policy", ID: "arn:aws:iam::123456789012:policy/role1-policy"},
+				},
+			},
+			expectedImports: []provisioning.TerraformImport{
+				{Address: "aws_iam_role.role1", ID: "role1"},
+				{Address: "aws_iam_policy.role1-policy", ID: "arn:aws:iam::123456789012:policy/role1-policy"},
+				{Address: "aws_iam_role_policy_attachment.role1-policy", ID: "role1/arn:aws:iam::123456789012:policy/role1-policy"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedImports, terraformImports(test.step))
+		})
+	}
+}
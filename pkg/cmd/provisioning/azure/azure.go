@@ -1,13 +1,63 @@
 package azure
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	azpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 )
 
+var (
+	// apiVersions pins the API versions requested of Azure resource providers by every azure subcommand, keyed by
+	// the namespace of the resource provider
+	apiVersions = map[string]string{}
+	// disableRPRegistration disables the automatic registration of unregistered resource providers by the SDK
+	disableRPRegistration bool
+	// loggedAPIVersions records the resource provider namespaces and API versions whose use has been logged
+	loggedAPIVersions sync.Map
+	// defaultRetryPolicy is the retry policy of Azure API calls unless overridden with --max-retries,
+	// --retry-base-delay and --retry-max-delay. Azure Resource Manager limits the writes of a principal within a
+	// subscription per hour and tells throttled callers when to retry with the Retry-After header, so a few more
+	// retries than by default in the SDK ride out throttling without waiting longer than a minute between retries.
+	defaultRetryPolicy = provisioning.RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  4 * time.Second,
+		MaxDelay:   60 * time.Second,
+	}
+)
+
+const (
+	// managedIdentityNameAnnotation is the annotation applied to secret manifests generated by ccoctl with
+	// --dry-run which records the name of the user-assigned managed identity that would have been created
+	managedIdentityNameAnnotation = "ccoctl.openshift.io/managed-identity-name"
+
+	// resourceManagerScope is the scope of the token requested to resolve the tenant of the credential
+	// with which ccoctl authenticates to Azure
+	resourceManagerScope = "https://management.azure.com/.default"
+)
+
 type azureOptions struct {
-	CredRequestDir     string
+	CredRequestDirs    []string
 	IssuerURL          string
 	Name               string
 	PublicKeyPath      string
@@ -15,10 +65,15 @@ type azureOptions struct {
 	StorageAccountName string
 	BlobContainerName  string
 	SubscriptionID     string
+	TenantID           string
 	OutputDir          string
 	DryRun             bool
 	EnableTechPreview  bool
 
+	// PreviousPublicKeyPath is the path of the public key currently used by the cluster when rotating the
+	// service account signing key with rotate-signing-key
+	PreviousPublicKeyPath string
+
 	// UserTags is the map of user provided tags to be applied to Azure resources created by ccoctl.
 	// For example:
 	// userTags := map[string]string{"openshift.io_cloud-credential-operator": "owned"}
@@ -37,6 +92,15 @@ type azureOptions struct {
 	// Reference: https://github.com/openshift/installer/blob/85138dd3c4e9c27c4bd4fbe3588af7712404347b/pkg/asset/installconfig/azure/validation.go#L558-L570
 	OIDCResourceGroupName string
 
+	// IdentityResourceGroupName is the name of the Azure resource group within which user-assigned managed identities
+	// are created and deleted, when they should not live within the OIDC resource group alongside the OIDC documents.
+	// Defaults to OIDCResourceGroupName.
+	IdentityResourceGroupName string
+
+	// CreateIdentityResourceGroup is a bool indicating that the resource group identified by IdentityResourceGroupName
+	// should be created when it does not exist, rather than required to pre-exist
+	CreateIdentityResourceGroup bool
+
 	// DNSZoneResourceGroupName is the name of the Azure resource group in which the OpenShift
 	// cluster's base domain DNS zone exists. The permissions granted to the managed identity created
 	// for the ingress operator will be scoped to the DNSZoneResourceGroupName.
@@ -45,6 +109,180 @@ type azureOptions struct {
 	// DeleteResourceGroup is a bool indicating that the OIDC resource group should be deleted when
 	// ccoctl azure delete is invoked with the --delete-oidc-resource-group flag
 	DeleteOIDCResourceGroup bool
+
+	// Force is a bool indicating that the OIDC resource group should be deleted by ccoctl azure delete
+	// --delete-oidc-resource-group even when the resource group does not carry CCO's "owned" tag.
+	// When resuming ccoctl azure create-all with --resume, Force indicates that previously completed steps
+	// which fail validation should be re-run rather than resulting in an error.
+	Force bool
+
+	// AllowInsecureSecretsDir is a bool indicating that secrets should be written to the manifests directory even if
+	// it is group or world writable
+	AllowInsecureSecretsDir bool
+
+	// OnlyMissing is a bool indicating that ccoctl azure create-managed-identities should only create the user-assigned
+	// managed identities of the CredentialsRequests which do not have a user-assigned managed identity yet
+	OnlyMissing bool
+
+	// CanonicalizeIssuerURL is a bool indicating that ccoctl azure create-managed-identities should canonicalize the
+	// --issuer-url with provisioning.CanonicalIssuerURL, eg. trimming trailing slashes, before federating the
+	// user-assigned managed identities with the issuer
+	CanonicalizeIssuerURL bool
+
+	// SkipStorageAccount is a bool indicating that ccoctl azure delete should not delete the storage account
+	SkipStorageAccount bool
+
+	// SkipManagedIdentities is a bool indicating that ccoctl azure delete should not delete user-assigned managed identities
+	SkipManagedIdentities bool
+
+	// ComponentFilter identifies the single component whose user-assigned managed identity ccoctl azure delete
+	// should delete, either by the "<secret namespace>/<secret name>" of the component's CredentialsRequest or by
+	// a prefix of "<secret namespace>-<secret name>"
+	ComponentFilter string
+
+	// IdentityIDs are the resource IDs of the pre-existing user-assigned managed identities which ccoctl azure
+	// import-identities imports into the ownership of ccoctl
+	IdentityIDs []string
+
+	// IssuerURLPathPrefix is the path within the blob container beneath which the OIDC documents are
+	// uploaded. The issuer URL and the jwks_uri within the OIDC discovery document incorporate the prefix.
+	IssuerURLPathPrefix string
+
+	// PrintIssuerURL is a bool indicating that the issuer URL should be printed to stdout, and nothing else, once
+	// the OIDC issuer has been created. Log output is always written to stderr.
+	PrintIssuerURL bool
+
+	// Resume is a bool indicating that ccoctl azure create-all should skip steps recorded as completed within
+	// the inventory saved to the output directory by a previous interrupted run
+	Resume bool
+
+	// ClusterID is the ID of the OpenShift cluster with which Azure resources created by ccoctl are tagged, in
+	// addition to CCO's "owned" tag. When provided to ccoctl azure delete only resources carrying the tag are deleted.
+	ClusterID string
+
+	// SkipQuotaCheck is a bool indicating that ccoctl should not check that the limit of role assignments per
+	// subscription allows assigning the roles of the user-assigned managed identities before creating them
+	SkipQuotaCheck bool
+
+	// SkipDeprecatedPermissionsCheck is a bool indicating that ccoctl should not warn about the roles requested by the
+	// CredentialsRequests which are known to be deprecated by Azure
+	SkipDeprecatedPermissionsCheck bool
+
+	// SkipWritePermissionsCheck is a bool indicating that ccoctl should not probe whether the identity is permitted to
+	// make changes within the subscription before creating resources
+	SkipWritePermissionsCheck bool
+
+	// FailOnInsufficientPermissions is a bool indicating that ccoctl azure delete should not delete any resource when
+	// the identity is found to lack permissions required to delete them, or they could not be checked
+	FailOnInsufficientPermissions bool
+
+	// SkipCostEstimate is a bool indicating that ccoctl azure create-all should not log an estimate of the monthly
+	// cost of the resources it creates
+	SkipCostEstimate bool
+
+	// OwnedTagValue is the value of CCO's "owned" tag which is applied to the Azure resources created by ccoctl and
+	// which identifies the resources that ccoctl azure delete and prune-federated-credentials may operate on
+	OwnedTagValue string
+
+	// OldOwnedTagKeyPrefix and OldOwnedTagValue identify the "owned" tag which ccoctl azure migrate-tags replaces
+	// with the "owned" tag identified by OwnedTagValue
+	OldOwnedTagKeyPrefix string
+	OldOwnedTagValue     string
+
+	// OutputArchive is the path of the gzipped tar archive into which ccoctl azure create-all packages the generated
+	// manifests, the tls directory and the inventory, when provided
+	OutputArchive string
+
+	// OutputImport is a bool indicating that ccoctl azure create-all should write a terraform import command for
+	// every Azure resource recorded within the inventory to the output directory
+	OutputImport bool
+
+	// VerifyIssuerReachable is a bool indicating that the OIDC documents uploaded to the storage account should be
+	// fetched from the issuer URL over the public network to ensure that they are publicly served
+	VerifyIssuerReachable bool
+
+	// DiscoveryDocument are the extra audiences and claims appended to the OIDC discovery document uploaded to the
+	// storage account
+	DiscoveryDocument provisioning.DiscoveryDocumentExtensions
+
+	// OlderThan restricts ccoctl azure delete to resources which were created more than OlderThan ago, when non-zero
+	OlderThan time.Duration
+
+	// TagSelector restricts ccoctl azure delete to the resources owned by Name whose tags match the selector, when
+	// not empty
+	TagSelector provisioning.TagSelector
+
+	// SubscriptionIDs are the Azure subscriptions within which ccoctl azure delete deletes resources, in turn
+	SubscriptionIDs []string
+
+	// ContinueOnError is a bool indicating that ccoctl azure delete should continue deleting resources within the
+	// remaining subscriptions after deletion within a subscription fails
+	ContinueOnError bool
+
+	// KeyVaultName is the name of the Azure key vault within which ccoctl azure create-all stores the signing private
+	// key, rather than leaving it within the output directory, and from which ccoctl azure delete removes it
+	KeyVaultName string
+
+	// KeyVaultSecretName is the name of the secret within the key vault identified by KeyVaultName in which the
+	// signing private key is stored. Defaults to "<name>-service-account-signer".
+	KeyVaultSecretName string
+
+	// DeleteKeyVaultSecret is a bool indicating that ccoctl azure delete should delete the secret in which the
+	// signing private key was stored within the key vault identified by KeyVaultName
+	DeleteKeyVaultSecret bool
+
+	// ConfirmThreshold is the number of resources discovered for deletion by ccoctl azure delete and
+	// reconcile-delete beyond which deletion must be confirmed
+	ConfirmThreshold int
+
+	// Yes is a bool indicating that ccoctl azure delete and reconcile-delete should delete the resources discovered
+	// for deletion without asking for confirmation, however many there are
+	Yes bool
+
+	// NameFromKubeconfig is a bool indicating that ccoctl azure delete should delete the resources named after the
+	// infrastructure name of the cluster of the current context of the kubeconfig rather than Name
+	NameFromKubeconfig bool
+
+	// ConfirmNameFromKubeconfig is a bool confirming that ccoctl azure delete should delete the resources named after
+	// the infrastructure name discovered with NameFromKubeconfig
+	ConfirmNameFromKubeconfig bool
+
+	// VerifyAfterDelete is a bool indicating that ccoctl azure delete should fail when resources it would delete
+	// remain once deletion completes
+	VerifyAfterDelete bool
+
+	// TeardownPlanPath is the path of the teardown plan listing the resources which ccoctl azure delete should delete,
+	// in the order they are listed, rather than its default deletion phases
+	TeardownPlanPath string
+
+	// TeardownPlan is the teardown plan read from TeardownPlanPath
+	TeardownPlan *provisioning.TeardownPlan
+}
+
+const (
+	// blobContainerNameFlag is the flag naming the blob container to which the OIDC documents are uploaded
+	blobContainerNameFlag = "blob-container-name"
+	// oidcContainerNameFlag is accepted as an alias of blobContainerNameFlag
+	oidcContainerNameFlag = "oidc-container-name"
+	// blobContainerNameFlagUsage describes the naming rules and alias of blobContainerNameFlag
+	blobContainerNameFlagUsage = "Azure blob container names must be between 3 and 63 characters in length and may contain numbers, lowercase letters and hyphens only. " +
+		"May also be provided as --" + oidcContainerNameFlag + "."
+
+	// identityResourceGroupNameFlagUsage describes the --identity-resource-group-name flag of the commands creating
+	// user-assigned managed identities
+	identityResourceGroupNameFlagUsage = "The existing Azure resource group in which to create user-assigned managed identities, separately from the OIDC resource group which keeps the OIDC documents. " +
+		"The resource group may not be the installation resource group. Defaults to the OIDC resource group identified by the --oidc-resource-group-name parameter"
+	// createIdentityResourceGroupFlagUsage describes the --create-identity-resource-group flag of the commands
+	// creating user-assigned managed identities
+	createIdentityResourceGroupFlagUsage = "Create the resource group identified by the --identity-resource-group-name parameter, tagged as owned by ccoctl, when it does not exist rather than requiring it to pre-exist"
+)
+
+// normalizeOIDCContainerNameFlag is a flag normalization function treating --oidc-container-name as --blob-container-name
+func normalizeOIDCContainerNameFlag(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == oidcContainerNameFlag {
+		name = blobContainerNameFlag
+	}
+	return pflag.NormalizedName(name)
 }
 
 // NewAzureCmd implements the "azure" subcommand for credentials provisioning
@@ -60,6 +298,361 @@ func NewAzureCmd() *cobra.Command {
 	createCmd.AddCommand(NewCreateManagedIdentitiesCmd())
 	createCmd.AddCommand(NewCreateAllCmd())
 	createCmd.AddCommand(NewDeleteCmd())
+	createCmd.AddCommand(NewPruneFederatedCredentialsCmd())
+	createCmd.AddCommand(NewMigrateTagsCmd())
+	createCmd.AddCommand(NewImportIdentitiesCmd())
+	createCmd.AddCommand(NewReconcileDeleteCmd())
+	createCmd.AddCommand(NewRotateSigningKeyCmd())
+	createCmd.AddCommand(NewFinalizeRotationCmd())
+
+	createCmd.PersistentFlags().StringToStringVar(&apiVersions, "api-versions", map[string]string{},
+		fmt.Sprintf("API versions to request of Azure resource providers instead of the SDK's defaults, keyed by resource provider namespace, eg. when a sovereign cloud lags the API versions of the public cloud. Multiple API versions may be specified comma-separated for example: --api-versions %s=2022-09-01,%s=2023-01-31. Supported namespaces are %s",
+			azureclients.StorageNamespace, azureclients.ManagedIdentityNamespace, strings.Join(azureclients.APIVersionNamespaces, ", ")))
+	createCmd.PersistentFlags().BoolVar(&disableRPRegistration, "disable-rp-registration", false, "Disable the automatic registration of resource providers which are not registered within the subscription, eg. when the identity running ccoctl may not register resource providers")
 
 	return createCmd
 }
+
+// newAzureCredential returns the credential with which ccoctl authenticates to Azure. Tokens are issued for
+// tenantID when it is provided, otherwise the tenant is inferred from the environment.
+func newAzureCredential(ctx context.Context, tenantID string) (cred azcore.TokenCredential, err error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.ResolveCredential", provisioning.ProviderAttribute.String("azure"))
+	defer func() { provisioning.EndSpan(span, err) }()
+
+	if err := validateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	cred, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+		ClientOptions: newClientOptions(),
+		TenantID:      tenantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logResolvedTenant(ctx, cred)
+	provisioning.RegisterAuditIdentity("azure", func() string {
+		identity := resolvedIdentity(ctx, cred)
+		if clientID := identity["principalClientID"]; clientID != "" {
+			return clientID
+		}
+		return identity["principalObjectID"]
+	})
+	return cred, nil
+}
+
+// newAzureClientWrapper returns the clients with which ccoctl calls the Azure APIs of subscriptionID, requesting the
+// API versions pinned with --api-versions
+func newAzureClientWrapper(subscriptionID string, cred azcore.TokenCredential) (*azureclients.AzureClientWrapper, error) {
+	options := &policy.ClientOptions{
+		ClientOptions:         newClientOptions(),
+		DisableRPRegistration: disableRPRegistration,
+	}
+	return azureclients.NewAzureClientWrapperWithAPIVersions(subscriptionID, cred, options, apiVersions, false)
+}
+
+// newClientOptions returns the options of the clients with which ccoctl calls Azure APIs. Requests identify ccoctl
+// in their User-Agent, are retried following the effective retry policy and are sent through an HTTP client
+// recording a span for every request when tracing is enabled.
+func newClientOptions() azpolicy.ClientOptions {
+	options := azpolicy.ClientOptions{
+		PerCallPolicies: []azpolicy.Policy{userAgentPolicy{}, apiVersionLoggingPolicy{}},
+		Retry:           newRetryOptions(provisioning.EffectiveRetryPolicy(defaultRetryPolicy)),
+	}
+	if client := provisioning.NewTracingHTTPClient(); client != nil {
+		options.Transport = client
+	}
+	// Every attempt of a request is dumped, after the policies which authenticate it
+	if provisioning.CloudRequestDumpEnabled() {
+		options.PerRetryPolicies = []azpolicy.Policy{cloudRequestDumpPolicy{}}
+	}
+	return options
+}
+
+// cloudRequestDumpPolicy writes every request, and its response, to the file of --dump-cloud-requests
+type cloudRequestDumpPolicy struct{}
+
+func (cloudRequestDumpPolicy) Do(req *azpolicy.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body() != nil {
+		body, _ = io.ReadAll(req.Body())
+		if err := req.RewindBody(); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	resp, err := req.Next()
+	provisioning.DumpHTTPRequest("azure", req.Raw(), body, resp, err, time.Since(start))
+	return resp, err
+}
+
+// newRetryOptions returns the retry options of the Azure clients following retryPolicy. The delay requested by the
+// Retry-After header of a throttled response takes precedence over the delays of retryPolicy.
+func newRetryOptions(retryPolicy provisioning.RetryPolicy) azpolicy.RetryOptions {
+	maxRetries := int32(retryPolicy.MaxRetries)
+	if maxRetries == 0 {
+		// The SDK retries three times when MaxRetries is zero, retries are only disabled by a negative value
+		maxRetries = -1
+	}
+	return azpolicy.RetryOptions{
+		MaxRetries:    maxRetries,
+		RetryDelay:    retryPolicy.BaseDelay,
+		MaxRetryDelay: retryPolicy.MaxDelay,
+	}
+}
+
+// userAgentPolicy prefixes the User-Agent of every request with the User-Agent of ccoctl. The SDK's own telemetry
+// option is not used since it truncates the application ID to 24 characters.
+type userAgentPolicy struct{}
+
+func (userAgentPolicy) Do(req *azpolicy.Request) (*http.Response, error) {
+	userAgent := provisioning.UserAgent()
+	if sdkUserAgent := req.Raw().Header.Get("User-Agent"); sdkUserAgent != "" {
+		userAgent = fmt.Sprintf("%s %s", userAgent, sdkUserAgent)
+	}
+	req.Raw().Header.Set("User-Agent", userAgent)
+	return req.Next()
+}
+
+// apiVersionLoggingPolicy logs the API version requested of each resource provider as a debug message, once for every
+// resource provider and API version, so that the effective API versions can be told apart from the SDK's defaults
+type apiVersionLoggingPolicy struct{}
+
+func (apiVersionLoggingPolicy) Do(req *azpolicy.Request) (*http.Response, error) {
+	if version := req.Raw().URL.Query().Get("api-version"); version != "" {
+		namespace := resourceProviderNamespace(req.Raw().URL.Path)
+		if _, logged := loggedAPIVersions.LoadOrStore(namespace+"@"+version, true); !logged {
+			provisioning.Debugf("Requesting API version %s of %s", version, namespace)
+		}
+	}
+	return req.Next()
+}
+
+// resourceProviderNamespace returns the namespace of the resource provider called by a request of path, the
+// namespace following the last providers segment of the path or Microsoft.Resources for resource groups and
+// subscriptions which are not managed by a namespaced resource provider
+func resourceProviderNamespace(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			return segments[i+1]
+		}
+	}
+	return azureclients.ResourcesNamespace
+}
+
+// validateTenantID validates that tenantID, when provided, is a tenant ID of the form
+// xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func validateTenantID(tenantID string) error {
+	if tenantID == "" {
+		return nil
+	}
+	if _, err := uuid.Parse(tenantID); err != nil || len(tenantID) != 36 {
+		return fmt.Errorf("invalid --tenant-id %q, expected a tenant ID of the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", tenantID)
+	}
+	return nil
+}
+
+// clusterResourceTagKey returns the key of the tag identifying the OpenShift cluster with ID clusterID as the owner of
+// an Azure resource, which matches the tag applied by the OpenShift installer.
+func clusterResourceTagKey(clusterID string) string {
+	return fmt.Sprintf("%s.%s", clusterAzureResourceTagKeyPrefix, clusterID)
+}
+
+// addClusterResourceTag validates the cluster ID within opts and, when provided, adds the tag identifying the
+// cluster as the owner of the Azure resources created by ccoctl to the user tags within opts.
+func addClusterResourceTag(opts *azureOptions) error {
+	if err := provisioning.ValidateClusterID(opts.ClusterID); err != nil {
+		return err
+	}
+	if opts.ClusterID == "" {
+		return nil
+	}
+	if opts.UserTags == nil {
+		opts.UserTags = map[string]string{}
+	}
+	opts.UserTags[clusterResourceTagKey(opts.ClusterID)] = provisioning.ClusterResourceTagValue
+	return nil
+}
+
+// hasClusterResourceTag returns true when clusterID is empty or when tags include the tag identifying the
+// OpenShift cluster with ID clusterID as the owner of the resource
+func hasClusterResourceTag(tags map[string]*string, clusterID string) bool {
+	if clusterID == "" {
+		return true
+	}
+	value, found := tags[clusterResourceTagKey(clusterID)]
+	return found && value != nil && *value == provisioning.ClusterResourceTagValue
+}
+
+// ownedResourceTagKey returns the key of CCO's "owned" tag applied to the Azure resources created by ccoctl for name
+func ownedResourceTagKey(name string) string {
+	return fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, name)
+}
+
+// hasOwnedResourceTag returns true when tags include CCO's "owned" tag for name with the value ownedTagValue
+func hasOwnedResourceTag(tags map[string]*string, name, ownedTagValue string) bool {
+	value, found := tags[ownedResourceTagKey(name)]
+	return found && value != nil && *value == ownedTagValue
+}
+
+// validateOwnedTagValue validates that the value of CCO's "owned" tag provided with --owned-tag-value is a valid
+// Azure tag value. Tag values are limited to 256 characters and, though Azure allows it, an empty value is refused
+// since it would not distinguish the resources created by ccoctl.
+func validateOwnedTagValue(ownedTagValue string) error {
+	if ownedTagValue == "" {
+		return errors.New("--owned-tag-value must not be empty")
+	}
+	if len(ownedTagValue) > maxAzureTagValueLength {
+		return fmt.Errorf("invalid --owned-tag-value %q, Azure tag values must not be longer than %d characters", ownedTagValue, maxAzureTagValueLength)
+	}
+	return nil
+}
+
+// addCreationTimestampTag adds the tag recording the current time as the creation time of a resource to tags
+func addCreationTimestampTag(tags map[string]*string) {
+	tags[creationTimestampAzureResourceTagKey] = to.Ptr(time.Now().UTC().Format(time.RFC3339))
+}
+
+// resourceCreationTime returns the time at which a resource was created, as reported by Azure within createdAt or,
+// when Azure does not report it, as recorded by the creation timestamp tag within tags. False is returned when the
+// creation time of the resource is unknown.
+func resourceCreationTime(createdAt *time.Time, tags map[string]*string) (time.Time, bool) {
+	if createdAt != nil && !createdAt.IsZero() {
+		return *createdAt, true
+	}
+	value, found := tags[creationTimestampAzureResourceTagKey]
+	if !found || value == nil {
+		return time.Time{}, false
+	}
+	creationTime, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return creationTime, true
+}
+
+// isOlderThan returns true when olderThan is zero or when the resource identified by resourceDescription, eg.
+// "resource group example-oidc", was created more than olderThan ago. Resources which are too recent, or whose
+// creation time is unknown, are logged as skipped.
+func isOlderThan(resourceDescription string, createdAt *time.Time, tags map[string]*string, olderThan time.Duration) bool {
+	if olderThan == 0 {
+		return true
+	}
+	creationTime, found := resourceCreationTime(createdAt, tags)
+	if !found {
+		provisioning.Warnf("Skipping %s whose creation time is unknown, the %s tag is only applied to resources created by ccoctl",
+			resourceDescription, creationTimestampAzureResourceTagKey)
+		return false
+	}
+	if age := time.Since(creationTime); age <= olderThan {
+		log.Printf("Skipping %s created %s ago which is not older than %s", resourceDescription, age.Round(time.Second), olderThan)
+		return false
+	}
+	return true
+}
+
+// matchesTagSelector returns true when the tags of the resource identified by resourceDescription, eg. "resource
+// group example-oidc", match selector. Resources which do not match are logged as skipped.
+func matchesTagSelector(resourceDescription string, tags map[string]*string, selector provisioning.TagSelector) bool {
+	if selector.Empty() {
+		return true
+	}
+	if !selector.Matches(tagValues(tags)) {
+		log.Printf("Skipping %s whose tags do not match --tag-selector %s", resourceDescription, selector.String())
+		return false
+	}
+	return true
+}
+
+// tagValues returns the values of the Azure resource tags which are set
+func tagValues(tags map[string]*string) map[string]string {
+	values := make(map[string]string, len(tags))
+	for key, value := range tags {
+		if value != nil {
+			values[key] = *value
+		}
+	}
+	return values
+}
+
+// logResolvedTenant logs the tenant for which the credential is issued tokens so that it can be confirmed
+// that resources are managed within the intended tenant
+func logResolvedTenant(ctx context.Context, cred azcore.TokenCredential) {
+	token, err := cred.GetToken(ctx, azpolicy.TokenRequestOptions{Scopes: []string{resourceManagerScope}})
+	if err != nil {
+		provisioning.Warnf("Unable to resolve Azure tenant: %s", err)
+		return
+	}
+	tenantID, err := tenantIDFromToken(token.Token)
+	if err != nil {
+		provisioning.Warnf("Unable to resolve Azure tenant: %s", err)
+		return
+	}
+	log.Printf("Using Azure tenant %s", tenantID)
+}
+
+// accessTokenClaims are the claims of an Azure AD access token identifying the identity to which it was issued
+type accessTokenClaims struct {
+	TenantID string `json:"tid"`
+	ObjectID string `json:"oid"`
+	AppID    string `json:"appid"`
+}
+
+// tenantIDFromToken returns the tenant ID from the "tid" claim of an Azure AD access token
+func tenantIDFromToken(token string) (string, error) {
+	claims, err := claimsFromToken(token)
+	if err != nil {
+		return "", err
+	}
+	if claims.TenantID == "" {
+		return "", errors.New("access token has no tenant ID claim")
+	}
+	return claims.TenantID, nil
+}
+
+// claimsFromToken returns the claims identifying the identity to which an Azure AD access token was issued
+func claimsFromToken(token string) (accessTokenClaims, error) {
+	claims := accessTokenClaims{}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims, errors.Wrap(err, "failed to decode access token claims")
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, errors.Wrap(err, "failed to unmarshal access token claims")
+	}
+	return claims, nil
+}
+
+// resolvedIdentity returns the identity with which Azure API calls are made using cred, which is written by
+// --dump-config. No identity is returned when it can't be resolved.
+func resolvedIdentity(ctx context.Context, cred azcore.TokenCredential) map[string]string {
+	token, err := cred.GetToken(ctx, azpolicy.TokenRequestOptions{Scopes: []string{resourceManagerScope}})
+	if err != nil {
+		provisioning.Warnf("Failed to resolve the Azure identity: %s", err)
+		return map[string]string{}
+	}
+	claims, err := claimsFromToken(token.Token)
+	if err != nil {
+		provisioning.Warnf("Failed to resolve the Azure identity: %s", err)
+		return map[string]string{}
+	}
+	resolved := map[string]string{"tenantID": claims.TenantID, "principalObjectID": claims.ObjectID}
+	if claims.AppID != "" {
+		resolved["principalClientID"] = claims.AppID
+	}
+	return resolved
+}
+
+// printIssuerURL prints only the issuerURL to stdout when --print-issuer-url was provided
+func printIssuerURL(enabled bool, issuerURL string) {
+	if !enabled {
+		return
+	}
+	if err := provisioning.PrintIssuerURL(os.Stdout, issuerURL); err != nil {
+		log.Fatal(err)
+	}
+}
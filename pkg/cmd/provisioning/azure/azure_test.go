@@ -0,0 +1,263 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestValidateTenantID(t *testing.T) {
+	tests := []struct {
+		name        string
+		tenantID    string
+		expectError bool
+	}{
+		{
+			name: "No tenant ID",
+		},
+		{
+			name:     "Valid tenant ID",
+			tenantID: "72f988bf-86f1-41af-91ab-2d7cd011db47",
+		},
+		{
+			name:        "Tenant ID which is not a GUID",
+			tenantID:    "contoso.onmicrosoft.com",
+			expectError: true,
+		},
+		{
+			name:        "Tenant ID enclosed in braces",
+			tenantID:    "{72f988bf-86f1-41af-91ab-2d7cd011db47}",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateTenantID(test.tenantID)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidateOwnedTagValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		ownedTagValue string
+		expectError   bool
+	}{
+		{
+			name:          "Default owned tag value",
+			ownedTagValue: ownedAzureResourceTagValue,
+		},
+		{
+			name:          "Custom owned tag value",
+			ownedTagValue: "ccoctl-managed",
+		},
+		{
+			name:        "Empty owned tag value",
+			expectError: true,
+		},
+		{
+			name:          "Owned tag value longer than allowed by Azure",
+			ownedTagValue: strings.Repeat("a", maxAzureTagValueLength+1),
+			expectError:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateOwnedTagValue(test.ownedTagValue)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestAddClusterResourceTag(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         azureOptions
+		expectedTags map[string]string
+		expectError  bool
+	}{
+		{
+			name: "No cluster ID",
+			opts: azureOptions{UserTags: map[string]string{"testtagname0": "testtagvalue0"}},
+			expectedTags: map[string]string{
+				"testtagname0": "testtagvalue0",
+			},
+		},
+		{
+			name: "Cluster tag added to user tags",
+			opts: azureOptions{ClusterID: "mycluster-x7k2p", UserTags: map[string]string{"testtagname0": "testtagvalue0"}},
+			expectedTags: map[string]string{
+				"testtagname0":                          "testtagvalue0",
+				"kubernetes.io_cluster.mycluster-x7k2p": "owned",
+			},
+		},
+		{
+			name: "Cluster tag added without user tags",
+			opts: azureOptions{ClusterID: "mycluster-x7k2p"},
+			expectedTags: map[string]string{
+				"kubernetes.io_cluster.mycluster-x7k2p": "owned",
+			},
+		},
+		{
+			name:        "Invalid cluster ID",
+			opts:        azureOptions{ClusterID: "my/cluster"},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := addClusterResourceTag(&test.opts)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				require.Equal(t, test.expectedTags, test.opts.UserTags)
+			}
+		})
+	}
+}
+
+func TestTenantIDFromToken(t *testing.T) {
+	encodeClaims := func(claims string) string {
+		return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+	}
+	tests := []struct {
+		name             string
+		token            string
+		expectedTenantID string
+		expectError      bool
+	}{
+		{
+			name:             "Token with tenant ID claim",
+			token:            encodeClaims(`{"aud":"https://management.azure.com","tid":"72f988bf-86f1-41af-91ab-2d7cd011db47"}`),
+			expectedTenantID: "72f988bf-86f1-41af-91ab-2d7cd011db47",
+		},
+		{
+			name:        "Token without tenant ID claim",
+			token:       encodeClaims(`{"aud":"https://management.azure.com"}`),
+			expectError: true,
+		},
+		{
+			name:        "Token which is not a JWT",
+			token:       "opaque-token",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tenantID, err := tenantIDFromToken(test.token)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				require.Equal(t, test.expectedTenantID, tenantID)
+			}
+		})
+	}
+}
+
+func TestClientOptionsUserAgent(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	options := newClientOptions()
+	pipeline := runtime.NewPipeline("ccoctl", "v1", runtime.PipelineOptions{}, &options)
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, server.URL)
+	require.NoError(t, err, "failed to create request")
+	_, err = pipeline.Do(req)
+	require.NoError(t, err, "unexpected error sending request")
+
+	assert.True(t, strings.HasPrefix(userAgent, provisioning.UserAgent()+" azsdk-go-ccoctl/v1 "), "expected User-Agent %q to start with the User-Agent of ccoctl followed by the SDK's", userAgent)
+}
+
+func TestNewRetryOptions(t *testing.T) {
+	options := newRetryOptions(provisioning.RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second})
+	assert.Equal(t, int32(2), options.MaxRetries, "unexpected maximum number of retries")
+	assert.Equal(t, time.Second, options.RetryDelay, "unexpected retry delay")
+	assert.Equal(t, 10*time.Second, options.MaxRetryDelay, "unexpected maximum retry delay")
+
+	options = newRetryOptions(provisioning.RetryPolicy{MaxRetries: 0, BaseDelay: time.Second, MaxDelay: 10 * time.Second})
+	assert.Negative(t, options.MaxRetries, "expected retries to be disabled, the SDK retries three times when zero")
+}
+
+func TestResourceProviderNamespace(t *testing.T) {
+	tests := []struct {
+		name              string
+		path              string
+		expectedNamespace string
+	}{
+		{
+			name:              "resource group",
+			path:              "/subscriptions/subscription/resourcegroups/resourcegroup",
+			expectedNamespace: "Microsoft.Resources",
+		},
+		{
+			name:              "blob container",
+			path:              "/subscriptions/subscription/resourceGroups/resourcegroup/providers/Microsoft.Storage/storageAccounts/account/blobServices/default/containers/container",
+			expectedNamespace: "Microsoft.Storage",
+		},
+		{
+			name:              "role assignment scoped to a resource group",
+			path:              "/subscriptions/subscription/resourceGroups/resourcegroup/providers/Microsoft.Authorization/roleAssignments/assignment",
+			expectedNamespace: "Microsoft.Authorization",
+		},
+		{
+			name:              "federated identity credential",
+			path:              "/subscriptions/subscription/resourceGroups/resourcegroup/providers/Microsoft.ManagedIdentity/userAssignedIdentities/identity/federatedIdentityCredentials/credential",
+			expectedNamespace: "Microsoft.ManagedIdentity",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedNamespace, resourceProviderNamespace(test.path))
+		})
+	}
+}
+
+func TestClientOptionsAPIVersionLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	provisioning.InitLogging(true, true)
+	defer provisioning.InitLogging(true, false)
+
+	options := newClientOptions()
+	pipeline := runtime.NewPipeline("ccoctl", "v1", runtime.PipelineOptions{}, &options)
+	for i := 0; i < 2; i++ {
+		req, err := runtime.NewRequest(context.Background(), http.MethodGet, server.URL+"/subscriptions/subscription/providers/Microsoft.Storage/storageAccounts?api-version=1999-01-01")
+		require.NoError(t, err, "failed to create request")
+		_, err = pipeline.Do(req)
+		require.NoError(t, err, "unexpected error sending request")
+	}
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "Requesting API version 1999-01-01 of Microsoft.Storage"), "expected the API version of the resource provider to be logged once")
+}
@@ -0,0 +1,43 @@
+package azure
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// createAllResourceCounts returns the number of resources of each type created by ccoctl azure create-all with opts
+// for the CredentialsRequests within opts.CredRequestDirs, whose resource group names must have been resolved.
+// Resources which already exist, eg. the resource groups, are counted.
+func createAllResourceCounts(opts azureOptions) (map[string]int, error) {
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(opts.CredRequestDirs, opts.EnableTechPreview)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return nil, err
+	}
+	resourceGroups := sets.NewString(opts.OIDCResourceGroupName, opts.IdentityResourceGroupName, opts.InstallationResourceGroupName)
+	counts := map[string]int{
+		"ResourceGroup":               resourceGroups.Len(),
+		"StorageAccount":              1,
+		"BlobContainer":               1,
+		"UserAssignedManagedIdentity": len(credentialsRequests),
+	}
+	for _, credentialsRequest := range credentialsRequests {
+		crProviderSpec := &credreqv1.AzureProviderSpec{}
+		if credentialsRequest.Spec.ProviderSpec != nil {
+			if err := codec.DecodeProviderSpec(credentialsRequest.Spec.ProviderSpec, crProviderSpec); err != nil {
+				return nil, fmt.Errorf("error decoding provider spec from CredentialsRequest: %w", err)
+			}
+		}
+		counts["FederatedIdentityCredential"] += len(credentialsRequest.Spec.ServiceAccountNames)
+		counts["RoleAssignment"] += len(crProviderSpec.RoleBindings) * len(scopingResourceGroupNamesFor(credentialsRequest, opts.InstallationResourceGroupName, opts.DNSZoneResourceGroupName))
+	}
+	return counts, nil
+}
@@ -0,0 +1,32 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAllResourceCounts(t *testing.T) {
+	credReqDir := t.TempDir()
+	require.NoError(t, testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false))
+	require.NoError(t, testCredentialsRequest(t, "secondcredreq", "namespace2", "secretName2", credReqDir, false))
+
+	opts := azureOptions{
+		CredRequestDirs:               []string{credReqDir},
+		OIDCResourceGroupName:         "test-oidc",
+		IdentityResourceGroupName:     "test-oidc",
+		InstallationResourceGroupName: "test",
+		DNSZoneResourceGroupName:      "test-dns",
+	}
+	counts, err := createAllResourceCounts(opts)
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string]int{
+		"ResourceGroup":               2,
+		"StorageAccount":              1,
+		"BlobContainer":               1,
+		"UserAssignedManagedIdentity": 2,
+		"FederatedIdentityCredential": 4,
+		"RoleAssignment":              2,
+	}, counts)
+}
@@ -1,15 +1,19 @@
 package azure
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -19,22 +23,212 @@ var (
 	CreateAllOpts = azureOptions{}
 )
 
+const (
+	// oidcIssuerStep is the inventory step recorded once the OIDC issuer has been created
+	oidcIssuerStep = "create-oidc-issuer"
+	// installationResourceGroupStep is the inventory step recorded once the installation resource group has been ensured
+	installationResourceGroupStep = "ensure-installation-resource-group"
+	// identityResourceGroupStep is the inventory step recorded once the identity resource group, when separate from
+	// the OIDC resource group, has been ensured
+	identityResourceGroupStep = "ensure-identity-resource-group"
+	// managedIdentityStepPrefix is the prefix of the inventory step recorded once the user-assigned managed identity for
+	// a CredentialsRequest has been created, the step is suffixed with the CredentialsRequest's "secretNamespace/secretName"
+	managedIdentityStepPrefix = "create-managed-identity/"
+	// oidcIssuerInventoryResourceType is the inventory resource type which records the issuer URL of the OIDC issuer
+	oidcIssuerInventoryResourceType = "OIDCIssuerURL"
+
+	// allowBlobPublicAccessSetting and blobContainerPublicAccessSetting are the inventory settings recording the
+	// anonymous access to the blobs of the storage account and container serving the OIDC documents
+	allowBlobPublicAccessSetting     = "allowBlobPublicAccess"
+	blobContainerPublicAccessSetting = "blobContainerPublicAccess"
+	// identityResourceGroupSetting is the inventory setting recording the resource group within which user-assigned
+	// managed identities were created, when separate from the OIDC resource group
+	identityResourceGroupSetting = "identityResourceGroupName"
+)
+
+// createProgress records completed create steps within an inventory so that an interrupted
+// "ccoctl azure create-all" may be resumed with --resume.
+type createProgress struct {
+	inventory *provisioning.Inventory
+	// resume indicates that steps previously completed within the inventory should be skipped
+	resume bool
+	// force indicates that previously completed steps failing validation should be re-run
+	force bool
+}
+
+// skip returns true if step was completed by a previous run and the step passes validation when resuming.
+// A previously completed step which fails validation results in an error unless force was specified,
+// in which case the step will be re-run.
+func (p *createProgress) skip(step string, validate func(*provisioning.InventoryStep) error) (bool, error) {
+	if p == nil || !p.resume {
+		return false, nil
+	}
+	completedStep, found := p.inventory.Step(step)
+	if !found {
+		return false, nil
+	}
+	if err := validate(completedStep); err != nil {
+		if !p.force {
+			return false, errors.Wrapf(err, "step %s completed by a previous run failed validation, the step can be re-run by providing --force", step)
+		}
+		provisioning.Warnf("Step %s completed by a previous run failed validation and will be re-run: %s", step, err)
+		return false, nil
+	}
+	log.Printf("Skipping step %s completed by a previous run", step)
+	return true, nil
+}
+
+// complete records step as completed along with the provided resources and persists the inventory.
+func (p *createProgress) complete(step string, resources ...provisioning.InventoryResource) error {
+	if p == nil {
+		return nil
+	}
+	return p.inventory.CompleteStep(step, resources...)
+}
+
+// recordSetting records a setting with which resources were created, which is persisted along with the next
+// completed step.
+func (p *createProgress) recordSetting(key, value string) {
+	if p == nil {
+		return
+	}
+	p.inventory.Settings[key] = value
+}
+
+// newCreateProgress returns a createProgress which records progress within a new inventory saved to outputDir or,
+// when resuming, within the inventory previously saved to outputDir. An inventory previously saved to outputDir which
+// records completed steps for name is only replaced by a new inventory when force is provided, so that the record of
+// an interrupted create-all is not lost by re-running it without --resume.
+func newCreateProgress(outputDir, name, region, subscriptionID string, resume, force bool) (*createProgress, error) {
+	inventory := provisioning.NewInventory(outputDir, "azure", name)
+	if !resume && !force {
+		if _, err := os.Stat(filepath.Join(outputDir, provisioning.InventoryFileName)); err == nil {
+			previous, err := provisioning.LoadInventory(outputDir)
+			if err != nil {
+				return nil, err
+			}
+			if previous.Provider == "azure" && previous.Name == name && len(previous.Steps) > 0 {
+				return nil, fmt.Errorf("inventory within %s already records %d completed steps for name %s, "+
+					"specify --resume to resume the interrupted create-all or --force to replace the inventory",
+					outputDir, len(previous.Steps), name)
+			}
+		}
+	}
+	if resume {
+		var err error
+		inventory, err = provisioning.LoadInventory(outputDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to resume")
+		}
+		if inventory.Provider != "azure" || inventory.Name != name ||
+			inventory.Settings["region"] != region || inventory.Settings["subscriptionID"] != subscriptionID {
+			return nil, fmt.Errorf("unable to resume, inventory within %s was recorded for provider=%s name=%s region=%s subscriptionID=%s",
+				outputDir, inventory.Provider, inventory.Name, inventory.Settings["region"], inventory.Settings["subscriptionID"])
+		}
+		log.Printf("Resuming from inventory with %d previously completed steps", len(inventory.Steps))
+	}
+	inventory.Settings["region"] = region
+	inventory.Settings["subscriptionID"] = subscriptionID
+	if err := inventory.Save(); err != nil {
+		return nil, err
+	}
+	return &createProgress{
+		inventory: inventory,
+		resume:    resume,
+		force:     force,
+	}, nil
+}
+
+// findPriorProvisioning returns descriptions of the resources created by ccoctl for name by a prior provisioning, the
+// resource groups identified by oidcResourceGroupName and identityResourceGroupName and the user-assigned managed
+// identities within the identity resource group which carry CCO's "owned" tag for name. Resources carrying the tag
+// are found regardless of the cluster they are tagged with.
+func findPriorProvisioning(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, oidcResourceGroupName, identityResourceGroupName string) ([]string, error) {
+	resourceGroupNames := []string{oidcResourceGroupName}
+	if identityResourceGroupName != oidcResourceGroupName {
+		resourceGroupNames = append(resourceGroupNames, identityResourceGroupName)
+	}
+
+	resources := []string{}
+	for _, resourceGroupName := range resourceGroupNames {
+		getResourceGroupResp, err := client.ResourceGroupsClient.Get(ctx, resourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) && respErr.ErrorCode == "ResourceGroupNotFound" {
+				// Managed identities are created within the identity resource group so none exist without it
+				continue
+			}
+			return nil, errors.Wrapf(err, "unable to get resource group %s", resourceGroupName)
+		}
+		if hasOwnedResourceTag(getResourceGroupResp.Tags, name, ownedTagValue) {
+			resources = append(resources, "resource group "+resourceGroupName)
+		}
+		if resourceGroupName != identityResourceGroupName {
+			continue
+		}
+
+		listManagedIdentities := client.UserAssignedIdentitiesClient.NewListByResourceGroupPager(
+			resourceGroupName,
+			&armmsi.UserAssignedIdentitiesClientListByResourceGroupOptions{},
+		)
+		for listManagedIdentities.More() {
+			pageResponse, err := listManagedIdentities.NextPage(ctx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to list user-assigned managed identities within resource group %s", resourceGroupName)
+			}
+			for _, identity := range pageResponse.UserAssignedIdentitiesListResult.Value {
+				if hasOwnedResourceTag(identity.Tags, name, ownedTagValue) {
+					resources = append(resources, "user-assigned managed identity "+*identity.Name)
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+// checkPriorProvisioning returns an error when resources created by ccoctl for name by a prior provisioning exist,
+// unless the prior provisioning is resumed with --resume or overridden with --force. With --dry-run the resources
+// are only reported with a warning since nothing will be created.
+func checkPriorProvisioning(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, oidcResourceGroupName, identityResourceGroupName string, resume, force, dryRun bool) error {
+	if resume || force {
+		return nil
+	}
+	resources, err := findPriorProvisioning(ctx, client, name, ownedTagValue, oidcResourceGroupName, identityResourceGroupName)
+	if err != nil {
+		return err
+	}
+	err = provisioning.CheckPriorProvisioning(name, resources, "--resume to resume an interrupted create-all, or --force to proceed regardless")
+	if err != nil && dryRun {
+		provisioning.Warnf("%s", err)
+		return nil
+	}
+	return err
+}
+
 func createAllCmd(cmd *cobra.Command, args []string) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	ctx := cmd.Context()
+	if err := CreateAllOpts.DiscoveryDocument.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	cred, err := newAzureCredential(ctx, CreateAllOpts.TenantID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	azureClientWrapper, err := azureclients.NewAzureClientWrapper(CreateAllOpts.SubscriptionID, cred, &policy.ClientOptions{}, false)
+	azureClientWrapper, err := newAzureClientWrapper(CreateAllOpts.SubscriptionID, cred)
 	if err != nil {
 		log.Fatalf("Failed to create Azure client: %s", err)
 	}
 
-	publicKeyPath := CreateAllOpts.PublicKeyPath
-	if publicKeyPath == "" {
-		publicKeyPath = filepath.Join(CreateAllOpts.OutputDir, provisioning.PublicKeyFile)
-		if err := provisioning.CreateKeys(CreateAllOpts.OutputDir); err != nil {
-			log.Fatalf("Failed to create RSA key pair: %s", err)
+	if CreateAllOpts.Resume && CreateAllOpts.DryRun {
+		log.Fatal("--resume may not be combined with --dry-run")
+	}
+
+	if !CreateAllOpts.DryRun && !CreateAllOpts.SkipWritePermissionsCheck {
+		provisioning.SetPhase("checking the write permissions of the identity")
+		if err := checkWritePermissions(ctx, azureClientWrapper); err != nil {
+			log.Fatal(err)
 		}
 	}
 
@@ -55,49 +249,276 @@ func createAllCmd(cmd *cobra.Command, args []string) {
 		CreateAllOpts.BlobContainerName = CreateAllOpts.Name
 		log.Printf("No --blob-container-name provided, defaulting blob container name to %s", CreateAllOpts.BlobContainerName)
 	}
+	if err := validateBlobContainerName(CreateAllOpts.BlobContainerName); err != nil {
+		log.Fatal(err)
+	}
 
 	if CreateAllOpts.InstallationResourceGroupName == "" {
 		CreateAllOpts.InstallationResourceGroupName = CreateAllOpts.Name
 		log.Printf("No --installation-resource-group-name provided, defaulting installation resource group name to %s", CreateAllOpts.InstallationResourceGroupName)
 	}
 
-	issuerURL, err := createOIDCIssuer(azureClientWrapper,
+	if err := validateIdentityResourceGroupName(&CreateAllOpts); err != nil {
+		log.Fatal(err)
+	}
+
+	provisioning.DumpConfig(cmd, func() map[string]string {
+		resolved := resolvedIdentity(ctx, cred)
+		resolved["oidcResourceGroupName"] = CreateAllOpts.OIDCResourceGroupName
+		resolved["identityResourceGroupName"] = CreateAllOpts.IdentityResourceGroupName
+		resolved["storageAccountName"] = CreateAllOpts.StorageAccountName
+		resolved["blobContainerName"] = CreateAllOpts.BlobContainerName
+		resolved["installationResourceGroupName"] = CreateAllOpts.InstallationResourceGroupName
+		resolved["outputDir"] = CreateAllOpts.OutputDir
+		return resolved
+	})
+
+	if !CreateAllOpts.SkipCostEstimate {
+		counts, err := createAllResourceCounts(CreateAllOpts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		provisioning.PrintCostEstimate("azure", CreateAllOpts.Region, counts)
+	}
+
+	// Access to the key vault is validated before any Azure resources are created
+	var keyVault keyVaultSecretsClient
+	vaultURL := keyVaultURL(CreateAllOpts.KeyVaultName)
+	if CreateAllOpts.KeyVaultName != "" {
+		keyVault = newKeyVaultClient(vaultURL, cred, newClientOptions())
+		err = validateKeyVaultAccess(ctx, keyVault, vaultURL, CreateAllOpts.Name, CreateAllOpts.OwnedTagValue, CreateAllOpts.KeyVaultSecretName)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	publicKeyPath := CreateAllOpts.PublicKeyPath
+	if publicKeyPath == "" {
+		publicKeyPath = filepath.Join(CreateAllOpts.OutputDir, provisioning.PublicKeyFile)
+		// Generating a new key pair would not match the public key uploaded by the run which stored the private key
+		if err := validateSigningKeyNotStored(CreateAllOpts.OutputDir); err != nil {
+			log.Fatal(err)
+		}
+		if err := provisioning.CreateKeys(CreateAllOpts.OutputDir); err != nil {
+			log.Fatalf("Failed to create RSA key pair: %s", err)
+		}
+	}
+	// The public key is validated as a signing key before any resource trusting it is created
+	signingKeySize, err := provisioning.SigningKeySize(publicKeyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Refuse to adopt or overwrite the resources of another cluster provisioned with the same --name
+	provisioning.SetPhase("checking for a prior provisioning")
+	err = checkPriorProvisioning(ctx, azureClientWrapper,
+		CreateAllOpts.Name,
+		CreateAllOpts.OwnedTagValue,
+		CreateAllOpts.OIDCResourceGroupName,
+		CreateAllOpts.IdentityResourceGroupName,
+		CreateAllOpts.Resume,
+		CreateAllOpts.Force,
+		CreateAllOpts.DryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !CreateAllOpts.DryRun && !CreateAllOpts.SkipQuotaCheck {
+		provisioning.SetPhase("checking the role assignment quota")
+		err = checkRoleAssignmentsQuota(ctx, azureClientWrapper,
+			CreateAllOpts.CredRequestDirs,
+			CreateAllOpts.Name,
+			CreateAllOpts.IdentityResourceGroupName,
+			CreateAllOpts.SubscriptionID,
+			CreateAllOpts.InstallationResourceGroupName,
+			CreateAllOpts.DNSZoneResourceGroupName,
+			CreateAllOpts.EnableTechPreview)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Progress is not recorded for a dry run because no Azure resources are created
+	var progress *createProgress
+	if !CreateAllOpts.DryRun {
+		progress, err = newCreateProgress(CreateAllOpts.OutputDir,
+			CreateAllOpts.Name,
+			CreateAllOpts.Region,
+			CreateAllOpts.SubscriptionID,
+			CreateAllOpts.Resume,
+			CreateAllOpts.Force)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	progress.recordSetting(provisioning.SigningKeySizeInventorySetting, strconv.Itoa(signingKeySize))
+
+	provisioning.SetPhase("creating the OIDC issuer")
+	issuerURL, err := createOIDCIssuer(ctx, azureClientWrapper,
 		CreateAllOpts.Name,
+		CreateAllOpts.OwnedTagValue,
 		CreateAllOpts.Region,
 		CreateAllOpts.OIDCResourceGroupName,
 		CreateAllOpts.StorageAccountName,
 		CreateAllOpts.BlobContainerName,
+		CreateAllOpts.IssuerURLPathPrefix,
 		CreateAllOpts.SubscriptionID,
 		publicKeyPath,
 		CreateAllOpts.OutputDir,
 		CreateAllOpts.UserTags,
-		// dryRun may only be invoked by subcommands create-oidc-issuer and create-managed-identities
-		false)
+		CreateAllOpts.DiscoveryDocument,
+		CreateAllOpts.DryRun,
+		progress)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = createManagedIdentities(azureClientWrapper,
-		CreateAllOpts.CredRequestDir,
+	// Federated identity credentials must trust exactly the issuer claimed by the discovery document
+	provisioning.SetPhase("verifying the issuer of the OIDC discovery document")
+	if err := verifyDiscoveryDocumentIssuer(issuerURL, CreateAllOpts.OutputDir, CreateAllOpts.DryRun); err != nil {
+		log.Fatal(err)
+	}
+
+	// Ensure the OIDC documents are publicly served before federated credentials trust the issuer
+	if CreateAllOpts.VerifyIssuerReachable && !CreateAllOpts.DryRun {
+		provisioning.SetPhase("verifying the OIDC issuer is reachable")
+		if err := provisioning.VerifyIssuerReachable(issuerURL); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	err = ensureIdentityResourceGroup(ctx, azureClientWrapper,
 		CreateAllOpts.Name,
+		CreateAllOpts.OwnedTagValue,
+		CreateAllOpts.IdentityResourceGroupName,
 		CreateAllOpts.OIDCResourceGroupName,
 		CreateAllOpts.SubscriptionID,
 		CreateAllOpts.Region,
+		CreateAllOpts.UserTags,
+		CreateAllOpts.CreateIdentityResourceGroup,
+		CreateAllOpts.DryRun,
+		progress)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = createManagedIdentities(ctx, azureClientWrapper,
+		CreateAllOpts.CredRequestDirs,
+		CreateAllOpts.Name,
+		CreateAllOpts.OwnedTagValue,
+		CreateAllOpts.IdentityResourceGroupName,
+		CreateAllOpts.SubscriptionID,
+		CreateAllOpts.Region,
 		issuerURL,
 		CreateAllOpts.OutputDir,
 		CreateAllOpts.InstallationResourceGroupName,
 		CreateAllOpts.DNSZoneResourceGroupName,
 		CreateAllOpts.UserTags,
 		CreateAllOpts.EnableTechPreview,
-		// dryRun may only be invoked by subcommands create-oidc-issuer and create-managed-identities
-		false)
+		CreateAllOpts.DryRun,
+		false,
+		progress)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// Ensure every secret manifest holds the client ID of the user-assigned managed identity it was written for
+	if !CreateAllOpts.DryRun {
+		provisioning.SetPhase("verifying the client IDs of user-assigned managed identities")
+		err = verifyManagedIdentityClientIDs(ctx, azureClientWrapper,
+			CreateAllOpts.CredRequestDirs,
+			CreateAllOpts.Name,
+			CreateAllOpts.OwnedTagValue,
+			CreateAllOpts.IdentityResourceGroupName,
+			CreateAllOpts.OutputDir,
+			CreateAllOpts.EnableTechPreview)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if keyVault != nil {
+		provisioning.SetPhase("storing the signing key within the key vault")
+		err = storeSigningKeyInKeyVault(ctx, keyVault,
+			vaultURL,
+			CreateAllOpts.Name,
+			CreateAllOpts.OwnedTagValue,
+			CreateAllOpts.KeyVaultSecretName,
+			CreateAllOpts.OutputDir,
+			CreateAllOpts.DryRun)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The inventory is only recorded when Azure resources are created
+	if CreateAllOpts.OutputImport && progress != nil {
+		if err := writeTerraformImports(progress.inventory); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// The next steps are derived from the output directory before the manifests are moved into the archive. Nothing
+	// remains to be done after a dry run.
+	var nextSteps *provisioning.NextSteps
+	if !CreateAllOpts.DryRun {
+		nextSteps, err = provisioning.NewNextSteps("azure", CreateAllOpts.OutputDir, publicKeyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		nextSteps.InstallConfig["platform.azure.resourceGroupName"] = CreateAllOpts.InstallationResourceGroupName
+		if keyVault != nil {
+			nextSteps.PrivateKeyLocation = fmt.Sprintf("secret %s within key vault %s", CreateAllOpts.KeyVaultSecretName, vaultURL)
+		}
+	}
+
+	if CreateAllOpts.OutputArchive != "" {
+		if err := provisioning.ArchiveOutputs(CreateAllOpts.OutputDir, CreateAllOpts.OutputArchive); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Wrote output archive %s", CreateAllOpts.OutputArchive)
+		if nextSteps != nil {
+			nextSteps.OutputArchive = CreateAllOpts.OutputArchive
+		}
+	}
+	if nextSteps != nil {
+		provisioning.PrintNextSteps(nextSteps)
+	}
+	printIssuerURL(CreateAllOpts.PrintIssuerURL, issuerURL)
 }
 
 // initEnvForCreateAllCmd ensures that the output directory specified by --output-dir exists
 func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
+	if CreateAllOpts.PrintIssuerURL {
+		if provisioning.JSONStreamEnabled() {
+			log.Fatal("--print-issuer-url may not be specified with --output=json-stream which writes progress events to stdout")
+		}
+		if provisioning.YAMLOutputEnabled() {
+			log.Fatal("--print-issuer-url may not be specified with --output=yaml which writes the install-config snippet to stdout")
+		}
+		// stdout is reserved for the issuer URL
+		log.SetOutput(os.Stderr)
+	}
+	if err := validateOwnedTagValue(CreateAllOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+	if err := addClusterResourceTag(&CreateAllOpts); err != nil {
+		log.Fatal(err)
+	}
+	if err := provisioning.ValidateCredentialsRequests(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if err := checkManagedIdentityLimits(CreateAllOpts.CredRequestDirs, CreateAllOpts.Name, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if !CreateAllOpts.SkipDeprecatedPermissionsCheck {
+		if err := provisioning.WarnDeprecatedPermissions(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if CreateAllOpts.OutputDir == "" && CreateAllOpts.DryRun {
+		CreateAllOpts.OutputDir = provisioning.DryRunOutputDir("azure")
+	}
 	if CreateAllOpts.OutputDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -125,12 +546,31 @@ func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to create manifests directory at path %s", manifestsDir)
 	}
 
+	provisioning.EnsureSecretsDir(manifestsDir, CreateAllOpts.AllowInsecureSecretsDir)
+
 	// Create tls dir within the output dir if it doesn't exist
 	tlsDir := filepath.Join(outputDirPath, provisioning.TLSDirName)
 	err = provisioning.EnsureDir(tlsDir)
 	if err != nil {
 		log.Fatalf("Failed to create tls directory at path %s", tlsDir)
 	}
+
+	if CreateAllOpts.KeyVaultName != "" {
+		if CreateAllOpts.PublicKeyPath != "" {
+			log.Fatal("--key-vault-name may not be specified with --public-key-file, the signing key pair must be generated by ccoctl to store its private key within the key vault")
+		}
+		CreateAllOpts.KeyVaultSecretName = signingKeySecretName(CreateAllOpts.Name, CreateAllOpts.KeyVaultSecretName)
+		if err := validateKeyVaultNames(CreateAllOpts.KeyVaultName, CreateAllOpts.KeyVaultSecretName); err != nil {
+			log.Fatal(err)
+		}
+	} else if CreateAllOpts.KeyVaultSecretName != "" {
+		log.Fatal("--key-vault-secret-name may only be specified with --key-vault-name")
+	}
+
+	CreateAllOpts.OutputArchive, err = provisioning.ValidateOutputArchivePath(CreateAllOpts.OutputArchive, outputDirPath)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 // NewCreateAllCmd combines create-identity-provider and create-managed-identities commands
@@ -141,6 +581,7 @@ func NewCreateAllCmd() *cobra.Command {
 		Run:              createAllCmd,
 		PersistentPreRun: initEnvForCreateAllCmd,
 	}
+	provisioning.DumpsResolvedConfig(createAllCmd)
 
 	// Required parameters
 	createAllCmd.PersistentFlags().StringVar(
@@ -148,14 +589,14 @@ func NewCreateAllCmd() *cobra.Command {
 		"name",
 		"",
 		"User-defined name for all created Azure resources. This user-defined name can be separate from the cluster's infra-id. "+
-			fmt.Sprintf("Azure resources created by ccoctl will be tagged with '%s_NAME = %s'", ownedAzureResourceTagKeyPrefix, ownedAzureResourceTagValue),
+			fmt.Sprintf("Azure resources created by ccoctl will be tagged with '%s_NAME = <--owned-tag-value>'", ownedAzureResourceTagKeyPrefix),
 	)
 	createAllCmd.MarkPersistentFlagRequired("name")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Region, "region", "", "Azure region in which to create identity provider infrastructure")
 	createAllCmd.MarkPersistentFlagRequired("region")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which to create identity provider infrastructure")
 	createAllCmd.MarkPersistentFlagRequired("subscription-id")
-	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing Azure CredentialsRequests files used to create user-assigned managed identities (can be created by running 'oc adm release extract --credentials-requests --cloud=azure' against an OpenShift release image)")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing Azure CredentialsRequests files used to create user-assigned managed identities (can be created by running 'oc adm release extract --credentials-requests --cloud=azure' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createAllCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.DNSZoneResourceGroupName, "dnszone-resource-group-name", "", "The existing Azure resource group which contains the DNS zone that will be used for the cluster's base domain. The cluster ingress operator will be scoped to allow management of DNS records in the DNS Zone resource group.")
 	createAllCmd.MarkPersistentFlagRequired("dnszone-resource-group-name")
@@ -165,9 +606,11 @@ func NewCreateAllCmd() *cobra.Command {
 		&CreateAllOpts.OIDCResourceGroupName,
 		"oidc-resource-group-name",
 		"",
-		"The Azure resource group in which to create OIDC infrastructure including a storage account, blob storage container and user-assigned managed identities. "+
+		"The Azure resource group in which to create OIDC infrastructure including a storage account, blob storage container and, unless --identity-resource-group-name is provided, user-assigned managed identities. "+
 			"A resource group will be created (with a name derived from the --name parameter) if an oidc-resource-group-name parameter was not provided",
 	)
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.IdentityResourceGroupName, "identity-resource-group-name", "", identityResourceGroupNameFlagUsage)
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.CreateIdentityResourceGroup, "create-identity-resource-group", false, createIdentityResourceGroupFlagUsage)
 	createAllCmd.PersistentFlags().StringVar(
 		&CreateAllOpts.InstallationResourceGroupName,
 		"installation-resource-group-name",
@@ -196,11 +639,70 @@ func NewCreateAllCmd() *cobra.Command {
 		"The name of the Azure blob container in which to upload OIDC discovery documents. "+
 			"A blob container will be created with a name derived from the --name parameter if a --blob-container-name parameter was not provided. "+
 			"The blob container will be created within the OIDC resource group identified by the --oidc-resource-group-name parameter "+
-			"and storage account identified by --storage-account-name.",
+			"and storage account identified by --storage-account-name. The issuer URL is https://<storage account>.blob.core.windows.net/<blob container>. "+
+			blobContainerNameFlagUsage,
+	)
+	createAllCmd.PersistentFlags().StringVar(
+		&CreateAllOpts.IssuerURLPathPrefix,
+		"issuer-url-path-prefix",
+		"",
+		"Path within the blob container beneath which the OIDC discovery document and JSON web key set are uploaded. "+
+			"The issuer URL, and the jwks_uri within the OIDC discovery document, will incorporate the prefix. "+
+			"The prefix may contain only letters, numbers, '.', '_', '~', '-' and '/' separators.",
+	)
+	createAllCmd.PersistentFlags().BoolVar(
+		&CreateAllOpts.PrintIssuerURL,
+		"print-issuer-url",
+		false,
+		"Print only the issuer URL to stdout so that it may be captured by scripts, eg. ISSUER=$(ccoctl azure ... --print-issuer-url). "+
+			"All other output is written to stderr.",
 	)
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.PublicKeyPath, "public-key-file", "", "Path to public ServiceAccount signing key")
-	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.OutputDir, "output-dir", "", "Directory to place generated manifest files. Defaults to the current directory.")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.OutputDir, "output-dir", "", "Directory to place generated manifest files. Defaults to the current directory, or a temporary directory when --dry-run is specified.")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipWritePermissionsCheck, "skip-write-permissions-check", false, "Skip probing whether the identity is permitted to make changes within the subscription before creating resources. The probe deletes a resource group with a random name which does not exist, so it never changes nor leaves behind any resource")
+	createAllCmd.PersistentFlags().BoolVar(
+		&CreateAllOpts.Resume,
+		"resume",
+		false,
+		fmt.Sprintf("Resume an interrupted create-all using the inventory (%s) saved to the --output-dir after each completed step. ", provisioning.InventoryFileName)+
+			"Previously completed steps are skipped when their resources are found to exist. "+
+			"A previously completed step whose resources are not found results in an error unless --force is also specified, in which case the step is re-run. "+
+			"The --name, --region and --subscription-id parameters must match those of the interrupted run.",
+	)
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipQuotaCheck, "skip-quota-check", false, "Skip checking that the limit of role assignments per subscription allows assigning the roles of the user-assigned managed identities before creating them")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipCostEstimate, provisioning.SkipCostEstimateFlag, false, provisioning.SkipCostEstimateFlagUsage)
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipDeprecatedPermissionsCheck, "skip-deprecated-permissions-check", false, "Skip warning about the roles requested by the CredentialsRequests which are known to be deprecated by Azure")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.Force, "force", false, "Re-run previously completed steps which fail validation when resuming with --resume "+
+		"and proceed even if resources created by ccoctl for --name by a prior provisioning exist. "+
+		fmt.Sprintf("Without --resume, replace an inventory (%s) within the --output-dir which records completed steps for --name", provisioning.InventoryFileName))
+	provisioning.AddAllowInsecureSecretsDirFlag(createAllCmd.PersistentFlags(), &CreateAllOpts.AllowInsecureSecretsDir)
 	createAllCmd.PersistentFlags().StringToStringVar(&CreateAllOpts.UserTags, "user-tags", map[string]string{}, "User tags to be applied to Azure resources, multiple tags may be specified comma-separated for example: --user-tags key1=value1,key2=value2")
+	createAllCmd.PersistentFlags().StringVar(
+		&CreateAllOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag applied to the Azure resources created by ccoctl, eg. to coexist with other tooling using the same tag key. ", ownedAzureResourceTagKeyPrefix)+
+			"The same value must be provided to ccoctl azure delete in order to delete the resources",
+	)
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag created Azure resources, the resources will be tagged with 'kubernetes.io_cluster.<cluster ID> = owned'")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.VerifyIssuerReachable, "verify-issuer-reachable", false, "Fetch the OIDC discovery document and JSON web key set from the issuer URL over the public network once they have been uploaded, honoring the HTTPS_PROXY and NO_PROXY environment variables, and fail if they are not publicly served. Skipped with --dry-run")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.DiscoveryDocument.Audiences, "oidc-extra-audience", nil, "Audience to append to the audiences_supported of the OIDC discovery document, eg. for an identity consumer of the issuer other than the cluster. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.DiscoveryDocument.Claims, "oidc-extra-claim", nil, "Claim to append to the claims_supported of the OIDC discovery document. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.OutputImport, "output-import", false, fmt.Sprintf("Write a terraform import command for every created Azure resource to %s within the output directory, so that the resources may be brought under terraform management. Ignored with --dry-run", provisioning.TerraformImportFileName))
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.OutputArchive, "output-archive", "", "Package the generated manifests, the tls directory and the inventory into a reproducible gzipped tar archive at this path, eg. to transport them into a disconnected environment. The manifests directory and the inventory are removed from the output directory once the archive has been written")
+	createAllCmd.PersistentFlags().StringVar(
+		&CreateAllOpts.KeyVaultName,
+		"key-vault-name",
+		"",
+		"Name of an existing Azure key vault within which to store the generated signing private key as a secret, instead of leaving it within the output directory. "+
+			"Access to the vault is validated before any Azure resources are created. Once stored, the private key is removed from the output directory "+
+			fmt.Sprintf("and %s/%s, which references the secret, is written in its place", provisioning.TLSDirName, signingKeyReferenceFile),
+	)
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.KeyVaultSecretName, "key-vault-secret-name", "", "Name of the secret within the key vault identified by --key-vault-name in which to store the signing private key. Defaults to <name>"+signingKeySecretSuffix)
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+
+	createAllCmd.SetGlobalNormalizationFunc(normalizeOIDCContainerNameFlag)
 
 	return createAllCmd
 }
@@ -0,0 +1,135 @@
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestCheckPriorProvisioning(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	otherClusterTags := map[string]*string{
+		ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue),
+		clusterResourceTagKey("other-id"):  to.Ptr(provisioning.ClusterResourceTagValue),
+	}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		// identityResourceGroupName defaults to the OIDC resource group
+		identityResourceGroupName string
+		resume                    bool
+		force                     bool
+		dryRun                    bool
+		expectError               bool
+		expectedResources         []string
+	}{
+		{
+			name: "No OIDC resource group",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+		},
+		{
+			name: "OIDC resource group not created by ccoctl without owned managed identities",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{})
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"unrelated-identity": {ownedResourceTagKey("other-name"): to.Ptr(ownedAzureResourceTagValue)},
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "Prior provisioning of another cluster",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, otherClusterTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testInfraName + "-namespace-secret": otherClusterTags,
+				})
+				return wrapper
+			},
+			expectError:       true,
+			expectedResources: []string{"resource group " + testOIDCResourceGroupName, "user-assigned managed identity " + testInfraName + "-namespace-secret"},
+		},
+		{
+			name: "Managed identities of a prior provisioning within a resource group not created by ccoctl",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{})
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testInfraName + "-namespace-secret": ownedTags,
+				})
+				return wrapper
+			},
+			expectError:       true,
+			expectedResources: []string{"user-assigned managed identity " + testInfraName + "-namespace-secret"},
+		},
+		{
+			name: "Managed identities of a prior provisioning within a separate identity resource group",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				mockGetResourceGroupSuccess(wrapper, testIdentityResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testIdentityResourceGroupName, map[string]map[string]*string{
+					testInfraName + "-namespace-secret": ownedTags,
+				})
+				return wrapper
+			},
+			identityResourceGroupName: testIdentityResourceGroupName,
+			expectError:               true,
+			expectedResources:         []string{"resource group " + testIdentityResourceGroupName, "user-assigned managed identity " + testInfraName + "-namespace-secret"},
+		},
+		{
+			name: "Prior provisioning reported with dry run",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{})
+				return wrapper
+			},
+			dryRun: true,
+		},
+		{
+			name:                   "Check skipped when resuming",
+			mockAzureClientWrapper: mockAzureClientWrapper,
+			resume:                 true,
+		},
+		{
+			name:                   "Check skipped with force",
+			mockAzureClientWrapper: mockAzureClientWrapper,
+			force:                  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			if test.identityResourceGroupName == "" {
+				test.identityResourceGroupName = testOIDCResourceGroupName
+			}
+			err := checkPriorProvisioning(context.Background(), test.mockAzureClientWrapper(mockCtrl), testInfraName, ownedAzureResourceTagValue, testOIDCResourceGroupName,
+				test.identityResourceGroupName, test.resume, test.force, test.dryRun)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				for _, resource := range test.expectedResources {
+					assert.Contains(t, err.Error(), resource, "expected error to report resource of the prior provisioning")
+				}
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
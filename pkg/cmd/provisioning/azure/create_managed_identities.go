@@ -7,15 +7,16 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	armauthorization "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 
 	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
@@ -24,12 +25,22 @@ import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 var (
 	// CreateManagedIdentitiesOpts are azureOptions necessary for creating user-assigned managed identities
 	CreateManagedIdentitiesOpts = azureOptions{}
 
+	// managedIdentityNameConstraints are the constraints of Azure on the names of user-assigned managed identities
+	managedIdentityNameConstraints = provisioning.NameConstraints{
+		MaxLength:   128,
+		Pattern:     regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{2,127}$`),
+		Description: "3 to 128 alphanumeric characters, hyphens or underscores starting with an alphanumeric character",
+	}
+
 	secretManifestTemplate = `apiVersion: v1
 stringData:
   azure_client_id: %s
@@ -39,11 +50,26 @@ stringData:
   azure_federated_token_file: %s
 kind: Secret
 metadata:
-  name: %s
+  annotations:
+%s  name: %s
   namespace: %s
 type: Opaque`
 
 	ingressCredentialRequestName = "openshift-ingress-azure"
+
+	// propagationRetryInterval is the delay between attempts of operations on a user-assigned managed identity which
+	// fail due to a replication delay following creation of the user-assigned managed identity
+	propagationRetryInterval = 10 * time.Second
+	// propagationTimeout bounds the time spent waiting for a user-assigned managed identity to propagate
+	propagationTimeout = 2 * time.Minute
+
+	// maxRoleAssignmentsPerSubscription is the limit of Azure role assignments within a subscription
+	// Reference: https://learn.microsoft.com/en-us/azure/role-based-access-control/troubleshoot-limits
+	maxRoleAssignmentsPerSubscription = 4000
+	// maxFederatedIdentityCredentialsPerIdentity is the limit of federated identity credentials of a user-assigned
+	// managed identity
+	// Reference: https://learn.microsoft.com/en-us/entra/workload-id/workload-identity-federation-considerations
+	maxFederatedIdentityCredentialsPerIdentity = 20
 )
 
 // createManagedIdentity creates a user-assigned managed identity for the provided CredentialsRequest
@@ -63,18 +89,18 @@ type: Opaque`
 //
 // A secret containing user-assigned managed identity details will be written to the outputDir
 // once the user-assigned managed identity is created and configured.
-func createManagedIdentity(client *azureclients.AzureClientWrapper, name, resourceGroupName, subscriptionID, region, issuerURL, outputDir string, scopingResourceGroupNames []string, resourceTags map[string]string, credentialsRequest *credreqv1.CredentialsRequest, dryRun bool) error {
+func createManagedIdentity(ctx context.Context, client *azureclients.AzureClientWrapper, roleDefinitions *roleDefinitionResolver, name, resourceGroupName, subscriptionID, region, issuerURL, outputDir string, scopingResourceGroupNames []string, resourceTags map[string]string, credentialsRequest *credreqv1.CredentialsRequest, dryRun bool) error {
+	shortenedManagedIdentityName, err := managedIdentityName(name, credentialsRequest)
+	if err != nil {
+		return err
+	}
+
 	// Write dummy secrets with blank clientID and tenantID when doing a dry run.
 	if dryRun {
-		writeCredReqSecret(credentialsRequest, outputDir, "", "", subscriptionID, region)
-		return nil
+		return writeDryRunCredReqSecret(credentialsRequest, outputDir, shortenedManagedIdentityName, subscriptionID, region, issuerURL)
 	}
 
-	// Create user-assigned managed identity with name "name-targetNamespace-targetSecretName"
-	// Azure resources can't have a name longer than 128 characters
-	managedIdentityName := fmt.Sprintf("%s-%s-%s", name, credentialsRequest.Spec.SecretRef.Namespace, credentialsRequest.Spec.SecretRef.Name)
-	shortenedManagedIdentityName := provisioning.ShortenName(managedIdentityName, 128)
-	userAssignedManagedIdentity, err := ensureUserAssignedManagedIdentity(client, shortenedManagedIdentityName, resourceGroupName, region, resourceTags)
+	userAssignedManagedIdentity, preExisting, err := ensureUserAssignedManagedIdentity(ctx, client, shortenedManagedIdentityName, resourceGroupName, region, managedIdentityResourceTags(resourceTags, credentialsRequest))
 	if err != nil {
 		return err
 	}
@@ -93,31 +119,56 @@ func createManagedIdentity(client *azureclients.AzureClientWrapper, name, resour
 	}
 
 	// Ensure roles from CredentialsRequest are assigned to the user-assigned managed identity
-	err = ensureRolesAssignedToManagedIdentity(client, *userAssignedManagedIdentity.Properties.PrincipalID, subscriptionID, crProviderSpec.RoleBindings, scopingResourceGroupNames)
+	err = ensureRolesAssignedToManagedIdentity(ctx, client, roleDefinitions, *userAssignedManagedIdentity.Properties.PrincipalID, subscriptionID, crProviderSpec.RoleBindings, scopingResourceGroupNames)
 	if err != nil {
 		return err
 	}
 
 	// Ensure a federated identity credential exists for every service account enumerated in the CredentialsRequest
-	for _, serviceAccountName := range credentialsRequest.Spec.ServiceAccountNames {
-		err := ensureFederatedIdentityCredential(client, shortenedManagedIdentityName, issuerURL, credentialsRequest.Spec.SecretRef.Namespace, serviceAccountName, resourceGroupName)
-		if err != nil {
-			return err
-		}
+	err = ensureFederatedIdentityCredentials(ctx, client, shortenedManagedIdentityName, issuerURL, credentialsRequest.Spec.SecretRef.Namespace, credentialsRequest.Spec.ServiceAccountNames, resourceGroupName, preExisting)
+	if err != nil {
+		return err
 	}
 
-	writeCredReqSecret(credentialsRequest, outputDir, *userAssignedManagedIdentity.Properties.ClientID, *userAssignedManagedIdentity.Properties.TenantID, subscriptionID, region)
+	writeCredReqSecret(credentialsRequest, outputDir, *userAssignedManagedIdentity.Properties.ClientID, *userAssignedManagedIdentity.Properties.TenantID, subscriptionID, region, issuerURL)
 	return nil
 }
 
+// managedIdentityName returns the name of the user-assigned managed identity created for the provided CredentialsRequest,
+// rendered from the --name-template of user-assigned managed identities when provided and derived from name otherwise.
+func managedIdentityName(name string, credentialsRequest *credreqv1.CredentialsRequest) (string, error) {
+	return provisioning.ComponentName(provisioning.NameTemplateAzureManagedIdentity, name, credentialsRequest, managedIdentityNameConstraints,
+		defaultManagedIdentityName(name, credentialsRequest))
+}
+
+// defaultManagedIdentityName returns the name of the user-assigned managed identity created for the provided
+// CredentialsRequest derived from name, "name-targetNamespace-targetSecretName", shortened to 128 characters because
+// Azure resources can't have a name longer than 128 characters.
+func defaultManagedIdentityName(name string, credentialsRequest *credreqv1.CredentialsRequest) string {
+	return provisioning.ShortenName(fmt.Sprintf("%s-%s-%s", name, credentialsRequest.Spec.SecretRef.Namespace, credentialsRequest.Spec.SecretRef.Name), 128)
+}
+
+// managedIdentityResourceTags returns resourceTags along with the tag identifying the component of the provided
+// CredentialsRequest, so that its user-assigned managed identity may be matched to the component whatever its name
+func managedIdentityResourceTags(resourceTags map[string]string, credentialsRequest *credreqv1.CredentialsRequest) map[string]string {
+	tags := make(map[string]string, len(resourceTags)+1)
+	for key, value := range resourceTags {
+		tags[key] = value
+	}
+	tags[componentAzureResourceTagKey] = provisioning.CredentialsRequestComponentTag(credentialsRequest)
+	return tags
+}
+
 // ensureRolesAssignedToManagedIdentity ensures that the provided roleBindings are assigned to the user-assigned
 // managed identity identified by managedIdentityPrincipalID.
 //
-// Role assignment will be scoped within the resource groups provided as scopingResourceGroupNames.
+// Role assignment will be scoped within the resource groups provided as scopingResourceGroupNames. The role of each
+// RoleBinding may be either the name of a role, such as "Storage Blob Data Contributor", or the ID of a role definition
+// and is resolved to a role definition by roleDefinitions.
 //
 // Roles which are assigned to pre-existing user-assigned managed identities will be removed if
 // not enumerated within roleBindings.
-func ensureRolesAssignedToManagedIdentity(client *azureclients.AzureClientWrapper, managedIdentityPrincipalID, subscriptionID string, roleBindings []credreqv1.RoleBinding, scopingResourceGroupNames []string) error {
+func ensureRolesAssignedToManagedIdentity(ctx context.Context, client *azureclients.AzureClientWrapper, roleDefinitions *roleDefinitionResolver, managedIdentityPrincipalID, subscriptionID string, roleBindings []credreqv1.RoleBinding, scopingResourceGroupNames []string) error {
 	// List role assignments by the user-assigned managed identity principal ID
 	// This list of role assignments are roles which are assigned to the user-assigned managed identity
 	existingRoleAssignments := []*armauthorization.RoleAssignment{}
@@ -128,7 +179,7 @@ func ensureRolesAssignedToManagedIdentity(client *azureclients.AzureClientWrappe
 		},
 	)
 	for listRoleAssignments.More() {
-		pageResponse, err := listRoleAssignments.NextPage(context.Background())
+		pageResponse, err := listRoleAssignments.NextPage(ctx)
 		if err != nil {
 			return err
 		}
@@ -143,21 +194,23 @@ func ensureRolesAssignedToManagedIdentity(client *azureclients.AzureClientWrappe
 	// Assign requested roles to the user-assigned managed identity
 	// Role assignment will be scoped to the resource group identified by scopingResourceGroupName
 	for _, roleBinding := range roleBindings {
+		// Get Azure role definition for the role name or role definition ID (roleBinding.Role)
+		roleDefinition, err := roleDefinitions.resolve(ctx, roleBinding.Role)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("failed to get role definition for role %s", roleBinding.Role))
+		}
+		roleName := roleDefinitionName(roleDefinition, roleBinding.Role)
+
 		for _, scopingResourceGroupName := range scopingResourceGroupNames {
 			scope := "/subscriptions/" + subscriptionID + "/resourceGroups/" + scopingResourceGroupName
-			// Get Azure role definition for the role name (roleBinding.Role)
-			roleDefinition, err := getRoleDefinitionByRoleName(client, roleBinding.Role, subscriptionID)
-			if err != nil {
-				return errors.Wrap(err, fmt.Sprintf("failed to get role definition for role %s", roleBinding.Role))
-			}
 
 			// Determine if the role definition's ID is already assigned to the user-assigned managed identity
 			// at the specified scope
 			roleAssignmentExists := false
 			for _, roleAssignment := range existingRoleAssignments {
-				if *roleDefinition.Properties.RoleName == roleBinding.Role && *roleAssignment.Properties.RoleDefinitionID == *roleDefinition.ID && *roleAssignment.Properties.Scope == scope {
+				if strings.EqualFold(*roleAssignment.Properties.RoleDefinitionID, *roleDefinition.ID) && *roleAssignment.Properties.Scope == scope {
 					roleAssignmentExists = true
-					log.Printf("Found existing role assignment %s for user-assigned managed identity with principal ID %s at scope %s", roleBinding.Role, managedIdentityPrincipalID, scope)
+					log.Printf("Found existing role assignment %s for user-assigned managed identity with principal ID %s at scope %s", roleName, managedIdentityPrincipalID, scope)
 					shouldExistRoleAssignments = append(shouldExistRoleAssignments, roleAssignment)
 					break
 				}
@@ -166,15 +219,16 @@ func ensureRolesAssignedToManagedIdentity(client *azureclients.AzureClientWrappe
 			if !roleAssignmentExists {
 				// Assign role to identity at scope
 				roleAssignment, err := createRoleAssignment(
+					ctx,
 					client,
 					managedIdentityPrincipalID,
 					*roleDefinition.ID,
-					roleBinding.Role,
+					roleName,
 					scope,
 					subscriptionID,
 				)
 				if err != nil {
-					return errors.Wrapf(err, "failed to assign role %s to user-assigned managed identity", roleBinding.Role)
+					return errors.Wrapf(err, "failed to assign role %s to user-assigned managed identity", roleName)
 				}
 				shouldExistRoleAssignments = append(shouldExistRoleAssignments, roleAssignment)
 			}
@@ -189,11 +243,11 @@ func ensureRolesAssignedToManagedIdentity(client *azureclients.AzureClientWrappe
 			}
 		}
 		if !found {
-			roleDefinition, err := getRoleDefinitionByID(client, *existingRoleAssignment.Properties.RoleDefinitionID)
+			roleDefinition, err := getRoleDefinitionByID(ctx, client, *existingRoleAssignment.Properties.RoleDefinitionID)
 			if err != nil {
 				return errors.Wrapf(err, "failed to get role definition with role definition ID %s", *existingRoleAssignment.Properties.RoleDefinitionID)
 			}
-			err = deleteRoleAssignment(client,
+			err = deleteRoleAssignment(ctx, client,
 				managedIdentityPrincipalID,
 				*existingRoleAssignment.Name,
 				*roleDefinition.Properties.RoleName,
@@ -209,11 +263,107 @@ func ensureRolesAssignedToManagedIdentity(client *azureclients.AzureClientWrappe
 	return nil
 }
 
+// roleDefinitionResolver resolves the role of a RoleBinding to a role definition within the subscription identified
+// by subscriptionID. Resolved role definitions are cached so that every role is looked up only once regardless of
+// the number of CredentialsRequests and scoping resource groups it is assigned for.
+type roleDefinitionResolver struct {
+	client          *azureclients.AzureClientWrapper
+	subscriptionID  string
+	roleDefinitions map[string]*armauthorization.RoleDefinition
+}
+
+func newRoleDefinitionResolver(client *azureclients.AzureClientWrapper, subscriptionID string) *roleDefinitionResolver {
+	return &roleDefinitionResolver{
+		client:          client,
+		subscriptionID:  subscriptionID,
+		roleDefinitions: map[string]*armauthorization.RoleDefinition{},
+	}
+}
+
+// resolve returns the role definition for role, which is either the ID of a role definition or the name of a role.
+// Role definition IDs may be provided as a bare GUID or as a fully qualified ID such as
+// /subscriptions/<subscriptionID>/providers/Microsoft.Authorization/roleDefinitions/<GUID>.
+func (r *roleDefinitionResolver) resolve(ctx context.Context, role string) (*armauthorization.RoleDefinition, error) {
+	if roleDefinition, ok := r.roleDefinitions[role]; ok {
+		return roleDefinition, nil
+	}
+
+	var roleDefinition *armauthorization.RoleDefinition
+	if roleDefinitionID, ok := r.roleDefinitionID(role); ok {
+		var err error
+		roleDefinition, err = getRoleDefinitionByID(ctx, r.client, roleDefinitionID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get role definition with ID %s", roleDefinitionID)
+		}
+		if roleDefinition.ID == nil {
+			roleDefinition.ID = to.Ptr(roleDefinitionID)
+		}
+	} else {
+		var err error
+		roleDefinition, err = getRoleDefinitionByRoleName(ctx, r.client, role, r.subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.roleDefinitions[role] = roleDefinition
+	return roleDefinition, nil
+}
+
+// roleDefinitionID returns the fully qualified role definition ID for role when role is a role definition ID
+func (r *roleDefinitionResolver) roleDefinitionID(role string) (string, bool) {
+	if _, err := uuid.Parse(role); err == nil && len(role) == 36 {
+		return fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", r.subscriptionID, role), true
+	}
+	if strings.HasPrefix(role, "/") && strings.Contains(strings.ToLower(role), "/providers/microsoft.authorization/roledefinitions/") {
+		return role, true
+	}
+	return "", false
+}
+
+// ValidateProviderSpec validates the role bindings of an AzureProviderSpec without contacting Azure. Roles which are
+// role definition IDs must end in a GUID and, when qualified with a subscription, must be defined within the
+// subscription identified by subscriptionID within which roles are assigned. Role names can only be resolved by Azure
+// and are not validated. Errors are identified by their path below fldPath.
+func ValidateProviderSpec(spec *credreqv1.AzureProviderSpec, subscriptionID string, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	resolver := &roleDefinitionResolver{subscriptionID: subscriptionID}
+	for i, roleBinding := range spec.RoleBindings {
+		rolePath := fldPath.Child("roleBindings").Index(i).Child("role")
+		if strings.TrimSpace(roleBinding.Role) == "" {
+			errs = append(errs, field.Required(rolePath, "the name of a role or the ID of a role definition"))
+			continue
+		}
+		if !strings.HasPrefix(roleBinding.Role, "/") {
+			continue
+		}
+		roleDefinitionID, ok := resolver.roleDefinitionID(roleBinding.Role)
+		if _, err := uuid.Parse(roleDefinitionID[strings.LastIndex(roleDefinitionID, "/")+1:]); !ok || err != nil {
+			errs = append(errs, field.Invalid(rolePath, roleBinding.Role,
+				"role definition IDs must be of the form /subscriptions/<subscription-id>/providers/Microsoft.Authorization/roleDefinitions/<GUID>"))
+			continue
+		}
+		segments := strings.Split(roleDefinitionID, "/")
+		if subscriptionID != "" && len(segments) > 2 && strings.EqualFold(segments[1], "subscriptions") && !strings.EqualFold(segments[2], subscriptionID) {
+			errs = append(errs, field.Invalid(rolePath, roleBinding.Role,
+				fmt.Sprintf("role definition is defined within subscription %s which is not the subscription %s within which roles are assigned", segments[2], subscriptionID)))
+		}
+	}
+	return errs
+}
+
+// roleDefinitionName returns the role name of roleDefinition, or role when the role definition has no name
+func roleDefinitionName(roleDefinition *armauthorization.RoleDefinition, role string) string {
+	if roleDefinition.Properties == nil || roleDefinition.Properties.RoleName == nil {
+		return role
+	}
+	return *roleDefinition.Properties.RoleName
+}
+
 // getRoleDefinitionByRoleName lists role definitions within the scope of the Azure subscription identified by subscriptionID
 // and returns the armauthorization.RoleDefinition with a name matching the provided roleName.
 //
 // If multiple roles are found matching the roleName this will result in an error.
-func getRoleDefinitionByRoleName(client *azureclients.AzureClientWrapper, roleName, subscriptionID string) (*armauthorization.RoleDefinition, error) {
+func getRoleDefinitionByRoleName(ctx context.Context, client *azureclients.AzureClientWrapper, roleName, subscriptionID string) (*armauthorization.RoleDefinition, error) {
 	listRoles := client.RoleDefinitionsClient.NewListPager(
 		"/subscriptions/"+subscriptionID,
 		&armauthorization.RoleDefinitionsClientListOptions{
@@ -222,7 +372,7 @@ func getRoleDefinitionByRoleName(client *azureclients.AzureClientWrapper, roleNa
 	)
 	roleDefinitions := make([]*armauthorization.RoleDefinition, 0)
 	for listRoles.More() {
-		pageResponse, err := listRoles.NextPage(context.TODO())
+		pageResponse, err := listRoles.NextPage(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -230,18 +380,24 @@ func getRoleDefinitionByRoleName(client *azureclients.AzureClientWrapper, roleNa
 	}
 	switch len(roleDefinitions) {
 	case 0:
-		return nil, fmt.Errorf("no role found for name %s", roleName)
+		return nil, fmt.Errorf("no role found for name %s within subscription %s", roleName, subscriptionID)
 	case 1:
 		return roleDefinitions[0], nil
 	default:
-		return nil, fmt.Errorf("found %d role definitions for %s, expected one", len(roleDefinitions), roleName)
+		roleDefinitionIDs := []string{}
+		for _, roleDefinition := range roleDefinitions {
+			if roleDefinition.ID != nil {
+				roleDefinitionIDs = append(roleDefinitionIDs, *roleDefinition.ID)
+			}
+		}
+		return nil, fmt.Errorf("found %d role definitions for %s within subscription %s, expected one. Specify the role by role definition ID instead, one of: %s", len(roleDefinitions), roleName, subscriptionID, strings.Join(roleDefinitionIDs, ", "))
 	}
 }
 
 // getRoleDefinitionByID gets the role definition identified by roleDefinitionID.
-func getRoleDefinitionByID(client *azureclients.AzureClientWrapper, roleDefinitionID string) (*armauthorization.RoleDefinition, error) {
+func getRoleDefinitionByID(ctx context.Context, client *azureclients.AzureClientWrapper, roleDefinitionID string) (*armauthorization.RoleDefinition, error) {
 	roleDefinitionGetResp, err := client.RoleDefinitionsClient.GetByID(
-		context.Background(),
+		ctx,
 		roleDefinitionID,
 		&armauthorization.RoleDefinitionsClientGetByIDOptions{},
 	)
@@ -256,17 +412,22 @@ func getRoleDefinitionByID(client *azureclients.AzureClientWrapper, roleDefiniti
 //
 // Scope is a string such as /subscriptions/<subscriptionID> which represents anything within the subscription.
 // This scope can be restricted within a resourceGroup such as /subscriptions/<subscriptionID>/resourceGroups/<resourceGroupName>.
-func createRoleAssignment(client *azureclients.AzureClientWrapper, managedIdentityPrincipalID, roleID, roleName, scope, subscriptionID string) (*armauthorization.RoleAssignment, error) {
+func createRoleAssignment(ctx context.Context, client *azureclients.AzureClientWrapper, managedIdentityPrincipalID, roleID, roleName, scope, subscriptionID string) (*armauthorization.RoleAssignment, error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.CreateRoleAssignment",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("roleAssignment"),
+		provisioning.ResourceNameAttribute.String(roleName),
+	)
+	defer span.End()
+
 	// Create a unique name for the role assignment
 	roleAssignmentName := uuid.New().String()
 
-	var rawResponse *http.Response
+	var roleAssignment *armauthorization.RoleAssignment
 	// Role assignment can fail due to a replication delay after creating the user-assigned managed identity
-	// Try up to 6 times with a 10 second delay between each attempt, up to 1 minute.
-	for i := 0; i < 6; i++ {
-		ctxWithResp := runtime.WithCaptureResponse(context.Background(), &rawResponse)
+	err := retryOnPropagationDelay(fmt.Sprintf("assigning role %s", roleName), func() error {
 		roleAssignmentCreateResponse, err := client.RoleAssignmentClient.Create(
-			ctxWithResp,
+			ctx,
 			scope,
 			roleAssignmentName,
 			armauthorization.RoleAssignmentCreateParameters{
@@ -279,32 +440,52 @@ func createRoleAssignment(client *azureclients.AzureClientWrapper, managedIdenti
 		)
 		if err != nil {
 			var respErr *azcore.ResponseError
-			if errors.As(err, &respErr) {
-				if respErr.ErrorCode == "PrincipalNotFound" {
-					// The identity ccoctl just created can't be found yet due to a replication delay so we need to retry.
-					if i == 5 {
-						log.Fatal("Timed out assigning role to user-assigned managed identity, this is most likely due to a replication delay following creation of the user-assigned managed identity, please retry")
-						break
-					} else {
-						log.Printf("Unable to assign role to user-assigned managed identity, retrying...")
-						time.Sleep(10 * time.Second)
-						continue
-					}
-				} else if respErr.ErrorCode == "RoleAssignmentExists" {
-					log.Printf("Found existing role assignment %s for user-assigned managed identity with principal ID %s at scope %s", roleName, managedIdentityPrincipalID, scope)
-					break
-				} else {
-					return nil, err
-				}
-			} else {
-				return nil, err
+			if errors.As(err, &respErr) && respErr.ErrorCode == "RoleAssignmentExists" {
+				log.Printf("Found existing role assignment %s for user-assigned managed identity with principal ID %s at scope %s", roleName, managedIdentityPrincipalID, scope)
+				return nil
 			}
-		} else {
-			log.Printf("Created role assignment for role %s with user-assigned managed identity principal ID %s at scope %s", roleName, managedIdentityPrincipalID, scope)
-			return &roleAssignmentCreateResponse.RoleAssignment, nil
+			return err
+		}
+		log.Printf("Created role assignment for role %s with user-assigned managed identity principal ID %s at scope %s", roleName, managedIdentityPrincipalID, scope)
+		roleAssignment = &roleAssignmentCreateResponse.RoleAssignment
+		provisioning.EmitResourceEvent("RoleAssignment", stringValue(roleAssignment.ID), provisioning.ResourceCreated)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roleAssignment, nil
+}
+
+// isPropagationError returns true when err indicates that a newly created user-assigned managed identity, or its
+// service principal, has not yet been replicated such that it could be found by the API returning the error.
+func isPropagationError(err error) bool {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	switch respErr.ErrorCode {
+	case "PrincipalNotFound", "ParentResourceNotFound":
+		return true
+	}
+	return false
+}
+
+// retryOnPropagationDelay calls fn until it succeeds or returns an error which does not indicate a replication delay
+// following creation of a user-assigned managed identity. An error is returned once propagationTimeout has elapsed.
+func retryOnPropagationDelay(operation string, fn func() error) error {
+	deadline := time.Now().Add(propagationTimeout)
+	for {
+		err := fn()
+		if err == nil || !isPropagationError(err) {
+			return err
+		}
+		if time.Now().Add(propagationRetryInterval).After(deadline) {
+			return errors.Wrapf(err, "timed out after %s waiting for the user-assigned managed identity to propagate while %s, please retry", propagationTimeout, operation)
 		}
+		log.Printf("Waiting %s for the user-assigned managed identity to propagate before retrying %s", propagationRetryInterval, operation)
+		time.Sleep(propagationRetryInterval)
 	}
-	return nil, nil
 }
 
 // deleteRoleAssignment deletes the Azure role assignment with roleID and scope from the managed identity
@@ -312,9 +493,16 @@ func createRoleAssignment(client *azureclients.AzureClientWrapper, managedIdenti
 //
 // Scope is a string such as /subscriptions/<subscriptionID> which represents anything within the subscription.
 // This scope can be restricted within a resourceGroup such as /subscriptions/<subscriptionID>/resourceGroups/<resourceGroupName>.
-func deleteRoleAssignment(client *azureclients.AzureClientWrapper, managedIdentityPrincipalID, roleID, roleName, scope, subscriptionID string) error {
+func deleteRoleAssignment(ctx context.Context, client *azureclients.AzureClientWrapper, managedIdentityPrincipalID, roleID, roleName, scope, subscriptionID string) error {
+	ctx, span := provisioning.StartSpan(ctx, "azure.DeleteRoleAssignment",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("roleAssignment"),
+		provisioning.ResourceNameAttribute.String(roleName),
+	)
+	defer span.End()
+
 	_, err := client.RoleAssignmentClient.Delete(
-		context.Background(),
+		ctx,
 		scope,
 		roleID,
 		&armauthorization.RoleAssignmentsClientDeleteOptions{},
@@ -323,6 +511,7 @@ func deleteRoleAssignment(client *azureclients.AzureClientWrapper, managedIdenti
 		return err
 	}
 	log.Printf("Deleted role assignment for role %s with user-assigned managed identity with principal ID %s at scope %s", roleName, managedIdentityPrincipalID, scope)
+	provisioning.EmitResourceEvent("RoleAssignment", fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignments/%s", scope, roleID), provisioning.ResourceDeleted)
 	return nil
 }
 
@@ -330,11 +519,21 @@ func deleteRoleAssignment(client *azureclients.AzureClientWrapper, managedIdenti
 // within the provided resourceGroup
 //
 // resourceTags will be updated to match those provided to ensureUserAssignedManagedIdentity if found to be different on the existing user-assigned managed identity.
-func ensureUserAssignedManagedIdentity(client *azureclients.AzureClientWrapper, managedIdentityName, resourceGroupName, region string, resourceTags map[string]string) (*armmsi.Identity, error) {
+//
+// The returned bool is true when the user-assigned managed identity already existed.
+func ensureUserAssignedManagedIdentity(ctx context.Context, client *azureclients.AzureClientWrapper, managedIdentityName, resourceGroupName, region string, resourceTags map[string]string) (*armmsi.Identity, bool, error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.EnsureUserAssignedManagedIdentity",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("userAssignedManagedIdentity"),
+		provisioning.ResourceNameAttribute.String(managedIdentityName),
+		provisioning.RegionAttribute.String(region),
+	)
+	defer span.End()
+
 	// Check if user-assigned managed identity exists
 	needToCreateUserAssignedManagedIdentity := false
 	var rawResponse *http.Response
-	ctxWithResp := runtime.WithCaptureResponse(context.Background(), &rawResponse)
+	ctxWithResp := runtime.WithCaptureResponse(ctx, &rawResponse)
 	getUserAssignedManagedIdentityResp, err := client.UserAssignedIdentitiesClient.Get(
 		ctxWithResp,
 		resourceGroupName,
@@ -348,19 +547,22 @@ func ensureUserAssignedManagedIdentity(client *azureclients.AzureClientWrapper,
 				// User-assigned managed identity wasn't found and will need to be created
 				needToCreateUserAssignedManagedIdentity = true
 			default:
-				return nil, errors.Wrapf(err, "unable to get user-assigned managed identity")
+				return nil, false, errors.Wrapf(err, "unable to get user-assigned managed identity")
 			}
 		} else {
-			return nil, err
+			return nil, false, err
 		}
 	}
 
 	mergedResourceTags, needToUpdateUserAssignedManagedIdentity := mergeResourceTags(resourceTags, getUserAssignedManagedIdentityResp.Tags)
+	if needToCreateUserAssignedManagedIdentity {
+		addCreationTimestampTag(mergedResourceTags)
+	}
 
 	// Found and validated existing user-assigned managed identity
 	if !needToCreateUserAssignedManagedIdentity && !needToUpdateUserAssignedManagedIdentity {
 		log.Printf("Found existing user-assigned managed identity %s", *getUserAssignedManagedIdentityResp.Identity.ID)
-		return &getUserAssignedManagedIdentityResp.Identity, nil
+		return &getUserAssignedManagedIdentityResp.Identity, true, nil
 	}
 
 	identityParameters := armmsi.Identity{
@@ -369,57 +571,117 @@ func ensureUserAssignedManagedIdentity(client *azureclients.AzureClientWrapper,
 	}
 
 	userAssignedManagedIdentity, err := client.UserAssignedIdentitiesClient.CreateOrUpdate(
-		context.Background(),
+		ctx,
 		resourceGroupName,
 		managedIdentityName,
 		identityParameters,
 		&armmsi.UserAssignedIdentitiesClientCreateOrUpdateOptions{},
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create user-assigned managed identity")
+		return nil, false, errors.Wrap(err, "failed to create user-assigned managed identity")
 	}
 	verb := "Updated"
 	if needToCreateUserAssignedManagedIdentity {
 		verb = "Created"
 	}
 	log.Printf("%s user-assigned managed identity %s", verb, *userAssignedManagedIdentity.ID)
-	return &userAssignedManagedIdentity.Identity, nil
+	provisioning.EmitResourceEvent("UserAssignedManagedIdentity", *userAssignedManagedIdentity.ID, strings.ToLower(verb))
+	return &userAssignedManagedIdentity.Identity, !needToCreateUserAssignedManagedIdentity, nil
+}
+
+// ensureFederatedIdentityCredentials ensures that a federated identity credential exists within the user-assigned
+// managed identity identified by managedIdentityName for each of serviceAccountNames.
+//
+// The federated identity credentials of a preExisting user-assigned managed identity are enumerated first so that a
+// re-run after a prior run failed between creating the user-assigned managed identity and creating all of its federated
+// identity credentials only creates the missing credentials. The federated identity credentials found and created are
+// then logged.
+func ensureFederatedIdentityCredentials(ctx context.Context, client *azureclients.AzureClientWrapper, managedIdentityName, issuerURL, serviceAccountNamespace string, serviceAccountNames []string, resourceGroupName string, preExisting bool) error {
+	existingCredentials := map[string]*armmsi.FederatedIdentityCredential{}
+	if preExisting {
+		var err error
+		existingCredentials, err = listFederatedIdentityCredentials(ctx, client, resourceGroupName, managedIdentityName)
+		if err != nil {
+			return err
+		}
+	}
+
+	var found, created, updated []string
+	for _, serviceAccountName := range serviceAccountNames {
+		status, err := ensureFederatedIdentityCredential(ctx, client, managedIdentityName, issuerURL, serviceAccountNamespace, serviceAccountName, resourceGroupName, existingCredentials[serviceAccountName])
+		if err != nil {
+			return err
+		}
+		switch status {
+		case provisioning.ResourceCreated:
+			created = append(created, serviceAccountName)
+		case provisioning.ResourceUpdated:
+			updated = append(updated, serviceAccountName)
+		default:
+			found = append(found, serviceAccountName)
+		}
+	}
+
+	if preExisting {
+		log.Printf("Reconciled the federated identity credentials of pre-existing user-assigned managed identity %s: found %s, created %s, updated %s",
+			managedIdentityName, serviceAccountNamesOrNone(found), serviceAccountNamesOrNone(created), serviceAccountNamesOrNone(updated))
+	}
+	return nil
+}
+
+// listFederatedIdentityCredentials returns the federated identity credentials within the user-assigned managed
+// identity identified by managedIdentityName keyed by name.
+func listFederatedIdentityCredentials(ctx context.Context, client *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string) (map[string]*armmsi.FederatedIdentityCredential, error) {
+	credentials := map[string]*armmsi.FederatedIdentityCredential{}
+	listFederatedCredentials := client.FederatedIdentityCredentialsClient.NewListPager(
+		resourceGroupName,
+		managedIdentityName,
+		&armmsi.FederatedIdentityCredentialsClientListOptions{},
+	)
+	for listFederatedCredentials.More() {
+		pageResponse, err := listFederatedCredentials.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list federated identity credentials of user-assigned managed identity %s", managedIdentityName)
+		}
+		for _, credential := range pageResponse.FederatedIdentityCredentialsListResult.Value {
+			if credential.Name != nil {
+				credentials[*credential.Name] = credential
+			}
+		}
+	}
+	return credentials, nil
+}
+
+// serviceAccountNamesOrNone joins serviceAccountNames for logging, "none" when empty.
+func serviceAccountNamesOrNone(serviceAccountNames []string) string {
+	if len(serviceAccountNames) == 0 {
+		return "none"
+	}
+	return strings.Join(serviceAccountNames, ", ")
 }
 
 // ensureFederatedIdentityCredential creates an Azure federated identity credential within the user-assigned managed
-// identity identified by managedIdentityName.
+// identity identified by managedIdentityName, or updates existingCredential, the federated identity credential of
+// serviceAccountName found within the user-assigned managed identity if any, when it differs from the expected one.
 //
 // Federated identity credentials are limited to a specific kubernetes service account by providing the service account's
 // name and namespace. The issuerURL of the OIDC endpoint hosting OIDC discovery and JWKS (public key information) documents
 // must also be known to establish trust from a token signed by the OIDC endpoint's matching private key.
-func ensureFederatedIdentityCredential(client *azureclients.AzureClientWrapper, managedIdentityName, issuerURL, serviceAccountNamespace, serviceAccountName, resourceGroupName string) error {
+//
+// The returned status is provisioning.ResourceCreated or provisioning.ResourceUpdated when the federated identity
+// credential was created or updated, and empty when existingCredential was found as expected.
+func ensureFederatedIdentityCredential(ctx context.Context, client *azureclients.AzureClientWrapper, managedIdentityName, issuerURL, serviceAccountNamespace, serviceAccountName, resourceGroupName string, existingCredential *armmsi.FederatedIdentityCredential) (string, error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.EnsureFederatedIdentityCredential",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("federatedIdentityCredential"),
+		provisioning.ResourceNameAttribute.String(serviceAccountName),
+	)
+	defer span.End()
+
 	var (
-		rawResponse                             *http.Response
-		needToCreateFederatedIdentityCredential bool
+		needToCreateFederatedIdentityCredential = existingCredential == nil
 		needToUpdateFederatedIdentityCredential bool
 	)
-	ctxWithResp := runtime.WithCaptureResponse(context.Background(), &rawResponse)
-	federatedIdentityCredentialGetResp, err := client.FederatedIdentityCredentialsClient.Get(
-		ctxWithResp,
-		resourceGroupName,
-		managedIdentityName,
-		serviceAccountName,
-		&armmsi.FederatedIdentityCredentialsClientGetOptions{},
-	)
-	if err != nil {
-		var respErr *azcore.ResponseError
-		if errors.As(err, &respErr) {
-			switch respErr.ErrorCode {
-			case "NotFound":
-				// Federated identity credential wasn't found and will need to be created
-				needToCreateFederatedIdentityCredential = true
-			default:
-				return errors.Wrapf(err, "unable to get federated identity credential")
-			}
-		} else {
-			return err
-		}
-	}
 
 	federatedIdentityCredentialParameters := armmsi.FederatedIdentityCredential{
 		Properties: &armmsi.FederatedIdentityCredentialProperties{
@@ -432,16 +694,19 @@ func ensureFederatedIdentityCredential(client *azureclients.AzureClientWrapper,
 	}
 
 	if !needToCreateFederatedIdentityCredential {
-		existingCredential := federatedIdentityCredentialGetResp.FederatedIdentityCredential
-		if len(existingCredential.Properties.Audiences) != len(federatedIdentityCredentialParameters.Properties.Audiences) ||
+		if existingCredential.Properties == nil || existingCredential.Properties.Issuer == nil || existingCredential.Properties.Subject == nil ||
+			len(existingCredential.Properties.Audiences) != len(federatedIdentityCredentialParameters.Properties.Audiences) ||
 			*existingCredential.Properties.Issuer != *federatedIdentityCredentialParameters.Properties.Issuer ||
 			*existingCredential.Properties.Subject != *federatedIdentityCredentialParameters.Properties.Subject {
 			needToUpdateFederatedIdentityCredential = true
 		}
 		for _, expectedAudience := range federatedIdentityCredentialParameters.Properties.Audiences {
+			if needToUpdateFederatedIdentityCredential {
+				break
+			}
 			found := false
 			for _, existingAudience := range existingCredential.Properties.Audiences {
-				if *expectedAudience == *existingAudience {
+				if existingAudience != nil && *expectedAudience == *existingAudience {
 					found = true
 				}
 			}
@@ -451,35 +716,84 @@ func ensureFederatedIdentityCredential(client *azureclients.AzureClientWrapper,
 		}
 	}
 	if !needToCreateFederatedIdentityCredential && !needToUpdateFederatedIdentityCredential {
-		log.Printf("Found existing federated identity credential %s", *federatedIdentityCredentialGetResp.FederatedIdentityCredential.ID)
-		return nil
+		log.Printf("Found existing federated identity credential %s", stringValue(existingCredential.ID))
+		return "", nil
 	}
 
-	federatedIdentityCredential, err := client.FederatedIdentityCredentialsClient.CreateOrUpdate(
-		context.Background(),
-		resourceGroupName,
-		managedIdentityName,
-		serviceAccountName,
-		federatedIdentityCredentialParameters,
-		&armmsi.FederatedIdentityCredentialsClientCreateOrUpdateOptions{})
+	var federatedIdentityCredential armmsi.FederatedIdentityCredentialsClientCreateOrUpdateResponse
+	// Creating a federated identity credential can fail due to a replication delay after creating the user-assigned managed identity
+	err := retryOnPropagationDelay(fmt.Sprintf("creating federated identity credential %s", serviceAccountName), func() error {
+		var err error
+		federatedIdentityCredential, err = client.FederatedIdentityCredentialsClient.CreateOrUpdate(
+			ctx,
+			resourceGroupName,
+			managedIdentityName,
+			serviceAccountName,
+			federatedIdentityCredentialParameters,
+			&armmsi.FederatedIdentityCredentialsClientCreateOrUpdateOptions{})
+		return err
+	})
 	if err != nil {
-		return errors.Wrap(err, "failed to create or update federated identity credential")
+		return "", errors.Wrap(err, "failed to create or update federated identity credential")
+	}
+	// Azure rejects the tokens of any issuer other than the issuer of the federated identity credential it stored
+	if properties := federatedIdentityCredential.Properties; properties != nil && properties.Issuer != nil && *properties.Issuer != issuerURL {
+		return "", fmt.Errorf("federated identity credential %s was stored with issuer %s rather than %s", serviceAccountName, *properties.Issuer, issuerURL)
 	}
 	verb := "Updated"
 	if needToCreateFederatedIdentityCredential {
 		verb = "Created"
 	}
 	log.Printf("%s federated identity credential %s", verb, *federatedIdentityCredential.ID)
+	provisioning.EmitResourceEvent("FederatedIdentityCredential", *federatedIdentityCredential.ID, strings.ToLower(verb))
+	return strings.ToLower(verb), nil
+}
+
+// verifyFederatedCredentialIssuer verifies that issuerURL, the issuer of the federated identity credentials, exactly
+// equals discoveryDocumentIssuer, the issuer claimed by the OIDC discovery document. Azure requires the issuer of a
+// service account token to exactly equal the issuer of the federated identity credential, such that any difference,
+// eg. a trailing slash, would break the exchange of tokens without any error when the credentials are created.
+func verifyFederatedCredentialIssuer(issuerURL, discoveryDocumentIssuer string) error {
+	if issuerURL != discoveryDocumentIssuer {
+		return fmt.Errorf("issuer URL %s of the federated identity credentials does not exactly equal the issuer %s of the OIDC discovery document, "+
+			"Azure would reject the service account tokens of the cluster", issuerURL, discoveryDocumentIssuer)
+	}
 	return nil
 }
 
+// verifyDiscoveryDocumentIssuer verifies that issuerURL exactly equals the issuer claimed by the OIDC discovery
+// document of the issuer. The discovery document written to outputDir by uploadOIDCDocuments is verified when outputDir
+// is provided and holds one. Otherwise the discovery document served by the issuer is fetched, unless dryRun, and only a
+// warning is logged when it can't be, eg. as the issuer is not reachable from where ccoctl runs.
+func verifyDiscoveryDocumentIssuer(issuerURL, outputDir string, dryRun bool) error {
+	var discoveryDocumentIssuer string
+	discoveryDocumentPath := filepath.Join(outputDir, openidConfigurationFileName)
+	data, err := os.ReadFile(discoveryDocumentPath)
+	switch {
+	case outputDir != "" && err == nil:
+		if discoveryDocumentIssuer, err = provisioning.DiscoveryDocumentIssuer(data); err != nil {
+			return errors.Wrapf(err, "invalid OIDC discovery document at path %s", discoveryDocumentPath)
+		}
+	case outputDir != "" && !os.IsNotExist(err):
+		return errors.Wrapf(err, "failed to read OIDC discovery document at path %s", discoveryDocumentPath)
+	case dryRun:
+		return nil
+	default:
+		if discoveryDocumentIssuer, err = provisioning.FetchDiscoveryDocumentIssuer(issuerURL); err != nil {
+			provisioning.Warnf("Unable to verify that issuer URL %s equals the issuer of the OIDC discovery document: %s", issuerURL, err)
+			return nil
+		}
+	}
+	return verifyFederatedCredentialIssuer(issuerURL, discoveryDocumentIssuer)
+}
+
 // writeCredReqSecret writes a secret file within the manifests directory (outputDir/manifests/)
 // containing user-assigned managed identity details.
-func writeCredReqSecret(cr *credreqv1.CredentialsRequest, outputDir, clientID, tenantID, subscriptionID, region string) error {
+func writeCredReqSecret(cr *credreqv1.CredentialsRequest, outputDir, clientID, tenantID, subscriptionID, region, issuerURL string) error {
 	manifestsDir := filepath.Join(outputDir, provisioning.ManifestsDirName)
 	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
 	filePath := filepath.Join(manifestsDir, fileName)
-	fileData := fmt.Sprintf(secretManifestTemplate, clientID, tenantID, region, subscriptionID, provisioning.OidcTokenPath, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
+	fileData := fmt.Sprintf(secretManifestTemplate, clientID, tenantID, region, subscriptionID, provisioning.OidcTokenPath, provisioning.ProvenanceAnnotations(cr, issuerURL), cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
 
 	// clientID would be an empty string if ccoctl was in --dry-run mode
 	// so lets make sure we have an invalid Secret until the user
@@ -488,7 +802,7 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, outputDir, clientID, t
 		fileData = fileData + "\nPOPULATE CLIENT ID AND TENANT ID AND DELETE THIS LINE"
 	}
 
-	if err := os.WriteFile(filePath, []byte(fileData), 0600); err != nil {
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
 		return errors.Wrapf(err, "failed to save secret file at path %s", filePath)
 	}
 	log.Printf("Saved credentials configuration to: %s", filePath)
@@ -496,9 +810,29 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, outputDir, clientID, t
 	return nil
 }
 
+// writeDryRunCredReqSecret writes a secret file within the manifests directory (outputDir/manifests/) for a
+// user-assigned managed identity that would have been created with managedIdentityName. The secret is annotated
+// as a dry-run artifact and must have its client ID and tenant ID populated before it can be applied.
+func writeDryRunCredReqSecret(cr *credreqv1.CredentialsRequest, outputDir, managedIdentityName, subscriptionID, region, issuerURL string) error {
+	manifestsDir := filepath.Join(outputDir, provisioning.ManifestsDirName)
+	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+	filePath := filepath.Join(manifestsDir, fileName)
+	annotations := provisioning.ProvenanceAnnotations(cr, issuerURL) + provisioning.DryRunAnnotations(map[string]string{managedIdentityNameAnnotation: managedIdentityName})
+	fileData := fmt.Sprintf(secretManifestTemplate, "", "", region, subscriptionID, provisioning.OidcTokenPath, annotations, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
+	fileData = fileData + "\nPOPULATE CLIENT ID AND TENANT ID AND DELETE THIS LINE"
+
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
+		return errors.Wrapf(err, "failed to save secret file at path %s", filePath)
+	}
+	log.Printf("Saved dry-run credentials configuration for user-assigned managed identity %s to: %s", managedIdentityName, filePath)
+
+	return nil
+}
+
 // createManagedIdentities creates user-assigned managed identities for each CredentialsRequest found within the creqReqDir.
 //
-// User-assigned managed identities are created within the resource group identified by oidcResourceGroupName.
+// User-assigned managed identities are created within the resource group identified by identityResourceGroupName, which
+// must have been ensured with ensureIdentityResourceGroup.
 //
 // Roles listed within the CredentialsRequest (spec.providerSpec.roleBindings) will be assigned to created user-assigned
 // managed identities and role assignment will be scoped to the resource group identified by installationResourceGroupName
@@ -508,53 +842,450 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, outputDir, clientID, t
 // additionally scoped within the resource group identified by dnsZoneResourceGroupName.
 //
 // Kubernetes secrets containing the user-assigned managed identity's clientID will be generated and written to the outputDir.
-func createManagedIdentities(client *azureclients.AzureClientWrapper, credReqDir, name, oidcResourceGroupName, subscriptionID, region, issuerURL, outputDir, installationResourceGroupName, dnsZoneResourceGroupName string, resourceTags map[string]string, enableTechPreview, dryRun bool) error {
+//
+// When onlyMissing is set, the CredentialsRequests whose user-assigned managed identity already exists are skipped,
+// leaving the user-assigned managed identity and its role assignments untouched and writing no secret manifest for it.
+//
+// Progress will be recorded within the provided createProgress, which may be nil, after the installation resource group
+// is ensured and after each user-assigned managed identity is created. Previously completed steps will be skipped when resuming.
+func createManagedIdentities(ctx context.Context, client *azureclients.AzureClientWrapper, credReqDirs []string, name, ownedTagValue, identityResourceGroupName, subscriptionID, region, issuerURL, outputDir, installationResourceGroupName, dnsZoneResourceGroupName string, resourceTags map[string]string, enableTechPreview, dryRun, onlyMissing bool, progress *createProgress) error {
 	// Add CCO's "owned" tag to resource tags map
-	resourceTags[fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, name)] = ownedAzureResourceTagValue
+	resourceTags[ownedResourceTagKey(name)] = ownedTagValue
 
 	// Ensure the installation resource group exists
 	if !dryRun {
-		err := ensureResourceGroup(client, installationResourceGroupName, region, resourceTags)
+		provisioning.SetPhase("ensuring the installation resource group " + installationResourceGroupName)
+		skip, err := progress.skip(installationResourceGroupStep, func(step *provisioning.InventoryStep) error {
+			_, err := client.ResourceGroupsClient.Get(ctx, installationResourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+			return err
+		})
 		if err != nil {
-			return errors.Wrap(err, "failed to ensure resource group")
+			return err
+		}
+		if !skip {
+			err = ensureResourceGroup(ctx, client, installationResourceGroupName, region, resourceTags)
+			if err != nil {
+				return errors.Wrap(err, "failed to ensure resource group")
+			}
+			err = progress.complete(installationResourceGroupStep, provisioning.InventoryResource{
+				Type: "ResourceGroup",
+				Name: installationResourceGroupName,
+				ID:   resourceGroupID(subscriptionID, installationResourceGroupName),
+			})
+			if err != nil {
+				return err
+			}
 		}
 		log.Printf("Cluster installation resource group name is %s. This resource group MUST be configured as the resource group used for cluster installation.", installationResourceGroupName)
 	}
 
 	// Process directory containing CredentialsRequests object manifests into list of CredentialsRequests objects
-	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDir, enableTechPreview)
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
 	if err != nil {
 		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
 	}
 
+	if onlyMissing {
+		credentialsRequests, err = credentialsRequestsWithoutManagedIdentity(ctx, client, name, ownedTagValue, identityResourceGroupName, credentialsRequests)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create user-assigned managed identities for each CredentialsRequest
+	roleDefinitions := newRoleDefinitionResolver(client, subscriptionID)
+	for _, credentialsRequest := range credentialsRequests {
+		scopingResourceGroupNames := scopingResourceGroupNamesFor(credentialsRequest, installationResourceGroupName, dnsZoneResourceGroupName)
+		step := managedIdentityStepPrefix + credentialsRequest.Spec.SecretRef.Namespace + "/" + credentialsRequest.Spec.SecretRef.Name
+		provisioning.SetPhase(fmt.Sprintf("creating the user-assigned managed identity for %s/%s", credentialsRequest.Spec.SecretRef.Namespace, credentialsRequest.Spec.SecretRef.Name))
+		identityName, err := managedIdentityName(name, credentialsRequest)
+		if err != nil {
+			return err
+		}
+		skip, err := progress.skip(step, func(step *provisioning.InventoryStep) error {
+			return validateManagedIdentity(ctx, client, identityName, identityResourceGroupName, outputDir, credentialsRequest)
+		})
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+		err = createManagedIdentity(ctx, client, roleDefinitions, name, identityResourceGroupName, subscriptionID, region, issuerURL, outputDir, scopingResourceGroupNames, resourceTags, credentialsRequest, dryRun)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			err = progress.complete(step, managedIdentityResources(subscriptionID, identityResourceGroupName, identityName, credentialsRequest)...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if dryRun {
+		log.Printf("Dry run complete, no Azure resources were created or modified. Manifests which would have been applied were saved to %s", filepath.Join(outputDir, provisioning.ManifestsDirName))
+	}
+
+	return nil
+}
+
+// ensureIdentityResourceGroup ensures the resource group identified by identityResourceGroupName within which
+// user-assigned managed identities are created, when it is not the OIDC resource group identified by
+// oidcResourceGroupName which is ensured along with the OIDC issuer. The resource group is created when create is
+// set, otherwise it must pre-exist. Either way it is recorded within the provided createProgress, which may be nil,
+// so that it is known to teardown. A created resource group carries CCO's "owned" tag for name. With dryRun a resource group which would be created is only logged.
+func ensureIdentityResourceGroup(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, identityResourceGroupName, oidcResourceGroupName, subscriptionID, region string, resourceTags map[string]string, create, dryRun bool, progress *createProgress) error {
+	if identityResourceGroupName == oidcResourceGroupName {
+		return nil
+	}
+	// Add CCO's "owned" tag to resource tags map
+	resourceTags[ownedResourceTagKey(name)] = ownedTagValue
+	provisioning.SetPhase("ensuring the identity resource group " + identityResourceGroupName)
+	skip, err := progress.skip(identityResourceGroupStep, func(step *provisioning.InventoryStep) error {
+		_, err := client.ResourceGroupsClient.Get(ctx, identityResourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+		return err
+	})
+	if err != nil || skip {
+		return err
+	}
+
+	switch {
+	case create && dryRun:
+		log.Printf("Would ensure identity resource group %s", identityResourceGroupName)
+		return nil
+	case create:
+		if err := ensureResourceGroup(ctx, client, identityResourceGroupName, region, resourceTags); err != nil {
+			return errors.Wrap(err, "failed to ensure identity resource group")
+		}
+	default:
+		_, err := client.ResourceGroupsClient.Get(ctx, identityResourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) && respErr.ErrorCode == "ResourceGroupNotFound" {
+				return fmt.Errorf("identity resource group %s does not exist, create it or provide --create-identity-resource-group", identityResourceGroupName)
+			}
+			return errors.Wrapf(err, "unable to get identity resource group %s", identityResourceGroupName)
+		}
+		if dryRun {
+			return nil
+		}
+	}
+	log.Printf("Creating user-assigned managed identities within identity resource group %s", identityResourceGroupName)
+	progress.recordSetting(identityResourceGroupSetting, identityResourceGroupName)
+	return progress.complete(identityResourceGroupStep, provisioning.InventoryResource{
+		Type: "ResourceGroup",
+		Name: identityResourceGroupName,
+		ID:   resourceGroupID(subscriptionID, identityResourceGroupName),
+	})
+}
+
+// validateIdentityResourceGroupName defaults the identity resource group of opts to its OIDC resource group, and
+// ensures that user-assigned managed identities are not created within the installation resource group which the
+// OpenShift installer requires to be empty
+func validateIdentityResourceGroupName(opts *azureOptions) error {
+	if opts.IdentityResourceGroupName == "" {
+		if opts.CreateIdentityResourceGroup {
+			return errors.New("--create-identity-resource-group requires --identity-resource-group-name")
+		}
+		opts.IdentityResourceGroupName = opts.OIDCResourceGroupName
+		return nil
+	}
+	if opts.IdentityResourceGroupName == opts.InstallationResourceGroupName {
+		return fmt.Errorf("invalid --identity-resource-group-name %s, user-assigned managed identities may not be created within the installation resource group which must be empty",
+			opts.IdentityResourceGroupName)
+	}
+	return nil
+}
+
+// credentialsRequestsWithoutManagedIdentity returns the CredentialsRequests of credentialsRequests for which no
+// user-assigned managed identity exists within the resource group identified by resourceGroupName, logging the
+// CredentialsRequests skipped as their user-assigned managed identity carrying CCO's "owned" tag for name exists. An
+// error is returned when a user-assigned managed identity named after a CredentialsRequest exists without the tag.
+func credentialsRequestsWithoutManagedIdentity(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, resourceGroupName string, credentialsRequests []*credreqv1.CredentialsRequest) ([]*credreqv1.CredentialsRequest, error) {
+	missing := []*credreqv1.CredentialsRequest{}
 	for _, credentialsRequest := range credentialsRequests {
-		// Scope user-assigned managed identity within the installationResourceGroupName
-		scopingResourceGroupNames := []string{installationResourceGroupName}
-		// Additionally scope the ingress CredentialsRequest within the dnsZoneResourceGroupName
-		if credentialsRequest.Name == ingressCredentialRequestName {
-			scopingResourceGroupNames = append(scopingResourceGroupNames, dnsZoneResourceGroupName)
+		identityName, err := managedIdentityName(name, credentialsRequest)
+		if err != nil {
+			return nil, err
+		}
+		identity, err := client.UserAssignedIdentitiesClient.Get(
+			ctx,
+			resourceGroupName,
+			identityName,
+			&armmsi.UserAssignedIdentitiesClientGetOptions{})
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) && (respErr.ErrorCode == "ResourceNotFound" || respErr.ErrorCode == "ResourceGroupNotFound") {
+				log.Printf("Creating user-assigned managed identity %s for CredentialsRequest %s/%s which has no user-assigned managed identity", identityName, credentialsRequest.Namespace, credentialsRequest.Name)
+				missing = append(missing, credentialsRequest)
+				continue
+			}
+			return nil, errors.Wrapf(err, "unable to get user-assigned managed identity %s", identityName)
+		}
+		if !hasOwnedResourceTag(identity.Tags, name, ownedTagValue) {
+			return nil, fmt.Errorf("user-assigned managed identity %s of CredentialsRequest %s/%s exists but does not carry the tag %s=%s, it was not created by ccoctl for %s",
+				identityName, credentialsRequest.Namespace, credentialsRequest.Name, ownedResourceTagKey(name), ownedTagValue, name)
+		}
+		log.Printf("Skipping CredentialsRequest %s/%s, user-assigned managed identity %s already exists", credentialsRequest.Namespace, credentialsRequest.Name, identityName)
+	}
+	log.Printf("Creating user-assigned managed identities for %d CredentialsRequests, skipped %d CredentialsRequests whose user-assigned managed identities exist", len(missing), len(credentialsRequests)-len(missing))
+	return missing, nil
+}
+
+// managedIdentityResources returns the inventory resources recording the user-assigned managed identity
+// managedIdentityName created for credentialsRequest and its federated identity credentials
+func managedIdentityResources(subscriptionID, resourceGroupName, managedIdentityName string, credentialsRequest *credreqv1.CredentialsRequest) []provisioning.InventoryResource {
+	identityID := managedIdentityID(subscriptionID, resourceGroupName, managedIdentityName)
+	resources := []provisioning.InventoryResource{{
+		Type: "UserAssignedManagedIdentity",
+		Name: managedIdentityName,
+		ID:   identityID,
+	}}
+	for _, serviceAccountName := range credentialsRequest.Spec.ServiceAccountNames {
+		resources = append(resources, provisioning.InventoryResource{
+			Type: "FederatedIdentityCredential",
+			Name: serviceAccountName,
+			ID:   identityID + "/federatedIdentityCredentials/" + serviceAccountName,
+		})
+	}
+	return resources
+}
+
+// scopingResourceGroupNamesFor returns the names of the resource groups within which the roles of the user-assigned
+// managed identity created for credentialsRequest are assigned
+func scopingResourceGroupNamesFor(credentialsRequest *credreqv1.CredentialsRequest, installationResourceGroupName, dnsZoneResourceGroupName string) []string {
+	// Scope user-assigned managed identity within the installationResourceGroupName
+	scopingResourceGroupNames := []string{installationResourceGroupName}
+	// Additionally scope the ingress CredentialsRequest within the dnsZoneResourceGroupName
+	if credentialsRequest.Name == ingressCredentialRequestName {
+		scopingResourceGroupNames = append(scopingResourceGroupNames, dnsZoneResourceGroupName)
+	}
+	return scopingResourceGroupNames
+}
+
+// checkRoleAssignmentsQuota ensures that the limit of role assignments per subscription allows assigning the roles of
+// the user-assigned managed identities which do not exist yet for the CredentialsRequests within credReqDirs. Azure
+// does not limit the number of user-assigned managed identities within a subscription, but every role binding of a
+// CredentialsRequest results in a role assignment for each resource group within which it is scoped. The check is
+// best-effort and is skipped when existing role assignments or user-assigned managed identities cannot be read.
+func checkRoleAssignmentsQuota(ctx context.Context, client *azureclients.AzureClientWrapper, credReqDirs []string, name, oidcResourceGroupName, subscriptionID, installationResourceGroupName, dnsZoneResourceGroupName string, enableTechPreview bool) error {
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return err
+	}
+	required := 0
+	for _, credentialsRequest := range credentialsRequests {
+		identityName, err := managedIdentityName(name, credentialsRequest)
+		if err != nil {
+			return err
+		}
+		_, err = client.UserAssignedIdentitiesClient.Get(
+			ctx,
+			oidcResourceGroupName,
+			identityName,
+			&armmsi.UserAssignedIdentitiesClientGetOptions{})
+		if err == nil {
+			// Roles of existing user-assigned managed identities were assigned when they were created
+			continue
+		}
+		var respErr *azcore.ResponseError
+		if !errors.As(err, &respErr) || (respErr.ErrorCode != "ResourceNotFound" && respErr.ErrorCode != "ResourceGroupNotFound") {
+			provisioning.Warnf("Skipping role assignments quota check, unable to get user-assigned managed identity: %s", err)
+			return nil
+		}
+
+		crProviderSpec := &credreqv1.AzureProviderSpec{}
+		if credentialsRequest.Spec.ProviderSpec != nil {
+			if err := codec.DecodeProviderSpec(credentialsRequest.Spec.ProviderSpec, crProviderSpec); err != nil {
+				return fmt.Errorf("error decoding provider spec from CredentialsRequest: %w", err)
+			}
 		}
-		err := createManagedIdentity(client, name, oidcResourceGroupName, subscriptionID, region, issuerURL, outputDir, scopingResourceGroupNames, resourceTags, credentialsRequest, dryRun)
+		required += len(crProviderSpec.RoleBindings) * len(scopingResourceGroupNamesFor(credentialsRequest, installationResourceGroupName, dnsZoneResourceGroupName))
+	}
+
+	usage := 0
+	listRoleAssignments := client.RoleAssignmentClient.NewListForScopePager("/subscriptions/"+subscriptionID, nil)
+	for listRoleAssignments.More() {
+		pageResponse, err := listRoleAssignments.NextPage(ctx)
+		if err != nil {
+			provisioning.Warnf("Skipping role assignments quota check, unable to list role assignments: %s", err)
+			return nil
+		}
+		usage += len(pageResponse.RoleAssignmentListResult.Value)
+	}
+
+	log.Printf("%d of %d role assignments allowed in the subscription are in use, up to %d role assignments will be created", usage, maxRoleAssignmentsPerSubscription, required)
+	return provisioning.CheckQuota(provisioning.Quota{
+		Name:  "role assignments per subscription",
+		Usage: usage,
+		Limit: maxRoleAssignmentsPerSubscription,
+	}, required)
+}
+
+// checkManagedIdentityLimits validates the user-assigned managed identities generated for the CredentialsRequests
+// within credReqDirs with validateManagedIdentityLimits before any cloud call
+func checkManagedIdentityLimits(credReqDirs []string, name string, enableTechPreview bool) error {
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+	return validateManagedIdentityLimits(credentialsRequests, name)
+}
+
+// validateManagedIdentityLimits ensures that the user-assigned managed identities generated for credentialsRequests
+// are within the limits of Azure, returning a LimitViolations naming every CredentialsRequest exceeding a limit. A
+// federated identity credential is created for each service account of a CredentialsRequest and every role binding of
+// a CredentialsRequest results in a role assignment for each resource group within which it is scoped. Role
+// assignments existing within the subscription are accounted for by checkRoleAssignmentsQuota.
+func validateManagedIdentityLimits(credentialsRequests []*credreqv1.CredentialsRequest, name string) error {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return err
+	}
+	violations := provisioning.LimitViolations{}
+	roleAssignments := 0
+	for _, credentialsRequest := range credentialsRequests {
+		identityName, err := managedIdentityName(name, credentialsRequest)
 		if err != nil {
 			return err
 		}
+		violations.Add(provisioning.LimitViolation{
+			Component: provisioning.CredentialsRequestComponent(credentialsRequest),
+			Artifact:  "user-assigned managed identity " + identityName,
+			Size:      len(credentialsRequest.Spec.ServiceAccountNames),
+			Limit:     maxFederatedIdentityCredentialsPerIdentity,
+			Unit:      "federated identity credentials",
+			Limited:   "of a user-assigned managed identity",
+			Hint:      "bind fewer service accounts",
+		})
+
+		crProviderSpec := &credreqv1.AzureProviderSpec{}
+		if credentialsRequest.Spec.ProviderSpec != nil {
+			if err := codec.DecodeProviderSpec(credentialsRequest.Spec.ProviderSpec, crProviderSpec); err != nil {
+				return fmt.Errorf("error decoding provider spec from CredentialsRequest: %w", err)
+			}
+		}
+		// Only the number of resource groups within which roles are assigned matters, not their names
+		roleAssignments += len(crProviderSpec.RoleBindings) * len(scopingResourceGroupNamesFor(credentialsRequest, "", ""))
 	}
+	violations.Add(provisioning.LimitViolation{
+		Component: fmt.Sprintf("%d CredentialsRequests", len(credentialsRequests)),
+		Artifact:  "assigning the roles of their user-assigned managed identities",
+		Size:      roleAssignments,
+		Limit:     maxRoleAssignmentsPerSubscription,
+		Unit:      "role assignments",
+		Limited:   "within a subscription",
+	})
+	return violations.Err()
+}
 
+// validateManagedIdentity validates that the user-assigned managed identity identified by managedIdentityName exists
+// and that its secret manifest was previously written to the outputDir.
+func validateManagedIdentity(ctx context.Context, client *azureclients.AzureClientWrapper, managedIdentityName, resourceGroupName, outputDir string, cr *credreqv1.CredentialsRequest) error {
+	_, err := client.UserAssignedIdentitiesClient.Get(
+		ctx,
+		resourceGroupName,
+		managedIdentityName,
+		&armmsi.UserAssignedIdentitiesClientGetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to get user-assigned managed identity %s", managedIdentityName)
+	}
+	secretPath := filepath.Join(outputDir, provisioning.ManifestsDirName, fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name))
+	if _, err := os.Stat(secretPath); err != nil {
+		return errors.Wrapf(err, "unable to find secret manifest for user-assigned managed identity %s", managedIdentityName)
+	}
 	return nil
 }
 
+// verifyManagedIdentityClientIDs verifies that the client ID within the secret manifest written to the outputDir for
+// each of the CredentialsRequests within credReqDirs matches the client ID of the user-assigned managed identity owned
+// by name within the resource group identified by resourceGroupName. A secret manifest holding the client ID of another
+// user-assigned managed identity, eg. one previously created with the same name, would silently break the component.
+func verifyManagedIdentityClientIDs(ctx context.Context, client *azureclients.AzureClientWrapper, credReqDirs []string, name, ownedTagValue, resourceGroupName, outputDir string, enableTechPreview bool) error {
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+
+	managedIdentities, err := listOwnedManagedIdentities(ctx, client, name, ownedTagValue, "", resourceGroupName)
+	if err != nil {
+		return errors.Wrap(err, "failed to list user-assigned managed identities")
+	}
+	clientIDs := map[string]string{}
+	for _, identity := range managedIdentities {
+		if identity.Properties != nil {
+			clientIDs[*identity.Name] = stringValue(identity.Properties.ClientID)
+		}
+	}
+
+	mismatches := []string{}
+	for _, credentialsRequest := range credentialsRequests {
+		component := credentialsRequest.Spec.SecretRef.Namespace + "/" + credentialsRequest.Spec.SecretRef.Name
+		identityName, err := managedIdentityName(name, credentialsRequest)
+		if err != nil {
+			return err
+		}
+		secretPath := filepath.Join(outputDir, provisioning.ManifestsDirName, fmt.Sprintf("%s-%s-credentials.yaml", credentialsRequest.Spec.SecretRef.Namespace, credentialsRequest.Spec.SecretRef.Name))
+		secretClientID, err := secretManifestClientID(secretPath)
+		if err != nil {
+			return err
+		}
+		liveClientID, found := clientIDs[identityName]
+		switch {
+		case !found:
+			mismatches = append(mismatches, fmt.Sprintf("component %s: user-assigned managed identity %s carrying the owned tag for %s was not found within resource group %s",
+				component, identityName, name, resourceGroupName))
+		case secretClientID != liveClientID:
+			mismatches = append(mismatches, fmt.Sprintf("component %s: secret manifest %s holds client ID %q but user-assigned managed identity %s has client ID %q",
+				component, secretPath, secretClientID, identityName, liveClientID))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the client IDs within %d secret manifests do not match their user-assigned managed identities:\n%s",
+			len(mismatches), strings.Join(mismatches, "\n"))
+	}
+	log.Printf("Verified the client IDs within the secret manifests of %d user-assigned managed identities", len(credentialsRequests))
+	return nil
+}
+
+// secretManifestClientID returns the azure_client_id of the secret manifest at secretPath
+func secretManifestClientID(secretPath string) (string, error) {
+	data, err := os.ReadFile(secretPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read secret manifest at path %s", secretPath)
+	}
+	secret := &corev1.Secret{}
+	if err := sigsyaml.Unmarshal(data, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to decode secret manifest at path %s", secretPath)
+	}
+	return secret.StringData["azure_client_id"], nil
+}
+
 func createManagedIdentitiesCmd(cmd *cobra.Command, args []string) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	ctx := cmd.Context()
+	cred, err := newAzureCredential(ctx, CreateManagedIdentitiesOpts.TenantID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	azureClientWrapper, err := azureclients.NewAzureClientWrapper(CreateManagedIdentitiesOpts.SubscriptionID, cred, &policy.ClientOptions{}, false)
+	azureClientWrapper, err := newAzureClientWrapper(CreateManagedIdentitiesOpts.SubscriptionID, cred)
 	if err != nil {
 		log.Fatalf("Failed to create Azure client: %s", err)
 	}
 
+	if !CreateManagedIdentitiesOpts.DryRun && !CreateManagedIdentitiesOpts.SkipWritePermissionsCheck {
+		provisioning.SetPhase("checking the write permissions of the identity")
+		if err := checkWritePermissions(ctx, azureClientWrapper); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if CreateManagedIdentitiesOpts.OIDCResourceGroupName == "" {
 		CreateManagedIdentitiesOpts.OIDCResourceGroupName = CreateManagedIdentitiesOpts.Name + oidcResourceGroupSuffix
 		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", CreateManagedIdentitiesOpts.OIDCResourceGroupName)
@@ -565,11 +1296,63 @@ func createManagedIdentitiesCmd(cmd *cobra.Command, args []string) {
 		log.Printf("No --installation-resource-group-name provided, defaulting installation resource group name to %s", CreateManagedIdentitiesOpts.InstallationResourceGroupName)
 	}
 
+	if err := validateIdentityResourceGroupName(&CreateManagedIdentitiesOpts); err != nil {
+		log.Fatal(err)
+	}
+
+	if CreateManagedIdentitiesOpts.CanonicalizeIssuerURL {
+		issuerURL, err := provisioning.CanonicalIssuerURL(CreateManagedIdentitiesOpts.IssuerURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if issuerURL != CreateManagedIdentitiesOpts.IssuerURL {
+			log.Printf("Canonicalized --issuer-url %s to %s", CreateManagedIdentitiesOpts.IssuerURL, issuerURL)
+			CreateManagedIdentitiesOpts.IssuerURL = issuerURL
+		}
+	}
+
+	// The issuer may have been created by another run of ccoctl or by other tooling, so its served discovery document is verified
+	provisioning.SetPhase("verifying the issuer of the OIDC discovery document")
+	if err := verifyDiscoveryDocumentIssuer(CreateManagedIdentitiesOpts.IssuerURL, "", CreateManagedIdentitiesOpts.DryRun); err != nil {
+		log.Fatal(err)
+	}
+
+	if !CreateManagedIdentitiesOpts.DryRun && !CreateManagedIdentitiesOpts.SkipQuotaCheck {
+		err = checkRoleAssignmentsQuota(ctx, azureClientWrapper,
+			CreateManagedIdentitiesOpts.CredRequestDirs,
+			CreateManagedIdentitiesOpts.Name,
+			CreateManagedIdentitiesOpts.IdentityResourceGroupName,
+			CreateManagedIdentitiesOpts.SubscriptionID,
+			CreateManagedIdentitiesOpts.InstallationResourceGroupName,
+			CreateManagedIdentitiesOpts.DNSZoneResourceGroupName,
+			CreateManagedIdentitiesOpts.EnableTechPreview)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	err = ensureIdentityResourceGroup(ctx, azureClientWrapper,
+		CreateManagedIdentitiesOpts.Name,
+		CreateManagedIdentitiesOpts.OwnedTagValue,
+		CreateManagedIdentitiesOpts.IdentityResourceGroupName,
+		CreateManagedIdentitiesOpts.OIDCResourceGroupName,
+		CreateManagedIdentitiesOpts.SubscriptionID,
+		CreateManagedIdentitiesOpts.Region,
+		CreateManagedIdentitiesOpts.UserTags,
+		CreateManagedIdentitiesOpts.CreateIdentityResourceGroup,
+		CreateManagedIdentitiesOpts.DryRun,
+		nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = createManagedIdentities(
+		ctx,
 		azureClientWrapper,
-		CreateManagedIdentitiesOpts.CredRequestDir,
+		CreateManagedIdentitiesOpts.CredRequestDirs,
 		CreateManagedIdentitiesOpts.Name,
-		CreateManagedIdentitiesOpts.OIDCResourceGroupName,
+		CreateManagedIdentitiesOpts.OwnedTagValue,
+		CreateManagedIdentitiesOpts.IdentityResourceGroupName,
 		CreateManagedIdentitiesOpts.SubscriptionID,
 		CreateManagedIdentitiesOpts.Region,
 		CreateManagedIdentitiesOpts.IssuerURL,
@@ -578,14 +1361,50 @@ func createManagedIdentitiesCmd(cmd *cobra.Command, args []string) {
 		CreateManagedIdentitiesOpts.DNSZoneResourceGroupName,
 		CreateManagedIdentitiesOpts.UserTags,
 		CreateManagedIdentitiesOpts.EnableTechPreview,
-		CreateManagedIdentitiesOpts.DryRun)
+		CreateManagedIdentitiesOpts.DryRun,
+		CreateManagedIdentitiesOpts.OnlyMissing,
+		nil)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// No secret manifests are written for the user-assigned managed identities skipped with --only-missing, while those
+	// written were just created from the user-assigned managed identities returned by Azure
+	if !CreateManagedIdentitiesOpts.DryRun && !CreateManagedIdentitiesOpts.OnlyMissing {
+		provisioning.SetPhase("verifying the client IDs of user-assigned managed identities")
+		err = verifyManagedIdentityClientIDs(ctx, azureClientWrapper,
+			CreateManagedIdentitiesOpts.CredRequestDirs,
+			CreateManagedIdentitiesOpts.Name,
+			CreateManagedIdentitiesOpts.OwnedTagValue,
+			CreateManagedIdentitiesOpts.IdentityResourceGroupName,
+			CreateManagedIdentitiesOpts.OutputDir,
+			CreateManagedIdentitiesOpts.EnableTechPreview)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 // initEnvForCreateManagedIdentitiesCmd ensures that the output directory specified by --output-dir exists
 func initEnvForCreateManagedIdentitiesCmd(cmd *cobra.Command, args []string) {
+	if err := validateOwnedTagValue(CreateManagedIdentitiesOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+	if err := addClusterResourceTag(&CreateManagedIdentitiesOpts); err != nil {
+		log.Fatal(err)
+	}
+	// Report every user-assigned managed identity exceeding the limits of Azure before any cloud call
+	if err := checkManagedIdentityLimits(CreateManagedIdentitiesOpts.CredRequestDirs, CreateManagedIdentitiesOpts.Name, CreateManagedIdentitiesOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if !CreateManagedIdentitiesOpts.SkipDeprecatedPermissionsCheck {
+		if err := provisioning.WarnDeprecatedPermissions(CreateManagedIdentitiesOpts.CredRequestDirs, CreateManagedIdentitiesOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if CreateManagedIdentitiesOpts.OutputDir == "" && CreateManagedIdentitiesOpts.DryRun {
+		CreateManagedIdentitiesOpts.OutputDir = provisioning.DryRunOutputDir("azure")
+	}
 	if CreateManagedIdentitiesOpts.OutputDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -612,6 +1431,8 @@ func initEnvForCreateManagedIdentitiesCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("Failed to create manifests directory at path %s", manifestsDir)
 	}
+
+	provisioning.EnsureSecretsDir(manifestsDir, CreateManagedIdentitiesOpts.AllowInsecureSecretsDir)
 }
 
 // NewCreateManagedIdentitiesCmd provides the "create-managed-identities" subcommand
@@ -629,12 +1450,12 @@ func NewCreateManagedIdentitiesCmd() *cobra.Command {
 		"name",
 		"",
 		"User-defined name for all created Azure resources. This user-defined name can be separate from the cluster's infra-id. "+
-			fmt.Sprintf("Azure resources created by ccoctl will be tagged with '%s_NAME = %s'", ownedAzureResourceTagKeyPrefix, ownedAzureResourceTagValue),
+			fmt.Sprintf("Azure resources created by ccoctl will be tagged with '%s_NAME = <--owned-tag-value>'", ownedAzureResourceTagKeyPrefix),
 	)
 	createManagedIdentitiesCmd.MarkPersistentFlagRequired("name")
 	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.Region, "region", "", "Azure region in which to create user-assigned managed identities")
 	createManagedIdentitiesCmd.MarkPersistentFlagRequired("region")
-	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing Azure CredentialsRequests files used to create user-assigned managed identities (can be created by running 'oc adm release extract --credentials-requests --cloud=azure' against an OpenShift release image)")
+	createManagedIdentitiesCmd.PersistentFlags().StringSliceVar(&CreateManagedIdentitiesOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing Azure CredentialsRequests files used to create user-assigned managed identities (can be created by running 'oc adm release extract --credentials-requests --cloud=azure' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createManagedIdentitiesCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.DNSZoneResourceGroupName, "dnszone-resource-group-name", "", "The existing Azure resource group which contains the DNS zone that will be used for the cluster's base domain. The cluster ingress operator will be scoped to allow management of DNS records in the DNS Zone resource group.")
 	createManagedIdentitiesCmd.MarkPersistentFlagRequired("dnszone-resource-group-name")
@@ -653,19 +1474,43 @@ func NewCreateManagedIdentitiesCmd() *cobra.Command {
 	createManagedIdentitiesCmd.MarkPersistentFlagRequired("subscription-id")
 	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.IssuerURL, "issuer-url", "", "OIDC Issuer URL (the OIDC Issuer can be created with the 'create-oidc-issuer' sub-command)")
 	createManagedIdentitiesCmd.MarkPersistentFlagRequired("issuer-url")
+	createManagedIdentitiesCmd.PersistentFlags().BoolVar(
+		&CreateManagedIdentitiesOpts.CanonicalizeIssuerURL,
+		"canonicalize-issuer-url",
+		true,
+		"Canonicalize the --issuer-url before federating the user-assigned managed identities with the issuer, ie. trim trailing slashes and the default https port and lowercase the host. "+
+			"Disable to use the --issuer-url verbatim when the OIDC discovery document of the issuer claims a non-canonical issuer. "+
+			"Either way the issuer URL must exactly equal the issuer of the OIDC discovery document",
+	)
 
 	// Optional
 	createManagedIdentitiesCmd.PersistentFlags().StringVar(
 		&CreateManagedIdentitiesOpts.OIDCResourceGroupName,
 		"oidc-resource-group-name",
 		"",
-		"The Azure resource group resource group in which to create user-assigned managed identities (can be created with the 'create-oidc-issuer' sub-command). "+
+		"The Azure resource group resource group in which to create user-assigned managed identities unless --identity-resource-group-name is provided (can be created with the 'create-oidc-issuer' sub-command). "+
 			"A resource group will be created with a name derived from the --name parameter if an --oidc-resource-group-name parameter was not provided.",
 	)
+	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.IdentityResourceGroupName, "identity-resource-group-name", "", identityResourceGroupNameFlagUsage)
+	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.CreateIdentityResourceGroup, "create-identity-resource-group", false, createIdentityResourceGroupFlagUsage)
 	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.DryRun, "dry-run", false, "Skip creating objects and just save what would have been created into files")
-	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.OutputDir, "output-dir", "", "Directory to place generated files. Defaults to the current directory.")
+	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.SkipWritePermissionsCheck, "skip-write-permissions-check", false, "Skip probing whether the identity is permitted to make changes within the subscription before creating resources. The probe deletes a resource group with a random name which does not exist, so it never changes nor leaves behind any resource")
+	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.OutputDir, "output-dir", "", "Directory to place generated files. Defaults to the current directory, or a temporary directory when --dry-run is specified.")
 	createManagedIdentitiesCmd.PersistentFlags().StringToStringVar(&CreateManagedIdentitiesOpts.UserTags, "user-tags", map[string]string{}, "User tags to be applied to Azure resources, multiple tags may be specified comma-separated for example: --user-tags key1=value1,key2=value2")
+	createManagedIdentitiesCmd.PersistentFlags().StringVar(
+		&CreateManagedIdentitiesOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag applied to the Azure resources created by ccoctl, eg. to coexist with other tooling using the same tag key. ", ownedAzureResourceTagKeyPrefix)+
+			"The same value must be provided to ccoctl azure delete in order to delete the resources",
+	)
+	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag created Azure resources, the resources will be tagged with 'kubernetes.io_cluster.<cluster ID> = owned'")
 	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests annotated with TechPreviewNoUpgrade")
+	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.SkipQuotaCheck, "skip-quota-check", false, "Skip checking that the limit of role assignments per subscription allows assigning the roles of the user-assigned managed identities before creating them")
+	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.SkipDeprecatedPermissionsCheck, "skip-deprecated-permissions-check", false, "Skip warning about the roles requested by the CredentialsRequests which are known to be deprecated by Azure")
+	provisioning.AddAllowInsecureSecretsDirFlag(createManagedIdentitiesCmd.PersistentFlags(), &CreateManagedIdentitiesOpts.AllowInsecureSecretsDir)
+	createManagedIdentitiesCmd.PersistentFlags().BoolVar(&CreateManagedIdentitiesOpts.OnlyMissing, "only-missing", false, "Only create the user-assigned managed identities of the CredentialsRequests which do not have a user-assigned managed identity yet, eg. of newly introduced components. Existing user-assigned managed identities carrying the owned tag for --name are left untouched and no secret manifests are written for them")
+	createManagedIdentitiesCmd.PersistentFlags().StringVar(&CreateManagedIdentitiesOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
 
 	return createManagedIdentitiesCmd
 }
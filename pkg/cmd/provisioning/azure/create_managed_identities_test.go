@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -19,6 +20,8 @@ import (
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/golang/mock/gomock"
 )
@@ -76,6 +79,7 @@ func TestCreateManagedIdentities(t *testing.T) {
 		verify                 func(t *testing.T, tempDirName string)
 		enableTechPreview      bool
 		dryRun                 bool
+		onlyMissing            bool
 		expectError            bool
 	}{
 		{
@@ -119,7 +123,7 @@ func TestCreateManagedIdentities(t *testing.T) {
 				mockGetResourceGroupNotFound(wrapper, testInstallResourceGroupName, testSubscriptionID)
 				mockCreateOrUpdateResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
 				mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1")
-				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", testRegionName, testSubscriptionID, resourceTags)
+				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", testRegionName, testSubscriptionID, withComponentTag(resourceTags, "secretName1/namespace1"))
 				mockRoleAssignmentsListForScopePager(wrapper,
 					[]*armauthorization.RoleAssignment{},
 					testManagedIdentityPrincipalID,
@@ -136,9 +140,7 @@ func TestCreateManagedIdentities(t *testing.T) {
 						},
 					})
 				mockCreateRoleAssignmentSuccess(wrapper, "/subscriptions/"+testSubscriptionID+"/resourceGroups/"+testInstallResourceGroupName, "RandomContributorRoleAssignmentNameGUID")
-				mockGetFederatedIdentityCredentialNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
-				mockGetFederatedIdentityCredentialNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount2")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount2", testSubscriptionID)
 				return wrapper
 			},
@@ -203,6 +205,11 @@ func TestCreateManagedIdentities(t *testing.T) {
 				files, err = ioutil.ReadDir(manifestsDirPath)
 				require.NoError(t, err, "unexpected error listing files in manifestsDir")
 				assert.Equal(t, 1, provisioning.CountNonDirectoryFiles(files), "Should be exactly 1 secret in manifestsDir for one CredReq")
+
+				secretData, err := os.ReadFile(filepath.Join(manifestsDirPath, "secretName1-namespace1-credentials.yaml"))
+				require.NoError(t, err, "unexpected error reading dry-run secret")
+				assert.Contains(t, string(secretData), fmt.Sprintf("%s: \"true\"", provisioning.DryRunAnnotation), "Dry-run secret should be annotated as a dry-run artifact")
+				assert.Contains(t, string(secretData), fmt.Sprintf("%s: \"testinfraname-secretName1-namespace1\"", managedIdentityNameAnnotation), "Dry-run secret should record the managed identity name")
 			},
 			expectError: false,
 		},
@@ -214,7 +221,7 @@ func TestCreateManagedIdentities(t *testing.T) {
 				mockGetResourceGroupNotFound(wrapper, testInstallResourceGroupName, testSubscriptionID)
 				mockCreateOrUpdateResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
 				mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1")
-				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", testRegionName, testSubscriptionID, resourceTags)
+				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", testRegionName, testSubscriptionID, withComponentTag(resourceTags, "secretName1/namespace1"))
 				mockRoleAssignmentsListForScopePager(wrapper,
 					[]*armauthorization.RoleAssignment{},
 					testManagedIdentityPrincipalID,
@@ -231,9 +238,7 @@ func TestCreateManagedIdentities(t *testing.T) {
 						},
 					})
 				mockCreateRoleAssignmentSuccess(wrapper, "/subscriptions/"+testSubscriptionID+"/resourceGroups/"+testInstallResourceGroupName, "RandomContributorRoleAssignmentNameGUID")
-				mockGetFederatedIdentityCredentialNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
-				mockGetFederatedIdentityCredentialNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount2")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount2", testSubscriptionID)
 				return wrapper
 			},
@@ -303,6 +308,75 @@ func TestCreateManagedIdentities(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Create only the missing managed identity of two (2) CredentialsRequests, --only-missing set",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				resourceTags, _ := mergeResourceTags(testUserTags, map[string]*string{})
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testInstallResourceGroupName, testSubscriptionID)
+				mockCreateOrUpdateResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+				mockGetUserAssignedManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-namespace1-secretName1", testSubscriptionID,
+					map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)})
+				mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-namespace2-secretName2")
+				mockCreateManagedIdentitySuccess(wrapper, "testinfraname-namespace2-secretName2", withComponentTag(resourceTags, "namespace2/secretName2"))
+				return wrapper
+			},
+			onlyMissing: true,
+			setup: func(t *testing.T) string {
+				tempDirName, err := os.MkdirTemp(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "failed to create temp directory")
+
+				manifestsDirPath := filepath.Join(tempDirName, provisioning.ManifestsDirName)
+				err = provisioning.EnsureDir(manifestsDirPath)
+				require.NoError(t, err, "errored while creating manifests directory for test")
+
+				credReqDirPath := filepath.Join(tempDirName, "credreqs")
+				err = provisioning.EnsureDir(credReqDirPath)
+				require.NoError(t, err, "errored while creating credreq directory for test")
+
+				err = os.WriteFile(filepath.Join(credReqDirPath, "0-credreq.yaml"), []byte(fmt.Sprintf(credReqTemplate, "firstcredreq", "secretName1", "namespace1")), 0600)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+				err = os.WriteFile(filepath.Join(credReqDirPath, "1-credreq.yaml"), []byte(fmt.Sprintf(credReqTemplate, "secondcredreq", "secretName2", "namespace2")), 0600)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+				return tempDirName
+			},
+			verify: func(t *testing.T, tempDirName string) {
+				manifestsDirPath := filepath.Join(tempDirName, provisioning.ManifestsDirName)
+				assert.NoFileExists(t, filepath.Join(manifestsDirPath, "namespace1-secretName1-credentials.yaml"), "Should be no secret for the skipped CredReq")
+				assert.FileExists(t, filepath.Join(manifestsDirPath, "namespace2-secretName2-credentials.yaml"), "Should be a secret for the created managed identity")
+			},
+			expectError: false,
+		},
+		{
+			name: "Refuse existing managed identity without the owned tag, --only-missing set",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				resourceTags, _ := mergeResourceTags(testUserTags, map[string]*string{})
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testInstallResourceGroupName, testSubscriptionID)
+				mockCreateOrUpdateResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+				mockGetUserAssignedManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-namespace1-secretName1", testSubscriptionID, map[string]*string{})
+				return wrapper
+			},
+			onlyMissing: true,
+			setup: func(t *testing.T) string {
+				tempDirName, err := os.MkdirTemp(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "failed to create temp directory")
+
+				manifestsDirPath := filepath.Join(tempDirName, provisioning.ManifestsDirName)
+				err = provisioning.EnsureDir(manifestsDirPath)
+				require.NoError(t, err, "errored while creating manifests directory for test")
+
+				credReqDirPath := filepath.Join(tempDirName, "credreqs")
+				err = provisioning.EnsureDir(credReqDirPath)
+				require.NoError(t, err, "errored while creating credreq directory for test")
+
+				err = os.WriteFile(filepath.Join(credReqDirPath, "0-credreq.yaml"), []byte(fmt.Sprintf(credReqTemplate, "firstcredreq", "secretName1", "namespace1")), 0600)
+				require.NoError(t, err, "errored while setting up test CredReq files")
+				return tempDirName
+			},
+			verify:      func(t *testing.T, tempDirName string) {},
+			expectError: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -315,9 +389,11 @@ func TestCreateManagedIdentities(t *testing.T) {
 			defer os.RemoveAll(tempDirName)
 
 			err := createManagedIdentities(
+				context.Background(),
 				mockAzureClientWrapper,
-				filepath.Join(tempDirName, "credreqs"),
+				[]string{filepath.Join(tempDirName, "credreqs")},
 				testInfraName,
+				ownedAzureResourceTagValue,
 				testOIDCResourceGroupName,
 				testSubscriptionID,
 				testRegionName,
@@ -327,7 +403,9 @@ func TestCreateManagedIdentities(t *testing.T) {
 				testDNSZoneResourceGroupName,
 				testUserTags,
 				test.enableTechPreview,
-				test.dryRun)
+				test.dryRun,
+				test.onlyMissing,
+				nil)
 			if test.expectError {
 				require.Error(t, err, "expected error")
 			} else {
@@ -338,10 +416,132 @@ func TestCreateManagedIdentities(t *testing.T) {
 	}
 }
 
+func TestCreateManagedIdentitiesResume(t *testing.T) {
+	tempDirName, err := os.MkdirTemp(os.TempDir(), testDirPrefix)
+	require.NoError(t, err, "failed to create temp directory")
+	defer os.RemoveAll(tempDirName)
+
+	manifestsDirPath := filepath.Join(tempDirName, provisioning.ManifestsDirName)
+	require.NoError(t, provisioning.EnsureDir(manifestsDirPath), "errored while creating manifests directory for test")
+	credReqDirPath := filepath.Join(tempDirName, "credreqs")
+	require.NoError(t, provisioning.EnsureDir(credReqDirPath), "errored while creating credreq directory for test")
+	// CredentialsRequests are processed in file name order
+	err = os.WriteFile(filepath.Join(credReqDirPath, "0-credreq.yaml"), []byte(fmt.Sprintf(credReqTemplate, "firstcredreq", "secretName1", "namespace1")), 0600)
+	require.NoError(t, err, "errored while setting up test CredReq files")
+	err = os.WriteFile(filepath.Join(credReqDirPath, "1-credreq.yaml"), []byte(fmt.Sprintf(credReqTemplate, "secondcredreq", "secretName2", "namespace2")), 0600)
+	require.NoError(t, err, "errored while setting up test CredReq files")
+
+	resourceTags, _ := mergeResourceTags(testUserTags, map[string]*string{})
+	firstManagedIdentityName := "testinfraname-namespace1-secretName1"
+	secondManagedIdentityName := "testinfraname-namespace2-secretName2"
+
+	runCreateManagedIdentities := func(wrapper *azureclients.AzureClientWrapper, progress *createProgress) error {
+		return createManagedIdentities(
+			context.Background(),
+			wrapper,
+			[]string{credReqDirPath},
+			testInfraName,
+			ownedAzureResourceTagValue,
+			testOIDCResourceGroupName,
+			testSubscriptionID,
+			testRegionName,
+			testIssuerURL,
+			tempDirName,
+			testInstallResourceGroupName,
+			testDNSZoneResourceGroupName,
+			testUserTags,
+			false,
+			false,
+			false,
+			progress)
+	}
+
+	// First run creates the first managed identity and is interrupted while creating the second managed identity
+	mockCtrl := gomock.NewController(t)
+	wrapper := mockAzureClientWrapper(mockCtrl)
+	mockGetResourceGroupNotFound(wrapper, testInstallResourceGroupName, testSubscriptionID)
+	mockCreateOrUpdateResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+	mockCreateManagedIdentitySuccess(wrapper, firstManagedIdentityName, withComponentTag(resourceTags, "namespace1/secretName1"))
+	mockGetUserAssignedManagedIdentityError(wrapper, testOIDCResourceGroupName, secondManagedIdentityName)
+	progress, err := newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, false, false)
+	require.NoError(t, err, "unexpected error creating progress")
+	err = runCreateManagedIdentities(wrapper, progress)
+	require.Error(t, err, "expected error from interrupted run")
+
+	inventory, err := provisioning.LoadInventory(tempDirName)
+	require.NoError(t, err, "inventory should be saved by the interrupted run")
+	_, found := inventory.Step(installationResourceGroupStep)
+	assert.True(t, found, "installation resource group step should be recorded as completed")
+	_, found = inventory.Step(managedIdentityStepPrefix + "namespace1/secretName1")
+	assert.True(t, found, "first managed identity step should be recorded as completed")
+	_, found = inventory.Step(managedIdentityStepPrefix + "namespace2/secretName2")
+	assert.False(t, found, "second managed identity step should not be recorded as completed")
+
+	// Resumed run validates previously completed steps and only creates the second managed identity
+	mockCtrl = gomock.NewController(t)
+	wrapper = mockAzureClientWrapper(mockCtrl)
+	mockGetResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+	mockGetUserAssignedManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, firstManagedIdentityName, testSubscriptionID, resourceTags)
+	mockCreateManagedIdentitySuccess(wrapper, secondManagedIdentityName, withComponentTag(resourceTags, "namespace2/secretName2"))
+	progress, err = newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, true, false)
+	require.NoError(t, err, "unexpected error resuming progress")
+	err = runCreateManagedIdentities(wrapper, progress)
+	require.NoError(t, err, "unexpected error from resumed run")
+
+	inventory, err = provisioning.LoadInventory(tempDirName)
+	require.NoError(t, err, "unexpected error loading inventory")
+	_, found = inventory.Step(managedIdentityStepPrefix + "namespace2/secretName2")
+	assert.True(t, found, "second managed identity step should be recorded as completed")
+
+	// Resumed run errors when a previously completed managed identity no longer exists
+	mockCtrl = gomock.NewController(t)
+	wrapper = mockAzureClientWrapper(mockCtrl)
+	mockGetResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+	mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, firstManagedIdentityName)
+	progress, err = newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, true, false)
+	require.NoError(t, err, "unexpected error resuming progress")
+	err = runCreateManagedIdentities(wrapper, progress)
+	require.Error(t, err, "expected error when previously completed step fails validation")
+
+	// Resumed run with force re-runs a previously completed step which fails validation
+	mockCtrl = gomock.NewController(t)
+	wrapper = mockAzureClientWrapper(mockCtrl)
+	mockGetResourceGroupSuccess(wrapper, testInstallResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+	mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, firstManagedIdentityName)
+	mockCreateManagedIdentitySuccess(wrapper, firstManagedIdentityName, withComponentTag(resourceTags, "namespace1/secretName1"))
+	mockGetUserAssignedManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, secondManagedIdentityName, testSubscriptionID, resourceTags)
+	progress, err = newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, true, true)
+	require.NoError(t, err, "unexpected error resuming progress")
+	err = runCreateManagedIdentities(wrapper, progress)
+	require.NoError(t, err, "unexpected error from resumed run with force")
+
+	// Resuming with a different region is refused
+	_, err = newCreateProgress(tempDirName, testInfraName, "westus3", testSubscriptionID, true, false)
+	require.Error(t, err, "expected error resuming with a different region")
+
+	// Re-running without --resume is refused rather than replacing the inventory, unless forced
+	_, err = newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, false, false)
+	require.Error(t, err, "expected error re-running without resume")
+	inventory, err = provisioning.LoadInventory(tempDirName)
+	require.NoError(t, err, "unexpected error loading inventory")
+	_, found = inventory.Step(managedIdentityStepPrefix + "namespace1/secretName1")
+	assert.True(t, found, "inventory should not be replaced when re-running without resume")
+	_, err = newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, false, true)
+	require.NoError(t, err, "unexpected error re-running with force")
+	inventory, err = provisioning.LoadInventory(tempDirName)
+	require.NoError(t, err, "unexpected error loading inventory")
+	assert.Empty(t, inventory.Steps, "inventory should be replaced when re-running with force")
+
+	// An inventory recorded for another name does not prevent creating a new inventory
+	_, err = newCreateProgress(tempDirName, "othername", testRegionName, testSubscriptionID, false, false)
+	require.NoError(t, err, "unexpected error creating progress for another name")
+}
+
 func TestEnsureUserAssignedManagedIdentity(t *testing.T) {
 	tests := []struct {
 		name                   string
 		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		expectPreExisting      bool
 		expectError            bool
 	}{
 		{
@@ -362,6 +562,7 @@ func TestEnsureUserAssignedManagedIdentity(t *testing.T) {
 				mockGetUserAssignedManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", testSubscriptionID, resourceTags)
 				return wrapper
 			},
+			expectPreExisting: true,
 		},
 		{
 			name: "Pre-existing user-assigned managed identity found with incorrect tags, identity updated",
@@ -381,6 +582,7 @@ func TestEnsureUserAssignedManagedIdentity(t *testing.T) {
 				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", testRegionName, testSubscriptionID, wantResourceTags)
 				return wrapper
 			},
+			expectPreExisting: true,
 		},
 	}
 
@@ -388,65 +590,179 @@ func TestEnsureUserAssignedManagedIdentity(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
-			_, err := ensureUserAssignedManagedIdentity(mockAzureClientWrapper, "testinfraname-secretName1-namespace1", testOIDCResourceGroupName, testRegionName, testUserTags)
+			_, preExisting, err := ensureUserAssignedManagedIdentity(context.Background(), mockAzureClientWrapper, "testinfraname-secretName1-namespace1", testOIDCResourceGroupName, testRegionName, testUserTags)
 			if test.expectError {
 				require.Error(t, err, "expected error")
 			} else {
 				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectPreExisting, preExisting, "unexpected pre-existing user-assigned managed identity")
 			}
 		})
 	}
 }
 
 func TestEnsureFederatedIdentityCredential(t *testing.T) {
+	defer shortenPropagationTimeout()()
 	tests := []struct {
 		name                   string
 		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		existingCredential     *armmsi.FederatedIdentityCredential
+		expectStatus           string
 		expectError            bool
 	}{
 		{
 			name: "Pre-existing federated identity credential not found, credential created",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
 				wrapper := mockAzureClientWrapper(mockCtrl)
-				mockGetFederatedIdentityCredentialNotFound(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
 				return wrapper
 			},
+			expectStatus: provisioning.ResourceCreated,
 		},
 		{
-			name: "Pre-existing federated identity credential found with correct audience, subject and issuer URL, credential not created or updated",
+			name: "Managed identity not found while propagating, credential created after retrying",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
 				wrapper := mockAzureClientWrapper(mockCtrl)
-				mockGetFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID, "openshift", testIssuerURL, "system:serviceaccount:namespace1:testServiceAccount1")
+				gomock.InOrder(
+					mockCreateOrUpdateFederatedIdentityCredentialError(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", "ParentResourceNotFound"),
+					mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID),
+				)
 				return wrapper
 			},
+			expectStatus: provisioning.ResourceCreated,
+		},
+		{
+			name: "Pre-existing federated identity credential found with correct audience, subject and issuer URL, credential not created or updated",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				return mockAzureClientWrapper(mockCtrl)
+			},
+			existingCredential: testFederatedIdentityCredential("testServiceAccount1", "openshift", testIssuerURL, "system:serviceaccount:namespace1:testServiceAccount1"),
 		},
 		{
 			name: "Pre-existing federated identity credential found with incorrect audience, credential updated",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
 				wrapper := mockAzureClientWrapper(mockCtrl)
-				mockGetFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID, "wrongaudience", testIssuerURL, "system:serviceaccount:namespace1:testServiceAccount1")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
 				return wrapper
 			},
+			existingCredential: testFederatedIdentityCredential("testServiceAccount1", "wrongaudience", testIssuerURL, "system:serviceaccount:namespace1:testServiceAccount1"),
+			expectStatus:       provisioning.ResourceUpdated,
 		},
 		{
 			name: "Pre-existing federated identity credential found with incorrect subject, credential updated",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
 				wrapper := mockAzureClientWrapper(mockCtrl)
-				mockGetFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID, "openshift", testIssuerURL, "system:serviceaccount:wrongnamespace:testServiceAccount1")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
 				return wrapper
 			},
+			existingCredential: testFederatedIdentityCredential("testServiceAccount1", "openshift", testIssuerURL, "system:serviceaccount:wrongnamespace:testServiceAccount1"),
+			expectStatus:       provisioning.ResourceUpdated,
 		},
 		{
 			name: "Pre-existing federated identity credential found with incorrect issuer URL, credential updated",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
 				wrapper := mockAzureClientWrapper(mockCtrl)
-				mockGetFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID, "openshift", "http://hue.hae", "system:serviceaccount:namespace1:testServiceAccount1")
 				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
 				return wrapper
 			},
+			existingCredential: testFederatedIdentityCredential("testServiceAccount1", "openshift", "http://hue.hae", "system:serviceaccount:namespace1:testServiceAccount1"),
+			expectStatus:       provisioning.ResourceUpdated,
+		},
+		{
+			name: "Pre-existing federated identity credential found without properties, credential updated",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID)
+				return wrapper
+			},
+			existingCredential: &armmsi.FederatedIdentityCredential{Name: to.Ptr("testServiceAccount1")},
+			expectStatus:       provisioning.ResourceUpdated,
+		},
+		{
+			name: "Federated identity credential stored with another issuer URL, error",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-secretName1-namespace1", "testServiceAccount1", testSubscriptionID).Return(
+					armmsi.FederatedIdentityCredentialsClientCreateOrUpdateResponse{
+						FederatedIdentityCredential: armmsi.FederatedIdentityCredential{
+							ID:         to.Ptr("testServiceAccount1"),
+							Properties: &armmsi.FederatedIdentityCredentialProperties{Issuer: to.Ptr(testIssuerURL + "/")},
+						},
+					},
+					nil,
+				)
+				return wrapper
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+			status, err := ensureFederatedIdentityCredential(context.Background(), mockAzureClientWrapper, "testinfraname-secretName1-namespace1", testIssuerURL, "namespace1", "testServiceAccount1", testOIDCResourceGroupName, test.existingCredential)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectStatus, status, "unexpected status of the federated identity credential")
+			}
+		})
+	}
+}
+
+func TestEnsureFederatedIdentityCredentials(t *testing.T) {
+	managedIdentityName := "testinfraname-namespace1-secretName1"
+	serviceAccountNames := []string{"testServiceAccount1", "testServiceAccount2"}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		preExisting            bool
+		expectError            bool
+	}{
+		{
+			name: "Created user-assigned managed identity, every credential created without listing credentials",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, "testServiceAccount1", testSubscriptionID)
+				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, "testServiceAccount2", testSubscriptionID)
+				return wrapper
+			},
+		},
+		{
+			name: "Pre-existing user-assigned managed identity with a missing credential, only the missing credential created",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, managedIdentityName, map[string]string{
+					"testServiceAccount1": "system:serviceaccount:namespace1:testServiceAccount1",
+				})
+				mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, "testServiceAccount2", testSubscriptionID)
+				return wrapper
+			},
+			preExisting: true,
+		},
+		{
+			name: "Pre-existing user-assigned managed identity with every credential, no credential created",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, managedIdentityName, map[string]string{
+					"testServiceAccount1": "system:serviceaccount:namespace1:testServiceAccount1",
+					"testServiceAccount2": "system:serviceaccount:namespace1:testServiceAccount2",
+				})
+				return wrapper
+			},
+			preExisting: true,
+		},
+		{
+			name: "Pre-existing user-assigned managed identity whose credentials cannot be listed, error",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockFederatedIdentityCredentialsListPagerError(wrapper, testOIDCResourceGroupName, managedIdentityName)
+				return wrapper
+			},
+			preExisting: true,
+			expectError: true,
 		},
 	}
 
@@ -454,7 +770,54 @@ func TestEnsureFederatedIdentityCredential(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
-			err := ensureFederatedIdentityCredential(mockAzureClientWrapper, "testinfraname-secretName1-namespace1", testIssuerURL, "namespace1", "testServiceAccount1", testOIDCResourceGroupName)
+			err := ensureFederatedIdentityCredentials(context.Background(), mockAzureClientWrapper, managedIdentityName, testIssuerURL, "namespace1", serviceAccountNames, testOIDCResourceGroupName, test.preExisting)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestVerifyDiscoveryDocumentIssuer(t *testing.T) {
+	tests := []struct {
+		name string
+		// discoveryDocument is the discovery document written to the output directory, none when empty
+		discoveryDocument string
+		issuerURL         string
+		expectError       bool
+	}{
+		{
+			name:              "Issuer of the discovery document equals the issuer URL",
+			discoveryDocument: fmt.Sprintf(openidConfigurationTemplate, testIssuerURL, testIssuerURL),
+			issuerURL:         testIssuerURL,
+		},
+		{
+			name:              "Issuer of the discovery document has a trailing slash",
+			discoveryDocument: fmt.Sprintf(openidConfigurationTemplate, testIssuerURL+"/", testIssuerURL),
+			issuerURL:         testIssuerURL,
+			expectError:       true,
+		},
+		{
+			name:              "Discovery document without issuer",
+			discoveryDocument: `{"jwks_uri": "https://example.com/openid/v1/jwks"}`,
+			issuerURL:         testIssuerURL,
+			expectError:       true,
+		},
+		{
+			name:      "No discovery document within the output directory, dry run",
+			issuerURL: testIssuerURL,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			if test.discoveryDocument != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(outputDir, openidConfigurationFileName), []byte(test.discoveryDocument), 0600), "failed to write discovery document")
+			}
+			err := verifyDiscoveryDocumentIssuer(test.issuerURL, outputDir, true)
 			if test.expectError {
 				require.Error(t, err, "expected error")
 			} else {
@@ -632,7 +995,7 @@ func TestEnsureRolesAssignedToManagedIdentity(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
-			err := ensureRolesAssignedToManagedIdentity(mockAzureClientWrapper, testManagedIdentityPrincipalID, testSubscriptionID, test.roleBindings, testScopingResourceGroupNames)
+			err := ensureRolesAssignedToManagedIdentity(context.Background(), mockAzureClientWrapper, newRoleDefinitionResolver(mockAzureClientWrapper, testSubscriptionID), testManagedIdentityPrincipalID, testSubscriptionID, test.roleBindings, testScopingResourceGroupNames)
 			if test.expectError {
 				require.Error(t, err, "expected error")
 			} else {
@@ -642,70 +1005,606 @@ func TestEnsureRolesAssignedToManagedIdentity(t *testing.T) {
 	}
 }
 
-func testCredentialsRequest(t *testing.T, crName, targetSecretNamespace, targetSecretName, targetDir string, isTechPreview bool) error {
-	var credReq string
-	if isTechPreview {
-		credReq = fmt.Sprintf(credReqTechPreviewTemplate, crName, targetSecretNamespace, targetSecretName)
-	} else {
-		credReq = fmt.Sprintf(credReqTemplate, crName, targetSecretNamespace, targetSecretName)
+func TestCheckRoleAssignmentsQuota(t *testing.T) {
+	managedIdentityName := testInfraName + "-secretName1-namespace1"
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		expectError            bool
+	}{
+		{
+			name: "Limit allows assigning roles",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, managedIdentityName)
+				mockRoleAssignmentsListForSubscriptionPager(wrapper, maxRoleAssignmentsPerSubscription-1)
+				return wrapper
+			},
+		},
+		{
+			name: "Limit exceeded",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, managedIdentityName)
+				mockRoleAssignmentsListForSubscriptionPager(wrapper, maxRoleAssignmentsPerSubscription)
+				return wrapper
+			},
+			expectError: true,
+		},
+		{
+			name: "Roles of existing user-assigned managed identity do not count against limit",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetUserAssignedManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, testSubscriptionID, map[string]*string{})
+				mockRoleAssignmentsListForSubscriptionPager(wrapper, maxRoleAssignmentsPerSubscription)
+				return wrapper
+			},
+		},
+		{
+			name: "Check skipped when user-assigned managed identity cannot be read",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetUserAssignedManagedIdentityError(wrapper, testOIDCResourceGroupName, managedIdentityName)
+				return wrapper
+			},
+		},
 	}
 
-	f, err := ioutil.TempFile(targetDir, "testCredReq*.yaml")
-	require.NoError(t, err, "error creating temp file for CredentialsRequest")
-	defer f.Close()
-
-	_, err = f.Write([]byte(credReq))
-	require.NoError(t, err, "error while writing out contents of CredentialsRequest file")
-
-	return nil
-}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
 
-func mockGetUserAssignedManagedIdentitySuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, subscriptionID string, tags map[string]*string) {
-	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Get(
-		gomock.Any(), // context
-		resourceGroupName,
-		managedIdentityName,
-		gomock.Any(), // options
-	).Return(
-		armmsi.UserAssignedIdentitiesClientGetResponse{
-			Identity: armmsi.Identity{
-				ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s", subscriptionID, resourceGroupName, managedIdentityName)),
-				Name: to.Ptr(managedIdentityName),
-				Properties: &armmsi.UserAssignedIdentityProperties{
-					PrincipalID: to.Ptr(testManagedIdentityPrincipalID),
-					ClientID:    to.Ptr("testClientID"),
-					TenantID:    to.Ptr("testTenantID"),
-				},
-				Tags: tags,
-			},
-		},
-		nil, // no error
-	)
-}
+			credReqDir, err := ioutil.TempDir(os.TempDir(), "ccoctl-azure-quota-")
+			require.NoError(t, err, "Failed to create temp directory")
+			defer os.RemoveAll(credReqDir)
+			err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+			require.NoError(t, err, "errored while setting up test CredReq files")
 
-func mockGetUserAssignedManagedIdentityNotFound(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string) {
-	respHeader := http.Header{}
-	respHeader.Set("x-ms-error-code", "ResourceNotFound")
-	resp := &http.Response{
-		Header: respHeader,
+			err = checkRoleAssignmentsQuota(context.Background(), mockAzureClientWrapper, []string{credReqDir}, testInfraName, testOIDCResourceGroupName, testSubscriptionID, testInstallResourceGroupName, testDNSZoneResourceGroupName, false)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
 	}
-	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Get(
-		gomock.Any(), // context
-		resourceGroupName,
-		managedIdentityName,
-		gomock.Any(), // options
-	).Return(
-		armmsi.UserAssignedIdentitiesClientGetResponse{},
-		NewResponseError(resp),
-	)
 }
 
-func mockCreateOrUpdateManagedIdentitySuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, region, subscriptionID string, tags map[string]*string) {
-	parameters := armmsi.Identity{
-		Location: to.Ptr(region),
-		Tags:     tags,
+func TestVerifyManagedIdentityClientIDs(t *testing.T) {
+	managedIdentityName := testInfraName + "-secretName1-namespace1"
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
 	}
-	userAssignedIdentitiesClientCreateOrUpdateResponse := armmsi.UserAssignedIdentitiesClientCreateOrUpdateResponse{
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		secretClientID         string
+		noSecretManifest       bool
+		expectError            string
+	}{
+		{
+			name: "Client IDs match",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListWithClientIDs(wrapper, testOIDCResourceGroupName, ownedTags, map[string]string{
+					managedIdentityName: "live-client-id",
+				})
+				return wrapper
+			},
+			secretClientID: "live-client-id",
+		},
+		{
+			name: "Client ID of secret manifest does not match",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListWithClientIDs(wrapper, testOIDCResourceGroupName, ownedTags, map[string]string{
+					managedIdentityName: "live-client-id",
+				})
+				return wrapper
+			},
+			secretClientID: "stale-client-id",
+			expectError:    `component secretName1/namespace1: secret manifest`,
+		},
+		{
+			name: "User-assigned managed identity not found",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListWithClientIDs(wrapper, testOIDCResourceGroupName, ownedTags, map[string]string{})
+				return wrapper
+			},
+			secretClientID: "live-client-id",
+			expectError:    "component secretName1/namespace1: user-assigned managed identity " + managedIdentityName + " carrying the owned tag",
+		},
+		{
+			name: "Secret manifest not found",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListWithClientIDs(wrapper, testOIDCResourceGroupName, ownedTags, map[string]string{
+					managedIdentityName: "live-client-id",
+				})
+				return wrapper
+			},
+			noSecretManifest: true,
+			expectError:      "failed to read secret manifest",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+
+			credReqDir := t.TempDir()
+			err := testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+			require.NoError(t, err, "errored while setting up test CredReq files")
+
+			outputDir := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(outputDir, provisioning.ManifestsDirName), 0700))
+			if !test.noSecretManifest {
+				credentialsRequests, err := provisioning.GetListOfCredentialsRequests([]string{credReqDir}, false)
+				require.NoError(t, err, "errored while reading test CredReq files")
+				err = writeCredReqSecret(credentialsRequests[0], outputDir, test.secretClientID, "tenant-id", testSubscriptionID, testRegionName, testIssuerURL)
+				require.NoError(t, err, "errored while writing test secret manifest")
+			}
+
+			err = verifyManagedIdentityClientIDs(context.Background(), mockAzureClientWrapper, []string{credReqDir}, testInfraName, ownedAzureResourceTagValue, testOIDCResourceGroupName, outputDir, false)
+			if test.expectError != "" {
+				require.ErrorContains(t, err, test.expectError)
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestRoleDefinitionResolver(t *testing.T) {
+	roleDefinitionGUID := "befefa01-2a29-4197-83a8-272ff33ce314"
+	roleDefinitionID := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", testSubscriptionID, roleDefinitionGUID)
+	dnsZoneContributor := &armauthorization.RoleDefinition{
+		Name: to.Ptr(roleDefinitionGUID),
+		ID:   to.Ptr(roleDefinitionID),
+		Properties: &armauthorization.RoleDefinitionProperties{
+			RoleName: to.Ptr("DNS Zone Contributor"),
+		},
+	}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		role                   string
+		expectedRoleName       string
+		expectError            bool
+	}{
+		{
+			name: "Role resolved by name",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockRoleDefinitionsListPager(wrapper, "/subscriptions/"+testSubscriptionID, []*armauthorization.RoleDefinition{dnsZoneContributor})
+				return wrapper
+			},
+			role:             "DNS Zone Contributor",
+			expectedRoleName: "DNS Zone Contributor",
+		},
+		{
+			name: "Role resolved by role definition GUID",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetRoleDefinitionByIDSuccess(wrapper, roleDefinitionID, "DNS Zone Contributor")
+				return wrapper
+			},
+			role:             roleDefinitionGUID,
+			expectedRoleName: "DNS Zone Contributor",
+		},
+		{
+			name: "Role resolved by fully qualified role definition ID",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetRoleDefinitionByIDSuccess(wrapper, "/providers/Microsoft.Authorization/roleDefinitions/"+roleDefinitionGUID, "DNS Zone Contributor")
+				return wrapper
+			},
+			role:             "/providers/Microsoft.Authorization/roleDefinitions/" + roleDefinitionGUID,
+			expectedRoleName: "DNS Zone Contributor",
+		},
+		{
+			name: "Role name not found",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockRoleDefinitionsListPager(wrapper, "/subscriptions/"+testSubscriptionID, []*armauthorization.RoleDefinition{})
+				return wrapper
+			},
+			role:        "DNS Zone Contributor",
+			expectError: true,
+		},
+		{
+			name: "Role name ambiguous",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockRoleDefinitionsListPager(wrapper, "/subscriptions/"+testSubscriptionID, []*armauthorization.RoleDefinition{
+					dnsZoneContributor,
+					{
+						ID: to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", testSubscriptionID, "customDNSZoneContributor")),
+						Properties: &armauthorization.RoleDefinitionProperties{
+							RoleName: to.Ptr("DNS Zone Contributor"),
+						},
+					},
+				})
+				return wrapper
+			},
+			role:        "DNS Zone Contributor",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			resolver := newRoleDefinitionResolver(test.mockAzureClientWrapper(mockCtrl), testSubscriptionID)
+			// The role is resolved twice, mocks expecting a single call validate that resolutions are cached
+			for i := 0; i < 2; i++ {
+				roleDefinition, err := resolver.resolve(context.Background(), test.role)
+				if test.expectError {
+					require.Error(t, err, "expected error")
+					return
+				}
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectedRoleName, roleDefinitionName(roleDefinition, test.role))
+				assert.NotNil(t, roleDefinition.ID, "expected role definition ID")
+			}
+		})
+	}
+}
+
+func TestCreateRoleAssignment(t *testing.T) {
+	defer shortenPropagationTimeout()()
+	scope := "/subscriptions/" + testSubscriptionID + "/resourceGroups/" + testInstallResourceGroupName
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		expectError            bool
+	}{
+		{
+			name: "Role assignment created",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateRoleAssignmentSuccess(wrapper, scope, "")
+				return wrapper
+			},
+		},
+		{
+			name: "Principal not found while managed identity propagates, role assignment created after retrying",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				gomock.InOrder(
+					mockCreateRoleAssignmentError(wrapper, scope, "PrincipalNotFound").Times(2),
+					mockCreateRoleAssignmentSuccess(wrapper, scope, ""),
+				)
+				return wrapper
+			},
+		},
+		{
+			name: "Principal never found, timed out waiting for managed identity to propagate",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateRoleAssignmentError(wrapper, scope, "PrincipalNotFound").MinTimes(1)
+				return wrapper
+			},
+			expectError: true,
+		},
+		{
+			name: "Role assignment already exists",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateRoleAssignmentError(wrapper, scope, "RoleAssignmentExists")
+				return wrapper
+			},
+		},
+		{
+			name: "Unrelated error not retried",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockCreateRoleAssignmentError(wrapper, scope, "AuthorizationFailed")
+				return wrapper
+			},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+			_, err := createRoleAssignment(context.Background(), mockAzureClientWrapper, testManagedIdentityPrincipalID, "DNSZoneContributorRoleDefinitionID", "DNS Zone Contributor", scope, testSubscriptionID)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+// shortenPropagationTimeout reduces the time spent waiting for user-assigned managed identities to propagate and
+// returns a function restoring the original values
+func shortenPropagationTimeout() func() {
+	originalRetryInterval, originalTimeout := propagationRetryInterval, propagationTimeout
+	propagationRetryInterval, propagationTimeout = time.Millisecond, 10*time.Millisecond
+	return func() {
+		propagationRetryInterval, propagationTimeout = originalRetryInterval, originalTimeout
+	}
+}
+
+func TestValidateManagedIdentityLimits(t *testing.T) {
+	credentialsRequest := func(t *testing.T, name string, roleBindings, serviceAccounts int) *credreqv1.CredentialsRequest {
+		codec, err := credreqv1.NewCodec()
+		require.NoError(t, err, "failed to create codec")
+		providerSpec := &credreqv1.AzureProviderSpec{TypeMeta: metav1.TypeMeta{Kind: "AzureProviderSpec"}}
+		for i := 0; i < roleBindings; i++ {
+			providerSpec.RoleBindings = append(providerSpec.RoleBindings, credreqv1.RoleBinding{Role: fmt.Sprintf("role-%d", i)})
+		}
+		encoded, err := codec.EncodeProviderSpec(providerSpec)
+		require.NoError(t, err, "failed to encode provider spec")
+		cr := &credreqv1.CredentialsRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openshift-cloud-credential-operator"},
+			Spec: credreqv1.CredentialsRequestSpec{
+				SecretRef:    corev1.ObjectReference{Namespace: "namespace1", Name: name + "-secret"},
+				ProviderSpec: encoded,
+			},
+		}
+		for i := 0; i < serviceAccounts; i++ {
+			cr.Spec.ServiceAccountNames = append(cr.Spec.ServiceAccountNames, fmt.Sprintf("service-account-%d", i))
+		}
+		return cr
+	}
+
+	tests := []struct {
+		name                string
+		credentialsRequests func(t *testing.T) []*credreqv1.CredentialsRequest
+		// expectedViolations are expected within the error, one for each violation
+		expectedViolations []string
+	}{
+		{
+			name: "Within the limits",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{
+					credentialsRequest(t, "firstcredreq", 2, maxFederatedIdentityCredentialsPerIdentity),
+					credentialsRequest(t, ingressCredentialRequestName, 1, 1),
+				}
+			},
+		},
+		{
+			name: "Too many federated identity credentials",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{credentialsRequest(t, "firstcredreq", 1, maxFederatedIdentityCredentialsPerIdentity+1)}
+			},
+			expectedViolations: []string{"CredentialsRequest openshift-cloud-credential-operator/firstcredreq: user-assigned managed identity " + testInfraName + "-namespace1-firstcredreq-secret requires 21 federated identity credentials, exceeding the limit of 20"},
+		},
+		{
+			name: "Too many role assignments counting both resource groups of the ingress CredentialsRequest",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{
+					credentialsRequest(t, "firstcredreq", maxRoleAssignmentsPerSubscription-2, 1),
+					credentialsRequest(t, ingressCredentialRequestName, 2, 1),
+				}
+			},
+			expectedViolations: []string{"2 CredentialsRequests: assigning the roles of their user-assigned managed identities requires 4002 role assignments, exceeding the limit of 4000"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateManagedIdentityLimits(test.credentialsRequests(t), testInfraName)
+			if len(test.expectedViolations) == 0 {
+				require.NoError(t, err, "unexpected error")
+				return
+			}
+			var violations provisioning.LimitViolations
+			require.ErrorAs(t, err, &violations, "expected limit violations")
+			assert.Len(t, violations, len(test.expectedViolations), "unexpected number of violations")
+			for _, expected := range test.expectedViolations {
+				assert.Contains(t, err.Error(), expected, "unexpected violation")
+			}
+		})
+	}
+}
+
+func TestEnsureIdentityResourceGroup(t *testing.T) {
+	tests := []struct {
+		name                      string
+		mockAzureClientWrapper    func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		identityResourceGroupName string
+		create                    bool
+		dryRun                    bool
+		expectError               bool
+	}{
+		{
+			name:                      "Identity resource group is the OIDC resource group",
+			mockAzureClientWrapper:    mockAzureClientWrapper,
+			identityResourceGroupName: testOIDCResourceGroupName,
+		},
+		{
+			name: "Pre-existing identity resource group",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testIdentityResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{})
+				return wrapper
+			},
+			identityResourceGroupName: testIdentityResourceGroupName,
+		},
+		{
+			name: "Missing identity resource group not created without --create-identity-resource-group",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testIdentityResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+			identityResourceGroupName: testIdentityResourceGroupName,
+			expectError:               true,
+		},
+		{
+			name: "Identity resource group created",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testIdentityResourceGroupName, testSubscriptionID)
+				mockCreateOrUpdateResourceGroupSuccess(wrapper, testIdentityResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+					ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue),
+				})
+				return wrapper
+			},
+			identityResourceGroupName: testIdentityResourceGroupName,
+			create:                    true,
+		},
+		{
+			name:                      "Identity resource group not created with dry run",
+			mockAzureClientWrapper:    mockAzureClientWrapper,
+			identityResourceGroupName: testIdentityResourceGroupName,
+			create:                    true,
+			dryRun:                    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := ensureIdentityResourceGroup(context.Background(), test.mockAzureClientWrapper(mockCtrl), testInfraName, ownedAzureResourceTagValue,
+				test.identityResourceGroupName, testOIDCResourceGroupName, testSubscriptionID, testRegionName, map[string]string{},
+				test.create, test.dryRun, nil)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidateIdentityResourceGroupName(t *testing.T) {
+	tests := []struct {
+		name                              string
+		opts                              azureOptions
+		expectedIdentityResourceGroupName string
+		expectError                       bool
+	}{
+		{
+			name:                              "Defaults to the OIDC resource group",
+			opts:                              azureOptions{OIDCResourceGroupName: testOIDCResourceGroupName, InstallationResourceGroupName: testInstallResourceGroupName},
+			expectedIdentityResourceGroupName: testOIDCResourceGroupName,
+		},
+		{
+			name: "Separate identity resource group",
+			opts: azureOptions{
+				OIDCResourceGroupName:         testOIDCResourceGroupName,
+				InstallationResourceGroupName: testInstallResourceGroupName,
+				IdentityResourceGroupName:     testIdentityResourceGroupName,
+				CreateIdentityResourceGroup:   true,
+			},
+			expectedIdentityResourceGroupName: testIdentityResourceGroupName,
+		},
+		{
+			name: "Identity resource group created without a name",
+			opts: azureOptions{
+				OIDCResourceGroupName:       testOIDCResourceGroupName,
+				CreateIdentityResourceGroup: true,
+			},
+			expectError: true,
+		},
+		{
+			name: "Identity resource group is the installation resource group",
+			opts: azureOptions{
+				OIDCResourceGroupName:         testOIDCResourceGroupName,
+				InstallationResourceGroupName: testInstallResourceGroupName,
+				IdentityResourceGroupName:     testInstallResourceGroupName,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateIdentityResourceGroupName(&test.opts)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedIdentityResourceGroupName, test.opts.IdentityResourceGroupName, "unexpected identity resource group")
+		})
+	}
+}
+
+func testCredentialsRequest(t *testing.T, crName, targetSecretNamespace, targetSecretName, targetDir string, isTechPreview bool) error {
+	var credReq string
+	if isTechPreview {
+		credReq = fmt.Sprintf(credReqTechPreviewTemplate, crName, targetSecretNamespace, targetSecretName)
+	} else {
+		credReq = fmt.Sprintf(credReqTemplate, crName, targetSecretNamespace, targetSecretName)
+	}
+
+	f, err := ioutil.TempFile(targetDir, "testCredReq*.yaml")
+	require.NoError(t, err, "error creating temp file for CredentialsRequest")
+	defer f.Close()
+
+	_, err = f.Write([]byte(credReq))
+	require.NoError(t, err, "error while writing out contents of CredentialsRequest file")
+
+	return nil
+}
+
+func mockGetUserAssignedManagedIdentitySuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, subscriptionID string, tags map[string]*string) {
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Get(
+		gomock.Any(), // context
+		resourceGroupName,
+		managedIdentityName,
+		gomock.Any(), // options
+	).Return(
+		armmsi.UserAssignedIdentitiesClientGetResponse{
+			Identity: armmsi.Identity{
+				ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s", subscriptionID, resourceGroupName, managedIdentityName)),
+				Name: to.Ptr(managedIdentityName),
+				Properties: &armmsi.UserAssignedIdentityProperties{
+					PrincipalID: to.Ptr(testManagedIdentityPrincipalID),
+					ClientID:    to.Ptr("testClientID"),
+					TenantID:    to.Ptr("testTenantID"),
+				},
+				Tags: tags,
+			},
+		},
+		nil, // no error
+	)
+}
+
+func mockGetUserAssignedManagedIdentityNotFound(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string) {
+	respHeader := http.Header{}
+	respHeader.Set("x-ms-error-code", "ResourceNotFound")
+	resp := &http.Response{
+		Header: respHeader,
+	}
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Get(
+		gomock.Any(), // context
+		resourceGroupName,
+		managedIdentityName,
+		gomock.Any(), // options
+	).Return(
+		armmsi.UserAssignedIdentitiesClientGetResponse{},
+		NewResponseError(resp),
+	)
+}
+
+// withComponentTag returns a copy of tags with the tag identifying component, "<secret namespace>/<secret name>", which
+// is added to the managed identities created for CredentialsRequests.
+func withComponentTag(tags map[string]*string, component string) map[string]*string {
+	componentTags := map[string]*string{componentAzureResourceTagKey: to.Ptr(component)}
+	for key, value := range tags {
+		componentTags[key] = value
+	}
+	return componentTags
+}
+
+func mockCreateOrUpdateManagedIdentitySuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, region, subscriptionID string, tags map[string]*string) *gomock.Call {
+	parameters := armmsi.Identity{
+		Location: to.Ptr(region),
+		Tags:     tags,
+	}
+	userAssignedIdentitiesClientCreateOrUpdateResponse := armmsi.UserAssignedIdentitiesClientCreateOrUpdateResponse{
 		Identity: armmsi.Identity{
 			ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s", subscriptionID, resourceGroupName, managedIdentityName)),
 			Name: to.Ptr(managedIdentityName),
@@ -717,11 +1616,11 @@ func mockCreateOrUpdateManagedIdentitySuccess(wrapper *azureclients.AzureClientW
 			Tags: tags,
 		},
 	}
-	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().CreateOrUpdate(
+	return wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().CreateOrUpdate(
 		gomock.Any(), // context
 		resourceGroupName,
 		managedIdentityName,
-		parameters,
+		withCreationTimestampTag(parameters),
 		gomock.Any(), // options
 	).Return(
 		userAssignedIdentitiesClientCreateOrUpdateResponse,
@@ -775,14 +1674,35 @@ func mockRoleAssignmentsListForScopePager(wrapper *azureclients.AzureClientWrapp
 	)
 }
 
-func mockCreateRoleAssignmentSuccess(wrapper *azureclients.AzureClientWrapper, scope, roleAssignmentName string) {
+func mockRoleAssignmentsListForSubscriptionPager(wrapper *azureclients.AzureClientWrapper, roleAssignments int) {
+	roleAssignmentsListResult := armauthorization.RoleAssignmentsClientListForScopeResponse{
+		RoleAssignmentListResult: armauthorization.RoleAssignmentListResult{
+			Value: make([]*armauthorization.RoleAssignment, roleAssignments),
+		},
+	}
+	wrapper.RoleAssignmentClient.(*mockazure.MockRoleAssignmentsClient).EXPECT().NewListForScopePager(
+		"/subscriptions/"+testSubscriptionID,
+		gomock.Nil(),
+	).Return(
+		runtime.NewPager(runtime.PagingHandler[armauthorization.RoleAssignmentsClientListForScopeResponse]{
+			More: func(current armauthorization.RoleAssignmentsClientListForScopeResponse) bool {
+				return current.NextLink != nil
+			},
+			Fetcher: func(ctx context.Context, current *armauthorization.RoleAssignmentsClientListForScopeResponse) (armauthorization.RoleAssignmentsClientListForScopeResponse, error) {
+				return roleAssignmentsListResult, nil
+			},
+		}),
+	)
+}
+
+func mockCreateRoleAssignmentSuccess(wrapper *azureclients.AzureClientWrapper, scope, roleAssignmentName string) *gomock.Call {
 	roleAssignmentsClientCreateResponse := armauthorization.RoleAssignmentsClientCreateResponse{
 		RoleAssignment: armauthorization.RoleAssignment{
 			ID:   to.Ptr("/role/assignment/ID/path"),
 			Name: to.Ptr(roleAssignmentName),
 		},
 	}
-	wrapper.RoleAssignmentClient.(*mockazure.MockRoleAssignmentsClient).EXPECT().Create(
+	return wrapper.RoleAssignmentClient.(*mockazure.MockRoleAssignmentsClient).EXPECT().Create(
 		gomock.Any(), // context
 		scope,
 		gomock.Any(), // roleAssignmentName, GUID generated by createRoleAssignment()
@@ -794,14 +1714,32 @@ func mockCreateRoleAssignmentSuccess(wrapper *azureclients.AzureClientWrapper, s
 	)
 }
 
-func mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, federatedIdentityCredentialName, subscriptionID string) {
+func mockCreateRoleAssignmentError(wrapper *azureclients.AzureClientWrapper, scope, errorCode string) *gomock.Call {
+	respHeader := http.Header{}
+	respHeader.Set("x-ms-error-code", errorCode)
+	resp := &http.Response{
+		Header: respHeader,
+	}
+	return wrapper.RoleAssignmentClient.(*mockazure.MockRoleAssignmentsClient).EXPECT().Create(
+		gomock.Any(), // context
+		scope,
+		gomock.Any(), // roleAssignmentName, GUID generated by createRoleAssignment()
+		gomock.Any(), // parameters
+		gomock.Any(), // options
+	).Return(
+		armauthorization.RoleAssignmentsClientCreateResponse{},
+		NewResponseError(resp),
+	)
+}
+
+func mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, federatedIdentityCredentialName, subscriptionID string) *gomock.Call {
 	federatedIdentityCredentialsClientCreateOrUpdateResponse := armmsi.FederatedIdentityCredentialsClientCreateOrUpdateResponse{
 		FederatedIdentityCredential: armmsi.FederatedIdentityCredential{
 			ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/abutcherdemo-oidc/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s/federatedIdentityCredentials/%s", subscriptionID, managedIdentityName, federatedIdentityCredentialName)),
 			Name: to.Ptr(federatedIdentityCredentialName),
 		},
 	}
-	wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().CreateOrUpdate(
+	return wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().CreateOrUpdate(
 		gomock.Any(), // context
 		resourceGroupName,
 		managedIdentityName,
@@ -814,46 +1752,55 @@ func mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper *azureclients.
 	)
 }
 
-func mockGetFederatedIdentityCredentialNotFound(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, federatedIdentityCredentialName string) {
+func mockCreateOrUpdateFederatedIdentityCredentialError(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, federatedIdentityCredentialName, errorCode string) *gomock.Call {
 	respHeader := http.Header{}
-	respHeader.Set("x-ms-error-code", "NotFound")
+	respHeader.Set("x-ms-error-code", errorCode)
 	resp := &http.Response{
 		Header: respHeader,
 	}
-	wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().Get(
+	return wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().CreateOrUpdate(
 		gomock.Any(), // context
 		resourceGroupName,
 		managedIdentityName,
 		federatedIdentityCredentialName,
+		gomock.Any(), // parameters
 		gomock.Any(), // options
 	).Return(
-		armmsi.FederatedIdentityCredentialsClientGetResponse{},
+		armmsi.FederatedIdentityCredentialsClientCreateOrUpdateResponse{},
 		NewResponseError(resp),
 	)
 }
 
-func mockGetFederatedIdentityCredentialSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, federatedIdentityCredentialName, subscriptionID, audience, issuerURL, subject string) {
-	wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().Get(
-		gomock.Any(), // context
-		resourceGroupName,
-		managedIdentityName,
-		federatedIdentityCredentialName,
-		gomock.Any(), // options
-	).Return(
-		armmsi.FederatedIdentityCredentialsClientGetResponse{
-			FederatedIdentityCredential: armmsi.FederatedIdentityCredential{
-				ID: to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s/federatedIdentityCredentials/%s", subscriptionID, resourceGroupName, managedIdentityName, federatedIdentityCredentialName)),
-				Properties: &armmsi.FederatedIdentityCredentialProperties{
-					Audiences: []*string{to.Ptr(audience)},
-					Issuer:    to.Ptr(issuerURL),
-					Subject:   to.Ptr(subject),
-				},
+func mockFederatedIdentityCredentialsListPagerError(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string) {
+	respHeader := http.Header{}
+	respHeader.Set("x-ms-error-code", "InternalServerError")
+	resp := &http.Response{
+		Header: respHeader,
+	}
+	wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().NewListPager(resourceGroupName, managedIdentityName, gomock.Any()).Return(
+		runtime.NewPager(runtime.PagingHandler[armmsi.FederatedIdentityCredentialsClientListResponse]{
+			More: func(current armmsi.FederatedIdentityCredentialsClientListResponse) bool {
+				return current.NextLink != nil
 			},
-		},
-		nil, // no error
+			Fetcher: func(ctx context.Context, current *armmsi.FederatedIdentityCredentialsClientListResponse) (armmsi.FederatedIdentityCredentialsClientListResponse, error) {
+				return armmsi.FederatedIdentityCredentialsClientListResponse{}, NewResponseError(resp)
+			},
+		}),
 	)
 }
 
+func testFederatedIdentityCredential(name, audience, issuerURL, subject string) *armmsi.FederatedIdentityCredential {
+	return &armmsi.FederatedIdentityCredential{
+		ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourcegroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/testinfraname-secretName1-namespace1/federatedIdentityCredentials/%s", testSubscriptionID, testOIDCResourceGroupName, name)),
+		Name: to.Ptr(name),
+		Properties: &armmsi.FederatedIdentityCredentialProperties{
+			Audiences: []*string{to.Ptr(audience)},
+			Issuer:    to.Ptr(issuerURL),
+			Subject:   to.Ptr(subject),
+		},
+	}
+}
+
 func mockGetRoleDefinitionByIDSuccess(wrapper *azureclients.AzureClientWrapper, roleDefinitionID, roleName string) {
 	wrapper.RoleDefinitionsClient.(*mockazure.MockRoleDefinitionsClient).EXPECT().GetByID(
 		gomock.Any(), // context
@@ -882,3 +1829,73 @@ func mockDeleteRoleAssignmentSuccess(wrapper *azureclients.AzureClientWrapper, s
 		nil,
 	)
 }
+
+// mockCreateManagedIdentitySuccess mocks the creation of a managed identity with managedIdentityName within the OIDC
+// resource group for a CredentialsRequest created from credReqTemplate.
+func mockCreateManagedIdentitySuccess(wrapper *azureclients.AzureClientWrapper, managedIdentityName string, resourceTags map[string]*string) {
+	mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, managedIdentityName)
+	mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, testRegionName, testSubscriptionID, resourceTags)
+	mockRoleAssignmentsListForScopePager(wrapper,
+		[]*armauthorization.RoleAssignment{},
+		testManagedIdentityPrincipalID,
+		testSubscriptionID,
+	)
+	mockRoleDefinitionsListPager(wrapper, "/subscriptions/"+testSubscriptionID,
+		[]*armauthorization.RoleDefinition{
+			{
+				Name: to.Ptr("ContibutorRoleDefinitionID"),
+				ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", testSubscriptionID, "ContibutorRoleDefinitionID")),
+				Properties: &armauthorization.RoleDefinitionProperties{
+					RoleName: to.Ptr("Contributor"),
+				},
+			},
+		})
+	mockCreateRoleAssignmentSuccess(wrapper, "/subscriptions/"+testSubscriptionID+"/resourceGroups/"+testInstallResourceGroupName, "RandomContributorRoleAssignmentNameGUID")
+	mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, "testServiceAccount1", testSubscriptionID)
+	mockCreateOrUpdateFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, managedIdentityName, "testServiceAccount2", testSubscriptionID)
+}
+
+func mockGetUserAssignedManagedIdentityError(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string) {
+	respHeader := http.Header{}
+	respHeader.Set("x-ms-error-code", "InternalServerError")
+	resp := &http.Response{
+		Header: respHeader,
+	}
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Get(
+		gomock.Any(), // context
+		resourceGroupName,
+		managedIdentityName,
+		gomock.Any(), // options
+	).Return(
+		armmsi.UserAssignedIdentitiesClientGetResponse{},
+		NewResponseError(resp),
+	)
+}
+
+func mockUserAssignedIdentitiesListWithClientIDs(wrapper *azureclients.AzureClientWrapper, resourceGroupName string, tags map[string]*string, clientIDs map[string]string) {
+	userAssignedIdentitiesListResult := armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse{
+		UserAssignedIdentitiesListResult: armmsi.UserAssignedIdentitiesListResult{
+			Value: []*armmsi.Identity{},
+		},
+	}
+	for identityName, clientID := range clientIDs {
+		userAssignedIdentitiesListResult.Value = append(userAssignedIdentitiesListResult.Value, &armmsi.Identity{
+			ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s", testSubscriptionID, resourceGroupName, identityName)),
+			Name: to.Ptr(identityName),
+			Tags: tags,
+			Properties: &armmsi.UserAssignedIdentityProperties{
+				ClientID: to.Ptr(clientID),
+			},
+		})
+	}
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().NewListByResourceGroupPager(resourceGroupName, gomock.Any()).Return(
+		runtime.NewPager(runtime.PagingHandler[armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse]{
+			More: func(current armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse) bool {
+				return current.NextLink != nil
+			},
+			Fetcher: func(ctx context.Context, current *armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse) (armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse, error) {
+				return userAssignedIdentitiesListResult, nil
+			},
+		}),
+	)
+}
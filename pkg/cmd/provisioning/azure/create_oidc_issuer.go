@@ -7,15 +7,15 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
@@ -49,6 +49,9 @@ var (
 	jwksFileName                = "jwks"
 	fileMode                    = 0644
 
+	// issuerURLPathPrefixRegexp matches one or more "/" separated path segments made up of URL unreserved characters
+	issuerURLPathPrefixRegexp = regexp.MustCompile(`^[A-Za-z0-9._~-]+(/[A-Za-z0-9._~-]+)*$`)
+
 	// oidcResourceGroupSuffix is the suffix used for the name of the resource group in which the OIDC
 	// infrastructure is created
 	oidcResourceGroupSuffix = "-oidc"
@@ -56,17 +59,45 @@ var (
 	// ownedAzureResourceTagKeyPrefix is the prefix of the tag key applied to Azure resources created by ccoctl
 	ownedAzureResourceTagKeyPrefix = "openshift.io_cloud-credential-operator"
 
-	// ownedAzureResourceTagValue is the value of the tag applied to the Azure resources created by ccoctl
+	// ownedAzureResourceTagValue is the default value of the tag applied to the Azure resources created by ccoctl,
+	// which may be overridden with --owned-tag-value
 	ownedAzureResourceTagValue = "owned"
+
+	// maxAzureTagValueLength is the maximum length of the value of an Azure tag
+	maxAzureTagValueLength = 256
+
+	// clusterAzureResourceTagKeyPrefix is the prefix of the tag key identifying the OpenShift cluster which owns
+	// an Azure resource, the tag key is "kubernetes.io_cluster.<cluster ID>"
+	clusterAzureResourceTagKeyPrefix = "kubernetes.io_cluster"
+
+	// creationTimestampAzureResourceTagKey is the key of the tag recording the time, in RFC 3339 format, at which
+	// ccoctl created an Azure resource so that ccoctl azure delete --older-than can determine the age of resources
+	// for which Azure does not report a creation time, such as resource groups
+	creationTimestampAzureResourceTagKey = ownedAzureResourceTagKeyPrefix + ".creation-timestamp"
+
+	// componentAzureResourceTagKey is the key of the tag identifying the component of the CredentialsRequest for which
+	// a user-assigned managed identity was created, its value is "<secret namespace>/<secret name>"
+	componentAzureResourceTagKey = ownedAzureResourceTagKeyPrefix + ".component"
 )
 
 // ensureResourceGroup ensures that a resource group with resourceGroupName exists within the provided region and subscription.
 // Resource group tags will be updated to include provided resourceTags if found to be missing from existing resource group tags.
-func ensureResourceGroup(client *azureclients.AzureClientWrapper, resourceGroupName, region string, resourceTags map[string]string) error {
+//
+// CCO's "owned" tag is only applied to resource groups created by ccoctl and will not be added to a pre-existing resource group
+// so that deletion of the resource group can be restricted to resource groups that were created by ccoctl.
+func ensureResourceGroup(ctx context.Context, client *azureclients.AzureClientWrapper, resourceGroupName, region string, resourceTags map[string]string) error {
+	ctx, span := provisioning.StartSpan(ctx, "azure.EnsureResourceGroup",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("resourceGroup"),
+		provisioning.ResourceNameAttribute.String(resourceGroupName),
+		provisioning.RegionAttribute.String(region),
+	)
+	defer span.End()
+
 	// Check if resource group already exists
 	needToCreateResourceGroup := false
 	var rawResponse *http.Response
-	ctxWithResp := runtime.WithCaptureResponse(context.Background(), &rawResponse)
+	ctxWithResp := runtime.WithCaptureResponse(ctx, &rawResponse)
 	getResourceGroupResp, err := client.ResourceGroupsClient.Get(
 		ctxWithResp,
 		resourceGroupName,
@@ -94,7 +125,13 @@ func ensureResourceGroup(client *azureclients.AzureClientWrapper, resourceGroupN
 			region)
 	}
 
+	if !needToCreateResourceGroup {
+		resourceTags = withoutOwnedResourceTags(resourceTags)
+	}
 	mergedResourceTags, needToUpdateResourceGroup := mergeResourceTags(resourceTags, getResourceGroupResp.Tags)
+	if needToCreateResourceGroup {
+		addCreationTimestampTag(mergedResourceTags)
+	}
 
 	// Found and validated existing resource group, return
 	if !needToCreateResourceGroup && !needToUpdateResourceGroup {
@@ -104,7 +141,7 @@ func ensureResourceGroup(client *azureclients.AzureClientWrapper, resourceGroupN
 
 	// Create or update resource group
 	createResourceGroupResp, err := client.ResourceGroupsClient.CreateOrUpdate(
-		context.Background(),
+		ctx,
 		resourceGroupName,
 		armresources.ResourceGroup{
 			Location: to.Ptr(region),
@@ -119,9 +156,22 @@ func ensureResourceGroup(client *azureclients.AzureClientWrapper, resourceGroupN
 		verb = "Created"
 	}
 	log.Printf("%s resource group %s", verb, *createResourceGroupResp.ResourceGroup.ID)
+	provisioning.EmitResourceEvent("ResourceGroup", *createResourceGroupResp.ResourceGroup.ID, strings.ToLower(verb))
 	return nil
 }
 
+// withoutOwnedResourceTags returns a copy of resourceTags with any of CCO's "owned" tags and cluster ownership tags removed.
+func withoutOwnedResourceTags(resourceTags map[string]string) map[string]string {
+	filteredResourceTags := map[string]string{}
+	for key, value := range resourceTags {
+		if strings.HasPrefix(key, ownedAzureResourceTagKeyPrefix+"_") || strings.HasPrefix(key, clusterAzureResourceTagKeyPrefix+".") {
+			continue
+		}
+		filteredResourceTags[key] = value
+	}
+	return filteredResourceTags
+}
+
 // Keys and values in "one" (user supplied) take precedence over those in "two" (existing objects).
 // If keys or values from "one" weren't already in "two" then the boolean return will be true.
 func mergeResourceTags(one map[string]string, two map[string]*string) (map[string]*string, bool) {
@@ -145,11 +195,19 @@ func mergeResourceTags(one map[string]string, two map[string]*string) (map[strin
 
 // ensureStorageAccount ensures that a storage account with storageAccountName exists within the provided resource group.
 // Storage account tags will be updated to include provided resourceTags if found to be missing from existing storage account tags.
-func ensureStorageAccount(client *azureclients.AzureClientWrapper, storageAccountName, resourceGroupName, region string, resourceTags map[string]string) error {
+func ensureStorageAccount(ctx context.Context, client *azureclients.AzureClientWrapper, storageAccountName, resourceGroupName, region string, resourceTags map[string]string) error {
+	ctx, span := provisioning.StartSpan(ctx, "azure.EnsureStorageAccount",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("storageAccount"),
+		provisioning.ResourceNameAttribute.String(storageAccountName),
+		provisioning.RegionAttribute.String(region),
+	)
+	defer span.End()
+
 	var existingStorageAccount *armstorage.Account
 	listAccounts := client.StorageAccountClient.NewListByResourceGroupPager(resourceGroupName, &armstorage.AccountsClientListByResourceGroupOptions{})
 	for listAccounts.More() {
-		pageResponse, err := listAccounts.NextPage(context.Background())
+		pageResponse, err := listAccounts.NextPage(ctx)
 		if err != nil {
 			return err
 		}
@@ -171,8 +229,9 @@ func ensureStorageAccount(client *azureclients.AzureClientWrapper, storageAccoun
 		mergedResourceTags = existingStorageAccount.Tags
 	}
 	mergedResourceTags, needToUpdateStorageAccount := mergeResourceTags(resourceTags, mergedResourceTags)
+	needToAllowBlobPublicAccess := !needToCreateStorageAccount && !blobPublicAccessAllowed(existingStorageAccount)
 
-	if !needToCreateStorageAccount && !needToUpdateStorageAccount {
+	if !needToCreateStorageAccount && !needToUpdateStorageAccount && !needToAllowBlobPublicAccess {
 		log.Printf("Found existing storage account %s", *existingStorageAccount.ID)
 		return nil
 	}
@@ -180,7 +239,7 @@ func ensureStorageAccount(client *azureclients.AzureClientWrapper, storageAccoun
 	// Create storage account
 	if needToCreateStorageAccount {
 		pollerResp, err := client.StorageAccountClient.BeginCreate(
-			context.TODO(),
+			ctx,
 			resourceGroupName,
 			storageAccountName,
 			armstorage.AccountCreateParameters{
@@ -190,10 +249,15 @@ func ensureStorageAccount(client *azureclients.AzureClientWrapper, storageAccoun
 				},
 				Location: to.Ptr(region),
 				Tags:     mergedResourceTags,
+				Properties: &armstorage.AccountPropertiesCreateParameters{
+					// Storage accounts disallow anonymous access to their blobs by default, which the
+					// OIDC documents must be served with
+					AllowBlobPublicAccess: to.Ptr(true),
+				},
 			},
 			&armstorage.AccountsClientBeginCreateOptions{})
 		if err != nil {
-			return err
+			return blobPublicAccessError(err, "storage account "+storageAccountName)
 		}
 		pollerWrapper := azureclients.NewPollerWrapper[armstorage.AccountsClientCreateResponse](
 			pollerResp,
@@ -203,31 +267,66 @@ func ensureStorageAccount(client *azureclients.AzureClientWrapper, storageAccoun
 			client.MockStorageClientBeginCreateResp,
 		)
 		// PollUntilDone with frequency of every 10 seconds.
-		resp, err := pollerWrapper.PollUntilDone(context.Background(), &runtime.PollUntilDoneOptions{Frequency: 10 * time.Second})
+		resp, err := pollerWrapper.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 10 * time.Second})
 		if err != nil {
-			return err
+			return blobPublicAccessError(err, "storage account "+storageAccountName)
 		}
 		log.Printf("Created storage account %s", *resp.Account.ID)
+		provisioning.EmitResourceEvent("StorageAccount", *resp.Account.ID, provisioning.ResourceCreated)
 		return nil
 	}
 
 	// Update storage account
-	updateResp, err := client.StorageAccountClient.Update(context.TODO(),
+	updateParameters := armstorage.AccountUpdateParameters{
+		Tags: mergedResourceTags,
+	}
+	if needToAllowBlobPublicAccess {
+		log.Printf("Allowing anonymous access to the blobs of existing storage account %s to serve the OIDC documents", *existingStorageAccount.ID)
+		updateParameters.Properties = &armstorage.AccountPropertiesUpdateParameters{
+			AllowBlobPublicAccess: to.Ptr(true),
+		}
+	}
+	updateResp, err := client.StorageAccountClient.Update(ctx,
 		resourceGroupName,
 		storageAccountName,
-		armstorage.AccountUpdateParameters{
-			Tags: mergedResourceTags,
-		},
+		updateParameters,
 		&armstorage.AccountsClientUpdateOptions{},
 	)
+	if err != nil {
+		return blobPublicAccessError(err, "storage account "+storageAccountName)
+	}
 	log.Printf("Updated storage account %s", *updateResp.Account.ID)
+	provisioning.EmitResourceEvent("StorageAccount", *updateResp.Account.ID, provisioning.ResourceUpdated)
+	return nil
+}
+
+// blobPublicAccessAllowed returns true when storageAccount explicitly allows anonymous access to its blobs
+func blobPublicAccessAllowed(storageAccount *armstorage.Account) bool {
+	return storageAccount.Properties != nil && storageAccount.Properties.AllowBlobPublicAccess != nil && *storageAccount.Properties.AllowBlobPublicAccess
+}
+
+// blobPublicAccessError returns an error guiding the user towards alternatives when err reports that anonymous access
+// to the blobs of resource, which the OIDC documents must be served with, was refused, either by an Azure Policy of
+// the organization or by the storage account disallowing it. Other errors are returned as is.
+func blobPublicAccessError(err error, resource string) error {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) {
+		return err
+	}
+	switch respErr.ErrorCode {
+	case "RequestDisallowedByPolicy", "PublicAccessNotPermitted":
+		return errors.Wrapf(err, "anonymous read access to the blobs of %s, with which the OIDC documents are served to identity consumers, "+
+			"was refused, likely by a policy of your organization. Either request a policy exemption for the OIDC resource group, "+
+			"or serve the OIDC documents from a private storage account through a CDN, eg. Azure Front Door, as documented in "+
+			"docs/ccoctl.md#azure-blob-public-access", resource)
+	}
 	return err
 }
 
 // getStorageAccountKey lists storage account keys for the storage account identified by storageAccountName and
 // returns the first key found.
-func getStorageAccountKey(client *azureclients.AzureClientWrapper, storageAccountName, resourceGroupName string) (string, error) {
-	listKeysResp, err := client.StorageAccountClient.ListKeys(context.Background(), resourceGroupName, storageAccountName, &armstorage.AccountsClientListKeysOptions{})
+func getStorageAccountKey(ctx context.Context, client *azureclients.AzureClientWrapper, storageAccountName, resourceGroupName string) (string, error) {
+	listKeysResp, err := client.StorageAccountClient.ListKeys(ctx, resourceGroupName, storageAccountName, &armstorage.AccountsClientListKeysOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -241,11 +340,20 @@ func getStorageAccountKey(client *azureclients.AzureClientWrapper, storageAccoun
 }
 
 // ensureBlobContainer ensures that a blob conttainer with containerName exists within the provided storage account, resource group, region and subscription
-func ensureBlobContainer(client *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName, containerName string) error {
+// and that its blobs may be read anonymously, returning the public access level of the container. New containers are given
+// the minimum level serving the OIDC documents, which allows reading blobs but not listing the container.
+func ensureBlobContainer(ctx context.Context, client *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName, containerName string) (armstorage.PublicAccess, error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.EnsureBlobContainer",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("blobContainer"),
+		provisioning.ResourceNameAttribute.String(containerName),
+	)
+	defer span.End()
+
 	// Check if blob container already exists
 	needToCreateBlobContainer := false
 	var rawResponse *http.Response
-	ctxWithResp := runtime.WithCaptureResponse(context.Background(), &rawResponse)
+	ctxWithResp := runtime.WithCaptureResponse(ctx, &rawResponse)
 	getBlobContainerResp, err := client.BlobContainerClient.Get(
 		ctxWithResp,
 		resourceGroupName,
@@ -260,126 +368,251 @@ func ensureBlobContainer(client *azureclients.AzureClientWrapper, resourceGroupN
 				needToCreateBlobContainer = true
 			default:
 				log.Printf("Unable to get blob container: %v", respErr.ErrorCode)
-				return err
+				return "", err
 			}
 		} else {
-			return err
+			return "", err
 		}
 	}
 
-	// Found and validated existing blob container, return
+	// Found existing blob container, ensure that its blobs may be read anonymously
 	if !needToCreateBlobContainer {
-		log.Printf("Found existing blob container %s", *getBlobContainerResp.BlobContainer.ID)
-		return nil
+		existingBlobContainer := getBlobContainerResp.BlobContainer
+		if existingBlobContainer.ContainerProperties != nil && existingBlobContainer.ContainerProperties.PublicAccess != nil &&
+			*existingBlobContainer.ContainerProperties.PublicAccess != armstorage.PublicAccessNone {
+			log.Printf("Found existing blob container %s", *existingBlobContainer.ID)
+			return *existingBlobContainer.ContainerProperties.PublicAccess, nil
+		}
+		updateBlobContainerResp, err := client.BlobContainerClient.Update(
+			ctx,
+			resourceGroupName,
+			storageAccountName,
+			containerName,
+			armstorage.BlobContainer{
+				ContainerProperties: &armstorage.ContainerProperties{
+					PublicAccess: to.Ptr(armstorage.PublicAccessBlob),
+				},
+			},
+			&armstorage.BlobContainersClientUpdateOptions{},
+		)
+		if err != nil {
+			return "", blobPublicAccessError(err, "blob container "+containerName)
+		}
+		log.Printf("Updated public access level of existing blob container %s to %s", *updateBlobContainerResp.BlobContainer.ID, armstorage.PublicAccessBlob)
+		provisioning.EmitResourceEvent("BlobContainer", *updateBlobContainerResp.BlobContainer.ID, provisioning.ResourceUpdated)
+		return armstorage.PublicAccessBlob, nil
 	}
 
 	createBlobContainerResp, err := client.BlobContainerClient.Create(
-		context.Background(),
+		ctx,
 		resourceGroupName,
 		storageAccountName,
 		containerName,
 		armstorage.BlobContainer{
 			// Note there is no Tags parameter within BlobContainer or ContainerProperties.
 			ContainerProperties: &armstorage.ContainerProperties{
-				PublicAccess: to.Ptr(armstorage.PublicAccessContainer),
+				PublicAccess: to.Ptr(armstorage.PublicAccessBlob),
 			},
 		},
 		&armstorage.BlobContainersClientCreateOptions{},
 	)
 	if err != nil {
-		return err
+		return "", blobPublicAccessError(err, "blob container "+containerName)
 	}
 	log.Printf("Created blob container %s", *createBlobContainerResp.BlobContainer.ID)
-	return nil
+	provisioning.EmitResourceEvent("BlobContainer", *createBlobContainerResp.BlobContainer.ID, provisioning.ResourceCreated)
+	return armstorage.PublicAccessBlob, nil
+}
+
+// normalizeIssuerURLPathPrefix strips leading and trailing slashes from the issuer URL path prefix and
+// validates that the prefix is made up of path segments containing only URL unreserved characters
+func normalizeIssuerURLPathPrefix(issuerURLPathPrefix string) (string, error) {
+	prefix := strings.Trim(issuerURLPathPrefix, "/")
+	if prefix == "" {
+		return "", nil
+	}
+	if !issuerURLPathPrefixRegexp.MatchString(prefix) {
+		return "", fmt.Errorf("invalid issuer URL path prefix: %s. The prefix may contain only letters, numbers, '.', '_', '~', '-' and '/' separators", issuerURLPathPrefix)
+	}
+	for _, segment := range strings.Split(prefix, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid issuer URL path prefix: %s. The prefix may not contain '.' or '..' path segments", issuerURLPathPrefix)
+		}
+	}
+	return prefix, nil
+}
+
+// oidcDocumentLocations returns the issuer URL along with the blob names to which the OIDC discovery document and
+// JSON web key set must be uploaded within the blob container, such that both documents are served relative to
+// the issuer URL. The issuerURLPathPrefix must have been normalized with normalizeIssuerURLPathPrefix. The issuer URL
+// is in the canonical form of provisioning.CanonicalIssuerURL, as recorded within the discovery document, the federated
+// identity credentials and the secret manifests.
+func oidcDocumentLocations(storageAccountName, blobContainerName, issuerURLPathPrefix string) (issuerURL, openidConfigurationBlobName, jwksBlobName string, err error) {
+	blobContainerURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s", storageAccountName, blobContainerName)
+	issuerURL = blobContainerURL
+	if issuerURLPathPrefix != "" {
+		issuerURL = blobContainerURL + "/" + issuerURLPathPrefix
+	}
+	if issuerURL, err = provisioning.CanonicalIssuerURL(issuerURL); err != nil {
+		return "", "", "", err
+	}
+	openidConfigurationBlobName = path.Join(issuerURLPathPrefix, ".well-known", openidConfigurationFileName)
+	jwksBlobName = path.Join(issuerURLPathPrefix, "openid/v1", jwksFileName)
+	return issuerURL, openidConfigurationBlobName, jwksBlobName, nil
+}
+
+// ensureBlobSharedKeyClient sets client.BlobSharedKeyClient to a client of the blob container at blobContainerURL
+// which authenticates with the storage account key
+func ensureBlobSharedKeyClient(client *azureclients.AzureClientWrapper, storageAccountName, storageAccountKey, blobContainerURL string) error {
+	// NOTE: It is not possible to instantiate an azureclients.AzureClientWrapper with this client
+	// because the storage key isn't known when we instantiate the other clients because the storage
+	// account doesn't or may not exist yet.
+	//
+	// client.BlobSharedKeyClient is previously set in tests for mocking so only create a real client
+	// if client.BlobSharedKeyClient is nil.
+	if client.BlobSharedKeyClient != nil {
+		return nil
+	}
+	sharedKeyCredential, err := azblob.NewSharedKeyCredential(storageAccountName, storageAccountKey)
+	if err != nil {
+		return err
+	}
+	client.BlobSharedKeyClient, err = azureclients.NewAZBlobClientWithSharedKeyCredential(blobContainerURL, sharedKeyCredential, &azblob.ClientOptions{ClientOptions: newClientOptions()})
+	return err
 }
 
 // uploadOIDCDocuments generates and uploads the OIDC discovery document (.well-known/openid-configuration) and the JSON web key set (jwks.json)
-// to the blob container
-func uploadOIDCDocuments(client *azureclients.AzureClientWrapper, storageAccountName, storageAccountKey, publicKeyFilepath, blobContainerName, targetDir string, dryRun bool, resourceTags map[string]string) (string, error) {
+// to the blob container, beneath the issuerURLPathPrefix when provided
+func uploadOIDCDocuments(ctx context.Context, client *azureclients.AzureClientWrapper, storageAccountName, storageAccountKey, publicKeyFilepath, blobContainerName, issuerURLPathPrefix, targetDir string, dryRun bool, resourceTags map[string]string, discoveryDocument provisioning.DiscoveryDocumentExtensions) (string, error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.UploadOIDCDocuments",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("blobContainer"),
+		provisioning.ResourceNameAttribute.String(blobContainerName),
+	)
+	defer span.End()
+
 	blobContainerURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s", storageAccountName, blobContainerName)
-	oidcDiscoveryDocumentData := []byte(fmt.Sprintf(openidConfigurationTemplate, blobContainerURL, blobContainerURL))
+	issuerURL, openidConfigurationBlobName, jwksBlobName, err := oidcDocumentLocations(storageAccountName, blobContainerName, issuerURLPathPrefix)
+	if err != nil {
+		return "", err
+	}
+	oidcDiscoveryDocument, err := provisioning.ExtendDiscoveryDocument(fmt.Sprintf(openidConfigurationTemplate, issuerURL, issuerURL), discoveryDocument)
+	if err != nil {
+		return issuerURL, err
+	}
+	oidcDiscoveryDocumentData := []byte(oidcDiscoveryDocument)
 	oidcDiscoveryDocumentFullPath := filepath.Join(targetDir, openidConfigurationFileName)
-	err := os.WriteFile(oidcDiscoveryDocumentFullPath, oidcDiscoveryDocumentData, fs.FileMode(fileMode))
+	err = os.WriteFile(oidcDiscoveryDocumentFullPath, oidcDiscoveryDocumentData, fs.FileMode(fileMode))
 	if err != nil {
-		return blobContainerURL, err
+		return issuerURL, err
 	}
 	log.Printf("Saved OIDC discovery document at path %s", oidcDiscoveryDocumentFullPath)
 
 	jwksFullPath := filepath.Join(targetDir, jwksFileName)
 	jwksData, err := provisioning.BuildJsonWebKeySet(publicKeyFilepath)
 	if err != nil {
-		return blobContainerURL, err
+		return issuerURL, err
 	}
 	err = os.WriteFile(jwksFullPath, jwksData, fs.FileMode(fileMode))
 	if err != nil {
-		return blobContainerURL, err
+		return issuerURL, err
 	}
 	log.Printf("Saved JSON web key set at path %s", jwksFullPath)
+	if err := provisioning.WriteJWKSPEMBundle(jwksData, targetDir); err != nil {
+		return issuerURL, err
+	}
 
 	// Return before uploading documents if doing a dry run
 	if dryRun {
-		return blobContainerURL, nil
+		return issuerURL, nil
 	}
 
-	sharedKeyCredential, err := azblob.NewSharedKeyCredential(storageAccountName, storageAccountKey)
-	if err != nil {
-		log.Fatal(err)
-		return blobContainerURL, err
-	}
-	// NOTE: It is not possible to instantiate an azureclients.AzureClientWrapper with this client
-	// because the storage key isn't known when we instantiate the other clients because the storage
-	// account doesn't or may not exist yet.
-	//
-	// client.BlobSharedKeyClient is previously set in tests for mocking so only create a real client
-	// if client.BlobSharedKeyClient is nil.
-	if client.BlobSharedKeyClient == nil {
-		client.BlobSharedKeyClient, err = azureclients.NewAZBlobClientWithSharedKeyCredential(blobContainerURL, sharedKeyCredential, nil)
-		if err != nil {
-			return blobContainerURL, err
-		}
+	if err := ensureBlobSharedKeyClient(client, storageAccountName, storageAccountKey, blobContainerURL); err != nil {
+		return issuerURL, err
 	}
 
 	_, err = client.BlobSharedKeyClient.UploadBuffer(
-		context.TODO(),
+		ctx,
 		"",
-		filepath.Join(".well-known", openidConfigurationFileName),
+		openidConfigurationBlobName,
 		oidcDiscoveryDocumentData,
 		&azblob.UploadBufferOptions{
 			Tags: resourceTags,
 		},
 	)
 	if err != nil {
-		return blobContainerURL, err
+		return issuerURL, err
 	}
-	log.Printf("Uploaded OIDC discovery document %s", blobContainerURL+"/.well-known/"+openidConfigurationFileName)
+	log.Printf("Uploaded OIDC discovery document %s", blobContainerURL+"/"+openidConfigurationBlobName)
+	provisioning.EmitResourceEvent("Blob", blobContainerURL+"/"+openidConfigurationBlobName, provisioning.ResourceUpdated)
 
 	_, err = client.BlobSharedKeyClient.UploadBuffer(
-		context.TODO(),
+		ctx,
 		"",
-		filepath.Join("openid/v1/", jwksFileName),
+		jwksBlobName,
 		jwksData,
 		&azblob.UploadBufferOptions{
 			Tags: resourceTags,
 		},
 	)
 	if err != nil {
-		return blobContainerURL, err
+		return issuerURL, err
 	}
-	log.Printf("Uploaded JSON web key set %s", blobContainerURL+"/openid/v1/"+jwksFileName)
+	log.Printf("Uploaded JSON web key set %s", blobContainerURL+"/"+jwksBlobName)
+	provisioning.EmitResourceEvent("Blob", blobContainerURL+"/"+jwksBlobName, provisioning.ResourceUpdated)
 
-	return blobContainerURL, nil
+	return issuerURL, nil
 }
 
 // createOIDCIssuer creates infrastructure necessary for Azure Workload Identity including,
 // * resource group in which to create storage account & identities
 // * scoping resource group which will remain empty and is used to scope identity role assignment, this resource group is for installation
 // * storage account
-// * blob container which hosts OIDC documents
-func createOIDCIssuer(client *azureclients.AzureClientWrapper, name, region, oidcResourceGroupName, storageAccountName, blobContainerName, subscriptionID, publicKeyPath, outputDir string, resourceTags map[string]string, dryRun bool) (string, error) {
+// * blob container which hosts OIDC documents, beneath the issuerURLPathPrefix when provided
+//
+// Progress of the OIDC issuer creation will be recorded within the provided createProgress, which may be nil,
+// and creation will be skipped if previously completed when resuming.
+func createOIDCIssuer(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, region, oidcResourceGroupName, storageAccountName, blobContainerName, issuerURLPathPrefix, subscriptionID, publicKeyPath, outputDir string, resourceTags map[string]string, discoveryDocument provisioning.DiscoveryDocumentExtensions, dryRun bool, progress *createProgress) (string, error) {
 	// Add CCO's "owned" tag to resource tags map
-	resourceTags[fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, name)] = ownedAzureResourceTagValue
+	resourceTags[ownedResourceTagKey(name)] = ownedTagValue
+
+	issuerURLPathPrefix, err := normalizeIssuerURLPathPrefix(issuerURLPathPrefix)
+	if err != nil {
+		return "", err
+	}
+	expectedIssuerURL, _, _, err := oidcDocumentLocations(storageAccountName, blobContainerName, issuerURLPathPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	var previousIssuerURL string
+	skip, err := progress.skip(oidcIssuerStep, func(step *provisioning.InventoryStep) error {
+		for _, resource := range step.Resources {
+			if resource.Type == oidcIssuerInventoryResourceType {
+				previousIssuerURL = resource.Name
+			}
+		}
+		if previousIssuerURL == "" {
+			return errors.New("no issuer URL was recorded")
+		}
+		if previousIssuerURL != expectedIssuerURL {
+			return fmt.Errorf("recorded issuer URL %s does not match issuer URL %s", previousIssuerURL, expectedIssuerURL)
+		}
+		return validateOIDCIssuer(ctx, client, oidcResourceGroupName, storageAccountName, blobContainerName)
+	})
+	if err != nil {
+		return "", err
+	}
+	if skip {
+		// Write cluster authentication object installer manifest in case it was removed from the outputDir
+		if err = provisioning.CreateClusterAuthentication(previousIssuerURL, outputDir, dryRun); err != nil {
+			return "", errors.Wrap(err, "failed to create cluster authentication manifest")
+		}
+		return previousIssuerURL, nil
+	}
 
 	storageAccountKey := ""
+	var blobContainerPublicAccess armstorage.PublicAccess
 	if !dryRun {
 		// Ensure that the public key file can be read at the publicKeyPath before continuing
 		_, err := os.ReadFile(publicKeyPath)
@@ -388,24 +621,24 @@ func createOIDCIssuer(client *azureclients.AzureClientWrapper, name, region, oid
 		}
 
 		// Ensure the resource group exists
-		err = ensureResourceGroup(client, oidcResourceGroupName, region, resourceTags)
+		err = ensureResourceGroup(ctx, client, oidcResourceGroupName, region, resourceTags)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to ensure resource group")
 		}
 
 		// Ensure storage account exists
-		err = ensureStorageAccount(client, storageAccountName, oidcResourceGroupName, region, resourceTags)
+		err = ensureStorageAccount(ctx, client, storageAccountName, oidcResourceGroupName, region, resourceTags)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to ensure storage account")
 		}
 
-		storageAccountKey, err = getStorageAccountKey(client, storageAccountName, oidcResourceGroupName)
+		storageAccountKey, err = getStorageAccountKey(ctx, client, storageAccountName, oidcResourceGroupName)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to get storage account key")
 		}
 
 		// Ensure blob container exists
-		err = ensureBlobContainer(client, oidcResourceGroupName, storageAccountName, blobContainerName)
+		blobContainerPublicAccess, err = ensureBlobContainer(ctx, client, oidcResourceGroupName, storageAccountName, blobContainerName)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to create blob container")
 		}
@@ -416,31 +649,81 @@ func createOIDCIssuer(client *azureclients.AzureClientWrapper, name, region, oid
 	if err != nil {
 		return "", err
 	}
-	issuerURL, err := uploadOIDCDocuments(client, storageAccountName, storageAccountKey, publicKeyPath, blobContainerName, outputDirAbsPath, dryRun, resourceTags)
+	issuerURL, err := uploadOIDCDocuments(ctx, client, storageAccountName, storageAccountKey, publicKeyPath, blobContainerName, issuerURLPathPrefix, outputDirAbsPath, dryRun, resourceTags, discoveryDocument)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to upload OIDC documents")
 	}
 
 	// Write cluster authentication object installer manifest cluster-authentication-02-config.yaml
 	// for our issuerURL within outputDir/manifests
-	if err = provisioning.CreateClusterAuthentication(issuerURL, outputDir); err != nil {
+	if err = provisioning.CreateClusterAuthentication(issuerURL, outputDir, dryRun); err != nil {
 		return "", errors.Wrap(err, "failed to create cluster authentication manifest")
 	}
 
+	if dryRun {
+		log.Printf("Dry run complete, no Azure resources were created or modified. OIDC documents and manifests which would have been uploaded and applied were saved to %s", outputDirAbsPath)
+		return issuerURL, nil
+	}
+
+	progress.recordSetting(allowBlobPublicAccessSetting, "true")
+	progress.recordSetting(blobContainerPublicAccessSetting, string(blobContainerPublicAccess))
+	err = progress.complete(oidcIssuerStep,
+		provisioning.InventoryResource{Type: "ResourceGroup", Name: oidcResourceGroupName, ID: resourceGroupID(subscriptionID, oidcResourceGroupName)},
+		provisioning.InventoryResource{Type: "StorageAccount", Name: storageAccountName, ID: storageAccountID(subscriptionID, oidcResourceGroupName, storageAccountName)},
+		provisioning.InventoryResource{Type: "BlobContainer", Name: blobContainerName, ID: blobContainerID(subscriptionID, oidcResourceGroupName, storageAccountName, blobContainerName)},
+		provisioning.InventoryResource{Type: oidcIssuerInventoryResourceType, Name: issuerURL},
+	)
+	if err != nil {
+		return "", err
+	}
+
 	return issuerURL, nil
 }
 
+// validateOIDCIssuer validates that the OIDC resource group and blob container hosting OIDC documents exist
+func validateOIDCIssuer(ctx context.Context, client *azureclients.AzureClientWrapper, oidcResourceGroupName, storageAccountName, blobContainerName string) error {
+	_, err := client.ResourceGroupsClient.Get(
+		ctx,
+		oidcResourceGroupName,
+		&armresources.ResourceGroupsClientGetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to get resource group %s", oidcResourceGroupName)
+	}
+	_, err = client.BlobContainerClient.Get(
+		ctx,
+		oidcResourceGroupName,
+		storageAccountName,
+		blobContainerName,
+		&armstorage.BlobContainersClientGetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to get blob container %s", blobContainerName)
+	}
+	return nil
+}
+
 func createOIDCIssuerCmd(cmd *cobra.Command, args []string) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	ctx := cmd.Context()
+	if err := CreateOIDCIssuerOpts.DiscoveryDocument.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	cred, err := newAzureCredential(ctx, CreateOIDCIssuerOpts.TenantID)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	azureClientWrapper, err := azureclients.NewAzureClientWrapper(CreateOIDCIssuerOpts.SubscriptionID, cred, &policy.ClientOptions{}, false)
+	azureClientWrapper, err := newAzureClientWrapper(CreateOIDCIssuerOpts.SubscriptionID, cred)
 	if err != nil {
 		log.Fatalf("Failed to create Azure client: %s", err)
 	}
 
+	if !CreateOIDCIssuerOpts.DryRun && !CreateOIDCIssuerOpts.SkipWritePermissionsCheck {
+		provisioning.SetPhase("checking the write permissions of the identity")
+		if err := checkWritePermissions(ctx, azureClientWrapper); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if CreateOIDCIssuerOpts.OIDCResourceGroupName == "" {
 		CreateOIDCIssuerOpts.OIDCResourceGroupName = CreateOIDCIssuerOpts.Name + oidcResourceGroupSuffix
 		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", CreateOIDCIssuerOpts.OIDCResourceGroupName)
@@ -458,21 +741,34 @@ func createOIDCIssuerCmd(cmd *cobra.Command, args []string) {
 		CreateOIDCIssuerOpts.BlobContainerName = CreateOIDCIssuerOpts.Name
 		log.Printf("No --blob-container-name provided, defaulting blob container name to %s", CreateOIDCIssuerOpts.BlobContainerName)
 	}
+	if err := validateBlobContainerName(CreateOIDCIssuerOpts.BlobContainerName); err != nil {
+		log.Fatal(err)
+	}
 
-	_, err = createOIDCIssuer(azureClientWrapper,
+	issuerURL, err := createOIDCIssuer(ctx, azureClientWrapper,
 		CreateOIDCIssuerOpts.Name,
+		CreateOIDCIssuerOpts.OwnedTagValue,
 		CreateOIDCIssuerOpts.Region,
 		CreateOIDCIssuerOpts.OIDCResourceGroupName,
 		CreateOIDCIssuerOpts.StorageAccountName,
 		CreateOIDCIssuerOpts.BlobContainerName,
+		CreateOIDCIssuerOpts.IssuerURLPathPrefix,
 		CreateOIDCIssuerOpts.SubscriptionID,
 		CreateOIDCIssuerOpts.PublicKeyPath,
 		CreateOIDCIssuerOpts.OutputDir,
 		CreateOIDCIssuerOpts.UserTags,
-		CreateOIDCIssuerOpts.DryRun)
+		CreateOIDCIssuerOpts.DiscoveryDocument,
+		CreateOIDCIssuerOpts.DryRun,
+		nil)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if CreateOIDCIssuerOpts.VerifyIssuerReachable && !CreateOIDCIssuerOpts.DryRun {
+		if err := provisioning.VerifyIssuerReachable(issuerURL); err != nil {
+			log.Fatal(err)
+		}
+	}
+	printIssuerURL(CreateOIDCIssuerOpts.PrintIssuerURL, issuerURL)
 }
 
 func validateStorageAccountName(storageAccountName string) error {
@@ -483,8 +779,38 @@ func validateStorageAccountName(storageAccountName string) error {
 	return nil
 }
 
+// blobContainerNameFormat matches lowercase letters, numbers and hyphens starting and ending with a letter or number
+// without consecutive hyphens
+var blobContainerNameFormat = regexp.MustCompile(`^[a-z0-9](-?[a-z0-9])*$`)
+
+// validateBlobContainerName ensures that blobContainerName follows the Azure naming rules of blob containers, since
+// the blob container name is also the path of the issuer URL
+func validateBlobContainerName(blobContainerName string) error {
+	if len(blobContainerName) < 3 || len(blobContainerName) > 63 || !blobContainerNameFormat.MatchString(blobContainerName) {
+		return fmt.Errorf("invalid blob container name: %s. Azure blob container names must be between 3 and 63 characters in length, "+
+			"may contain numbers, lowercase letters and hyphens only, must start and end with a letter or number and may not contain consecutive hyphens.", blobContainerName)
+	}
+	return nil
+}
+
 // initEnvForCreateOIDCIssuerCmd ensures that the output directory specified by --output-dir exists
 func initEnvForCreateOIDCIssuerCmd(cmd *cobra.Command, args []string) {
+	if CreateOIDCIssuerOpts.PrintIssuerURL {
+		if provisioning.JSONStreamEnabled() {
+			log.Fatal("--print-issuer-url may not be specified with --output=json-stream which writes progress events to stdout")
+		}
+		// stdout is reserved for the issuer URL
+		log.SetOutput(os.Stderr)
+	}
+	if err := validateOwnedTagValue(CreateOIDCIssuerOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+	if err := addClusterResourceTag(&CreateOIDCIssuerOpts); err != nil {
+		log.Fatal(err)
+	}
+	if CreateOIDCIssuerOpts.OutputDir == "" && CreateOIDCIssuerOpts.DryRun {
+		CreateOIDCIssuerOpts.OutputDir = provisioning.DryRunOutputDir("azure")
+	}
 	if CreateOIDCIssuerOpts.OutputDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -528,7 +854,7 @@ func NewCreateOIDCIssuerCmd() *cobra.Command {
 		"name",
 		"",
 		"User-defined name for all created Azure resources. This user-defined name can be separate from the cluster's infra-id. "+
-			fmt.Sprintf("Azure resources created by ccoctl will be tagged with '%s_NAME = %s'", ownedAzureResourceTagKeyPrefix, ownedAzureResourceTagValue),
+			fmt.Sprintf("Azure resources created by ccoctl will be tagged with '%s_NAME = <--owned-tag-value>'", ownedAzureResourceTagKeyPrefix),
 	)
 	createOIDCIssuerCmd.MarkPersistentFlagRequired("name")
 	createOIDCIssuerCmd.PersistentFlags().StringVar(&CreateOIDCIssuerOpts.Region, "region", "", "Azure region in which to create identity provider infrastructure")
@@ -564,11 +890,42 @@ func NewCreateOIDCIssuerCmd() *cobra.Command {
 		"The name of the Azure blob container in which to upload OIDC discovery documents. "+
 			"A blob container will be created with a name derived from the --name parameter if a --blob-container-name parameter was not provided. "+
 			"The blob container will be created within the OIDC resource group identified by the --oidc-resource-group-name parameter "+
-			"and storage account identified by --storage-account-name.",
+			"and storage account identified by --storage-account-name. The issuer URL is https://<storage account>.blob.core.windows.net/<blob container>. "+
+			blobContainerNameFlagUsage,
+	)
+	createOIDCIssuerCmd.PersistentFlags().StringVar(
+		&CreateOIDCIssuerOpts.IssuerURLPathPrefix,
+		"issuer-url-path-prefix",
+		"",
+		"Path within the blob container beneath which the OIDC discovery document and JSON web key set are uploaded. "+
+			"The issuer URL, and the jwks_uri within the OIDC discovery document, will incorporate the prefix. "+
+			"The prefix may contain only letters, numbers, '.', '_', '~', '-' and '/' separators.",
+	)
+	createOIDCIssuerCmd.PersistentFlags().BoolVar(
+		&CreateOIDCIssuerOpts.PrintIssuerURL,
+		"print-issuer-url",
+		false,
+		"Print only the issuer URL to stdout so that it may be captured by scripts, eg. ISSUER=$(ccoctl azure ... --print-issuer-url). "+
+			"All other output is written to stderr.",
 	)
 	createOIDCIssuerCmd.PersistentFlags().BoolVar(&CreateOIDCIssuerOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
-	createOIDCIssuerCmd.PersistentFlags().StringVar(&CreateOIDCIssuerOpts.OutputDir, "output-dir", "", "Directory to place generated manifest files. Defaults to the current directory.")
+	createOIDCIssuerCmd.PersistentFlags().BoolVar(&CreateOIDCIssuerOpts.SkipWritePermissionsCheck, "skip-write-permissions-check", false, "Skip probing whether the identity is permitted to make changes within the subscription before creating resources. The probe deletes a resource group with a random name which does not exist, so it never changes nor leaves behind any resource")
+	createOIDCIssuerCmd.PersistentFlags().BoolVar(&CreateOIDCIssuerOpts.VerifyIssuerReachable, "verify-issuer-reachable", false, "Fetch the OIDC discovery document and JSON web key set from the issuer URL over the public network once they have been uploaded, honoring the HTTPS_PROXY and NO_PROXY environment variables, and fail if they are not publicly served. Skipped with --dry-run")
+	createOIDCIssuerCmd.PersistentFlags().StringSliceVar(&CreateOIDCIssuerOpts.DiscoveryDocument.Audiences, "oidc-extra-audience", nil, "Audience to append to the audiences_supported of the OIDC discovery document, eg. for an identity consumer of the issuer other than the cluster. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createOIDCIssuerCmd.PersistentFlags().StringSliceVar(&CreateOIDCIssuerOpts.DiscoveryDocument.Claims, "oidc-extra-claim", nil, "Claim to append to the claims_supported of the OIDC discovery document. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createOIDCIssuerCmd.PersistentFlags().StringVar(&CreateOIDCIssuerOpts.OutputDir, "output-dir", "", "Directory to place generated manifest files. Defaults to the current directory, or a temporary directory when --dry-run is specified.")
 	createOIDCIssuerCmd.PersistentFlags().StringToStringVar(&CreateOIDCIssuerOpts.UserTags, "user-tags", map[string]string{}, "User tags to be applied to Azure resources, multiple tags may be specified comma-separated for example: --user-tags key1=value1,key2=value2")
+	createOIDCIssuerCmd.PersistentFlags().StringVar(
+		&CreateOIDCIssuerOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag applied to the Azure resources created by ccoctl, eg. to coexist with other tooling using the same tag key. ", ownedAzureResourceTagKeyPrefix)+
+			"The same value must be provided to ccoctl azure delete in order to delete the resources",
+	)
+	createOIDCIssuerCmd.PersistentFlags().StringVar(&CreateOIDCIssuerOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag created Azure resources, the resources will be tagged with 'kubernetes.io_cluster.<cluster ID> = owned'")
+	createOIDCIssuerCmd.PersistentFlags().StringVar(&CreateOIDCIssuerOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+
+	createOIDCIssuerCmd.SetGlobalNormalizationFunc(normalizeOIDCContainerNameFlag)
 
 	return createOIDCIssuerCmd
 }
@@ -4,13 +4,17 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
@@ -26,6 +30,7 @@ var (
 	testInfraName      = "testinfraname"
 	testRegionName     = "testregion"
 	testSubscriptionID = "123456789"
+	testClusterID      = "5a1c7b3e-0d6f-4c2a-9b8e-1f2d3c4b5a69"
 	testUserTags       = map[string]string{
 		"testtagname0": "testtagvalue0",
 		"testtagname1": "testtagvalue1",
@@ -33,12 +38,13 @@ var (
 		// If passing testUserTags around a lot, expect the map to be modified to include this tag.
 		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): ownedAzureResourceTagValue,
 	}
-	testOIDCResourceGroupName    = testInfraName + oidcResourceGroupSuffix
-	testInstallResourceGroupName = testInfraName
-	testStorageAccountName       = testInfraName
-	testBlobContainerName        = testInfraName
-	testDNSZoneResourceGroupName = testInfraName
-	testIssuerURL                = fmt.Sprintf("https://%s.blob.core.windows.net/%s", testBlobContainerName, testBlobContainerName)
+	testOIDCResourceGroupName     = testInfraName + oidcResourceGroupSuffix
+	testIdentityResourceGroupName = testInfraName + "-identities"
+	testInstallResourceGroupName  = testInfraName
+	testStorageAccountName        = testInfraName
+	testBlobContainerName         = testInfraName
+	testDNSZoneResourceGroupName  = testInfraName
+	testIssuerURL                 = fmt.Sprintf("https://%s.blob.core.windows.net/%s", testBlobContainerName, testBlobContainerName)
 
 	testPublicKeyFile = "publicKeyFile"
 	testPublicKeyData = "-----BEGIN PUBLIC KEY-----" +
@@ -64,7 +70,10 @@ func TestCreateOIDCIssuer(t *testing.T) {
 		setup                  func(*testing.T) string
 		verify                 func(t *testing.T, tempDirName string)
 		dryRun                 bool
-		expectError            bool
+		issuerURLPathPrefix    string
+		// expectedIssuerURL defaults to testIssuerURL when unset
+		expectedIssuerURL string
+		expectError       bool
 	}{
 		{
 			name: "Public key not found",
@@ -114,7 +123,70 @@ func TestCreateOIDCIssuer(t *testing.T) {
 				require.NoError(t, err, "errored while creating manifests directory for test")
 				return tempDirName
 			},
-			verify: func(t *testing.T, tempDirName string) {},
+			verify: func(t *testing.T, tempDirName string) {
+				inventory, err := provisioning.LoadInventory(tempDirName)
+				require.NoError(t, err, "unexpected error loading inventory")
+				require.Equal(t, "true", inventory.Settings[allowBlobPublicAccessSetting], "expected the anonymous blob access of the storage account to be recorded")
+				require.Equal(t, string(armstorage.PublicAccessBlob), inventory.Settings[blobContainerPublicAccessSetting], "expected the public access level of the blob container to be recorded")
+			},
+		},
+		{
+			name:                "OIDC issuer created with issuer URL path prefix",
+			issuerURLPathPrefix: "/prefix/path/",
+			expectedIssuerURL:   testIssuerURL + "/prefix/path",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				resourceTags, _ := mergeResourceTags(testUserTags, map[string]*string{})
+				mockStorageClientBeginCreateResp := armstorage.AccountsClientCreateResponse{
+					Account: armstorage.Account{
+						Name: to.Ptr(testStorageAccountName),
+						ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", testSubscriptionID, testOIDCResourceGroupName, testStorageAccountName)),
+						Tags: resourceTags,
+					}}
+				wrapper := mockAzureClientWrapperWithStorageClientBeginCrateResp(mockCtrl, &mockStorageClientBeginCreateResp)
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				mockCreateOrUpdateResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+				mockStorageAccountBeginCreate(wrapper, testOIDCResourceGroupName, testStorageAccountName, testRegionName, testSubscriptionID, resourceTags)
+				mockStorageAccountListKeys(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+				mockGetBlobContainerNotFound(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName)
+				mockCreateBlobContainerSuccess(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, testSubscriptionID)
+				mockBlobContainerUploadBufferSuccess(wrapper, "prefix/path/.well-known/"+openidConfigurationFileName)
+				mockBlobContainerUploadBufferSuccess(wrapper, "prefix/path/openid/v1/"+jwksFileName)
+				return wrapper
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := os.MkdirTemp(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "failed to create temp directory")
+
+				err = os.WriteFile(filepath.Join(tempDirName, testPublicKeyFile), []byte(testPublicKeyData), 0600)
+				require.NoError(t, err, "errored while setting up environment for test")
+
+				manifestsDirPath := filepath.Join(tempDirName, provisioning.ManifestsDirName)
+				err = provisioning.EnsureDir(manifestsDirPath)
+				require.NoError(t, err, "errored while creating manifests directory for test")
+				return tempDirName
+			},
+			verify: func(t *testing.T, tempDirName string) {
+				discoveryDocument, err := os.ReadFile(filepath.Join(tempDirName, openidConfigurationFileName))
+				require.NoError(t, err, "unexpected error reading OIDC discovery document")
+				require.Contains(t, string(discoveryDocument), fmt.Sprintf(`"issuer": "%s/prefix/path"`, testIssuerURL))
+				require.Contains(t, string(discoveryDocument), fmt.Sprintf(`"jwks_uri": "%s/prefix/path/openid/v1/jwks"`, testIssuerURL))
+			},
+		},
+		{
+			name:                "Invalid issuer URL path prefix",
+			issuerURLPathPrefix: "prefix/../path",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				return wrapper
+			},
+			setup: func(t *testing.T) string {
+				tempDirName, err := os.MkdirTemp(os.TempDir(), testDirPrefix)
+				require.NoError(t, err, "failed to create temp directory")
+				return tempDirName
+			},
+			expectError: true,
+			verify:      func(t *testing.T, tempDirName string) {},
 		},
 	}
 	for _, test := range tests {
@@ -127,25 +199,36 @@ func TestCreateOIDCIssuer(t *testing.T) {
 			defer os.RemoveAll(tempDirName)
 
 			testPublicKeyPath := filepath.Join(tempDirName, testPublicKeyFile)
+			progress, err := newCreateProgress(tempDirName, testInfraName, testRegionName, testSubscriptionID, false, false)
+			require.NoError(t, err, "unexpected error creating progress")
 
 			issuerURL, err := createOIDCIssuer(
+				context.Background(),
 				mockAzureClientWrapper,
 				testInfraName,
+				ownedAzureResourceTagValue,
 				testRegionName,
 				testOIDCResourceGroupName,
 				testStorageAccountName,
 				testBlobContainerName,
+				test.issuerURLPathPrefix,
 				testSubscriptionID,
 				testPublicKeyPath,
 				tempDirName,
 				testUserTags,
-				test.dryRun)
+				provisioning.DiscoveryDocumentExtensions{},
+				test.dryRun,
+				progress)
 			if test.expectError {
 				require.Error(t, err, "expected error")
 			} else {
 				require.NoError(t, err, "unexpected error")
 				test.verify(t, tempDirName)
-				require.Equal(t, fmt.Sprintf("https://%s.blob.core.windows.net/%s", testBlobContainerName, testBlobContainerName), issuerURL, "unexpected issuerURL returned")
+				expectedIssuerURL := test.expectedIssuerURL
+				if expectedIssuerURL == "" {
+					expectedIssuerURL = testIssuerURL
+				}
+				require.Equal(t, expectedIssuerURL, issuerURL, "unexpected issuerURL returned")
 			}
 		})
 	}
@@ -188,7 +271,7 @@ func TestEnsureResourceGroup(t *testing.T) {
 			expectError: true,
 		},
 		{
-			name: "Pre-existing resource group found with missing and different tags, resource group updated",
+			name: "Pre-existing resource group found with missing and different tags, resource group updated without owned tag",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
 				wrapper := mockAzureClientWrapper(mockCtrl)
 				gotResourceTags := map[string]*string{
@@ -199,7 +282,6 @@ func TestEnsureResourceGroup(t *testing.T) {
 					"testtagname0":     to.Ptr("testtagvalue0"),
 					"testtagname1":     to.Ptr("testtagvalue1"),
 					"existingtagname0": to.Ptr("existingtagvalue0"),
-					fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
 				}
 				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, gotResourceTags)
 				mockCreateOrUpdateResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, wantResourceTags)
@@ -211,7 +293,7 @@ func TestEnsureResourceGroup(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
-			err := ensureResourceGroup(mockAzureClientWrapper, testOIDCResourceGroupName, testRegionName, testUserTags)
+			err := ensureResourceGroup(context.Background(), mockAzureClientWrapper, testOIDCResourceGroupName, testRegionName, testUserTags)
 			if test.expectError {
 				require.Error(t, err, "expected error")
 			} else {
@@ -226,6 +308,7 @@ func TestEnsureStorageAccount(t *testing.T) {
 		name                   string
 		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
 		expectError            bool
+		expectErrorContains    string
 	}{
 		{
 			name: "Pre-existing storage account not found, storage account created",
@@ -252,6 +335,42 @@ func TestEnsureStorageAccount(t *testing.T) {
 				return wrapper
 			},
 		},
+		{
+			name: "Pre-existing storage account found disallowing blob public access, storage account updated to allow it",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				resourceTags, _ := mergeResourceTags(testUserTags, map[string]*string{})
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockStorageAccountListByResourceGroupPagerWithAccounts(wrapper, testOIDCResourceGroupName, &armstorage.Account{
+					Name:       to.Ptr(testStorageAccountName),
+					Location:   to.Ptr(testRegionName),
+					ID:         to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", testSubscriptionID, testOIDCResourceGroupName, testStorageAccountName)),
+					Tags:       resourceTags,
+					Properties: &armstorage.AccountProperties{AllowBlobPublicAccess: to.Ptr(false)},
+				})
+				mockUpdateStorageAccountWithParametersSuccess(wrapper, testOIDCResourceGroupName, testStorageAccountName, testRegionName, testSubscriptionID, armstorage.AccountUpdateParameters{
+					Tags:       resourceTags,
+					Properties: &armstorage.AccountPropertiesUpdateParameters{AllowBlobPublicAccess: to.Ptr(true)},
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "Storage account creation disallowed by policy",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				resourceTags, _ := mergeResourceTags(testUserTags, map[string]*string{})
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, resourceTags)
+				respHeader := http.Header{}
+				respHeader.Set("x-ms-error-code", "RequestDisallowedByPolicy")
+				wrapper.StorageAccountClient.(*mockazure.MockAccountsClient).EXPECT().BeginCreate(gomock.Any(), testOIDCResourceGroupName, testStorageAccountName, gomock.Any(), gomock.Any()).Return(
+					nil,
+					NewResponseError(&http.Response{StatusCode: http.StatusForbidden, Header: respHeader, Body: http.NoBody, Request: httptest.NewRequest(http.MethodPut, "https://management.azure.com", nil)}),
+				)
+				return wrapper
+			},
+			expectError:         true,
+			expectErrorContains: "private storage account through a CDN",
+		},
 		{
 			name: "Pre-existing storage account found with missing and different tags, storage account updated",
 			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
@@ -276,9 +395,10 @@ func TestEnsureStorageAccount(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
-			err := ensureStorageAccount(mockAzureClientWrapper, testStorageAccountName, testOIDCResourceGroupName, testRegionName, testUserTags)
+			err := ensureStorageAccount(context.Background(), mockAzureClientWrapper, testStorageAccountName, testOIDCResourceGroupName, testRegionName, testUserTags)
 			if test.expectError {
 				require.Error(t, err, "expected error")
+				require.Contains(t, err.Error(), test.expectErrorContains, "unexpected error")
 			} else {
 				require.NoError(t, err, "unexpected error")
 			}
@@ -290,6 +410,7 @@ func TestEnsureBlobContainer(t *testing.T) {
 	tests := []struct {
 		name                   string
 		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		expectPublicAccess     armstorage.PublicAccess
 		expectError            bool
 	}{
 		{
@@ -300,6 +421,7 @@ func TestEnsureBlobContainer(t *testing.T) {
 				mockCreateBlobContainerSuccess(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, testSubscriptionID)
 				return wrapper
 			},
+			expectPublicAccess: armstorage.PublicAccessBlob,
 		},
 		{
 			name: "Pre-existing blob container found, blob container not created",
@@ -308,6 +430,51 @@ func TestEnsureBlobContainer(t *testing.T) {
 				mockGetBlobContainerFound(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, testSubscriptionID)
 				return wrapper
 			},
+			expectPublicAccess: armstorage.PublicAccessBlob,
+		},
+		{
+			name: "Pre-existing blob container found allowing container listing, public access level kept",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetBlobContainerFoundWithPublicAccess(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, testSubscriptionID, armstorage.PublicAccessContainer)
+				return wrapper
+			},
+			expectPublicAccess: armstorage.PublicAccessContainer,
+		},
+		{
+			name: "Pre-existing blob container found without public access, public access level updated",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetBlobContainerFoundWithPublicAccess(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, testSubscriptionID, armstorage.PublicAccessNone)
+				wrapper.BlobContainerClient.(*mockazure.MockBlobContainersClient).EXPECT().Update(gomock.Any(), testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, armstorage.BlobContainer{
+					ContainerProperties: &armstorage.ContainerProperties{PublicAccess: to.Ptr(armstorage.PublicAccessBlob)},
+				}, gomock.Any()).Return(
+					armstorage.BlobContainersClientUpdateResponse{
+						BlobContainer: armstorage.BlobContainer{
+							ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s/blobServices/default/containers/%s", testSubscriptionID, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName)),
+							Name: to.Ptr(testBlobContainerName),
+						},
+					},
+					nil, // no error
+				)
+				return wrapper
+			},
+			expectPublicAccess: armstorage.PublicAccessBlob,
+		},
+		{
+			name: "Blob container creation refused by storage account disallowing public access",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetBlobContainerNotFound(wrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName)
+				respHeader := http.Header{}
+				respHeader.Set("x-ms-error-code", "PublicAccessNotPermitted")
+				wrapper.BlobContainerClient.(*mockazure.MockBlobContainersClient).EXPECT().Create(gomock.Any(), testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName, gomock.Any(), gomock.Any()).Return(
+					armstorage.BlobContainersClientCreateResponse{},
+					NewResponseError(&http.Response{StatusCode: http.StatusConflict, Header: respHeader, Body: http.NoBody, Request: httptest.NewRequest(http.MethodPut, "https://management.azure.com", nil)}),
+				)
+				return wrapper
+			},
+			expectError: true,
 		},
 	}
 
@@ -315,11 +482,13 @@ func TestEnsureBlobContainer(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
-			err := ensureBlobContainer(mockAzureClientWrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName)
+			publicAccess, err := ensureBlobContainer(context.Background(), mockAzureClientWrapper, testOIDCResourceGroupName, testStorageAccountName, testBlobContainerName)
 			if test.expectError {
 				require.Error(t, err, "expected error")
+				require.Contains(t, err.Error(), "policy exemption", "expected guidance within the error")
 			} else {
 				require.NoError(t, err, "unexpected error")
+				require.Equal(t, test.expectPublicAccess, publicAccess, "unexpected public access level")
 			}
 		})
 	}
@@ -338,6 +507,7 @@ func mockAzureClientWrapper(mockCtrl *gomock.Controller) *azureclients.AzureClie
 	wrapper.UserAssignedIdentitiesClient = mockazure.NewMockUserAssignedIdentitiesClient(mockCtrl)
 	wrapper.RoleDefinitionsClient = mockazure.NewMockRoleDefinitionsClient(mockCtrl)
 	wrapper.RoleAssignmentClient = mockazure.NewMockRoleAssignmentsClient(mockCtrl)
+	wrapper.PermissionsClient = mockazure.NewMockPermissionsClient(mockCtrl)
 	wrapper.FederatedIdentityCredentialsClient = mockazure.NewMockFederatedIdentityCredentialsClient(mockCtrl)
 	// Mock = true so that runtime.Poller operations will be mocked by an azureclients.PollerWrapper
 	wrapper.Mock = true
@@ -357,7 +527,7 @@ func mockCreateOrUpdateResourceGroupSuccess(wrapper *azureclients.AzureClientWra
 		Location: to.Ptr(region),
 		Tags:     tags,
 	}
-	wrapper.ResourceGroupsClient.(*mockazure.MockResourceGroupsClient).EXPECT().CreateOrUpdate(gomock.Any(), resourceGroupName, parameters, gomock.Any()).Return(
+	wrapper.ResourceGroupsClient.(*mockazure.MockResourceGroupsClient).EXPECT().CreateOrUpdate(gomock.Any(), resourceGroupName, withCreationTimestampTag(parameters), gomock.Any()).Return(
 		armresources.ResourceGroupsClientCreateOrUpdateResponse{
 			ResourceGroup: armresources.ResourceGroup{
 				Name: to.Ptr(resourceGroupName),
@@ -369,6 +539,51 @@ func mockCreateOrUpdateResourceGroupSuccess(wrapper *azureclients.AzureClientWra
 	)
 }
 
+// creationTimestampTagMatcher matches resource group or user-assigned managed identity parameters equal to expected
+// once any valid creation timestamp tag, whose value varies with the time at which the resource was created, is removed
+type creationTimestampTagMatcher struct {
+	expected interface{}
+}
+
+// withCreationTimestampTag returns a matcher of parameters equal to expected, which may additionally carry the creation
+// timestamp tag applied by ccoctl to the resources it creates
+func withCreationTimestampTag(expected interface{}) gomock.Matcher {
+	return creationTimestampTagMatcher{expected: expected}
+}
+
+func (m creationTimestampTagMatcher) Matches(x interface{}) bool {
+	withoutCreationTimestampTag := func(tags map[string]*string) (map[string]*string, bool) {
+		value, found := tags[creationTimestampAzureResourceTagKey]
+		if !found {
+			return tags, true
+		}
+		if _, err := time.Parse(time.RFC3339, *value); err != nil {
+			return nil, false
+		}
+		filteredTags := map[string]*string{}
+		for key, value := range tags {
+			if key != creationTimestampAzureResourceTagKey {
+				filteredTags[key] = value
+			}
+		}
+		return filteredTags, true
+	}
+	valid := false
+	switch parameters := x.(type) {
+	case armresources.ResourceGroup:
+		parameters.Tags, valid = withoutCreationTimestampTag(parameters.Tags)
+		x = parameters
+	case armmsi.Identity:
+		parameters.Tags, valid = withoutCreationTimestampTag(parameters.Tags)
+		x = parameters
+	}
+	return valid && gomock.Eq(m.expected).Matches(x)
+}
+
+func (m creationTimestampTagMatcher) String() string {
+	return fmt.Sprintf("%s, ignoring the %s tag", gomock.Eq(m.expected), creationTimestampAzureResourceTagKey)
+}
+
 func mockGetResourceGroupSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, regionName, subscriptionID string, tags map[string]*string) {
 	wrapper.ResourceGroupsClient.(*mockazure.MockResourceGroupsClient).EXPECT().Get(gomock.Any(), resourceGroupName, gomock.Any()).Return(
 		armresources.ResourceGroupsClientGetResponse{
@@ -401,19 +616,25 @@ func mockGetResourceGroupNotFound(wrapper *azureclients.AzureClientWrapper, reso
 }
 
 func mockStorageAccountListByResourceGroupPager(wrapper *azureclients.AzureClientWrapper, existingStorageAccountNames []string, resourceGroupName, regionName, subscriptionID string, tags map[string]*string) {
+	existingStorageAccounts := []*armstorage.Account{}
+	for _, storageAccountName := range existingStorageAccountNames {
+		existingStorageAccounts = append(existingStorageAccounts, &armstorage.Account{
+			Name:       to.Ptr(storageAccountName),
+			Location:   to.Ptr(regionName),
+			ID:         to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, storageAccountName)),
+			Tags:       tags,
+			Properties: &armstorage.AccountProperties{AllowBlobPublicAccess: to.Ptr(true)},
+		})
+	}
+	mockStorageAccountListByResourceGroupPagerWithAccounts(wrapper, resourceGroupName, existingStorageAccounts...)
+}
+
+func mockStorageAccountListByResourceGroupPagerWithAccounts(wrapper *azureclients.AzureClientWrapper, resourceGroupName string, existingStorageAccounts ...*armstorage.Account) {
 	accountListByResourceGroupResult := armstorage.AccountsClientListByResourceGroupResponse{
 		AccountListResult: armstorage.AccountListResult{
-			Value: []*armstorage.Account{},
+			Value: existingStorageAccounts,
 		},
 	}
-	for _, storageAccountName := range existingStorageAccountNames {
-		accountListByResourceGroupResult.Value = append(accountListByResourceGroupResult.Value, &armstorage.Account{
-			Name:     to.Ptr(storageAccountName),
-			Location: to.Ptr(regionName),
-			ID:       to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, storageAccountName)),
-			Tags:     tags,
-		})
-	}
 
 	wrapper.StorageAccountClient.(*mockazure.MockAccountsClient).EXPECT().NewListByResourceGroupPager(resourceGroupName, gomock.Any()).Return(
 		runtime.NewPager(runtime.PagingHandler[armstorage.AccountsClientListByResourceGroupResponse]{
@@ -435,6 +656,9 @@ func mockStorageAccountBeginCreate(wrapper *azureclients.AzureClientWrapper, res
 		},
 		Location: to.Ptr(region),
 		Tags:     tags,
+		Properties: &armstorage.AccountPropertiesCreateParameters{
+			AllowBlobPublicAccess: to.Ptr(true),
+		},
 	}
 
 	// This poller is not returned from subsequent PollerWrapper.PollUntilDone() and is just instantiated
@@ -452,15 +676,18 @@ func mockStorageAccountBeginCreate(wrapper *azureclients.AzureClientWrapper, res
 }
 
 func mockUpdateStorageAccountSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName, regionName, subscriptionID string, tags map[string]*string) {
-	accountsClientUpdateParameters := armstorage.AccountUpdateParameters{
+	mockUpdateStorageAccountWithParametersSuccess(wrapper, resourceGroupName, storageAccountName, regionName, subscriptionID, armstorage.AccountUpdateParameters{
 		Tags: tags,
-	}
+	})
+}
+
+func mockUpdateStorageAccountWithParametersSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName, regionName, subscriptionID string, accountsClientUpdateParameters armstorage.AccountUpdateParameters) {
 	accountsClientUpdateResponse := armstorage.AccountsClientUpdateResponse{
 		Account: armstorage.Account{
 			Name:     to.Ptr(storageAccountName),
 			Location: to.Ptr(regionName),
 			ID:       to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", subscriptionID, resourceGroupName, storageAccountName)),
-			Tags:     tags,
+			Tags:     accountsClientUpdateParameters.Tags,
 		}}
 	wrapper.StorageAccountClient.(*mockazure.MockAccountsClient).EXPECT().Update(gomock.Any(), resourceGroupName, storageAccountName, accountsClientUpdateParameters, gomock.Any()).Return(
 		accountsClientUpdateResponse,
@@ -498,11 +725,18 @@ func mockGetBlobContainerNotFound(wrapper *azureclients.AzureClientWrapper, reso
 }
 
 func mockGetBlobContainerFound(wrapper *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName, blobContainerName, subscriptionID string) {
+	mockGetBlobContainerFoundWithPublicAccess(wrapper, resourceGroupName, storageAccountName, blobContainerName, subscriptionID, armstorage.PublicAccessBlob)
+}
+
+func mockGetBlobContainerFoundWithPublicAccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName, blobContainerName, subscriptionID string, publicAccess armstorage.PublicAccess) {
 	wrapper.BlobContainerClient.(*mockazure.MockBlobContainersClient).EXPECT().Get(gomock.Any(), resourceGroupName, storageAccountName, blobContainerName, gomock.Any()).Return(
 		armstorage.BlobContainersClientGetResponse{
 			BlobContainer: armstorage.BlobContainer{
 				ID:   to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s/blobServices/default/containers/%s", subscriptionID, resourceGroupName, storageAccountName, blobContainerName)),
 				Name: to.Ptr(blobContainerName),
+				ContainerProperties: &armstorage.ContainerProperties{
+					PublicAccess: to.Ptr(publicAccess),
+				},
 			},
 		},
 		nil, // no error
@@ -554,3 +788,73 @@ func NewResponseError(resp *http.Response) error {
 
 	return respErr
 }
+
+func TestValidateBlobContainerName(t *testing.T) {
+	tests := []struct {
+		name              string
+		blobContainerName string
+		expectError       bool
+	}{
+		{
+			name:              "Default blob container name",
+			blobContainerName: testInfraName,
+		},
+		{
+			name:              "Blob container name with hyphens and numbers",
+			blobContainerName: "oidc-issuer-01",
+		},
+		{
+			name:              "Blob container name too short",
+			blobContainerName: "ab",
+			expectError:       true,
+		},
+		{
+			name:              "Blob container name too long",
+			blobContainerName: strings.Repeat("a", 64),
+			expectError:       true,
+		},
+		{
+			name:              "Uppercase letters",
+			blobContainerName: "OIDC-issuer",
+			expectError:       true,
+		},
+		{
+			name:              "Leading hyphen",
+			blobContainerName: "-oidc",
+			expectError:       true,
+		},
+		{
+			name:              "Trailing hyphen",
+			blobContainerName: "oidc-",
+			expectError:       true,
+		},
+		{
+			name:              "Consecutive hyphens",
+			blobContainerName: "oidc--issuer",
+			expectError:       true,
+		},
+		{
+			name:              "Invalid characters",
+			blobContainerName: "oidc_issuer",
+			expectError:       true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateBlobContainerName(test.blobContainerName)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestOIDCContainerNameFlag(t *testing.T) {
+	defer func(opts azureOptions) { CreateOIDCIssuerOpts = opts }(CreateOIDCIssuerOpts)
+
+	cmd := NewCreateOIDCIssuerCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--oidc-container-name=oidc-issuer"}), "unexpected error parsing flags")
+	require.Equal(t, "oidc-issuer", CreateOIDCIssuerOpts.BlobContainerName, "--oidc-container-name not treated as --blob-container-name")
+}
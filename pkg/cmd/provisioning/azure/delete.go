@@ -4,17 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 )
 
 var (
@@ -23,70 +32,338 @@ var (
 	DeleteOpts = azureOptions{}
 )
 
-// deleteManagedIdentities lists user-assigned managed identities and deletes those with CCO's "owned" tag.
-func deleteManagedIdentities(client *azureclients.AzureClientWrapper, name, resourceGroupName, subscriptionID, region string) error {
+// listOwnedManagedIdentities lists user-assigned managed identities within the resource group identified by
+// resourceGroupName which carry CCO's "owned" tag for the provided name and, when clusterID is provided, the tag
+// identifying the OpenShift cluster with ID clusterID as their owner.
+func listOwnedManagedIdentities(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, clusterID, resourceGroupName string) ([]*armmsi.Identity, error) {
 	listManagedIdentities := client.UserAssignedIdentitiesClient.NewListByResourceGroupPager(
 		resourceGroupName,
 		&armmsi.UserAssignedIdentitiesClientListByResourceGroupOptions{},
 	)
-	ownedTagKey := fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, name)
 	managedIdentities := make([]*armmsi.Identity, 0)
 	for listManagedIdentities.More() {
-		pageResponse, err := listManagedIdentities.NextPage(context.Background())
+		pageResponse, err := listManagedIdentities.NextPage(ctx)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		// Find managed identities within the resource group that have CCO's "owned" tag.
 		// The "owned" tag key includes the name argument provided to "ccoctl create-managed-identities"
-		// so ccoctl will only delete identites that ccoctl created.
+		// so ccoctl will only operate on identites that ccoctl created.
 		//
 		// Key: "openshift.io_cloud-credential-operator_<name>"
-		// Value: "owned"
+		// Value: "owned", unless overridden with --owned-tag-value
 		for _, identity := range pageResponse.UserAssignedIdentitiesListResult.Value {
-			if nameTagValue, found := identity.Tags[ownedTagKey]; found && *nameTagValue == ownedAzureResourceTagValue {
+			if hasOwnedResourceTag(identity.Tags, name, ownedTagValue) {
+				if !hasClusterResourceTag(identity.Tags, clusterID) {
+					log.Printf("Skipping user-assigned managed identity %s which does not have tag key=%s, value=%s",
+						*identity.Name, clusterResourceTagKey(clusterID), provisioning.ClusterResourceTagValue)
+					continue
+				}
 				managedIdentities = append(managedIdentities, identity)
 			}
 		}
 	}
 	if len(managedIdentities) == 0 {
-		log.Printf("Found no user-assigned managed identities with tag key=%s, value=%s", ownedTagKey, ownedAzureResourceTagValue)
-		return nil
+		log.Printf("Found no user-assigned managed identities with tag key=%s, value=%s", ownedResourceTagKey(name), ownedTagValue)
+	}
+	return managedIdentities, nil
+}
+
+// managedIdentityComponentMatcher returns a function matching the user-assigned managed identities owned by name
+// which belong to the component identified by componentFilter, either a prefix of the name of the identities or the
+// <secret namespace>/<secret name> of the component's CredentialsRequest
+func managedIdentityComponentMatcher(name, componentFilter string) (func(identity *armmsi.Identity) bool, error) {
+	if secretNamespace, secretName, found := strings.Cut(componentFilter, "/"); found {
+		if secretNamespace == "" || secretName == "" || strings.Contains(secretName, "/") {
+			return nil, fmt.Errorf("invalid --component-filter %q, expected <secret namespace>/<secret name>", componentFilter)
+		}
+		expectedName := defaultManagedIdentityName(name, &credreqv1.CredentialsRequest{
+			Spec: credreqv1.CredentialsRequestSpec{
+				SecretRef: corev1.ObjectReference{Namespace: secretNamespace, Name: secretName},
+			},
+		})
+		return func(identity *armmsi.Identity) bool {
+			if component, tagged := identity.Tags[componentAzureResourceTagKey]; tagged && component != nil {
+				return *component == componentFilter
+			}
+			return *identity.Name == expectedName
+		}, nil
+	}
+	prefix := fmt.Sprintf("%s-%s", name, componentFilter)
+	return func(identity *armmsi.Identity) bool {
+		return *identity.Name == prefix || strings.HasPrefix(*identity.Name, prefix+"-")
+	}, nil
+}
+
+// filterManagedIdentitiesByComponent returns the user-assigned managed identity created for the component identified by
+// componentFilter from managedIdentities, which carry the tag of the component or, when created without the tag, are
+// named "<name>-<secret namespace>-<secret name>" after the secret targeted by the component's CredentialsRequest.
+//
+// componentFilter is either the "<secret namespace>/<secret name>" of the component's CredentialsRequest or a prefix of
+// "<secret namespace>-<secret name>" ending at a "-" boundary, eg. the secret namespace, which is matched against the
+// names of the identities. An error is returned unless exactly one managed identity matches so that only the intended
+// component's resources are ever deleted.
+func filterManagedIdentitiesByComponent(managedIdentities []*armmsi.Identity, name, componentFilter string) ([]*armmsi.Identity, error) {
+	matchesComponent, err := managedIdentityComponentMatcher(name, componentFilter)
+	if err != nil {
+		return nil, err
 	}
+
+	matches := []*armmsi.Identity{}
+	matchNames := []string{}
 	for _, identity := range managedIdentities {
-		_, err := client.UserAssignedIdentitiesClient.Delete(
-			context.Background(),
-			resourceGroupName,
-			*identity.Name,
-			&armmsi.UserAssignedIdentitiesClientDeleteOptions{},
-		)
+		if identity.Name != nil && matchesComponent(identity) {
+			matches = append(matches, identity)
+			matchNames = append(matchNames, *identity.Name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("found no user-assigned managed identity owned by %s for component %s", name, componentFilter)
+	case 1:
+		return matches, nil
+	default:
+		return nil, fmt.Errorf("refusing to delete, component %s matches multiple user-assigned managed identities owned by %s: %s. "+
+			"Specify the <secret namespace>/<secret name> of the component's CredentialsRequest to identify a single component",
+			componentFilter, name, strings.Join(matchNames, ", "))
+	}
+}
+
+// filterManagedIdentitiesOlderThan returns the user-assigned managed identities within managedIdentities which were
+// created more than olderThan ago. The creation time reported by Azure is preferred over the creation timestamp tag
+// applied by ccoctl and identities whose creation time is unknown are not returned.
+func filterManagedIdentitiesOlderThan(managedIdentities []*armmsi.Identity, olderThan time.Duration) []*armmsi.Identity {
+	if olderThan == 0 {
+		return managedIdentities
+	}
+	olderIdentities := []*armmsi.Identity{}
+	for _, identity := range managedIdentities {
+		var createdAt *time.Time
+		if identity.SystemData != nil {
+			createdAt = identity.SystemData.CreatedAt
+		}
+		if isOlderThan("user-assigned managed identity "+*identity.Name, createdAt, identity.Tags, olderThan) {
+			olderIdentities = append(olderIdentities, identity)
+		}
+	}
+	return olderIdentities
+}
+
+// filterManagedIdentitiesByTagSelector returns the user-assigned managed identities within managedIdentities whose
+// tags match selector
+func filterManagedIdentitiesByTagSelector(managedIdentities []*armmsi.Identity, selector provisioning.TagSelector) []*armmsi.Identity {
+	if selector.Empty() {
+		return managedIdentities
+	}
+	selectedIdentities := []*armmsi.Identity{}
+	for _, identity := range managedIdentities {
+		if matchesTagSelector("user-assigned managed identity "+*identity.Name, identity.Tags, selector) {
+			selectedIdentities = append(selectedIdentities, identity)
+		}
+	}
+	return selectedIdentities
+}
+
+// deleteManagedIdentities lists user-assigned managed identities and deletes those with CCO's "owned" tag, and the
+// cluster ownership tag when clusterID is provided. When componentFilter is provided only the user-assigned managed
+// identity of the identified component is deleted. When olderThan is provided only user-assigned managed identities
+// created more than olderThan ago are deleted. When tagSelector is not empty only user-assigned managed identities whose
+// tags match tagSelector are deleted. When dryRun is provided the identities are logged but not deleted.
+func deleteManagedIdentities(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, clusterID, resourceGroupName, subscriptionID, region, componentFilter string, olderThan time.Duration, tagSelector provisioning.TagSelector, dryRun bool) error {
+	managedIdentities, err := listOwnedManagedIdentities(ctx, client, name, ownedTagValue, clusterID, resourceGroupName)
+	if err != nil {
+		return err
+	}
+	if componentFilter != "" {
+		managedIdentities, err = filterManagedIdentitiesByComponent(managedIdentities, name, componentFilter)
 		if err != nil {
 			return err
 		}
-		log.Printf("Deleted %s %s", *identity.Type, *identity.ID)
 	}
+	managedIdentities = filterManagedIdentitiesByTagSelector(managedIdentities, tagSelector)
+	for _, identity := range filterManagedIdentitiesOlderThan(managedIdentities, olderThan) {
+		if err := deleteManagedIdentity(ctx, client, identity, resourceGroupName, region, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteManagedIdentity deletes the user-assigned managed identity within the resource group identified by
+// resourceGroupName. When dryRun is provided the identity is logged but not deleted.
+func deleteManagedIdentity(ctx context.Context, client *azureclients.AzureClientWrapper, identity *armmsi.Identity, resourceGroupName, region string, dryRun bool) error {
+	if dryRun {
+		log.Printf("Would delete %s %s", *identity.Type, *identity.ID)
+		provisioning.RecordDeletionCandidate("UserAssignedManagedIdentity")
+		return nil
+	}
+	ctx, span := provisioning.StartSpan(ctx, "azure.DeleteUserAssignedManagedIdentity",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("userAssignedManagedIdentity"),
+		provisioning.ResourceNameAttribute.String(*identity.Name),
+		provisioning.RegionAttribute.String(region),
+	)
+	_, err := client.UserAssignedIdentitiesClient.Delete(
+		ctx,
+		resourceGroupName,
+		*identity.Name,
+		&armmsi.UserAssignedIdentitiesClientDeleteOptions{},
+	)
+	provisioning.EndSpan(span, err)
+	if err != nil {
+		return err
+	}
+	log.Printf("Deleted %s %s", *identity.Type, *identity.ID)
+	provisioning.EmitResourceEvent("UserAssignedManagedIdentity", *identity.ID, provisioning.ResourceDeleted)
 	return nil
 }
 
-func deleteResourceGroup(client *azureclients.AzureClientWrapper, resourceGroupName string) error {
+// deleteResourceGroup deletes the resource group identified by resourceGroupName along with everything within it.
+//
+// The resource group will only be deleted if it carries CCO's "owned" tag for the provided name, which is applied
+// when ccoctl creates the resource group, so that a pre-existing resource group which merely contains ccoctl
+// created resources is not deleted. When clusterID is provided the resource group must additionally carry the tag
+// identifying the OpenShift cluster with ID clusterID as its owner. Providing force will skip these checks.
+//
+// When olderThan is provided the resource group is only deleted if the creation timestamp tag applied when ccoctl
+// created the resource group records that it was created more than olderThan ago, this check is not skipped by force.
+// When tagSelector is not empty the resource group is only deleted if its tags match tagSelector. When dryRun is
+// provided the resource group is logged but not deleted.
+func deleteResourceGroup(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, clusterID, resourceGroupName string, force bool, olderThan time.Duration, tagSelector provisioning.TagSelector, dryRun bool) error {
+	ctx, span := provisioning.StartSpan(ctx, "azure.DeleteResourceGroup",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("resourceGroup"),
+		provisioning.ResourceNameAttribute.String(resourceGroupName),
+	)
+	defer span.End()
+
+	if !force || olderThan != 0 || !tagSelector.Empty() {
+		getResourceGroupResp, err := client.ResourceGroupsClient.Get(
+			ctx,
+			resourceGroupName,
+			&armresources.ResourceGroupsClientGetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get resource group")
+		}
+		if err := validateResourceGroupOwnership(getResourceGroupResp.Tags, name, ownedTagValue, clusterID, resourceGroupName, force); err != nil {
+			return err
+		}
+		if !matchesTagSelector("resource group "+resourceGroupName, getResourceGroupResp.Tags, tagSelector) {
+			return nil
+		}
+		if !isOlderThan("resource group "+resourceGroupName, nil, getResourceGroupResp.Tags, olderThan) {
+			return nil
+		}
+	}
+
+	if dryRun {
+		log.Printf("Would delete resource group %s", resourceGroupName)
+		provisioning.RecordDeletionCandidate("ResourceGroup")
+		return nil
+	}
+
 	pollerResp, err := client.ResourceGroupsClient.BeginDelete(
-		context.Background(),
+		ctx,
 		resourceGroupName,
 		&armresources.ResourceGroupsClientBeginDeleteOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to delete resource group")
 	}
-	// Stomped return is an armresources.ResourceGroupsClientDeleteResponse which is an empty struct with no values
-	_, err = pollerResp.PollUntilDone(context.Background(), &runtime.PollUntilDoneOptions{Frequency: 10 * time.Second})
+	pollerWrapper := azureclients.NewPollerWrapper[armresources.ResourceGroupsClientDeleteResponse](
+		pollerResp,
+		client.Mock,
+		// Stomped return is an armresources.ResourceGroupsClientDeleteResponse which is an empty struct with no values
+		armresources.ResourceGroupsClientDeleteResponse{},
+	)
+	_, err = pollerWrapper.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: 10 * time.Second})
 	if err != nil {
 		return err
 	}
 	log.Printf("Deleted resource group %s", resourceGroupName)
+	provisioning.EmitResourceEvent("ResourceGroup", resourceGroupName, provisioning.ResourceDeleted)
+	return nil
+}
+
+// validateResourceGroupOwnership returns an error unless the tags of the resource group identified by resourceGroupName
+// include CCO's "owned" tag for the provided name and, when clusterID is provided, the cluster ownership tag. The
+// checks are skipped when force is provided.
+func validateResourceGroupOwnership(tags map[string]*string, name, ownedTagValue, clusterID, resourceGroupName string, force bool) error {
+	if force {
+		return nil
+	}
+	if !hasOwnedResourceTag(tags, name, ownedTagValue) {
+		return fmt.Errorf("refusing to delete resource group %s which does not have tag key=%s, value=%s, "+
+			"the resource group may not have been created by ccoctl. Use --force to delete the resource group regardless",
+			resourceGroupName, ownedResourceTagKey(name), ownedTagValue)
+	}
+	if !hasClusterResourceTag(tags, clusterID) {
+		return fmt.Errorf("refusing to delete resource group %s which does not have tag key=%s, value=%s, "+
+			"the resource group may not belong to the cluster. Use --force to delete the resource group regardless",
+			resourceGroupName, clusterResourceTagKey(clusterID), provisioning.ClusterResourceTagValue)
+	}
 	return nil
 }
 
-func deleteStorageAccount(client *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName string) error {
+// deleteStorageAccount deletes the storage account identified by storageAccountName. When clusterID is provided the
+// storage account is only deleted if it carries the tag identifying the OpenShift cluster with ID clusterID as its owner.
+// When olderThan is provided the storage account is only deleted if it carries CCO's "owned" tag for the provided name
+// and was created more than olderThan ago. Likewise, when tagSelector is not empty the storage account is only deleted
+// if it carries CCO's "owned" tag and its tags match tagSelector. When dryRun is provided the storage account is logged
+// but not deleted.
+func deleteStorageAccount(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, clusterID, resourceGroupName, storageAccountName string, olderThan time.Duration, tagSelector provisioning.TagSelector, dryRun bool) error {
+	ctx, span := provisioning.StartSpan(ctx, "azure.DeleteStorageAccount",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("storageAccount"),
+		provisioning.ResourceNameAttribute.String(storageAccountName),
+	)
+	defer span.End()
+
+	filtered := clusterID != "" || olderThan != 0 || !tagSelector.Empty()
+	// A dry run looks the storage account up so that it is only reported for deletion when it exists
+	if filtered || dryRun {
+		storageAccount, err := findStorageAccount(ctx, client, resourceGroupName, storageAccountName)
+		if err != nil {
+			return err
+		}
+		if storageAccount == nil {
+			if filtered {
+				return fmt.Errorf("found no storage account %s within resource group %s", storageAccountName, resourceGroupName)
+			}
+			log.Printf("Storage account %s not found within resource group %s", storageAccountName, resourceGroupName)
+			return nil
+		}
+		if !hasClusterResourceTag(storageAccount.Tags, clusterID) {
+			return fmt.Errorf("refusing to delete storage account %s which does not have tag key=%s, value=%s, "+
+				"the storage account may not belong to the cluster",
+				storageAccountName, clusterResourceTagKey(clusterID), provisioning.ClusterResourceTagValue)
+		}
+		if olderThan != 0 || !tagSelector.Empty() {
+			if !hasOwnedResourceTag(storageAccount.Tags, name, ownedTagValue) {
+				log.Printf("Skipping storage account %s which does not have tag key=%s, value=%s",
+					storageAccountName, ownedResourceTagKey(name), ownedTagValue)
+				return nil
+			}
+			if !matchesTagSelector("storage account "+storageAccountName, storageAccount.Tags, tagSelector) {
+				return nil
+			}
+			var createdAt *time.Time
+			if storageAccount.Properties != nil {
+				createdAt = storageAccount.Properties.CreationTime
+			}
+			if !isOlderThan("storage account "+storageAccountName, createdAt, storageAccount.Tags, olderThan) {
+				return nil
+			}
+		}
+	}
+
+	if dryRun {
+		log.Printf("Would delete storage account %s", storageAccountName)
+		provisioning.RecordDeletionCandidate("StorageAccount")
+		return nil
+	}
+
 	_, err := client.StorageAccountClient.Delete(
-		context.Background(),
+		ctx,
 		resourceGroupName,
 		storageAccountName,
 		&armstorage.AccountsClientDeleteOptions{})
@@ -94,24 +371,50 @@ func deleteStorageAccount(client *azureclients.AzureClientWrapper, resourceGroup
 		return errors.Wrap(err, "failed to delete storage account")
 	}
 	log.Printf("Deleted storage account %s", storageAccountName)
+	provisioning.EmitResourceEvent("StorageAccount", storageAccountName, provisioning.ResourceDeleted)
 	return nil
 }
 
+// findStorageAccount returns the storage account identified by storageAccountName within the resource group identified
+// by resourceGroupName, or nil when the storage account or its resource group does not exist.
+func findStorageAccount(ctx context.Context, client *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName string) (*armstorage.Account, error) {
+	listAccounts := client.StorageAccountClient.NewListByResourceGroupPager(resourceGroupName, &armstorage.AccountsClientListByResourceGroupOptions{})
+	for listAccounts.More() {
+		pageResponse, err := listAccounts.NextPage(ctx)
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) && respErr.ErrorCode == "ResourceGroupNotFound" {
+				return nil, nil
+			}
+			return nil, errors.Wrap(err, "failed to list storage accounts")
+		}
+		for _, storageAccount := range pageResponse.AccountListResult.Value {
+			if storageAccount.Name != nil && *storageAccount.Name == storageAccountName {
+				return storageAccount, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 func deleteCmd(cmd *cobra.Command, args []string) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
+	ctx := cmd.Context()
+	if err := provisioning.ResolveNameFromKubeconfig(ctx, &DeleteOpts.Name, DeleteOpts.NameFromKubeconfig, DeleteOpts.ConfirmNameFromKubeconfig, configv1.AzurePlatformType); err != nil {
 		log.Fatal(err)
 	}
 
-	azureClientWrapper, err := azureclients.NewAzureClientWrapper(DeleteOpts.SubscriptionID, cred, &policy.ClientOptions{}, false)
+	cred, err := newAzureCredential(ctx, DeleteOpts.TenantID)
 	if err != nil {
-		log.Fatal("Failed to create Azure client")
+		log.Fatal(err)
 	}
 
 	if DeleteOpts.OIDCResourceGroupName == "" {
 		DeleteOpts.OIDCResourceGroupName = DeleteOpts.Name + oidcResourceGroupSuffix
 		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", DeleteOpts.OIDCResourceGroupName)
 	}
+	if err := validateIdentityResourceGroupName(&DeleteOpts); err != nil {
+		log.Fatal(err)
+	}
 
 	if DeleteOpts.StorageAccountName == "" {
 		DeleteOpts.StorageAccountName = DeleteOpts.Name
@@ -121,53 +424,393 @@ func deleteCmd(cmd *cobra.Command, args []string) {
 		log.Fatal(err)
 	}
 
-	// Every Azure object created by ccoctl exists within the context of the OIDC resource group so deleting the OIDC resource group
-	// will delete everything within and we can return after the resource group has been deleted
-	if DeleteOpts.DeleteOIDCResourceGroup {
-		err = deleteResourceGroup(
-			azureClientWrapper,
-			DeleteOpts.OIDCResourceGroupName)
+	if err := validateDeletePhases(DeleteOpts); err != nil {
+		log.Fatal(err)
+	}
+	if DeleteOpts.TeardownPlanPath != "" {
+		DeleteOpts.TeardownPlan, err = provisioning.LoadTeardownPlan(DeleteOpts.TeardownPlanPath, teardownPlanResourceTypes)
 		if err != nil {
 			log.Fatal(err)
 		}
-		return
 	}
 
-	// Delete user-assigned managed identities
-	err = deleteManagedIdentities(azureClientWrapper,
-		DeleteOpts.Name,
-		DeleteOpts.OIDCResourceGroupName,
-		DeleteOpts.SubscriptionID,
-		DeleteOpts.Region)
+	if err := provisioning.ValidateClusterID(DeleteOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateOwnedTagValue(DeleteOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+
+	if DeleteOpts.OlderThan < 0 {
+		log.Fatalf("invalid --older-than %s, the duration may not be negative", DeleteOpts.OlderThan)
+	}
+
+	// The "owned" tag remains required of every resource matched by --tag-selector
+	if !DeleteOpts.TagSelector.Empty() && DeleteOpts.Force {
+		log.Fatal("--force may not be specified with --tag-selector, only resources carrying CCO's owned tag may be selected")
+	}
+
+	subscriptionIDs, err := provisioning.UniqueScopes("--subscription-id", DeleteOpts.SubscriptionIDs)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Delete storage account
-	err = deleteStorageAccount(azureClientWrapper,
-		DeleteOpts.OIDCResourceGroupName,
-		DeleteOpts.StorageAccountName)
+	if DeleteOpts.DeleteKeyVaultSecret {
+		if DeleteOpts.KeyVaultName == "" {
+			log.Fatal("--delete-key-vault-secret requires --key-vault-name")
+		}
+		DeleteOpts.KeyVaultSecretName = signingKeySecretName(DeleteOpts.Name, DeleteOpts.KeyVaultSecretName)
+		if err := validateKeyVaultNames(DeleteOpts.KeyVaultName, DeleteOpts.KeyVaultSecretName); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := provisioning.ValidateConfirmThreshold(DeleteOpts.ConfirmThreshold); err != nil {
+		log.Fatal(err)
+	}
+
+	// The resources to delete are discovered with a dry run of the deletion before any is deleted, so that a
+	// mis-scoped deletion, eg. by --tag-selector, can be caught. With --dry-run the discovery is the entire deletion.
+	discoverOpts := DeleteOpts
+	discoverOpts.DryRun = true
+	summary, err := provisioning.DiscoverDeletions(func() error {
+		return deleteAll(ctx, cred, subscriptionIDs, discoverOpts)
+	})
 	if err != nil {
+		if !DeleteOpts.ContinueOnError || DeleteOpts.DryRun {
+			log.Fatal(err)
+		}
+		provisioning.Warnf("Failed to discover every resource to delete, continuing as --continue-on-error was provided: %s", err)
+	}
+	if DeleteOpts.DryRun {
+		provisioning.PrintDeletionSummary(summary)
+		return
+	}
+	if err := provisioning.ConfirmDeletion(summary, DeleteOpts.ConfirmThreshold, DeleteOpts.Yes); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := deleteAll(ctx, cred, subscriptionIDs, DeleteOpts); err != nil {
 		log.Fatal(err)
 	}
+
+	// Resources remaining once deletion completes, which are listed like the deletion is scoped, fail the deletion
+	if DeleteOpts.VerifyAfterDelete {
+		provisioning.SetPhase("verifying that no resources remain")
+		err := provisioning.VerifyDeletion(func() ([]string, error) {
+			return listAllRemainingResources(ctx, cred, subscriptionIDs, DeleteOpts)
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// deleteAll deletes the resources selected by opts within each of subscriptionIDs in turn and then, when requested,
+// the secret storing the signing private key within the key vault
+func deleteAll(ctx context.Context, cred azcore.TokenCredential, subscriptionIDs []string, opts azureOptions) error {
+	err := provisioning.DeleteAcrossScopes("subscription", subscriptionIDs, opts.ContinueOnError, func(subscriptionID string) error {
+		azureClientWrapper, err := newAzureClientWrapper(subscriptionID, cred)
+		if err != nil {
+			return errors.Wrap(err, "failed to create Azure client")
+		}
+		// Resources are only deleted once they have been attributed to the issuer URL
+		if opts.IssuerURL != "" {
+			err := verifyIssuerURL(ctx, azureClientWrapper,
+				opts.Name,
+				opts.OwnedTagValue,
+				opts.ClusterID,
+				opts.IdentityResourceGroupName,
+				opts.IssuerURL)
+			if err != nil {
+				return err
+			}
+		}
+		// Likewise with --fail-on-insufficient-permissions when the identity lacks permissions required for deletion.
+		// The permissions are checked once the deletion has been confirmed rather than while discovering resources.
+		if !opts.DryRun {
+			if err := checkDeletionPermissions(ctx, azureClientWrapper, opts, opts.FailOnInsufficientPermissions); err != nil {
+				return err
+			}
+		}
+		return deleteWithinSubscription(ctx, azureClientWrapper, opts, subscriptionID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The signing private key is only deleted once the resources which trust it have been deleted, so that a failed
+	// deletion may be retried without losing the key
+	if opts.DeleteKeyVaultSecret {
+		vaultURL := keyVaultURL(opts.KeyVaultName)
+		provisioning.SetPhase("deleting the signing key from the key vault")
+		return deleteSigningKeySecret(ctx, newKeyVaultClient(vaultURL, cred, newClientOptions()),
+			vaultURL,
+			opts.Name,
+			opts.OwnedTagValue,
+			opts.KeyVaultSecretName,
+			opts.TagSelector,
+			opts.DryRun)
+	}
+	return nil
+}
+
+// verifyIssuerURL ensures that the federated identity credentials of the user-assigned managed identities owned by name
+// within the resource group identified by resourceGroupName, which record the issuer URL of the cluster whose service
+// accounts they federate, all trust issuerURL. An error is returned when none are found, as the resources to be deleted
+// can't be attributed to issuerURL.
+func verifyIssuerURL(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, clusterID, resourceGroupName, issuerURL string) error {
+	managedIdentities, err := listOwnedManagedIdentities(ctx, client, name, ownedTagValue, clusterID, resourceGroupName)
+	if err != nil {
+		return errors.Wrap(err, "failed to list user-assigned managed identities")
+	}
+
+	verified := false
+	for _, identity := range managedIdentities {
+		listFederatedCredentials := client.FederatedIdentityCredentialsClient.NewListPager(
+			resourceGroupName,
+			*identity.Name,
+			&armmsi.FederatedIdentityCredentialsClientListOptions{},
+		)
+		for listFederatedCredentials.More() {
+			pageResponse, err := listFederatedCredentials.NextPage(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list federated identity credentials of user-assigned managed identity %s", *identity.Name)
+			}
+			for _, credential := range pageResponse.FederatedIdentityCredentialsListResult.Value {
+				if credential.Properties == nil {
+					continue
+				}
+				err := provisioning.VerifyDiscoveredIssuerURL(issuerURL,
+					stringValue(credential.Properties.Issuer),
+					fmt.Sprintf("federated identity credential %s of user-assigned managed identity %s", stringValue(credential.Name), *identity.Name))
+				if err != nil {
+					return err
+				}
+				verified = true
+			}
+		}
+	}
+	if !verified {
+		return provisioning.VerifyDiscoveredIssuerURL(issuerURL, "", "")
+	}
+	return nil
+}
+
+// deleteWithinSubscription deletes the resources selected by opts within the subscription identified by
+// subscriptionID, stopping at the first deletion phase which fails. The resources of the teardown plan of opts are
+// deleted instead of the deletion phases when one is provided.
+func deleteWithinSubscription(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions, subscriptionID string) error {
+	if opts.TeardownPlan != nil {
+		return deleteTeardownPlan(ctx, client, opts)
+	}
+
+	// Every Azure object created by ccoctl exists within the context of the OIDC resource group, or the identity resource
+	// group when separate, so deleting the resource groups will delete everything within and we can return after the
+	// resource groups have been deleted
+	if opts.DeleteOIDCResourceGroup {
+		if opts.IdentityResourceGroupName != opts.OIDCResourceGroupName {
+			if err := deleteIdentityResourceGroup(ctx, client, opts, subscriptionID); err != nil {
+				return err
+			}
+		}
+		provisioning.SetPhase("deleting the OIDC resource group " + opts.OIDCResourceGroupName)
+		return deleteResourceGroup(
+			ctx,
+			client,
+			opts.Name,
+			opts.OwnedTagValue,
+			opts.ClusterID,
+			opts.OIDCResourceGroupName,
+			opts.Force,
+			opts.OlderThan,
+			opts.TagSelector,
+			opts.DryRun)
+	}
+
+	// The user-assigned managed identities and the storage account are independent of each other so they are
+	// deleted concurrently
+	phases := []string{}
+	deletions := []func() error{}
+
+	// Delete user-assigned managed identities
+	if opts.SkipManagedIdentities {
+		log.Printf("Skipping deletion of user-assigned managed identities within resource group %s", opts.IdentityResourceGroupName)
+	} else {
+		phases = append(phases, "deleting user-assigned managed identities")
+		deletions = append(deletions, func() error {
+			return deleteManagedIdentities(ctx, client,
+				opts.Name,
+				opts.OwnedTagValue,
+				opts.ClusterID,
+				opts.IdentityResourceGroupName,
+				subscriptionID,
+				opts.Region,
+				opts.ComponentFilter,
+				opts.OlderThan,
+				opts.TagSelector,
+				opts.DryRun)
+		})
+	}
+
+	// Delete storage account
+	if opts.ComponentFilter != "" {
+		log.Printf("Skipping deletion of storage account %s which is shared by all components", opts.StorageAccountName)
+	} else if opts.SkipStorageAccount {
+		log.Printf("Skipping deletion of storage account %s", opts.StorageAccountName)
+	} else {
+		phases = append(phases, "deleting the storage account "+opts.StorageAccountName)
+		deletions = append(deletions, func() error {
+			return deleteStorageAccount(ctx, client,
+				opts.Name,
+				opts.OwnedTagValue,
+				opts.ClusterID,
+				opts.OIDCResourceGroupName,
+				opts.StorageAccountName,
+				opts.OlderThan,
+				opts.TagSelector,
+				opts.DryRun)
+		})
+	}
+
+	if len(deletions) == 0 {
+		return nil
+	}
+	provisioning.SetPhase(strings.Join(phases, " and "))
+	return deleteConcurrently(deletions...)
+}
+
+// deleteConcurrently runs the independent deletions concurrently. Every deletion runs to completion regardless of
+// the others failing, the errors of the failed deletions are aggregated in the order that the deletions are provided.
+func deleteConcurrently(deletions ...func() error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(deletions))
+	for i, deletion := range deletions {
+		wg.Add(1)
+		go func(i int, deletion func() error) {
+			defer wg.Done()
+			errs[i] = deletion()
+		}(i, deletion)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteIdentityResourceGroup deletes the identity resource group selected by opts, separate from the OIDC resource
+// group, along with everything within it when it carries CCO's "owned" tag for the name of opts, and the cluster
+// ownership tag when a cluster ID is provided, as it was created by ccoctl with --create-identity-resource-group.
+// Otherwise the identity resource group pre-existed and only the user-assigned managed identities within it are deleted.
+func deleteIdentityResourceGroup(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions, subscriptionID string) error {
+	getResourceGroupResp, err := client.ResourceGroupsClient.Get(
+		ctx,
+		opts.IdentityResourceGroupName,
+		&armresources.ResourceGroupsClientGetOptions{})
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.ErrorCode == "ResourceGroupNotFound" {
+			log.Printf("Identity resource group %s not found, skipping deletion of the user-assigned managed identities within", opts.IdentityResourceGroupName)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get identity resource group %s", opts.IdentityResourceGroupName)
+	}
+
+	if validateResourceGroupOwnership(getResourceGroupResp.Tags, opts.Name, opts.OwnedTagValue, opts.ClusterID, opts.IdentityResourceGroupName, false) == nil {
+		provisioning.SetPhase("deleting the identity resource group " + opts.IdentityResourceGroupName)
+		return deleteResourceGroup(ctx, client,
+			opts.Name,
+			opts.OwnedTagValue,
+			opts.ClusterID,
+			opts.IdentityResourceGroupName,
+			false,
+			opts.OlderThan,
+			opts.TagSelector,
+			opts.DryRun)
+	}
+	log.Printf("Skipping deletion of identity resource group %s which was not created by ccoctl", opts.IdentityResourceGroupName)
+	provisioning.SetPhase("deleting user-assigned managed identities")
+	return deleteManagedIdentities(ctx, client,
+		opts.Name,
+		opts.OwnedTagValue,
+		opts.ClusterID,
+		opts.IdentityResourceGroupName,
+		subscriptionID,
+		opts.Region,
+		opts.ComponentFilter,
+		opts.OlderThan,
+		opts.TagSelector,
+		opts.DryRun)
+}
+
+// validateDeletePhases validates that at least one deletion phase has been selected by the provided options and
+// that phases are not skipped when the OIDC resource group, which contains all resources, is to be deleted, nor the
+// signing key deleted when a single component is. The phases may not be selected along with a teardown plan, which
+// selects the resources to delete itself.
+func validateDeletePhases(opts azureOptions) error {
+	if opts.TeardownPlanPath != "" {
+		if opts.DeleteOIDCResourceGroup || opts.SkipStorageAccount || opts.SkipManagedIdentities || opts.ComponentFilter != "" {
+			return errors.New("--delete-oidc-resource-group, --skip-storage-account, --skip-managed-identities and --component-filter " +
+				"may not be specified with --teardown-plan because the teardown plan lists the resources to delete")
+		}
+		if opts.Force || opts.OlderThan != 0 || !opts.TagSelector.Empty() {
+			return errors.New("--force, --older-than and --tag-selector may not be specified with --teardown-plan, " +
+				"every resource listed within the teardown plan must carry CCO's owned tag and is deleted")
+		}
+		return nil
+	}
+	if opts.ComponentFilter != "" {
+		if opts.DeleteOIDCResourceGroup {
+			return errors.New("--component-filter may not be specified with --delete-oidc-resource-group " +
+				"because deleting the OIDC resource group deletes the resources of all components")
+		}
+		if opts.SkipManagedIdentities {
+			return errors.New("nothing to delete, --skip-managed-identities was specified with --component-filter")
+		}
+		if opts.DeleteKeyVaultSecret {
+			return errors.New("--delete-key-vault-secret may not be specified with --component-filter " +
+				"because the signing key stored within the key vault is trusted by the resources of all components")
+		}
+		return nil
+	}
+	if opts.DeleteOIDCResourceGroup {
+		if opts.SkipStorageAccount || opts.SkipManagedIdentities {
+			return errors.New("--skip-storage-account and --skip-managed-identities may not be specified with --delete-oidc-resource-group " +
+				"because deleting the OIDC resource group deletes all resources within it")
+		}
+		return nil
+	}
+	if opts.SkipStorageAccount && opts.SkipManagedIdentities {
+		return errors.New("nothing to delete, --skip-storage-account and --skip-managed-identities were both specified")
+	}
+	return nil
 }
 
 // NewDeleteCmd provides the "delete" subcommand
 func NewDeleteCmd() *cobra.Command {
 	deleteCmd := &cobra.Command{
-		Use:   "delete --name NAME --region REGION --subscription-id SUBSCRIPTION_ID",
+		Use:   "delete --name NAME --region REGION --subscription-id SUBSCRIPTION_ID[,SUBSCRIPTION_ID...]",
 		Short: "Delete OIDC issuer and managed identities",
 		Long: "This command will delete the storage account and user-assigned managed identities within the OIDC resource group by default. " +
-			"The OIDC resource group will be deleted when the --delete-oidc-resource-group paramter has been provided.",
+			"Deletion of the storage account or user-assigned managed identities may be skipped with --skip-storage-account or --skip-managed-identities. " +
+			"The OIDC resource group will be deleted when the --delete-oidc-resource-group paramter has been provided. " +
+			"The user-assigned managed identity of a single component may be deleted with --component-filter. " +
+			"Resources created recently may be preserved with --older-than, a subset of the resources owned by --name may be selected by their tags with --tag-selector " +
+			"and the resources which would be deleted may be previewed with --dry-run. " +
+			"The resources to delete, and the order in which they are deleted, may instead be listed within a teardown plan provided with --teardown-plan.",
 		Run: deleteCmd,
 	}
 
 	// Required
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Name, "name", "", "User-defined name for all previously created Azure resources")
-	deleteCmd.MarkPersistentFlagRequired("name")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Name, "name", "", "User-defined name for all previously created Azure resources. Required unless --name-from-kubeconfig is specified")
 	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Region, "region", "", "Azure region in which to delete user-assigned managed identities")
 	deleteCmd.MarkPersistentFlagRequired("region")
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which to create and scope the access of managed identities")
+	deleteCmd.PersistentFlags().StringSliceVar(
+		&DeleteOpts.SubscriptionIDs,
+		"subscription-id",
+		nil,
+		"Azure Subscription ID within which to delete resources. May be specified multiple times, or as a comma-separated list, "+
+			"to delete the resources within each subscription in turn",
+	)
 	deleteCmd.MarkPersistentFlagRequired("subscription-id")
 
 	// Optional
@@ -187,9 +830,108 @@ func NewDeleteCmd() *cobra.Command {
 			"or within the OIDC resource group name derived from the --name parameter when --oidc-resource-group-name paramter was not provided. "+
 			"Azure storage account names must be between 3 and 24 characters in length and may contain numbers and lowercase letters only.",
 	)
-	// TODO: Plumb dry-run through delete
+	deleteCmd.PersistentFlags().BoolVar(
+		&DeleteOpts.Force,
+		"force",
+		false,
+		"Delete the OIDC resource group when --delete-oidc-resource-group has been specified even if the resource group "+
+			fmt.Sprintf("does not carry the '%s_NAME = <--owned-tag-value>' tag applied to resource groups created by ccoctl.", ownedAzureResourceTagKeyPrefix),
+	)
+	deleteCmd.PersistentFlags().StringVar(
+		&DeleteOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag identifying the Azure resources created by ccoctl which may be deleted. ", ownedAzureResourceTagKeyPrefix)+
+			"Must match the --owned-tag-value with which the resources were created",
+	)
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.SkipStorageAccount, "skip-storage-account", false, "Skip deleting the storage account which hosts OIDC documents")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.SkipManagedIdentities, "skip-managed-identities", false, "Skip deleting user-assigned managed identities")
+	deleteCmd.PersistentFlags().StringVar(
+		&DeleteOpts.ComponentFilter,
+		"component-filter",
+		"",
+		"Only delete the user-assigned managed identity of a single component, identified by the <secret namespace>/<secret name> "+
+			"of the component's CredentialsRequest or by a prefix such as the secret namespace. "+
+			"Deletion is refused unless exactly one user-assigned managed identity owned by --name matches. The storage account is not deleted.",
+	)
+	deleteCmd.PersistentFlags().StringVar(
+		&DeleteOpts.ClusterID,
+		"cluster-id",
+		"",
+		"Only delete resources which carry the 'kubernetes.io_cluster.<cluster ID> = owned' tag applied by ccoctl when the resources were created with --cluster-id. "+
+			"The check of the OIDC resource group's tag is skipped with --force.",
+	)
+	deleteCmd.PersistentFlags().StringVar(
+		&DeleteOpts.IssuerURL,
+		"issuer-url",
+		"",
+		"Only delete resources when the federated identity credentials of the user-assigned managed identities owned by --name trust this OIDC issuer URL, "+
+			"eg. https://<storage account>.blob.core.windows.net/<blob container>. Guards against deleting the resources of another cluster created with the same name",
+	)
+	deleteCmd.PersistentFlags().DurationVar(
+		&DeleteOpts.OlderThan,
+		"older-than",
+		0,
+		"Only delete resources owned by --name which were created more than the provided duration ago, eg. 72h. "+
+			"The creation time reported by Azure is used for user-assigned managed identities and the storage account, falling back to the "+
+			fmt.Sprintf("'%s' tag applied by ccoctl when the resources were created. ", creationTimestampAzureResourceTagKey)+
+			"Resources whose creation time is unknown are not deleted.",
+	)
+	deleteCmd.PersistentFlags().Var(
+		&DeleteOpts.TagSelector,
+		"tag-selector",
+		"Only delete resources owned by --name whose tags match the selector, a comma-separated list of requirements of the form "+
+			"key=value, key!=value, key in (value,...) or key notin (value,...), eg. 'env in (dev,test),team=platform'. "+
+			"All requirements must be met. May be specified multiple times to add requirements. "+
+			"Resources are still required to carry CCO's owned tag, so --tag-selector may not be specified with --force. Combine with --dry-run to preview the matched resources",
+	)
 	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.DryRun, "dry-run", false, "Skip deleting objects and display actions that would have been taken")
+	deleteCmd.PersistentFlags().StringVar(
+		&DeleteOpts.TeardownPlanPath,
+		"teardown-plan",
+		"",
+		"Path of a YAML or JSON teardown plan listing the resources to delete, by type and ID, in the order they must be deleted, eg. as external dependencies require. "+
+			fmt.Sprintf("The supported types are %s. ", strings.Join(teardownPlanResourceTypes, ", "))+
+			"Every resource listed must carry CCO's owned tag for --name, owned resources which are not listed are reported and left intact. "+
+			"Combine with --dry-run to preview the ordered deletions",
+	)
+	deleteCmd.PersistentFlags().IntVar(
+		&DeleteOpts.ConfirmThreshold,
+		"confirm-threshold",
+		provisioning.DefaultConfirmThreshold,
+		"Number of resources discovered for deletion beyond which deletion must be confirmed on the terminal, or with --yes. "+
+			"A table counting the resources of each type to delete is always logged before any is deleted",
+	)
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.Yes, "yes", false, "Delete the resources discovered for deletion without asking for confirmation, however many there are")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.VerifyAfterDelete, provisioning.VerifyAfterDeleteFlag, false, provisioning.VerifyAfterDeleteFlagUsage)
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.NameFromKubeconfig, "name-from-kubeconfig", false, "Delete the resources named after the infrastructure name of the cluster of the current context of the kubeconfig, read from its infrastructures.config.openshift.io object, rather than --name. Requires --confirm-name-from-kubeconfig")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.ConfirmNameFromKubeconfig, "confirm-name-from-kubeconfig", false, "Confirm the deletion of the resources named after the infrastructure name discovered with --name-from-kubeconfig")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.FailOnInsufficientPermissions, "fail-on-insufficient-permissions", false, "Exit without deleting any resource when the permissions of the current identity on the resource groups containing the resources, or the failure to list them, show that it lacks permissions required to delete them. Otherwise the missing permissions are reported as warnings before deletion proceeds")
+	deleteCmd.PersistentFlags().BoolVar(
+		&DeleteOpts.ContinueOnError,
+		"continue-on-error",
+		false,
+		"Continue deleting resources within the remaining subscriptions when deletion within a subscription fails. "+
+			"Deletion fails overall if it failed within any subscription",
+	)
 	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.OIDCResourceGroupName, "oidc-resource-group-name", "", "The Azure resource group in which to delete user-assigned managed identities. This resource group will not be deleted unless --delete-resource-group has been specified.")
+	deleteCmd.PersistentFlags().StringVar(
+		&DeleteOpts.IdentityResourceGroupName,
+		"identity-resource-group-name",
+		"",
+		"The Azure resource group in which to delete user-assigned managed identities when they were created with --identity-resource-group-name separately from the OIDC resource group. "+
+			"With --delete-oidc-resource-group this resource group is also deleted if it was created by ccoctl with --create-identity-resource-group. Defaults to the OIDC resource group",
+	)
+	deleteCmd.PersistentFlags().BoolVar(
+		&DeleteOpts.DeleteKeyVaultSecret,
+		"delete-key-vault-secret",
+		false,
+		"Delete the secret in which ccoctl azure create-all stored the signing private key within the key vault identified by --key-vault-name, "+
+			"once all other resources have been deleted. The secret is only deleted if it carries CCO's owned tag for --name",
+	)
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.KeyVaultName, "key-vault-name", "", "Name of the Azure key vault within which the signing private key was stored with --key-vault-name")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.KeyVaultSecretName, "key-vault-secret-name", "", "Name of the secret in which the signing private key was stored within the key vault. Defaults to <name>"+signingKeySecretSuffix)
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
 
 	return deleteCmd
 }
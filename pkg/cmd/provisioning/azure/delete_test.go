@@ -0,0 +1,879 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestDeleteResourceGroup(t *testing.T) {
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		clusterID              string
+		force                  bool
+		expectError            bool
+	}{
+		{
+			name: "Resource group with owned tag deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+					fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+				})
+				mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+				return wrapper
+			},
+		},
+		{
+			name: "Pre-existing resource group without owned tag not deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+					"testtagname0": to.Ptr("testtagvalue0"),
+				})
+				return wrapper
+			},
+			expectError: true,
+		},
+		{
+			name: "Pre-existing resource group owned by a different name not deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+					fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, "othername"): to.Ptr(ownedAzureResourceTagValue),
+				})
+				return wrapper
+			},
+			expectError: true,
+		},
+		{
+			name: "Resource group with owned and cluster tags deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+					fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+					clusterResourceTagKey(testClusterID):                                to.Ptr(provisioning.ClusterResourceTagValue),
+				})
+				mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+				return wrapper
+			},
+			clusterID: testClusterID,
+		},
+		{
+			name: "Resource group owned by a different cluster not deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+					fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+					clusterResourceTagKey("othercluster"):                               to.Ptr(provisioning.ClusterResourceTagValue),
+				})
+				return wrapper
+			},
+			clusterID:   testClusterID,
+			expectError: true,
+		},
+		{
+			name: "Pre-existing resource group without owned tag deleted with force",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+				return wrapper
+			},
+			force: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+			err := deleteResourceGroup(context.Background(), mockAzureClientWrapper, testInfraName, ownedAzureResourceTagValue, test.clusterID, testOIDCResourceGroupName, test.force, 0, provisioning.TagSelector{}, false)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestDeleteIdentityResourceGroup(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		expectError            bool
+	}{
+		{
+			name: "Identity resource group created by ccoctl deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				// The ownership of the resource group is checked again as it is deleted
+				mockGetResourceGroupSuccess(wrapper, testIdentityResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockGetResourceGroupSuccess(wrapper, testIdentityResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockResourceGroupBeginDelete(wrapper, testIdentityResourceGroupName)
+				return wrapper
+			},
+		},
+		{
+			name: "Only managed identities deleted within a pre-existing identity resource group",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testIdentityResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{})
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testIdentityResourceGroupName, map[string]map[string]*string{
+					testInfraName + "-namespace-secret": ownedTags,
+					"unrelated-identity":                {},
+				})
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testIdentityResourceGroupName, testInfraName+"-namespace-secret")
+				return wrapper
+			},
+		},
+		{
+			name: "Missing identity resource group skipped",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testIdentityResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+			err := deleteIdentityResourceGroup(context.Background(), mockAzureClientWrapper, azureOptions{
+				Name:                      testInfraName,
+				OwnedTagValue:             ownedAzureResourceTagValue,
+				OIDCResourceGroupName:     testOIDCResourceGroupName,
+				IdentityResourceGroupName: testIdentityResourceGroupName,
+				Region:                    testRegionName,
+			}, testSubscriptionID)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidateDeletePhases(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        azureOptions
+		expectError bool
+	}{
+		{
+			name: "All phases selected",
+			opts: azureOptions{},
+		},
+		{
+			name: "Storage account skipped",
+			opts: azureOptions{SkipStorageAccount: true},
+		},
+		{
+			name: "Managed identities skipped",
+			opts: azureOptions{SkipManagedIdentities: true},
+		},
+		{
+			name:        "All phases skipped",
+			opts:        azureOptions{SkipStorageAccount: true, SkipManagedIdentities: true},
+			expectError: true,
+		},
+		{
+			name: "OIDC resource group deleted",
+			opts: azureOptions{DeleteOIDCResourceGroup: true},
+		},
+		{
+			name:        "OIDC resource group deleted with phase skipped",
+			opts:        azureOptions{DeleteOIDCResourceGroup: true, SkipStorageAccount: true},
+			expectError: true,
+		},
+		{
+			name: "Single component deleted",
+			opts: azureOptions{ComponentFilter: "openshift-ingress-operator/cloud-credentials"},
+		},
+		{
+			name:        "Single component deleted with OIDC resource group",
+			opts:        azureOptions{ComponentFilter: "openshift-ingress-operator/cloud-credentials", DeleteOIDCResourceGroup: true},
+			expectError: true,
+		},
+		{
+			name:        "Single component deleted with managed identities skipped",
+			opts:        azureOptions{ComponentFilter: "openshift-ingress-operator/cloud-credentials", SkipManagedIdentities: true},
+			expectError: true,
+		},
+		{
+			name:        "Single component deleted with the signing key",
+			opts:        azureOptions{ComponentFilter: "openshift-ingress-operator/cloud-credentials", DeleteKeyVaultSecret: true, KeyVaultName: "testvault"},
+			expectError: true,
+		},
+		{
+			name: "Teardown plan followed",
+			opts: azureOptions{TeardownPlanPath: "teardown-plan.yaml"},
+		},
+		{
+			name:        "Teardown plan followed with phase skipped",
+			opts:        azureOptions{TeardownPlanPath: "teardown-plan.yaml", SkipStorageAccount: true},
+			expectError: true,
+		},
+		{
+			name:        "Teardown plan followed with OIDC resource group",
+			opts:        azureOptions{TeardownPlanPath: "teardown-plan.yaml", DeleteOIDCResourceGroup: true},
+			expectError: true,
+		},
+		{
+			name:        "Teardown plan followed with older than",
+			opts:        azureOptions{TeardownPlanPath: "teardown-plan.yaml", OlderThan: time.Hour},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateDeletePhases(test.opts)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestDeleteManagedIdentitiesComponentFilter(t *testing.T) {
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+	}
+	ownedIdentities := map[string]map[string]*string{
+		"testinfraname-openshift-ingress-operator-cloud-credentials":         ownedTags,
+		"testinfraname-openshift-cluster-csi-drivers-azure-disk-credentials": ownedTags,
+		"testinfraname-openshift-cluster-csi-drivers-azure-file-credentials": ownedTags,
+		"testinfraname-openshift-image-registry-installer-cloud-credentials": ownedTags,
+	}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		componentFilter        string
+		expectError            bool
+	}{
+		{
+			name: "Component identified by secret namespace and name",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, ownedIdentities)
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-cluster-csi-drivers-azure-disk-credentials")
+				return wrapper
+			},
+			componentFilter: "openshift-cluster-csi-drivers/azure-disk-credentials",
+		},
+		{
+			name: "Component identified by secret namespace",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, ownedIdentities)
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials")
+				return wrapper
+			},
+			componentFilter: "openshift-ingress-operator",
+		},
+		{
+			name: "Component matching multiple managed identities not deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, ownedIdentities)
+				return wrapper
+			},
+			componentFilter: "openshift-cluster-csi-drivers",
+			expectError:     true,
+		},
+		{
+			name: "Component filter not matching at a name boundary",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, ownedIdentities)
+				return wrapper
+			},
+			componentFilter: "openshift-ingress-op",
+			expectError:     true,
+		},
+		{
+			name: "Component of a managed identity which is not owned",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-openshift-ingress-operator-cloud-credentials": {},
+				})
+				return wrapper
+			},
+			componentFilter: "openshift-ingress-operator/cloud-credentials",
+			expectError:     true,
+		},
+		{
+			name: "Invalid component filter",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, ownedIdentities)
+				return wrapper
+			},
+			componentFilter: "openshift-ingress-operator/",
+			expectError:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+			err := deleteManagedIdentities(context.Background(), mockAzureClientWrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testSubscriptionID, testRegionName, test.componentFilter, 0, provisioning.TagSelector{}, false)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestDeleteByClusterID(t *testing.T) {
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+	}
+	clusterOwnedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+		clusterResourceTagKey(testClusterID):                                to.Ptr(provisioning.ClusterResourceTagValue),
+	}
+
+	t.Run("Only managed identities of the cluster deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+			"testinfraname-openshift-ingress-operator-cloud-credentials":         clusterOwnedTags,
+			"testinfraname-openshift-image-registry-installer-cloud-credentials": ownedTags,
+		})
+		mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials")
+		err := deleteManagedIdentities(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, testClusterID, testOIDCResourceGroupName, testSubscriptionID, testRegionName, "", 0, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Storage account of the cluster deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, clusterOwnedTags)
+		mockStorageAccountDelete(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, testClusterID, testOIDCResourceGroupName, testStorageAccountName, 0, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Storage account without cluster tag not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, testClusterID, testOIDCResourceGroupName, testStorageAccountName, 0, provisioning.TagSelector{}, false)
+		require.Error(t, err, "expected error")
+	})
+}
+
+func TestVerifyIssuerURL(t *testing.T) {
+	testIdentityName := "testinfraname-openshift-ingress-operator-cloud-credentials"
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+	}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		issuerURL              string
+		expectError            bool
+	}{
+		{
+			name: "Federated identity credentials trust the issuer URL",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testIdentityName: ownedTags,
+				})
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, testIdentityName, map[string]string{
+					"testServiceAccount1": "system:serviceaccount:openshift-ingress-operator:testServiceAccount1",
+				})
+				return wrapper
+			},
+			issuerURL: testIssuerURL + "/",
+		},
+		{
+			name: "Federated identity credentials trust another issuer URL",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testIdentityName: ownedTags,
+				})
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, testIdentityName, map[string]string{
+					"testServiceAccount1": "system:serviceaccount:openshift-ingress-operator:testServiceAccount1",
+				})
+				return wrapper
+			},
+			issuerURL:   "https://otheraccount.blob.core.windows.net/othercontainer",
+			expectError: true,
+		},
+		{
+			name: "No federated identity credentials found",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{})
+				return wrapper
+			},
+			issuerURL:   testIssuerURL,
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			wrapper := test.mockAzureClientWrapper(mockCtrl)
+			err := verifyIssuerURL(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, test.issuerURL)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestDeleteWithOwnedTagValue(t *testing.T) {
+	const customOwnedTagValue = "ccoctl-managed"
+	ownedTagKey := fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName)
+	customOwnedTags := map[string]*string{
+		ownedTagKey: to.Ptr(customOwnedTagValue),
+	}
+	defaultOwnedTags := map[string]*string{
+		ownedTagKey: to.Ptr(ownedAzureResourceTagValue),
+	}
+
+	t.Run("Only managed identities with the owned tag value deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+			"testinfraname-openshift-ingress-operator-cloud-credentials":         customOwnedTags,
+			"testinfraname-openshift-image-registry-installer-cloud-credentials": defaultOwnedTags,
+		})
+		mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials")
+		err := deleteManagedIdentities(context.Background(), wrapper, testInfraName, customOwnedTagValue, "", testOIDCResourceGroupName, testSubscriptionID, testRegionName, "", 0, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group with the owned tag value deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, customOwnedTags)
+		mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, customOwnedTagValue, "", testOIDCResourceGroupName, false, 0, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group with the default owned tag value not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, defaultOwnedTags)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, customOwnedTagValue, "", testOIDCResourceGroupName, false, 0, provisioning.TagSelector{}, false)
+		require.Error(t, err, "expected error")
+	})
+}
+
+func TestDeleteOlderThan(t *testing.T) {
+	olderThan := 72 * time.Hour
+	ownedTagKey := fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName)
+	oldTags := map[string]*string{
+		ownedTagKey:                          to.Ptr(ownedAzureResourceTagValue),
+		creationTimestampAzureResourceTagKey: to.Ptr(time.Now().Add(-96 * time.Hour).UTC().Format(time.RFC3339)),
+	}
+	recentTags := map[string]*string{
+		ownedTagKey:                          to.Ptr(ownedAzureResourceTagValue),
+		creationTimestampAzureResourceTagKey: to.Ptr(time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)),
+	}
+	unknownAgeTags := map[string]*string{
+		ownedTagKey: to.Ptr(ownedAzureResourceTagValue),
+	}
+
+	t.Run("Only managed identities older than the threshold deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+			"testinfraname-openshift-ingress-operator-cloud-credentials":         oldTags,
+			"testinfraname-openshift-image-registry-installer-cloud-credentials": recentTags,
+			"testinfraname-openshift-cluster-csi-drivers-azure-disk-credentials": unknownAgeTags,
+		})
+		mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials")
+		err := deleteManagedIdentities(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testSubscriptionID, testRegionName, "", olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Managed identities not deleted with dry run", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+			"testinfraname-openshift-ingress-operator-cloud-credentials": oldTags,
+		})
+		err := deleteManagedIdentities(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testSubscriptionID, testRegionName, "", olderThan, provisioning.TagSelector{}, true)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group older than the threshold deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+		mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, false, olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Recent resource group not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, recentTags)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, false, olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group of unknown age not deleted with force", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{})
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, true, olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group not deleted with dry run", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, false, olderThan, provisioning.TagSelector{}, true)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Storage account older than the threshold deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+		mockStorageAccountDelete(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Recent storage account not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, recentTags)
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Storage account without owned tag not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+			creationTimestampAzureResourceTagKey: oldTags[creationTimestampAzureResourceTagKey],
+		})
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, olderThan, provisioning.TagSelector{}, false)
+		require.NoError(t, err, "unexpected error")
+	})
+}
+
+func TestDeleteTagSelector(t *testing.T) {
+	ownedTagKey := fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName)
+	devTags := map[string]*string{
+		ownedTagKey: to.Ptr(ownedAzureResourceTagValue),
+		"env":       to.Ptr("dev"),
+	}
+	prodTags := map[string]*string{
+		ownedTagKey: to.Ptr(ownedAzureResourceTagValue),
+		"env":       to.Ptr("prod"),
+	}
+	untaggedTags := map[string]*string{
+		ownedTagKey: to.Ptr(ownedAzureResourceTagValue),
+	}
+	selector, err := provisioning.ParseTagSelector("env in (dev,test)")
+	require.NoError(t, err)
+
+	t.Run("Only managed identities matching the selector deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+			"testinfraname-openshift-ingress-operator-cloud-credentials":         devTags,
+			"testinfraname-openshift-image-registry-installer-cloud-credentials": prodTags,
+			"testinfraname-openshift-cluster-csi-drivers-azure-disk-credentials": untaggedTags,
+			"otherinfraname-openshift-ingress-operator-cloud-credentials": {
+				"env": to.Ptr("dev"),
+			},
+		})
+		mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials")
+		err := deleteManagedIdentities(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testSubscriptionID, testRegionName, "", 0, selector, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Managed identities matching the selector not deleted with dry run", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+			"testinfraname-openshift-ingress-operator-cloud-credentials": devTags,
+		})
+		err := deleteManagedIdentities(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testSubscriptionID, testRegionName, "", 0, selector, true)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group matching the selector deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, devTags)
+		mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, false, 0, selector, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group not matching the selector not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, prodTags)
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, false, 0, selector, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Resource group matching the selector without owned tag refused", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+			"env": to.Ptr("dev"),
+		})
+		err := deleteResourceGroup(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, false, 0, selector, false)
+		require.Error(t, err, "expected error")
+	})
+
+	t.Run("Storage account matching the selector deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, devTags)
+		mockStorageAccountDelete(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, 0, selector, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Storage account matching the selector without owned tag not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, map[string]*string{
+			"env": to.Ptr("dev"),
+		})
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, 0, selector, false)
+		require.NoError(t, err, "unexpected error")
+	})
+
+	t.Run("Storage account not matching the selector not deleted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		wrapper := mockAzureClientWrapper(mockCtrl)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, prodTags)
+		err := deleteStorageAccount(context.Background(), wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, 0, selector, false)
+		require.NoError(t, err, "unexpected error")
+	})
+}
+
+func TestResourceCreationTime(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	taggedAt := time.Date(2024, 6, 7, 8, 9, 10, 0, time.UTC)
+	tests := []struct {
+		name          string
+		createdAt     *time.Time
+		tags          map[string]*string
+		expectFound   bool
+		expectCreated time.Time
+	}{
+		{
+			name:          "Creation time reported by Azure",
+			createdAt:     &createdAt,
+			tags:          map[string]*string{creationTimestampAzureResourceTagKey: to.Ptr(taggedAt.Format(time.RFC3339))},
+			expectFound:   true,
+			expectCreated: createdAt,
+		},
+		{
+			name:          "Creation timestamp tag",
+			tags:          map[string]*string{creationTimestampAzureResourceTagKey: to.Ptr(taggedAt.Format(time.RFC3339))},
+			expectFound:   true,
+			expectCreated: taggedAt,
+		},
+		{
+			name: "Invalid creation timestamp tag",
+			tags: map[string]*string{creationTimestampAzureResourceTagKey: to.Ptr("yesterday")},
+		},
+		{
+			name: "Unknown creation time",
+			tags: map[string]*string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			created, found := resourceCreationTime(test.createdAt, test.tags)
+			require.Equal(t, test.expectFound, found, "unexpected found")
+			require.True(t, test.expectCreated.Equal(created), "unexpected creation time %s", created)
+		})
+	}
+}
+
+func TestDeleteConcurrently(t *testing.T) {
+	var completed int32
+	deletion := func(err error) func() error {
+		return func() error {
+			atomic.AddInt32(&completed, 1)
+			return err
+		}
+	}
+
+	assert.NoError(t, deleteConcurrently(deletion(nil), deletion(nil)), "unexpected error")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&completed), "expected every deletion to run")
+
+	// Every deletion runs regardless of the others failing, and the errors of all of them are reported
+	atomic.StoreInt32(&completed, 0)
+	err := deleteConcurrently(deletion(fmt.Errorf("identities failed")), deletion(nil), deletion(fmt.Errorf("storage account failed")))
+	require.Error(t, err, "expected error")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&completed), "expected every deletion to run")
+	assert.Equal(t, "[identities failed, storage account failed]", err.Error(), "unexpected aggregated error")
+}
+
+func TestDeleteWithinSubscriptionConcurrently(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	mockCtrl := gomock.NewController(t)
+	wrapper := mockAzureClientWrapper(mockCtrl)
+	// The managed identities are deleted although the deletion of the storage account fails
+	mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+		"testinfraname-openshift-ingress-operator-cloud-credentials": ownedTags,
+	})
+	mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials")
+	wrapper.StorageAccountClient.(*mockazure.MockAccountsClient).EXPECT().Delete(gomock.Any(), testOIDCResourceGroupName, testStorageAccountName, gomock.Any()).Return(
+		armstorage.AccountsClientDeleteResponse{}, fmt.Errorf("storage account deletion failed"))
+
+	err := deleteWithinSubscription(context.Background(), wrapper, azureOptions{
+		Name:                      testInfraName,
+		OwnedTagValue:             ownedAzureResourceTagValue,
+		OIDCResourceGroupName:     testOIDCResourceGroupName,
+		IdentityResourceGroupName: testOIDCResourceGroupName,
+		StorageAccountName:        testStorageAccountName,
+		Region:                    testRegionName,
+	}, testSubscriptionID)
+	require.Error(t, err, "expected error")
+	assert.Contains(t, err.Error(), "storage account deletion failed", "unexpected error")
+}
+
+func TestDeleteStorageAccountTimeout(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	wrapper := mockAzureClientWrapper(mockCtrl)
+	// The deletion blocks until the context of the command is done, as an unresponsive endpoint would
+	wrapper.StorageAccountClient.(*mockazure.MockAccountsClient).EXPECT().Delete(gomock.Any(), testOIDCResourceGroupName, testStorageAccountName, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _, _ string, _ *armstorage.AccountsClientDeleteOptions) (armstorage.AccountsClientDeleteResponse, error) {
+			<-ctx.Done()
+			return armstorage.AccountsClientDeleteResponse{}, ctx.Err()
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := deleteStorageAccount(ctx, wrapper, testInfraName, ownedAzureResourceTagValue, "", testOIDCResourceGroupName, testStorageAccountName, 0, provisioning.TagSelector{}, false)
+	require.Error(t, err, "expected error")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "expected the deletion to be abandoned once the deadline passed")
+}
+
+func mockStorageAccountDelete(wrapper *azureclients.AzureClientWrapper, resourceGroupName, storageAccountName string) {
+	wrapper.StorageAccountClient.(*mockazure.MockAccountsClient).EXPECT().Delete(
+		gomock.Any(), // context
+		resourceGroupName,
+		storageAccountName,
+		gomock.Any(), // options
+	).Return(armstorage.AccountsClientDeleteResponse{}, nil)
+}
+
+func mockDeleteUserAssignedIdentitySuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string) {
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Delete(
+		gomock.Any(), // context
+		resourceGroupName,
+		managedIdentityName,
+		gomock.Any(), // options
+	).Return(
+		armmsi.UserAssignedIdentitiesClientDeleteResponse{},
+		nil, // no error
+	)
+}
+
+func mockResourceGroupBeginDelete(wrapper *azureclients.AzureClientWrapper, resourceGroupName string) {
+	// This poller is not polled because wrapper.Mock = true causes deleteResourceGroup to use
+	// an azureclients.PollerWrapper which returns immediately.
+	poller, _ := runtime.NewPoller(
+		&http.Response{
+			Body: http.NoBody,
+		},
+		runtime.NewPipeline("testpipeline", "", runtime.PipelineOptions{}, nil),
+		&runtime.NewPollerOptions[armresources.ResourceGroupsClientDeleteResponse]{},
+	)
+	wrapper.ResourceGroupsClient.(*mockazure.MockResourceGroupsClient).EXPECT().BeginDelete(gomock.Any(), resourceGroupName, gomock.Any()).Return(poller, nil)
+}
+
+func TestDiscoverDeletions(t *testing.T) {
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+	}
+	mockCtrl := gomock.NewController(t)
+	wrapper := mockAzureClientWrapper(mockCtrl)
+	mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+		"testinfraname-openshift-ingress-operator-cloud-credentials":         ownedTags,
+		"testinfraname-openshift-image-registry-installer-cloud-credentials": ownedTags,
+		"otherinfraname-openshift-ingress-operator-cloud-credentials":        {},
+	})
+	mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+	opts := azureOptions{
+		Name:                      testInfraName,
+		OwnedTagValue:             ownedAzureResourceTagValue,
+		OIDCResourceGroupName:     testOIDCResourceGroupName,
+		IdentityResourceGroupName: testOIDCResourceGroupName,
+		StorageAccountName:        testStorageAccountName,
+		Region:                    testRegionName,
+		DryRun:                    true,
+	}
+
+	// Nothing is deleted while discovering the resources to delete, the mocks expect no delete calls
+	summary, err := provisioning.DiscoverDeletions(func() error {
+		return deleteWithinSubscription(context.Background(), wrapper, opts, testSubscriptionID)
+	})
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string]int{"UserAssignedManagedIdentity": 2, "StorageAccount": 1}, summary.Counts(), "unexpected resources discovered for deletion")
+}
+
+func TestDiscoverDeletionsStorageAccountNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	wrapper := mockAzureClientWrapper(mockCtrl)
+	mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{})
+	mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+	opts := azureOptions{
+		Name:                      testInfraName,
+		OwnedTagValue:             ownedAzureResourceTagValue,
+		OIDCResourceGroupName:     testOIDCResourceGroupName,
+		IdentityResourceGroupName: testOIDCResourceGroupName,
+		StorageAccountName:        testStorageAccountName,
+		Region:                    testRegionName,
+		DryRun:                    true,
+	}
+
+	// A storage account which no longer exists is not reported for deletion
+	summary, err := provisioning.DiscoverDeletions(func() error {
+		return deleteWithinSubscription(context.Background(), wrapper, opts, testSubscriptionID)
+	})
+	require.NoError(t, err, "unexpected error")
+	assert.Empty(t, summary.Counts(), "unexpected resources discovered for deletion")
+}
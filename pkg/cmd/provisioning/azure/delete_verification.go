@@ -0,0 +1,146 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/pkg/errors"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+)
+
+// listRemainingResources returns the resources which ccoctl azure delete would delete for opts within the subscription
+// of client: the resource groups, the storage account and the user-assigned managed identities. Resources skipped by
+// the deletion, eg. those not matching --tag-selector, are not listed and resources within resource groups which no
+// longer exist are treated as deleted.
+func listRemainingResources(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions) ([]string, error) {
+	resources, err := listTeardownResources(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// selected mirrors the --tag-selector and --older-than checks of the deletion without logging skipped resources
+	selected := func(resource teardownResource) bool {
+		if !opts.TagSelector.Matches(tagValues(resource.tags)) {
+			return false
+		}
+		if opts.OlderThan == 0 {
+			return true
+		}
+		creationTime, found := resourceCreationTime(resource.createdAt, resource.tags)
+		return found && time.Since(creationTime) > opts.OlderThan
+	}
+	matchesComponent := func(*armmsi.Identity) bool { return true }
+	if opts.ComponentFilter != "" {
+		matchesComponent, err = managedIdentityComponentMatcher(opts.Name, opts.ComponentFilter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The identities within a separate identity resource group which was created by ccoctl are deleted along with it
+	identityResourceGroupOwned := false
+	for _, resource := range resources {
+		if resource.resourceType == resourceGroupResourceType && resource.name == opts.IdentityResourceGroupName {
+			identityResourceGroupOwned = resource.owned
+		}
+	}
+
+	remaining := []string{}
+	for _, resource := range resources {
+		var targeted bool
+		switch {
+		case opts.TeardownPlan != nil:
+			for _, planned := range opts.TeardownPlan.Resources {
+				if resource.matches(planned) {
+					targeted = true
+					break
+				}
+			}
+		case opts.DeleteOIDCResourceGroup:
+			switch {
+			case resource.resourceType == resourceGroupResourceType && resource.name == opts.OIDCResourceGroupName:
+				targeted = (resource.owned || opts.Force) && selected(resource)
+			case resource.resourceType == resourceGroupResourceType:
+				targeted = resource.owned && selected(resource)
+			case resource.resourceType == managedIdentityResourceType && resource.resourceGroupName != opts.OIDCResourceGroupName:
+				targeted = !identityResourceGroupOwned && resource.owned && selected(resource)
+			}
+		default:
+			switch resource.resourceType {
+			case managedIdentityResourceType:
+				targeted = !opts.SkipManagedIdentities && resource.owned && matchesComponent(resource.identity) && selected(resource)
+			case storageAccountResourceType:
+				targeted = resource.name == opts.StorageAccountName && opts.ComponentFilter == "" && !opts.SkipStorageAccount &&
+					hasClusterResourceTag(resource.tags, opts.ClusterID) &&
+					((opts.OlderThan == 0 && opts.TagSelector.Empty()) ||
+						(hasOwnedResourceTag(resource.tags, opts.Name, opts.OwnedTagValue) && selected(resource)))
+			}
+		}
+		if targeted {
+			remaining = append(remaining, describeTeardownResource(resource))
+		}
+	}
+	return remaining, nil
+}
+
+// listRemainingSigningKeySecret returns the secret storing the signing private key within the key vault of client
+// when it remains and would be deleted by ccoctl azure delete for opts
+func listRemainingSigningKeySecret(ctx context.Context, client keyVaultSecretsClient, vaultURL string, opts azureOptions) ([]string, error) {
+	secret, err := client.GetSecret(ctx, opts.KeyVaultSecretName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get secret %s within key vault %s", opts.KeyVaultSecretName, vaultURL)
+	}
+	if secret == nil || !hasOwnedResourceTag(secret.Tags, opts.Name, opts.OwnedTagValue) || !opts.TagSelector.Matches(tagValues(secret.Tags)) {
+		return []string{}, nil
+	}
+	return []string{fmt.Sprintf("secret %s within key vault %s", opts.KeyVaultSecretName, vaultURL)}, nil
+}
+
+// listAllRemainingResources returns the resources which ccoctl azure delete would delete for opts within each of
+// subscriptionIDs and the secret storing the signing private key, prefixing the resources with their subscription
+// when deleting across several subscriptions
+func listAllRemainingResources(ctx context.Context, cred azcore.TokenCredential, subscriptionIDs []string, opts azureOptions) ([]string, error) {
+	remaining := []string{}
+	for _, subscriptionID := range subscriptionIDs {
+		azureClientWrapper, err := newAzureClientWrapper(subscriptionID, cred)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Azure client")
+		}
+		resources, err := listRemainingResources(ctx, azureClientWrapper, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the resources remaining within subscription %s", subscriptionID)
+		}
+		for _, resource := range resources {
+			if len(subscriptionIDs) > 1 {
+				resource = fmt.Sprintf("%s within subscription %s", resource, subscriptionID)
+			}
+			remaining = append(remaining, resource)
+		}
+	}
+
+	if opts.DeleteKeyVaultSecret {
+		vaultURL := keyVaultURL(opts.KeyVaultName)
+		secrets, err := listRemainingSigningKeySecret(ctx, newKeyVaultClient(vaultURL, cred, newClientOptions()), vaultURL, opts)
+		if err != nil {
+			return nil, err
+		}
+		remaining = append(remaining, secrets...)
+	}
+	return remaining, nil
+}
+
+// describeTeardownResource describes resource for the list of resources which remain once deletion completes
+func describeTeardownResource(resource teardownResource) string {
+	switch resource.resourceType {
+	case resourceGroupResourceType:
+		return "resource group " + resource.name
+	case storageAccountResourceType:
+		return fmt.Sprintf("storage account %s within resource group %s", resource.name, resource.resourceGroupName)
+	default:
+		return fmt.Sprintf("user-assigned managed identity %s within resource group %s", resource.name, resource.resourceGroupName)
+	}
+}
@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestListRemainingResources(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	ingressIdentityName := testInfraName + "-openshift-ingress-operator-cloud-credentials"
+	devSelector, err := provisioning.ParseTagSelector("env=dev")
+	require.NoError(t, err, "unexpected error parsing tag selector")
+
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		opts                   azureOptions
+		expectRemaining        []string
+	}{
+		{
+			name: "No resources remain",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{})
+				return wrapper
+			},
+			expectRemaining: []string{},
+		},
+		{
+			name: "Owned resources remain",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					ingressIdentityName: ownedTags,
+					"otherinfraname-openshift-ingress-operator-cloud-credentials": {},
+				})
+				return wrapper
+			},
+			expectRemaining: []string{
+				"storage account " + testStorageAccountName + " within resource group " + testOIDCResourceGroupName,
+				"user-assigned managed identity " + ingressIdentityName + " within resource group " + testOIDCResourceGroupName,
+			},
+		},
+		{
+			name: "Resources skipped by the deletion not listed",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					ingressIdentityName: ownedTags,
+				})
+				return wrapper
+			},
+			opts:            azureOptions{TagSelector: devSelector},
+			expectRemaining: []string{},
+		},
+		{
+			name: "No resources remain once the OIDC resource group was deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+			opts:            azureOptions{DeleteOIDCResourceGroup: true},
+			expectRemaining: []string{},
+		},
+		{
+			name: "Owned OIDC resource group remains",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					ingressIdentityName: ownedTags,
+				})
+				return wrapper
+			},
+			opts:            azureOptions{DeleteOIDCResourceGroup: true},
+			expectRemaining: []string{"resource group " + testOIDCResourceGroupName},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			wrapper := test.mockAzureClientWrapper(mockCtrl)
+			opts := test.opts
+			opts.Name = testInfraName
+			opts.OwnedTagValue = ownedAzureResourceTagValue
+			opts.OIDCResourceGroupName = testOIDCResourceGroupName
+			opts.IdentityResourceGroupName = testOIDCResourceGroupName
+			opts.StorageAccountName = testStorageAccountName
+			opts.Region = testRegionName
+
+			remaining, err := listRemainingResources(context.Background(), wrapper, opts)
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectRemaining, remaining, "unexpected remaining resources")
+		})
+	}
+}
+
+func TestDeleteWithVerification(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	ingressIdentityName := testInfraName + "-openshift-ingress-operator-cloud-credentials"
+
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		opts                   azureOptions
+	}{
+		{
+			name: "Storage account and managed identities deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				// Deletion
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					ingressIdentityName: ownedTags,
+				})
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, ingressIdentityName)
+				mockStorageAccountDelete(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+				// Verification, the OIDC resource group which was not to be deleted remains
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{})
+				return wrapper
+			},
+		},
+		{
+			name: "OIDC resource group deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				// Deletion
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockResourceGroupBeginDelete(wrapper, testOIDCResourceGroupName)
+				// Verification
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+			opts: azureOptions{DeleteOIDCResourceGroup: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			wrapper := test.mockAzureClientWrapper(mockCtrl)
+			opts := test.opts
+			opts.Name = testInfraName
+			opts.OwnedTagValue = ownedAzureResourceTagValue
+			opts.OIDCResourceGroupName = testOIDCResourceGroupName
+			opts.IdentityResourceGroupName = testOIDCResourceGroupName
+			opts.StorageAccountName = testStorageAccountName
+			opts.Region = testRegionName
+
+			require.NoError(t, deleteWithinSubscription(context.Background(), wrapper, opts, testSubscriptionID), "unexpected error deleting")
+			err := provisioning.VerifyDeletion(func() ([]string, error) {
+				return listRemainingResources(context.Background(), wrapper, opts)
+			})
+			require.NoError(t, err, "unexpected error verifying the deletion")
+		})
+	}
+}
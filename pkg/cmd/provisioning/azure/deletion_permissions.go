@@ -0,0 +1,121 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	armauthorization "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/pkg/errors"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// deletionPermissions returns the permissions required to delete the resources selected by opts, each checked
+// against the resource group which contains the resources whose deletion requires it
+func deletionPermissions(opts azureOptions) []provisioning.DeletionPermission {
+	identities := fmt.Sprintf("the user-assigned managed identities of %s", opts.Name)
+	if opts.DeleteOIDCResourceGroup {
+		permissions := []provisioning.DeletionPermission{
+			{Permission: "Microsoft.Resources/subscriptions/resourceGroups/delete", Resource: opts.OIDCResourceGroupName, Blocks: "the OIDC resource group " + opts.OIDCResourceGroupName},
+		}
+		// The identity resource group is only deleted when it was created by ccoctl, otherwise the user-assigned
+		// managed identities within it are
+		if opts.IdentityResourceGroupName != opts.OIDCResourceGroupName {
+			permissions = append(permissions,
+				provisioning.DeletionPermission{Permission: "Microsoft.ManagedIdentity/userAssignedIdentities/delete", Resource: opts.IdentityResourceGroupName, Blocks: identities})
+		}
+		return permissions
+	}
+
+	permissions := []provisioning.DeletionPermission{}
+	if !opts.SkipManagedIdentities {
+		permissions = append(permissions,
+			provisioning.DeletionPermission{Permission: "Microsoft.ManagedIdentity/userAssignedIdentities/delete", Resource: opts.IdentityResourceGroupName, Blocks: identities})
+	}
+	if opts.ComponentFilter == "" && !opts.SkipStorageAccount {
+		permissions = append(permissions,
+			provisioning.DeletionPermission{Permission: "Microsoft.Storage/storageAccounts/delete", Resource: opts.OIDCResourceGroupName, Blocks: "the storage account " + opts.StorageAccountName})
+	}
+	return permissions
+}
+
+// checkDeletionPermissions lists the permissions of the identity with which deletion is performed on the resource
+// groups containing the resources selected by opts, to verify that it is permitted to delete them before any of them
+// is deleted. Resource groups which do not exist contain nothing to delete. When the permissions cannot be listed the
+// check is reported as skipped, which fails it when failOnInsufficient is provided.
+func checkDeletionPermissions(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions, failOnInsufficient bool) error {
+	missing := []provisioning.DeletionPermission{}
+	resourceGroupPermissions := map[string][]*armauthorization.Permission{}
+	for _, required := range deletionPermissions(opts) {
+		permissions, listed := resourceGroupPermissions[required.Resource]
+		if !listed {
+			var err error
+			permissions, err = listResourceGroupPermissions(ctx, client, required.Resource)
+			if err != nil {
+				var respErr *azcore.ResponseError
+				if errors.As(err, &respErr) && respErr.ErrorCode == "ResourceGroupNotFound" {
+					resourceGroupPermissions[required.Resource] = nil
+					continue
+				}
+				return provisioning.ReportUncheckedDeletionPermissions(
+					errors.Wrapf(err, "failed to list the permissions on resource group %s", required.Resource), failOnInsufficient)
+			}
+			resourceGroupPermissions[required.Resource] = permissions
+		}
+		if permissions == nil {
+			continue
+		}
+		if !actionPermitted(permissions, required.Permission) {
+			missing = append(missing, required)
+		}
+	}
+	return provisioning.ReportMissingDeletionPermissions(missing, failOnInsufficient)
+}
+
+// listResourceGroupPermissions lists the permissions of the identity running ccoctl on the resource group identified
+// by resourceGroupName. The list is empty rather than nil when the identity has no permissions.
+func listResourceGroupPermissions(ctx context.Context, client *azureclients.AzureClientWrapper, resourceGroupName string) ([]*armauthorization.Permission, error) {
+	permissions := []*armauthorization.Permission{}
+	pager := client.PermissionsClient.NewListForResourceGroupPager(resourceGroupName, &armauthorization.PermissionsClientListForResourceGroupOptions{})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range page.Value {
+			if permission != nil {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// actionPermitted returns whether action is permitted by permissions, ie. matched by the actions of one of the
+// permissions without being matched by its denied actions. Actions are matched case-insensitively and may contain
+// "*" wildcards, eg. "Microsoft.Storage/*" or "*/delete".
+func actionPermitted(permissions []*armauthorization.Permission, action string) bool {
+	for _, permission := range permissions {
+		if matchesAnyAction(permission.Actions, action) && !matchesAnyAction(permission.NotActions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyAction(patterns []*string, action string) bool {
+	for _, pattern := range patterns {
+		if pattern == nil {
+			continue
+		}
+		expr := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(*pattern), `\*`, ".*") + "$"
+		if regexp.MustCompile(expr).MatchString(action) {
+			return true
+		}
+	}
+	return false
+}
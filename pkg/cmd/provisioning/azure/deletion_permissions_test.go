@@ -0,0 +1,120 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	armauthorization "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+)
+
+func TestActionPermitted(t *testing.T) {
+	permissions := []*armauthorization.Permission{
+		{Actions: []*string{to.Ptr("Microsoft.Storage/*")}, NotActions: []*string{to.Ptr("Microsoft.Storage/storageAccounts/delete")}},
+		{Actions: []*string{to.Ptr("*/read"), to.Ptr("microsoft.managedidentity/userassignedidentities/*")}},
+	}
+	assert.True(t, actionPermitted(permissions, "Microsoft.Storage/storageAccounts/write"), "expected action matched by a wildcard to be permitted")
+	assert.False(t, actionPermitted(permissions, "Microsoft.Storage/storageAccounts/delete"), "expected denied action not to be permitted")
+	assert.True(t, actionPermitted(permissions, "Microsoft.ManagedIdentity/userAssignedIdentities/delete"), "expected actions to be matched case-insensitively")
+	assert.False(t, actionPermitted(permissions, "Microsoft.Resources/subscriptions/resourceGroups/delete"), "expected action without a matching permission not to be permitted")
+}
+
+func TestCheckDeletionPermissions(t *testing.T) {
+	owner := []*armauthorization.Permission{{Actions: []*string{to.Ptr("*")}}}
+	reader := []*armauthorization.Permission{{Actions: []*string{to.Ptr("*/read")}}}
+	opts := azureOptions{
+		Name:                      testInfraName,
+		OIDCResourceGroupName:     testOIDCResourceGroupName,
+		IdentityResourceGroupName: testOIDCResourceGroupName,
+		StorageAccountName:        testStorageAccountName,
+	}
+
+	tests := []struct {
+		name               string
+		permissions        []*armauthorization.Permission
+		listError          error
+		failOnInsufficient bool
+		expectError        []string
+	}{
+		{
+			name:               "Every permission granted",
+			permissions:        owner,
+			failOnInsufficient: true,
+		},
+		{
+			name:        "Missing permissions reported without failing",
+			permissions: reader,
+		},
+		{
+			name:               "Missing permissions fail with --fail-on-insufficient-permissions",
+			permissions:        reader,
+			failOnInsufficient: true,
+			expectError: []string{
+				"Microsoft.ManagedIdentity/userAssignedIdentities/delete, blocking the deletion of the user-assigned managed identities of " + testInfraName,
+				"Microsoft.Storage/storageAccounts/delete, blocking the deletion of the storage account " + testStorageAccountName,
+			},
+		},
+		{
+			name:               "Nothing to check within a resource group which does not exist",
+			listError:          responseError(http.StatusNotFound, "ResourceGroupNotFound"),
+			failOnInsufficient: true,
+		},
+		{
+			name:      "Check skipped when the permissions cannot be listed",
+			listError: errors.New("AuthorizationFailed: not authorized to perform Microsoft.Authorization/permissions/read"),
+		},
+		{
+			name:               "Unlisted permissions fail with --fail-on-insufficient-permissions",
+			listError:          errors.New("AuthorizationFailed: not authorized to perform Microsoft.Authorization/permissions/read"),
+			failOnInsufficient: true,
+			expectError:        []string{"failed to list the permissions on resource group " + testOIDCResourceGroupName},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			wrapper := mockAzureClientWrapper(mockCtrl)
+			mockPermissionsListForResourceGroupPager(wrapper, testOIDCResourceGroupName, test.permissions, test.listError)
+
+			err := checkDeletionPermissions(context.Background(), wrapper, opts, test.failOnInsufficient)
+			if len(test.expectError) == 0 {
+				require.NoError(t, err, "unexpected error")
+				return
+			}
+			require.Error(t, err, "expected error")
+			for _, expected := range test.expectError {
+				assert.Contains(t, err.Error(), expected, "unexpected error")
+			}
+		})
+	}
+}
+
+// mockPermissionsListForResourceGroupPager mocks listing the permissions on the resource group identified by
+// resourceGroupName, which fails with listError when provided
+func mockPermissionsListForResourceGroupPager(wrapper *azureclients.AzureClientWrapper, resourceGroupName string, permissions []*armauthorization.Permission, listError error) {
+	wrapper.PermissionsClient.(*mockazure.MockPermissionsClient).EXPECT().NewListForResourceGroupPager(resourceGroupName, gomock.Any()).Return(
+		runtime.NewPager(runtime.PagingHandler[armauthorization.PermissionsClientListForResourceGroupResponse]{
+			More: func(current armauthorization.PermissionsClientListForResourceGroupResponse) bool {
+				return current.NextLink != nil
+			},
+			Fetcher: func(ctx context.Context, current *armauthorization.PermissionsClientListForResourceGroupResponse) (armauthorization.PermissionsClientListForResourceGroupResponse, error) {
+				if listError != nil {
+					return armauthorization.PermissionsClientListForResourceGroupResponse{}, listError
+				}
+				return armauthorization.PermissionsClientListForResourceGroupResponse{
+					PermissionGetResult: armauthorization.PermissionGetResult{Value: permissions},
+				}, nil
+			},
+		}),
+	).Times(1)
+}
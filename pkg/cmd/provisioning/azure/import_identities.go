@@ -0,0 +1,262 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// ImportIdentitiesOpts captures the azureOptions that affect importing pre-existing user-assigned managed
+	// identities into the ownership of ccoctl
+	ImportIdentitiesOpts = azureOptions{}
+)
+
+const (
+	// importedManagedIdentityStepPrefix is the prefix of the inventory step recorded once a pre-existing user-assigned
+	// managed identity has been imported, the step is suffixed with the name of the user-assigned managed identity
+	importedManagedIdentityStepPrefix = "import-managed-identity/"
+
+	// userAssignedIdentityResourceType is the resource type of the IDs of user-assigned managed identities
+	userAssignedIdentityResourceType = "Microsoft.ManagedIdentity/userAssignedIdentities"
+)
+
+// parseManagedIdentityIDs parses the resource IDs of the user-assigned managed identities provided with --identity-id,
+// which must be user-assigned managed identities within the resource group identified by resourceGroupName of the
+// subscription identified by subscriptionID. Duplicate IDs are ignored.
+func parseManagedIdentityIDs(identityIDs []string, subscriptionID, resourceGroupName string) ([]*arm.ResourceID, error) {
+	if len(identityIDs) == 0 {
+		return nil, errors.New("no --identity-id provided, the IDs of the user-assigned managed identities to import must be provided explicitly")
+	}
+	seen := map[string]bool{}
+	resourceIDs := []*arm.ResourceID{}
+	for _, identityID := range identityIDs {
+		resourceID, err := arm.ParseResourceID(identityID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid --identity-id %s", identityID)
+		}
+		if !strings.EqualFold(resourceID.ResourceType.String(), userAssignedIdentityResourceType) {
+			return nil, fmt.Errorf("invalid --identity-id %s, the ID of a user-assigned managed identity of type %s is required", identityID, userAssignedIdentityResourceType)
+		}
+		if !strings.EqualFold(resourceID.SubscriptionID, subscriptionID) {
+			return nil, fmt.Errorf("invalid --identity-id %s, the user-assigned managed identity must be within subscription %s", identityID, subscriptionID)
+		}
+		// ccoctl azure delete only deletes the user-assigned managed identities within the OIDC resource group
+		if !strings.EqualFold(resourceID.ResourceGroupName, resourceGroupName) {
+			return nil, fmt.Errorf("invalid --identity-id %s, the user-assigned managed identity must be within the OIDC resource group %s to be managed by ccoctl", identityID, resourceGroupName)
+		}
+		key := strings.ToLower(resourceID.String())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	return resourceIDs, nil
+}
+
+// foreignOwnedResourceTagKey returns the key of a CCO "owned" tag within tags for another name than name, if any
+func foreignOwnedResourceTagKey(tags map[string]*string, name string) (string, bool) {
+	for key := range tags {
+		if strings.HasPrefix(key, ownedAzureResourceTagKeyPrefix+"_") && key != ownedResourceTagKey(name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// importIdentities tags the pre-existing user-assigned managed identities identified by identityIDs with CCO's "owned"
+// tag for name, along with resourceTags, so that they are treated as created by ccoctl for name, eg. by ccoctl azure
+// delete. Every identity is validated before any is tagged: identities must exist and must not carry the "owned" tag
+// of another name. Imported identities are recorded within inventory, when provided. When dryRun is set, identities
+// which would have been tagged are logged rather than tagged.
+func importIdentities(ctx context.Context, client *azureclients.AzureClientWrapper, name, ownedTagValue, resourceGroupName string, identityIDs []*arm.ResourceID, resourceTags map[string]string, inventory *provisioning.Inventory, dryRun bool) error {
+
+	tags := map[string]string{ownedResourceTagKey(name): ownedTagValue}
+	for key, value := range resourceTags {
+		tags[key] = value
+	}
+
+	identities := []armmsi.Identity{}
+	for _, identityID := range identityIDs {
+		getResp, err := client.UserAssignedIdentitiesClient.Get(ctx, resourceGroupName, identityID.Name, &armmsi.UserAssignedIdentitiesClientGetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user-assigned managed identity %s", identityID.Name)
+		}
+		if key, found := foreignOwnedResourceTagKey(getResp.Identity.Tags, name); found {
+			return fmt.Errorf("user-assigned managed identity %s carries the owned tag %s of another name, refusing to import it for name %s", identityID.Name, key, name)
+		}
+		identities = append(identities, getResp.Identity)
+	}
+
+	// User-assigned managed identities are tagged concurrently once all of them have been validated
+	tagIdentities := []provisioning.TagOperation{}
+	for _, identity := range identities {
+		mergedTags, needToUpdate := mergeResourceTags(tags, identity.Tags)
+		identityName, location := *identity.Name, identity.Location
+		switch {
+		case !needToUpdate:
+			log.Printf("User-assigned managed identity %s is already owned by ccoctl for name %s", identityName, name)
+		case dryRun:
+			log.Printf("Would tag user-assigned managed identity %s with key=%s, value=%s", identityName, ownedResourceTagKey(name), ownedTagValue)
+		default:
+			tagIdentities = append(tagIdentities, provisioning.TagOperation{
+				ResourceType: "UserAssignedManagedIdentity",
+				ResourceName: identityName,
+				Tag: func() error {
+					_, err := client.UserAssignedIdentitiesClient.CreateOrUpdate(
+						ctx,
+						resourceGroupName,
+						identityName,
+						armmsi.Identity{
+							Location: location,
+							Tags:     mergedTags,
+						},
+						&armmsi.UserAssignedIdentitiesClientCreateOrUpdateOptions{},
+					)
+					if err != nil {
+						return err
+					}
+					log.Printf("Tagged user-assigned managed identity %s", identityName)
+					return nil
+				},
+			})
+		}
+	}
+	if dryRun {
+		log.Printf("Dry run complete, %d user-assigned managed identities would have been imported", len(identities))
+		return nil
+	}
+	if err := provisioning.TagResources(tagIdentities, isThrottlingError); err != nil {
+		return errors.Wrap(err, "failed to tag user-assigned managed identities")
+	}
+
+	for _, identity := range identities {
+		provisioning.EmitResourceEvent("UserAssignedManagedIdentity", *identity.ID, provisioning.ResourceUpdated)
+		if inventory == nil {
+			continue
+		}
+		err := inventory.CompleteStep(importedManagedIdentityStepPrefix+*identity.Name, provisioning.InventoryResource{
+			Type: "UserAssignedManagedIdentity",
+			Name: *identity.Name,
+			ID:   *identity.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("Imported %d user-assigned managed identities for name %s", len(identities), name)
+	return nil
+}
+
+// loadOrCreateInventory returns the inventory previously saved to outputDir for name, or a new inventory which will be
+// saved to outputDir when there is none
+func loadOrCreateInventory(outputDir, name string) *provisioning.Inventory {
+	inventory, err := provisioning.LoadInventory(outputDir)
+	if err != nil || inventory.Provider != "azure" || inventory.Name != name {
+		return provisioning.NewInventory(outputDir, "azure", name)
+	}
+	return inventory
+}
+
+func importIdentitiesCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	if err := validateOwnedTagValue(ImportIdentitiesOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+	if err := addClusterResourceTag(&ImportIdentitiesOpts); err != nil {
+		log.Fatal(err)
+	}
+
+	if ImportIdentitiesOpts.OIDCResourceGroupName == "" {
+		ImportIdentitiesOpts.OIDCResourceGroupName = ImportIdentitiesOpts.Name + oidcResourceGroupSuffix
+		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", ImportIdentitiesOpts.OIDCResourceGroupName)
+	}
+
+	identityIDs, err := parseManagedIdentityIDs(ImportIdentitiesOpts.IdentityIDs, ImportIdentitiesOpts.SubscriptionID, ImportIdentitiesOpts.OIDCResourceGroupName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cred, err := newAzureCredential(ctx, ImportIdentitiesOpts.TenantID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	azureClientWrapper, err := newAzureClientWrapper(ImportIdentitiesOpts.SubscriptionID, cred)
+	if err != nil {
+		log.Fatalf("Failed to create Azure client: %s", err)
+	}
+
+	var inventory *provisioning.Inventory
+	if !ImportIdentitiesOpts.DryRun {
+		inventory = loadOrCreateInventory(ImportIdentitiesOpts.OutputDir, ImportIdentitiesOpts.Name)
+	}
+
+	err = importIdentities(
+		ctx,
+		azureClientWrapper,
+		ImportIdentitiesOpts.Name,
+		ImportIdentitiesOpts.OwnedTagValue,
+		ImportIdentitiesOpts.OIDCResourceGroupName,
+		identityIDs,
+		ImportIdentitiesOpts.UserTags,
+		inventory,
+		ImportIdentitiesOpts.DryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NewImportIdentitiesCmd provides the "import-identities" subcommand
+func NewImportIdentitiesCmd() *cobra.Command {
+	importIdentitiesCmd := &cobra.Command{
+		Use:     "import-identities --name NAME --subscription-id SUBSCRIPTION_ID --identity-id IDENTITY_ID",
+		Aliases: []string{"adopt"},
+		Short:   "Import pre-existing user-assigned managed identities into the ownership of ccoctl",
+		Long: "This command will tag the pre-existing user-assigned managed identities identified by --identity-id with the owned tag applied by ccoctl for --name " +
+			"and record them within the inventory of the output directory, so that they are managed as if they had been created by ccoctl, eg. deleted by ccoctl azure delete. " +
+			"The user-assigned managed identities must be within the OIDC resource group and must not carry the owned tag of another name.",
+		Run: importIdentitiesCmd,
+	}
+
+	// Required
+	importIdentitiesCmd.PersistentFlags().StringVar(&ImportIdentitiesOpts.Name, "name", "", "User-defined name of the Azure resources managed by ccoctl into which the user-assigned managed identities are imported")
+	importIdentitiesCmd.MarkPersistentFlagRequired("name")
+	importIdentitiesCmd.PersistentFlags().StringVar(&ImportIdentitiesOpts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which the user-assigned managed identities exist")
+	importIdentitiesCmd.MarkPersistentFlagRequired("subscription-id")
+	importIdentitiesCmd.PersistentFlags().StringSliceVar(
+		&ImportIdentitiesOpts.IdentityIDs,
+		"identity-id",
+		nil,
+		"Resource ID of a user-assigned managed identity to import, eg. /subscriptions/<subscription ID>/resourceGroups/<resource group>/providers/Microsoft.ManagedIdentity/userAssignedIdentities/<name>. "+
+			"May be specified multiple times, or as a comma-separated list",
+	)
+	importIdentitiesCmd.MarkPersistentFlagRequired("identity-id")
+
+	// Optional
+	importIdentitiesCmd.PersistentFlags().StringVar(&ImportIdentitiesOpts.OIDCResourceGroupName, "oidc-resource-group-name", "", "The Azure resource group which contains the user-assigned managed identities to import. If not specified, the name of the OIDC resource group will be derived from the --name parameter.")
+	importIdentitiesCmd.PersistentFlags().StringVar(
+		&ImportIdentitiesOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag with which the user-assigned managed identities are tagged. ", ownedAzureResourceTagKeyPrefix)+
+			"The same value must be provided to ccoctl azure delete in order to delete the resources",
+	)
+	importIdentitiesCmd.PersistentFlags().StringVar(&ImportIdentitiesOpts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag the user-assigned managed identities, the identities will be tagged with 'kubernetes.io_cluster.<cluster ID> = owned'")
+	importIdentitiesCmd.PersistentFlags().StringToStringVar(&ImportIdentitiesOpts.UserTags, "user-tags", map[string]string{}, "User tags to be applied to the user-assigned managed identities, multiple tags may be specified comma-separated for example: --user-tags key1=value1,key2=value2")
+	importIdentitiesCmd.PersistentFlags().StringVar(&ImportIdentitiesOpts.OutputDir, "output-dir", "", "Directory containing the inventory within which the imported user-assigned managed identities are recorded. Defaults to the current directory.")
+	importIdentitiesCmd.PersistentFlags().BoolVar(&ImportIdentitiesOpts.DryRun, "dry-run", false, "Skip tagging user-assigned managed identities and display the user-assigned managed identities that would have been imported")
+	importIdentitiesCmd.PersistentFlags().StringVar(&ImportIdentitiesOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+
+	return importIdentitiesCmd
+}
@@ -0,0 +1,191 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const testImportedIdentityName = "hand-rolled-ingress"
+
+func TestParseManagedIdentityIDs(t *testing.T) {
+	identityID := managedIdentityID(testSubscriptionID, testOIDCResourceGroupName, testImportedIdentityName)
+	tests := []struct {
+		name          string
+		identityIDs   []string
+		expectedNames []string
+		expectError   bool
+	}{
+		{
+			name:          "User-assigned managed identity within the OIDC resource group",
+			identityIDs:   []string{identityID},
+			expectedNames: []string{testImportedIdentityName},
+		},
+		{
+			name:          "Duplicate IDs ignored",
+			identityIDs:   []string{identityID, identityID},
+			expectedNames: []string{testImportedIdentityName},
+		},
+		{
+			name:        "No IDs",
+			expectError: true,
+		},
+		{
+			name:        "Invalid ID",
+			identityIDs: []string{testImportedIdentityName},
+			expectError: true,
+		},
+		{
+			name:        "ID of another resource type",
+			identityIDs: []string{fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", testSubscriptionID, testOIDCResourceGroupName, testStorageAccountName)},
+			expectError: true,
+		},
+		{
+			name:        "User-assigned managed identity within another subscription",
+			identityIDs: []string{managedIdentityID("00000000-0000-0000-0000-000000000000", testOIDCResourceGroupName, testImportedIdentityName)},
+			expectError: true,
+		},
+		{
+			name:        "User-assigned managed identity within another resource group",
+			identityIDs: []string{managedIdentityID(testSubscriptionID, "other-resource-group", testImportedIdentityName)},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resourceIDs, err := parseManagedIdentityIDs(test.identityIDs, testSubscriptionID, testOIDCResourceGroupName)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			names := []string{}
+			for _, resourceID := range resourceIDs {
+				names = append(names, resourceID.Name)
+			}
+			assert.Equal(t, test.expectedNames, names, "unexpected user-assigned managed identities")
+		})
+	}
+}
+
+func TestImportIdentities(t *testing.T) {
+	ownedTags := map[string]*string{
+		ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue),
+		"testTagKey":                       to.Ptr("testTagValue"),
+	}
+
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		dryRun                 bool
+		expectError            bool
+		expectRecorded         bool
+	}{
+		{
+			name: "User-assigned managed identity tagged and recorded",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetImportedManagedIdentity(wrapper, map[string]*string{"testTagKey": to.Ptr("testTagValue")})
+				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, testImportedIdentityName, testRegionName, testSubscriptionID, ownedTags)
+				return wrapper
+			},
+			expectRecorded: true,
+		},
+		{
+			name: "User-assigned managed identity already owned recorded without tagging",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetImportedManagedIdentity(wrapper, ownedTags)
+				return wrapper
+			},
+			expectRecorded: true,
+		},
+		{
+			name: "User-assigned managed identity not tagged with dry run",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetImportedManagedIdentity(wrapper, nil)
+				return wrapper
+			},
+			dryRun: true,
+		},
+		{
+			name: "User-assigned managed identity owned for another name refused",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetImportedManagedIdentity(wrapper, map[string]*string{ownedResourceTagKey("othername"): to.Ptr(ownedAzureResourceTagValue)})
+				return wrapper
+			},
+			expectError: true,
+		},
+		{
+			name: "User-assigned managed identity not found",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetUserAssignedManagedIdentityNotFound(wrapper, testOIDCResourceGroupName, testImportedIdentityName)
+				return wrapper
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			identityIDs, err := parseManagedIdentityIDs([]string{managedIdentityID(testSubscriptionID, testOIDCResourceGroupName, testImportedIdentityName)}, testSubscriptionID, testOIDCResourceGroupName)
+			require.NoError(t, err, "unexpected error parsing identity IDs")
+			var inventory *provisioning.Inventory
+			if !test.dryRun {
+				inventory = provisioning.NewInventory(t.TempDir(), "azure", testInfraName)
+			}
+
+			err = importIdentities(context.Background(), test.mockAzureClientWrapper(mockCtrl), testInfraName, ownedAzureResourceTagValue, testOIDCResourceGroupName, identityIDs, map[string]string{}, inventory, test.dryRun)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			if test.expectRecorded {
+				step, found := inventory.Step(importedManagedIdentityStepPrefix + testImportedIdentityName)
+				require.True(t, found, "expected imported identity to be recorded within the inventory")
+				assert.Equal(t, []provisioning.InventoryResource{{
+					Type: "UserAssignedManagedIdentity",
+					Name: testImportedIdentityName,
+					ID:   managedIdentityID(testSubscriptionID, testOIDCResourceGroupName, testImportedIdentityName),
+				}}, step.Resources, "unexpected inventory resources")
+			}
+		})
+	}
+}
+
+func mockGetImportedManagedIdentity(wrapper *azureclients.AzureClientWrapper, tags map[string]*string) {
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Get(
+		gomock.Any(), // context
+		testOIDCResourceGroupName,
+		testImportedIdentityName,
+		gomock.Any(), // options
+	).Return(
+		armmsi.UserAssignedIdentitiesClientGetResponse{
+			Identity: armmsi.Identity{
+				ID:       to.Ptr(managedIdentityID(testSubscriptionID, testOIDCResourceGroupName, testImportedIdentityName)),
+				Name:     to.Ptr(testImportedIdentityName),
+				Location: to.Ptr(testRegionName),
+				Tags:     tags,
+			},
+		},
+		nil, // no error
+	)
+}
@@ -0,0 +1,309 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// keyVaultAPIVersion is the version of the Azure Key Vault data plane API used to store secrets
+	keyVaultAPIVersion = "7.4"
+	// keyVaultScope is the scope of the tokens with which requests are sent to Azure Key Vault
+	keyVaultScope = "https://vault.azure.net/.default"
+	// keyVaultDNSSuffix is the DNS suffix of the vaults of the Azure public cloud
+	keyVaultDNSSuffix = "vault.azure.net"
+
+	// signingKeySecretContentType is the content type of the Key Vault secret in which the signing private key is stored
+	signingKeySecretContentType = "application/x-pem-file"
+	// signingKeySecretSuffix is appended to --name to form the default name of the signing private key's secret
+	signingKeySecretSuffix = "-service-account-signer"
+	// signingKeyReferenceFile is the name of the file written to the tls directory, in place of the signing private
+	// key, which references the Key Vault secret in which the signing private key is stored
+	signingKeyReferenceFile = "bound-service-account-signing-key.key-vault-reference.json"
+)
+
+var (
+	// keyVaultNameRegexp matches the names of Azure key vaults, which are 3-24 characters long, start with a letter,
+	// end with a letter or digit and may not contain consecutive hyphens
+	keyVaultNameRegexp = regexp.MustCompile(`^[a-zA-Z](-?[a-zA-Z0-9])+$`)
+	// keyVaultSecretNameRegexp matches the names of Azure Key Vault secrets
+	keyVaultSecretNameRegexp = regexp.MustCompile(`^[0-9a-zA-Z-]{1,127}$`)
+)
+
+// keyVaultSecret is a secret stored within an Azure key vault
+type keyVaultSecret struct {
+	ID          string             `json:"id,omitempty"`
+	Value       string             `json:"value,omitempty"`
+	ContentType string             `json:"contentType,omitempty"`
+	Tags        map[string]*string `json:"tags,omitempty"`
+}
+
+// keyVaultSecretsClient manages the secrets of a single Azure key vault
+type keyVaultSecretsClient interface {
+	// GetSecret returns the latest version of the secret identified by name, or nil when the secret does not exist
+	GetSecret(ctx context.Context, name string) (*keyVaultSecret, error)
+	// SetSecret stores secret as a new version of the secret identified by name and returns the stored version
+	SetSecret(ctx context.Context, name string, secret keyVaultSecret) (*keyVaultSecret, error)
+	// DeleteSecret deletes the secret identified by name along with all of its versions
+	DeleteSecret(ctx context.Context, name string) error
+}
+
+// keyVaultClient implements keyVaultSecretsClient with the Azure Key Vault data plane REST API
+type keyVaultClient struct {
+	vaultURL string
+	pipeline runtime.Pipeline
+}
+
+// newKeyVaultClient returns a client for the secrets of the key vault at vaultURL authenticating with cred
+func newKeyVaultClient(vaultURL string, cred azcore.TokenCredential, options azpolicy.ClientOptions) keyVaultSecretsClient {
+	return &keyVaultClient{
+		vaultURL: vaultURL,
+		pipeline: runtime.NewPipeline("ccoctl", "v1", runtime.PipelineOptions{
+			PerRetry: []azpolicy.Policy{runtime.NewBearerTokenPolicy(cred, []string{keyVaultScope}, nil)},
+		}, &options),
+	}
+}
+
+func (c *keyVaultClient) secretRequest(ctx context.Context, method, name string) (*azpolicy.Request, error) {
+	req, err := runtime.NewRequest(ctx, method, fmt.Sprintf("%s/secrets/%s", c.vaultURL, url.PathEscape(name)))
+	if err != nil {
+		return nil, err
+	}
+	query := req.Raw().URL.Query()
+	query.Set("api-version", keyVaultAPIVersion)
+	req.Raw().URL.RawQuery = query.Encode()
+	req.Raw().Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (c *keyVaultClient) GetSecret(ctx context.Context, name string) (*keyVaultSecret, error) {
+	req, err := c.secretRequest(ctx, http.MethodGet, name)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.HasStatusCode(resp, http.StatusNotFound) {
+		return nil, nil
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	secret := &keyVaultSecret{}
+	if err := runtime.UnmarshalAsJSON(resp, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (c *keyVaultClient) SetSecret(ctx context.Context, name string, secret keyVaultSecret) (*keyVaultSecret, error) {
+	req, err := c.secretRequest(ctx, http.MethodPut, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.MarshalAsJSON(req, secret); err != nil {
+		return nil, err
+	}
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	stored := &keyVaultSecret{}
+	if err := runtime.UnmarshalAsJSON(resp, stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (c *keyVaultClient) DeleteSecret(ctx context.Context, name string) error {
+	req, err := c.secretRequest(ctx, http.MethodDelete, name)
+	if err != nil {
+		return err
+	}
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return nil
+}
+
+// keyVaultURL returns the URL of the key vault identified by vaultName
+func keyVaultURL(vaultName string) string {
+	return fmt.Sprintf("https://%s.%s", vaultName, keyVaultDNSSuffix)
+}
+
+// validateKeyVaultNames validates the names of the key vault and of the secret within it in which the signing private
+// key is stored
+func validateKeyVaultNames(vaultName, secretName string) error {
+	if len(vaultName) < 3 || len(vaultName) > 24 || !keyVaultNameRegexp.MatchString(vaultName) {
+		return fmt.Errorf("invalid --key-vault-name %q, key vault names must be between 3 and 24 characters in length, "+
+			"start with a letter, end with a letter or digit and may only contain letters, digits and non-consecutive hyphens", vaultName)
+	}
+	if !keyVaultSecretNameRegexp.MatchString(secretName) {
+		return fmt.Errorf("invalid --key-vault-secret-name %q, secret names must be between 1 and 127 characters in length "+
+			"and may only contain letters, digits and hyphens", secretName)
+	}
+	return nil
+}
+
+// signingKeySecretName returns secretName, or the default name of the signing private key's secret for name when
+// secretName is empty
+func signingKeySecretName(name, secretName string) string {
+	if secretName != "" {
+		return secretName
+	}
+	return name + signingKeySecretSuffix
+}
+
+// validateKeyVaultAccess ensures that the secret identified by secretName within the key vault of client may be read,
+// and that an existing secret carries CCO's "owned" tag for the provided name, before any Azure resources are created.
+func validateKeyVaultAccess(ctx context.Context, client keyVaultSecretsClient, vaultURL, name, ownedTagValue, secretName string) error {
+	secret, err := client.GetSecret(ctx, secretName)
+	if err != nil {
+		return errors.Wrapf(err, "unable to access key vault %s, the credentials must be allowed to get and set secrets", vaultURL)
+	}
+	if secret != nil && !hasOwnedResourceTag(secret.Tags, name, ownedTagValue) {
+		return fmt.Errorf("refusing to overwrite secret %s within key vault %s which does not have tag key=%s, value=%s, "+
+			"the secret may not have been created by ccoctl", secretName, vaultURL, ownedResourceTagKey(name), ownedTagValue)
+	}
+	return nil
+}
+
+// validateSigningKeyNotStored returns an error when the signing private key generated within outputDir by a previous
+// run has been stored within a key vault, in which case a new key pair must not be generated
+func validateSigningKeyNotStored(outputDir string) error {
+	referencePath := filepath.Join(outputDir, provisioning.TLSDirName, signingKeyReferenceFile)
+	if _, err := os.Stat(referencePath); err != nil {
+		return nil
+	}
+	privateKeyPath := filepath.Join(outputDir, provisioning.PrivateKeyFile)
+	if _, err := os.Stat(privateKeyPath); err == nil {
+		return nil
+	}
+	return fmt.Errorf("the signing private key of a previous run was stored within a key vault as recorded by %s, "+
+		"restore the private key from the key vault to %s to re-use the key pair", referencePath, privateKeyPath)
+}
+
+// signingKeyReference references the Key Vault secret in which the signing private key is stored
+type signingKeyReference struct {
+	VaultURL   string `json:"vaultURL"`
+	SecretName string `json:"secretName"`
+	// SecretID identifies the version of the secret in which the signing private key is stored
+	SecretID string `json:"secretID"`
+}
+
+// storeSigningKeyInKeyVault stores the signing private key generated within outputDir as the secret identified by
+// secretName within the key vault of client, tagged with CCO's "owned" tag for the provided name. The private key is
+// then removed from outputDir and a reference to the secret is written to the tls directory in its place. When dryRun
+// is provided the secret is logged but not stored and the private key is left in place.
+func storeSigningKeyInKeyVault(ctx context.Context, client keyVaultSecretsClient, vaultURL, name, ownedTagValue, secretName, outputDir string, dryRun bool) error {
+	tlsPrivateKeyPath := filepath.Join(outputDir, provisioning.TLSDirName, provisioning.BoundSAKeyFile)
+	privateKey, err := os.ReadFile(tlsPrivateKeyPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read signing private key")
+	}
+	if dryRun {
+		log.Printf("Would store signing private key %s as secret %s within key vault %s", tlsPrivateKeyPath, secretName, vaultURL)
+		return nil
+	}
+
+	ctx, span := provisioning.StartSpan(ctx, "azure.SetKeyVaultSecret",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("keyVaultSecret"),
+		provisioning.ResourceNameAttribute.String(secretName),
+	)
+	stored, err := client.SetSecret(ctx, secretName, keyVaultSecret{
+		Value:       string(privateKey),
+		ContentType: signingKeySecretContentType,
+		Tags:        map[string]*string{ownedResourceTagKey(name): &ownedTagValue},
+	})
+	provisioning.EndSpan(span, err)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store signing private key within key vault %s", vaultURL)
+	}
+	log.Printf("Stored signing private key as secret %s", stored.ID)
+	provisioning.EmitResourceEvent("KeyVaultSecret", stored.ID, provisioning.ResourceCreated)
+
+	reference, err := json.MarshalIndent(signingKeyReference{VaultURL: vaultURL, SecretName: secretName, SecretID: stored.ID}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode signing private key reference")
+	}
+	referencePath := filepath.Join(outputDir, provisioning.TLSDirName, signingKeyReferenceFile)
+	if err := os.WriteFile(referencePath, append(reference, '\n'), 0600); err != nil {
+		return errors.Wrap(err, "failed to write signing private key reference")
+	}
+	log.Printf("Saved signing private key reference to: %s", referencePath)
+
+	// The signing private key must only remain within the key vault
+	for _, path := range []string{tlsPrivateKeyPath, filepath.Join(outputDir, provisioning.PrivateKeyFile)} {
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to remove signing private key %s", path)
+		}
+		log.Printf("Removed signing private key %s", path)
+	}
+	return nil
+}
+
+// deleteSigningKeySecret deletes the secret identified by secretName within the key vault of client. The secret is
+// only deleted if it carries CCO's "owned" tag for the provided name and, when tagSelector is not empty, if its tags
+// match tagSelector. When dryRun is provided the secret is logged but not deleted.
+func deleteSigningKeySecret(ctx context.Context, client keyVaultSecretsClient, vaultURL, name, ownedTagValue, secretName string, tagSelector provisioning.TagSelector, dryRun bool) (err error) {
+	ctx, span := provisioning.StartSpan(ctx, "azure.DeleteKeyVaultSecret",
+		provisioning.ProviderAttribute.String("azure"),
+		provisioning.ResourceTypeAttribute.String("keyVaultSecret"),
+		provisioning.ResourceNameAttribute.String(secretName),
+	)
+	defer func() { provisioning.EndSpan(span, err) }()
+
+	secret, err := client.GetSecret(ctx, secretName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get secret %s within key vault %s", secretName, vaultURL)
+	}
+	if secret == nil {
+		log.Printf("Found no secret %s within key vault %s", secretName, vaultURL)
+		return nil
+	}
+	if !hasOwnedResourceTag(secret.Tags, name, ownedTagValue) {
+		return fmt.Errorf("refusing to delete secret %s within key vault %s which does not have tag key=%s, value=%s, "+
+			"the secret may not have been created by ccoctl", secretName, vaultURL, ownedResourceTagKey(name), ownedTagValue)
+	}
+	if !matchesTagSelector(fmt.Sprintf("secret %s within key vault %s", secretName, vaultURL), secret.Tags, tagSelector) {
+		return nil
+	}
+	if dryRun {
+		log.Printf("Would delete secret %s within key vault %s", secretName, vaultURL)
+		provisioning.RecordDeletionCandidate("KeyVaultSecret")
+		return nil
+	}
+	if err := client.DeleteSecret(ctx, secretName); err != nil {
+		return errors.Wrapf(err, "failed to delete secret %s within key vault %s", secretName, vaultURL)
+	}
+	log.Printf("Deleted secret %s within key vault %s", secretName, vaultURL)
+	provisioning.EmitResourceEvent("KeyVaultSecret", fmt.Sprintf("%s/secrets/%s", vaultURL, secretName), provisioning.ResourceDeleted)
+	return nil
+}
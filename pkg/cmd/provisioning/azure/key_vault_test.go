@@ -0,0 +1,232 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	azpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const testKeyVaultURL = "https://test-vault.vault.azure.net"
+
+// fakeKeyVault stores secrets in memory
+type fakeKeyVault struct {
+	secrets map[string]*keyVaultSecret
+	err     error
+}
+
+func (f *fakeKeyVault) GetSecret(ctx context.Context, name string) (*keyVaultSecret, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.secrets[name], nil
+}
+
+func (f *fakeKeyVault) SetSecret(ctx context.Context, name string, secret keyVaultSecret) (*keyVaultSecret, error) {
+	secret.ID = fmt.Sprintf("%s/secrets/%s/version", testKeyVaultURL, name)
+	f.secrets[name] = &secret
+	return &secret, nil
+}
+
+func (f *fakeKeyVault) DeleteSecret(ctx context.Context, name string) error {
+	delete(f.secrets, name)
+	return nil
+}
+
+// fakeTokenCredential returns a static token
+type fakeTokenCredential struct{}
+
+func (fakeTokenCredential) GetToken(ctx context.Context, options azpolicy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "test-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestValidateKeyVaultNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		vaultName   string
+		secretName  string
+		expectError bool
+	}{
+		{name: "Valid names", vaultName: "test-vault-1", secretName: "test-service-account-signer"},
+		{name: "Vault name too short", vaultName: "ab", secretName: "secret", expectError: true},
+		{name: "Vault name too long", vaultName: "abcdefghijklmnopqrstuvwxy", secretName: "secret", expectError: true},
+		{name: "Vault name starting with a digit", vaultName: "1vault", secretName: "secret", expectError: true},
+		{name: "Vault name with consecutive hyphens", vaultName: "test--vault", secretName: "secret", expectError: true},
+		{name: "Vault name ending with a hyphen", vaultName: "test-vault-", secretName: "secret", expectError: true},
+		{name: "Secret name with invalid characters", vaultName: "test-vault", secretName: "test_secret", expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateKeyVaultNames(test.vaultName, test.secretName)
+			if test.expectError {
+				assert.Error(t, err, "expected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidateKeyVaultAccess(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+
+	vault := &fakeKeyVault{secrets: map[string]*keyVaultSecret{}}
+	assert.NoError(t, validateKeyVaultAccess(context.Background(), vault, testKeyVaultURL, testInfraName, ownedAzureResourceTagValue, "secret"), "unexpected error without existing secret")
+
+	vault.secrets["secret"] = &keyVaultSecret{Tags: ownedTags}
+	assert.NoError(t, validateKeyVaultAccess(context.Background(), vault, testKeyVaultURL, testInfraName, ownedAzureResourceTagValue, "secret"), "unexpected error with existing owned secret")
+
+	vault.secrets["secret"] = &keyVaultSecret{}
+	assert.Error(t, validateKeyVaultAccess(context.Background(), vault, testKeyVaultURL, testInfraName, ownedAzureResourceTagValue, "secret"), "expected error with existing secret which is not owned")
+
+	vault.err = fmt.Errorf("403 Forbidden")
+	assert.Error(t, validateKeyVaultAccess(context.Background(), vault, testKeyVaultURL, testInfraName, ownedAzureResourceTagValue, "secret"), "expected error without access to the vault")
+}
+
+func TestStoreSigningKeyInKeyVault(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "Signing key stored"},
+		{name: "Signing key not stored with dry run", dryRun: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(outputDir, provisioning.TLSDirName), 0700), "failed to create tls directory")
+			require.NoError(t, provisioning.CreateKeys(outputDir), "failed to create key pair")
+			privateKeyPath := filepath.Join(outputDir, provisioning.TLSDirName, provisioning.BoundSAKeyFile)
+			privateKey, err := os.ReadFile(privateKeyPath)
+			require.NoError(t, err, "failed to read private key")
+
+			vault := &fakeKeyVault{secrets: map[string]*keyVaultSecret{}}
+			err = storeSigningKeyInKeyVault(context.Background(), vault, testKeyVaultURL, testInfraName, ownedAzureResourceTagValue, "test-secret", outputDir, test.dryRun)
+			require.NoError(t, err, "unexpected error storing signing key")
+
+			referencePath := filepath.Join(outputDir, provisioning.TLSDirName, signingKeyReferenceFile)
+			if test.dryRun {
+				assert.Empty(t, vault.secrets, "expected no secret to be stored with dry run")
+				assert.FileExists(t, privateKeyPath, "expected private key to be kept with dry run")
+				assert.NoFileExists(t, referencePath, "expected no reference with dry run")
+				return
+			}
+
+			secret := vault.secrets["test-secret"]
+			require.NotNil(t, secret, "expected secret to be stored")
+			assert.Equal(t, string(privateKey), secret.Value, "unexpected secret value")
+			assert.Equal(t, signingKeySecretContentType, secret.ContentType, "unexpected secret content type")
+			assert.True(t, hasOwnedResourceTag(secret.Tags, testInfraName, ownedAzureResourceTagValue), "expected secret to carry the owned tag")
+
+			assert.NoFileExists(t, privateKeyPath, "expected private key to be removed from the tls directory")
+			assert.NoFileExists(t, filepath.Join(outputDir, provisioning.PrivateKeyFile), "expected private key to be removed from the output directory")
+			assert.FileExists(t, filepath.Join(outputDir, provisioning.PublicKeyFile), "expected public key to be kept")
+
+			data, err := os.ReadFile(referencePath)
+			require.NoError(t, err, "expected reference to be written")
+			reference := signingKeyReference{}
+			require.NoError(t, json.Unmarshal(data, &reference), "failed to decode reference")
+			assert.Equal(t, signingKeyReference{VaultURL: testKeyVaultURL, SecretName: "test-secret", SecretID: secret.ID}, reference, "unexpected reference")
+
+			assert.Error(t, validateSigningKeyNotStored(outputDir), "expected error generating a new key pair once the private key is stored")
+		})
+	}
+}
+
+func TestDeleteSigningKeySecret(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	tests := []struct {
+		name          string
+		secret        *keyVaultSecret
+		dryRun        bool
+		expectError   bool
+		expectDeleted bool
+	}{
+		{name: "Owned secret deleted", secret: &keyVaultSecret{Tags: ownedTags}, expectDeleted: true},
+		{name: "Missing secret ignored"},
+		{name: "Secret which is not owned not deleted", secret: &keyVaultSecret{}, expectError: true},
+		{name: "Owned secret not deleted with dry run", secret: &keyVaultSecret{Tags: ownedTags}, dryRun: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vault := &fakeKeyVault{secrets: map[string]*keyVaultSecret{}}
+			if test.secret != nil {
+				vault.secrets["test-secret"] = test.secret
+			}
+			err := deleteSigningKeySecret(context.Background(), vault, testKeyVaultURL, testInfraName, ownedAzureResourceTagValue, "test-secret", provisioning.TagSelector{}, test.dryRun)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+			_, exists := vault.secrets["test-secret"]
+			assert.Equal(t, test.secret != nil && !test.expectDeleted, exists, "unexpected secret existence")
+		})
+	}
+}
+
+func TestKeyVaultClient(t *testing.T) {
+	secrets := map[string]keyVaultSecret{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" || r.URL.Query().Get("api-version") != keyVaultAPIVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		name := filepath.Base(r.URL.Path)
+		switch r.Method {
+		case http.MethodPut:
+			secret := keyVaultSecret{}
+			if err := json.NewDecoder(r.Body).Decode(&secret); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			secret.ID = fmt.Sprintf("https://%s/secrets/%s/version", r.Host, name)
+			secrets[name] = secret
+			json.NewEncoder(w).Encode(secret)
+		case http.MethodGet:
+			secret, ok := secrets[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(secret)
+		case http.MethodDelete:
+			delete(secrets, name)
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	client := newKeyVaultClient(server.URL, fakeTokenCredential{}, azpolicy.ClientOptions{Transport: server.Client()})
+
+	secret, err := client.GetSecret(context.Background(), "test-secret")
+	require.NoError(t, err, "unexpected error getting missing secret")
+	assert.Nil(t, secret, "expected no secret before it is stored")
+
+	stored, err := client.SetSecret(context.Background(), "test-secret", keyVaultSecret{Value: "key", ContentType: signingKeySecretContentType})
+	require.NoError(t, err, "unexpected error storing secret")
+	assert.NotEmpty(t, stored.ID, "expected stored secret to have an ID")
+
+	secret, err = client.GetSecret(context.Background(), "test-secret")
+	require.NoError(t, err, "unexpected error getting secret")
+	assert.Equal(t, "key", secret.Value, "unexpected secret value")
+
+	require.NoError(t, client.DeleteSecret(context.Background(), "test-secret"), "unexpected error deleting secret")
+	assert.Empty(t, secrets, "expected secret to be deleted")
+}
@@ -0,0 +1,307 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// MigrateTagsOpts captures the azureOptions that affect migrating CCO's "owned" tag to a new naming scheme
+	MigrateTagsOpts = azureOptions{}
+)
+
+// ownedTagScheme identifies the key prefix and value of CCO's "owned" tag, "<key prefix>_<name> = <value>", applied to
+// the Azure resources created by a version of ccoctl
+type ownedTagScheme struct {
+	keyPrefix string
+	value     string
+}
+
+// key returns the key of the "owned" tag applied to the Azure resources created for name
+func (s ownedTagScheme) key(name string) string {
+	return fmt.Sprintf("%s_%s", s.keyPrefix, name)
+}
+
+// matches returns true when tags include the "owned" tag for name with the value of the scheme
+func (s ownedTagScheme) matches(tags map[string]*string, name string) bool {
+	value, found := tags[s.key(name)]
+	return found && value != nil && *value == s.value
+}
+
+// migrateOwnedTag returns a copy of tags in which the "owned" tag for name of the oldScheme is replaced by the "owned"
+// tag of the newScheme. False is returned, and tags are left untouched, unless tags carry the "owned" tag of the
+// oldScheme so that only resources which were clearly created by ccoctl are migrated.
+func migrateOwnedTag(tags map[string]*string, name string, oldScheme, newScheme ownedTagScheme) (map[string]*string, bool) {
+	if !oldScheme.matches(tags, name) {
+		return tags, false
+	}
+	migratedTags := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		if key != oldScheme.key(name) {
+			migratedTags[key] = value
+		}
+	}
+	migratedTags[newScheme.key(name)] = to.Ptr(newScheme.value)
+	return migratedTags, true
+}
+
+// isThrottlingError returns true when err indicates that a call retagging an Azure resource was throttled, in which
+// case the call is retried by provisioning.TagResources
+func isThrottlingError(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusTooManyRequests
+}
+
+// validateOwnedTagSchemes validates that the old and new "owned" tag schemes are valid and differ from one another
+func validateOwnedTagSchemes(oldScheme, newScheme ownedTagScheme) error {
+	if oldScheme.keyPrefix == "" {
+		return errors.New("--old-owned-tag-key-prefix must not be empty")
+	}
+	if oldScheme.value == "" {
+		return errors.New("--old-owned-tag-value must not be empty")
+	}
+	if err := validateOwnedTagValue(newScheme.value); err != nil {
+		return err
+	}
+	if oldScheme == newScheme {
+		return fmt.Errorf("nothing to migrate, the old owned tag '%s_NAME = %s' is the same as the new owned tag",
+			oldScheme.keyPrefix, oldScheme.value)
+	}
+	return nil
+}
+
+// migrateTags replaces the "owned" tag of the oldScheme with the "owned" tag of the newScheme on the resource group
+// identified by resourceGroupName and on the storage account and user-assigned managed identities within it, so that
+// they can be found by ccoctl azure delete using the newScheme. Resources which do not carry the "owned" tag of the
+// oldScheme for name are left untouched. When dryRun is set, resources which would have been retagged are logged
+// rather than retagged.
+func migrateTags(ctx context.Context, client *azureclients.AzureClientWrapper, name, resourceGroupName, storageAccountName string, oldScheme, newScheme ownedTagScheme, dryRun bool) error {
+	migrated := 0
+
+	getResourceGroupResp, err := client.ResourceGroupsClient.Get(ctx, resourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get resource group %s", resourceGroupName)
+	}
+	if tags, ok := migrateOwnedTag(getResourceGroupResp.Tags, name, oldScheme, newScheme); !ok {
+		log.Printf("Skipping resource group %s which does not have tag key=%s, value=%s", resourceGroupName, oldScheme.key(name), oldScheme.value)
+	} else if dryRun {
+		log.Printf("Would retag resource group %s", resourceGroupName)
+		migrated++
+	} else {
+		err := provisioning.TagResource(provisioning.TagOperation{
+			ResourceType: "ResourceGroup",
+			ResourceName: resourceGroupName,
+			Tag: func() error {
+				_, err := client.ResourceGroupsClient.CreateOrUpdate(
+					ctx,
+					resourceGroupName,
+					armresources.ResourceGroup{
+						Location: getResourceGroupResp.Location,
+						Tags:     tags,
+					},
+					nil)
+				return err
+			},
+		}, isThrottlingError)
+		if err != nil {
+			return errors.Wrapf(err, "failed to retag resource group %s", resourceGroupName)
+		}
+		log.Printf("Retagged resource group %s", resourceGroupName)
+		migrated++
+	}
+
+	storageAccount, err := findStorageAccount(ctx, client, resourceGroupName, storageAccountName)
+	if err != nil {
+		return err
+	}
+	if storageAccount == nil {
+		log.Printf("Found no storage account %s within resource group %s", storageAccountName, resourceGroupName)
+	} else if tags, ok := migrateOwnedTag(storageAccount.Tags, name, oldScheme, newScheme); !ok {
+		log.Printf("Skipping storage account %s which does not have tag key=%s, value=%s", storageAccountName, oldScheme.key(name), oldScheme.value)
+	} else if dryRun {
+		log.Printf("Would retag storage account %s", storageAccountName)
+		migrated++
+	} else {
+		err := provisioning.TagResource(provisioning.TagOperation{
+			ResourceType: "StorageAccount",
+			ResourceName: storageAccountName,
+			Tag: func() error {
+				_, err := client.StorageAccountClient.Update(
+					ctx,
+					resourceGroupName,
+					storageAccountName,
+					armstorage.AccountUpdateParameters{
+						Tags: tags,
+					},
+					&armstorage.AccountsClientUpdateOptions{})
+				return err
+			},
+		}, isThrottlingError)
+		if err != nil {
+			return errors.Wrapf(err, "failed to retag storage account %s", storageAccountName)
+		}
+		log.Printf("Retagged storage account %s", storageAccountName)
+		migrated++
+	}
+
+	// User-assigned managed identities are retagged concurrently once all of them have been listed
+	retagIdentities := []provisioning.TagOperation{}
+	listManagedIdentities := client.UserAssignedIdentitiesClient.NewListByResourceGroupPager(
+		resourceGroupName,
+		&armmsi.UserAssignedIdentitiesClientListByResourceGroupOptions{},
+	)
+	for listManagedIdentities.More() {
+		pageResponse, err := listManagedIdentities.NextPage(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to list user-assigned managed identities")
+		}
+		for _, identity := range pageResponse.UserAssignedIdentitiesListResult.Value {
+			tags, ok := migrateOwnedTag(identity.Tags, name, oldScheme, newScheme)
+			if !ok {
+				continue
+			}
+			if dryRun {
+				log.Printf("Would retag user-assigned managed identity %s", *identity.Name)
+				migrated++
+				continue
+			}
+			identityName, location := *identity.Name, identity.Location
+			retagIdentities = append(retagIdentities, provisioning.TagOperation{
+				ResourceType: "UserAssignedManagedIdentity",
+				ResourceName: identityName,
+				Tag: func() error {
+					_, err := client.UserAssignedIdentitiesClient.CreateOrUpdate(
+						ctx,
+						resourceGroupName,
+						identityName,
+						armmsi.Identity{
+							Location: location,
+							Tags:     tags,
+						},
+						&armmsi.UserAssignedIdentitiesClientCreateOrUpdateOptions{},
+					)
+					if err != nil {
+						return err
+					}
+					log.Printf("Retagged user-assigned managed identity %s", identityName)
+					return nil
+				},
+			})
+		}
+	}
+	if err := provisioning.TagResources(retagIdentities, isThrottlingError); err != nil {
+		return errors.Wrap(err, "failed to retag user-assigned managed identities")
+	}
+	migrated += len(retagIdentities)
+
+	if dryRun {
+		log.Printf("Dry run complete, %d resources would have been retagged with key=%s, value=%s", migrated, newScheme.key(name), newScheme.value)
+	} else {
+		log.Printf("Retagged %d resources with key=%s, value=%s", migrated, newScheme.key(name), newScheme.value)
+	}
+	return nil
+}
+
+func migrateTagsCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	oldScheme := ownedTagScheme{keyPrefix: MigrateTagsOpts.OldOwnedTagKeyPrefix, value: MigrateTagsOpts.OldOwnedTagValue}
+	newScheme := ownedTagScheme{keyPrefix: ownedAzureResourceTagKeyPrefix, value: MigrateTagsOpts.OwnedTagValue}
+	if err := validateOwnedTagSchemes(oldScheme, newScheme); err != nil {
+		log.Fatal(err)
+	}
+
+	if MigrateTagsOpts.OIDCResourceGroupName == "" {
+		MigrateTagsOpts.OIDCResourceGroupName = MigrateTagsOpts.Name + oidcResourceGroupSuffix
+		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", MigrateTagsOpts.OIDCResourceGroupName)
+	}
+
+	if MigrateTagsOpts.StorageAccountName == "" {
+		MigrateTagsOpts.StorageAccountName = MigrateTagsOpts.Name
+		log.Printf("No --storage-account-name provided, defaulting storage account name to %s", MigrateTagsOpts.StorageAccountName)
+	}
+	if err := validateStorageAccountName(MigrateTagsOpts.StorageAccountName); err != nil {
+		log.Fatal(err)
+	}
+
+	cred, err := newAzureCredential(ctx, MigrateTagsOpts.TenantID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	azureClientWrapper, err := newAzureClientWrapper(MigrateTagsOpts.SubscriptionID, cred)
+	if err != nil {
+		log.Fatalf("Failed to create Azure client: %s", err)
+	}
+
+	err = migrateTags(
+		ctx,
+		azureClientWrapper,
+		MigrateTagsOpts.Name,
+		MigrateTagsOpts.OIDCResourceGroupName,
+		MigrateTagsOpts.StorageAccountName,
+		oldScheme,
+		newScheme,
+		MigrateTagsOpts.DryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NewMigrateTagsCmd provides the "migrate-tags" subcommand
+func NewMigrateTagsCmd() *cobra.Command {
+	migrateTagsCmd := &cobra.Command{
+		Use:   "migrate-tags --name NAME --subscription-id SUBSCRIPTION_ID",
+		Short: "Migrate the owned tag of Azure resources to a new naming scheme",
+		Long: "This command will replace the owned tag identified by --old-owned-tag-key-prefix and --old-owned-tag-value with the owned tag " +
+			"applied by this version of ccoctl for --owned-tag-value on the OIDC resource group and on the storage account and user-assigned " +
+			"managed identities within it, so that ccoctl azure delete can find resources created with a different owned tag. " +
+			"Only resources which carry the old owned tag for --name are retagged.",
+		Run: migrateTagsCmd,
+	}
+
+	// Required
+	migrateTagsCmd.PersistentFlags().StringVar(&MigrateTagsOpts.Name, "name", "", "User-defined name for all previously created Azure resources")
+	migrateTagsCmd.MarkPersistentFlagRequired("name")
+	migrateTagsCmd.PersistentFlags().StringVar(&MigrateTagsOpts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which the Azure resources were created")
+	migrateTagsCmd.MarkPersistentFlagRequired("subscription-id")
+
+	// Optional
+	migrateTagsCmd.PersistentFlags().StringVar(&MigrateTagsOpts.OIDCResourceGroupName, "oidc-resource-group-name", "", "The Azure resource group which contains the resources to retag. If not specified, the name of the OIDC resource group will be derived from the --name parameter.")
+	migrateTagsCmd.PersistentFlags().StringVar(&MigrateTagsOpts.StorageAccountName, "storage-account-name", "", "The name of the Azure storage account to retag. If not specified, the storage account name will be derived from the --name parameter.")
+	migrateTagsCmd.PersistentFlags().StringVar(
+		&MigrateTagsOpts.OldOwnedTagKeyPrefix,
+		"old-owned-tag-key-prefix",
+		ownedAzureResourceTagKeyPrefix,
+		"Prefix of the key of the owned tag, '<prefix>_NAME', currently applied to the Azure resources",
+	)
+	migrateTagsCmd.PersistentFlags().StringVar(
+		&MigrateTagsOpts.OldOwnedTagValue,
+		"old-owned-tag-value",
+		ownedAzureResourceTagValue,
+		"Value of the owned tag currently applied to the Azure resources",
+	)
+	migrateTagsCmd.PersistentFlags().StringVar(
+		&MigrateTagsOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag with which the Azure resources are retagged. ", ownedAzureResourceTagKeyPrefix)+
+			"The same value must be provided to ccoctl azure delete in order to delete the resources",
+	)
+	migrateTagsCmd.PersistentFlags().BoolVar(&MigrateTagsOpts.DryRun, "dry-run", false, "Skip retagging resources and display the resources that would have been retagged")
+	migrateTagsCmd.PersistentFlags().StringVar(&MigrateTagsOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+
+	return migrateTagsCmd
+}
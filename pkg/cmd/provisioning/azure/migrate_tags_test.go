@@ -0,0 +1,213 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestMigrateTags(t *testing.T) {
+	oldScheme := ownedTagScheme{keyPrefix: "openshift.io_cco", value: "ccoctl"}
+	newScheme := ownedTagScheme{keyPrefix: ownedAzureResourceTagKeyPrefix, value: ownedAzureResourceTagValue}
+	oldTags := map[string]*string{
+		oldScheme.key(testInfraName): to.Ptr(oldScheme.value),
+		"testTagKey":                 to.Ptr("testTagValue"),
+	}
+	newTags := map[string]*string{
+		newScheme.key(testInfraName): to.Ptr(newScheme.value),
+		"testTagKey":                 to.Ptr("testTagValue"),
+	}
+	untaggedIdentityName := "testinfraname-openshift-image-registry-installer-cloud-credentials"
+
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		dryRun                 bool
+		expectError            bool
+		expectTaggingError     bool
+	}{
+		{
+			name: "Resources with the old owned tag retagged",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+				mockCreateOrUpdateResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, newTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+				mockUpdateStorageAccountSuccess(wrapper, testOIDCResourceGroupName, testStorageAccountName, testRegionName, testSubscriptionID, newTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-openshift-ingress-operator-cloud-credentials": oldTags,
+					untaggedIdentityName: {
+						"testTagKey": to.Ptr("testTagValue"),
+					},
+				})
+				mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials", testRegionName, testSubscriptionID, newTags)
+				return wrapper
+			},
+		},
+		{
+			name: "Resources not retagged with dry run",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, oldTags)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-openshift-ingress-operator-cloud-credentials": oldTags,
+				})
+				return wrapper
+			},
+			dryRun: true,
+		},
+		{
+			name: "Resources without the old owned tag not retagged",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, newTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-openshift-ingress-operator-cloud-credentials": {
+						oldScheme.key("othername"): to.Ptr(oldScheme.value),
+					},
+					untaggedIdentityName: {
+						oldScheme.key(testInfraName): to.Ptr("othervalue"),
+					},
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "Throttled retagging of user-assigned managed identity retried",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, newTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-openshift-ingress-operator-cloud-credentials": oldTags,
+				})
+				gomock.InOrder(
+					mockCreateOrUpdateManagedIdentityFailure(wrapper, http.StatusTooManyRequests),
+					mockCreateOrUpdateManagedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-openshift-ingress-operator-cloud-credentials", testRegionName, testSubscriptionID, newTags),
+				)
+				return wrapper
+			},
+		},
+		{
+			name: "Failure to retag user-assigned managed identity reported as a tagging failure",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, newTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-openshift-ingress-operator-cloud-credentials": oldTags,
+				})
+				mockCreateOrUpdateManagedIdentityFailure(wrapper, http.StatusForbidden)
+				return wrapper
+			},
+			expectError:        true,
+			expectTaggingError: true,
+		},
+		{
+			name: "Resource group not found",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := migrateTags(context.Background(), test.mockAzureClientWrapper(mockCtrl), testInfraName, testOIDCResourceGroupName, testStorageAccountName, oldScheme, newScheme, test.dryRun)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				assert.Equal(t, test.expectTaggingError, provisioning.IsTaggingError(err), "unexpected kind of error returned")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidateOwnedTagSchemes(t *testing.T) {
+	defaultScheme := ownedTagScheme{keyPrefix: ownedAzureResourceTagKeyPrefix, value: ownedAzureResourceTagValue}
+	tests := []struct {
+		name        string
+		oldScheme   ownedTagScheme
+		newScheme   ownedTagScheme
+		expectError bool
+	}{
+		{
+			name:      "Owned tag key prefix migrated",
+			oldScheme: ownedTagScheme{keyPrefix: "openshift.io_cco", value: ownedAzureResourceTagValue},
+			newScheme: defaultScheme,
+		},
+		{
+			name:      "Owned tag value migrated",
+			oldScheme: defaultScheme,
+			newScheme: ownedTagScheme{keyPrefix: ownedAzureResourceTagKeyPrefix, value: "ccoctl-managed"},
+		},
+		{
+			name:        "Old owned tag same as new owned tag",
+			oldScheme:   defaultScheme,
+			newScheme:   defaultScheme,
+			expectError: true,
+		},
+		{
+			name:        "Empty old owned tag key prefix",
+			oldScheme:   ownedTagScheme{value: ownedAzureResourceTagValue},
+			newScheme:   defaultScheme,
+			expectError: true,
+		},
+		{
+			name:        "Empty old owned tag value",
+			oldScheme:   ownedTagScheme{keyPrefix: "openshift.io_cco"},
+			newScheme:   defaultScheme,
+			expectError: true,
+		},
+		{
+			name:        "Empty new owned tag value",
+			oldScheme:   defaultScheme,
+			newScheme:   ownedTagScheme{keyPrefix: ownedAzureResourceTagKeyPrefix},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateOwnedTagSchemes(test.oldScheme, test.newScheme)
+			if test.expectError {
+				require.Error(t, err, fmt.Sprintf("expected error migrating %+v to %+v", test.oldScheme, test.newScheme))
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func mockCreateOrUpdateManagedIdentityFailure(wrapper *azureclients.AzureClientWrapper, statusCode int) *gomock.Call {
+	return wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().CreateOrUpdate(
+		gomock.Any(), // context
+		gomock.Any(), // resource group name
+		gomock.Any(), // managed identity name
+		gomock.Any(), // parameters
+		gomock.Any(), // options
+	).Return(
+		armmsi.UserAssignedIdentitiesClientCreateOrUpdateResponse{},
+		&azcore.ResponseError{StatusCode: statusCode},
+	)
+}
@@ -0,0 +1,218 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// serviceAccountSubjectPrefix is the prefix of the subject of federated identity credentials created by ccoctl,
+	// "system:serviceaccount:<namespace>:<name>"
+	serviceAccountSubjectPrefix = "system:serviceaccount:"
+)
+
+var (
+	// PruneFederatedCredentialsOpts captures the azureOptions that affect pruning of stale federated identity credentials
+	PruneFederatedCredentialsOpts = azureOptions{}
+)
+
+// expectedFederatedCredentialSubjects returns the federated identity credential subjects expected for the provided
+// CredentialsRequests keyed by the name of the user-assigned managed identity created for each CredentialsRequest.
+func expectedFederatedCredentialSubjects(name string, credentialsRequests []*credreqv1.CredentialsRequest) (map[string]map[string]bool, error) {
+	expectedSubjects := map[string]map[string]bool{}
+	for _, credentialsRequest := range credentialsRequests {
+		identityName, err := managedIdentityName(name, credentialsRequest)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := expectedSubjects[identityName]; !ok {
+			expectedSubjects[identityName] = map[string]bool{}
+		}
+		for _, serviceAccountName := range credentialsRequest.Spec.ServiceAccountNames {
+			subject := fmt.Sprintf("%s%s:%s", serviceAccountSubjectPrefix, credentialsRequest.Spec.SecretRef.Namespace, serviceAccountName)
+			expectedSubjects[identityName][subject] = true
+		}
+	}
+	return expectedSubjects, nil
+}
+
+// attributableFederatedCredential returns true when the federated identity credential has the form of a credential
+// created by ccoctl, which names the credential after the service account identified by its subject.
+func attributableFederatedCredential(credential *armmsi.FederatedIdentityCredential) bool {
+	if credential.Name == nil || credential.Properties == nil || credential.Properties.Subject == nil {
+		return false
+	}
+	subject := *credential.Properties.Subject
+	if !strings.HasPrefix(subject, serviceAccountSubjectPrefix) {
+		return false
+	}
+	serviceAccount := strings.Split(strings.TrimPrefix(subject, serviceAccountSubjectPrefix), ":")
+	if len(serviceAccount) != 2 || serviceAccount[0] == "" || serviceAccount[1] == "" {
+		return false
+	}
+	return *credential.Name == serviceAccount[1]
+}
+
+// pruneFederatedCredentials deletes federated identity credentials on user-assigned managed identities carrying CCO's
+// "owned" tag whose subject does not map to a service account of the CredentialsRequests found within credReqDirs.
+//
+// Only federated identity credentials which can be attributed to ccoctl are considered, credentials which were not named
+// after the service account identified by their subject are left untouched. When dryRun is set, federated identity
+// credentials which would have been deleted are logged rather than deleted.
+func pruneFederatedCredentials(ctx context.Context, client *azureclients.AzureClientWrapper, credReqDirs []string, name, ownedTagValue, resourceGroupName string, enableTechPreview, dryRun bool) error {
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+	// Every federated identity credential would be pruned without any CredentialsRequests which is unlikely to be desired
+	if len(credentialsRequests) == 0 {
+		return fmt.Errorf("refusing to prune federated identity credentials, found no CredentialsRequests within %s", credReqDirs)
+	}
+	expectedSubjects, err := expectedFederatedCredentialSubjects(name, credentialsRequests)
+	if err != nil {
+		return err
+	}
+
+	managedIdentities, err := listOwnedManagedIdentities(ctx, client, name, ownedTagValue, "", resourceGroupName)
+	if err != nil {
+		return errors.Wrap(err, "failed to list user-assigned managed identities")
+	}
+
+	pruned := 0
+	for _, identity := range managedIdentities {
+		listFederatedCredentials := client.FederatedIdentityCredentialsClient.NewListPager(
+			resourceGroupName,
+			*identity.Name,
+			&armmsi.FederatedIdentityCredentialsClientListOptions{},
+		)
+		for listFederatedCredentials.More() {
+			pageResponse, err := listFederatedCredentials.NextPage(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list federated identity credentials of user-assigned managed identity %s", *identity.Name)
+			}
+			for _, credential := range pageResponse.FederatedIdentityCredentialsListResult.Value {
+				if !attributableFederatedCredential(credential) {
+					log.Printf("Skipping federated identity credential %s of user-assigned managed identity %s which was not created by ccoctl", stringValue(credential.Name), *identity.Name)
+					continue
+				}
+				subject := *credential.Properties.Subject
+				if expectedSubjects[*identity.Name][subject] {
+					continue
+				}
+				pruned++
+				if dryRun {
+					log.Printf("Would delete federated identity credential %s of user-assigned managed identity %s with subject %s", *credential.Name, *identity.Name, subject)
+					continue
+				}
+				_, err := client.FederatedIdentityCredentialsClient.Delete(
+					ctx,
+					resourceGroupName,
+					*identity.Name,
+					*credential.Name,
+					&armmsi.FederatedIdentityCredentialsClientDeleteOptions{},
+				)
+				if err != nil {
+					return errors.Wrapf(err, "failed to delete federated identity credential %s of user-assigned managed identity %s", *credential.Name, *identity.Name)
+				}
+				log.Printf("Deleted federated identity credential %s of user-assigned managed identity %s with subject %s", *credential.Name, *identity.Name, subject)
+				provisioning.EmitResourceEvent("FederatedIdentityCredential", stringValue(credential.ID), provisioning.ResourceDeleted)
+			}
+		}
+	}
+
+	if pruned == 0 {
+		log.Print("Found no stale federated identity credentials")
+	} else if dryRun {
+		log.Printf("Dry run complete, %d federated identity credentials would have been deleted", pruned)
+	}
+	return nil
+}
+
+// stringValue returns the string pointed to by s or an empty string when s is nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func pruneFederatedCredentialsCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	if err := validateOwnedTagValue(PruneFederatedCredentialsOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+
+	cred, err := newAzureCredential(ctx, PruneFederatedCredentialsOpts.TenantID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	azureClientWrapper, err := newAzureClientWrapper(PruneFederatedCredentialsOpts.SubscriptionID, cred)
+	if err != nil {
+		log.Fatalf("Failed to create Azure client: %s", err)
+	}
+
+	if PruneFederatedCredentialsOpts.OIDCResourceGroupName == "" {
+		PruneFederatedCredentialsOpts.OIDCResourceGroupName = PruneFederatedCredentialsOpts.Name + oidcResourceGroupSuffix
+		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", PruneFederatedCredentialsOpts.OIDCResourceGroupName)
+	}
+
+	err = pruneFederatedCredentials(
+		ctx,
+		azureClientWrapper,
+		PruneFederatedCredentialsOpts.CredRequestDirs,
+		PruneFederatedCredentialsOpts.Name,
+		PruneFederatedCredentialsOpts.OwnedTagValue,
+		PruneFederatedCredentialsOpts.OIDCResourceGroupName,
+		PruneFederatedCredentialsOpts.EnableTechPreview,
+		PruneFederatedCredentialsOpts.DryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NewPruneFederatedCredentialsCmd provides the "prune-federated-credentials" subcommand
+func NewPruneFederatedCredentialsCmd() *cobra.Command {
+	pruneFederatedCredentialsCmd := &cobra.Command{
+		Use:   "prune-federated-credentials --name NAME --credentials-requests-dir CRED_REQ_DIR --subscription-id SUBSCRIPTION_ID",
+		Short: "Delete stale federated identity credentials",
+		Long: "This command will delete federated identity credentials of user-assigned managed identities created by ccoctl " +
+			"whose service account subject does not belong to a CredentialsRequest within --credentials-requests-dir. " +
+			"Only user-assigned managed identities within the OIDC resource group that carry the owned tag for --name are considered " +
+			"and federated identity credentials which were not created by ccoctl are never deleted.",
+		Run: pruneFederatedCredentialsCmd,
+	}
+
+	// Required
+	pruneFederatedCredentialsCmd.PersistentFlags().StringVar(&PruneFederatedCredentialsOpts.Name, "name", "", "User-defined name for all previously created Azure resources")
+	pruneFederatedCredentialsCmd.MarkPersistentFlagRequired("name")
+	pruneFederatedCredentialsCmd.PersistentFlags().StringSliceVar(&PruneFederatedCredentialsOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing the current set of Azure CredentialsRequests files (can be created by running 'oc adm release extract --credentials-requests --cloud=azure' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
+	pruneFederatedCredentialsCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	pruneFederatedCredentialsCmd.PersistentFlags().StringVar(&PruneFederatedCredentialsOpts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which the user-assigned managed identities were created")
+	pruneFederatedCredentialsCmd.MarkPersistentFlagRequired("subscription-id")
+
+	// Optional
+	pruneFederatedCredentialsCmd.PersistentFlags().StringVar(&PruneFederatedCredentialsOpts.OIDCResourceGroupName, "oidc-resource-group-name", "", "The Azure resource group which contains the user-assigned managed identities. If not specified, the name of the OIDC resource group will be derived from the --name parameter.")
+	pruneFederatedCredentialsCmd.PersistentFlags().BoolVar(&PruneFederatedCredentialsOpts.DryRun, "dry-run", false, "Skip deleting federated identity credentials and display the federated identity credentials that would have been deleted")
+	pruneFederatedCredentialsCmd.PersistentFlags().BoolVar(&PruneFederatedCredentialsOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests annotated with TechPreviewNoUpgrade")
+	pruneFederatedCredentialsCmd.PersistentFlags().StringVar(
+		&PruneFederatedCredentialsOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag identifying the user-assigned managed identities created by ccoctl. ", ownedAzureResourceTagKeyPrefix)+
+			"Must match the --owned-tag-value with which the user-assigned managed identities were created",
+	)
+	pruneFederatedCredentialsCmd.PersistentFlags().StringVar(&PruneFederatedCredentialsOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+
+	return pruneFederatedCredentialsCmd
+}
@@ -0,0 +1,237 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+)
+
+func TestPruneFederatedCredentials(t *testing.T) {
+	// testCredentialsRequest swaps the target secret namespace and name so the CredentialsRequest below targets
+	// secret "namespace1" within namespace "secretName1"
+	testIdentityName := "testinfraname-secretName1-namespace1"
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+	}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		noCredentialsRequests  bool
+		dryRun                 bool
+		expectError            bool
+	}{
+		{
+			name: "Current federated identity credentials not pruned",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testIdentityName: ownedTags,
+				})
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, testIdentityName, map[string]string{
+					"testServiceAccount1": "system:serviceaccount:secretName1:testServiceAccount1",
+					"testServiceAccount2": "system:serviceaccount:secretName1:testServiceAccount2",
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "Stale federated identity credentials pruned",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testIdentityName:                  ownedTags,
+					"testinfraname-removed-component": ownedTags,
+				})
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, testIdentityName, map[string]string{
+					"testServiceAccount1":       "system:serviceaccount:secretName1:testServiceAccount1",
+					"testServiceAccount2":       "system:serviceaccount:secretName1:testServiceAccount2",
+					"removedServiceAccount":     "system:serviceaccount:secretName1:removedServiceAccount",
+					"userCreatedCredentialName": "system:serviceaccount:secretName1:userServiceAccount",
+					"externalSubject":           "repo:openshift/cloud-credential-operator:ref:refs/heads/master",
+				})
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, "testinfraname-removed-component", map[string]string{
+					"removedServiceAccount": "system:serviceaccount:removed:removedServiceAccount",
+				})
+				mockDeleteFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, testIdentityName, "removedServiceAccount")
+				mockDeleteFederatedIdentityCredentialSuccess(wrapper, testOIDCResourceGroupName, "testinfraname-removed-component", "removedServiceAccount")
+				return wrapper
+			},
+		},
+		{
+			name: "Stale federated identity credentials not deleted with dry run",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					testIdentityName: ownedTags,
+				})
+				mockFederatedIdentityCredentialsListPager(wrapper, testOIDCResourceGroupName, testIdentityName, map[string]string{
+					"removedServiceAccount": "system:serviceaccount:secretName1:removedServiceAccount",
+				})
+				return wrapper
+			},
+			dryRun: true,
+		},
+		{
+			name: "Federated identity credentials of identities not owned are not listed",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"otherinfraname-secretName1-namespace1": {
+						fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, "otherinfraname"): to.Ptr(ownedAzureResourceTagValue),
+					},
+					"untagged-identity": {},
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "No CredentialsRequests",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				return mockAzureClientWrapper(mockCtrl)
+			},
+			noCredentialsRequests: true,
+			expectError:           true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+
+			credReqDir, err := os.MkdirTemp(os.TempDir(), "prunecredreqdir")
+			require.NoError(t, err, "failed to create temp directory")
+			defer os.RemoveAll(credReqDir)
+			if !test.noCredentialsRequests {
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+				require.NoError(t, err, "errored while setting up environment for test")
+			}
+
+			err = pruneFederatedCredentials(context.Background(), mockAzureClientWrapper, []string{credReqDir}, testInfraName, ownedAzureResourceTagValue, testOIDCResourceGroupName, false, test.dryRun)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestAttributableFederatedCredential(t *testing.T) {
+	tests := []struct {
+		name            string
+		credentialName  string
+		subject         string
+		expectAttribute bool
+	}{
+		{
+			name:            "Credential named after its service account",
+			credentialName:  "testServiceAccount1",
+			subject:         "system:serviceaccount:namespace1:testServiceAccount1",
+			expectAttribute: true,
+		},
+		{
+			name:           "Credential not named after its service account",
+			credentialName: "customName",
+			subject:        "system:serviceaccount:namespace1:testServiceAccount1",
+		},
+		{
+			name:           "Credential with a subject which is not a service account",
+			credentialName: "testServiceAccount1",
+			subject:        "repo:org/testServiceAccount1",
+		},
+		{
+			name:           "Credential with a malformed service account subject",
+			credentialName: "testServiceAccount1",
+			subject:        "system:serviceaccount:testServiceAccount1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			credential := &armmsi.FederatedIdentityCredential{
+				Name: to.Ptr(test.credentialName),
+				Properties: &armmsi.FederatedIdentityCredentialProperties{
+					Subject: to.Ptr(test.subject),
+				},
+			}
+			require.Equal(t, test.expectAttribute, attributableFederatedCredential(credential))
+		})
+	}
+}
+
+func mockUserAssignedIdentitiesListByResourceGroupPager(wrapper *azureclients.AzureClientWrapper, resourceGroupName string, identityTags map[string]map[string]*string) {
+	userAssignedIdentitiesListResult := armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse{
+		UserAssignedIdentitiesListResult: armmsi.UserAssignedIdentitiesListResult{
+			Value: []*armmsi.Identity{},
+		},
+	}
+	for identityName, tags := range identityTags {
+		userAssignedIdentitiesListResult.Value = append(userAssignedIdentitiesListResult.Value, &armmsi.Identity{
+			ID:       to.Ptr(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s", testSubscriptionID, resourceGroupName, identityName)),
+			Name:     to.Ptr(identityName),
+			Location: to.Ptr(testRegionName),
+			Tags:     tags,
+			Type:     to.Ptr("Microsoft.ManagedIdentity/userAssignedIdentities"),
+		})
+	}
+	wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().NewListByResourceGroupPager(resourceGroupName, gomock.Any()).Return(
+		runtime.NewPager(runtime.PagingHandler[armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse]{
+			More: func(current armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse) bool {
+				return current.NextLink != nil
+			},
+			Fetcher: func(ctx context.Context, current *armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse) (armmsi.UserAssignedIdentitiesClientListByResourceGroupResponse, error) {
+				return userAssignedIdentitiesListResult, nil
+			},
+		}),
+	)
+}
+
+func mockFederatedIdentityCredentialsListPager(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName string, credentialSubjects map[string]string) {
+	federatedIdentityCredentialsListResult := armmsi.FederatedIdentityCredentialsClientListResponse{
+		FederatedIdentityCredentialsListResult: armmsi.FederatedIdentityCredentialsListResult{
+			Value: []*armmsi.FederatedIdentityCredential{},
+		},
+	}
+	for credentialName, subject := range credentialSubjects {
+		federatedIdentityCredentialsListResult.Value = append(federatedIdentityCredentialsListResult.Value, &armmsi.FederatedIdentityCredential{
+			Name: to.Ptr(credentialName),
+			Properties: &armmsi.FederatedIdentityCredentialProperties{
+				Audiences: []*string{to.Ptr("openshift")},
+				Issuer:    to.Ptr(testIssuerURL),
+				Subject:   to.Ptr(subject),
+			},
+		})
+	}
+	wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().NewListPager(resourceGroupName, managedIdentityName, gomock.Any()).Return(
+		runtime.NewPager(runtime.PagingHandler[armmsi.FederatedIdentityCredentialsClientListResponse]{
+			More: func(current armmsi.FederatedIdentityCredentialsClientListResponse) bool {
+				return current.NextLink != nil
+			},
+			Fetcher: func(ctx context.Context, current *armmsi.FederatedIdentityCredentialsClientListResponse) (armmsi.FederatedIdentityCredentialsClientListResponse, error) {
+				return federatedIdentityCredentialsListResult, nil
+			},
+		}),
+	)
+}
+
+func mockDeleteFederatedIdentityCredentialSuccess(wrapper *azureclients.AzureClientWrapper, resourceGroupName, managedIdentityName, federatedIdentityCredentialName string) {
+	wrapper.FederatedIdentityCredentialsClient.(*mockazure.MockFederatedIdentityCredentialsClient).EXPECT().Delete(
+		gomock.Any(), // context
+		resourceGroupName,
+		managedIdentityName,
+		federatedIdentityCredentialName,
+		gomock.Any(), // options
+	).Return(
+		armmsi.FederatedIdentityCredentialsClientDeleteResponse{},
+		nil, // no error
+	)
+}
@@ -0,0 +1,193 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// ReconcileDeleteOpts captures the azureOptions that affect deleting the resources of decommissioned components
+	ReconcileDeleteOpts = azureOptions{}
+)
+
+// reconcileDelete deletes the user-assigned managed identities carrying CCO's "owned" tag for name, and the cluster
+// ownership tag when clusterID is provided, which were not created for any of the CredentialsRequests found within
+// credReqDirs. The user-assigned managed identities of the current CredentialsRequests are left untouched. When dryRun
+// is set, user-assigned managed identities which would have been deleted are logged rather than deleted. Otherwise the
+// deletion of more than confirmThreshold user-assigned managed identities must be confirmed unless yes is set.
+func reconcileDelete(ctx context.Context, client *azureclients.AzureClientWrapper, credReqDirs []string, name, ownedTagValue, clusterID, resourceGroupName string, enableTechPreview, dryRun bool, confirmThreshold int, yes bool) error {
+	credentialsRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
+	}
+	// Every user-assigned managed identity would be deleted without any CredentialsRequests, which is a full teardown
+	// better done with ccoctl azure delete
+	if len(credentialsRequests) == 0 {
+		return fmt.Errorf("refusing to delete user-assigned managed identities, found no CredentialsRequests within %s", credReqDirs)
+	}
+	// User-assigned managed identities are matched to the current CredentialsRequests by the tag of their component,
+	// or by their name when they were created without the tag
+	currentIdentityNames := map[string]bool{}
+	currentComponents := map[string]bool{}
+	for _, credentialsRequest := range credentialsRequests {
+		identityName, err := managedIdentityName(name, credentialsRequest)
+		if err != nil {
+			return err
+		}
+		currentIdentityNames[identityName] = true
+		currentComponents[provisioning.CredentialsRequestComponentTag(credentialsRequest)] = true
+	}
+
+	managedIdentities, err := listOwnedManagedIdentities(ctx, client, name, ownedTagValue, clusterID, resourceGroupName)
+	if err != nil {
+		return errors.Wrap(err, "failed to list user-assigned managed identities")
+	}
+
+	candidates := []*armmsi.Identity{}
+	for _, identity := range managedIdentities {
+		if component, tagged := identity.Tags[componentAzureResourceTagKey]; tagged && component != nil && currentComponents[*component] {
+			continue
+		}
+		if currentIdentityNames[*identity.Name] {
+			continue
+		}
+		candidates = append(candidates, identity)
+	}
+
+	if len(candidates) == 0 {
+		log.Print("Found no user-assigned managed identities which do not correspond to a CredentialsRequest")
+		return nil
+	}
+	// None of the owned user-assigned managed identities corresponding to the current CredentialsRequests is most
+	// likely a sign of identities named differently than at create time, eg. with another --name-template, rather
+	// than of every component having been decommissioned
+	if len(candidates) == len(managedIdentities) {
+		return fmt.Errorf("refusing to delete every one of the %d user-assigned managed identities owned by %s, none of which corresponds to the CredentialsRequests within %s. "+
+			"Check that --name and --name-template are those the identities were created with, or delete every identity with ccoctl azure delete",
+			len(candidates), name, credReqDirs)
+	}
+
+	if dryRun {
+		for _, identity := range candidates {
+			log.Printf("Would delete user-assigned managed identity %s which does not correspond to any CredentialsRequest", *identity.Name)
+		}
+		log.Printf("Dry run complete, %d user-assigned managed identities would have been deleted", len(candidates))
+		return nil
+	}
+	summary, _ := provisioning.DiscoverDeletions(func() error {
+		for range candidates {
+			provisioning.RecordDeletionCandidate("UserAssignedManagedIdentity")
+		}
+		return nil
+	})
+	if err := provisioning.ConfirmDeletion(summary, confirmThreshold, yes); err != nil {
+		return err
+	}
+	for _, identity := range candidates {
+		_, err := client.UserAssignedIdentitiesClient.Delete(
+			ctx,
+			resourceGroupName,
+			*identity.Name,
+			&armmsi.UserAssignedIdentitiesClientDeleteOptions{},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete user-assigned managed identity %s", *identity.Name)
+		}
+		log.Printf("Deleted user-assigned managed identity %s which does not correspond to any CredentialsRequest", *identity.Name)
+		provisioning.EmitResourceEvent("UserAssignedManagedIdentity", stringValue(identity.ID), provisioning.ResourceDeleted)
+	}
+	log.Printf("Deleted %d user-assigned managed identities", len(candidates))
+	return nil
+}
+
+func reconcileDeleteCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	if err := validateOwnedTagValue(ReconcileDeleteOpts.OwnedTagValue); err != nil {
+		log.Fatal(err)
+	}
+	if err := provisioning.ValidateClusterID(ReconcileDeleteOpts.ClusterID); err != nil {
+		log.Fatal(err)
+	}
+	if err := provisioning.ValidateConfirmThreshold(ReconcileDeleteOpts.ConfirmThreshold); err != nil {
+		log.Fatal(err)
+	}
+
+	cred, err := newAzureCredential(ctx, ReconcileDeleteOpts.TenantID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	azureClientWrapper, err := newAzureClientWrapper(ReconcileDeleteOpts.SubscriptionID, cred)
+	if err != nil {
+		log.Fatalf("Failed to create Azure client: %s", err)
+	}
+
+	if ReconcileDeleteOpts.OIDCResourceGroupName == "" {
+		ReconcileDeleteOpts.OIDCResourceGroupName = ReconcileDeleteOpts.Name + oidcResourceGroupSuffix
+		log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", ReconcileDeleteOpts.OIDCResourceGroupName)
+	}
+
+	err = reconcileDelete(
+		ctx,
+		azureClientWrapper,
+		ReconcileDeleteOpts.CredRequestDirs,
+		ReconcileDeleteOpts.Name,
+		ReconcileDeleteOpts.OwnedTagValue,
+		ReconcileDeleteOpts.ClusterID,
+		ReconcileDeleteOpts.OIDCResourceGroupName,
+		ReconcileDeleteOpts.EnableTechPreview,
+		ReconcileDeleteOpts.DryRun,
+		ReconcileDeleteOpts.ConfirmThreshold,
+		ReconcileDeleteOpts.Yes)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// NewReconcileDeleteCmd provides the "reconcile-delete" subcommand
+func NewReconcileDeleteCmd() *cobra.Command {
+	reconcileDeleteCmd := &cobra.Command{
+		Use:   "reconcile-delete --name NAME --credentials-requests-dir CRED_REQ_DIR --subscription-id SUBSCRIPTION_ID",
+		Short: "Delete the user-assigned managed identities of components without a CredentialsRequest",
+		Long: "This command will delete the user-assigned managed identities created by ccoctl which do not correspond to any " +
+			"CredentialsRequest within --credentials-requests-dir, eg. once a component has been decommissioned. " +
+			"Only user-assigned managed identities within the OIDC resource group that carry the owned tag for --name are considered, " +
+			"the user-assigned managed identities of the current CredentialsRequests and the OIDC issuer are left intact. " +
+			"Nothing is deleted when none of the owned user-assigned managed identities corresponds to a current CredentialsRequest.",
+		Run: reconcileDeleteCmd,
+	}
+
+	// Required
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.Name, "name", "", "User-defined name for all previously created Azure resources")
+	reconcileDeleteCmd.MarkPersistentFlagRequired("name")
+	reconcileDeleteCmd.PersistentFlags().StringSliceVar(&ReconcileDeleteOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing the current set of Azure CredentialsRequests files (can be created by running 'oc adm release extract --credentials-requests --cloud=azure' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
+	reconcileDeleteCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which the user-assigned managed identities were created")
+	reconcileDeleteCmd.MarkPersistentFlagRequired("subscription-id")
+
+	// Optional
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.OIDCResourceGroupName, "oidc-resource-group-name", "", "The Azure resource group which contains the user-assigned managed identities. If not specified, the name of the OIDC resource group will be derived from the --name parameter.")
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.ClusterID, "cluster-id", "", "Only delete user-assigned managed identities which are tagged with 'kubernetes.io_cluster.<cluster ID> = owned' by ccoctl when the resources were created with --cluster-id")
+	reconcileDeleteCmd.PersistentFlags().BoolVar(&ReconcileDeleteOpts.DryRun, "dry-run", false, "Skip deleting user-assigned managed identities and display the user-assigned managed identities that would have been deleted")
+	reconcileDeleteCmd.PersistentFlags().IntVar(&ReconcileDeleteOpts.ConfirmThreshold, "confirm-threshold", provisioning.DefaultConfirmThreshold, "Number of user-assigned managed identities to delete beyond which deletion must be confirmed on the terminal, or with --yes")
+	reconcileDeleteCmd.PersistentFlags().BoolVar(&ReconcileDeleteOpts.Yes, "yes", false, "Delete the user-assigned managed identities which do not correspond to any CredentialsRequest without asking for confirmation, however many there are")
+	reconcileDeleteCmd.PersistentFlags().BoolVar(&ReconcileDeleteOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests annotated with TechPreviewNoUpgrade")
+	reconcileDeleteCmd.PersistentFlags().StringVar(
+		&ReconcileDeleteOpts.OwnedTagValue,
+		"owned-tag-value",
+		ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag identifying the user-assigned managed identities created by ccoctl. ", ownedAzureResourceTagKeyPrefix)+
+			"Must match the --owned-tag-value with which the user-assigned managed identities were created",
+	)
+	reconcileDeleteCmd.PersistentFlags().StringVar(&ReconcileDeleteOpts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+
+	return reconcileDeleteCmd
+}
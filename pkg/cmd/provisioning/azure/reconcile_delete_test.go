@@ -0,0 +1,179 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestReconcileDelete(t *testing.T) {
+	// testCredentialsRequest swaps the target secret namespace and name so the CredentialsRequest below targets
+	// secret "namespace1" within namespace "secretName1"
+	currentIdentityName := "testinfraname-secretName1-namespace1"
+	ownedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+	}
+	clusterOwnedTags := map[string]*string{
+		fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr(ownedAzureResourceTagValue),
+		clusterResourceTagKey(testClusterID):                                to.Ptr(provisioning.ClusterResourceTagValue),
+	}
+	tests := []struct {
+		name                   string
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		noCredentialsRequests  bool
+		nameTemplates          []string
+		clusterID              string
+		dryRun                 bool
+		confirmThreshold       int
+		expectError            string
+	}{
+		{
+			name: "Nothing to prune",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					currentIdentityName: ownedTags,
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "Several identities of decommissioned components pruned",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					currentIdentityName:                ownedTags,
+					"testinfraname-removed-component1": ownedTags,
+					"testinfraname-removed-component2": ownedTags,
+				})
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-removed-component1")
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-removed-component2")
+				return wrapper
+			},
+		},
+		{
+			name: "Identities of decommissioned components not deleted with dry run",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					currentIdentityName:                ownedTags,
+					"testinfraname-removed-component1": ownedTags,
+				})
+				return wrapper
+			},
+			dryRun: true,
+		},
+		{
+			name: "Identities not owned are not deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"otherinfraname-removed-component": {
+						fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, "otherinfraname"): to.Ptr(ownedAzureResourceTagValue),
+					},
+					"testinfraname-other-owned-tag-value": {
+						fmt.Sprintf("%s_%s", ownedAzureResourceTagKeyPrefix, testInfraName): to.Ptr("othervalue"),
+					},
+					"untagged-identity": {},
+				})
+				return wrapper
+			},
+		},
+		{
+			name: "Only identities of the cluster pruned with cluster ID",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					currentIdentityName:                clusterOwnedTags,
+					"testinfraname-removed-component1": clusterOwnedTags,
+					"testinfraname-removed-component2": ownedTags,
+				})
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, "testinfraname-removed-component1")
+				return wrapper
+			},
+			clusterID: testClusterID,
+		},
+		{
+			name: "No CredentialsRequests",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				return mockAzureClientWrapper(mockCtrl)
+			},
+			noCredentialsRequests: true,
+			expectError:           "found no CredentialsRequests",
+		},
+		{
+			name: "Identities named with another name template not deleted",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					currentIdentityName:                ownedTags,
+					"testinfraname-removed-component1": ownedTags,
+				})
+				return wrapper
+			},
+			nameTemplates: []string{provisioning.NameTemplateAzureManagedIdentity + "=custom-{{.Component}}"},
+			expectError:   "refusing to delete every one of the 2 user-assigned managed identities owned by " + testInfraName,
+		},
+		{
+			name: "Every identity not deleted with dry run",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					"testinfraname-removed-component1": ownedTags,
+				})
+				return wrapper
+			},
+			dryRun:      true,
+			expectError: "refusing to delete every one of the 1 user-assigned managed identities",
+		},
+		{
+			name: "Identities beyond the confirm threshold not deleted without confirmation",
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					currentIdentityName:                ownedTags,
+					"testinfraname-removed-component1": ownedTags,
+					"testinfraname-removed-component2": ownedTags,
+				})
+				return wrapper
+			},
+			confirmThreshold: 1,
+			expectError:      "more than the --confirm-threshold of 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockAzureClientWrapper := test.mockAzureClientWrapper(mockCtrl)
+			require.NoError(t, provisioning.InitNameTemplates(test.nameTemplates), "failed to parse name templates")
+			defer provisioning.InitNameTemplates(nil)
+			confirmThreshold := test.confirmThreshold
+			if confirmThreshold == 0 {
+				confirmThreshold = provisioning.DefaultConfirmThreshold
+			}
+
+			credReqDir, err := os.MkdirTemp(os.TempDir(), "reconciledeletecredreqdir")
+			require.NoError(t, err, "failed to create temp directory")
+			defer os.RemoveAll(credReqDir)
+			if !test.noCredentialsRequests {
+				err = testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir, false)
+				require.NoError(t, err, "errored while setting up environment for test")
+			}
+
+			err = reconcileDelete(context.Background(), mockAzureClientWrapper, []string{credReqDir}, testInfraName, ownedAzureResourceTagValue, test.clusterID, testOIDCResourceGroupName, false, test.dryRun, confirmThreshold, false)
+			if test.expectError != "" {
+				require.ErrorContains(t, err, test.expectError, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// RotateSigningKeyOpts captures the azureOptions that affect the rotation of the service account signing key
+	RotateSigningKeyOpts = azureOptions{}
+	// FinalizeRotationOpts captures the azureOptions that affect the removal of the previous service account signing key
+	FinalizeRotationOpts = azureOptions{}
+)
+
+// publishJSONWebKeySet saves the JSON web key set jwks within the output directory and, unless doing a dry run,
+// uploads it to the blob container of the OIDC issuer described by opts, replacing the published JSON web key set
+func publishJSONWebKeySet(ctx context.Context, client *azureclients.AzureClientWrapper, jwks []byte, opts azureOptions) error {
+	issuerURLPathPrefix, err := normalizeIssuerURLPathPrefix(opts.IssuerURLPathPrefix)
+	if err != nil {
+		return err
+	}
+	_, _, jwksBlobName, err := oidcDocumentLocations(opts.StorageAccountName, opts.BlobContainerName, issuerURLPathPrefix)
+	if err != nil {
+		return err
+	}
+
+	jwksFullPath := filepath.Join(opts.OutputDir, jwksFileName)
+	if err := os.WriteFile(jwksFullPath, jwks, fs.FileMode(fileMode)); err != nil {
+		return err
+	}
+	log.Printf("Saved JSON web key set at path %s", jwksFullPath)
+
+	if opts.DryRun {
+		return nil
+	}
+
+	storageAccountKey, err := getStorageAccountKey(ctx, client, opts.StorageAccountName, opts.OIDCResourceGroupName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the key of storage account %s", opts.StorageAccountName)
+	}
+	blobContainerURL := fmt.Sprintf("https://%s.blob.core.windows.net/%s", opts.StorageAccountName, opts.BlobContainerName)
+	if err := ensureBlobSharedKeyClient(client, opts.StorageAccountName, storageAccountKey, blobContainerURL); err != nil {
+		return err
+	}
+
+	// The tags of the blob are replaced along with its content
+	resourceTags := map[string]string{ownedResourceTagKey(opts.Name): opts.OwnedTagValue}
+	for key, value := range opts.UserTags {
+		resourceTags[key] = value
+	}
+	_, err = client.BlobSharedKeyClient.UploadBuffer(
+		ctx,
+		"",
+		jwksBlobName,
+		jwks,
+		&azblob.UploadBufferOptions{
+			Tags: resourceTags,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	log.Printf("Uploaded JSON web key set %s", blobContainerURL+"/"+jwksBlobName)
+	provisioning.EmitResourceEvent("Blob", blobContainerURL+"/"+jwksBlobName, provisioning.ResourceUpdated)
+	return nil
+}
+
+// rotateSigningKey generates a new signing key pair within the output directory and publishes the JSON web key set
+// containing both the previous and the new public keys to the blob container of the OIDC issuer
+func rotateSigningKey(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions) error {
+	jwks, err := provisioning.RotateSigningKey(opts.OutputDir, opts.PreviousPublicKeyPath)
+	if err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(ctx, client, jwks, opts)
+}
+
+// finalizeRotation publishes the JSON web key set containing only the new public key to the blob container of the
+// OIDC issuer, removing the previous public key published by rotateSigningKey
+func finalizeRotation(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions) error {
+	publicKeyPath := opts.PublicKeyPath
+	if publicKeyPath == "" {
+		publicKeyPath = filepath.Join(opts.OutputDir, provisioning.PublicKeyFile)
+	}
+	jwks, err := provisioning.FinalizeRotation(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(ctx, client, jwks, opts)
+}
+
+// newRotationClient returns a client for the subscription within opts
+func newRotationClient(ctx context.Context, opts azureOptions) *azureclients.AzureClientWrapper {
+	cred, err := newAzureCredential(ctx, opts.TenantID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	azureClientWrapper, err := newAzureClientWrapper(opts.SubscriptionID, cred)
+	if err != nil {
+		log.Fatalf("Failed to create Azure client: %s", err)
+	}
+	return azureClientWrapper
+}
+
+func rotateSigningKeyCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	if err := rotateSigningKey(ctx, newRotationClient(ctx, RotateSigningKeyOpts), RotateSigningKeyOpts); err != nil {
+		log.Fatalf("Failed to rotate the signing key: %s", err)
+	}
+}
+
+func finalizeRotationCmd(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	if err := finalizeRotation(ctx, newRotationClient(ctx, FinalizeRotationOpts), FinalizeRotationOpts); err != nil {
+		log.Fatalf("Failed to finalize the signing key rotation: %s", err)
+	}
+}
+
+// initEnvForRotationCmd defaults the OIDC issuer names within opts from --name, validates the tags and ensures the
+// output directory is ready to receive the generated files
+func initEnvForRotationCmd(opts *azureOptions) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := validateOwnedTagValue(opts.OwnedTagValue); err != nil {
+			log.Fatal(err)
+		}
+		if err := addClusterResourceTag(opts); err != nil {
+			log.Fatal(err)
+		}
+
+		if opts.OIDCResourceGroupName == "" {
+			opts.OIDCResourceGroupName = opts.Name + oidcResourceGroupSuffix
+			log.Printf("No --oidc-resource-group-name provided, defaulting OIDC resource group name to %s", opts.OIDCResourceGroupName)
+		}
+		if opts.StorageAccountName == "" {
+			opts.StorageAccountName = opts.Name
+			log.Printf("No --storage-account-name provided, defaulting storage account name to %s", opts.StorageAccountName)
+		}
+		if opts.BlobContainerName == "" {
+			opts.BlobContainerName = opts.Name
+			log.Printf("No --blob-container-name provided, defaulting blob container name to %s", opts.BlobContainerName)
+		}
+		if err := validateBlobContainerName(opts.BlobContainerName); err != nil {
+			log.Fatal(err)
+		}
+
+		outputDir, err := provisioning.InitRotationOutputDir(opts.OutputDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.OutputDir = outputDir
+	}
+}
+
+// addRotationFlags adds the flags shared by the signing key rotation subcommands to cmd
+func addRotationFlags(cmd *cobra.Command, opts *azureOptions) {
+	cmd.PersistentFlags().StringVar(&opts.Name, "name", "", "User-defined name for all created Azure resources. This user-defined name can be separate from the cluster's infra-id.")
+	cmd.MarkPersistentFlagRequired("name")
+	cmd.PersistentFlags().StringVar(&opts.SubscriptionID, "subscription-id", "", "Azure Subscription ID within which the OIDC issuer was created")
+	cmd.MarkPersistentFlagRequired("subscription-id")
+	cmd.PersistentFlags().StringVar(&opts.TenantID, "tenant-id", "", "Azure Tenant ID for which tokens are requested. If not specified, the tenant is inferred from the environment, eg. the tenant of the Azure CLI login")
+	cmd.PersistentFlags().StringVar(&opts.OIDCResourceGroupName, "oidc-resource-group-name", "", "The Azure resource group containing the storage account of the OIDC issuer. Defaults to a name derived from the --name parameter")
+	cmd.PersistentFlags().StringVar(&opts.StorageAccountName, "storage-account-name", "", "The name of the storage account hosting the OIDC issuer. Defaults to the --name parameter")
+	cmd.PersistentFlags().StringVar(&opts.BlobContainerName, "blob-container-name", "", "The name of the blob container hosting the OIDC issuer. Defaults to the --name parameter. May also be provided as --"+oidcContainerNameFlag)
+	cmd.SetGlobalNormalizationFunc(normalizeOIDCContainerNameFlag)
+	cmd.PersistentFlags().StringVar(&opts.IssuerURLPathPrefix, "issuer-url-path-prefix", "", "Path within the blob container beneath which the OIDC documents were uploaded, must match the --issuer-url-path-prefix with which the OIDC issuer was created")
+	cmd.PersistentFlags().StringToStringVar(&opts.UserTags, "user-tags", map[string]string{}, "User tags to be applied to the JSON web key set blob, multiple tags may be specified comma-separated for example: --user-tags key1=value1,key2=value2")
+	cmd.PersistentFlags().StringVar(&opts.OwnedTagValue, "owned-tag-value", ownedAzureResourceTagValue,
+		fmt.Sprintf("Value of the '%s_NAME' tag applied to the JSON web key set blob, must match the --owned-tag-value with which the OIDC issuer was created", ownedAzureResourceTagKeyPrefix))
+	cmd.PersistentFlags().StringVar(&opts.ClusterID, "cluster-id", "", "ID of the OpenShift cluster with which to tag the JSON web key set blob, the blob will be tagged with 'kubernetes.io_cluster.<cluster ID> = owned'")
+	cmd.PersistentFlags().BoolVar(&opts.DryRun, "dry-run", false, "Skip uploading the JSON web key set, and just save it into a file")
+}
+
+// NewRotateSigningKeyCmd provides the "rotate-signing-key" subcommand
+func NewRotateSigningKeyCmd() *cobra.Command {
+	rotateSigningKeyCmd := &cobra.Command{
+		Use:   "rotate-signing-key",
+		Short: "Publish a new service account signing key alongside the previous key",
+		Long: "Generate a new service account signing key pair within the output directory and publish a JSON web key set containing both the previous and the new public keys, " +
+			"so that tokens signed with either key are trusted while the cluster cuts over to the new key. Apply the next signing key secret manifest written to the manifests directory " +
+			"to the cluster, then run finalize-rotation once the cluster has cut over to remove the previous key",
+		Run:              rotateSigningKeyCmd,
+		PersistentPreRun: initEnvForRotationCmd(&RotateSigningKeyOpts),
+	}
+
+	addRotationFlags(rotateSigningKeyCmd, &RotateSigningKeyOpts)
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.PreviousPublicKeyPath, "previous-public-key-file", "", "Path to the public ServiceAccount signing key currently used by the cluster")
+	rotateSigningKeyCmd.MarkPersistentFlagRequired("previous-public-key-file")
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.OutputDir, "output-dir", "", "Directory to place the new key pair and generated files (defaults to current directory), must not contain the previous key pair")
+
+	return rotateSigningKeyCmd
+}
+
+// NewFinalizeRotationCmd provides the "finalize-rotation" subcommand
+func NewFinalizeRotationCmd() *cobra.Command {
+	finalizeRotationCmd := &cobra.Command{
+		Use:   "finalize-rotation",
+		Short: "Remove the previous service account signing key once the cluster has cut over to the new key",
+		Long: "Publish a JSON web key set containing only the new public key generated by rotate-signing-key, so that tokens signed with the previous key are no longer trusted. " +
+			"Run it once the cluster has cut over to the new key and tokens signed with the previous key have expired",
+		Run:              finalizeRotationCmd,
+		PersistentPreRun: initEnvForRotationCmd(&FinalizeRotationOpts),
+	}
+
+	addRotationFlags(finalizeRotationCmd, &FinalizeRotationOpts)
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.PublicKeyPath, "public-key-file", "", "Path to the new public ServiceAccount signing key (defaults to the public key within --output-dir)")
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.OutputDir, "output-dir", "", "Directory containing the new key pair generated by rotate-signing-key (defaults to current directory)")
+
+	return finalizeRotationCmd
+}
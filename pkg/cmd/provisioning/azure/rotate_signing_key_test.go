@@ -0,0 +1,66 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestRotateSigningKey(t *testing.T) {
+	previousDir, err := provisioning.InitRotationOutputDir(filepath.Join(t.TempDir(), "previous"))
+	require.NoError(t, err, "failed to prepare previous output directory")
+	require.NoError(t, provisioning.CreateKeys(previousDir), "failed to create previous key pair")
+	outputDir, err := provisioning.InitRotationOutputDir(filepath.Join(t.TempDir(), "rotation"))
+	require.NoError(t, err, "failed to prepare output directory")
+
+	opts := azureOptions{
+		Name:                  testInfraName,
+		OIDCResourceGroupName: testOIDCResourceGroupName,
+		StorageAccountName:    testStorageAccountName,
+		BlobContainerName:     testBlobContainerName,
+		IssuerURLPathPrefix:   "prefix",
+		OwnedTagValue:         ownedAzureResourceTagValue,
+		PreviousPublicKeyPath: filepath.Join(previousDir, provisioning.PublicKeyFile),
+		OutputDir:             outputDir,
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	wrapper := mockAzureClientWrapper(mockCtrl)
+	mockStorageAccountListKeys(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+	mockStorageAccountListKeys(wrapper, testOIDCResourceGroupName, testStorageAccountName)
+
+	// Capture the JSON web key sets uploaded to the blob container
+	uploadedKeySets := []provisioning.JSONWebKeySet{}
+	wrapper.BlobSharedKeyClient.(*mockazure.MockAZBlobClient).EXPECT().UploadBuffer(gomock.Any(), "", "prefix/openid/v1/"+jwksFileName, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, containerName, blobName string, buffer []byte, o *azblob.UploadBufferOptions) (azblob.UploadBufferResponse, error) {
+			assert.Equal(t, ownedAzureResourceTagValue, o.Tags[ownedResourceTagKey(testInfraName)], "expected JSON web key set blob to carry the owned tag")
+			keySet := provisioning.JSONWebKeySet{}
+			require.NoError(t, json.Unmarshal(buffer, &keySet), "failed to decode uploaded JSON web key set")
+			uploadedKeySets = append(uploadedKeySets, keySet)
+			return azblob.UploadBufferResponse{}, nil
+		}).Times(2)
+
+	require.NoError(t, rotateSigningKey(context.Background(), wrapper, opts), "unexpected error rotating signing key")
+	assert.FileExists(t, filepath.Join(outputDir, provisioning.ManifestsDirName, provisioning.NextSigningKeySecretFile), "expected next signing key secret manifest")
+	require.NoError(t, finalizeRotation(context.Background(), wrapper, opts), "unexpected error finalizing rotation")
+
+	require.Len(t, uploadedKeySets, 2, "expected JSON web key sets to be uploaded by rotation and finalization")
+	require.Len(t, uploadedKeySets[0].Keys, 2, "expected rotation to publish the previous and the new key")
+	require.Len(t, uploadedKeySets[1].Keys, 1, "expected finalization to publish only the new key")
+	assert.Equal(t, uploadedKeySets[0].Keys[1].KeyID, uploadedKeySets[1].Keys[0].KeyID, "expected finalization to keep the new key")
+	assert.FileExists(t, filepath.Join(outputDir, jwksFileName), "expected JSON web key set to be saved within the output directory")
+
+	// A dry run only saves the JSON web key set within the output directory
+	opts.DryRun = true
+	require.NoError(t, finalizeRotation(context.Background(), wrapper, opts), "unexpected error finalizing rotation with dry run")
+}
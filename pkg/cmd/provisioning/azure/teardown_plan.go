@@ -0,0 +1,234 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/pkg/errors"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	resourceGroupResourceType   = "ResourceGroup"
+	storageAccountResourceType  = "StorageAccount"
+	managedIdentityResourceType = "UserAssignedManagedIdentity"
+)
+
+// teardownPlanResourceTypes are the types of the resources which may be listed within the teardown plan of ccoctl
+// azure delete
+var teardownPlanResourceTypes = []string{managedIdentityResourceType, storageAccountResourceType, resourceGroupResourceType}
+
+// teardownResource is a resource which ccoctl azure delete may delete by following a teardown plan
+type teardownResource struct {
+	resourceType string
+	name         string
+	id           string
+	// resourceGroupName is the resource group containing the resource, empty for resource groups
+	resourceGroupName string
+	// owned is true when the resource carries CCO's "owned" tag for the name being deleted, and the cluster
+	// ownership tag when a cluster ID is provided
+	owned bool
+	// tags are the tags of the resource
+	tags map[string]*string
+	// createdAt is the creation time of the resource reported by Azure, nil for resource groups
+	createdAt *time.Time
+	// identity is the user-assigned managed identity of resources of type managedIdentityResourceType
+	identity *armmsi.Identity
+}
+
+// matches returns true when the resource is listed as planned within a teardown plan, by its name or by its Azure
+// resource ID which is case-insensitive
+func (r teardownResource) matches(planned provisioning.TeardownPlanResource) bool {
+	return r.resourceType == planned.Type && (r.name == planned.ID || strings.EqualFold(r.id, planned.ID))
+}
+
+// listTeardownResources lists the resources which may be listed within the teardown plan of opts: the OIDC resource
+// group and the identity resource group, the storage accounts within the OIDC resource group and the user-assigned
+// managed identities within the identity resource group. Resource groups which do not exist are skipped.
+func listTeardownResources(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions) ([]teardownResource, error) {
+	owned := func(tags map[string]*string) bool {
+		return hasOwnedResourceTag(tags, opts.Name, opts.OwnedTagValue) && hasClusterResourceTag(tags, opts.ClusterID)
+	}
+
+	resources := []teardownResource{}
+	resourceGroupNames := []string{opts.OIDCResourceGroupName}
+	if opts.IdentityResourceGroupName != opts.OIDCResourceGroupName {
+		resourceGroupNames = append(resourceGroupNames, opts.IdentityResourceGroupName)
+	}
+	existingResourceGroups := map[string]bool{}
+	for _, resourceGroupName := range resourceGroupNames {
+		resp, err := client.ResourceGroupsClient.Get(ctx, resourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) && respErr.ErrorCode == "ResourceGroupNotFound" {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get resource group %s", resourceGroupName)
+		}
+		existingResourceGroups[resourceGroupName] = true
+		resources = append(resources, teardownResource{
+			resourceType: resourceGroupResourceType,
+			name:         resourceGroupName,
+			id:           stringValue(resp.ID),
+			owned:        owned(resp.Tags),
+			tags:         resp.Tags,
+		})
+	}
+
+	if existingResourceGroups[opts.OIDCResourceGroupName] {
+		storageAccounts := []teardownResource{}
+		listAccounts := client.StorageAccountClient.NewListByResourceGroupPager(opts.OIDCResourceGroupName, &armstorage.AccountsClientListByResourceGroupOptions{})
+		for listAccounts.More() {
+			pageResponse, err := listAccounts.NextPage(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list storage accounts")
+			}
+			for _, storageAccount := range pageResponse.AccountListResult.Value {
+				var createdAt *time.Time
+				if storageAccount.Properties != nil {
+					createdAt = storageAccount.Properties.CreationTime
+				}
+				storageAccounts = append(storageAccounts, teardownResource{
+					resourceType:      storageAccountResourceType,
+					name:              stringValue(storageAccount.Name),
+					id:                stringValue(storageAccount.ID),
+					resourceGroupName: opts.OIDCResourceGroupName,
+					owned:             owned(storageAccount.Tags),
+					tags:              storageAccount.Tags,
+					createdAt:         createdAt,
+				})
+			}
+		}
+		resources = append(resources, sortTeardownResources(storageAccounts)...)
+	}
+
+	if existingResourceGroups[opts.IdentityResourceGroupName] {
+		managedIdentities := []teardownResource{}
+		listManagedIdentities := client.UserAssignedIdentitiesClient.NewListByResourceGroupPager(opts.IdentityResourceGroupName, &armmsi.UserAssignedIdentitiesClientListByResourceGroupOptions{})
+		for listManagedIdentities.More() {
+			pageResponse, err := listManagedIdentities.NextPage(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list user-assigned managed identities")
+			}
+			for _, identity := range pageResponse.UserAssignedIdentitiesListResult.Value {
+				var createdAt *time.Time
+				if identity.SystemData != nil {
+					createdAt = identity.SystemData.CreatedAt
+				}
+				managedIdentities = append(managedIdentities, teardownResource{
+					resourceType:      managedIdentityResourceType,
+					name:              stringValue(identity.Name),
+					id:                stringValue(identity.ID),
+					resourceGroupName: opts.IdentityResourceGroupName,
+					owned:             owned(identity.Tags),
+					tags:              identity.Tags,
+					createdAt:         createdAt,
+					identity:          identity,
+				})
+			}
+		}
+		resources = append(resources, sortTeardownResources(managedIdentities)...)
+	}
+
+	return resources, nil
+}
+
+// sortTeardownResources orders resources by name so that they are reported in a stable order
+func sortTeardownResources(resources []teardownResource) []teardownResource {
+	sort.Slice(resources, func(i, j int) bool { return resources[i].name < resources[j].name })
+	return resources
+}
+
+// deleteTeardownPlan deletes the resources listed within the teardown plan of opts in the order they are listed.
+//
+// Every listed resource is resolved before any is deleted. An error is returned when a listed resource exists without
+// CCO's "owned" tag for the name of opts, and the cluster ownership tag when a cluster ID is provided, while listed
+// resources which do not exist, eg. as a previous run deleted them, are skipped. Owned resources which are neither
+// listed nor within a listed resource group are reported, as they are left intact. Deletion stops at the first resource
+// which fails to be deleted since the following resources may depend on it. When dryRun is provided the ordered
+// deletions are logged instead.
+func deleteTeardownPlan(ctx context.Context, client *azureclients.AzureClientWrapper, opts azureOptions) error {
+	resources, err := listTeardownResources(ctx, client, opts)
+	if err != nil {
+		return err
+	}
+
+	plan := opts.TeardownPlan.Resources
+	planned := make([]*teardownResource, len(plan))
+	plannedIDs := map[string]bool{}
+	plannedResourceGroups := map[string]bool{}
+	for i, plannedResource := range plan {
+		for j := range resources {
+			if resources[j].matches(plannedResource) {
+				planned[i] = &resources[j]
+				break
+			}
+		}
+		if planned[i] == nil {
+			continue
+		}
+		if !planned[i].owned {
+			return fmt.Errorf("refusing to follow the teardown plan, %s is not owned by %s: it does not have tag key=%s, value=%s%s",
+				plannedResource, opts.Name, ownedResourceTagKey(opts.Name), opts.OwnedTagValue, clusterTagRequirement(opts.ClusterID))
+		}
+		plannedIDs[planned[i].id] = true
+		if planned[i].resourceType == resourceGroupResourceType {
+			plannedResourceGroups[planned[i].name] = true
+		}
+	}
+
+	// Resources are discovered with a dry run before any is deleted, so the resources which are left intact are only
+	// reported once
+	if opts.DryRun {
+		for _, resource := range resources {
+			if resource.owned && !plannedIDs[resource.id] && !plannedResourceGroups[resource.resourceGroupName] {
+				provisioning.Warnf("%s %s is owned by %s but is not listed within the teardown plan, it will not be deleted",
+					resource.resourceType, resource.name, opts.Name)
+			}
+		}
+	}
+
+	for i, plannedResource := range plan {
+		resource := planned[i]
+		provisioning.SetPhase(fmt.Sprintf("deleting %s, step %d of the teardown plan", plannedResource, i+1))
+		log.Printf("Teardown plan step %d of %d: %s", i+1, len(plan), plannedResource)
+		if resource == nil {
+			log.Printf("Skipping %s which was not found", plannedResource)
+			continue
+		}
+
+		// The ownership of the resource was validated as the teardown plan was resolved
+		var err error
+		switch resource.resourceType {
+		case resourceGroupResourceType:
+			err = deleteResourceGroup(ctx, client, opts.Name, opts.OwnedTagValue, "", resource.name, true, 0, provisioning.TagSelector{}, opts.DryRun)
+		case storageAccountResourceType:
+			err = deleteStorageAccount(ctx, client, opts.Name, opts.OwnedTagValue, "", resource.resourceGroupName, resource.name, 0, provisioning.TagSelector{}, opts.DryRun)
+		case managedIdentityResourceType:
+			err = deleteManagedIdentity(ctx, client, resource.identity, resource.resourceGroupName, opts.Region, opts.DryRun)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete %s, step %d of the teardown plan", plannedResource, i+1)
+		}
+	}
+	return nil
+}
+
+// clusterTagRequirement describes the cluster ownership tag required along with CCO's "owned" tag when clusterID is
+// provided
+func clusterTagRequirement(clusterID string) string {
+	if clusterID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" and tag key=%s, value=%s", clusterResourceTagKey(clusterID), provisioning.ClusterResourceTagValue)
+}
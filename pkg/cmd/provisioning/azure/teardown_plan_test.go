@@ -0,0 +1,181 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/msi/armmsi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestDeleteTeardownPlan(t *testing.T) {
+	ownedTags := map[string]*string{ownedResourceTagKey(testInfraName): to.Ptr(ownedAzureResourceTagValue)}
+	ingressIdentityName := testInfraName + "-openshift-ingress-operator-cloud-credentials"
+	registryIdentityName := testInfraName + "-openshift-image-registry-installer-cloud-credentials"
+	registryIdentityID := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s",
+		testSubscriptionID, testOIDCResourceGroupName, registryIdentityName)
+
+	// mockTeardownResources mocks the OIDC resource group, containing the storage account and identities with tags
+	mockTeardownResources := func(wrapper *azureclients.AzureClientWrapper, identities map[string]map[string]*string) {
+		mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+		mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+		mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, identities)
+	}
+	plan := &provisioning.TeardownPlan{Resources: []provisioning.TeardownPlanResource{
+		{Type: managedIdentityResourceType, ID: ingressIdentityName},
+		{Type: storageAccountResourceType, ID: testStorageAccountName},
+		{Type: managedIdentityResourceType, ID: registryIdentityID},
+	}}
+
+	tests := []struct {
+		name                   string
+		plan                   *provisioning.TeardownPlan
+		mockAzureClientWrapper func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper
+		dryRun                 bool
+		expectCounts           map[string]int
+		expectError            string
+	}{
+		{
+			name: "Resources deleted in the order of the teardown plan",
+			plan: plan,
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockTeardownResources(wrapper, map[string]map[string]*string{
+					ingressIdentityName:  ownedTags,
+					registryIdentityName: ownedTags,
+				})
+				identitiesClient := wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient)
+				accountsClient := wrapper.StorageAccountClient.(*mockazure.MockAccountsClient)
+				gomock.InOrder(
+					identitiesClient.EXPECT().Delete(gomock.Any(), testOIDCResourceGroupName, ingressIdentityName, gomock.Any()).Return(armmsi.UserAssignedIdentitiesClientDeleteResponse{}, nil),
+					accountsClient.EXPECT().Delete(gomock.Any(), testOIDCResourceGroupName, testStorageAccountName, gomock.Any()),
+					identitiesClient.EXPECT().Delete(gomock.Any(), testOIDCResourceGroupName, registryIdentityName, gomock.Any()).Return(armmsi.UserAssignedIdentitiesClientDeleteResponse{}, nil),
+				)
+				return wrapper
+			},
+		},
+		{
+			name: "Ordered deletions previewed with dry run",
+			plan: plan,
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				// The identity of the cloud controller manager is owned but not listed, it is reported and left intact
+				mockTeardownResources(wrapper, map[string]map[string]*string{
+					ingressIdentityName:  ownedTags,
+					registryIdentityName: ownedTags,
+					testInfraName + "-openshift-cloud-controller-manager-azure-cloud-credentials": ownedTags,
+				})
+				// The dry run looks the storage account up again before reporting it
+				mockStorageAccountListByResourceGroupPager(wrapper, []string{testStorageAccountName}, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				return wrapper
+			},
+			dryRun:       true,
+			expectCounts: map[string]int{"UserAssignedManagedIdentity": 2, "StorageAccount": 1},
+		},
+		{
+			name: "Resources within a listed resource group deleted along with it",
+			plan: &provisioning.TeardownPlan{Resources: []provisioning.TeardownPlanResource{
+				{Type: managedIdentityResourceType, ID: ingressIdentityName},
+				{Type: resourceGroupResourceType, ID: testOIDCResourceGroupName},
+			}},
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockTeardownResources(wrapper, map[string]map[string]*string{
+					ingressIdentityName:  ownedTags,
+					registryIdentityName: ownedTags,
+				})
+				return wrapper
+			},
+			dryRun:       true,
+			expectCounts: map[string]int{"UserAssignedManagedIdentity": 1, "ResourceGroup": 1},
+		},
+		{
+			name: "Listed resources which were not found skipped",
+			plan: plan,
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupSuccess(wrapper, testOIDCResourceGroupName, testRegionName, testSubscriptionID, ownedTags)
+				mockStorageAccountListByResourceGroupPager(wrapper, nil, testOIDCResourceGroupName, testRegionName, testSubscriptionID, nil)
+				mockUserAssignedIdentitiesListByResourceGroupPager(wrapper, testOIDCResourceGroupName, map[string]map[string]*string{
+					registryIdentityName: ownedTags,
+				})
+				mockDeleteUserAssignedIdentitySuccess(wrapper, testOIDCResourceGroupName, registryIdentityName)
+				return wrapper
+			},
+		},
+		{
+			name: "Nothing deleted without the OIDC resource group",
+			plan: plan,
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockGetResourceGroupNotFound(wrapper, testOIDCResourceGroupName, testSubscriptionID)
+				return wrapper
+			},
+		},
+		{
+			name: "Listed resource not owned refused before any deletion",
+			plan: plan,
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockTeardownResources(wrapper, map[string]map[string]*string{
+					ingressIdentityName:  ownedTags,
+					registryIdentityName: {ownedResourceTagKey("othername"): to.Ptr(ownedAzureResourceTagValue)},
+				})
+				return wrapper
+			},
+			expectError: fmt.Sprintf("refusing to follow the teardown plan, UserAssignedManagedIdentity %s is not owned by %s", registryIdentityID, testInfraName),
+		},
+		{
+			name: "Teardown plan stopped at the first failed deletion",
+			plan: plan,
+			mockAzureClientWrapper: func(mockCtrl *gomock.Controller) *azureclients.AzureClientWrapper {
+				wrapper := mockAzureClientWrapper(mockCtrl)
+				mockTeardownResources(wrapper, map[string]map[string]*string{
+					ingressIdentityName:  ownedTags,
+					registryIdentityName: ownedTags,
+				})
+				wrapper.UserAssignedIdentitiesClient.(*mockazure.MockUserAssignedIdentitiesClient).EXPECT().Delete(gomock.Any(), testOIDCResourceGroupName, ingressIdentityName, gomock.Any()).Return(
+					armmsi.UserAssignedIdentitiesClientDeleteResponse{}, fmt.Errorf("identity deletion failed"))
+				return wrapper
+			},
+			expectError: "failed to delete UserAssignedManagedIdentity " + ingressIdentityName + ", step 1 of the teardown plan: identity deletion failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			wrapper := test.mockAzureClientWrapper(mockCtrl)
+			opts := azureOptions{
+				Name:                      testInfraName,
+				OwnedTagValue:             ownedAzureResourceTagValue,
+				OIDCResourceGroupName:     testOIDCResourceGroupName,
+				IdentityResourceGroupName: testOIDCResourceGroupName,
+				StorageAccountName:        testStorageAccountName,
+				Region:                    testRegionName,
+				DryRun:                    test.dryRun,
+				TeardownPlan:              test.plan,
+			}
+
+			summary, err := provisioning.DiscoverDeletions(func() error {
+				return deleteWithinSubscription(context.Background(), wrapper, opts, testSubscriptionID)
+			})
+			if test.expectError != "" {
+				assert.ErrorContains(t, err, test.expectError)
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			if test.dryRun {
+				assert.Equal(t, test.expectCounts, summary.Counts(), "unexpected resources discovered for deletion")
+			}
+		})
+	}
+}
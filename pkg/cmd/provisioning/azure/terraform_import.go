@@ -0,0 +1,75 @@
+package azure
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// terraformResourceTypes are the terraform resource types into which the Azure resources recorded within the inventory
+// are imported, keyed by the type of the inventory resource
+var terraformResourceTypes = map[string]string{
+	"ResourceGroup":               "azurerm_resource_group",
+	"StorageAccount":              "azurerm_storage_account",
+	"BlobContainer":               "azurerm_storage_container",
+	"UserAssignedManagedIdentity": "azurerm_user_assigned_identity",
+	"FederatedIdentityCredential": "azurerm_federated_identity_credential",
+}
+
+// resourceGroupID returns the ID of the resource group resourceGroupName within subscriptionID
+func resourceGroupID(subscriptionID, resourceGroupName string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionID, resourceGroupName)
+}
+
+// storageAccountID returns the ID of the storage account storageAccountName within resourceGroupName
+func storageAccountID(subscriptionID, resourceGroupName, storageAccountName string) string {
+	return resourceGroupID(subscriptionID, resourceGroupName) + "/providers/Microsoft.Storage/storageAccounts/" + storageAccountName
+}
+
+// blobContainerID returns the ID of the blob container blobContainerName within storageAccountName
+func blobContainerID(subscriptionID, resourceGroupName, storageAccountName, blobContainerName string) string {
+	return storageAccountID(subscriptionID, resourceGroupName, storageAccountName) + "/blobServices/default/containers/" + blobContainerName
+}
+
+// managedIdentityID returns the ID of the user-assigned managed identity managedIdentityName within resourceGroupName
+func managedIdentityID(subscriptionID, resourceGroupName, managedIdentityName string) string {
+	return resourceGroupID(subscriptionID, resourceGroupName) + "/providers/Microsoft.ManagedIdentity/userAssignedIdentities/" + managedIdentityName
+}
+
+// terraformImports returns the terraform imports of the Azure resources recorded within step. The federated identity
+// credentials recorded within the step of a user-assigned managed identity are named after the identity, since
+// service accounts of the same name may be federated with several identities.
+func terraformImports(step provisioning.InventoryStep) []provisioning.TerraformImport {
+	imports := []provisioning.TerraformImport{}
+	managedIdentityName := ""
+	for _, resource := range step.Resources {
+		resourceType, ok := terraformResourceTypes[resource.Type]
+		// Resources recorded by earlier versions of ccoctl lack the ID with which they are imported
+		if !ok || resource.ID == "" {
+			continue
+		}
+		name := resource.Name
+		switch resource.Type {
+		case "UserAssignedManagedIdentity":
+			managedIdentityName = resource.Name
+		case "FederatedIdentityCredential":
+			name = managedIdentityName + "-" + resource.Name
+		}
+		imports = append(imports, provisioning.TerraformImport{
+			Address: provisioning.TerraformAddress(resourceType, name),
+			ID:      resource.ID,
+		})
+	}
+	return imports
+}
+
+// writeTerraformImports writes the terraform import commands of the Azure resources recorded within inventory
+func writeTerraformImports(inventory *provisioning.Inventory) error {
+	path, err := provisioning.WriteTerraformImports(inventory, terraformImports)
+	if err != nil {
+		return err
+	}
+	log.Printf("Saved terraform import commands at path %s", path)
+	return nil
+}
@@ -0,0 +1,76 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestTerraformImports(t *testing.T) {
+	identityID := managedIdentityID(testSubscriptionID, testOIDCResourceGroupName, "identity1")
+	tests := []struct {
+		name            string
+		step            provisioning.InventoryStep
+		expectedImports []provisioning.TerraformImport
+	}{
+		{
+			name: "OIDC issuer",
+			step: provisioning.InventoryStep{
+				Name: oidcIssuerStep,
+				Resources: []provisioning.InventoryResource{
+					{Type: "ResourceGroup", Name: testOIDCResourceGroupName, ID: resourceGroupID(testSubscriptionID, testOIDCResourceGroupName)},
+					{Type: "StorageAccount", Name: "storageaccount1", ID: storageAccountID(testSubscriptionID, testOIDCResourceGroupName, "storageaccount1")},
+					{Type: "BlobContainer", Name: "container1", ID: blobContainerID(testSubscriptionID, testOIDCResourceGroupName, "storageaccount1", "container1")},
+					{Type: oidcIssuerInventoryResourceType, Name: "https://storageaccount1.blob.core.windows.net/container1"},
+				},
+			},
+			expectedImports: []provisioning.TerraformImport{
+				{
+					Address: "azurerm_resource_group." + testOIDCResourceGroupName,
+					ID:      "/subscriptions/123456789/resourceGroups/" + testOIDCResourceGroupName,
+				},
+				{
+					Address: "azurerm_storage_account.storageaccount1",
+					ID:      "/subscriptions/123456789/resourceGroups/" + testOIDCResourceGroupName + "/providers/Microsoft.Storage/storageAccounts/storageaccount1",
+				},
+				{
+					Address: "azurerm_storage_container.container1",
+					ID:      "/subscriptions/123456789/resourceGroups/" + testOIDCResourceGroupName + "/providers/Microsoft.Storage/storageAccounts/storageaccount1/blobServices/default/containers/container1",
+				},
+			},
+		},
+		{
+			name: "managed identity",
+			step: provisioning.InventoryStep{
+				Name: managedIdentityStepPrefix + "namespace1/secret1",
+				Resources: managedIdentityResources(testSubscriptionID, testOIDCResourceGroupName, "identity1", &credreqv1.CredentialsRequest{
+					Spec: credreqv1.CredentialsRequestSpec{
+						ServiceAccountNames: []string{"serviceaccount1", "serviceaccount2"},
+					},
+				}),
+			},
+			expectedImports: []provisioning.TerraformImport{
+				{Address: "azurerm_user_assigned_identity.identity1", ID: identityID},
+				{Address: "azurerm_federated_identity_credential.identity1-serviceaccount1", ID: identityID + "/federatedIdentityCredentials/serviceaccount1"},
+				{Address: "azurerm_federated_identity_credential.identity1-serviceaccount2", ID: identityID + "/federatedIdentityCredentials/serviceaccount2"},
+			},
+		},
+		{
+			name: "resources recorded without IDs not imported",
+			step: provisioning.InventoryStep{
+				Name:      installationResourceGroupStep,
+				Resources: []provisioning.InventoryResource{{Type: "ResourceGroup", Name: testInfraName}},
+			},
+			expectedImports: []provisioning.TerraformImport{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedImports, terraformImports(test.step))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	azureclients "github.com/openshift/cloud-credential-operator/pkg/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// writePermissionsProbePrefix prefixes the name of the resource group which does not exist with which the write
+// permissions of the identity are probed
+const writePermissionsProbePrefix = "ccoctl-write-permissions-probe-"
+
+// readOnlyIdentityMessage is the error reported by checkWritePermissions when the identity is denied the probe
+const readOnlyIdentityMessage = "the provided identity appears to be read-only; ccoctl requires write permissions."
+
+// isAuthorizationFailure returns true when err is the response of Azure to a request the identity is not permitted
+// to make
+func isAuthorizationFailure(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && (respErr.StatusCode == http.StatusForbidden || respErr.ErrorCode == "AuthorizationFailed")
+}
+
+// checkWritePermissions fails fast when the identity of client is clearly read-only, rather than deep within the first
+// change made by ccoctl. The probe is a no-op mutation: a resource group with a random name is looked up, which a
+// read-only identity is permitted to do, then deleted. As the resource group does not exist nothing is deleted, while
+// Azure authorizes the deletion before looking up the resource group, so that the deletion is only denied when the
+// identity is not permitted to delete resource groups within the subscription. Nothing is ever created. The probe is
+// skipped with a warning when its outcome is not conclusive, eg. when the identity may not read resource groups either.
+func checkWritePermissions(ctx context.Context, client *azureclients.AzureClientWrapper) error {
+	probeResourceGroupName := writePermissionsProbePrefix + uuid.New().String()
+
+	_, err := client.ResourceGroupsClient.Get(ctx, probeResourceGroupName, &armresources.ResourceGroupsClientGetOptions{})
+	if err == nil {
+		// The probe never deletes a resource group which exists
+		provisioning.Warnf("Skipping the check of write permissions, the probe resource group %s unexpectedly exists", probeResourceGroupName)
+		return nil
+	}
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.ErrorCode != "ResourceGroupNotFound" {
+		provisioning.Warnf("Skipping the check of write permissions, failed to look up the probe resource group %s: %v", probeResourceGroupName, err)
+		return nil
+	}
+
+	_, err = client.ResourceGroupsClient.BeginDelete(ctx, probeResourceGroupName, &armresources.ResourceGroupsClientBeginDeleteOptions{})
+	if isAuthorizationFailure(err) {
+		return fmt.Errorf("%s Deleting the probe resource group %s, which does not exist, was denied: %v. Grant the identity a role permitting changes within the subscription, eg. Contributor, or pass --skip-write-permissions-check to skip this check",
+			readOnlyIdentityMessage, probeResourceGroupName, err)
+	}
+	// Any other outcome, typically ResourceGroupNotFound, shows that the identity is permitted to make changes
+	return nil
+}
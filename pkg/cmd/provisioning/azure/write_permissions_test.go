@@ -0,0 +1,87 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockazure "github.com/openshift/cloud-credential-operator/pkg/azure/mock"
+)
+
+// responseError returns the error of an Azure response with statusCode and errorCode
+func responseError(statusCode int, errorCode string) error {
+	header := http.Header{}
+	header.Set("x-ms-error-code", errorCode)
+	return NewResponseError(&http.Response{StatusCode: statusCode, Header: header})
+}
+
+func TestCheckWritePermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		getError    error
+		expectProbe bool
+		deleteError error
+		expectError bool
+	}{
+		{
+			name:        "Identity permitted to make changes",
+			getError:    responseError(http.StatusNotFound, "ResourceGroupNotFound"),
+			expectProbe: true,
+			deleteError: responseError(http.StatusNotFound, "ResourceGroupNotFound"),
+		},
+		{
+			name:        "Read-only identity",
+			getError:    responseError(http.StatusNotFound, "ResourceGroupNotFound"),
+			expectProbe: true,
+			deleteError: responseError(http.StatusForbidden, "AuthorizationFailed"),
+			expectError: true,
+		},
+		{
+			name:     "Identity not permitted to read resource groups",
+			getError: responseError(http.StatusForbidden, "AuthorizationFailed"),
+		},
+		{
+			name: "Probe resource group exists",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			wrapper := mockAzureClientWrapper(mockCtrl)
+			resourceGroupsClient := wrapper.ResourceGroupsClient.(*mockazure.MockResourceGroupsClient)
+
+			var probeResourceGroupName string
+			resourceGroupsClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientGetOptions) (armresources.ResourceGroupsClientGetResponse, error) {
+					probeResourceGroupName = resourceGroupName
+					return armresources.ResourceGroupsClientGetResponse{}, test.getError
+				})
+			// A resource group which exists, or may exist, is never deleted
+			if test.expectProbe {
+				resourceGroupsClient.EXPECT().BeginDelete(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, resourceGroupName string, options *armresources.ResourceGroupsClientBeginDeleteOptions) (*runtime.Poller[armresources.ResourceGroupsClientDeleteResponse], error) {
+						assert.Equal(t, probeResourceGroupName, resourceGroupName, "expected the resource group which was not found to be deleted")
+						return nil, test.deleteError
+					})
+			}
+
+			err := checkWritePermissions(context.Background(), wrapper)
+			assert.True(t, strings.HasPrefix(probeResourceGroupName, writePermissionsProbePrefix), "unexpected name of probe resource group %s", probeResourceGroupName)
+			if test.expectError {
+				require.Error(t, err, "expected a read-only identity to be reported")
+				assert.Contains(t, err.Error(), "the provided identity appears to be read-only; ccoctl requires write permissions.")
+				assert.Contains(t, err.Error(), "--skip-write-permissions-check")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
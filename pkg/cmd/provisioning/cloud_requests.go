@@ -0,0 +1,288 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxDumpedBodySize is the size beyond which the bodies of the requests and responses written by
+// --dump-cloud-requests are truncated
+const maxDumpedBodySize = 64 * 1024
+
+// sensitiveHeaders are the headers of requests and responses whose values are never written by
+// --dump-cloud-requests, keyed by their canonical name
+var sensitiveHeaders = map[string]bool{
+	"Authorization":                  true,
+	"Proxy-Authorization":            true,
+	"Cookie":                         true,
+	"Set-Cookie":                     true,
+	"X-Amz-Security-Token":           true,
+	"X-Goog-Api-Key":                 true,
+	"X-Ms-Authorization-Auxiliary":   true,
+	"X-Ms-Copy-Source-Authorization": true,
+}
+
+// sensitiveFieldPattern matches the fields of JSON bodies whose string values are never written by
+// --dump-cloud-requests, in addition to the secret material redacted by Redact
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)(password|secret|token|privatekey|keydata|accountkey|credentials?$)`)
+
+// CloudRequest is an outbound cloud API request and its response, written by --dump-cloud-requests as a single line of
+// JSON. Every attempt of a request which is retried is written.
+type CloudRequest struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Provider is the cloud provider whose API was called, eg. "aws"
+	Provider string `json:"provider"`
+	// Method is the HTTP method of the request or, for gRPC calls, "GRPC"
+	Method string `json:"method"`
+	// URL is the URL of the request or, for gRPC calls, the full name of the method called
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"requestHeaders,omitempty"`
+	RequestBody     string            `json:"requestBody,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	ResponseBody    string            `json:"responseBody,omitempty"`
+	// Duration is the time for which the request was in flight, in milliseconds
+	Duration int64 `json:"durationMs"`
+	// Error is the error with which the request failed before a response was received, eg. a transport error or,
+	// for gRPC calls, the status of the call
+	Error string `json:"error,omitempty"`
+}
+
+// cloudRequestWriter writes cloud requests to out. Requests may be sent concurrently so writes are serialized.
+type cloudRequestWriter struct {
+	mu  sync.Mutex
+	out io.WriteCloser
+	now func() time.Time
+}
+
+// cloudRequestDump is the writer of --dump-cloud-requests, it is nil unless cloud requests are dumped
+var cloudRequestDump *cloudRequestWriter
+
+// InitCloudRequestDump configures ccoctl to write every outbound cloud API request and its response to the file at
+// path, which is truncated. Requests are not dumped when path is empty.
+func InitCloudRequestDump(path string) error {
+	if path == "" {
+		cloudRequestDump = nil
+		return nil
+	}
+	// The requests may identify the resources of the account and are only readable by their owner
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open --dump-cloud-requests file %s", path)
+	}
+	cloudRequestDump = &cloudRequestWriter{out: f, now: time.Now}
+	return nil
+}
+
+// CloseCloudRequestDump closes the file of --dump-cloud-requests, after which requests are no longer dumped
+func CloseCloudRequestDump() {
+	if cloudRequestDump == nil {
+		return
+	}
+	if err := cloudRequestDump.out.Close(); err != nil {
+		Warnf("Failed to close --dump-cloud-requests file: %s", err)
+	}
+	cloudRequestDump = nil
+}
+
+// CloudRequestDumpEnabled returns true when --dump-cloud-requests was provided
+func CloudRequestDumpEnabled() bool {
+	return cloudRequestDump != nil
+}
+
+// DumpHTTPRequest writes the request req sent to the API of provider, along with either its response resp or the
+// error err with which it failed, to the file of --dump-cloud-requests. requestBody is the body of req, which the caller
+// must read since the body of req may no longer be readable once sent. The body of resp is read and replaced, so that
+// it may still be read by the caller. Nothing is written unless --dump-cloud-requests was provided.
+func DumpHTTPRequest(provider string, req *http.Request, requestBody []byte, resp *http.Response, err error, duration time.Duration) {
+	if cloudRequestDump == nil {
+		return
+	}
+	request := CloudRequest{
+		Provider:       provider,
+		Method:         req.Method,
+		URL:            Redact(req.URL.String()),
+		RequestHeaders: sanitizeHeaders(req.Header),
+		RequestBody:    sanitizeBody(requestBody),
+		Duration:       duration.Milliseconds(),
+	}
+	if err != nil {
+		request.Error = Redact(err.Error())
+	}
+	if resp != nil {
+		request.Status = resp.StatusCode
+		request.ResponseHeaders = sanitizeHeaders(resp.Header)
+		if resp.Body != nil && resp.Body != http.NoBody {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), errorReader{readErr}))
+			request.ResponseBody = sanitizeBody(body)
+		}
+	}
+	cloudRequestDump.write(request)
+}
+
+// DumpRPC writes the gRPC call of method sent to the API of provider, along with its request and reply messages and
+// the error it failed with, if any, to the file of --dump-cloud-requests. Nothing is written unless
+// --dump-cloud-requests was provided.
+func DumpRPC(provider, method string, req, reply interface{}, err error, duration time.Duration) {
+	if cloudRequestDump == nil {
+		return
+	}
+	request := CloudRequest{
+		Provider:    provider,
+		Method:      "GRPC",
+		URL:         method,
+		RequestBody: sanitizeBody(marshalMessage(req)),
+		Duration:    duration.Milliseconds(),
+	}
+	if err != nil {
+		request.Error = Redact(err.Error())
+	} else {
+		request.ResponseBody = sanitizeBody(marshalMessage(reply))
+	}
+	cloudRequestDump.write(request)
+}
+
+// NewCloudRequestDumpTransport returns a transport writing every request sent to the API of provider through base to
+// the file of --dump-cloud-requests, or base when --dump-cloud-requests was not provided
+func NewCloudRequestDumpTransport(provider string, base http.RoundTripper) http.RoundTripper {
+	if cloudRequestDump == nil {
+		return base
+	}
+	return &cloudRequestDumpTransport{provider: provider, base: base}
+}
+
+// cloudRequestDumpTransport writes every request sent through base to the file of --dump-cloud-requests
+type cloudRequestDumpTransport struct {
+	provider string
+	base     http.RoundTripper
+}
+
+func (t *cloudRequestDumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	DumpHTTPRequest(t.provider, req, requestBody, resp, err, time.Since(start))
+	return resp, err
+}
+
+func (w *cloudRequestWriter) write(request CloudRequest) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	request.Timestamp = w.now().UTC()
+	data, err := json.Marshal(request)
+	if err == nil {
+		_, err = w.out.Write(append(data, '\n'))
+	}
+	if err != nil {
+		Warnf("Failed to write to --dump-cloud-requests file: %s", err)
+	}
+}
+
+// sanitizeHeaders returns the first value of every header of header, with the values of sensitive headers replaced by
+// their fingerprint and the secret material within the other values redacted
+func sanitizeHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	sanitized := make(map[string]string, len(header))
+	for name, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			sanitized[name] = RedactedFingerprint(values[0])
+			continue
+		}
+		sanitized[name] = Redact(values[0])
+	}
+	return sanitized
+}
+
+// sanitizeBody returns body with the values of the sensitive fields of JSON bodies replaced by their fingerprint and
+// the secret material within any body redacted. Bodies larger than maxDumpedBodySize are truncated.
+func sanitizeBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err == nil {
+		if sanitized, err := json.Marshal(sanitizeJSON(document)); err == nil {
+			body = sanitized
+		}
+	}
+	truncated := ""
+	if len(body) > maxDumpedBodySize {
+		truncated = fmt.Sprintf("... (%d bytes truncated)", len(body)-maxDumpedBodySize)
+		body = body[:maxDumpedBodySize]
+	}
+	return Redact(string(body)) + truncated
+}
+
+// sanitizeJSON returns value, a decoded JSON document, with the string values of sensitive fields replaced by their
+// fingerprint. The keys of Azure storage accounts, objects with a "keyName" field, have their "value" replaced too.
+func sanitizeJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		_, storageKey := v["keyName"]
+		for key, field := range v {
+			if s, ok := field.(string); ok && (sensitiveFieldPattern.MatchString(key) || (storageKey && key == "value")) {
+				v[key] = RedactedFingerprint(s)
+				continue
+			}
+			v[key] = sanitizeJSON(field)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = sanitizeJSON(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// marshalMessage returns the JSON encoding of message, a gRPC message, or nil when it can't be encoded
+func marshalMessage(message interface{}) []byte {
+	m, ok := message.(proto.Message)
+	if !ok {
+		return nil
+	}
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// errorReader returns err once the body read before it has been consumed, so that an error reading a response body
+// which was dumped is still reported to the reader of the body
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,125 @@
+package provisioning
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	iamadminpb "google.golang.org/genproto/googleapis/iam/admin/v1"
+)
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func testCloudRequestDump(out *bytes.Buffer) *cloudRequestWriter {
+	return &cloudRequestWriter{
+		out: nopWriteCloser{out},
+		now: func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+}
+
+func decodeCloudRequests(t *testing.T, out *bytes.Buffer) []CloudRequest {
+	requests := []CloudRequest{}
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		request := CloudRequest{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &request), "expected each line to be a JSON object")
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+func TestCloudRequestDumpTransport(t *testing.T) {
+	out := &bytes.Buffer{}
+	cloudRequestDump = testCloudRequestDump(out)
+	defer func() { cloudRequestDump = nil }()
+
+	responseBody := `{"keys":[{"keyName":"key1","value":"c2VjcmV0","permissions":"FULL"}],"access_token":"opaque-token"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"test","password":"hunter2"}`, string(body), "expected the request body to be sent unchanged")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, responseBody)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewCloudRequestDumpTransport("azure", http.DefaultTransport)}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/listKeys?sig=c2lnbmF0dXJl", strings.NewReader(`{"name":"test","password":"hunter2"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer opaque-bearer-token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err, "unexpected error")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, responseBody, string(body), "expected the response body to still be readable once dumped")
+
+	requests := decodeCloudRequests(t, out)
+	require.Len(t, requests, 1, "expected a single request to be dumped")
+	request := requests[0]
+	assert.Equal(t, "azure", request.Provider)
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, http.StatusOK, request.Status)
+	assert.Equal(t, server.URL+"/listKeys?sig="+RedactedFingerprint("c2lnbmF0dXJl"), request.URL, "expected the signature of the URL to be redacted")
+	assert.Equal(t, RedactedFingerprint("Bearer opaque-bearer-token"), request.RequestHeaders["Authorization"], "expected the authorization header to be redacted")
+	assert.Equal(t, "application/json", request.RequestHeaders["Content-Type"])
+	assert.Equal(t, RedactedFingerprint("session=abc"), request.ResponseHeaders["Set-Cookie"], "expected the cookie to be redacted")
+	assert.JSONEq(t, `{"name":"test","password":"`+RedactedFingerprint("hunter2")+`"}`, request.RequestBody, "expected the password to be redacted")
+	assert.JSONEq(t, `{"keys":[{"keyName":"key1","value":"`+RedactedFingerprint("c2VjcmV0")+`","permissions":"FULL"}],"access_token":"`+RedactedFingerprint("opaque-token")+`"}`,
+		request.ResponseBody, "expected the storage account key and the token to be redacted")
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), request.Timestamp)
+}
+
+func TestDumpRPC(t *testing.T) {
+	out := &bytes.Buffer{}
+	cloudRequestDump = testCloudRequestDump(out)
+	defer func() { cloudRequestDump = nil }()
+
+	req := &iamadminpb.CreateServiceAccountKeyRequest{Name: "projects/test/serviceAccounts/test"}
+	reply := &iamadminpb.ServiceAccountKey{PrivateKeyData: []byte("secret")}
+	DumpRPC("gcp", "/google.iam.admin.v1.IAM/CreateServiceAccountKey", req, reply, nil, time.Second)
+
+	requests := decodeCloudRequests(t, out)
+	require.Len(t, requests, 1, "expected a single call to be dumped")
+	assert.Equal(t, "GRPC", requests[0].Method)
+	assert.Equal(t, "/google.iam.admin.v1.IAM/CreateServiceAccountKey", requests[0].URL)
+	assert.JSONEq(t, `{"name":"projects/test/serviceAccounts/test"}`, requests[0].RequestBody)
+	assert.JSONEq(t, `{"privateKeyData":"`+RedactedFingerprint("c2VjcmV0")+`"}`, requests[0].ResponseBody, "expected the private key to be redacted")
+	assert.Equal(t, int64(1000), requests[0].Duration)
+}
+
+func TestSanitizeBodyTruncated(t *testing.T) {
+	body := sanitizeBody([]byte(strings.Repeat("a", maxDumpedBodySize+10)))
+	assert.Equal(t, strings.Repeat("a", maxDumpedBodySize)+"... (10 bytes truncated)", body)
+}
+
+func TestInitCloudRequestDump(t *testing.T) {
+	defer func() { cloudRequestDump = nil }()
+	require.NoError(t, InitCloudRequestDump(""), "unexpected error")
+	assert.False(t, CloudRequestDumpEnabled(), "expected requests not to be dumped without a file")
+	assert.Equal(t, http.DefaultTransport, NewCloudRequestDumpTransport("gcp", http.DefaultTransport), "expected the transport not to be wrapped")
+
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	require.NoError(t, InitCloudRequestDump(path), "unexpected error")
+	assert.True(t, CloudRequestDumpEnabled(), "expected requests to be dumped")
+	CloseCloudRequestDump()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "expected the file to only be readable by its owner")
+}
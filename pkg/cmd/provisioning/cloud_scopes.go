@@ -0,0 +1,65 @@
+package provisioning
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	// DefaultMaxConcurrentClouds is the default number of cloud scopes processed concurrently, see InitMaxConcurrentClouds
+	DefaultMaxConcurrentClouds = 2
+)
+
+// maxConcurrentClouds is the value of --max-concurrent-clouds set by InitMaxConcurrentClouds
+var maxConcurrentClouds = DefaultMaxConcurrentClouds
+
+// InitMaxConcurrentClouds bounds the number of cloud scopes processed concurrently by a single run of ccoctl to max. A
+// cloud scope is either a cloud provider, eg. when creating the resources of several providers with ccoctl multicloud
+// create-all, or a subscription, project or account when deleting resources across several of them. The parallelism
+// within each scope, eg. tagging TaggingConcurrency resources at a time, is bounded separately since the rate limits of
+// cloud APIs apply within each scope.
+func InitMaxConcurrentClouds(max int) error {
+	if max < 1 {
+		return fmt.Errorf("invalid --max-concurrent-clouds %d, at least one cloud scope must be processed at a time", max)
+	}
+	maxConcurrentClouds = max
+	return nil
+}
+
+// RunCloudScopes calls run with the index of each of count cloud scopes, starting them in order with at most
+// --max-concurrent-clouds of them running at a time, and returns once every call has returned. Once a call has
+// returned false, the scopes which have not yet been started are not run. Whether each scope was run is returned.
+func RunCloudScopes(count int, run func(i int) bool) []bool {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		stopped bool
+		ran     = make([]bool, count)
+		slots   = make(chan struct{}, maxConcurrentClouds)
+	)
+	for i := 0; i < count; i++ {
+		slots <- struct{}{}
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			break
+		}
+		ran[i] = true
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// The slot is only released once stopped has been set so that no further scope is started after a failure,
+			// which makes a limit of one process the scopes strictly in turn
+			defer func() { <-slots }()
+			if !run(i) {
+				mu.Lock()
+				stopped = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return ran
+}
@@ -0,0 +1,85 @@
+package provisioning
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitMaxConcurrentClouds(t *testing.T) {
+	t.Cleanup(func() { maxConcurrentClouds = DefaultMaxConcurrentClouds })
+
+	assert.EqualError(t, InitMaxConcurrentClouds(0), "invalid --max-concurrent-clouds 0, at least one cloud scope must be processed at a time")
+	assert.Equal(t, DefaultMaxConcurrentClouds, maxConcurrentClouds, "expected the limit to be left unchanged")
+	require.NoError(t, InitMaxConcurrentClouds(3))
+	assert.Equal(t, 3, maxConcurrentClouds, "unexpected limit")
+}
+
+func TestRunCloudScopes(t *testing.T) {
+	t.Cleanup(func() { maxConcurrentClouds = DefaultMaxConcurrentClouds })
+
+	tests := []struct {
+		name                string
+		maxConcurrentClouds int
+		count               int
+		failing             int
+		expectedRan         []bool
+		expectedMaxRunning  int
+	}{
+		{
+			name:                "Scopes run up to the limit at a time",
+			maxConcurrentClouds: 2,
+			count:               5,
+			failing:             -1,
+			expectedRan:         []bool{true, true, true, true, true},
+			expectedMaxRunning:  2,
+		},
+		{
+			name:                "Limit above the number of scopes",
+			maxConcurrentClouds: 10,
+			count:               3,
+			failing:             -1,
+			expectedRan:         []bool{true, true, true},
+			expectedMaxRunning:  3,
+		},
+		{
+			name:                "Scopes not started are skipped after a failure",
+			maxConcurrentClouds: 1,
+			count:               3,
+			failing:             1,
+			expectedRan:         []bool{true, true, false},
+			expectedMaxRunning:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, InitMaxConcurrentClouds(test.maxConcurrentClouds))
+
+			var (
+				mu         sync.Mutex
+				running    int
+				maxRunning int
+			)
+			ran := RunCloudScopes(test.count, func(i int) bool {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				// Keep the scope running long enough for the others to be started concurrently
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return i != test.failing
+			})
+			assert.Equal(t, test.expectedRan, ran, "unexpected scopes run")
+			assert.Equal(t, test.expectedMaxRunning, maxRunning, "unexpected number of scopes running concurrently")
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package provisioning
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// configFileFlag is the flag providing the configuration file, which may not be set within the file itself
+const configFileFlag = "config"
+
+// ApplyConfigFile sets the flags of cmd, the command being run, which were not provided on the command line to the
+// values of the YAML configuration file at path.
+//
+// The file mirrors the tree of commands: every key is either the name of a subcommand, whose section holds the
+// defaults of the subcommand, or the name of a flag. The flags of a section apply to the command of the section and
+// to each of its subcommands which accept the flag, the flags of a subcommand's section taking precedence, eg.
+//
+//	timeout: 30m
+//	aws:
+//	  name: mycluster
+//	  region: us-east-1
+//	  create-all:
+//	    credentials-requests-dir: ./credrequests
+//
+// Lists set a flag once per item and mappings set a flag once per "key=value" pair. An error listing every unknown
+// key is returned so that misspelled keys are not silently ignored.
+func ApplyConfigFile(cmd *cobra.Command, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read configuration file %s", path)
+	}
+	config := map[string]interface{}{}
+	if err := sigsyaml.Unmarshal(data, &config); err != nil {
+		return errors.Wrapf(err, "failed to decode configuration file %s", path)
+	}
+
+	root := cmd.Root()
+	unknownKeys := []string{}
+	validateConfigSection(root, config, "", &unknownKeys)
+	if len(unknownKeys) > 0 {
+		sort.Strings(unknownKeys)
+		return fmt.Errorf("configuration file %s contains unknown keys: %s", path, strings.Join(unknownKeys, ", "))
+	}
+
+	// Values of the sections of the commands closest to cmd take precedence
+	values := map[string]interface{}{}
+	keys := map[string]string{}
+	section := config
+	prefix := ""
+	for _, command := range commandPath(cmd) {
+		if command != root {
+			subsection, _ := section[command.Name()].(map[string]interface{})
+			section = subsection
+			prefix += command.Name() + "."
+		}
+		for key, value := range section {
+			if !hasSubcommand(command, key) {
+				values[key] = value
+				keys[key] = prefix + key
+			}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		// Flags given on the command line override the configuration file, flags of other commands are ignored
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := setConfigFlag(cmd.Flags(), name, values[name]); err != nil {
+			return fmt.Errorf("invalid %s within configuration file %s: %w", keys[name], path, err)
+		}
+	}
+	return nil
+}
+
+// validateConfigSection records the keys of section which are neither subcommands of cmd nor flags accepted by cmd
+// or by any of its subcommands within unknownKeys
+func validateConfigSection(cmd *cobra.Command, section map[string]interface{}, prefix string, unknownKeys *[]string) {
+	for key, value := range section {
+		if subCmd := subcommand(cmd, key); subCmd != nil {
+			subsection, ok := value.(map[string]interface{})
+			if !ok {
+				*unknownKeys = append(*unknownKeys, fmt.Sprintf("%s%s (expected a section of the flags of %q)", prefix, key, subCmd.CommandPath()))
+				continue
+			}
+			validateConfigSection(subCmd, subsection, prefix+key+".", unknownKeys)
+			continue
+		}
+		if key == configFileFlag || !acceptsFlag(cmd, key) {
+			*unknownKeys = append(*unknownKeys, prefix+key)
+		}
+	}
+}
+
+// acceptsFlag returns true when cmd or any of its subcommands accepts the flag identified by name
+func acceptsFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil || cmd.InheritedFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, subCmd := range cmd.Commands() {
+		if acceptsFlag(subCmd, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// setConfigFlag sets the flag identified by name to value, once per item of a list or "key=value" pair of a mapping
+func setConfigFlag(flags *pflag.FlagSet, name string, value interface{}) error {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			s, err := configScalar(item)
+			if err != nil {
+				return err
+			}
+			if err := flags.Set(name, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s, err := configScalar(value[key])
+			if err != nil {
+				return err
+			}
+			if err := flags.Set(name, key+"="+s); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		s, err := configScalar(value)
+		if err != nil {
+			return err
+		}
+		return flags.Set(name, s)
+	}
+}
+
+// configScalar returns the flag value of a string, number or boolean of the configuration file
+func configScalar(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported value %v, expected a string, number or boolean", value)
+	}
+}
+
+// commandPath returns the commands from the root command down to cmd
+func commandPath(cmd *cobra.Command) []*cobra.Command {
+	path := []*cobra.Command{}
+	for c := cmd; c != nil; c = c.Parent() {
+		path = append([]*cobra.Command{c}, path...)
+	}
+	return path
+}
+
+// subcommand returns the subcommand of cmd named name, or nil
+func subcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, subCmd := range cmd.Commands() {
+		if subCmd.Name() == name {
+			return subCmd
+		}
+	}
+	return nil
+}
+
+// hasSubcommand returns true when cmd has a subcommand named name
+func hasSubcommand(cmd *cobra.Command, name string) bool {
+	return subcommand(cmd, name) != nil
+}
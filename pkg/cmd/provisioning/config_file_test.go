@@ -0,0 +1,202 @@
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfigFlags struct {
+	timeout         time.Duration
+	name            string
+	region          string
+	credReqDir      string
+	dryRun          bool
+	extraAudiences  []string
+	userTags        map[string]string
+	subscriptionID  string
+	createAllRegion string
+}
+
+func testConfigCommands(flags *testConfigFlags) (*cobra.Command, *cobra.Command) {
+	rootCmd := &cobra.Command{Use: "ccoctl"}
+	rootCmd.PersistentFlags().DurationVar(&flags.timeout, "timeout", 0, "Timeout")
+
+	awsCmd := &cobra.Command{Use: "aws"}
+	createAllCmd := &cobra.Command{Use: "create-all", Run: func(cmd *cobra.Command, args []string) {}}
+	createAllCmd.PersistentFlags().StringVar(&flags.name, "name", "", "Name")
+	createAllCmd.MarkPersistentFlagRequired("name")
+	createAllCmd.PersistentFlags().StringVar(&flags.region, "region", "", "Region")
+	createAllCmd.PersistentFlags().StringVar(&flags.credReqDir, "credentials-requests-dir", "", "CredentialsRequests directory")
+	createAllCmd.PersistentFlags().BoolVar(&flags.dryRun, "dry-run", false, "Dry run")
+	createAllCmd.PersistentFlags().StringSliceVar(&flags.extraAudiences, "oidc-extra-audience", []string{}, "Extra audiences")
+	createAllCmd.PersistentFlags().StringToStringVar(&flags.userTags, "user-tags", map[string]string{}, "User tags")
+	awsCmd.AddCommand(createAllCmd)
+
+	azureCmd := &cobra.Command{Use: "azure"}
+	azureCreateAllCmd := &cobra.Command{Use: "create-all", Run: func(cmd *cobra.Command, args []string) {}}
+	azureCreateAllCmd.PersistentFlags().StringVar(&flags.subscriptionID, "subscription-id", "", "Subscription ID")
+	azureCreateAllCmd.PersistentFlags().StringVar(&flags.createAllRegion, "region", "", "Region")
+	azureCmd.AddCommand(azureCreateAllCmd)
+
+	rootCmd.AddCommand(awsCmd, azureCmd)
+	return rootCmd, createAllCmd
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		args        []string
+		expectError string
+		verify      func(t *testing.T, flags *testConfigFlags)
+	}{
+		{
+			name: "Flags of every section of the command applied",
+			config: `
+timeout: 30m
+aws:
+  region: us-east-1
+  create-all:
+    name: test-name
+    dry-run: true
+    oidc-extra-audience:
+    - sts.amazonaws.com
+    - openshift
+    user-tags:
+      team: platform
+      env: test
+azure:
+  create-all:
+    region: westus
+`,
+			args: []string{"aws", "create-all"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Equal(t, 30*time.Minute, flags.timeout)
+				assert.Equal(t, "test-name", flags.name)
+				assert.Equal(t, "us-east-1", flags.region)
+				assert.True(t, flags.dryRun)
+				assert.Equal(t, []string{"sts.amazonaws.com", "openshift"}, flags.extraAudiences)
+				assert.Equal(t, map[string]string{"team": "platform", "env": "test"}, flags.userTags)
+				assert.Empty(t, flags.createAllRegion, "expected the flags of other commands to be left unset")
+			},
+		},
+		{
+			name: "Flags of the command line override the configuration file",
+			config: `
+aws:
+  create-all:
+    name: config-name
+    region: us-east-1
+`,
+			args: []string{"aws", "create-all", "--name", "cli-name"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Equal(t, "cli-name", flags.name)
+				assert.Equal(t, "us-east-1", flags.region)
+			},
+		},
+		{
+			name: "Sections of subcommands take precedence",
+			config: `
+aws:
+  region: us-east-1
+  create-all:
+    region: eu-west-1
+    name: test-name
+`,
+			args: []string{"aws", "create-all"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Equal(t, "eu-west-1", flags.region)
+			},
+		},
+		{
+			name: "Unknown keys reported",
+			config: `
+aws:
+  create-all:
+    regoin: us-east-1
+    name: test-name
+gcp:
+  name: test-name
+`,
+			args:        []string{"aws", "create-all"},
+			expectError: "contains unknown keys: aws.create-all.regoin, gcp",
+		},
+		{
+			name: "Flag of another provider reported",
+			config: `
+aws:
+  subscription-id: 00000000-0000-0000-0000-000000000000
+`,
+			args:        []string{"aws", "create-all"},
+			expectError: "contains unknown keys: aws.subscription-id",
+		},
+		{
+			name: "Configuration file key rejected",
+			config: `
+config: other.yaml
+`,
+			args:        []string{"aws", "create-all"},
+			expectError: "contains unknown keys: config",
+		},
+		{
+			name: "Command without a section rejected",
+			config: `
+aws: us-east-1
+`,
+			args:        []string{"aws", "create-all"},
+			expectError: "contains unknown keys: aws (expected a section of the flags of \"ccoctl aws\")",
+		},
+		{
+			name: "Invalid value reported",
+			config: `
+aws:
+  create-all:
+    dry-run: sometimes
+`,
+			args:        []string{"aws", "create-all"},
+			expectError: "invalid aws.create-all.dry-run",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ccoctl.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.config), 0600))
+
+			flags := &testConfigFlags{}
+			rootCmd, _ := testConfigCommands(flags)
+			cmd, args, err := rootCmd.Find(test.args)
+			require.NoError(t, err)
+			require.NoError(t, cmd.ParseFlags(args))
+
+			err = ApplyConfigFile(cmd, path)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError)
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			test.verify(t, flags)
+		})
+	}
+}
+
+func TestApplyConfigFileRequiredFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ccoctl.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("aws:\n  create-all:\n    name: test-name\n"), 0600))
+
+	flags := &testConfigFlags{}
+	_, createAllCmd := testConfigCommands(flags)
+	require.NoError(t, createAllCmd.ParseFlags([]string{}))
+	require.Error(t, createAllCmd.ValidateRequiredFlags(), "expected the required flag to be missing")
+
+	require.NoError(t, ApplyConfigFile(createAllCmd, path))
+	assert.NoError(t, createAllCmd.ValidateRequiredFlags(), "expected the required flag to be provided by the configuration file")
+	assert.Equal(t, "test-name", flags.name)
+}
@@ -13,6 +13,8 @@ const (
 	ManifestsDirName = "manifests"
 	// TLSDirName is the name of the directory to save bound service account signing key created by ccoctl
 	TLSDirName = "tls"
+	// BoundSAKeyFile is the name of the copy of the private key within the tls directory which is used by the installer
+	BoundSAKeyFile = "bound-service-account-signing-key.key"
 	// OidcTokenPath is the path where oidc token is stored in the pod
 	OidcTokenPath = "/var/run/secrets/openshift/serviceaccount/token"
 	// DiscoveryDocumentTemplate is a template of the discovery document that needs to be populated with appropriate values
@@ -40,6 +42,21 @@ const (
 	// featureGateAnnotation is the annotation used to indicate that a specific manifest is hidden behind a feature gate.
 	featureGateAnnotation = "release.openshift.io/feature-set"
 
+	// credentialsRequestKind is the kind of the CredentialsRequest manifests consumed by ccoctl
+	credentialsRequestKind = "CredentialsRequest"
+
 	// deletionAnnotation is the annotation used to tell the CVO that a resource should be deleted
 	deletionAnnotation = "release.openshift.io/delete"
+
+	// CredentialsSourceStdin is the credentials source which reads credentials from stdin
+	CredentialsSourceStdin = "-"
+	// CredentialsSourceEnvPrefix prefixes the name of the environment variable of a credentials source
+	// which reads credentials from the environment, eg. "env:NUTANIX_CREDENTIALS"
+	CredentialsSourceEnvPrefix = "env:"
+
+	// ClusterResourceTagValue is the value of the tag identifying the OpenShift cluster which owns a cloud resource
+	ClusterResourceTagValue = "owned"
+	// maxClusterIDLength is the maximum length of a cluster ID such that the "kubernetes.io/cluster/<cluster ID>"
+	// tag key fits within the 128 character limit of AWS tag keys
+	maxClusterIDLength = 106
 )
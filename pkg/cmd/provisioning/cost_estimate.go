@@ -0,0 +1,144 @@
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+const (
+	// SkipCostEstimateFlag is the flag of the create-all commands skipping the estimate of the ongoing cost of the
+	// resources to be created
+	SkipCostEstimateFlag = "skip-cost-estimate"
+	// SkipCostEstimateFlagUsage describes SkipCostEstimateFlag
+	SkipCostEstimateFlagUsage = "Skip logging a rough estimate of the monthly cost of the resources to be created, based on the approximate prices bundled with ccoctl, before creating any resources"
+
+	// anyRegion keys the prices of a PriceTable which apply to the regions without prices of their own
+	anyRegion = "*"
+)
+
+// PriceTable holds the estimated monthly prices in USD of the types of resources created by ccoctl, eg. "S3Bucket",
+// keyed by region. The prices of the "*" region apply to the regions without a price of their own for a type.
+type PriceTable map[string]map[string]float64
+
+// price returns the monthly price of a resource of resourceType within region, and false when it is not known
+func (p PriceTable) price(region, resourceType string) (float64, bool) {
+	if price, found := p[region][resourceType]; found {
+		return price, true
+	}
+	price, found := p[anyRegion][resourceType]
+	return price, found
+}
+
+// priceTables are the price tables bundled with ccoctl keyed by cloud provider. The prices are rough estimates of the
+// storage and requests of an OIDC issuer serving a discovery document and a JSON web key set, which are only fetched
+// when tokens are exchanged, and of the resources which are not charged for, such as identities and roles.
+var priceTables = map[string]PriceTable{
+	"aws": {
+		anyRegion: {
+			"S3Bucket":                       0.01,
+			"CloudFrontDistribution":         0.05,
+			"CloudFrontOriginAccessIdentity": 0,
+			"IAMIdentityProvider":            0,
+			"IAMRole":                        0,
+		},
+		"sa-east-1": {
+			"S3Bucket": 0.02,
+		},
+	},
+	"azure": {
+		anyRegion: {
+			"ResourceGroup":               0,
+			"StorageAccount":              0.05,
+			"BlobContainer":               0,
+			"UserAssignedManagedIdentity": 0,
+			"FederatedIdentityCredential": 0,
+			"RoleAssignment":              0,
+		},
+		"brazilsouth": {
+			"StorageAccount": 0.10,
+		},
+	},
+	"gcp": {
+		anyRegion: {
+			"StorageBucket":            0.01,
+			"WorkloadIdentityPool":     0,
+			"WorkloadIdentityProvider": 0,
+			"IAMServiceAccount":        0,
+			"IAMCustomRole":            0,
+		},
+	},
+}
+
+// CostEstimateLine is the estimated monthly cost of the resources of a type to be created
+type CostEstimateLine struct {
+	// Type is the type of the resources, eg. "IAMRole"
+	Type string
+	// Count is the number of resources of Type to be created
+	Count int
+	// MonthlyCost is the estimated monthly cost in USD of the resources, zero when Priced is false
+	MonthlyCost float64
+	// Priced is false when the price table has no price for Type
+	Priced bool
+}
+
+// CostEstimate is the estimated monthly cost of the resources to be created within a region
+type CostEstimate struct {
+	// Region is the region of the resources, whose prices are used when the price table has prices of its own
+	Region string
+	// Lines are the costs of the resources of each type, ordered by type
+	Lines []CostEstimateLine
+	// MonthlyTotal is the estimated monthly cost in USD of the resources whose type is priced
+	MonthlyTotal float64
+}
+
+// EstimateCost estimates the monthly cost of the resources to be created within region with prices, counts holding
+// the number of resources of each type to be created
+func EstimateCost(prices PriceTable, region string, counts map[string]int) CostEstimate {
+	estimate := CostEstimate{Region: region}
+	for resourceType, count := range counts {
+		price, priced := prices.price(region, resourceType)
+		line := CostEstimateLine{Type: resourceType, Count: count, Priced: priced}
+		if priced {
+			line.MonthlyCost = price * float64(count)
+			estimate.MonthlyTotal += line.MonthlyCost
+		}
+		estimate.Lines = append(estimate.Lines, line)
+	}
+	sort.Slice(estimate.Lines, func(i, j int) bool { return estimate.Lines[i].Type < estimate.Lines[j].Type })
+	return estimate
+}
+
+// table returns the lines of a table listing the number and the estimated monthly cost of the resources of each type,
+// followed by their total
+func (e CostEstimate) table() []string {
+	width := len("TOTAL")
+	total := 0
+	for _, line := range e.Lines {
+		if len(line.Type) > width {
+			width = len(line.Type)
+		}
+		total += line.Count
+	}
+
+	lines := []string{fmt.Sprintf("%-*s  %5s  %s", width, "TYPE", "COUNT", "MONTHLY (USD)")}
+	for _, line := range e.Lines {
+		cost := "unknown"
+		if line.Priced {
+			cost = fmt.Sprintf("%.2f", line.MonthlyCost)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %5d  %s", width, line.Type, line.Count, cost))
+	}
+	return append(lines, fmt.Sprintf("%-*s  %5d  %.2f", width, "TOTAL", total, e.MonthlyTotal))
+}
+
+// PrintCostEstimate logs the estimated monthly cost of the resources to be created on provider within region with
+// the price table bundled with ccoctl, counts holding the number of resources of each type to be created
+func PrintCostEstimate(provider, region string, counts map[string]int) {
+	estimate := EstimateCost(priceTables[provider], region, counts)
+	log.Printf("Estimated monthly cost of the resources to be created within %s, a rough estimate based on approximate prices "+
+		"which exclude taxes, data transfer and free tiers, actual costs depend on usage (--%s to skip):", region, SkipCostEstimateFlag)
+	for _, line := range estimate.table() {
+		log.Printf("  %s", line)
+	}
+}
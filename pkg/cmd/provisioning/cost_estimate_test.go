@@ -0,0 +1,99 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPriceTable = PriceTable{
+	anyRegion: {
+		"Bucket":   0.5,
+		"Identity": 0,
+	},
+	"expensive-region": {
+		"Bucket": 2,
+	},
+}
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name           string
+		region         string
+		counts         map[string]int
+		expectEstimate CostEstimate
+	}{
+		{
+			name:   "Prices of any region",
+			region: "test-region",
+			counts: map[string]int{"Identity": 12, "Bucket": 2},
+			expectEstimate: CostEstimate{
+				Region: "test-region",
+				Lines: []CostEstimateLine{
+					{Type: "Bucket", Count: 2, MonthlyCost: 1, Priced: true},
+					{Type: "Identity", Count: 12, MonthlyCost: 0, Priced: true},
+				},
+				MonthlyTotal: 1,
+			},
+		},
+		{
+			name:   "Prices of the region take precedence",
+			region: "expensive-region",
+			counts: map[string]int{"Identity": 12, "Bucket": 2},
+			expectEstimate: CostEstimate{
+				Region: "expensive-region",
+				Lines: []CostEstimateLine{
+					{Type: "Bucket", Count: 2, MonthlyCost: 4, Priced: true},
+					{Type: "Identity", Count: 12, MonthlyCost: 0, Priced: true},
+				},
+				MonthlyTotal: 4,
+			},
+		},
+		{
+			name:   "Unpriced types excluded from the total",
+			region: "test-region",
+			counts: map[string]int{"Bucket": 1, "Vault": 1},
+			expectEstimate: CostEstimate{
+				Region: "test-region",
+				Lines: []CostEstimateLine{
+					{Type: "Bucket", Count: 1, MonthlyCost: 0.5, Priced: true},
+					{Type: "Vault", Count: 1, Priced: false},
+				},
+				MonthlyTotal: 0.5,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectEstimate, EstimateCost(testPriceTable, test.region, test.counts))
+		})
+	}
+}
+
+func TestCostEstimateTable(t *testing.T) {
+	estimate := EstimateCost(testPriceTable, "test-region", map[string]int{"Identity": 12, "Bucket": 2, "Vault": 1})
+	assert.Equal(t, []string{
+		"TYPE      COUNT  MONTHLY (USD)",
+		"Bucket        2  1.00",
+		"Identity     12  0.00",
+		"Vault         1  unknown",
+		"TOTAL        15  1.00",
+	}, estimate.table())
+}
+
+func TestBundledPriceTables(t *testing.T) {
+	for provider, prices := range priceTables {
+		_, found := prices[anyRegion]
+		assert.True(t, found, "the price table of %s should provide the prices of any region", provider)
+		for region, regionPrices := range prices {
+			for resourceType, price := range regionPrices {
+				assert.GreaterOrEqual(t, price, 0.0, "the price of %s within %s of %s should not be negative", resourceType, region, provider)
+				if region != anyRegion {
+					_, found := prices[anyRegion][resourceType]
+					assert.True(t, found, "%s priced within %s of %s should be priced within any region", resourceType, region, provider)
+				}
+			}
+		}
+	}
+}
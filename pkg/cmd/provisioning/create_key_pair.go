@@ -14,8 +14,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const boundSAKeyFilename = "bound-service-account-signing-key.key"
-
 type options struct {
 	TargetDir string
 }
@@ -28,21 +26,28 @@ var (
 	}
 )
 
+// CreateKeys generates the RSA key pair signing service account tokens within prefixDir, of DefaultSigningKeySize bits
+// unless --signing-key-size is provided. An existing key pair is reused once it has been validated as a signing key.
 func CreateKeys(prefixDir string) error {
 
 	privateKeyFilePath := filepath.Join(prefixDir, PrivateKeyFile)
 	publicKeyFilePath := filepath.Join(prefixDir, PublicKeyFile)
-	bitSize := 4096
-
-	defer copyPrivateKeyForInstaller(privateKeyFilePath, prefixDir)
+	bitSize := generatedSigningKeySize()
 
 	_, err := os.Stat(privateKeyFilePath)
 	if err == nil {
-		log.Printf("Using existing RSA keypair found at %s", privateKeyFilePath)
+		existingBitSize, err := validatePrivateKeyFile(privateKeyFilePath)
+		if err != nil {
+			return err
+		}
+		log.Printf("Using existing %d-bit RSA keypair found at %s", existingBitSize, privateKeyFilePath)
+		copyPrivateKeyForInstaller(privateKeyFilePath, prefixDir)
 		return nil
 	}
 
-	log.Print("Generating RSA keypair")
+	defer copyPrivateKeyForInstaller(privateKeyFilePath, prefixDir)
+
+	log.Printf("Generating %d-bit RSA keypair", bitSize)
 	privateKey, err := rsa.GenerateKey(rand.Reader, bitSize)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate private key")
@@ -89,7 +94,7 @@ func CreateKeys(prefixDir string) error {
 }
 
 func copyPrivateKeyForInstaller(sourceFile, prefixDir string) {
-	privateKeyForInstaller := filepath.Join(prefixDir, TLSDirName, boundSAKeyFilename)
+	privateKeyForInstaller := filepath.Join(prefixDir, TLSDirName, BoundSAKeyFile)
 
 	log.Print("Copying signing key for use by installer")
 	from, err := os.Open(sourceFile)
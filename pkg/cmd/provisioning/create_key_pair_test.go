@@ -1,6 +1,8 @@
 package provisioning
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"io/ioutil"
@@ -15,6 +17,7 @@ import (
 const keypairTestDirPrefix = "keypairtestdir"
 
 func TestKeyPair(t *testing.T) {
+	existingPrivateKey := testPrivateKeyPEM(t, 2048)
 
 	tests := []struct {
 		name        string
@@ -28,7 +31,7 @@ func TestKeyPair(t *testing.T) {
 			setup: func(t *testing.T) string {
 				tempDirName := prepTempDir(t)
 
-				err := ioutil.WriteFile(filepath.Join(tempDirName, PrivateKeyFile), []byte("some data"), 0600)
+				err := ioutil.WriteFile(filepath.Join(tempDirName, PrivateKeyFile), existingPrivateKey, 0600)
 				require.NoError(t, err, "errored while setting up environment for test")
 
 				return tempDirName
@@ -40,13 +43,37 @@ func TestKeyPair(t *testing.T) {
 				fileData, err := ioutil.ReadFile(filepath.Join(tempDirName, PrivateKeyFile))
 				require.NoError(t, err, "unexpected error reading in test private key data")
 
-				assert.Equal(t, []byte("some data"), fileData, "unexpected change in test private key data")
+				assert.Equal(t, existingPrivateKey, fileData, "unexpected change in test private key data")
+
+				tlsFileData, err := ioutil.ReadFile(filepath.Join(tempDirName, TLSDirName, BoundSAKeyFile))
+				require.NoError(t, err, "unexpected error reading in copied file %s/%s", TLSDirName, BoundSAKeyFile)
+
+				assert.Equal(t, existingPrivateKey, tlsFileData, "unexpected file contents for %s/%s", TLSDirName, BoundSAKeyFile)
+			},
+		},
+		{
+			name: "weak private key file exists",
+			setup: func(t *testing.T) string {
+				tempDirName := prepTempDir(t)
+
+				err := ioutil.WriteFile(filepath.Join(tempDirName, PrivateKeyFile), testPrivateKeyPEM(t, 1024), 0600)
+				require.NoError(t, err, "errored while setting up environment for test")
+
+				return tempDirName
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid private key file exists",
+			setup: func(t *testing.T) string {
+				tempDirName := prepTempDir(t)
 
-				tlsFileData, err := ioutil.ReadFile(filepath.Join(tempDirName, TLSDirName, boundSAKeyFilename))
-				require.NoError(t, err, "unexpected error reading in copied file %s/%s", TLSDirName, boundSAKeyFilename)
+				err := ioutil.WriteFile(filepath.Join(tempDirName, PrivateKeyFile), []byte("some data"), 0600)
+				require.NoError(t, err, "errored while setting up environment for test")
 
-				assert.Equal(t, []byte("some data"), tlsFileData, "unexpected file contents for %s/%s", TLSDirName, boundSAKeyFilename)
+				return tempDirName
 			},
+			expectError: true,
 		},
 		{
 			name: "generate keys",
@@ -70,6 +97,7 @@ func TestKeyPair(t *testing.T) {
 
 				err = privKey.Validate()
 				require.Nil(t, err, "private key failed validation")
+				assert.Equal(t, DefaultSigningKeySize, privKey.N.BitLen(), "unexpected size of the generated key")
 
 				calculatedPubKey, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
 				require.NoError(t, err, "unexpected error marshaling public key from test private key")
@@ -82,10 +110,10 @@ func TestKeyPair(t *testing.T) {
 
 				assert.Equal(t, pubFileBytes, calculatedPubKeyBytes, "Missmatch between written public key file and caluclated public key (from private key)")
 
-				tlsFileData, err := ioutil.ReadFile(filepath.Join(tempDirName, TLSDirName, boundSAKeyFilename))
-				require.NoError(t, err, "unexpected error reading in copied file %s/%s", TLSDirName, boundSAKeyFilename)
+				tlsFileData, err := ioutil.ReadFile(filepath.Join(tempDirName, TLSDirName, BoundSAKeyFile))
+				require.NoError(t, err, "unexpected error reading in copied file %s/%s", TLSDirName, BoundSAKeyFile)
 
-				assert.Equal(t, privFile, tlsFileData, "unexpected file contents for %s/%s", TLSDirName, boundSAKeyFilename)
+				assert.Equal(t, privFile, tlsFileData, "unexpected file contents for %s/%s", TLSDirName, BoundSAKeyFile)
 
 			},
 		},
@@ -108,6 +136,13 @@ func TestKeyPair(t *testing.T) {
 	}
 }
 
+// testPrivateKeyPEM generates a PKCS #1 PEM encoded RSA private key of bits
+func testPrivateKeyPEM(t *testing.T, bits int) []byte {
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err, "failed to generate test private key")
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+}
+
 func prepTempDir(t *testing.T) string {
 	tempDirName, err := ioutil.TempDir(os.TempDir(), keypairTestDirPrefix)
 
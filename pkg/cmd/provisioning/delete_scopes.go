@@ -0,0 +1,130 @@
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ScopeDeletion is the outcome of deleting the resources within a single scope, eg. an Azure subscription, a
+// Google cloud project or an AWS account
+type ScopeDeletion struct {
+	Scope string
+	// Err is the error with which deletion within the scope failed, if any
+	Err error
+	// Skipped is true when deletion within the scope was not attempted because deletion within another scope failed
+	// before it was started
+	Skipped bool
+}
+
+// UniqueScopes returns the scopes provided to flagName with surrounding whitespace removed and duplicates dropped, in
+// the order they were first provided. An error is returned when no scope or an empty scope was provided.
+func UniqueScopes(flagName string, scopes []string) ([]string, error) {
+	unique := make([]string, 0, len(scopes))
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			return nil, errors.Errorf("invalid %s, values may not be empty", flagName)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, errors.Errorf("%s must be provided", flagName)
+	}
+	return unique, nil
+}
+
+// DeleteAcrossScopes runs deleteScope for each of scopes, running up to --max-concurrent-clouds of them concurrently
+// with RunCloudScopes. Unless continueOnError is provided the scopes not yet started are skipped after deletion within a
+// scope fails. A summary of the outcome within each scope, described by scopeKind eg. "subscription", is logged once
+// every scope has been processed and an error naming every scope within which deletion failed is returned.
+func DeleteAcrossScopes(scopeKind string, scopes []string, continueOnError bool, deleteScope func(scope string) error) error {
+	errs := make([]error, len(scopes))
+	ran := RunCloudScopes(len(scopes), func(i int) bool {
+		if len(scopes) > 1 {
+			log.Printf("Deleting resources within %s %s", scopeKind, scopes[i])
+		}
+		errs[i] = deleteScope(scopes[i])
+		if errs[i] != nil {
+			log.Printf("Failed to delete resources within %s %s: %s", scopeKind, scopes[i], errs[i])
+			return continueOnError
+		}
+		return true
+	})
+
+	// The outcomes are reported in the order the scopes were provided, whichever order deletion completed in
+	deletions := make([]ScopeDeletion, 0, len(scopes))
+	for i, scope := range scopes {
+		deletions = append(deletions, ScopeDeletion{Scope: scope, Err: errs[i], Skipped: !ran[i]})
+	}
+
+	if len(scopes) > 1 {
+		for _, line := range SummarizeScopeDeletions(scopeKind, deletions) {
+			log.Print(line)
+		}
+	}
+	return ScopeDeletionsError(scopeKind, deletions)
+}
+
+// SummarizeScopeDeletions returns a line describing the outcome of deletion within each scope
+func SummarizeScopeDeletions(scopeKind string, deletions []ScopeDeletion) []string {
+	summary := make([]string, 0, len(deletions))
+	for _, deletion := range deletions {
+		switch {
+		case deletion.Skipped:
+			summary = append(summary, fmt.Sprintf("%s %s: skipped after a previous failure, use --continue-on-error to process every %s", scopeKind, deletion.Scope, scopeKind))
+		case deletion.Err != nil:
+			summary = append(summary, fmt.Sprintf("%s %s: failed: %s", scopeKind, deletion.Scope, deletion.Err))
+		default:
+			summary = append(summary, fmt.Sprintf("%s %s: succeeded", scopeKind, deletion.Scope))
+		}
+	}
+	return summary
+}
+
+// ScopeDeletionsError returns an error naming the scopes within which deletion failed or was skipped, or nil when
+// deletion succeeded within every scope
+func ScopeDeletionsError(scopeKind string, deletions []ScopeDeletion) error {
+	failed := []string{}
+	skipped := []string{}
+	for _, deletion := range deletions {
+		if deletion.Skipped {
+			skipped = append(skipped, deletion.Scope)
+		} else if deletion.Err != nil {
+			failed = append(failed, deletion.Scope)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(deletions) == 1 {
+		return deletions[0].Err
+	}
+	message := fmt.Sprintf("failed to delete resources within %d of %d %ss: %s", len(failed), len(deletions), scopeKind, strings.Join(failed, ", "))
+	if len(skipped) > 0 {
+		message += fmt.Sprintf(", skipped %s", strings.Join(skipped, ", "))
+	}
+	return errors.New(message)
+}
+
+// JoinDeletionErrors returns an error combining the errors encountered while deleting the resources within a scope,
+// or nil when there are none
+func JoinDeletionErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
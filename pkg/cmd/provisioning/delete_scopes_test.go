@@ -0,0 +1,136 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueScopes(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      []string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "Single scope",
+			scopes:   []string{"one"},
+			expected: []string{"one"},
+		},
+		{
+			name:     "Duplicate scopes are dropped",
+			scopes:   []string{"one", " two", "one", "two "},
+			expected: []string{"one", "two"},
+		},
+		{
+			name:        "No scopes",
+			scopes:      nil,
+			expectError: true,
+		},
+		{
+			name:        "Empty scope",
+			scopes:      []string{"one", " "},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scopes, err := UniqueScopes("--test", test.scopes)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expected, scopes, "unexpected scopes")
+			}
+		})
+	}
+}
+
+func TestDeleteAcrossScopes(t *testing.T) {
+	tests := []struct {
+		name             string
+		scopes           []string
+		failingScopes    []string
+		continueOnError  bool
+		expectedDeleted  []string
+		expectedErrorMsg string
+	}{
+		{
+			name:            "Every scope succeeds",
+			scopes:          []string{"one", "two", "three"},
+			expectedDeleted: []string{"one", "two", "three"},
+		},
+		{
+			name:             "Remaining scopes are skipped after a failure",
+			scopes:           []string{"one", "two", "three"},
+			failingScopes:    []string{"two"},
+			expectedDeleted:  []string{"one", "two"},
+			expectedErrorMsg: "failed to delete resources within 1 of 3 subscriptions: two, skipped three",
+		},
+		{
+			name:             "Remaining scopes are processed with continue on error",
+			scopes:           []string{"one", "two", "three"},
+			failingScopes:    []string{"one", "two"},
+			continueOnError:  true,
+			expectedDeleted:  []string{"one", "two", "three"},
+			expectedErrorMsg: "failed to delete resources within 2 of 3 subscriptions: one, two",
+		},
+		{
+			name:             "Error of a single scope is returned as is",
+			scopes:           []string{"one"},
+			failingScopes:    []string{"one"},
+			expectedDeleted:  []string{"one"},
+			expectedErrorMsg: "failed within one",
+		},
+	}
+
+	// The scopes are processed strictly in turn with --max-concurrent-clouds=1
+	require.NoError(t, InitMaxConcurrentClouds(1))
+	t.Cleanup(func() { maxConcurrentClouds = DefaultMaxConcurrentClouds })
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deleted := []string{}
+			err := DeleteAcrossScopes("subscription", test.scopes, test.continueOnError, func(scope string) error {
+				deleted = append(deleted, scope)
+				for _, failingScope := range test.failingScopes {
+					if scope == failingScope {
+						return errors.Errorf("failed within %s", scope)
+					}
+				}
+				return nil
+			})
+			assert.Equal(t, test.expectedDeleted, deleted, "unexpected scopes processed")
+			if test.expectedErrorMsg != "" {
+				require.Error(t, err, "expected error")
+				assert.Equal(t, test.expectedErrorMsg, err.Error(), "unexpected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestSummarizeScopeDeletions(t *testing.T) {
+	summary := SummarizeScopeDeletions("project", []ScopeDeletion{
+		{Scope: "one"},
+		{Scope: "two", Err: errors.New("access denied")},
+		{Scope: "three", Skipped: true},
+	})
+	assert.Equal(t, []string{
+		"project one: succeeded",
+		"project two: failed: access denied",
+		"project three: skipped after a previous failure, use --continue-on-error to process every project",
+	}, summary, "unexpected summary")
+}
+
+func TestJoinDeletionErrors(t *testing.T) {
+	assert.NoError(t, JoinDeletionErrors(nil), "expected no error without errors")
+	single := errors.New("one")
+	assert.Equal(t, single, JoinDeletionErrors([]error{single}), "expected a single error to be returned as is")
+	assert.EqualError(t, JoinDeletionErrors([]error{single, errors.New("two")}), "one; two", "unexpected joined error")
+}
@@ -0,0 +1,53 @@
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// VerifyAfterDeleteFlag is the flag of the delete commands requesting a verification that no resources remain
+	// once deletion completes
+	VerifyAfterDeleteFlag = "verify-after-delete"
+	// VerifyAfterDeleteFlagUsage describes VerifyAfterDeleteFlag
+	VerifyAfterDeleteFlagUsage = "Once deletion completes, list the resources created by ccoctl which remain within the scope of the deletion, " +
+		"allowing a short window for deletions which were accepted but not yet applied, and fail if any remain"
+)
+
+var (
+	// verifyAfterDeleteWindow is the time within which the deletions accepted by a cloud API are expected to be
+	// applied, remaining resources are listed again until it elapses
+	verifyAfterDeleteWindow = 30 * time.Second
+	// verifyAfterDeleteInterval is the time waited before listing remaining resources again
+	verifyAfterDeleteInterval = 5 * time.Second
+)
+
+// VerifyDeletion lists the resources which remain after deletion with listRemaining, which must only list the
+// resources the deletion was scoped to so that resources skipped on purpose, eg. by --cluster-id, are not reported.
+// Cloud APIs may accept a deletion before it is applied, so remaining resources are listed again until none remain
+// or verifyAfterDeleteWindow elapses. An error naming the remaining resources is returned.
+func VerifyDeletion(listRemaining func() ([]string, error)) error {
+	deadline := time.Now().Add(verifyAfterDeleteWindow)
+	for {
+		remaining, err := listRemaining()
+		if err != nil {
+			return errors.Wrap(err, "failed to list the resources remaining after deletion")
+		}
+		if len(remaining) == 0 {
+			log.Print("Verified that no resources remain after deletion")
+			return nil
+		}
+		if time.Now().Add(verifyAfterDeleteInterval).After(deadline) {
+			// Resources are listed concurrently by some providers, they are reported by type then name
+			sort.Strings(remaining)
+			return fmt.Errorf("%d resources remain after deletion:\n  %s", len(remaining), strings.Join(remaining, "\n  "))
+		}
+		log.Printf("%d resources remain after deletion, listing them again in %s", len(remaining), verifyAfterDeleteInterval)
+		time.Sleep(verifyAfterDeleteInterval)
+	}
+}
@@ -0,0 +1,72 @@
+package provisioning
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyDeletion(t *testing.T) {
+	defaultWindow, defaultInterval := verifyAfterDeleteWindow, verifyAfterDeleteInterval
+	defer func() { verifyAfterDeleteWindow, verifyAfterDeleteInterval = defaultWindow, defaultInterval }()
+	verifyAfterDeleteWindow, verifyAfterDeleteInterval = 50*time.Millisecond, 10*time.Millisecond
+
+	tests := []struct {
+		name        string
+		listings    [][]string
+		listErr     error
+		expectCalls int
+		expectError string
+	}{
+		{
+			name:        "No resources remain",
+			listings:    [][]string{nil},
+			expectCalls: 1,
+		},
+		{
+			name:        "Deletion applied within the window",
+			listings:    [][]string{{"IAM Role test-role"}, {"IAM Role test-role"}, nil},
+			expectCalls: 3,
+		},
+		{
+			name:        "Resources remain once the window elapsed",
+			listings:    [][]string{{"IAM Role test-role", "S3 bucket test-oidc"}},
+			expectError: "2 resources remain after deletion:\n  IAM Role test-role\n  S3 bucket test-oidc",
+		},
+		{
+			name:        "Failure listing remaining resources",
+			listErr:     errors.New("access denied"),
+			expectCalls: 1,
+			expectError: "failed to list the resources remaining after deletion: access denied",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			err := VerifyDeletion(func() ([]string, error) {
+				calls++
+				if test.listErr != nil {
+					return nil, test.listErr
+				}
+				// The last listing is repeated once every listing was returned
+				if calls > len(test.listings) {
+					return test.listings[len(test.listings)-1], nil
+				}
+				return test.listings[calls-1], nil
+			})
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Equal(t, test.expectError, err.Error(), "unexpected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+			if test.expectCalls != 0 {
+				assert.Equal(t, test.expectCalls, calls, "unexpected number of listings")
+			}
+		})
+	}
+}
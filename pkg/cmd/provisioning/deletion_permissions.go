@@ -0,0 +1,75 @@
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// DeletionPermission is a permission required to delete resources created by ccoctl
+type DeletionPermission struct {
+	// Permission is the permission of the cloud provider, eg. "iam:DeleteRole"
+	Permission string
+	// Resource is the resource against which the permission is checked, eg. an ARN. It is empty when the permission
+	// is checked against the scope of the deletion, eg. a Google cloud project.
+	Resource string
+	// Blocks describes the resources whose deletion requires the permission, eg. "the IAM Roles of mycluster"
+	Blocks string
+}
+
+// summarizeMissingDeletionPermissions summarizes the permissions of missing, each followed by the resources whose deletion
+// it would block, in the order that the permissions were first found missing
+func summarizeMissingDeletionPermissions(missing []DeletionPermission) []string {
+	permissions := []string{}
+	blocked := map[string][]string{}
+	for _, permission := range missing {
+		if _, found := blocked[permission.Permission]; !found {
+			permissions = append(permissions, permission.Permission)
+		}
+		duplicate := false
+		for _, blocks := range blocked[permission.Permission] {
+			duplicate = duplicate || blocks == permission.Blocks
+		}
+		if !duplicate {
+			blocked[permission.Permission] = append(blocked[permission.Permission], permission.Blocks)
+		}
+	}
+
+	lines := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		lines = append(lines, fmt.Sprintf("%s, blocking the deletion of %s", permission, strings.Join(blocked[permission], ", ")))
+	}
+	return lines
+}
+
+// ReportMissingDeletionPermissions reports the permissions required for deletion which the identity running ccoctl
+// was found to lack before any resource is deleted, rather than deletion failing partway. When failOnInsufficient is
+// provided, or with --strict, an error listing the missing permissions is returned so that nothing is deleted,
+// otherwise they are logged as warnings and deletion of the resources they block is expected to fail.
+func ReportMissingDeletionPermissions(missing []DeletionPermission, failOnInsufficient bool) error {
+	if len(missing) == 0 {
+		log.Print("The current identity is permitted to delete the resources discovered for deletion")
+		return nil
+	}
+	lines := summarizeMissingDeletionPermissions(missing)
+	if failOnInsufficient || Strict() {
+		return fmt.Errorf("the current identity lacks %d permissions required for deletion, no resources were deleted:\n  %s",
+			len(lines), strings.Join(lines, "\n  "))
+	}
+	Warnf("The current identity lacks %d permissions required for deletion, deleting the resources they block will fail:", len(lines))
+	for _, line := range lines {
+		Warnf("  %s", line)
+	}
+	return nil
+}
+
+// ReportUncheckedDeletionPermissions reports that the permissions required for deletion could not be checked, eg.
+// as the identity running ccoctl is not permitted to check them. As with missing permissions, an error is returned
+// so that nothing is deleted when failOnInsufficient is provided, or with --strict, otherwise a warning is logged.
+func ReportUncheckedDeletionPermissions(err error, failOnInsufficient bool) error {
+	if failOnInsufficient || Strict() {
+		return fmt.Errorf("failed to check the permissions required for deletion, no resources were deleted: %s", err)
+	}
+	Warnf("Skipping the check of the permissions to delete resources: %s", err)
+	return nil
+}
@@ -0,0 +1,28 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportMissingDeletionPermissions(t *testing.T) {
+	missing := []DeletionPermission{
+		{Permission: "iam:DeleteRole", Resource: "arn:aws:iam::123456789012:role/test-name-*", Blocks: "the IAM Roles of test-name"},
+		{Permission: "s3:DeleteObject", Resource: "arn:aws:s3:::test-name-oidc/*", Blocks: "the objects of the OIDC bucket test-name-oidc"},
+		{Permission: "iam:DeleteRole", Resource: "arn:aws:iam::123456789012:role/test-name-*", Blocks: "the IAM Roles of test-name"},
+		{Permission: "iam:DeleteRole", Resource: "arn:aws:iam::123456789012:role/other-*", Blocks: "the IAM Roles of other"},
+	}
+
+	assert.Equal(t, []string{
+		"iam:DeleteRole, blocking the deletion of the IAM Roles of test-name, the IAM Roles of other",
+		"s3:DeleteObject, blocking the deletion of the objects of the OIDC bucket test-name-oidc",
+	}, summarizeMissingDeletionPermissions(missing), "unexpected summary of missing permissions")
+
+	assert.NoError(t, ReportMissingDeletionPermissions(nil, true), "expected no error without missing permissions")
+	assert.NoError(t, ReportMissingDeletionPermissions(missing, false), "expected missing permissions only to be warned about")
+	err := ReportMissingDeletionPermissions(missing, true)
+	require.Error(t, err, "expected error with --fail-on-insufficient-permissions")
+	assert.Contains(t, err.Error(), "lacks 2 permissions required for deletion, no resources were deleted", "unexpected error")
+}
@@ -0,0 +1,153 @@
+package provisioning
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+const (
+	// DefaultConfirmThreshold is the default of --confirm-threshold, the number of resources discovered for deletion
+	// beyond which deletion must be confirmed
+	DefaultConfirmThreshold = 10
+
+	// deletionSummaryEventType is the type of the progress event counting the resources discovered for deletion
+	deletionSummaryEventType = "deletionSummary"
+	// resourcesDiscovered is the status of the progress event counting the resources discovered for deletion
+	resourcesDiscovered = "discovered"
+)
+
+// DeletionSummary counts the resources of each type discovered for deletion, before any of them is deleted
+type DeletionSummary struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// discovery is the summary to which RecordDeletionCandidate reports resources, it is nil unless DiscoverDeletions is
+// running
+var discovery *DeletionSummary
+
+// DiscoverDeletions runs discover, which must report the resources which would be deleted through
+// RecordDeletionCandidate without deleting any, eg. a deletion in dry-run mode, and returns the summary of the
+// resources reported. The summary of the resources reported before discover failed is returned along with its error.
+func DiscoverDeletions(discover func() error) (*DeletionSummary, error) {
+	summary := &DeletionSummary{counts: map[string]int{}}
+	discovery = summary
+	defer func() { discovery = nil }()
+	return summary, discover()
+}
+
+// RecordDeletionCandidate reports that the resource of resourceType, eg. "IAMRole", would be deleted. Nothing is
+// recorded unless DiscoverDeletions is running.
+func RecordDeletionCandidate(resourceType string) {
+	if discovery == nil {
+		return
+	}
+	discovery.mu.Lock()
+	defer discovery.mu.Unlock()
+	discovery.counts[resourceType]++
+}
+
+// Counts returns the number of resources of each type discovered for deletion
+func (s *DeletionSummary) Counts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int, len(s.counts))
+	for resourceType, count := range s.counts {
+		counts[resourceType] = count
+	}
+	return counts
+}
+
+// Total returns the number of resources discovered for deletion
+func (s *DeletionSummary) Total() int {
+	total := 0
+	for _, count := range s.Counts() {
+		total += count
+	}
+	return total
+}
+
+// table returns the lines of a table listing the number of resources of each type discovered for deletion, ordered
+// by type, followed by their total
+func (s *DeletionSummary) table() []string {
+	counts := s.Counts()
+	resourceTypes := make([]string, 0, len(counts))
+	width := len("TOTAL")
+	for resourceType := range counts {
+		resourceTypes = append(resourceTypes, resourceType)
+		if len(resourceType) > width {
+			width = len(resourceType)
+		}
+	}
+	sort.Strings(resourceTypes)
+
+	lines := []string{fmt.Sprintf("%-*s  %s", width, "TYPE", "COUNT")}
+	for _, resourceType := range resourceTypes {
+		lines = append(lines, fmt.Sprintf("%-*s  %d", width, resourceType, counts[resourceType]))
+	}
+	return append(lines, fmt.Sprintf("%-*s  %d", width, "TOTAL", s.Total()))
+}
+
+// PrintDeletionSummary logs the table of the resources of summary and, when the json-stream output format is
+// enabled, reports their counts within a progress event of type "deletionSummary"
+func PrintDeletionSummary(summary *DeletionSummary) {
+	if summary.Total() == 0 {
+		log.Print("No resources were discovered for deletion")
+	} else {
+		log.Print("Resources discovered for deletion:")
+		for _, line := range summary.table() {
+			log.Printf("  %s", line)
+		}
+	}
+	if stream != nil {
+		if err := stream.emitUncounted(ProgressEvent{Type: deletionSummaryEventType, Status: resourcesDiscovered, Counts: summary.Counts()}); err != nil {
+			Warnf("Failed to write progress event: %s", err)
+		}
+	}
+}
+
+// ConfirmDeletion prints summary and, when more than threshold resources were discovered for deletion, asks for the
+// deletion to be confirmed on stdin unless yes is provided. An error is returned when the deletion was not confirmed,
+// or could not be since stdin is not a terminal.
+func ConfirmDeletion(summary *DeletionSummary, threshold int, yes bool) error {
+	return confirmDeletion(summary, threshold, yes, os.Stdin, term.IsTerminal(int(os.Stdin.Fd())), os.Stderr)
+}
+
+func confirmDeletion(summary *DeletionSummary, threshold int, yes bool, in io.Reader, interactive bool, prompt io.Writer) error {
+	PrintDeletionSummary(summary)
+	total := summary.Total()
+	if yes || total <= threshold {
+		return nil
+	}
+	if !interactive {
+		return fmt.Errorf("refusing to delete %d resources, more than the --confirm-threshold of %d, without confirmation. "+
+			"Review the resources with --dry-run and provide --yes to delete them", total, threshold)
+	}
+
+	fmt.Fprintf(prompt, "Delete %d resources? Type 'yes' to confirm: ", total)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to read confirmation")
+	}
+	if strings.TrimSpace(strings.ToLower(answer)) != "yes" {
+		return errors.New("deletion was not confirmed, no resources were deleted")
+	}
+	return nil
+}
+
+// ValidateConfirmThreshold validates the value of --confirm-threshold
+func ValidateConfirmThreshold(threshold int) error {
+	if threshold < 0 {
+		return fmt.Errorf("invalid --confirm-threshold %d, the threshold must not be negative", threshold)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package provisioning
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDeletionSummary(t *testing.T, resourceTypes ...string) *DeletionSummary {
+	summary, err := DiscoverDeletions(func() error {
+		for _, resourceType := range resourceTypes {
+			RecordDeletionCandidate(resourceType)
+		}
+		return nil
+	})
+	require.NoError(t, err, "unexpected error")
+	return summary
+}
+
+func TestDeletionSummary(t *testing.T) {
+	summary := testDeletionSummary(t, "IAMRole", "IAMRole", "S3Bucket")
+	RecordDeletionCandidate("IAMRole")
+
+	assert.Equal(t, map[string]int{"IAMRole": 2, "S3Bucket": 1}, summary.Counts(), "expected only resources reported during discovery to be counted")
+	assert.Equal(t, 3, summary.Total())
+	assert.Equal(t, []string{
+		"TYPE      COUNT",
+		"IAMRole   2",
+		"S3Bucket  1",
+		"TOTAL     3",
+	}, summary.table())
+}
+
+func TestConfirmDeletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		resources   int
+		threshold   int
+		yes         bool
+		interactive bool
+		answer      string
+		expectErr   string
+	}{
+		{
+			name:      "Resources within threshold deleted without confirmation",
+			resources: 3,
+			threshold: 3,
+		},
+		{
+			name:      "Resources beyond threshold deleted with --yes",
+			resources: 4,
+			threshold: 3,
+			yes:       true,
+		},
+		{
+			name:      "Resources beyond threshold refused without a terminal",
+			resources: 4,
+			threshold: 3,
+			expectErr: "refusing to delete 4 resources, more than the --confirm-threshold of 3",
+		},
+		{
+			name:        "Resources beyond threshold deleted once confirmed",
+			resources:   4,
+			threshold:   3,
+			interactive: true,
+			answer:      "YES\n",
+		},
+		{
+			name:        "Resources beyond threshold not deleted when not confirmed",
+			resources:   4,
+			threshold:   3,
+			interactive: true,
+			answer:      "y\n",
+			expectErr:   "deletion was not confirmed",
+		},
+		{
+			name:        "Resources beyond threshold not deleted when stdin is closed",
+			resources:   4,
+			threshold:   0,
+			interactive: true,
+			expectErr:   "deletion was not confirmed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resourceTypes := make([]string, test.resources)
+			for i := range resourceTypes {
+				resourceTypes[i] = "IAMRole"
+			}
+			summary := testDeletionSummary(t, resourceTypes...)
+			prompt := &bytes.Buffer{}
+			err := confirmDeletion(summary, test.threshold, test.yes, strings.NewReader(test.answer), test.interactive, prompt)
+			if test.expectErr != "" {
+				require.ErrorContains(t, err, test.expectErr)
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+			if test.interactive {
+				assert.Contains(t, prompt.String(), "Delete 4 resources?", "expected confirmation to be asked")
+			} else {
+				assert.Empty(t, prompt.String(), "expected no confirmation to be asked")
+			}
+		})
+	}
+}
+
+func TestDeletionSummaryEvent(t *testing.T) {
+	out := &bytes.Buffer{}
+	stream = testProgressStream(out)
+	defer func() { stream = nil }()
+
+	PrintDeletionSummary(testDeletionSummary(t, "ResourceGroup"))
+	FinishOutput(nil)
+
+	events := decodeProgressEvents(t, out)
+	require.Len(t, events, 2, "expected a deletion summary event followed by the summary event")
+	assert.Equal(t, deletionSummaryEventType, events[0].Type)
+	assert.Equal(t, resourcesDiscovered, events[0].Status)
+	assert.Equal(t, map[string]int{"ResourceGroup": 1}, events[0].Counts)
+	assert.Empty(t, events[1].Counts, "expected the discovered resources not to be counted within the summary event")
+}
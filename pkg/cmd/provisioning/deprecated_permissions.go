@@ -0,0 +1,170 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+// DeprecatedPermission is a permission or role which the cloud provider has deprecated, and which CredentialsRequests
+// should stop requesting
+type DeprecatedPermission struct {
+	// Permission is the deprecated permission or role, eg. "aws-portal:ViewBilling"
+	Permission string
+	// Reason describes the deprecation, eg. "retired in favor of the fine-grained Billing actions"
+	Reason string
+	// Replacement lists the permissions or roles to request instead, if any
+	Replacement []string
+}
+
+// deprecatedPermissions are the permissions and roles known to be deprecated by each cloud provider, keyed by the kind
+// of the provider spec requesting them. Entries are matched exactly, wildcards within CredentialsRequests are not
+// expanded.
+var deprecatedPermissions = map[string][]DeprecatedPermission{
+	"AWSProviderSpec": {
+		{Permission: "aws-portal:ViewBilling", Reason: "retired in favor of the fine-grained Billing actions", Replacement: []string{"billing:Get*", "ce:Get*"}},
+		{Permission: "aws-portal:ModifyBilling", Reason: "retired in favor of the fine-grained Billing actions", Replacement: []string{"billing:Put*", "billing:Update*"}},
+		{Permission: "aws-portal:ViewAccount", Reason: "retired in favor of the fine-grained Account actions", Replacement: []string{"account:GetAccountInformation"}},
+		{Permission: "aws-portal:ModifyAccount", Reason: "retired in favor of the fine-grained Account actions", Replacement: []string{"account:PutContactInformation"}},
+		{Permission: "aws-portal:ViewPaymentMethods", Reason: "retired in favor of the fine-grained Payments actions", Replacement: []string{"payments:ListPaymentPreferences"}},
+		{Permission: "aws-portal:ModifyPaymentMethods", Reason: "retired in favor of the fine-grained Payments actions", Replacement: []string{"payments:UpdatePaymentPreferences"}},
+		{Permission: "aws-portal:ViewUsage", Reason: "retired in favor of the fine-grained Cost Explorer actions", Replacement: []string{"ce:GetCostAndUsage"}},
+		{Permission: "purchase-orders:ViewPurchaseOrders", Reason: "retired in favor of the fine-grained Purchase Orders actions", Replacement: []string{"purchase-orders:GetPurchaseOrder", "purchase-orders:ListPurchaseOrders"}},
+		{Permission: "purchase-orders:ModifyPurchaseOrders", Reason: "retired in favor of the fine-grained Purchase Orders actions", Replacement: []string{"purchase-orders:UpdatePurchaseOrder"}},
+	},
+	"GCPProviderSpec": {
+		{Permission: "roles/iam.serviceAccountActor", Reason: "split into the Service Account User and Service Account Token Creator roles", Replacement: []string{"roles/iam.serviceAccountUser", "roles/iam.serviceAccountTokenCreator"}},
+		{Permission: "roles/source.reader", Reason: "Cloud Source Repositories is no longer available to new projects"},
+		{Permission: "roles/source.writer", Reason: "Cloud Source Repositories is no longer available to new projects"},
+		{Permission: "roles/source.admin", Reason: "Cloud Source Repositories is no longer available to new projects"},
+	},
+	"AzureProviderSpec": {
+		{Permission: "Classic Virtual Machine Contributor", Reason: "classic virtual machines are retired", Replacement: []string{"Virtual Machine Contributor"}},
+		{Permission: "Classic Network Contributor", Reason: "classic virtual networks are retired", Replacement: []string{"Network Contributor"}},
+		{Permission: "Classic Storage Account Contributor", Reason: "classic storage accounts are retired", Replacement: []string{"Storage Account Contributor"}},
+		{Permission: "Classic Storage Account Key Operator Service Role", Reason: "classic storage accounts are retired", Replacement: []string{"Storage Account Key Operator Service Role"}},
+	},
+}
+
+// caseInsensitivePermissions are the kinds of provider specs whose cloud provider matches permissions and roles
+// regardless of case, eg. the actions of AWS policies
+var caseInsensitivePermissions = map[string]bool{
+	"AWSProviderSpec":   true,
+	"AzureProviderSpec": true,
+}
+
+// DeprecatedPermissionRequest is a deprecated permission or role requested by a CredentialsRequest
+type DeprecatedPermissionRequest struct {
+	DeprecatedPermission
+	// Component identifies the requesting CredentialsRequest, eg. "CredentialsRequest openshift-ingress-operator/openshift-ingress"
+	Component string
+	// Requested is the permission as requested, which may differ in case from the deprecated permission
+	Requested string
+}
+
+func (r DeprecatedPermissionRequest) String() string {
+	message := r.Component + " requests " + r.Requested + " which is deprecated, " + r.Reason
+	if len(r.Replacement) > 0 {
+		message += ", request " + strings.Join(r.Replacement, ", ") + " instead"
+	}
+	return message
+}
+
+// requestedPermissions returns the kind of the provider spec of cr along with the permissions and roles it requests.
+// No permissions are returned for the kinds of provider specs of which no permission is known to be deprecated, nor
+// for provider specs which can't be decoded, which fail when the credentials of cr are created.
+func requestedPermissions(codec *credreqv1.ProviderCodec, cr *credreqv1.CredentialsRequest) (string, []string) {
+	if cr.Spec.ProviderSpec == nil || len(cr.Spec.ProviderSpec.Raw) == 0 {
+		return "", nil
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(cr.Spec.ProviderSpec.Raw, &typeMeta); err != nil {
+		return "", nil
+	}
+
+	permissions := []string{}
+	switch typeMeta.Kind {
+	case "AWSProviderSpec":
+		spec := credreqv1.AWSProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &spec); err != nil {
+			return "", nil
+		}
+		for _, statement := range spec.StatementEntries {
+			permissions = append(permissions, statement.Action...)
+		}
+	case "GCPProviderSpec":
+		spec := credreqv1.GCPProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &spec); err != nil {
+			return "", nil
+		}
+		permissions = append(permissions, spec.PredefinedRoles...)
+		permissions = append(permissions, spec.Permissions...)
+	case "AzureProviderSpec":
+		spec := credreqv1.AzureProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &spec); err != nil {
+			return "", nil
+		}
+		for _, roleBinding := range spec.RoleBindings {
+			permissions = append(permissions, roleBinding.Role)
+		}
+	}
+	return typeMeta.Kind, permissions
+}
+
+// FindDeprecatedPermissions returns the permissions and roles requested by credReqs which are known to be deprecated
+// by their cloud provider, in the order that they are requested
+func FindDeprecatedPermissions(credReqs []*credreqv1.CredentialsRequest) ([]DeprecatedPermissionRequest, error) {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create credReq codec")
+	}
+
+	requests := []DeprecatedPermissionRequest{}
+	for _, cr := range credReqs {
+		kind, permissions := requestedPermissions(codec, cr)
+		for _, permission := range permissions {
+			for _, deprecated := range deprecatedPermissions[kind] {
+				matches := permission == deprecated.Permission
+				if caseInsensitivePermissions[kind] {
+					matches = strings.EqualFold(permission, deprecated.Permission)
+				}
+				if matches {
+					requests = append(requests, DeprecatedPermissionRequest{
+						DeprecatedPermission: deprecated,
+						Component:            CredentialsRequestComponent(cr),
+						Requested:            permission,
+					})
+				}
+			}
+		}
+	}
+	return requests, nil
+}
+
+// WarnDeprecatedPermissions logs a warning for every deprecated permission or role requested by the CredentialsRequests
+// within dirs, as accepted by GetListOfCredentialsRequests, so that their manifests may be updated before the cloud
+// provider stops honoring them. Deprecated permissions are still granted, invalid manifests are ignored as they are
+// reported by ValidateCredentialsRequests.
+func WarnDeprecatedPermissions(dirs []string, enableTechPreview bool) error {
+	credReqs := []*credreqv1.CredentialsRequest{}
+	for _, d := range dirs {
+		dirCredReqs, _, err := loadCredentialsRequestsFromDir(d, enableTechPreview, false)
+		if err != nil {
+			return errors.Wrapf(err, "failed to process CredentialsRequests in directory %s", d)
+		}
+		credReqs = append(credReqs, dirCredReqs...)
+	}
+
+	requests, err := FindDeprecatedPermissions(credReqs)
+	if err != nil {
+		return err
+	}
+	for _, request := range requests {
+		Warnf("%s", request)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+func withRawProviderSpec(raw string) option {
+	return func(credreq *credreqv1.CredentialsRequest) {
+		credreq.Spec.ProviderSpec = &runtime.RawExtension{Raw: []byte(raw)}
+	}
+}
+
+func TestFindDeprecatedPermissions(t *testing.T) {
+	builder := NewCredentialsRequestBuilder().Options(WithName("test-credreq"), func(credreq *credreqv1.CredentialsRequest) {
+		credreq.SetNamespace("openshift-cloud-credential-operator")
+	})
+	tests := []struct {
+		name         string
+		providerSpec string
+		expected     []string
+	}{
+		{
+			name:         "AWS action matched regardless of case",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"AWSProviderSpec","statementEntries":[{"effect":"Allow","action":["ec2:DescribeInstances","aws-portal:viewbilling"],"resource":"*"}]}`,
+			expected:     []string{"aws-portal:viewbilling"},
+		},
+		{
+			name:         "GCP predefined roles",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"GCPProviderSpec","predefinedRoles":["roles/iam.serviceAccountActor","roles/compute.viewer","roles/source.reader"],"permissions":["compute.instances.get"]}`,
+			expected:     []string{"roles/iam.serviceAccountActor", "roles/source.reader"},
+		},
+		{
+			name:         "GCP role matched exactly",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"GCPProviderSpec","predefinedRoles":["roles/iam.serviceaccountactor"]}`,
+		},
+		{
+			name:         "Azure role",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"AzureProviderSpec","roleBindings":[{"role":"Contributor"},{"role":"Classic Network Contributor"}]}`,
+			expected:     []string{"Classic Network Contributor"},
+		},
+		{
+			name:         "Wildcards not expanded",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"AWSProviderSpec","statementEntries":[{"effect":"Allow","action":["aws-portal:*"],"resource":"*"}]}`,
+		},
+		{
+			name:         "Provider spec of another kind",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"VSphereProviderSpec"}`,
+		},
+		{
+			name:         "Provider spec which can't be decoded",
+			providerSpec: `{"apiVersion":"cloudcredential.openshift.io/v1","kind":"AWSProviderSpec","statementEntries":"aws-portal:ViewBilling"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests, err := FindDeprecatedPermissions([]*credreqv1.CredentialsRequest{builder.Build(withRawProviderSpec(test.providerSpec))})
+			require.NoError(t, err, "unexpected error")
+
+			requested := []string{}
+			for _, request := range requests {
+				assert.Equal(t, "CredentialsRequest openshift-cloud-credential-operator/test-credreq", request.Component, "unexpected component")
+				requested = append(requested, request.Requested)
+			}
+			if test.expected == nil {
+				test.expected = []string{}
+			}
+			assert.Equal(t, test.expected, requested, "unexpected deprecated permissions")
+		})
+	}
+}
+
+func TestDeprecatedPermissionRequestString(t *testing.T) {
+	request := DeprecatedPermissionRequest{
+		DeprecatedPermission: DeprecatedPermission{
+			Permission:  "roles/iam.serviceAccountActor",
+			Reason:      "split into the Service Account User and Service Account Token Creator roles",
+			Replacement: []string{"roles/iam.serviceAccountUser", "roles/iam.serviceAccountTokenCreator"},
+		},
+		Component: "CredentialsRequest openshift-cloud-credential-operator/test-credreq",
+		Requested: "roles/iam.serviceAccountActor",
+	}
+	assert.Equal(t, "CredentialsRequest openshift-cloud-credential-operator/test-credreq requests roles/iam.serviceAccountActor which is deprecated, "+
+		"split into the Service Account User and Service Account Token Creator roles, request roles/iam.serviceAccountUser, roles/iam.serviceAccountTokenCreator instead", request.String(), "unexpected warning")
+}
+
+func TestDeprecatedPermissionsList(t *testing.T) {
+	// Entries are matched literally, they may not be wildcards, and explain their deprecation
+	for kind, permissions := range deprecatedPermissions {
+		seen := map[string]bool{}
+		for _, permission := range permissions {
+			assert.NotEmpty(t, permission.Reason, "expected reason for deprecated permission %s of %s", permission.Permission, kind)
+			assert.NotContains(t, permission.Permission, "*", "deprecated permission %s of %s may not be a wildcard", permission.Permission, kind)
+			assert.False(t, seen[permission.Permission], "duplicate deprecated permission %s of %s", permission.Permission, kind)
+			seen[permission.Permission] = true
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package provisioning
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"text/template"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+// descriptionTemplate is the template of --resource-description-template, nil when not provided
+var descriptionTemplate *template.Template
+
+// DescriptionConstraints are the constraints of a cloud provider on the descriptions of a resource type
+type DescriptionConstraints struct {
+	// MaxLength is the maximum length of a description in bytes
+	MaxLength int
+	// Pattern matches the valid descriptions, any description is valid when nil
+	Pattern *regexp.Regexp
+	// Description describes the valid descriptions, eg. "printable Latin-1 characters"
+	Description string
+}
+
+// InitDescriptionTemplate parses the Go template of --resource-description-template, whose variables are those of
+// --name-template. The resources keep the default description of ccoctl when text is empty.
+func InitDescriptionTemplate(text string) error {
+	descriptionTemplate = nil
+	if text == "" {
+		return nil
+	}
+	tmpl, err := template.New("description").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid --resource-description-template %q: %s", text, err)
+	}
+	for field := range referredFields(tmpl) {
+		if _, found := reflect.TypeOf(NameTemplateVars{}).FieldByName(field); !found {
+			return fmt.Errorf("invalid --resource-description-template %q, unknown variable .%s", text, field)
+		}
+	}
+	descriptionTemplate = tmpl
+	return nil
+}
+
+// ComponentDescription returns the description of the resource created for cr by ccoctl for clusterName, rendered
+// from --resource-description-template. defaultDescription is returned when no template was provided.
+//
+// Unlike names, descriptions are never shortened: an error is returned when the rendered description is not valid
+// according to constraints. Descriptions are informational only, ccoctl never relies on them to find the resources
+// it created, eg. when deleting them.
+func ComponentDescription(clusterName string, cr *credreqv1.CredentialsRequest, constraints DescriptionConstraints, defaultDescription string) (string, error) {
+	if descriptionTemplate == nil {
+		return defaultDescription, nil
+	}
+	vars := NameTemplateVars{
+		ClusterName: clusterName,
+		Component:   cr.Spec.SecretRef.Namespace + "-" + cr.Spec.SecretRef.Name,
+		Namespace:   cr.Spec.SecretRef.Namespace,
+		SecretName:  cr.Spec.SecretRef.Name,
+		Region:      nameTemplateRegion,
+	}
+	buf := &bytes.Buffer{}
+	if err := descriptionTemplate.Execute(buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render the --resource-description-template for component %s: %s", vars.Component, err)
+	}
+	description := buf.String()
+	if constraints.MaxLength > 0 && len(description) > constraints.MaxLength {
+		return "", fmt.Errorf("the --resource-description-template rendered a description of %d bytes for component %s, descriptions must not exceed %d bytes",
+			len(description), vars.Component, constraints.MaxLength)
+	}
+	if constraints.Pattern != nil && !constraints.Pattern.MatchString(description) {
+		return "", fmt.Errorf("the --resource-description-template rendered the invalid description %q for component %s, descriptions must consist of %s",
+			description, vars.Component, constraints.Description)
+	}
+	return description, nil
+}
@@ -0,0 +1,112 @@
+package provisioning
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDescriptionConstraints = DescriptionConstraints{
+	MaxLength:   100,
+	Pattern:     regexp.MustCompile(`^[\x20-\x7E]*$`),
+	Description: "printable ASCII characters",
+}
+
+func TestInitDescriptionTemplate(t *testing.T) {
+	defer InitDescriptionTemplate("")
+
+	tests := []struct {
+		name        string
+		template    string
+		expectError string
+	}{
+		{
+			name: "No template",
+		},
+		{
+			name:     "Template",
+			template: "{{.ClusterName}} credentials of {{.Namespace}}/{{.SecretName}}",
+		},
+		{
+			name:        "Malformed template",
+			template:    "{{.Component",
+			expectError: "invalid --resource-description-template",
+		},
+		{
+			name:        "Unknown variable",
+			template:    "{{.Cluster}} {{.Component}}",
+			expectError: "unknown variable .Cluster",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := InitDescriptionTemplate(test.template)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestComponentDescription(t *testing.T) {
+	defer InitDescriptionTemplate("")
+	cr := testNameTemplateCredentialsRequest("openshift-image-registry", "installer-cloud-credentials")
+
+	tests := []struct {
+		name              string
+		template          string
+		expectDescription string
+		expectError       string
+	}{
+		{
+			name:              "No template, default description",
+			expectDescription: "default description",
+		},
+		{
+			name:              "Template",
+			template:          "{{.ClusterName}} ({{.Region}}): {{.Namespace}}/{{.SecretName}}",
+			expectDescription: "test (us-east-1): openshift-image-registry/installer-cloud-credentials",
+		},
+		{
+			name:        "Description exceeding the maximum length",
+			template:    "Credentials of the {{.Component}} component of the {{.ClusterName}} OpenShift cluster",
+			expectError: "descriptions must not exceed 100 bytes",
+		},
+		{
+			name:        "Description with invalid characters",
+			template:    "{{.ClusterName}}\t{{.SecretName}}",
+			expectError: `invalid description "test\tinstaller-cloud-credentials"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, InitDescriptionTemplate(test.template), "unexpected error parsing template")
+			nameTemplateRegion = "us-east-1"
+			defer func() { nameTemplateRegion = "" }()
+
+			description, err := ComponentDescription("test", cr, testDescriptionConstraints, "default description")
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectDescription, description, "unexpected description")
+		})
+	}
+
+	// Descriptions of the maximum length are valid
+	require.NoError(t, InitDescriptionTemplate("{{.SecretName}}"), "unexpected error parsing template")
+	cr = testNameTemplateCredentialsRequest("ns", strings.Repeat("a", testDescriptionConstraints.MaxLength))
+	description, err := ComponentDescription("test", cr, testDescriptionConstraints, "")
+	require.NoError(t, err, "unexpected error")
+	assert.Len(t, description, testDescriptionConstraints.MaxLength, "unexpected description length")
+}
@@ -0,0 +1,180 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ClaimsSupportedKey is the field of the discovery document listing the claims of the tokens of the issuer
+	ClaimsSupportedKey = "claims_supported"
+	// AudiencesSupportedKey is the field of the discovery document listing the audiences of the tokens of the
+	// issuer. The field is not defined by OpenID Connect Discovery, which allows providers to publish additional
+	// metadata, and is only written when extra audiences are provided.
+	AudiencesSupportedKey = "audiences_supported"
+)
+
+// requiredDiscoveryDocumentLists are the lists which OpenID Connect Discovery requires of a discovery document
+var requiredDiscoveryDocumentLists = []string{
+	"response_types_supported",
+	"subject_types_supported",
+	"id_token_signing_alg_values_supported",
+}
+
+// DiscoveryDocumentExtensions are the entries appended to the OIDC discovery document generated by ccoctl, eg. for
+// consumers of the issuer other than the cluster
+type DiscoveryDocumentExtensions struct {
+	// Audiences are appended to the audiences_supported of the discovery document
+	Audiences []string
+	// Claims are appended to the claims_supported of the discovery document
+	Claims []string
+}
+
+// Empty returns true when there are no entries to append to the discovery document
+func (e DiscoveryDocumentExtensions) Empty() bool {
+	return len(e.Audiences) == 0 && len(e.Claims) == 0
+}
+
+// Validate ensures that each extra audience and claim is neither empty nor contains whitespace
+func (e DiscoveryDocumentExtensions) Validate() error {
+	if err := validateDiscoveryDocumentEntries("--oidc-extra-audience", e.Audiences); err != nil {
+		return err
+	}
+	return validateDiscoveryDocumentEntries("--oidc-extra-claim", e.Claims)
+}
+
+func validateDiscoveryDocumentEntries(flag string, values []string) error {
+	for _, value := range values {
+		if value == "" || strings.IndexFunc(value, unicode.IsSpace) != -1 {
+			return fmt.Errorf("invalid %s %q, the value may neither be empty nor contain whitespace", flag, value)
+		}
+	}
+	return nil
+}
+
+// ExtendDiscoveryDocument appends the audiences and claims of extensions to the discovery document, skipping those
+// already listed. The document is returned unchanged when there is nothing to append. An error is returned unless the
+// resulting document remains a valid discovery document whose issuer and jwks_uri are those of document.
+func ExtendDiscoveryDocument(document string, extensions DiscoveryDocumentExtensions) (string, error) {
+	if extensions.Empty() {
+		return document, nil
+	}
+	if err := extensions.Validate(); err != nil {
+		return "", err
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(document), &fields); err != nil {
+		return "", errors.Wrap(err, "failed to decode discovery document")
+	}
+	for key, values := range map[string][]string{AudiencesSupportedKey: extensions.Audiences, ClaimsSupportedKey: extensions.Claims} {
+		if len(values) == 0 {
+			continue
+		}
+		existing, err := stringList(fields, key)
+		if err != nil {
+			return "", err
+		}
+		fields[key] = appendMissing(existing, values)
+	}
+
+	extended, err := json.MarshalIndent(fields, "", "    ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode discovery document")
+	}
+	if err := validateDiscoveryDocument(document, string(extended)); err != nil {
+		return "", err
+	}
+	return string(extended), nil
+}
+
+// DiscoveryDocumentIssuer returns the issuer claimed by the OIDC discovery document
+func DiscoveryDocumentIssuer(document []byte) (string, error) {
+	fields := struct {
+		Issuer string `json:"issuer"`
+	}{}
+	if err := json.Unmarshal(document, &fields); err != nil {
+		return "", errors.Wrap(err, "failed to decode discovery document")
+	}
+	if fields.Issuer == "" {
+		return "", errors.New("discovery document has no issuer")
+	}
+	return fields.Issuer, nil
+}
+
+// validateDiscoveryDocument ensures that extended is a valid discovery document whose issuer and jwks_uri are those
+// of original
+func validateDiscoveryDocument(original, extended string) error {
+	originalFields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(original), &originalFields); err != nil {
+		return errors.Wrap(err, "failed to decode discovery document")
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(extended), &fields); err != nil {
+		return errors.Wrap(err, "extended discovery document is not valid JSON")
+	}
+	for _, key := range []string{"issuer", "jwks_uri"} {
+		value, ok := fields[key].(string)
+		if !ok || value == "" {
+			return fmt.Errorf("extended discovery document has no %s", key)
+		}
+		if value != originalFields[key] {
+			return fmt.Errorf("extended discovery document has %s %q rather than %q", key, value, originalFields[key])
+		}
+	}
+	for _, key := range requiredDiscoveryDocumentLists {
+		values, err := stringList(fields, key)
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("extended discovery document has no %s", key)
+		}
+	}
+	for _, key := range []string{AudiencesSupportedKey, ClaimsSupportedKey} {
+		if _, err := stringList(fields, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringList returns the list of strings of fields identified by key, which is empty when the field is absent
+func stringList(fields map[string]interface{}, key string) ([]string, error) {
+	value, found := fields[key]
+	if !found {
+		return []string{}, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s of the discovery document is not a list", key)
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s of the discovery document may only contain strings", key)
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+// appendMissing appends the values which are not already within list
+func appendMissing(list, values []string) []string {
+	present := map[string]bool{}
+	for _, item := range list {
+		present[item] = true
+	}
+	for _, value := range values {
+		if !present[value] {
+			list = append(list, value)
+			present[value] = true
+		}
+	}
+	return list
+}
@@ -0,0 +1,143 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtendDiscoveryDocument(t *testing.T) {
+	issuerURL := "https://test-name-oidc.s3.test-region.amazonaws.com"
+	document := fmt.Sprintf(DiscoveryDocumentTemplate, issuerURL, issuerURL, KeysURI)
+
+	tests := []struct {
+		name            string
+		document        string
+		extensions      DiscoveryDocumentExtensions
+		expectUnchanged bool
+		expectAudiences []string
+		expectClaims    []string
+		expectError     bool
+	}{
+		{
+			name:            "Document unchanged without extensions",
+			document:        document,
+			expectUnchanged: true,
+		},
+		{
+			name:     "Audiences and claims appended",
+			document: document,
+			extensions: DiscoveryDocumentExtensions{
+				Audiences: []string{"sts.amazonaws.com", "api://AzureADTokenExchange"},
+				Claims:    []string{"kubernetes.io", "nbf"},
+			},
+			expectAudiences: []string{"sts.amazonaws.com", "api://AzureADTokenExchange"},
+			expectClaims:    []string{"aud", "exp", "sub", "iat", "iss", "sub", "kubernetes.io", "nbf"},
+		},
+		{
+			name:     "Entries already listed are not duplicated",
+			document: document,
+			extensions: DiscoveryDocumentExtensions{
+				Audiences: []string{"openshift", "openshift"},
+				Claims:    []string{"aud", "nbf"},
+			},
+			expectAudiences: []string{"openshift"},
+			expectClaims:    []string{"aud", "exp", "sub", "iat", "iss", "sub", "nbf"},
+		},
+		{
+			name: "Claims added to a document without claims_supported",
+			document: `{
+				"issuer": "https://test-storage-account.blob.core.windows.net/test-container",
+				"jwks_uri": "https://test-storage-account.blob.core.windows.net/test-container/openid/v1/jwks",
+				"response_types_supported": ["id_token"],
+				"subject_types_supported": ["public"],
+				"id_token_signing_alg_values_supported": ["RS256"]
+			}`,
+			extensions: DiscoveryDocumentExtensions{
+				Claims: []string{"nbf"},
+			},
+			expectClaims: []string{"nbf"},
+		},
+		{
+			name:     "Audience containing whitespace rejected",
+			document: document,
+			extensions: DiscoveryDocumentExtensions{
+				Audiences: []string{"my audience"},
+			},
+			expectError: true,
+		},
+		{
+			name:     "Empty claim rejected",
+			document: document,
+			extensions: DiscoveryDocumentExtensions{
+				Claims: []string{""},
+			},
+			expectError: true,
+		},
+		{
+			name: "Document missing required fields rejected",
+			document: `{
+				"issuer": "https://test-name-oidc.s3.test-region.amazonaws.com",
+				"jwks_uri": "https://test-name-oidc.s3.test-region.amazonaws.com/keys.json"
+			}`,
+			extensions: DiscoveryDocumentExtensions{
+				Claims: []string{"nbf"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Document with invalid claims_supported rejected",
+			document: `{
+				"issuer": "https://test-name-oidc.s3.test-region.amazonaws.com",
+				"jwks_uri": "https://test-name-oidc.s3.test-region.amazonaws.com/keys.json",
+				"response_types_supported": ["id_token"],
+				"subject_types_supported": ["public"],
+				"id_token_signing_alg_values_supported": ["RS256"],
+				"claims_supported": "aud"
+			}`,
+			extensions: DiscoveryDocumentExtensions{
+				Claims: []string{"nbf"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			extended, err := ExtendDiscoveryDocument(test.document, test.extensions)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			if test.expectUnchanged {
+				assert.Equal(t, test.document, extended)
+				return
+			}
+
+			original := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(test.document), &original))
+			fields := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(extended), &fields), "expected the extended document to be valid JSON")
+			assert.Equal(t, original["issuer"], fields["issuer"], "expected the issuer to be untouched")
+			assert.Equal(t, original["jwks_uri"], fields["jwks_uri"], "expected the jwks_uri to be untouched")
+			for _, key := range requiredDiscoveryDocumentLists {
+				assert.Equal(t, original[key], fields[key], "expected %s to be untouched", key)
+			}
+
+			audiences, err := stringList(fields, AudiencesSupportedKey)
+			require.NoError(t, err)
+			if test.expectAudiences == nil {
+				assert.NotContains(t, fields, AudiencesSupportedKey)
+			} else {
+				assert.Equal(t, test.expectAudiences, audiences)
+			}
+			claims, err := stringList(fields, ClaimsSupportedKey)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectClaims, claims)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DryRunAnnotation is the annotation applied to the manifests generated by the create commands with --dry-run to
+// identify them as dry-run artifacts, which must not be applied as is
+const DryRunAnnotation = "ccoctl.openshift.io/dry-run"
+
+// DryRunAnnotations returns the annotations of a manifest generated with --dry-run, rendered as the entries of the
+// annotations of a manifest's metadata, one per line, as by ProvenanceAnnotations. The DryRunAnnotation is rendered
+// along with annotations, eg. recording the names of the cloud resources which would have been created.
+func DryRunAnnotations(annotations map[string]string) string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var rendered strings.Builder
+	fmt.Fprintf(&rendered, "    %s: %s\n", DryRunAnnotation, strconv.Quote("true"))
+	for _, key := range keys {
+		fmt.Fprintf(&rendered, "    %s: %s\n", key, strconv.Quote(annotations[key]))
+	}
+	return rendered.String()
+}
+
+// DryRunOutputDir creates a temporary directory in which to save the files generated by a create command of
+// provider with --dry-run when no output directory has been provided
+func DryRunOutputDir(provider string) string {
+	outputDir, err := os.MkdirTemp("", fmt.Sprintf("ccoctl-%s-dry-run-", provider))
+	if err != nil {
+		log.Fatalf("Failed to create temporary output directory for dry run: %s", err)
+	}
+	log.Printf("No --output-dir provided, saving dry-run artifacts to temporary directory %s", outputDir)
+	return outputDir
+}
@@ -0,0 +1,66 @@
+package provisioning
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestDryRunAnnotations(t *testing.T) {
+	manifestTemplate := `apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+%s  name: installer-cloud-credentials
+  namespace: openshift-image-registry
+type: Opaque`
+
+	secret := &corev1.Secret{}
+	err := sigsyaml.UnmarshalStrict([]byte(fmt.Sprintf(manifestTemplate, DryRunAnnotations(map[string]string{"ccoctl.openshift.io/iam-role-name": "test-role"}))), secret)
+	require.NoError(t, err, "expected the annotated manifest to be a valid secret")
+	assert.Equal(t, map[string]string{
+		DryRunAnnotation:                    "true",
+		"ccoctl.openshift.io/iam-role-name": "test-role",
+	}, secret.Annotations, "unexpected annotations")
+}
+
+func TestCreateClusterAuthentication(t *testing.T) {
+	tests := []struct {
+		name                string
+		dryRun              bool
+		expectedAnnotations map[string]string
+	}{
+		{
+			name: "Authentication manifest",
+		},
+		{
+			name:                "Dry-run authentication manifest",
+			dryRun:              true,
+			expectedAnnotations: map[string]string{DryRunAnnotation: "true"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			require.NoError(t, os.Mkdir(filepath.Join(outputDir, ManifestsDirName), 0700))
+
+			require.NoError(t, CreateClusterAuthentication("https://test-oidc.example.com", outputDir, test.dryRun), "unexpected error")
+
+			data, err := os.ReadFile(filepath.Join(outputDir, ManifestsDirName, clusterAuthenticationFile))
+			require.NoError(t, err, "expected the authentication manifest to be written")
+			authentication := &configv1.Authentication{}
+			require.NoError(t, sigsyaml.UnmarshalStrict(data, authentication), "expected the manifest to be a valid authentication")
+			assert.Equal(t, test.expectedAnnotations, authentication.Annotations, "unexpected annotations")
+			assert.Equal(t, "https://test-oidc.example.com", authentication.Spec.ServiceAccountIssuer, "unexpected issuer URL")
+		})
+	}
+}
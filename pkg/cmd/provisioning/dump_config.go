@@ -0,0 +1,135 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// redactedValue replaces the sensitive values within the configuration written by --dump-config
+	redactedValue = "REDACTED"
+	// dumpsResolvedConfigAnnotation marks the commands which call DumpConfig themselves once their configuration
+	// has been resolved
+	dumpsResolvedConfigAnnotation = "ccoctl.openshift.io/dumps-resolved-config"
+)
+
+// sensitiveConfigName matches the names of flags and resolved values whose values are redacted by --dump-config
+var sensitiveConfigName = regexp.MustCompile(`(?i)(password|token|secret-access-key|secretaccesskey|client-secret|clientsecret|api-key|apikey)`)
+
+var (
+	// dumpConfigEnabled indicates that the effective configuration is written before a command does any work
+	dumpConfigEnabled bool
+	// configDumped indicates that the effective configuration has already been written
+	configDumped bool
+	// dumpConfigOut is where the effective configuration is written, logs are written to stderr as well
+	dumpConfigOut io.Writer = os.Stderr
+)
+
+// EffectiveConfig is the configuration of an invocation of ccoctl written as JSON by --dump-config
+type EffectiveConfig struct {
+	// Command is the path of the command, eg. "ccoctl aws create-all"
+	Command string `json:"command"`
+	// Version is the version of ccoctl
+	Version string `json:"version"`
+	// Flags are the values of every flag of the command, including the flags left to their default
+	Flags map[string]string `json:"flags"`
+	// Resolved are the values resolved by the command before doing any work, eg. the identity with which cloud API
+	// calls are made or the names of the resources derived from --name
+	Resolved map[string]string `json:"resolved,omitempty"`
+}
+
+// InitDumpConfig enables writing the effective configuration of the command before the command does any work
+func InitDumpConfig(enabled bool) {
+	dumpConfigEnabled = enabled
+	configDumped = false
+}
+
+// DumpsResolvedConfig marks cmd as calling DumpConfig itself with the values it resolves before doing any work, the
+// configuration of the commands which are not marked is written by DumpConfigCommands without resolved values
+func DumpsResolvedConfig(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[dumpsResolvedConfigAnnotation] = "true"
+	return cmd
+}
+
+// DumpConfigCommands writes the effective configuration of cmd and of its subcommands before they run when
+// --dump-config is enabled, except for the commands marked by DumpsResolvedConfig
+func DumpConfigCommands(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		DumpConfigCommands(subCmd)
+	}
+	if cmd.Run == nil || cmd.Annotations[dumpsResolvedConfigAnnotation] != "" {
+		return
+	}
+	run := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		DumpConfig(cmd, nil)
+		run(cmd, args)
+	}
+}
+
+// DumpConfig writes the effective configuration of cmd as JSON when --dump-config is enabled. The values resolved by
+// the command are returned by resolve, which is only called when the configuration is written and may be nil.
+// Sensitive values are redacted. The configuration is written once per invocation.
+func DumpConfig(cmd *cobra.Command, resolve func() map[string]string) {
+	if !dumpConfigEnabled || configDumped {
+		return
+	}
+	configDumped = true
+
+	config := EffectiveConfig{
+		Command: cmd.CommandPath(),
+		Version: ccoctlVersion(),
+		Flags:   map[string]string{},
+	}
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		config.Flags[flag.Name] = redactConfigValue(flag.Name, flag.Value.String())
+	})
+	if resolve != nil {
+		config.Resolved = map[string]string{}
+		for name, value := range resolve() {
+			config.Resolved[name] = redactConfigValue(name, value)
+		}
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		Warnf("Failed to dump the effective configuration: %s", err)
+		return
+	}
+	dumpConfigOut.Write(append(data, '\n'))
+}
+
+// redactConfigValue returns value, or a redacted value when value is sensitive. The values of sensitive flags are
+// redacted entirely, while the credentials and query of URLs are redacted since they may carry credentials such as
+// shared access signatures. The secret material within other values is redacted by Redact.
+func redactConfigValue(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if sensitiveConfigName.MatchString(name) {
+		return redactedValue
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.User == nil && u.RawQuery == "") {
+		return Redact(value)
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redactedValue
+	}
+	return u.String()
+}
@@ -0,0 +1,48 @@
+package provisioning
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// EnvironmentPrefix is the prefix of the environment variables providing the values of flags
+const EnvironmentPrefix = "CCOCTL_"
+
+// EnvironmentVariable returns the name of the environment variable providing the value of the flag identified by
+// name, eg. CCOCTL_CREDENTIALS_REQUESTS_DIR for --credentials-requests-dir
+func EnvironmentVariable(name string) string {
+	return EnvironmentPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ApplyEnvironment sets the flags of cmd, the command being run, which were not provided on the command line to the
+// values of their environment variables, see EnvironmentVariable. Environment variables which are empty are ignored.
+//
+// It must be called before ApplyConfigFile so that the command line takes precedence over the environment, which
+// takes precedence over the configuration file. Flags which may be repeated are set once to the value of their
+// environment variable, so that comma-separated lists are only accepted by the flags accepting them on the command
+// line. The values of sensitive flags, eg. passwords or tokens, are never included within the errors returned.
+func ApplyEnvironment(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		variable := EnvironmentVariable(flag.Name)
+		value := os.Getenv(variable)
+		if value == "" {
+			return
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			if sensitiveConfigName.MatchString(flag.Name) {
+				err = fmt.Errorf("invalid value of environment variable %s for --%s", variable, flag.Name)
+				return
+			}
+			err = fmt.Errorf("invalid value of environment variable %s for --%s: %w", variable, flag.Name, setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,152 @@
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironmentVariable(t *testing.T) {
+	assert.Equal(t, "CCOCTL_CREDENTIALS_REQUESTS_DIR", EnvironmentVariable("credentials-requests-dir"))
+	assert.Equal(t, "CCOCTL_TIMEOUT", EnvironmentVariable("timeout"))
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		config      string
+		args        []string
+		expectError string
+		verify      func(t *testing.T, flags *testConfigFlags)
+	}{
+		{
+			name: "Flags set from the environment",
+			env: map[string]string{
+				"CCOCTL_TIMEOUT":             "30m",
+				"CCOCTL_NAME":                "env-name",
+				"CCOCTL_DRY_RUN":             "true",
+				"CCOCTL_OIDC_EXTRA_AUDIENCE": "sts.amazonaws.com,openshift",
+				"CCOCTL_USER_TAGS":           "team=platform",
+			},
+			args: []string{"aws", "create-all"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Equal(t, 30*time.Minute, flags.timeout)
+				assert.Equal(t, "env-name", flags.name)
+				assert.True(t, flags.dryRun)
+				assert.Equal(t, []string{"sts.amazonaws.com", "openshift"}, flags.extraAudiences)
+				assert.Equal(t, map[string]string{"team": "platform"}, flags.userTags)
+			},
+		},
+		{
+			name: "Flag over environment over configuration file",
+			env: map[string]string{
+				"CCOCTL_NAME":   "env-name",
+				"CCOCTL_REGION": "eu-west-1",
+			},
+			config: `
+aws:
+  create-all:
+    name: config-name
+    region: us-east-1
+    credentials-requests-dir: ./credrequests
+`,
+			args: []string{"aws", "create-all", "--name", "cli-name"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Equal(t, "cli-name", flags.name, "the command line should take precedence")
+				assert.Equal(t, "eu-west-1", flags.region, "the environment should take precedence over the configuration file")
+				assert.Equal(t, "./credrequests", flags.credReqDir, "the configuration file should apply to the flags without an environment variable")
+			},
+		},
+		{
+			name: "Empty environment variables ignored",
+			env: map[string]string{
+				"CCOCTL_REGION": "",
+			},
+			config: `
+aws:
+  create-all:
+    region: us-east-1
+`,
+			args: []string{"aws", "create-all"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Equal(t, "us-east-1", flags.region)
+			},
+		},
+		{
+			name: "Environment variables of the flags of other commands ignored",
+			env: map[string]string{
+				"CCOCTL_SUBSCRIPTION_ID": "00000000-0000-0000-0000-000000000000",
+			},
+			args: []string{"aws", "create-all"},
+			verify: func(t *testing.T, flags *testConfigFlags) {
+				assert.Empty(t, flags.subscriptionID)
+			},
+		},
+		{
+			name: "Invalid value reported",
+			env: map[string]string{
+				"CCOCTL_DRY_RUN": "sometimes",
+			},
+			args:        []string{"aws", "create-all"},
+			expectError: `invalid value of environment variable CCOCTL_DRY_RUN for --dry-run: invalid argument "sometimes"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for variable, value := range test.env {
+				t.Setenv(variable, value)
+			}
+
+			flags := &testConfigFlags{}
+			rootCmd, _ := testConfigCommands(flags)
+			cmd, args, err := rootCmd.Find(test.args)
+			require.NoError(t, err)
+			require.NoError(t, cmd.ParseFlags(args))
+
+			err = ApplyEnvironment(cmd)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			if test.config != "" {
+				path := filepath.Join(t.TempDir(), "ccoctl.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(test.config), 0600))
+				require.NoError(t, ApplyConfigFile(cmd, path), "unexpected error applying configuration file")
+			}
+			test.verify(t, flags)
+		})
+	}
+}
+
+func TestApplyEnvironmentSensitiveValue(t *testing.T) {
+	var tokenLifetime time.Duration
+	cmd := &cobra.Command{Use: "ccoctl"}
+	cmd.Flags().DurationVar(&tokenLifetime, "token-lifetime", 0, "Token lifetime")
+	require.NoError(t, cmd.ParseFlags([]string{}))
+	t.Setenv("CCOCTL_TOKEN_LIFETIME", "s3cr3t")
+
+	err := ApplyEnvironment(cmd)
+	require.Error(t, err, "expected error")
+	assert.Equal(t, "invalid value of environment variable CCOCTL_TOKEN_LIFETIME for --token-lifetime", err.Error())
+	assert.NotContains(t, err.Error(), "s3cr3t", "the value of a sensitive flag should not be reported")
+}
+
+func TestApplyEnvironmentRequiredFlags(t *testing.T) {
+	flags := &testConfigFlags{}
+	_, createAllCmd := testConfigCommands(flags)
+	require.NoError(t, createAllCmd.ParseFlags([]string{}))
+	require.Error(t, createAllCmd.ValidateRequiredFlags(), "expected the required flag to be missing")
+
+	t.Setenv("CCOCTL_NAME", "env-name")
+	require.NoError(t, ApplyEnvironment(createAllCmd))
+	require.NoError(t, createAllCmd.ValidateRequiredFlags(), "the required flag should be set from the environment")
+}
@@ -0,0 +1,229 @@
+package provisioning
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OutputText is the default output format, progress is only reported through the logs written to stderr
+	OutputText = "text"
+	// OutputJSONStream is the output format in which a JSON object is written to stdout on its own line as each
+	// resource is created, updated or deleted, followed by a final summary object
+	OutputJSONStream = "json-stream"
+	// OutputYAML is the output format in which the fields of install-config.yaml to set for the created credentials
+	// are written to stdout as YAML once a create-all command has succeeded
+	OutputYAML = "yaml"
+
+	// ResourceCreated, ResourceUpdated and ResourceDeleted are the statuses of the progress events of resources
+	ResourceCreated = "created"
+	ResourceUpdated = "updated"
+	ResourceDeleted = "deleted"
+
+	// summaryEventType is the type of the progress event written once the command has completed
+	summaryEventType = "summary"
+)
+
+// OutputFormats are the supported values of --output
+var OutputFormats = []string{OutputText, OutputJSONStream, OutputYAML}
+
+// ProgressEvent is written to stdout as a single line of JSON for each resource which is created, updated or
+// deleted when the json-stream output format is enabled. A final event of type "summary" counts the events of each
+// status and reports whether the command succeeded.
+type ProgressEvent struct {
+	// Type is the type of the resource, eg. "IAMRole", or "summary" for the final event
+	Type string `json:"type"`
+	// ID identifies the resource, eg. its ARN or name
+	ID string `json:"id,omitempty"`
+	// Status is the operation completed on the resource or, for the final event, "succeeded" or "failed"
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	// Counts is the number of resources reported for each status, only set on the final event, or the number of
+	// resources of each type discovered for deletion, only set on the event of type "deletionSummary"
+	Counts map[string]int `json:"counts,omitempty"`
+	// Error is the error with which the command failed, only set on the final event
+	Error string `json:"error,omitempty"`
+	// NextSteps are the steps remaining to install a cluster with the created credentials, only set on the final
+	// event of a successful create
+	NextSteps []*NextSteps `json:"nextSteps,omitempty"`
+}
+
+// progressStream writes progress events to out. Events may be emitted concurrently by operations running in
+// parallel so writes are serialized and every event is flushed as it is written.
+type progressStream struct {
+	mu       sync.Mutex
+	out      *bufio.Writer
+	counts   map[string]int
+	finished bool
+	now      func() time.Time
+	// nextSteps are reported within the summary event when the command succeeds
+	nextSteps []*NextSteps
+}
+
+// stream is the stream to which progress events are written, it is nil unless the json-stream output format is enabled
+var stream *progressStream
+
+// InitOutput configures the output format of ccoctl. When format is OutputJSONStream progress events are written to
+// stdout and a failed summary event is written when ccoctl exits through log.Fatal. When format is OutputYAML the
+// install-config snippets of the next steps are written to stdout.
+func InitOutput(format string) error {
+	stream = nil
+	installConfigOut = nil
+	switch format {
+	case OutputText:
+		return nil
+	case OutputJSONStream:
+		stream = newProgressStream(os.Stdout)
+		log.SetOutput(&fatalSummaryWriter{out: log.Writer(), flags: log.Flags()})
+		return nil
+	case OutputYAML:
+		installConfigOut = os.Stdout
+		return nil
+	default:
+		return fmt.Errorf("invalid --output %q, expected one of %s", format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+func newProgressStream(out io.Writer) *progressStream {
+	return &progressStream{
+		out:    bufio.NewWriter(out),
+		counts: map[string]int{},
+		now:    time.Now,
+	}
+}
+
+// JSONStreamEnabled returns true when progress events are written to stdout, which must then not be written to
+// otherwise
+func JSONStreamEnabled() bool {
+	return stream != nil
+}
+
+// YAMLOutputEnabled returns true when the install-config snippets of the next steps are written to stdout, which
+// must then not be written to otherwise
+func YAMLOutputEnabled() bool {
+	return installConfigOut != nil
+}
+
+// EmitResourceEvent reports that the resource of resourceType identified by id was created, updated or deleted, as
+// indicated by status, and records it within the audit log of --audit-log. No event is written unless the json-stream
+// output format is enabled.
+func EmitResourceEvent(resourceType, id, status string) {
+	auditResource(resourceType, id, status)
+	if stream == nil {
+		return
+	}
+	if err := stream.emit(ProgressEvent{Type: resourceType, ID: id, Status: status}); err != nil {
+		Warnf("Failed to write progress event: %s", err)
+	}
+}
+
+// FinishOutput writes the summary event reporting whether the command failed with err. It is safe to call more than
+// once, only the first summary is written.
+func FinishOutput(err error) {
+	if stream == nil {
+		return
+	}
+	if err := stream.finish(err); err != nil {
+		Warnf("Failed to write progress event: %s", err)
+	}
+}
+
+func (s *progressStream) emit(event ProgressEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return nil
+	}
+	s.counts[event.Status]++
+	return s.write(event)
+}
+
+// emitUncounted writes event without counting it within the summary event, eg. as it reports resources which are yet
+// to be deleted
+func (s *progressStream) emitUncounted(event ProgressEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return nil
+	}
+	return s.write(event)
+}
+
+func (s *progressStream) finish(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return nil
+	}
+	s.finished = true
+	summary := ProgressEvent{Type: summaryEventType, Status: "succeeded", Counts: s.counts}
+	if err != nil {
+		summary.Status = "failed"
+		summary.Error = err.Error()
+	} else {
+		summary.NextSteps = s.nextSteps
+	}
+	return s.write(summary)
+}
+
+// addNextSteps records next steps to report within the summary event. Several providers may report next steps
+// concurrently, eg. when running "multicloud create-all".
+func (s *progressStream) addNextSteps(nextSteps *NextSteps) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextSteps = append(s.nextSteps, nextSteps)
+}
+
+// write must be called with mu held. Nothing may be logged while mu is held since the summary is written from
+// within the standard logger when ccoctl exits through log.Fatal. The secret material within the identifiers and
+// errors reported is redacted.
+func (s *progressStream) write(event ProgressEvent) error {
+	event.ID = Redact(event.ID)
+	event.Error = Redact(event.Error)
+	event.Timestamp = s.now().UTC()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := s.out.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return s.out.Flush()
+}
+
+// fatalSummaryWriter wraps the output of the standard logger to write a failed summary event before the message
+// logged by log.Fatal, after which ccoctl exits without returning to its caller
+type fatalSummaryWriter struct {
+	out io.Writer
+	// flags are the flags of the standard logger, which may not be read while it is writing
+	flags int
+}
+
+func (w *fatalSummaryWriter) Write(p []byte) (int, error) {
+	if loggedByFatalOrPanic() && stream != nil {
+		// The standard logger is locked while writing so errors writing the summary can't be logged
+		stream.finish(errors.New(w.message(p)))
+	}
+	return w.out.Write(p)
+}
+
+// message returns the message logged in p without the date and time with which the standard logger prefixes it
+func (w *fatalSummaryWriter) message(p []byte) string {
+	fields := 0
+	if w.flags&log.Ldate != 0 {
+		fields++
+	}
+	if w.flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		fields++
+	}
+	parts := strings.SplitN(string(p), " ", fields+1)
+	return strings.TrimSpace(parts[len(parts)-1])
+}
@@ -0,0 +1,120 @@
+package provisioning
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitOutput(t *testing.T) {
+	defer func() { stream, installConfigOut = nil, nil }()
+
+	assert.NoError(t, InitOutput(OutputText), "unexpected error with text output")
+	assert.False(t, JSONStreamEnabled(), "expected progress events to be disabled with text output")
+	assert.False(t, YAMLOutputEnabled(), "expected install-config snippets to be disabled with text output")
+	assert.NoError(t, InitOutput(OutputYAML), "unexpected error with yaml output")
+	assert.True(t, YAMLOutputEnabled(), "expected install-config snippets to be enabled with yaml output")
+	assert.False(t, JSONStreamEnabled(), "expected progress events to be disabled with yaml output")
+	assert.Error(t, InitOutput("xml"), "expected error with unsupported output")
+}
+
+func TestProgressStream(t *testing.T) {
+	out := &bytes.Buffer{}
+	stream = testProgressStream(out)
+	defer func() { stream = nil }()
+
+	EmitResourceEvent("IAMRole", "arn:aws:iam::123456789012:role/one", ResourceCreated)
+	EmitResourceEvent("IAMRole", "arn:aws:iam::123456789012:role/two", ResourceCreated)
+	EmitResourceEvent("S3Bucket", "test-oidc", ResourceDeleted)
+	FinishOutput(errors.New("failed to delete"))
+	FinishOutput(nil)
+	EmitResourceEvent("S3Bucket", "ignored", ResourceDeleted)
+
+	events := decodeProgressEvents(t, out)
+	require.Len(t, events, 4, "expected an event per resource followed by a single summary")
+	assert.Equal(t, ProgressEvent{
+		Type:      "IAMRole",
+		ID:        "arn:aws:iam::123456789012:role/one",
+		Status:    ResourceCreated,
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, events[0], "unexpected resource event")
+	assert.Equal(t, ProgressEvent{
+		Type:      summaryEventType,
+		Status:    "failed",
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Counts:    map[string]int{ResourceCreated: 2, ResourceDeleted: 1},
+		Error:     "failed to delete",
+	}, events[3], "unexpected summary event")
+}
+
+func TestProgressStreamConcurrentEvents(t *testing.T) {
+	out := &bytes.Buffer{}
+	stream = testProgressStream(out)
+	defer func() { stream = nil }()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			EmitResourceEvent("UserAssignedManagedIdentity", fmt.Sprintf("identity-%d", i), ResourceCreated)
+		}(i)
+	}
+	wg.Wait()
+	FinishOutput(nil)
+
+	events := decodeProgressEvents(t, out)
+	require.Len(t, events, 51, "expected every event to be written on its own line")
+	assert.Equal(t, "succeeded", events[50].Status, "unexpected summary status")
+	assert.Equal(t, map[string]int{ResourceCreated: 50}, events[50].Counts, "unexpected summary counts")
+}
+
+func TestFatalSummaryWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	stream = testProgressStream(out)
+	defer func() { stream = nil }()
+
+	logs := &bytes.Buffer{}
+	writer := &fatalSummaryWriter{out: logs, flags: log.LstdFlags}
+	assert.Equal(t, "failed to create role", writer.message([]byte("2024/01/02 03:04:05 failed to create role\n")), "unexpected message")
+
+	logger := log.New(writer, "", log.LstdFlags)
+	logger.Print("not fatal")
+	assert.Empty(t, out.String(), "expected no summary for messages not logged by log.Fatal")
+	assert.Panics(t, func() { logger.Panic("failed to create role") }, "expected log.Panic to panic")
+
+	events := decodeProgressEvents(t, out)
+	require.Len(t, events, 1, "expected a summary to be written")
+	assert.Equal(t, "failed", events[0].Status, "unexpected summary status")
+	assert.Equal(t, "failed to create role", events[0].Error, "unexpected summary error")
+	assert.Contains(t, logs.String(), "failed to create role", "expected message to be logged")
+}
+
+func testProgressStream(out *bytes.Buffer) *progressStream {
+	s := newProgressStream(out)
+	s.now = func() time.Time {
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+	return s
+}
+
+func decodeProgressEvents(t *testing.T, out *bytes.Buffer) []ProgressEvent {
+	events := []ProgressEvent{}
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		event := ProgressEvent{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event), "expected each line to be a JSON object")
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,196 @@
+package external
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const secretManifestsTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+%s  name: %s
+  namespace: %s
+type: Opaque
+data:
+%s`
+
+var (
+	// CreateAllOpts captures the options that affect creation of the generated objects
+	CreateAllOpts = options{}
+)
+
+func createAllCmd(cmd *cobra.Command, args []string) {
+	pluginPath, err := resolvePlugin(CreateAllOpts.Plugin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	publicKeyPath := CreateAllOpts.PublicKeyPath
+	if publicKeyPath == "" {
+		if err := provisioning.CreateKeys(CreateAllOpts.TargetDir); err != nil {
+			log.Fatalf("Failed to create public/private key pair: %s", err)
+		}
+		publicKeyPath = filepath.Join(CreateAllOpts.TargetDir, provisioning.PublicKeyFile)
+	}
+
+	provisioning.SetPhase("creating the resources with external provider " + CreateAllOpts.Plugin)
+	if err := createAll(cmd.Context(), pluginPath, publicKeyPath, CreateAllOpts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// createAll requests the external provider at pluginPath to create the resources of the CredentialsRequests within
+// opts.CredRequestDirs, then writes the secret manifests it returned and, when it returned an issuer URL, the
+// authentication manifest setting the issuer of the cluster to the output directory
+func createAll(ctx context.Context, pluginPath, publicKeyPath string, opts options) error {
+	credReqs, err := provisioning.GetListOfCredentialsRequests(opts.CredRequestDirs, opts.EnableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	publicKey, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read public key file %s", publicKeyPath)
+	}
+
+	response, err := runPlugin(ctx, pluginPath, Request{
+		Operation:           OperationCreate,
+		Name:                opts.Name,
+		CredentialsRequests: credReqs,
+		Options:             opts.PluginOptions,
+		PublicKey:           string(publicKey),
+	})
+	if err != nil {
+		return err
+	}
+	for _, resource := range response.Resources {
+		log.Printf("External provider %s %s %s", resource.Status, resource.Type, resource.ID)
+		provisioning.EmitResourceEvent(resource.Type, resource.ID, resource.Status)
+	}
+
+	if response.IssuerURL != "" {
+		if u, err := url.Parse(response.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("external provider returned the invalid issuer URL %q, expected an https URL", response.IssuerURL)
+		}
+	}
+
+	// Every CredentialsRequest is granted its secret, secrets which do not match a CredentialsRequest are refused
+	secrets := map[string]Secret{}
+	for _, secret := range response.Secrets {
+		secrets[secret.Namespace+"/"+secret.Name] = secret
+	}
+	for _, cr := range credReqs {
+		secretRef := cr.Spec.SecretRef.Namespace + "/" + cr.Spec.SecretRef.Name
+		secret, found := secrets[secretRef]
+		if !found {
+			return fmt.Errorf("external provider returned no secret for CredentialsRequest %s/%s targeting secret %s", cr.Namespace, cr.Name, secretRef)
+		}
+		delete(secrets, secretRef)
+		if err := writeCredReqSecret(cr, opts.TargetDir, secret, response.IssuerURL); err != nil {
+			return err
+		}
+	}
+	if len(secrets) > 0 {
+		unknown := make([]string, 0, len(secrets))
+		for secretRef := range secrets {
+			unknown = append(unknown, secretRef)
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("external provider returned secrets targeted by no CredentialsRequest: %s", strings.Join(unknown, ", "))
+	}
+
+	if response.IssuerURL != "" {
+		if err := provisioning.CreateClusterAuthentication(response.IssuerURL, opts.TargetDir, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCredReqSecret writes the manifest of secret returned for cr to the manifests directory within targetDir
+func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir string, secret Secret, issuerURL string) error {
+	if len(secret.Data) == 0 {
+		return fmt.Errorf("external provider returned an empty secret for CredentialsRequest %s/%s", cr.Namespace, cr.Name)
+	}
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var data strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&data, "  %s: %s\n", key, base64.StdEncoding.EncodeToString([]byte(secret.Data[key])))
+	}
+
+	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+	filePath := filepath.Join(targetDir, provisioning.ManifestsDirName, fileName)
+	fileData := fmt.Sprintf(secretManifestsTemplate, provisioning.ProvenanceAnnotations(cr, issuerURL), cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace, data.String())
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
+		return errors.Wrap(err, "failed to save secret manifest")
+	}
+	log.Printf("Saved credentials configuration to: %s", filePath)
+	return nil
+}
+
+// initEnvForCreateAllCmd will ensure the destination directory is ready to receive the generated files, and will
+// create the directory if necessary
+func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
+	if err := provisioning.ValidateCredentialsRequests(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+
+	if CreateAllOpts.TargetDir == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			log.Fatalf("Failed to get current directory: %s", err)
+		}
+		CreateAllOpts.TargetDir = pwd
+	}
+
+	fPath, err := filepath.Abs(CreateAllOpts.TargetDir)
+	if err != nil {
+		log.Fatalf("Failed to resolve full path: %s", err)
+	}
+	CreateAllOpts.TargetDir = fPath
+
+	for _, dir := range []string{fPath, filepath.Join(fPath, provisioning.ManifestsDirName), filepath.Join(fPath, provisioning.TLSDirName)} {
+		if err := provisioning.EnsureDir(dir); err != nil {
+			log.Fatalf("failed to create directory at %s", dir)
+		}
+	}
+
+	provisioning.EnsureSecretsDir(filepath.Join(fPath, provisioning.ManifestsDirName), CreateAllOpts.AllowInsecureSecretsDir)
+}
+
+// NewCreateAllCmd provides the "create-all" subcommand
+func NewCreateAllCmd() *cobra.Command {
+	createAllCmd := &cobra.Command{
+		Use:              "create-all",
+		Short:            "Create all the required credentials objects with an external provider",
+		Run:              createAllCmd,
+		PersistentPreRun: initEnvForCreateAllCmd,
+	}
+
+	addPluginFlags(createAllCmd, &CreateAllOpts)
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create credentials for. May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
+	createAllCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.PublicKeyPath, "public-key-file", "", "Path to public ServiceAccount signing key provided to the external provider (a key pair is generated within the output directory when not specified)")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	provisioning.AddAllowInsecureSecretsDirFlag(createAllCmd.PersistentFlags(), &CreateAllOpts.AllowInsecureSecretsDir)
+
+	return createAllCmd
+}
@@ -0,0 +1,61 @@
+package external
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// DeleteOpts captures the options that affect deletion of the cloud resources
+	DeleteOpts = options{}
+)
+
+func deleteCmd(cmd *cobra.Command, args []string) {
+	pluginPath, err := resolvePlugin(DeleteOpts.Plugin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	provisioning.SetPhase("deleting the resources with external provider " + DeleteOpts.Plugin)
+	if err := deleteResources(cmd.Context(), pluginPath, DeleteOpts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// deleteResources requests the external provider at pluginPath to delete the resources it created for opts.Name
+func deleteResources(ctx context.Context, pluginPath string, opts options) error {
+	response, err := runPlugin(ctx, pluginPath, Request{
+		Operation: OperationDelete,
+		Name:      opts.Name,
+		Options:   opts.PluginOptions,
+	})
+	if err != nil {
+		return err
+	}
+	if len(response.Resources) == 0 {
+		log.Printf("External provider found no resources to delete for %s", opts.Name)
+	}
+	for _, resource := range response.Resources {
+		log.Printf("External provider %s %s %s", resource.Status, resource.Type, resource.ID)
+		provisioning.EmitResourceEvent(resource.Type, resource.ID, resource.Status)
+	}
+	return nil
+}
+
+// NewDeleteCmd provides the "delete" subcommand
+func NewDeleteCmd() *cobra.Command {
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete credentials objects with an external provider",
+		Long:  "Deleting the cloud resources created by an external provider for --name",
+		Run:   deleteCmd,
+	}
+
+	addPluginFlags(deleteCmd, &DeleteOpts)
+
+	return deleteCmd
+}
@@ -0,0 +1,42 @@
+package external
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type options struct {
+	Plugin                  string
+	PluginOptions           map[string]string
+	Name                    string
+	CredRequestDirs         []string
+	TargetDir               string
+	PublicKeyPath           string
+	EnableTechPreview       bool
+	AllowInsecureSecretsDir bool
+}
+
+// NewExternalCmd implements the "external" subcommand for the credentials provisioning with external providers, which
+// are executables implementing the exec protocol of Request and Response for the clouds ccoctl does not support
+func NewExternalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "external",
+		Short: "Manage credentials objects with an external provider",
+		Long: "Creating/deleting/verifying cloud credentials objects with an external provider plugin, an executable to which ccoctl " +
+			"writes a JSON request on stdin and from which it reads a JSON response on stdout",
+	}
+
+	cmd.AddCommand(NewCreateAllCmd())
+	cmd.AddCommand(NewDeleteCmd())
+	cmd.AddCommand(NewVerifyCmd())
+
+	return cmd
+}
+
+// addPluginFlags adds the flags selecting the external provider and providing its options to cmd
+func addPluginFlags(cmd *cobra.Command, opts *options) {
+	cmd.PersistentFlags().StringVar(&opts.Plugin, "plugin", "", "External provider plugin, either the path of its executable or the name of a provider whose executable ccoctl-provider-<name> is found on PATH")
+	cmd.MarkPersistentFlagRequired("plugin")
+	cmd.PersistentFlags().StringToStringVar(&opts.PluginOptions, "plugin-option", map[string]string{}, "Option of the external provider as key=value, eg. --plugin-option=region=eu-1. May be specified multiple times, or as a comma-separated list")
+	cmd.PersistentFlags().StringVar(&opts.Name, "name", "", "User-defined name for all resources created by the external provider")
+	cmd.MarkPersistentFlagRequired("name")
+}
@@ -0,0 +1,287 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// referenceProviderEnv makes the test binary act as the reference external provider
+	referenceProviderEnv = "GO_WANT_REFERENCE_PROVIDER"
+
+	testCredReqDirPrefix = "external_test_dir_credreq"
+	testTargetDirPrefix  = "external_test_dir_target"
+	testStateDirPrefix   = "external_test_dir_state"
+
+	testName      = "test-name"
+	testIssuerURL = "https://issuer.example.com/test-name"
+
+	credReqTemplate = `---
+apiVersion: cloudcredential.openshift.io/v1
+kind: CredentialsRequest
+metadata:
+  name: %s
+  namespace: openshift-cloud-credential-operator
+spec:
+  providerSpec:
+    apiVersion: cloudcredential.openshift.io/v1
+    kind: ExternalProviderSpec
+  secretRef:
+    name: %s
+    namespace: %s`
+)
+
+// TestMain runs the reference external provider instead of the tests when the test binary is executed by runPlugin
+func TestMain(m *testing.M) {
+	if os.Getenv(referenceProviderEnv) == "1" {
+		os.Exit(referenceProvider())
+	}
+	os.Exit(m.Run())
+}
+
+// referenceProvider is the reference implementation of an external provider. It records the service accounts it
+// creates within the "state-file" option, grants each CredentialsRequest a secret holding a token, and returns the
+// "issuer-url" option as its issuer. The "fail" option makes it fail every operation with its value.
+func referenceProvider() int {
+	request := Request{}
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to decode request: %s\n", err)
+		return 1
+	}
+	respond := func(response Response) int {
+		response.APIVersion = ProtocolVersion
+		if apiVersion, ok := request.Options["response-api-version"]; ok {
+			response.APIVersion = apiVersion
+		}
+		json.NewEncoder(os.Stdout).Encode(response)
+		if response.Error != "" {
+			return 1
+		}
+		return 0
+	}
+	if request.APIVersion != ProtocolVersion {
+		return respond(Response{Error: fmt.Sprintf("unsupported apiVersion %q", request.APIVersion)})
+	}
+	if message, ok := request.Options["fail"]; ok {
+		return respond(Response{Error: message})
+	}
+
+	stateFile := request.Options["state-file"]
+	created := []string{}
+	if data, err := os.ReadFile(stateFile); err == nil {
+		json.Unmarshal(data, &created)
+	}
+
+	response := Response{}
+	switch request.Operation {
+	case OperationCreate:
+		if !strings.Contains(request.PublicKey, "PUBLIC KEY") {
+			return respond(Response{Error: "no public key"})
+		}
+		for _, cr := range request.CredentialsRequests {
+			id := request.Name + "-" + cr.Name
+			created = append(created, id)
+			response.Resources = append(response.Resources, Resource{Type: "ServiceAccount", ID: id, Status: provisioning.ResourceCreated})
+			if request.Options["skip-secrets"] != "" {
+				continue
+			}
+			response.Secrets = append(response.Secrets, Secret{
+				Namespace: cr.Spec.SecretRef.Namespace,
+				Name:      cr.Spec.SecretRef.Name,
+				Data:      map[string]string{"token": "token-" + id},
+			})
+		}
+		if extra := request.Options["extra-secret"]; extra != "" {
+			response.Secrets = append(response.Secrets, Secret{Namespace: "default", Name: extra, Data: map[string]string{"token": "extra"}})
+		}
+		response.IssuerURL = request.Options["issuer-url"]
+	case OperationDelete:
+		for _, id := range created {
+			response.Resources = append(response.Resources, Resource{Type: "ServiceAccount", ID: id, Status: provisioning.ResourceDeleted})
+		}
+		created = nil
+	case OperationVerify:
+		for _, cr := range request.CredentialsRequests {
+			id := request.Name + "-" + cr.Name
+			status := ResourceMissing
+			for _, createdID := range created {
+				if createdID == id {
+					status = ResourcePresent
+				}
+			}
+			response.Resources = append(response.Resources, Resource{Type: "ServiceAccount", ID: id, Status: status})
+		}
+	default:
+		return respond(Response{Error: fmt.Sprintf("unsupported operation %q", request.Operation)})
+	}
+
+	data, _ := json.Marshal(created)
+	if err := os.WriteFile(stateFile, data, 0600); err != nil {
+		return respond(Response{Error: err.Error()})
+	}
+	return respond(response)
+}
+
+// testPlugin returns the path of the test binary, executed as the reference external provider
+func testPlugin(t *testing.T) string {
+	t.Setenv(referenceProviderEnv, "1")
+	path, err := os.Executable()
+	require.NoError(t, err, "Failed to find the test binary")
+	return path
+}
+
+func testCredReqDir(t *testing.T, names ...string) string {
+	credReqDir, err := ioutil.TempDir(os.TempDir(), testCredReqDirPrefix)
+	require.NoError(t, err, "Failed to create temp directory for credentials requests")
+	t.Cleanup(func() { os.RemoveAll(credReqDir) })
+	for _, name := range names {
+		credReq := fmt.Sprintf(credReqTemplate, name, name+"-secret", "namespace-"+name)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(credReqDir, name+".yaml"), []byte(credReq), 0600), "Failed to write CredentialsRequest")
+	}
+	return credReqDir
+}
+
+func testOptions(t *testing.T, credReqDir string, pluginOptions map[string]string) (options, string) {
+	targetDir, err := ioutil.TempDir(os.TempDir(), testTargetDirPrefix)
+	require.NoError(t, err, "Failed to create temp directory for output")
+	t.Cleanup(func() { os.RemoveAll(targetDir) })
+	require.NoError(t, os.Mkdir(filepath.Join(targetDir, provisioning.ManifestsDirName), 0700))
+
+	stateDir, err := ioutil.TempDir(os.TempDir(), testStateDirPrefix)
+	require.NoError(t, err, "Failed to create temp directory for provider state")
+	t.Cleanup(func() { os.RemoveAll(stateDir) })
+	pluginOptions["state-file"] = filepath.Join(stateDir, "state.json")
+
+	publicKeyPath := filepath.Join(targetDir, provisioning.PublicKeyFile)
+	require.NoError(t, ioutil.WriteFile(publicKeyPath, []byte("-----BEGIN PUBLIC KEY-----\ntest\n-----END PUBLIC KEY-----\n"), 0600))
+
+	return options{
+		Name:            testName,
+		CredRequestDirs: []string{credReqDir},
+		TargetDir:       targetDir,
+		PluginOptions:   pluginOptions,
+	}, publicKeyPath
+}
+
+func TestCreateAll(t *testing.T) {
+	tests := []struct {
+		name          string
+		pluginOptions map[string]string
+		verify        func(t *testing.T, targetDir string)
+		expectedErr   string
+	}{
+		{
+			name:          "Secrets and issuer",
+			pluginOptions: map[string]string{"issuer-url": testIssuerURL},
+			verify: func(t *testing.T, targetDir string) {
+				secret, err := ioutil.ReadFile(filepath.Join(targetDir, provisioning.ManifestsDirName, "namespace-first-first-secret-credentials.yaml"))
+				require.NoError(t, err, "Failed to read secret manifest")
+				assert.Contains(t, string(secret), "  name: first-secret\n  namespace: namespace-first\n")
+				// base64 of "token-test-name-first"
+				assert.Contains(t, string(secret), "  token: dG9rZW4tdGVzdC1uYW1lLWZpcnN0\n")
+				assert.Contains(t, string(secret), testIssuerURL)
+
+				auth, err := ioutil.ReadFile(filepath.Join(targetDir, provisioning.ManifestsDirName, "cluster-authentication-02-config.yaml"))
+				require.NoError(t, err, "Failed to read authentication manifest")
+				assert.Contains(t, string(auth), "serviceAccountIssuer: "+testIssuerURL)
+			},
+		},
+		{
+			name:          "Secrets without issuer",
+			pluginOptions: map[string]string{},
+			verify: func(t *testing.T, targetDir string) {
+				assert.FileExists(t, filepath.Join(targetDir, provisioning.ManifestsDirName, "namespace-second-second-secret-credentials.yaml"))
+				assert.NoFileExists(t, filepath.Join(targetDir, provisioning.ManifestsDirName, "cluster-authentication-02-config.yaml"))
+			},
+		},
+		{
+			name:          "Provider failure",
+			pluginOptions: map[string]string{"fail": "quota exceeded"},
+			expectedErr:   "failed to create: quota exceeded",
+		},
+		{
+			name:          "Unsupported response apiVersion",
+			pluginOptions: map[string]string{"response-api-version": "v0"},
+			expectedErr:   `returned a response of apiVersion "v0"`,
+		},
+		{
+			name:          "Missing secret",
+			pluginOptions: map[string]string{"skip-secrets": "true"},
+			expectedErr:   "returned no secret for CredentialsRequest openshift-cloud-credential-operator/first",
+		},
+		{
+			name:          "Unknown secret",
+			pluginOptions: map[string]string{"extra-secret": "unknown"},
+			expectedErr:   "secrets targeted by no CredentialsRequest: default/unknown",
+		},
+		{
+			name:          "Insecure issuer",
+			pluginOptions: map[string]string{"issuer-url": "http://issuer.example.com"},
+			expectedErr:   "expected an https URL",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plugin := testPlugin(t)
+			opts, publicKeyPath := testOptions(t, testCredReqDir(t, "first", "second"), test.pluginOptions)
+
+			err := createAll(context.TODO(), plugin, publicKeyPath, opts)
+
+			if test.expectedErr != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			test.verify(t, opts.TargetDir)
+		})
+	}
+}
+
+func TestVerifyAndDeleteResources(t *testing.T) {
+	plugin := testPlugin(t)
+	opts, publicKeyPath := testOptions(t, testCredReqDir(t, "first"), map[string]string{})
+
+	err := verifyResources(context.TODO(), plugin, opts)
+	require.Error(t, err, "expected missing resources before creation")
+	assert.Contains(t, err.Error(), "1 resources are missing:\n  ServiceAccount test-name-first")
+
+	require.NoError(t, createAll(context.TODO(), plugin, publicKeyPath, opts))
+	require.NoError(t, verifyResources(context.TODO(), plugin, opts), "expected resources to be present after creation")
+
+	require.NoError(t, deleteResources(context.TODO(), plugin, opts))
+	err = verifyResources(context.TODO(), plugin, opts)
+	require.Error(t, err, "expected missing resources after deletion")
+}
+
+func TestResolvePlugin(t *testing.T) {
+	pathDir, err := ioutil.TempDir(os.TempDir(), testStateDirPrefix)
+	require.NoError(t, err, "Failed to create temp directory for PATH")
+	defer os.RemoveAll(pathDir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pathDir, "ccoctl-provider-test"), []byte("#!/bin/sh\n"), 0700))
+	t.Setenv("PATH", pathDir)
+
+	path, err := resolvePlugin("test")
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, filepath.Join(pathDir, "ccoctl-provider-test"), path)
+
+	path, err = resolvePlugin("./bin/provider")
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, "./bin/provider", path)
+
+	_, err = resolvePlugin("unknown")
+	require.Error(t, err, "expected error")
+	assert.Contains(t, err.Error(), "failed to find the executable of external provider unknown")
+}
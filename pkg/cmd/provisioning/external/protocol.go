@@ -0,0 +1,143 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+const (
+	// ProtocolVersion is the apiVersion of the requests sent to external providers and of the responses expected
+	ProtocolVersion = "ccoctl.openshift.io/v1alpha1"
+
+	// OperationCreate requests the external provider to create the cloud resources of the CredentialsRequests and to
+	// return the secrets granting access to them, along with the issuer URL serving the public key when the provider
+	// implements short-lived credentials
+	OperationCreate = "create"
+	// OperationDelete requests the external provider to delete the cloud resources it created for the name
+	OperationDelete = "delete"
+	// OperationVerify requests the external provider to report the state of the cloud resources of the
+	// CredentialsRequests without modifying them
+	OperationVerify = "verify"
+
+	// ResourcePresent and ResourceMissing are the statuses of the resources reported by the verify operation
+	ResourcePresent = "present"
+	ResourceMissing = "missing"
+
+	// pluginPrefix is the prefix of the name of the executables of the external providers referred to by name
+	pluginPrefix = "ccoctl-provider-"
+)
+
+// Request is written as JSON to the standard input of the external provider
+type Request struct {
+	// APIVersion is ProtocolVersion
+	APIVersion string `json:"apiVersion"`
+	// Operation is one of OperationCreate, OperationDelete or OperationVerify
+	Operation string `json:"operation"`
+	// Name is the --name of the command, which the resources created by the external provider are named after
+	Name string `json:"name"`
+	// CredentialsRequests are the CredentialsRequests to process, omitted by the delete operation
+	CredentialsRequests []*credreqv1.CredentialsRequest `json:"credentialsRequests,omitempty"`
+	// Options are the --plugin-option of the command, which are specific to the external provider
+	Options map[string]string `json:"options,omitempty"`
+	// PublicKey is the PEM encoded public key of the service account signing key, provided to the create
+	// operation so that the external provider may serve it from an OIDC issuer
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// Response is read as JSON from the standard output of the external provider once it exits
+type Response struct {
+	// APIVersion is ProtocolVersion
+	APIVersion string `json:"apiVersion"`
+	// Resources are the resources created or deleted by the operation, or reported by the verify operation
+	Resources []Resource `json:"resources,omitempty"`
+	// Secrets are the secrets returned by the create operation, one for the secretRef of each CredentialsRequest
+	Secrets []Secret `json:"secrets,omitempty"`
+	// IssuerURL is the URL of the OIDC issuer serving the public key, returned by the create operation of the
+	// external providers implementing short-lived credentials
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// Error describes the failure of the operation, the external provider exits with a non-zero status
+	Error string `json:"error,omitempty"`
+}
+
+// Resource is a cloud resource handled by the external provider
+type Resource struct {
+	// Type is the type of the resource, eg. "ServiceAccount"
+	Type string `json:"type"`
+	// ID identifies the resource within the cloud
+	ID string `json:"id"`
+	// Status is "created", "updated" or "deleted", or ResourcePresent or ResourceMissing for the verify operation
+	Status string `json:"status"`
+}
+
+// Secret is a secret returned by the external provider
+type Secret struct {
+	// Namespace and Name are those of the secretRef of the CredentialsRequest
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Data are the keys of the secret and their values, which are base64 encoded within the secret manifest
+	Data map[string]string `json:"data"`
+}
+
+// resolvePlugin returns the path of the executable of the external provider plugin, which is a path when it contains a
+// path separator and otherwise the name of a provider whose executable ccoctl-provider-<plugin> is looked up on PATH
+func resolvePlugin(plugin string) (string, error) {
+	if plugin == "" {
+		return "", errors.New("--plugin must be provided")
+	}
+	if strings.ContainsRune(plugin, filepath.Separator) {
+		return plugin, nil
+	}
+	path, err := exec.LookPath(pluginPrefix + plugin)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the executable of external provider %s", plugin)
+	}
+	return path, nil
+}
+
+// runPlugin runs the executable of the external provider at path with request written to its standard input and
+// returns the response it wrote to its standard output. The standard error of the provider is passed through to
+// ccoctl's own so that its logs are visible. An error is returned when the provider fails or its response is invalid.
+func runPlugin(ctx context.Context, path string, request Request) (*Response, error) {
+	request.APIVersion = ProtocolVersion
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the request of the external provider")
+	}
+
+	stdout := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+
+	response := &Response{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		if runErr != nil {
+			return nil, errors.Wrapf(runErr, "external provider %s failed to %s", path, request.Operation)
+		}
+		// The response may hold secrets, it is not quoted
+		return nil, fmt.Errorf("external provider %s returned an invalid response to %s: %s", path, request.Operation, err)
+	}
+	if runErr != nil || response.Error != "" {
+		message := response.Error
+		if message == "" {
+			message = runErr.Error()
+		}
+		return nil, fmt.Errorf("external provider %s failed to %s: %s", path, request.Operation, message)
+	}
+	if response.APIVersion != ProtocolVersion {
+		return nil, fmt.Errorf("external provider %s returned a response of apiVersion %q to %s, expected %s", path, response.APIVersion, request.Operation, ProtocolVersion)
+	}
+	return response, nil
+}
@@ -0,0 +1,83 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+var (
+	// VerifyOpts captures the options that affect the verification of the cloud resources
+	VerifyOpts = options{}
+)
+
+func verifyCmd(cmd *cobra.Command, args []string) {
+	pluginPath, err := resolvePlugin(VerifyOpts.Plugin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	provisioning.SetPhase("verifying the resources with external provider " + VerifyOpts.Plugin)
+	if err := verifyResources(cmd.Context(), pluginPath, VerifyOpts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// verifyResources requests the external provider at pluginPath to report the state of the resources of the
+// CredentialsRequests within opts.CredRequestDirs, returning an error naming the resources it reports missing
+func verifyResources(ctx context.Context, pluginPath string, opts options) error {
+	credReqs, err := provisioning.GetListOfCredentialsRequests(opts.CredRequestDirs, opts.EnableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	response, err := runPlugin(ctx, pluginPath, Request{
+		Operation:           OperationVerify,
+		Name:                opts.Name,
+		CredentialsRequests: credReqs,
+		Options:             opts.PluginOptions,
+	})
+	if err != nil {
+		return err
+	}
+
+	missing := []string{}
+	for _, resource := range response.Resources {
+		switch resource.Status {
+		case ResourcePresent:
+			log.Printf("Verified %s %s", resource.Type, resource.ID)
+		case ResourceMissing:
+			missing = append(missing, resource.Type+" "+resource.ID)
+		default:
+			return fmt.Errorf("external provider reported %s %s with the unknown status %q, expected %s or %s", resource.Type, resource.ID, resource.Status, ResourcePresent, ResourceMissing)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%d resources are missing:\n  %s", len(missing), strings.Join(missing, "\n  "))
+	}
+	return nil
+}
+
+// NewVerifyCmd provides the "verify" subcommand
+func NewVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify credentials objects with an external provider",
+		Long:  "Verifying that the cloud resources of the CredentialsRequests created by an external provider for --name exist, without modifying them",
+		Run:   verifyCmd,
+	}
+
+	addPluginFlags(verifyCmd, &VerifyOpts)
+	verifyCmd.PersistentFlags().StringSliceVar(&VerifyOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests whose resources to verify. May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
+	verifyCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	verifyCmd.PersistentFlags().BoolVar(&VerifyOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+
+	return verifyCmd
+}
@@ -0,0 +1,35 @@
+package gcp
+
+import (
+	"github.com/pkg/errors"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// createAllResourceCounts returns the number of resources of each type created by ccoctl gcp create-all for the
+// CredentialsRequests within credReqDirs, a custom role being created for the CredentialsRequests which request
+// permissions
+func createAllResourceCounts(credReqDirs []string, enableTechPreview bool) (map[string]int, error) {
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create credReq codec")
+	}
+	counts := map[string]int{
+		"StorageBucket":            1,
+		"WorkloadIdentityPool":     1,
+		"WorkloadIdentityProvider": 1,
+		"IAMServiceAccount":        len(credReqs),
+	}
+	for _, cr := range credReqs {
+		gcpProviderSpec := credreqv1.GCPProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &gcpProviderSpec); err == nil && len(gcpProviderSpec.Permissions) > 0 {
+			counts["IAMCustomRole"]++
+		}
+	}
+	return counts, nil
+}
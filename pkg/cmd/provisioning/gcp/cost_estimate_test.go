@@ -0,0 +1,24 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAllResourceCounts(t *testing.T) {
+	credReqDir := t.TempDir()
+	require.NoError(t, testCredentialsRequest(t, "firstcredreq", "namespace1", "secretName1", credReqDir))
+	require.NoError(t, testCredentialsRequest(t, "secondcredreq", "namespace2", "secretName2", credReqDir))
+
+	counts, err := createAllResourceCounts([]string{credReqDir}, false)
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string]int{
+		"StorageBucket":            1,
+		"WorkloadIdentityPool":     1,
+		"WorkloadIdentityProvider": 1,
+		"IAMServiceAccount":        2,
+		"IAMCustomRole":            2,
+	}, counts)
+}
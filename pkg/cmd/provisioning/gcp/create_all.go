@@ -1,60 +1,109 @@
 package gcp
 
 import (
-	"context"
+	"fmt"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
-	"github.com/openshift/cloud-credential-operator/pkg/gcp"
 )
 
 var (
 	// CreateAllOpts captures the options that affect creation/updating
 	// of the generated objects.
 	CreateAllOpts = options{
-		TargetDir: "",
+		TargetDir:            "",
+		ServiceAccountsQuota: defaultServiceAccountsQuota,
 	}
 )
 
 func createAllCmd(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	if err := CreateAllOpts.DiscoveryDocument.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// The context of the command carries the deadline of --timeout
+	ctx := cmd.Context()
 
-	creds, err := loadCredentials(ctx)
+	creds, err := loadCredentials(ctx, CreateAllOpts.CredentialsFile, CreateAllOpts.ImpersonateServiceAccount)
 	if err != nil {
 		log.Fatalf("Failed to load credentials: %s", err)
 	}
 
-	gcpClient, err := gcp.NewClient(CreateAllOpts.Project, creds)
+	gcpClient, project, err := newClientForProject(ctx, CreateAllOpts.Project, creds, CreateAllOpts.QuotaProject)
 	if err != nil {
 		log.Fatalf("Failed to initiate GCP client: %s", err)
 	}
+	CreateAllOpts.Project = project.ID
 
 	publicKeyPath := CreateAllOpts.PublicKeyPath
 	if publicKeyPath == "" {
 		publicKeyPath = path.Join(CreateAllOpts.TargetDir, provisioning.PublicKeyFile)
 	}
 
+	provisioning.DumpConfig(cmd, func() map[string]string {
+		identity := CreateAllOpts.ImpersonateServiceAccount
+		if identity == "" {
+			identity = credentialsIdentity(creds)
+		}
+		return map[string]string{
+			"identity":                 identity,
+			"projectID":                project.ID,
+			"projectNumber":            strconv.FormatInt(project.Number, 10),
+			"workloadIdentityPool":     CreateAllOpts.Name,
+			"workloadIdentityProvider": CreateAllOpts.Name,
+			"oidcBucketName":           fmt.Sprintf("%s-oidc", CreateAllOpts.Name),
+			"publicKeyPath":            publicKeyPath,
+			"outputDir":                CreateAllOpts.TargetDir,
+		}
+	})
+
+	if !CreateAllOpts.SkipQuotaCheck {
+		provisioning.SetPhase("checking the service account quota")
+		if err := checkServiceAccountsQuota(ctx, gcpClient, CreateAllOpts.Name, CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview, CreateAllOpts.ServiceAccountsQuota); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if err := provisioning.CreateKeys(CreateAllOpts.TargetDir); err != nil {
 		log.Fatalf("Failed to create public/private key pair: %s", err)
 	}
 
+	provisioning.SetPhase("creating the workload identity pool")
 	if err = createWorkloadIdentityPool(ctx, gcpClient, CreateAllOpts.Name, CreateAllOpts.Project, CreateAllOpts.TargetDir, false); err != nil {
 		log.Fatalf("Failed to create workload identity pool: %s", err)
 	}
 
-	if err = createWorkloadIdentityProvider(ctx, gcpClient, CreateAllOpts.Name, CreateAllOpts.Region, CreateAllOpts.Project, CreateAllOpts.Name, publicKeyPath, CreateAllOpts.TargetDir, false); err != nil {
+	provisioning.SetPhase("creating the workload identity provider")
+	if err = createWorkloadIdentityProvider(ctx, gcpClient, CreateAllOpts.Name, CreateAllOpts.Region, CreateAllOpts.Project, CreateAllOpts.Name, publicKeyPath, CreateAllOpts.TargetDir, false, CreateAllOpts.VerifyIssuerReachable, CreateAllOpts.DiscoveryDocument); err != nil {
 		log.Fatalf("Failed to create workload identity provider: %s", err)
 	}
 
-	if err = createServiceAccounts(ctx, gcpClient, CreateAllOpts.Name, CreateAllOpts.Name, CreateAllOpts.Name, CreateAllOpts.CredRequestDir,
-		CreateAllOpts.TargetDir, CreateAllOpts.EnableTechPreview, false); err != nil {
+	provisioning.SetPhase("creating IAM service accounts")
+	if err = createServiceAccounts(ctx, gcpClient, CreateAllOpts.Name, CreateAllOpts.Name, CreateAllOpts.Name, CreateAllOpts.CredRequestDirs,
+		CreateAllOpts.TargetDir, project.Number, CreateAllOpts.EnableTechPreview, false); err != nil {
 		log.Fatalf("Failed to create IAM service accounts: %s", err)
 	}
+
+	// The next steps are derived from the output directory before the manifests are moved into the archive
+	nextSteps, err := provisioning.NewNextSteps("gcp", CreateAllOpts.TargetDir, publicKeyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if CreateAllOpts.OutputArchive != "" {
+		if err := provisioning.ArchiveOutputs(CreateAllOpts.TargetDir, CreateAllOpts.OutputArchive); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Wrote output archive %s", CreateAllOpts.OutputArchive)
+		nextSteps.OutputArchive = CreateAllOpts.OutputArchive
+	}
+	provisioning.PrintNextSteps(nextSteps)
 }
 
 // validationForCreateAllCmd will validate the arguments to the command, ensure the destination directory
@@ -64,6 +113,26 @@ func validationForCreateAllCmd(cmd *cobra.Command, args []string) {
 		log.Fatalf("Name can be at most 32 characters long")
 	}
 
+	if err := provisioning.ValidateCredentialsRequests(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if err := checkServiceAccountLimits(CreateAllOpts.CredRequestDirs, CreateAllOpts.Name, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if !CreateAllOpts.SkipDeprecatedPermissionsCheck {
+		if err := provisioning.WarnDeprecatedPermissions(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if !CreateAllOpts.SkipCostEstimate {
+		counts, err := createAllResourceCounts(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview)
+		if err != nil {
+			log.Fatal(err)
+		}
+		provisioning.PrintCostEstimate("gcp", CreateAllOpts.Region, counts)
+	}
+
 	if CreateAllOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -91,12 +160,19 @@ func validationForCreateAllCmd(cmd *cobra.Command, args []string) {
 		log.Fatalf("failed to create manifests directory at %s", manifestsDir)
 	}
 
+	provisioning.EnsureSecretsDir(manifestsDir, CreateAllOpts.AllowInsecureSecretsDir)
+
 	// create tls dir if necessary
 	tlsDir := filepath.Join(fPath, provisioning.TLSDirName)
 	err = provisioning.EnsureDir(tlsDir)
 	if err != nil {
 		log.Fatalf("failed to create tls directory at %s", tlsDir)
 	}
+
+	CreateAllOpts.OutputArchive, err = provisioning.ValidateOutputArchivePath(CreateAllOpts.OutputArchive, fPath)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 // NewCreateAllCmd provides the "create-all" subcommand
@@ -107,16 +183,29 @@ func NewCreateAllCmd() *cobra.Command {
 		Run:              createAllCmd,
 		PersistentPreRun: validationForCreateAllCmd,
 	}
+	provisioning.DumpsResolvedConfig(createAllCmd)
 
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Name, "name", "", "User-defined name for all created Google cloud resources (can be separate from the cluster's infra-id)")
 	createAllCmd.MarkPersistentFlagRequired("name")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Region, "region", "us", "Google cloud region where the Google Storage Bucket holding the OpenID Connect configuration will be created")
-	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Project, "project", "", "ID of the Google cloud project")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.Project, "project", "", "ID or number of the Google cloud project")
 	createAllCmd.MarkPersistentFlagRequired("project")
-	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to create gcp service accounts for (can be created by running 'oc adm release extract --credentials-requests --cloud=gcp' against an OpenShift release image)")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create gcp service accounts for (can be created by running 'oc adm release extract --credentials-requests --cloud=gcp' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createAllCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
 	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.VerifyIssuerReachable, "verify-issuer-reachable", false, "Fetch the OIDC discovery document and JSON web key set from the issuer URL over the public network once they have been uploaded, honoring the HTTPS_PROXY and NO_PROXY environment variables, and fail if they are not publicly served")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.DiscoveryDocument.Audiences, "oidc-extra-audience", nil, "Audience to append to the audiences_supported of the OIDC discovery document, eg. for an identity consumer of the issuer other than the cluster. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.DiscoveryDocument.Claims, "oidc-extra-claim", nil, "Claim to append to the claims_supported of the OIDC discovery document. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.OutputArchive, "output-archive", "", "Package the generated manifests, the tls directory and the inventory into a reproducible gzipped tar archive at this path, eg. to transport them into a disconnected environment. The manifests directory and the inventory are removed from the output directory once the archive has been written")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipQuotaCheck, "skip-quota-check", false, "Skip checking that the IAM service accounts quota of the project allows creating the IAM service accounts before creating any resources")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipCostEstimate, provisioning.SkipCostEstimateFlag, false, provisioning.SkipCostEstimateFlagUsage)
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.SkipDeprecatedPermissionsCheck, "skip-deprecated-permissions-check", false, "Skip warning about the roles and permissions requested by the CredentialsRequests which are known to be deprecated by Google Cloud")
+	createAllCmd.PersistentFlags().IntVar(&CreateAllOpts.ServiceAccountsQuota, "service-accounts-quota", defaultServiceAccountsQuota, "Quota of IAM service accounts per project, if it was increased from the default")
+	provisioning.AddAllowInsecureSecretsDirFlag(createAllCmd.PersistentFlags(), &CreateAllOpts.AllowInsecureSecretsDir)
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.CredentialsFile, "credentials-file", "", "File containing the service account key used to authenticate to Google cloud. Use \"-\" to read the key from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, credentials are loaded from the default locations")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.ImpersonateServiceAccount, "impersonate-service-account", "", "Email of a service account to impersonate, every operation is performed as the service account. The loaded credentials must be granted the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.QuotaProject, "quota-project", "", "ID or number of the project to which the quota and billing of GCP API calls are attributed, sent as the x-goog-user-project header, eg. when the credentials belong to another project than --project. The credentials must be granted the serviceusage.services.use permission on the project. Defaults to the project inferred from the credentials when not specified")
 
 	return createAllCmd
 }
@@ -13,6 +13,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	iamadminpb "google.golang.org/genproto/googleapis/iam/admin/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
@@ -28,10 +30,15 @@ data:
   service_account.json: %s
 kind: Secret
 metadata:
-  name: %s
+  annotations:
+%s  name: %s
   namespace: %s
 type: Opaque`
 
+	// serviceAccountNameAnnotation is the annotation applied to the secret manifests generated with --dry-run which
+	// records the display name of the IAM service account that would have been created
+	serviceAccountNameAnnotation = "ccoctl.openshift.io/service-account-name"
+
 	// credentialsConfigTemplate is a template of the client credentials configuration required to impersonate IAM service
 	// account
 	credentialsConfigTemplate = `{
@@ -69,35 +76,190 @@ type: Opaque`
 	// generateCredentialsConfigScriptName is the name of the script to generate credentials config required to
 	// impersonate service account
 	generateCredentialsConfigScriptName = "09-%d-generate-credentials-config-for-%s-sa.sh"
+	// defaultServiceAccountsQuota is the default quota of IAM service accounts per project
+	defaultServiceAccountsQuota = 100
+	// maxCustomRolePermissions is the limit of permissions included within a custom role
+	maxCustomRolePermissions = 3000
+	// maxPolicyPrincipals is the limit of principals, counted once for each role they are granted, within an allow
+	// policy such as the IAM policy of a project or of an IAM service account
+	maxPolicyPrincipals = 1500
 )
 
 var (
 	// CreateServiceAccountsOpts captures the options that affect creation/updating
 	// of the service accounts.
 	CreateServiceAccountsOpts = options{
-		TargetDir: "",
+		TargetDir:            "",
+		ServiceAccountsQuota: defaultServiceAccountsQuota,
+	}
+
+	// descriptionConstraints are the constraints of google cloud on the descriptions of IAM service accounts and
+	// custom roles
+	descriptionConstraints = provisioning.DescriptionConstraints{
+		MaxLength: 256,
 	}
 )
 
-func createServiceAccounts(ctx context.Context, client gcp.Client, name, workloadIdentityPool, workloadIdentityProvider, credReqDir, targetDir string, enableTechPreview, generateOnly bool) error {
+// serviceAccountDescription returns the description of the IAM service account named serviceAccountName and of the
+// custom role created for credReq, rendered from --resource-description-template when provided
+func serviceAccountDescription(name, serviceAccountName string, credReq *credreqv1.CredentialsRequest) (string, error) {
+	return provisioning.ComponentDescription(name, credReq, descriptionConstraints,
+		fmt.Sprintf("%s for service account %s", createdByCcoctl, serviceAccountName))
+}
+
+// checkServiceAccountsQuota ensures that the quota of IAM service accounts per project allows creating the IAM service
+// accounts which do not exist yet for the CredentialsRequests within credReqDirs. Google cloud does not expose the
+// quota through an API so it is provided as quota. The check is best-effort and is skipped when the existing IAM
+// service accounts cannot be listed.
+func checkServiceAccountsQuota(ctx context.Context, client gcp.Client, name string, credReqDirs []string, enableTechPreview bool, quota int) error {
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+
+	svcAcctList, err := client.ListServiceAccounts(ctx, &iamadminpb.ListServiceAccountsRequest{
+		Name: fmt.Sprintf("projects/%s", client.GetProjectName()),
+	})
+	if err != nil {
+		provisioning.Warnf("Skipping IAM service accounts quota check, failed to list IAM service accounts: %s", err)
+		return nil
+	}
+	existingNames := sets.NewString()
+	for _, svcAcct := range svcAcctList {
+		existingNames.Insert(svcAcct.DisplayName)
+	}
+
+	required := 0
+	for _, cr := range credRequests {
+		serviceAccountName, err := utils.GenerateNameWithFieldLimits(name, 50, cr.Name, 49)
+		if err != nil {
+			return errors.Wrap(err, "Error generating service account name")
+		}
+		if !existingNames.Has(serviceAccountName) {
+			required++
+		}
+	}
+
+	log.Printf("%d of %d IAM service accounts allowed in the project are in use, %d IAM service accounts will be created", len(svcAcctList), quota, required)
+	return provisioning.CheckQuota(provisioning.Quota{
+		Name:  "IAM service accounts per project",
+		Usage: len(svcAcctList),
+		Limit: quota,
+	}, required)
+}
+
+// checkServiceAccountLimits validates the custom roles and IAM policy bindings generated for the CredentialsRequests
+// within credReqDirs with validateServiceAccountLimits before any cloud call
+func checkServiceAccountLimits(credReqDirs []string, name string, enableTechPreview bool) error {
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	return validateServiceAccountLimits(credRequests, name)
+}
+
+// validateServiceAccountLimits ensures that the custom roles and IAM policy bindings generated for credReqs are
+// within the limits of Google cloud, returning a LimitViolations naming every CredentialsRequest exceeding a limit.
+// The IAM policy of each IAM service account binds a principal for each service account of its CredentialsRequest
+// while the IAM policy of the project binds the IAM service accounts to each of their roles. Principals bound within
+// the IAM policy of the project by others are not accounted for.
+func validateServiceAccountLimits(credReqs []*credreqv1.CredentialsRequest, name string) error {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return errors.Wrap(err, "Failed to create credReq codec")
+	}
+	violations := provisioning.LimitViolations{}
+	projectPrincipals := 0
+	for _, cr := range credReqs {
+		if cr.Spec.ProviderSpec == nil {
+			continue
+		}
+		gcpProviderSpec := credreqv1.GCPProviderSpec{}
+		if err := codec.DecodeProviderSpec(cr.Spec.ProviderSpec, &gcpProviderSpec); err != nil || gcpProviderSpec.Kind != "GCPProviderSpec" {
+			// CredentialsRequests which can't be processed fail when their IAM service account is created
+			continue
+		}
+		component := provisioning.CredentialsRequestComponent(cr)
+		// The custom role and the IAM service account are named alike
+		serviceAccountName, err := utils.GenerateNameWithFieldLimits(name, 50, cr.Name, 49)
+		if err != nil {
+			return errors.Wrap(err, "Error generating service account name")
+		}
+
+		violations.Add(provisioning.LimitViolation{
+			Component: component,
+			Artifact:  "custom role " + serviceAccountName,
+			Size:      len(gcpProviderSpec.Permissions),
+			Limit:     maxCustomRolePermissions,
+			Unit:      "permissions",
+			Limited:   "of a custom role",
+		})
+		violations.Add(provisioning.LimitViolation{
+			Component: component,
+			Artifact:  "the IAM policy of IAM service account " + serviceAccountName,
+			Size:      len(cr.Spec.ServiceAccountNames),
+			Limit:     maxPolicyPrincipals,
+			Unit:      "principals",
+			Limited:   "of an allow policy",
+			Hint:      "bind fewer service accounts",
+		})
+
+		projectPrincipals += len(gcpProviderSpec.PredefinedRoles)
+		if len(gcpProviderSpec.Permissions) > 0 {
+			projectPrincipals++
+		}
+	}
+	violations.Add(provisioning.LimitViolation{
+		Component: fmt.Sprintf("%d CredentialsRequests", len(credReqs)),
+		Artifact:  "granting the roles of their IAM service accounts within the IAM policy of the project",
+		Size:      projectPrincipals,
+		Limit:     maxPolicyPrincipals,
+		Unit:      "principals",
+		Limited:   "of an allow policy",
+	})
+	return violations.Err()
+}
+
+// createServiceAccounts creates the IAM service accounts of the CredentialsRequests within credReqDirs, granting the
+// workload identity pool principals of the project identified by projectNum the use of them
+func createServiceAccounts(ctx context.Context, client gcp.Client, name, workloadIdentityPool, workloadIdentityProvider string, credReqDirs []string, targetDir string, projectNum int64, enableTechPreview, generateOnly bool) error {
 	// Process directory
-	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDir, enableTechPreview)
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
 
 	// Create service accounts
-	if err := processCredentialsRequests(ctx, client, credRequests, name, workloadIdentityPool, workloadIdentityProvider, targetDir, generateOnly); err != nil {
+	if err := processCredentialsRequests(ctx, client, credRequests, name, workloadIdentityPool, workloadIdentityProvider, targetDir, projectNum, generateOnly); err != nil {
 		return errors.Wrap(err, "Failed while processing each CredentialsRequest")
 	}
 
+	if generateOnly {
+		log.Printf("Dry run complete, no GCP resources were created or modified. Manifests which would have been applied were saved to %s", filepath.Join(targetDir, provisioning.ManifestsDirName))
+	}
+
 	return nil
 }
 
-func processCredentialsRequests(ctx context.Context, client gcp.Client, credReqs []*credreqv1.CredentialsRequest, name, workloadIdentityPool, workloadIdentityProvider, targetDir string, generateOnly bool) error {
+func processCredentialsRequests(ctx context.Context, client gcp.Client, credReqs []*credreqv1.CredentialsRequest, name, workloadIdentityPool, workloadIdentityProvider, targetDir string, projectNum int64, generateOnly bool) error {
 	project := client.GetProjectName()
+	if len(credReqs) == 0 {
+		return nil
+	}
+
+	// The descriptions are validated before any IAM service account is created
+	for _, cr := range credReqs {
+		serviceAccountName, err := utils.GenerateNameWithFieldLimits(name, 50, cr.Name, 49)
+		if err != nil {
+			return errors.Wrap(err, "Error generating service account name")
+		}
+		if _, err := serviceAccountDescription(name, serviceAccountName, cr); err != nil {
+			return err
+		}
+	}
+
 	for i, cr := range credReqs {
-		_, err := createServiceAccount(ctx, client, name, cr, i, workloadIdentityPool, workloadIdentityProvider, project, targetDir, generateOnly)
+		_, err := createServiceAccount(ctx, client, name, cr, i, workloadIdentityPool, workloadIdentityProvider, project, projectNum, targetDir, generateOnly)
 		if err != nil {
 			return err
 		}
@@ -106,7 +268,7 @@ func processCredentialsRequests(ctx context.Context, client gcp.Client, credReqs
 	return nil
 }
 
-func createServiceAccount(ctx context.Context, client gcp.Client, name string, credReq *credreqv1.CredentialsRequest, serviceAccountNum int, workloadIdentityPool, workloadIdentityProvider, project, targetDir string, generateOnly bool) (string, error) {
+func createServiceAccount(ctx context.Context, client gcp.Client, name string, credReq *credreqv1.CredentialsRequest, serviceAccountNum int, workloadIdentityPool, workloadIdentityProvider, project string, projectNum int64, targetDir string, generateOnly bool) (string, error) {
 	// The credReq must have a non zero-length list of ServiceAccountNames
 	// that can be used to restrict which k8s ServiceAccounts can use the GCP ServiceAccount.
 	if len(credReq.Spec.ServiceAccountNames) == 0 {
@@ -140,6 +302,13 @@ func createServiceAccount(ctx context.Context, client gcp.Client, name string, c
 		return "", fmt.Errorf("error generating custom role name: %v", err)
 	}
 
+	ctx, span := provisioning.StartSpan(ctx, "gcp.EnsureServiceAccount",
+		provisioning.ProviderAttribute.String("gcp"),
+		provisioning.ResourceTypeAttribute.String("serviceAccount"),
+		provisioning.ResourceNameAttribute.String(serviceAccountName),
+	)
+	defer span.End()
+
 	// Decode GCPProviderSpec
 	codec, err := credreqv1.NewCodec()
 	if err != nil {
@@ -155,11 +324,6 @@ func createServiceAccount(ctx context.Context, client gcp.Client, name string, c
 		return "", fmt.Errorf("CredentialsRequest %s/%s is not of type GCP", credReq.Namespace, credReq.Name)
 	}
 
-	projectNum, err := getProjectNumber(ctx, client, project)
-	if err != nil {
-		return "", errors.Wrap(err, "Failed to get project number")
-	}
-
 	identityProviderBindingNames := getIdentityProviderBindingNames(projectNum, workloadIdentityPool, credReq.Spec.SecretRef.Namespace, credReq.Spec.ServiceAccountNames)
 
 	var encodedCredentialsConfig string
@@ -226,15 +390,17 @@ func createServiceAccount(ctx context.Context, client gcp.Client, name string, c
 		// secrets are not populated with credentials in generate mode, you need to create client credentials config
 		// using 'gcloud iam workload-identity-pools create-cred-config' command, base64 encode resulting json and
 		// populate service_account.json field in the secret manifests
-		encodedCredentialsConfig = ""
-		if err := writeCredReqSecret(credReq, targetDir, encodedCredentialsConfig, generateCredentialsConfigScriptFullPath); err != nil {
+		if err := writeDryRunCredReqSecret(credReq, targetDir, serviceAccountName, generateCredentialsConfigScriptFullPath); err != nil {
 			return "", errors.Wrap(err, "Failed to save Secret for install manifests")
 		}
 
 		return "", nil
 
 	default:
-		createdByCcoctlForSvcAcct := fmt.Sprintf("%s for service account %s", createdByCcoctl, serviceAccountName)
+		createdByCcoctlForSvcAcct, err := serviceAccountDescription(name, serviceAccountName, credReq)
+		if err != nil {
+			return "", err
+		}
 
 		var serviceAccount *iamadminpb.ServiceAccount
 		serviceAccount, err = getServiceAccountByName(ctx, client, serviceAccountName)
@@ -245,6 +411,7 @@ func createServiceAccount(ctx context.Context, client gcp.Client, name string, c
 					return "", errors.Wrap(err, "Failed to create IAM service account")
 				}
 				log.Printf("IAM service account %s created", serviceAccount.DisplayName)
+				provisioning.EmitResourceEvent("IAMServiceAccount", serviceAccount.Email, provisioning.ResourceCreated)
 			} else {
 				return "", err
 			}
@@ -264,6 +431,7 @@ func createServiceAccount(ctx context.Context, client gcp.Client, name string, c
 					}
 					roles = append(roles, role.Name)
 					log.Printf("IAM custom role %s created", role.Title)
+					provisioning.EmitResourceEvent("IAMCustomRole", role.Name, provisioning.ResourceCreated)
 				} else {
 					return "", err
 				}
@@ -296,30 +464,50 @@ func createServiceAccount(ctx context.Context, client gcp.Client, name string, c
 		}
 
 		log.Printf("Updated policy bindings for IAM service account %s", serviceAccount.DisplayName)
+		provisioning.EmitResourceEvent("IAMServiceAccount", serviceAccount.Email, provisioning.ResourceUpdated)
 
 		projectNumStr := fmt.Sprint(projectNum)
 		credentialsConfig := fmt.Sprintf(credentialsConfigTemplate, projectNumStr, workloadIdentityPool, workloadIdentityProvider, serviceAccount.Email, provisioning.OidcTokenPath)
 		encodedCredentialsConfig = base64.StdEncoding.EncodeToString([]byte(credentialsConfig))
 	}
 
-	if err := writeCredReqSecret(credReq, targetDir, encodedCredentialsConfig, ""); err != nil {
+	if err := writeCredReqSecret(credReq, targetDir, encodedCredentialsConfig); err != nil {
 		return "", errors.Wrap(err, "Failed to save secret for install manifests")
 	}
 	return "", nil
 }
 
 // createShellScript creates a shell script given commands to execute
-func createShellScript(commands []string) string {
-	return fmt.Sprintf("#!/bin/sh\n%s", strings.Join(commands, "\n"))
+// ValidateProviderSpec validates the roles and permissions of a GCPProviderSpec without contacting Google cloud.
+// Custom roles defined within a project must be defined within the project identified by project, within which roles
+// are granted, unless project is a project number which can only be resolved by Google cloud. Errors are identified
+// by their path below fldPath.
+func ValidateProviderSpec(spec *credreqv1.GCPProviderSpec, project string, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if len(spec.PredefinedRoles) == 0 && len(spec.Permissions) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("predefinedRoles"), "the roles or permissions to grant"))
+	}
+	for i, role := range spec.PredefinedRoles {
+		rolePath := fldPath.Child("predefinedRoles").Index(i)
+		matches := roleRegexp.FindStringSubmatch(role)
+		if matches == nil {
+			errs = append(errs, field.Invalid(rolePath, role, "roles must be of the form roles/<role>, projects/<project>/roles/<role> or organizations/<organization>/roles/<role>"))
+			continue
+		}
+		if roleProject := matches[2]; roleProject != "" && project != "" && !projectNumberRegexp.MatchString(project) && roleProject != project {
+			errs = append(errs, field.Invalid(rolePath, role, fmt.Sprintf("role is defined within project %s which is not the project %s within which roles are granted", roleProject, project)))
+		}
+	}
+	for i, permission := range spec.Permissions {
+		if !permissionRegexp.MatchString(permission) {
+			errs = append(errs, field.Invalid(fldPath.Child("permissions").Index(i), permission, "permissions must be of the form <service>.<resource>.<verb>"))
+		}
+	}
+	return errs
 }
 
-// getProjectNumber fetches project number given project name
-func getProjectNumber(ctx context.Context, client gcp.Client, projectName string) (int64, error) {
-	project, err := client.GetProject(ctx, projectName)
-	if err != nil {
-		return 0, err
-	}
-	return project.ProjectNumber, nil
+func createShellScript(commands []string) string {
+	return fmt.Sprintf("#!/bin/sh\n%s", strings.Join(commands, "\n"))
 }
 
 // getIdentityProviderBindingNames generates member names for binding IAM service account to workload identity provider
@@ -395,23 +583,21 @@ func getRoleByName(ctx context.Context, client gcp.Client, roleName string) (*ia
 }
 
 // writeCredReqSecret will take a credentialsRequest and a base 64 encoded credentials configuration to create
-// a Secret manifest.
-func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, encodedCredentialsConfig, generateCredentialsConfigScriptPath string) error {
+// a Secret manifest. The issuer URL recorded within the provenance annotations of the Secret is the one of the cluster
+// authentication manifest written to the targetDir by create-workload-identity-provider, when present.
+func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, encodedCredentialsConfig string) error {
 	manifestsDir := filepath.Join(targetDir, provisioning.ManifestsDirName)
 
 	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
 	filePath := filepath.Join(manifestsDir, fileName)
 
-	fileData := fmt.Sprintf(secretManifestsTemplate, encodedCredentialsConfig, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
-
-	// encodedCredentialsConfig would be an empty string if ccoctl is run in --dry-run mode
-	// so lets make sure we have an invalid Secret until the user
-	// has populated the Secret manually.
-	if encodedCredentialsConfig == "" {
-		fileData = fileData + fmt.Sprintf("\nPOPULATE service_account.json FIELD WITH BASE 64 ENCODED CREDENTIALS CONFIG JSON GENERATED FROM SCRIPT %s", generateCredentialsConfigScriptPath)
+	issuerURL, err := provisioning.ClusterAuthenticationIssuerURL(targetDir)
+	if err != nil {
+		return err
 	}
+	fileData := fmt.Sprintf(secretManifestsTemplate, encodedCredentialsConfig, provisioning.ProvenanceAnnotations(cr, issuerURL), cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
 
-	if err := ioutil.WriteFile(filePath, []byte(fileData), 0600); err != nil {
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
 		return errors.Wrap(err, "Failed to save Secret file")
 	}
 
@@ -420,21 +606,56 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, encodedCred
 	return nil
 }
 
+// writeDryRunCredReqSecret writes a secret file within the manifests directory (targetDir/manifests/) for the IAM
+// service account that would have been created with serviceAccountName. The secret is annotated as a dry-run artifact
+// and must have its service_account.json populated with the credentials configuration generated by the script at
+// generateCredentialsConfigScriptPath before it can be applied.
+func writeDryRunCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir, serviceAccountName, generateCredentialsConfigScriptPath string) error {
+	manifestsDir := filepath.Join(targetDir, provisioning.ManifestsDirName)
+
+	fileName := fmt.Sprintf("%s-%s-credentials.yaml", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+	filePath := filepath.Join(manifestsDir, fileName)
+
+	issuerURL, err := provisioning.ClusterAuthenticationIssuerURL(targetDir)
+	if err != nil {
+		return err
+	}
+	annotations := provisioning.ProvenanceAnnotations(cr, issuerURL) + provisioning.DryRunAnnotations(map[string]string{serviceAccountNameAnnotation: serviceAccountName})
+	fileData := fmt.Sprintf(secretManifestsTemplate, "", annotations, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace)
+	fileData = fileData + fmt.Sprintf("\nPOPULATE service_account.json FIELD WITH BASE 64 ENCODED CREDENTIALS CONFIG JSON GENERATED FROM SCRIPT %s", generateCredentialsConfigScriptPath)
+
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
+		return errors.Wrap(err, "Failed to save Secret file")
+	}
+
+	log.Printf("Saved dry-run credentials configuration for IAM service account %s to: %s", serviceAccountName, filePath)
+
+	return nil
+}
+
 func createServiceAccountsCmd(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 
-	creds, err := loadCredentials(ctx)
+	creds, err := loadCredentials(ctx, CreateServiceAccountsOpts.CredentialsFile, CreateServiceAccountsOpts.ImpersonateServiceAccount)
 	if err != nil {
 		log.Fatalf("Failed to load credentials: %s", err)
 	}
 
-	gcpClient, err := gcp.NewClient(CreateWorkloadIdentityProviderOpts.Project, creds)
+	gcpClient, project, err := newClientForProject(ctx, CreateWorkloadIdentityProviderOpts.Project, creds, CreateServiceAccountsOpts.QuotaProject)
 	if err != nil {
 		log.Fatal(err)
 	}
+	CreateWorkloadIdentityProviderOpts.Project = project.ID
+
+	if !CreateServiceAccountsOpts.DryRun && !CreateServiceAccountsOpts.SkipQuotaCheck {
+		if err := checkServiceAccountsQuota(ctx, gcpClient, CreateServiceAccountsOpts.Name, CreateServiceAccountsOpts.CredRequestDirs,
+			CreateServiceAccountsOpts.EnableTechPreview, CreateServiceAccountsOpts.ServiceAccountsQuota); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	err = createServiceAccounts(ctx, gcpClient, CreateServiceAccountsOpts.Name, CreateServiceAccountsOpts.WorkloadIdentityPool,
-		CreateServiceAccountsOpts.WorkloadIdentityProvider, CreateServiceAccountsOpts.CredRequestDir, CreateServiceAccountsOpts.TargetDir,
+		CreateServiceAccountsOpts.WorkloadIdentityProvider, CreateServiceAccountsOpts.CredRequestDirs, CreateServiceAccountsOpts.TargetDir, project.Number,
 		CreateServiceAccountsOpts.EnableTechPreview, CreateServiceAccountsOpts.DryRun)
 	if err != nil {
 		log.Fatal(err)
@@ -444,6 +665,19 @@ func createServiceAccountsCmd(cmd *cobra.Command, args []string) {
 // initEnvForCreateServiceAccountsCmd will ensure the destination directory is ready to receive the generated
 // files, and will create the directory if necessary.
 func initEnvForCreateServiceAccountsCmd(cmd *cobra.Command, args []string) {
+	// Report every custom role and IAM policy exceeding the limits of Google cloud before any cloud call
+	if err := checkServiceAccountLimits(CreateServiceAccountsOpts.CredRequestDirs, CreateServiceAccountsOpts.Name, CreateServiceAccountsOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+	if !CreateServiceAccountsOpts.SkipDeprecatedPermissionsCheck {
+		if err := provisioning.WarnDeprecatedPermissions(CreateServiceAccountsOpts.CredRequestDirs, CreateServiceAccountsOpts.EnableTechPreview); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if CreateServiceAccountsOpts.TargetDir == "" && CreateServiceAccountsOpts.DryRun {
+		CreateServiceAccountsOpts.TargetDir = provisioning.DryRunOutputDir("gcp")
+	}
 	if CreateServiceAccountsOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -470,6 +704,8 @@ func initEnvForCreateServiceAccountsCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("Failed to create manifests directory at %s", manifestsDir)
 	}
+
+	provisioning.EnsureSecretsDir(manifestsDir, CreateServiceAccountsOpts.AllowInsecureSecretsDir)
 }
 
 // NewCreateServiceAccountsCmd provides the "create-service-accounts" subcommand
@@ -483,17 +719,24 @@ func NewCreateServiceAccountsCmd() *cobra.Command {
 
 	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.Name, "name", "", "User-defined name for all created google cloud resources (can be separate from the cluster's infra-id)")
 	createServiceAccountsCmd.MarkPersistentFlagRequired("name")
-	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to create gcp service accounts for (can be created by running 'oc adm release extract --credentials-requests --cloud=gcp' against an OpenShift release image)")
+	createServiceAccountsCmd.PersistentFlags().StringSliceVar(&CreateServiceAccountsOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create gcp service accounts for (can be created by running 'oc adm release extract --credentials-requests --cloud=gcp' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createServiceAccountsCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.WorkloadIdentityPool, "workload-identity-pool", "", "ID of workload identity pool (can be created with the 'create-workload-identity-pool' sub-command)")
 	createServiceAccountsCmd.MarkPersistentFlagRequired("workload-identity-pool")
 	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.WorkloadIdentityProvider, "workload-identity-provider", "", "ID of workload identity provider (can be created with the 'create-workload-identity-pool' sub-command)")
 	createServiceAccountsCmd.MarkPersistentFlagRequired("workload-identity-provider")
-	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.Project, "project", "", "ID of the google cloud project")
+	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.Project, "project", "", "ID or number of the google cloud project")
 	createServiceAccountsCmd.MarkPersistentFlagRequired("project")
 	createServiceAccountsCmd.PersistentFlags().BoolVar(&CreateServiceAccountsOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
-	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
+	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory, or a temporary directory when --dry-run is specified)")
 	createServiceAccountsCmd.PersistentFlags().BoolVar(&CreateServiceAccountsOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	createServiceAccountsCmd.PersistentFlags().BoolVar(&CreateServiceAccountsOpts.SkipQuotaCheck, "skip-quota-check", false, "Skip checking that the IAM service accounts quota of the project allows creating the IAM service accounts before creating them")
+	createServiceAccountsCmd.PersistentFlags().BoolVar(&CreateServiceAccountsOpts.SkipDeprecatedPermissionsCheck, "skip-deprecated-permissions-check", false, "Skip warning about the roles and permissions requested by the CredentialsRequests which are known to be deprecated by Google Cloud")
+	createServiceAccountsCmd.PersistentFlags().IntVar(&CreateServiceAccountsOpts.ServiceAccountsQuota, "service-accounts-quota", defaultServiceAccountsQuota, "Quota of IAM service accounts per project, if it was increased from the default")
+	provisioning.AddAllowInsecureSecretsDirFlag(createServiceAccountsCmd.PersistentFlags(), &CreateServiceAccountsOpts.AllowInsecureSecretsDir)
+	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.CredentialsFile, "credentials-file", "", "File containing the service account key used to authenticate to Google cloud. Use \"-\" to read the key from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, credentials are loaded from the default locations")
+	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.ImpersonateServiceAccount, "impersonate-service-account", "", "Email of a service account to impersonate, every operation is performed as the service account. The loaded credentials must be granted the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account")
+	createServiceAccountsCmd.PersistentFlags().StringVar(&CreateServiceAccountsOpts.QuotaProject, "quota-project", "", "ID or number of the project to which the quota and billing of GCP API calls are attributed, sent as the x-goog-user-project header, eg. when the credentials belong to another project than --project. The credentials must be granted the serviceusage.services.use permission on the project. Defaults to the project inferred from the credentials when not specified")
 
 	return createServiceAccountsCmd
 }
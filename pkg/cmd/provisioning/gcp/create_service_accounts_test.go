@@ -14,7 +14,10 @@ import (
 	"google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/api/iam/v1"
 	iamadminpb "google.golang.org/genproto/googleapis/iam/admin/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 	mockgcp "github.com/openshift/cloud-credential-operator/pkg/gcp/mock"
 )
@@ -76,7 +79,6 @@ func TestCreateServiceAccounts(t *testing.T) {
 			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
 				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
 				mockGetProjectName(mockGCPClient, 1)
-				mockGetProject(mockGCPClient)
 				return mockGCPClient
 			},
 			setup: func(t *testing.T) string {
@@ -96,6 +98,11 @@ func TestCreateServiceAccounts(t *testing.T) {
 				files, err = ioutil.ReadDir(manifestsDir)
 				require.NoError(t, err, "Unexpected error listing files in manifestsDir")
 				assert.Equal(t, 1, countNonDirectoryFiles(files), "Should be exactly 1 secret in manifestsDir for one CredReq")
+
+				secretData, err := os.ReadFile(filepath.Join(manifestsDir, fmt.Sprintf("%s-%s-credentials.yaml", testTargetNamespaceName, testTargetSecretName)))
+				require.NoError(t, err, "Unexpected error reading dry-run secret")
+				assert.Contains(t, string(secretData), fmt.Sprintf("%s: \"true\"", provisioning.DryRunAnnotation), "Dry-run secret should be annotated as a dry-run artifact")
+				assert.Contains(t, string(secretData), fmt.Sprintf("%s: \"%s-%s\"", serviceAccountNameAnnotation, testName, testCredReqName), "Dry-run secret should record the service account name")
 			},
 		},
 		{
@@ -107,7 +114,6 @@ func TestCreateServiceAccounts(t *testing.T) {
 				mockListRolesEmpty(mockGCPClient)
 				mockCreateServiceAccountSuccessful(mockGCPClient)
 				mockGetProjectName(mockGCPClient, 6)
-				mockGetProject(mockGCPClient)
 				mockGetProjectIamPolicy(mockGCPClient)
 				mockSetProjectIamPolicy(mockGCPClient)
 				mockGetServiceAccountIamPolicy(mockGCPClient)
@@ -141,7 +147,6 @@ func TestCreateServiceAccounts(t *testing.T) {
 			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
 				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
 				mockGetProjectName(mockGCPClient, 2)
-				mockGetProject(mockGCPClient)
 				mockListServiceAccountsEmpty(mockGCPClient)
 				mockCreateServiceAccountFailed(mockGCPClient)
 				return mockGCPClient
@@ -165,7 +170,6 @@ func TestCreateServiceAccounts(t *testing.T) {
 				mockListServiceAccountsNotEmpty(mockGCPClient)
 				mockListRolesNotEmpty(mockGCPClient)
 				mockGetProjectName(mockGCPClient, 6)
-				mockGetProject(mockGCPClient)
 				mockGetProjectIamPolicy(mockGCPClient)
 				mockSetProjectIamPolicy(mockGCPClient)
 				mockGetServiceAccountIamPolicy(mockGCPClient)
@@ -205,7 +209,7 @@ func TestCreateServiceAccounts(t *testing.T) {
 			require.NoError(t, err, "Unexpected error creating manifests dir for test")
 			defer os.RemoveAll(manifestsDir)
 
-			err = createServiceAccounts(context.TODO(), mockGCPClient, testName, testName, testName, credReqDir, targetDir, false, test.generateOnly)
+			err = createServiceAccounts(context.TODO(), mockGCPClient, testName, testName, testName, []string{credReqDir}, targetDir, testProjectNumber, false, test.generateOnly)
 
 			if test.expectError {
 				require.Error(t, err, "expected error returned")
@@ -216,6 +220,165 @@ func TestCreateServiceAccounts(t *testing.T) {
 	}
 }
 
+func TestCheckServiceAccountsQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockGCPClient func(mockCtrl *gomock.Controller) *mockgcp.MockClient
+		quota         int
+		expectError   bool
+	}{
+		{
+			name: "Quota allows creating service accounts",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockListServiceAccountsEmpty(mockGCPClient)
+				return mockGCPClient
+			},
+			quota: 1,
+		},
+		{
+			name: "Quota exceeded",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListServiceAccounts(gomock.Any(), gomock.Any()).Return(
+					[]*iamadminpb.ServiceAccount{
+						{
+							DisplayName: "unrelated-service-account",
+						},
+					}, nil).Times(1)
+				return mockGCPClient
+			},
+			quota:       1,
+			expectError: true,
+		},
+		{
+			name: "Existing service account does not count against quota",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockListServiceAccountsNotEmpty(mockGCPClient)
+				return mockGCPClient
+			},
+			quota: 1,
+		},
+		{
+			name: "Check skipped when service accounts cannot be listed",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListServiceAccounts(gomock.Any(), gomock.Any()).Return(
+					nil, fmt.Errorf("permission denied")).Times(1)
+				return mockGCPClient
+			},
+			quota: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockGCPClient := test.mockGCPClient(mockCtrl)
+
+			credReqDir, err := ioutil.TempDir(os.TempDir(), testDirPrefix)
+			require.NoError(t, err, "Failed to create temp directory")
+			defer os.RemoveAll(credReqDir)
+			err = testCredentialsRequest(t, testCredReqName, testTargetNamespaceName, testTargetSecretName, credReqDir)
+			require.NoError(t, err, "errored while setting up test CredReq files")
+
+			err = checkServiceAccountsQuota(context.TODO(), mockGCPClient, testName, []string{credReqDir}, false, test.quota)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestValidateServiceAccountLimits(t *testing.T) {
+	credentialsRequest := func(t *testing.T, name string, predefinedRoles, permissions, serviceAccounts int) *credreqv1.CredentialsRequest {
+		codec, err := credreqv1.NewCodec()
+		require.NoError(t, err, "failed to create codec")
+		providerSpec := &credreqv1.GCPProviderSpec{TypeMeta: metav1.TypeMeta{Kind: "GCPProviderSpec"}}
+		for i := 0; i < predefinedRoles; i++ {
+			providerSpec.PredefinedRoles = append(providerSpec.PredefinedRoles, fmt.Sprintf("roles/role%d", i))
+		}
+		for i := 0; i < permissions; i++ {
+			providerSpec.Permissions = append(providerSpec.Permissions, fmt.Sprintf("service.resource.verb%d", i))
+		}
+		encoded, err := codec.EncodeProviderSpec(providerSpec)
+		require.NoError(t, err, "failed to encode provider spec")
+		cr := &credreqv1.CredentialsRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openshift-cloud-credential-operator"},
+			Spec: credreqv1.CredentialsRequestSpec{
+				SecretRef:    corev1.ObjectReference{Namespace: testTargetNamespaceName, Name: name},
+				ProviderSpec: encoded,
+			},
+		}
+		for i := 0; i < serviceAccounts; i++ {
+			cr.Spec.ServiceAccountNames = append(cr.Spec.ServiceAccountNames, fmt.Sprintf("service-account-%d", i))
+		}
+		return cr
+	}
+
+	tests := []struct {
+		name                string
+		credentialsRequests func(t *testing.T) []*credreqv1.CredentialsRequest
+		// expectedViolations are expected within the error, one for each violation
+		expectedViolations []string
+	}{
+		{
+			name: "Within the limits",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{credentialsRequest(t, testCredReqName, 2, maxCustomRolePermissions, 2)}
+			},
+		},
+		{
+			name: "Custom role with too many permissions",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{credentialsRequest(t, testCredReqName, 0, maxCustomRolePermissions+1, 2)}
+			},
+			expectedViolations: []string{"CredentialsRequest openshift-cloud-credential-operator/test-cred-req: custom role test-name-test-cred-req requires 3001 permissions, exceeding the limit of 3000"},
+		},
+		{
+			name: "IAM service account bound to too many service accounts",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{credentialsRequest(t, testCredReqName, 1, 0, maxPolicyPrincipals+1)}
+			},
+			expectedViolations: []string{"the IAM policy of IAM service account test-name-test-cred-req requires 1501 principals"},
+		},
+		{
+			name: "Project IAM policy with too many principals",
+			credentialsRequests: func(t *testing.T) []*credreqv1.CredentialsRequest {
+				return []*credreqv1.CredentialsRequest{
+					credentialsRequest(t, testCredReqName, maxPolicyPrincipals-1, 1, 1),
+					credentialsRequest(t, "other-cred-req", 1, 0, 1),
+				}
+			},
+			expectedViolations: []string{"2 CredentialsRequests: granting the roles of their IAM service accounts within the IAM policy of the project requires 1501 principals"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateServiceAccountLimits(test.credentialsRequests(t), testName)
+			if len(test.expectedViolations) == 0 {
+				require.NoError(t, err, "unexpected error")
+				return
+			}
+			var violations provisioning.LimitViolations
+			require.ErrorAs(t, err, &violations, "expected limit violations")
+			assert.Len(t, violations, len(test.expectedViolations), "unexpected number of violations")
+			for _, expected := range test.expectedViolations {
+				assert.Contains(t, err.Error(), expected, "unexpected violation")
+			}
+		})
+	}
+}
+
 func testCredentialsRequest(t *testing.T, crName, targetSecretNamespace, targetSecretName, targetDir string) error {
 	credReqTemplate := `---
 apiVersion: cloudcredential.openshift.io/v1
@@ -265,6 +428,145 @@ func countNonDirectoryFiles(files []os.FileInfo) int {
 	return NonDirectoryFiles
 }
 
+func TestResolveProject(t *testing.T) {
+	tests := []struct {
+		name            string
+		project         string
+		mockGCPClient   func(mockCtrl *gomock.Controller) *mockgcp.MockClient
+		expectError     bool
+		expectedProject *projectIdentifiers
+	}{
+		{
+			name:    "Resolve project ID",
+			project: testProject,
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProject(mockGCPClient)
+				return mockGCPClient
+			},
+			expectedProject: &projectIdentifiers{ID: testProject, Number: testProjectNumber},
+		},
+		{
+			name:    "Resolve project number",
+			project: fmt.Sprint(testProjectNumber),
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProject(mockGCPClient)
+				return mockGCPClient
+			},
+			expectedProject: &projectIdentifiers{ID: testProject, Number: testProjectNumber},
+		},
+		{
+			name:    "Invalid project",
+			project: "Not_A_Project",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				return mockgcp.NewMockClient(mockCtrl)
+			},
+			expectError: true,
+		},
+		{
+			name:    "Project not accessible with current credentials",
+			project: testProject,
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGCPClient.EXPECT().GetProject(gomock.Any(), testProject).Return(nil, fmt.Errorf("permission denied")).Times(1)
+				return mockGCPClient
+			},
+			expectError: true,
+		},
+		{
+			name:    "Project resolved to a different project",
+			project: "other-project",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProject(mockGCPClient)
+				return mockGCPClient
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockGCPClient := test.mockGCPClient(mockCtrl)
+
+			project, err := resolveProject(context.TODO(), mockGCPClient, test.project)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectedProject, project)
+			}
+		})
+	}
+}
+
+func TestValidateQuotaProject(t *testing.T) {
+	tests := []struct {
+		name          string
+		quotaProject  string
+		mockGCPClient func(mockCtrl *gomock.Controller) *mockgcp.MockClient
+		expectedError string
+	}{
+		{
+			name:         "Permission granted",
+			quotaProject: "billing-project",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGCPClient.EXPECT().TestIamPermissions("billing-project", &cloudresourcemanager.TestIamPermissionsRequest{Permissions: []string{"serviceusage.services.use"}}).Return(
+					&cloudresourcemanager.TestIamPermissionsResponse{Permissions: []string{"serviceusage.services.use"}}, nil).Times(1)
+				return mockGCPClient
+			},
+		},
+		{
+			name:         "Permission not granted",
+			quotaProject: fmt.Sprint(testProjectNumber),
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGCPClient.EXPECT().TestIamPermissions(fmt.Sprint(testProjectNumber), gomock.Any()).Return(
+					&cloudresourcemanager.TestIamPermissionsResponse{}, nil).Times(1)
+				return mockGCPClient
+			},
+			expectedError: "the credentials are not granted the serviceusage.services.use permission on quota project 123456789",
+		},
+		{
+			name:         "Quota project not accessible",
+			quotaProject: "billing-project",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGCPClient.EXPECT().TestIamPermissions("billing-project", gomock.Any()).Return(nil, fmt.Errorf("USER_PROJECT_DENIED")).Times(1)
+				return mockGCPClient
+			},
+			expectedError: "failed to verify that the credentials may use quota project billing-project: USER_PROJECT_DENIED",
+		},
+		{
+			name:         "Invalid quota project",
+			quotaProject: "Not_A_Project",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				return mockgcp.NewMockClient(mockCtrl)
+			},
+			expectedError: "invalid --quota-project",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := validateQuotaProject(test.mockGCPClient(mockCtrl), test.quotaProject)
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError, "unexpected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
 func mockListServiceAccountsEmpty(mockGCPClient *mockgcp.MockClient) {
 	mockGCPClient.EXPECT().ListServiceAccounts(gomock.Any(), gomock.Any()).Return(
 		[]*iamadminpb.ServiceAccount{}, nil).Times(1)
@@ -316,6 +618,7 @@ func mockGetProjectName(mockGCPClient *mockgcp.MockClient, times int) {
 func mockGetProject(mockGCPClient *mockgcp.MockClient) {
 	mockGCPClient.EXPECT().GetProject(gomock.Any(), gomock.Any()).Return(&cloudresourcemanager.Project{
 		Name:          testProject,
+		ProjectId:     testProject,
 		ProjectNumber: testProjectNumber,
 	}, nil).Times(1)
 }
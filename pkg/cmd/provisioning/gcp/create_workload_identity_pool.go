@@ -41,15 +41,16 @@ const (
 func createWorkloadIdentityPoolCmd(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 
-	creds, err := loadCredentials(ctx)
+	creds, err := loadCredentials(ctx, CreateWorkloadIdentityPoolOpts.CredentialsFile, CreateWorkloadIdentityPoolOpts.ImpersonateServiceAccount)
 	if err != nil {
 		log.Fatalf("Failed to load credentials: %s", err)
 	}
 
-	gcpClient, err := gcp.NewClient(CreateWorkloadIdentityPoolOpts.Project, creds)
+	gcpClient, project, err := newClientForProject(ctx, CreateWorkloadIdentityPoolOpts.Project, creds, CreateWorkloadIdentityPoolOpts.QuotaProject)
 	if err != nil {
 		log.Fatalf("Failed to setup GCP client: %s", err)
 	}
+	CreateWorkloadIdentityPoolOpts.Project = project.ID
 
 	err = createWorkloadIdentityPool(ctx, gcpClient, CreateWorkloadIdentityPoolOpts.Name, CreateWorkloadIdentityPoolOpts.Project, CreateWorkloadIdentityPoolOpts.TargetDir, CreateWorkloadIdentityPoolOpts.DryRun)
 	if err != nil {
@@ -64,6 +65,9 @@ func validationForCreateWorkloadIdentityPoolCmd(cmd *cobra.Command, args []strin
 		log.Fatalf("Name can be at most 32 characters long")
 	}
 
+	if CreateWorkloadIdentityPoolOpts.TargetDir == "" && CreateWorkloadIdentityPoolOpts.DryRun {
+		CreateWorkloadIdentityPoolOpts.TargetDir = provisioning.DryRunOutputDir("gcp")
+	}
 	if CreateWorkloadIdentityPoolOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -86,6 +90,13 @@ func validationForCreateWorkloadIdentityPoolCmd(cmd *cobra.Command, args []strin
 }
 
 func createWorkloadIdentityPool(ctx context.Context, client gcp.Client, name, project, targetDir string, generateOnly bool) error {
+	ctx, span := provisioning.StartSpan(ctx, "gcp.EnsureWorkloadIdentityPool",
+		provisioning.ProviderAttribute.String("gcp"),
+		provisioning.ResourceTypeAttribute.String("workloadIdentityPool"),
+		provisioning.ResourceNameAttribute.String(name),
+	)
+	defer span.End()
+
 	if generateOnly {
 		createWorkloadIdentityPoolScript := provisioning.CreateShellScript([]string{
 			fmt.Sprintf(createIdentityPoolCmd, name, name),
@@ -120,6 +131,7 @@ func createWorkloadIdentityPool(ctx context.Context, client gcp.Client, name, pr
 					return errors.Wrapf(err, "failed to create workload identity pool %s", name)
 				}
 				log.Printf("Workload identity pool created with name %s", name)
+				provisioning.EmitResourceEvent("WorkloadIdentityPool", name, provisioning.ResourceCreated)
 			} else {
 				return errors.Wrapf(err, "failed to check if there is existing workload identity pool %s", name)
 			}
@@ -142,10 +154,13 @@ func NewCreateWorkloadIdentityPool() *cobra.Command {
 
 	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.Name, "name", "", "User-defined name for all created Google cloud resources (can be separate from the cluster's infra-id)")
 	createWorkloadIdentityPoolCmd.MarkPersistentFlagRequired("name")
-	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.Project, "project", "", "ID of the Google cloud project")
+	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.Project, "project", "", "ID or number of the Google cloud project")
 	createWorkloadIdentityPoolCmd.MarkPersistentFlagRequired("project")
 	createWorkloadIdentityPoolCmd.PersistentFlags().BoolVar(&CreateWorkloadIdentityPoolOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
-	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
+	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory, or a temporary directory when --dry-run is specified)")
+	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.CredentialsFile, "credentials-file", "", "File containing the service account key used to authenticate to Google cloud. Use \"-\" to read the key from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, credentials are loaded from the default locations")
+	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.ImpersonateServiceAccount, "impersonate-service-account", "", "Email of a service account to impersonate, every operation is performed as the service account. The loaded credentials must be granted the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account")
+	createWorkloadIdentityPoolCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityPoolOpts.QuotaProject, "quota-project", "", "ID or number of the project to which the quota and billing of GCP API calls are attributed, sent as the x-goog-user-project header, eg. when the credentials belong to another project than --project. The credentials must be granted the serviceusage.services.use permission on the project. Defaults to the project inferred from the credentials when not specified")
 
 	return createWorkloadIdentityPoolCmd
 }
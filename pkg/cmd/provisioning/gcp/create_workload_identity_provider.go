@@ -53,30 +53,35 @@ const (
 )
 
 func createWorkloadIdentityProviderCmd(cmd *cobra.Command, args []string) {
+	if err := CreateWorkloadIdentityProviderOpts.DiscoveryDocument.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
 	ctx := context.Background()
 
-	creds, err := loadCredentials(ctx)
+	creds, err := loadCredentials(ctx, CreateWorkloadIdentityProviderOpts.CredentialsFile, CreateWorkloadIdentityProviderOpts.ImpersonateServiceAccount)
 	if err != nil {
 		log.Fatalf("Failed to load credentials: %s", err)
 	}
 
-	gcpClient, err := gcp.NewClient(CreateWorkloadIdentityProviderOpts.Project, creds)
+	gcpClient, project, err := newClientForProject(ctx, CreateWorkloadIdentityProviderOpts.Project, creds, CreateWorkloadIdentityProviderOpts.QuotaProject)
 	if err != nil {
 		log.Fatal(err)
 	}
+	CreateWorkloadIdentityProviderOpts.Project = project.ID
 
 	publicKeyPath := CreateWorkloadIdentityProviderOpts.PublicKeyPath
 	if publicKeyPath == "" {
 		publicKeyPath = filepath.Join(CreateWorkloadIdentityProviderOpts.TargetDir, provisioning.PublicKeyFile)
 	}
 
-	err = createWorkloadIdentityProvider(ctx, gcpClient, CreateWorkloadIdentityProviderOpts.Name, CreateWorkloadIdentityProviderOpts.Region, CreateWorkloadIdentityProviderOpts.Project, CreateWorkloadIdentityProviderOpts.WorkloadIdentityPool, publicKeyPath, CreateWorkloadIdentityProviderOpts.TargetDir, CreateWorkloadIdentityProviderOpts.DryRun)
+	err = createWorkloadIdentityProvider(ctx, gcpClient, CreateWorkloadIdentityProviderOpts.Name, CreateWorkloadIdentityProviderOpts.Region, CreateWorkloadIdentityProviderOpts.Project, CreateWorkloadIdentityProviderOpts.WorkloadIdentityPool, publicKeyPath, CreateWorkloadIdentityProviderOpts.TargetDir, CreateWorkloadIdentityProviderOpts.DryRun, CreateWorkloadIdentityProviderOpts.VerifyIssuerReachable, CreateWorkloadIdentityProviderOpts.DiscoveryDocument)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-func createWorkloadIdentityProvider(ctx context.Context, client gcp.Client, name, region, project, workloadIdentityPool string, publicKeyPath, targetDir string, generateOnly bool) error {
+func createWorkloadIdentityProvider(ctx context.Context, client gcp.Client, name, region, project, workloadIdentityPool string, publicKeyPath, targetDir string, generateOnly, verifyIssuerReachable bool, discoveryDocument provisioning.DiscoveryDocumentExtensions) error {
 	// Create a storage bucket
 	bucketName := fmt.Sprintf("%s-oidc", name)
 	if err := createOIDCBucket(ctx, client, bucketName, region, project, targetDir, generateOnly); err != nil {
@@ -85,7 +90,7 @@ func createWorkloadIdentityProvider(ctx context.Context, client gcp.Client, name
 	issuerURL := fmt.Sprintf("https://storage.googleapis.com/%s", bucketName)
 
 	// Create the OIDC config file
-	if err := createOIDCConfiguration(ctx, client, bucketName, issuerURL, targetDir, generateOnly); err != nil {
+	if err := createOIDCConfiguration(ctx, client, bucketName, issuerURL, targetDir, generateOnly, discoveryDocument); err != nil {
 		return err
 	}
 
@@ -94,6 +99,13 @@ func createWorkloadIdentityProvider(ctx context.Context, client gcp.Client, name
 		return err
 	}
 
+	// Ensure the OIDC documents are publicly served before the workload identity provider trusts the issuer
+	if verifyIssuerReachable && !generateOnly {
+		if err := provisioning.VerifyIssuerReachable(issuerURL); err != nil {
+			return err
+		}
+	}
+
 	// Create the workload identity provider
 	err := createIdentityProvider(ctx, client, name, project, issuerURL, workloadIdentityPool, targetDir, generateOnly)
 	if err != nil {
@@ -101,7 +113,7 @@ func createWorkloadIdentityProvider(ctx context.Context, client gcp.Client, name
 	}
 
 	// Create the installer manifest file
-	if err := provisioning.CreateClusterAuthentication(issuerURL, targetDir); err != nil {
+	if err := provisioning.CreateClusterAuthentication(issuerURL, targetDir, generateOnly); err != nil {
 		return err
 	}
 
@@ -109,6 +121,14 @@ func createWorkloadIdentityProvider(ctx context.Context, client gcp.Client, name
 }
 
 func createOIDCBucket(ctx context.Context, client gcp.Client, bucketName, region, project, targetDir string, generateOnly bool) error {
+	ctx, span := provisioning.StartSpan(ctx, "gcp.EnsureOIDCBucket",
+		provisioning.ProviderAttribute.String("gcp"),
+		provisioning.ResourceTypeAttribute.String("storageBucket"),
+		provisioning.ResourceNameAttribute.String(bucketName),
+		provisioning.RegionAttribute.String(region),
+	)
+	defer span.End()
+
 	if generateOnly {
 		createOidcBucketScript := provisioning.CreateShellScript([]string{createOidcBucketCmd, makeBucketPubliclyReadableCmd})
 		createOidcBucketScriptFilepath := filepath.Join(targetDir, createOidcBucketScriptName)
@@ -132,6 +152,7 @@ func createOIDCBucket(ctx context.Context, client gcp.Client, bucketName, region
 					return errors.Wrapf(err, "failed to create the bucket %s to store OpenID Connect configuration", bucketName)
 				}
 				log.Print("Bucket ", bucketName, " created")
+				provisioning.EmitResourceEvent("StorageBucket", bucketName, provisioning.ResourceCreated)
 
 				policy, err := client.GetBucketPolicy(ctx, bucketName)
 				if err != nil {
@@ -157,8 +178,11 @@ func createOIDCBucket(ctx context.Context, client gcp.Client, bucketName, region
 	return nil
 }
 
-func createOIDCConfiguration(ctx context.Context, client gcp.Client, bucketName, issuerURL, targetDir string, generateOnly bool) error {
-	discoveryDocumentJSON := fmt.Sprintf(provisioning.DiscoveryDocumentTemplate, issuerURL, issuerURL, provisioning.KeysURI)
+func createOIDCConfiguration(ctx context.Context, client gcp.Client, bucketName, issuerURL, targetDir string, generateOnly bool, discoveryDocument provisioning.DiscoveryDocumentExtensions) error {
+	discoveryDocumentJSON, err := provisioning.ExtendDiscoveryDocument(fmt.Sprintf(provisioning.DiscoveryDocumentTemplate, issuerURL, issuerURL, provisioning.KeysURI), discoveryDocument)
+	if err != nil {
+		return err
+	}
 	if generateOnly {
 		discoveryDocumentFilepath := filepath.Join(targetDir, gcpOidcConfigurationFilename)
 		log.Printf("Saving discovery document locally at %s", discoveryDocumentFilepath)
@@ -171,6 +195,7 @@ func createOIDCConfiguration(ctx context.Context, client gcp.Client, bucketName,
 			return errors.Wrapf(err, "failed to upload discovery document in the bucket %s", bucketName)
 		}
 		log.Printf("OpenID Connect discovery document in the S3 bucket %s at %s updated", bucketName, provisioning.DiscoveryDocumentURI)
+		provisioning.EmitResourceEvent("StorageObject", bucketName+"/"+provisioning.DiscoveryDocumentURI, provisioning.ResourceUpdated)
 	}
 	return nil
 }
@@ -180,7 +205,15 @@ func createJSONWebKeySet(ctx context.Context, client gcp.Client, publicKeyFilepa
 	if err != nil {
 		return errors.Wrap(err, "failed to build JSON web key set from the public key")
 	}
+	if err := provisioning.WriteJWKSPEMBundle(jwks, targetDir); err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(ctx, client, jwks, bucketName, targetDir, generateOnly)
+}
 
+// publishJSONWebKeySet uploads the JSON web key set jwks to the OIDC storage bucket identified by bucketName or, when
+// generateOnly is provided, saves it within targetDir
+func publishJSONWebKeySet(ctx context.Context, client gcp.Client, jwks []byte, bucketName, targetDir string, generateOnly bool) error {
 	if generateOnly {
 		JWKSFilePath := filepath.Join(targetDir, gcpOidcKeysFilename)
 		log.Printf("Saving JSON web key set (JWKS) locally at %s", JWKSFilePath)
@@ -188,16 +221,24 @@ func createJSONWebKeySet(ctx context.Context, client gcp.Client, publicKeyFilepa
 			return errors.Wrap(err, fmt.Sprintf("Failed to save JSON web key set (JWKS) locally at %s", JWKSFilePath))
 		}
 	} else {
-		err = client.PutObject(ctx, bucketName, provisioning.KeysURI, jwks)
+		err := client.PutObject(ctx, bucketName, provisioning.KeysURI, jwks)
 		if err != nil {
 			return errors.Wrapf(err, "failed to upload JSON web key set (JWKS) in the S3 bucket %s", bucketName)
 		}
 		log.Printf("JSON web key set (JWKS) in the S3 bucket %s at %s updated", bucketName, provisioning.KeysURI)
+		provisioning.EmitResourceEvent("StorageObject", bucketName+"/"+provisioning.KeysURI, provisioning.ResourceUpdated)
 	}
 	return nil
 }
 
 func createIdentityProvider(ctx context.Context, client gcp.Client, name, project, issuerURL, workloadIdentityPool, targetDir string, generateOnly bool) error {
+	ctx, span := provisioning.StartSpan(ctx, "gcp.EnsureWorkloadIdentityProvider",
+		provisioning.ProviderAttribute.String("gcp"),
+		provisioning.ResourceTypeAttribute.String("workloadIdentityProvider"),
+		provisioning.ResourceNameAttribute.String(name),
+	)
+	defer span.End()
+
 	if generateOnly {
 		createIdentityProviderScript := provisioning.CreateShellScript([]string{createIdentityProviderCmd})
 		createIdentityProviderScriptFilepath := filepath.Join(targetDir, createIdentityProviderScriptName)
@@ -234,6 +275,7 @@ func createIdentityProvider(ctx context.Context, client gcp.Client, name, projec
 					return errors.Wrapf(err, "failed to create workload identity provider %s", name)
 				}
 				log.Printf("workload identity provider created with name %s", name)
+				provisioning.EmitResourceEvent("WorkloadIdentityProvider", name, provisioning.ResourceCreated)
 			} else {
 				return errors.Wrapf(err, "failed to check if there is existing workload identity provider %s in pool %s", name, workloadIdentityPool)
 			}
@@ -252,6 +294,9 @@ func validationForCreateWorkloadIdentityProviderCmd(cmd *cobra.Command, args []s
 		log.Fatalf("Name can be at most 32 characters long")
 	}
 
+	if CreateWorkloadIdentityProviderOpts.TargetDir == "" && CreateWorkloadIdentityProviderOpts.DryRun {
+		CreateWorkloadIdentityProviderOpts.TargetDir = provisioning.DryRunOutputDir("gcp")
+	}
 	if CreateWorkloadIdentityProviderOpts.TargetDir == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -292,13 +337,19 @@ func NewCreateWorkloadIdentityProviderCmd() *cobra.Command {
 	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.Name, "name", "", "User-defined name for all created Google cloud resources (can be separate from the cluster's infra-id)")
 	createWorkloadIdentityProviderCmd.MarkPersistentFlagRequired("name")
 	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.Region, "region", "us", "Google cloud region where the Google Storage Bucket holding the OpenID Connect configuration will be created")
-	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.Project, "project", "", "ID of the Google cloud project")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.Project, "project", "", "ID or number of the Google cloud project")
 	createWorkloadIdentityProviderCmd.MarkPersistentFlagRequired("project")
 	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.WorkloadIdentityPool, "workload-identity-pool", "", "Pool to create this provider in")
 	createWorkloadIdentityProviderCmd.MarkPersistentFlagRequired("workload-identity-pool")
 	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.PublicKeyPath, "public-key-file", "", "Path to public ServiceAccount signing key")
 	createWorkloadIdentityProviderCmd.PersistentFlags().BoolVar(&CreateWorkloadIdentityProviderOpts.DryRun, "dry-run", false, "Skip creating objects, and just save what would have been created into files")
-	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
+	createWorkloadIdentityProviderCmd.PersistentFlags().BoolVar(&CreateWorkloadIdentityProviderOpts.VerifyIssuerReachable, "verify-issuer-reachable", false, "Fetch the OIDC discovery document and JSON web key set from the issuer URL over the public network once they have been uploaded, honoring the HTTPS_PROXY and NO_PROXY environment variables, and fail if they are not publicly served. Skipped with --dry-run")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringSliceVar(&CreateWorkloadIdentityProviderOpts.DiscoveryDocument.Audiences, "oidc-extra-audience", nil, "Audience to append to the audiences_supported of the OIDC discovery document, eg. for an identity consumer of the issuer other than the cluster. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringSliceVar(&CreateWorkloadIdentityProviderOpts.DiscoveryDocument.Claims, "oidc-extra-claim", nil, "Claim to append to the claims_supported of the OIDC discovery document. The defaults required by the cluster are kept. May be specified multiple times, or as a comma-separated list")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory, or a temporary directory when --dry-run is specified)")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.CredentialsFile, "credentials-file", "", "File containing the service account key used to authenticate to Google cloud. Use \"-\" to read the key from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, credentials are loaded from the default locations")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.ImpersonateServiceAccount, "impersonate-service-account", "", "Email of a service account to impersonate, every operation is performed as the service account. The loaded credentials must be granted the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account")
+	createWorkloadIdentityProviderCmd.PersistentFlags().StringVar(&CreateWorkloadIdentityProviderOpts.QuotaProject, "quota-project", "", "ID or number of the project to which the quota and billing of GCP API calls are attributed, sent as the x-goog-user-project header, eg. when the credentials belong to another project than --project. The credentials must be granted the serviceusage.services.use permission on the project. Defaults to the project inferred from the credentials when not specified")
 
 	return createWorkloadIdentityProviderCmd
 }
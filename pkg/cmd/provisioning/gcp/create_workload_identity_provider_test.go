@@ -189,7 +189,7 @@ func TestCreateWorkloadIdentityProvider(t *testing.T) {
 			defer os.RemoveAll(tempDirName)
 
 			testPublicKeyPath := filepath.Join(tempDirName, testPublicKeyFile)
-			err := createWorkloadIdentityProvider(context.TODO(), mockGCPClient, testInfraName, testRegionName, testProject, testName, testPublicKeyPath, tempDirName, test.generateOnly)
+			err := createWorkloadIdentityProvider(context.TODO(), mockGCPClient, testInfraName, testRegionName, testProject, testName, testPublicKeyPath, tempDirName, test.generateOnly, false, provisioning.DiscoveryDocumentExtensions{})
 
 			if test.expectError {
 				require.Error(t, err, "expected error returned")
@@ -8,11 +8,14 @@ package gcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -20,6 +23,10 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2/google"
 	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 )
 
 var (
@@ -27,16 +34,94 @@ var (
 	defaultAuthFilePath = filepath.Join(os.Getenv("HOME"), ".gcp", "osServiceAccount.json")
 	credLoaders         = []credLoader{}
 	onceLoggers         = map[credLoader]*sync.Once{}
+
+	// serviceAccountEmailRegexp matches the email of a Google cloud service account
+	serviceAccountEmailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.gserviceaccount\.com$`)
 )
 
-// loadCredentials returns a GCP credentials found in default locations in order:
+// loadCredentials returns the GCP credentials loaded by loadSourceCredentials. When impersonateServiceAccount is
+// provided the loaded credentials are used to impersonate the service account through the IAM Credentials API and
+// credentials of the impersonated service account are returned instead.
+func loadCredentials(ctx context.Context, credentialsFile, impersonateServiceAccount string) (*google.Credentials, error) {
+	creds, err := loadSourceCredentials(ctx, credentialsFile)
+	if err != nil || impersonateServiceAccount == "" {
+		if err == nil {
+			provisioning.RegisterAuditIdentity("gcp", func() string { return credentialsIdentity(creds) })
+		}
+		return creds, err
+	}
+	creds, err = impersonateCredentials(ctx, creds, impersonateServiceAccount)
+	if err == nil {
+		provisioning.RegisterAuditIdentity("gcp", func() string { return impersonateServiceAccount })
+	}
+	return creds, err
+}
+
+// impersonateCredentials returns credentials of the service account identified by serviceAccount which are issued by
+// impersonating the service account with creds. A token is requested immediately so that a lack of permission to
+// impersonate the service account is reported before any resources are operated upon.
+func impersonateCredentials(ctx context.Context, creds *google.Credentials, serviceAccount string) (*google.Credentials, error) {
+	if !serviceAccountEmailRegexp.MatchString(serviceAccount) {
+		return nil, fmt.Errorf("invalid --impersonate-service-account %q, expected the email of a service account, eg. name@project.iam.gserviceaccount.com", serviceAccount)
+	}
+
+	tokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: serviceAccount,
+		Scopes:          []string{compute.CloudPlatformScope},
+	}, option.WithCredentials(creds), option.WithUserAgent(provisioning.UserAgent()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to impersonate service account %s", serviceAccount)
+	}
+	if _, err := tokenSource.Token(); err != nil {
+		return nil, errors.Wrapf(err, "failed to impersonate service account %s, the credentials must be granted "+
+			"the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account", serviceAccount)
+	}
+
+	if sourceIdentity := credentialsIdentity(creds); sourceIdentity != "" {
+		log.Printf("Impersonating service account %s with the credentials of %s", serviceAccount, sourceIdentity)
+	} else {
+		log.Printf("Impersonating service account %s", serviceAccount)
+	}
+	log.Printf("All operations will be performed as %s", serviceAccount)
+
+	return &google.Credentials{
+		ProjectID:   creds.ProjectID,
+		TokenSource: tokenSource,
+	}, nil
+}
+
+// credentialsIdentity returns the email of the service account identified by creds, or an empty string when
+// creds do not identify a service account, eg. the credentials of a user logged in with the gcloud CLI
+func credentialsIdentity(creds *google.Credentials) string {
+	var key struct {
+		ClientEmail string `json:"client_email"`
+	}
+	if err := json.Unmarshal(creds.JSON, &key); err != nil {
+		return ""
+	}
+	return key.ClientEmail
+}
+
+// loadSourceCredentials returns the GCP credentials read from credentialsFile when provided, which may be "-"
+// to read the credentials from stdin or "env:NAME" to read them from the environment variable NAME.
+// Otherwise loadSourceCredentials returns a GCP credentials found in default locations in order:
 // env GOOGLE_CREDENTIALS,
 // env GOOGLE_CLOUD_KEYFILE_JSON,
 // env GCLOUD_KEYFILE_JSON,
 // file ~/.gcp/osServiceAccount.json, and
 // gcloud cli defaults
 // and, if no creds are found, asks for them and stores them on disk in a config file
-func loadCredentials(ctx context.Context) (*google.Credentials, error) {
+func loadSourceCredentials(ctx context.Context, credentialsFile string) (*google.Credentials, error) {
+	if credentialsFile != "" {
+		loader := &sourceLoader{source: credentialsFile, stdin: os.Stdin}
+		creds, err := loader.Load(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load credentials from %s", loader)
+		}
+		log.Printf("Credentials loaded from %s", loader)
+		return creds, nil
+	}
+
 	if len(credLoaders) == 0 {
 		for _, authEnv := range authEnvs {
 			credLoaders = append(credLoaders, &envLoader{env: authEnv})
@@ -145,6 +230,23 @@ func (f *fileLoader) String() string {
 	return fmt.Sprintf("file %q", f.path)
 }
 
+type sourceLoader struct {
+	source string
+	stdin  io.Reader
+}
+
+func (s *sourceLoader) Load(ctx context.Context) (*google.Credentials, error) {
+	content, err := provisioning.ReadCredentialsSource(s.source, s.stdin)
+	if err != nil {
+		return nil, err
+	}
+	return (&contentLoader{content: string(content)}).Load(ctx)
+}
+
+func (s *sourceLoader) String() string {
+	return provisioning.DescribeCredentialsSource(s.source)
+}
+
 type contentLoader struct {
 	content string
 }
@@ -0,0 +1,46 @@
+package gcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2/google"
+)
+
+func TestImpersonateCredentialsInvalidServiceAccount(t *testing.T) {
+	for _, serviceAccount := range []string{"provisioner", "user@example.com", "provisioner@my-project.iam.gserviceaccount.com.evil"} {
+		t.Run(serviceAccount, func(t *testing.T) {
+			_, err := impersonateCredentials(context.Background(), &google.Credentials{}, serviceAccount)
+			require.Error(t, err, "expected error")
+			assert.Contains(t, err.Error(), "invalid --impersonate-service-account")
+		})
+	}
+}
+
+func TestCredentialsIdentity(t *testing.T) {
+	tests := []struct {
+		name             string
+		json             string
+		expectedIdentity string
+	}{
+		{
+			name:             "Service account key",
+			json:             `{"type": "service_account", "client_email": "bootstrap@my-project.iam.gserviceaccount.com"}`,
+			expectedIdentity: "bootstrap@my-project.iam.gserviceaccount.com",
+		},
+		{
+			name: "Authorized user",
+			json: `{"type": "authorized_user", "client_id": "123.apps.googleusercontent.com"}`,
+		},
+		{
+			name: "No JSON",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedIdentity, credentialsIdentity(&google.Credentials{JSON: []byte(test.json)}))
+		})
+	}
+}
@@ -8,7 +8,13 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iam/v1"
 	iamadminpb "google.golang.org/genproto/googleapis/iam/admin/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
 
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 	"github.com/openshift/cloud-credential-operator/pkg/gcp"
@@ -35,6 +41,7 @@ func deleteOIDCObjectsFromBucket(ctx context.Context, client gcp.Client, bucketN
 			return errors.Wrapf(err, "Failed to delete object %s from bucket %s", attr.Name, bucketName)
 		}
 		log.Printf("Deleted object %s from bucket %s", attr.Name, bucketName)
+		provisioning.EmitResourceEvent("StorageObject", bucketName+"/"+attr.Name, provisioning.ResourceDeleted)
 	}
 
 	return nil
@@ -42,23 +49,31 @@ func deleteOIDCObjectsFromBucket(ctx context.Context, client gcp.Client, bucketN
 
 // deleteOIDCBucket deletes the OIDC cloud storage bucket
 func deleteOIDCBucket(ctx context.Context, client gcp.Client, bucketName, namePrefix string) error {
+	ctx, span := provisioning.StartSpan(ctx, "gcp.DeleteOIDCBucket",
+		provisioning.ProviderAttribute.String("gcp"),
+		provisioning.ResourceTypeAttribute.String("storageBucket"),
+		provisioning.ResourceNameAttribute.String(bucketName),
+	)
+	defer span.End()
+
 	err := client.DeleteBucket(ctx, bucketName)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to delete the OIDC bucket %s", bucketName)
 	}
 	log.Printf("OIDC bucket %s deleted", bucketName)
+	provisioning.EmitResourceEvent("StorageBucket", bucketName, provisioning.ResourceDeleted)
 
 	return nil
 }
 
 // deleteServiceAccounts deletes the IAM service accounts created by ccoctl
-func deleteServiceAccounts(ctx context.Context, client gcp.Client, namePrefix, credReqDir string) error {
+func deleteServiceAccounts(ctx context.Context, client gcp.Client, namePrefix string, credReqDirs []string) error {
 	projectName := client.GetProjectName()
 	projectResourceName := fmt.Sprintf("projects/%s", projectName)
 
 	// Process directory
 	// always tech-preview==true because we should do a full cleanup to be on the safe side
-	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDir, true)
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, true)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
@@ -92,6 +107,7 @@ func deleteServiceAccounts(ctx context.Context, client gcp.Client, namePrefix, c
 				}
 
 				log.Printf("IAM service account %s deleted", svcAcct.DisplayName)
+				provisioning.EmitResourceEvent("IAMServiceAccount", svcAcct.Email, provisioning.ResourceDeleted)
 			}
 		}
 	}
@@ -99,13 +115,13 @@ func deleteServiceAccounts(ctx context.Context, client gcp.Client, namePrefix, c
 }
 
 // deleteCustomRoles deletes the IAM custom roles created by ccoctl
-func deleteCustomRoles(ctx context.Context, client gcp.Client, namePrefix, credReqDir string) error {
+func deleteCustomRoles(ctx context.Context, client gcp.Client, namePrefix string, credReqDirs []string) error {
 	projectName := client.GetProjectName()
 	projectResourceName := fmt.Sprintf("projects/%s", projectName)
 
 	// Process directory
 	// always tech-preview==true because we should do a full cleanup to be on the safe side
-	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDir, true)
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, true)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
@@ -132,6 +148,7 @@ func deleteCustomRoles(ctx context.Context, client gcp.Client, namePrefix, credR
 					return errors.Wrapf(err, "Failed to delete custom role")
 				}
 				log.Printf("IAM custom role %s deleted", role.Title)
+				provisioning.EmitResourceEvent("IAMCustomRole", role.Name, provisioning.ResourceDeleted)
 			}
 		}
 		nextPageToken := listRolesResponse.NextPageToken
@@ -151,6 +168,7 @@ func deleteCustomRoles(ctx context.Context, client gcp.Client, namePrefix, credR
 						return errors.Wrapf(err, "Failed to delete custom role")
 					}
 					log.Printf("IAM custom role %s deleted", role.Title)
+					provisioning.EmitResourceEvent("IAMCustomRole", role.Name, provisioning.ResourceDeleted)
 				}
 			}
 			nextPageToken = listRolesResponse.NextPageToken
@@ -164,53 +182,238 @@ func isCreatedByCcoctl(identity, name string) bool {
 	return strings.HasPrefix(identity, name+"-")
 }
 
+// deleteWorkloadIdentityProviders deletes every workload identity provider of the workload identity pool, whatever
+// its attribute mapping and condition, as the pool named after --name and all of its providers are owned by ccoctl.
+// Providers are deleted explicitly, rather than along with the pool, so that providers modified or added out of band
+// are reported and providers already deleted are skipped.
+func deleteWorkloadIdentityProviders(ctx context.Context, client gcp.Client, poolName string) error {
+	poolResource := fmt.Sprintf("projects/%s/locations/global/workloadIdentityPools/%s", client.GetProjectName(), poolName)
+
+	providers := []*iam.WorkloadIdentityPoolProvider{}
+	pageToken := ""
+	for {
+		resp, err := client.ListWorkloadIdentityProviders(ctx, poolResource, pageToken)
+		if err != nil {
+			if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+				log.Printf("Workload identity pool %s not found, skipping the deletion of its providers", poolName)
+				return nil
+			}
+			return errors.Wrapf(err, "Failed to list the providers of workload identity pool %s", poolName)
+		}
+		providers = append(providers, resp.WorkloadIdentityPoolProviders...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	errs := []error{}
+	for _, provider := range providers {
+		// The resource name of a provider ends with its ID
+		providerID := provider.Name[strings.LastIndex(provider.Name, "/")+1:]
+		if provider.State == "DELETED" {
+			log.Printf("Workload identity provider %s already deleted", providerID)
+			continue
+		}
+		if _, err := client.DeleteWorkloadIdentityProvider(ctx, provider.Name); err != nil {
+			if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+				log.Printf("Workload identity provider %s already deleted", providerID)
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "Failed to delete workload identity provider %s", providerID))
+			continue
+		}
+		log.Printf("Workload identity provider %s deleted", providerID)
+		provisioning.EmitResourceEvent("WorkloadIdentityProvider", providerID, provisioning.ResourceDeleted)
+	}
+	return provisioning.JoinDeletionErrors(errs)
+}
+
 // deleteWorkloadIdentityPool deletes the workload identity pool along with the providers
 func deleteWorkloadIdentityPool(ctx context.Context, client gcp.Client, poolName string) error {
+	ctx, span := provisioning.StartSpan(ctx, "gcp.DeleteWorkloadIdentityPool",
+		provisioning.ProviderAttribute.String("gcp"),
+		provisioning.ResourceTypeAttribute.String("workloadIdentityPool"),
+		provisioning.ResourceNameAttribute.String(poolName),
+	)
+	defer span.End()
+
 	projectName := client.GetProjectName()
 	poolResource := fmt.Sprintf("projects/%s/locations/global/workloadIdentityPools/%s", projectName, poolName)
 
 	_, err := client.DeleteWorkloadIdentityPool(ctx, poolResource)
 	if err != nil {
+		if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+			log.Printf("Workload identity pool %s not found", poolName)
+			return nil
+		}
 		return errors.Wrapf(err, "Failed to delete workload identity pool %s", poolName)
 	}
 	log.Printf("Workload identity pool %s deleted", poolName)
+	provisioning.EmitResourceEvent("WorkloadIdentityPool", poolName, provisioning.ResourceDeleted)
 	return nil
 }
 
+// verifyIssuerURL ensures that the workload identity provider which will be deleted along with the workload identity
+// pool named after name was created for issuerURL before any resource is deleted
+func verifyIssuerURL(ctx context.Context, client gcp.Client, name, issuerURL string) error {
+	providerResource := fmt.Sprintf("projects/%s/locations/global/workloadIdentityPools/%s/providers/%s", client.GetProjectName(), name, name)
+	provider, err := client.GetWorkloadIdentityProvider(ctx, providerResource)
+	if err != nil {
+		if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+			return provisioning.VerifyDiscoveredIssuerURL(issuerURL, "", "")
+		}
+		return errors.Wrapf(err, "Failed to get workload identity provider %s", name)
+	}
+	discoveredIssuerURL := ""
+	if provider.Oidc != nil {
+		discoveredIssuerURL = provider.Oidc.IssuerUri
+	}
+	return provisioning.VerifyDiscoveredIssuerURL(issuerURL, discoveredIssuerURL, fmt.Sprintf("workload identity provider %s", name))
+}
+
+// deletionPermissions returns the permissions required to delete the resources created by ccoctl for name
+func deletionPermissions(name string) []provisioning.DeletionPermission {
+	bucketName := fmt.Sprintf("%s-oidc", name)
+	objects := fmt.Sprintf("the objects of the OIDC bucket %s", bucketName)
+	serviceAccounts := fmt.Sprintf("the IAM service accounts of %s", name)
+	return []provisioning.DeletionPermission{
+		{Permission: "storage.objects.list", Blocks: objects},
+		{Permission: "storage.objects.delete", Blocks: objects},
+		{Permission: "storage.buckets.delete", Blocks: fmt.Sprintf("the OIDC bucket %s", bucketName)},
+		{Permission: "iam.roles.delete", Blocks: fmt.Sprintf("the custom roles of %s", name)},
+		{Permission: "resourcemanager.projects.setIamPolicy", Blocks: serviceAccounts},
+		{Permission: "iam.serviceAccounts.delete", Blocks: serviceAccounts},
+		{Permission: "iam.workloadIdentityPoolProviders.delete", Blocks: fmt.Sprintf("the providers of the workload identity pool %s", name)},
+		{Permission: "iam.workloadIdentityPools.delete", Blocks: fmt.Sprintf("the workload identity pool %s", name)},
+	}
+}
+
+// checkDeletionPermissions tests the permissions of the identity with which deletion is performed on the project of
+// the client, to verify that it is permitted to delete the resources created by ccoctl for name before any of them is
+// deleted. When the permissions cannot be tested the check is reported as skipped, which fails it when
+// failOnInsufficient is provided.
+func checkDeletionPermissions(client gcp.Client, name string, failOnInsufficient bool) error {
+	required := deletionPermissions(name)
+	permissions := make([]string, 0, len(required))
+	for _, permission := range required {
+		permissions = append(permissions, permission.Permission)
+	}
+	projectName := client.GetProjectName()
+	response, err := client.TestIamPermissions(projectName, &cloudresourcemanager.TestIamPermissionsRequest{Permissions: permissions})
+	if err != nil {
+		return provisioning.ReportUncheckedDeletionPermissions(errors.Wrapf(err, "failed to test the permissions on project %s", projectName), failOnInsufficient)
+	}
+	granted := sets.NewString(response.Permissions...)
+
+	missing := []provisioning.DeletionPermission{}
+	for _, permission := range required {
+		if !granted.Has(permission.Permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return provisioning.ReportMissingDeletionPermissions(missing, failOnInsufficient)
+}
+
 func deleteCmd(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	// The context of the command carries the deadline of --timeout
+	ctx := cmd.Context()
+
+	if err := provisioning.ResolveNameFromKubeconfig(ctx, &DeleteOpts.Name, DeleteOpts.NameFromKubeconfig, DeleteOpts.ConfirmNameFromKubeconfig, configv1.GCPPlatformType); err != nil {
+		log.Fatal(err)
+	}
+
+	projects, err := provisioning.UniqueScopes("--project", DeleteOpts.Projects)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	creds, err := loadCredentials(ctx)
+	creds, err := loadCredentials(ctx, DeleteOpts.CredentialsFile, DeleteOpts.ImpersonateServiceAccount)
 	if err != nil {
 		log.Fatalf("Failed to load credentials: %s", err)
 	}
 
-	gcpClient, err := gcp.NewClient(DeleteOpts.Project, creds)
+	err = provisioning.DeleteAcrossScopes("project", projects, DeleteOpts.ContinueOnError, func(project string) error {
+		gcpClient, _, err := newClientForProject(ctx, project, creds, DeleteOpts.QuotaProject)
+		if err != nil {
+			return err
+		}
+		// Resources are only deleted once they have been attributed to the issuer URL, the failure to do so is
+		// returned even when deleting within a single project since nothing has been deleted
+		if DeleteOpts.IssuerURL != "" {
+			if err := verifyIssuerURL(ctx, gcpClient, DeleteOpts.Name, DeleteOpts.IssuerURL); err != nil {
+				return err
+			}
+		}
+		// Likewise with --fail-on-insufficient-permissions when the identity lacks permissions required for deletion
+		if err := checkDeletionPermissions(gcpClient, DeleteOpts.Name, DeleteOpts.FailOnInsufficientPermissions); err != nil {
+			return err
+		}
+		err = deleteWithinProject(ctx, gcpClient, DeleteOpts.Name, DeleteOpts.CredRequestDirs)
+		// Resources remaining once deletion completes fail the deletion even within a single project
+		if DeleteOpts.VerifyAfterDelete {
+			provisioning.SetPhase("verifying that no resources remain")
+			if err := provisioning.VerifyDeletion(func() ([]string, error) {
+				return listRemainingResources(ctx, gcpClient, DeleteOpts.Name, DeleteOpts.CredRequestDirs)
+			}); err != nil {
+				return err
+			}
+		}
+		// Errors deleting the resources of a single project have already been logged and, as deletion is expected
+		// to be re-run after a partial failure, only result in a failure when deleting across several projects
+		if len(projects) == 1 {
+			return nil
+		}
+		return err
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
+}
+
+// deleteWithinProject deletes the resources created by ccoctl for name within the project of the client. Deletion
+// carries on after an error so that as many resources as possible are deleted, the errors encountered are returned.
+func deleteWithinProject(ctx context.Context, gcpClient gcp.Client, name string, credReqDirs []string) error {
+	bucketName := fmt.Sprintf("%s-oidc", name)
+	errs := []error{}
 
-	bucketName := fmt.Sprintf("%s-oidc", DeleteOpts.Name)
+	provisioning.SetPhase("deleting the objects of the OIDC bucket " + bucketName)
+	if err := deleteOIDCObjectsFromBucket(ctx, gcpClient, bucketName, name); err != nil {
+		log.Print(err)
+		errs = append(errs, err)
+	}
 
-	if err := deleteOIDCObjectsFromBucket(ctx, gcpClient, bucketName, DeleteOpts.Name); err != nil {
+	provisioning.SetPhase("deleting the OIDC bucket " + bucketName)
+	if err := deleteOIDCBucket(ctx, gcpClient, bucketName, name); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteOIDCBucket(ctx, gcpClient, bucketName, DeleteOpts.Name); err != nil {
+	provisioning.SetPhase("deleting custom roles")
+	if err := deleteCustomRoles(ctx, gcpClient, name, credReqDirs); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteCustomRoles(ctx, gcpClient, DeleteOpts.Name, DeleteOpts.CredRequestDir); err != nil {
+	provisioning.SetPhase("deleting IAM service accounts")
+	if err := deleteServiceAccounts(ctx, gcpClient, name, credReqDirs); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteServiceAccounts(ctx, gcpClient, DeleteOpts.Name, DeleteOpts.CredRequestDir); err != nil {
+	provisioning.SetPhase("deleting the providers of the workload identity pool")
+	if err := deleteWorkloadIdentityProviders(ctx, gcpClient, name); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
 
-	if err := deleteWorkloadIdentityPool(ctx, gcpClient, DeleteOpts.Name); err != nil {
+	provisioning.SetPhase("deleting the workload identity pool")
+	if err := deleteWorkloadIdentityPool(ctx, gcpClient, name); err != nil {
 		log.Print(err)
+		errs = append(errs, err)
 	}
+
+	return provisioning.JoinDeletionErrors(errs)
 }
 
 // NewDeleteCmd implements the "delete" command for the credentials provisioning
@@ -222,12 +425,20 @@ func NewDeleteCmd() *cobra.Command {
 		Run:   deleteCmd,
 	}
 
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Name, "name", "", "User-defined name for all created google cloud resources (can be separate from the cluster's infra-id)")
-	deleteCmd.MarkPersistentFlagRequired("name")
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Project, "project", "", "ID of the google cloud project")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.Name, "name", "", "User-defined name for all created google cloud resources (can be separate from the cluster's infra-id). Required unless --name-from-kubeconfig is specified")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.NameFromKubeconfig, "name-from-kubeconfig", false, "Delete the resources named after the infrastructure name of the cluster of the current context of the kubeconfig, read from its infrastructures.config.openshift.io object, rather than --name. Requires --confirm-name-from-kubeconfig")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.ConfirmNameFromKubeconfig, "confirm-name-from-kubeconfig", false, "Confirm the deletion of the resources named after the infrastructure name discovered with --name-from-kubeconfig")
+	deleteCmd.PersistentFlags().StringSliceVar(&DeleteOpts.Projects, "project", nil, "ID or number of the google cloud project. May be specified multiple times, or as a comma-separated list, to delete the resources within each project in turn")
 	deleteCmd.MarkPersistentFlagRequired("project")
-	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to delete IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=ibmcloud' against an OpenShift release image)")
+	deleteCmd.PersistentFlags().StringSliceVar(&DeleteOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to delete IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=ibmcloud' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	deleteCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.CredentialsFile, "credentials-file", "", "File containing the service account key used to authenticate to Google cloud. Use \"-\" to read the key from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, credentials are loaded from the default locations")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.ContinueOnError, "continue-on-error", false, "Continue deleting resources within the remaining projects when deletion within a project fails. Deletion fails overall if it failed within any project")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.ImpersonateServiceAccount, "impersonate-service-account", "", "Email of a service account to impersonate, every operation is performed as the service account. The loaded credentials must be granted the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.QuotaProject, "quota-project", "", "ID or number of the project to which the quota and billing of GCP API calls are attributed, sent as the x-goog-user-project header, eg. when the credentials belong to another project than --project. The credentials must be granted the serviceusage.services.use permission on the project. Defaults to the project inferred from the credentials when not specified")
+	deleteCmd.PersistentFlags().StringVar(&DeleteOpts.IssuerURL, "issuer-url", "", "Only delete resources when the workload identity provider created for --name was created for this OIDC issuer URL, eg. https://storage.googleapis.com/<name>-oidc. Guards against deleting the resources of another cluster created with the same name")
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.VerifyAfterDelete, provisioning.VerifyAfterDeleteFlag, false, provisioning.VerifyAfterDeleteFlagUsage)
+	deleteCmd.PersistentFlags().BoolVar(&DeleteOpts.FailOnInsufficientPermissions, "fail-on-insufficient-permissions", false, "Exit without deleting any resource when testing the permissions of the current identity on the project finds that it lacks permissions required to delete them, or fails. Otherwise the missing permissions are reported as warnings before deletion proceeds")
 
 	return deleteCmd
 }
@@ -0,0 +1,273 @@
+package gcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iam/v1"
+
+	mockgcp "github.com/openshift/cloud-credential-operator/pkg/gcp/mock"
+)
+
+func TestVerifyIssuerURL(t *testing.T) {
+	testIssuerURL := "https://storage.googleapis.com/" + testName + "-oidc"
+	tests := []struct {
+		name          string
+		mockGCPClient func(mockCtrl *gomock.Controller) *mockgcp.MockClient
+		issuerURL     string
+		expectError   bool
+	}{
+		{
+			name: "Issuer URL of the workload identity provider matches",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGetWorkloadIdentityProviderWithIssuer(mockGCPClient, testIssuerURL)
+				return mockGCPClient
+			},
+			issuerURL: testIssuerURL,
+		},
+		{
+			name: "Issuer URL of the workload identity provider does not match",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGetWorkloadIdentityProviderWithIssuer(mockGCPClient, "https://storage.googleapis.com/other-oidc")
+				return mockGCPClient
+			},
+			issuerURL:   testIssuerURL,
+			expectError: true,
+		},
+		{
+			name: "Workload identity provider not found",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGetWorkloadIdentityProviderFailure(mockGCPClient)
+				return mockGCPClient
+			},
+			issuerURL:   testIssuerURL,
+			expectError: true,
+		},
+		{
+			name: "Failure to get the workload identity provider",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().GetWorkloadIdentityProvider(gomock.Any(), gomock.Any()).Return(
+					nil, &googleapi.Error{Code: 403, Message: "Permission denied"}).Times(1)
+				return mockGCPClient
+			},
+			issuerURL:   testIssuerURL,
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := verifyIssuerURL(context.TODO(), test.mockGCPClient(mockCtrl), testName, test.issuerURL)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestDeleteWorkloadIdentityProviders(t *testing.T) {
+	poolResource := "projects/" + testProject + "/locations/global/workloadIdentityPools/" + testName
+	tests := []struct {
+		name          string
+		mockGCPClient func(mockCtrl *gomock.Controller) *mockgcp.MockClient
+		expectError   bool
+	}{
+		{
+			name: "Providers of every page deleted whatever their mapping and condition",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListWorkloadIdentityProviders(gomock.Any(), poolResource, "").Return(
+					&iam.ListWorkloadIdentityPoolProvidersResponse{
+						WorkloadIdentityPoolProviders: []*iam.WorkloadIdentityPoolProvider{
+							{
+								Name:             poolResource + "/providers/" + testName,
+								AttributeMapping: map[string]string{"google.subject": "assertion.sub"},
+							},
+							{
+								Name:               poolResource + "/providers/out-of-band",
+								AttributeMapping:   map[string]string{"google.subject": "assertion.sub", "attribute.namespace": "assertion['kubernetes.io']['namespace']"},
+								AttributeCondition: "attribute.namespace == 'openshift-image-registry'",
+							},
+						},
+						NextPageToken: "next",
+					}, nil).Times(1)
+				mockGCPClient.EXPECT().ListWorkloadIdentityProviders(gomock.Any(), poolResource, "next").Return(
+					&iam.ListWorkloadIdentityPoolProvidersResponse{
+						WorkloadIdentityPoolProviders: []*iam.WorkloadIdentityPoolProvider{
+							{
+								Name:               poolResource + "/providers/conditioned",
+								AttributeCondition: "assertion.sub.startsWith('system:serviceaccount:')",
+							},
+						},
+					}, nil).Times(1)
+				mockGCPClient.EXPECT().DeleteWorkloadIdentityProvider(gomock.Any(), poolResource+"/providers/"+testName).Return(&iam.Operation{}, nil).Times(1)
+				mockGCPClient.EXPECT().DeleteWorkloadIdentityProvider(gomock.Any(), poolResource+"/providers/out-of-band").Return(&iam.Operation{}, nil).Times(1)
+				mockGCPClient.EXPECT().DeleteWorkloadIdentityProvider(gomock.Any(), poolResource+"/providers/conditioned").Return(&iam.Operation{}, nil).Times(1)
+				return mockGCPClient
+			},
+		},
+		{
+			name: "Providers already deleted skipped",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListWorkloadIdentityProviders(gomock.Any(), poolResource, "").Return(
+					&iam.ListWorkloadIdentityPoolProvidersResponse{
+						WorkloadIdentityPoolProviders: []*iam.WorkloadIdentityPoolProvider{
+							{Name: poolResource + "/providers/" + testName, State: "DELETED"},
+							{Name: poolResource + "/providers/concurrently-deleted"},
+						},
+					}, nil).Times(1)
+				mockGCPClient.EXPECT().DeleteWorkloadIdentityProvider(gomock.Any(), poolResource+"/providers/concurrently-deleted").Return(
+					nil, &googleapi.Error{Code: 404, Message: "Requested entity was not found."}).Times(1)
+				return mockGCPClient
+			},
+		},
+		{
+			name: "Workload identity pool not found",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListWorkloadIdentityProviders(gomock.Any(), poolResource, "").Return(
+					nil, &googleapi.Error{Code: 404, Message: "Requested entity was not found."}).Times(1)
+				return mockGCPClient
+			},
+		},
+		{
+			name: "Failure to delete a provider does not stop the deletion of the others",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListWorkloadIdentityProviders(gomock.Any(), poolResource, "").Return(
+					&iam.ListWorkloadIdentityPoolProvidersResponse{
+						WorkloadIdentityPoolProviders: []*iam.WorkloadIdentityPoolProvider{
+							{Name: poolResource + "/providers/denied"},
+							{Name: poolResource + "/providers/" + testName},
+						},
+					}, nil).Times(1)
+				mockGCPClient.EXPECT().DeleteWorkloadIdentityProvider(gomock.Any(), poolResource+"/providers/denied").Return(
+					nil, &googleapi.Error{Code: 403, Message: "Permission denied"}).Times(1)
+				mockGCPClient.EXPECT().DeleteWorkloadIdentityProvider(gomock.Any(), poolResource+"/providers/"+testName).Return(&iam.Operation{}, nil).Times(1)
+				return mockGCPClient
+			},
+			expectError: true,
+		},
+		{
+			name: "Failure to list the providers",
+			mockGCPClient: func(mockCtrl *gomock.Controller) *mockgcp.MockClient {
+				mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+				mockGetProjectName(mockGCPClient, 1)
+				mockGCPClient.EXPECT().ListWorkloadIdentityProviders(gomock.Any(), poolResource, "").Return(
+					nil, &googleapi.Error{Code: 403, Message: "Permission denied"}).Times(1)
+				return mockGCPClient
+			},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			err := deleteWorkloadIdentityProviders(context.TODO(), test.mockGCPClient(mockCtrl), testName)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func mockGetWorkloadIdentityProviderWithIssuer(mockGCPClient *mockgcp.MockClient, issuerURL string) {
+	mockGCPClient.EXPECT().GetWorkloadIdentityProvider(gomock.Any(), gomock.Any()).Return(
+		&iam.WorkloadIdentityPoolProvider{
+			Name: testName,
+			Oidc: &iam.Oidc{
+				IssuerUri: issuerURL,
+			},
+		}, nil).Times(1)
+}
+
+func TestCheckDeletionPermissions(t *testing.T) {
+	allPermissions := []string{}
+	for _, permission := range deletionPermissions(testName) {
+		allPermissions = append(allPermissions, permission.Permission)
+	}
+
+	tests := []struct {
+		name               string
+		granted            []string
+		testErr            error
+		failOnInsufficient bool
+		expectError        string
+	}{
+		{
+			name:               "Every permission granted",
+			granted:            allPermissions,
+			failOnInsufficient: true,
+		},
+		{
+			name:    "Missing permissions reported without failing",
+			granted: []string{"storage.objects.list", "storage.objects.delete"},
+		},
+		{
+			name:               "Missing permissions fail with --fail-on-insufficient-permissions",
+			granted:            allPermissions[:len(allPermissions)-1],
+			failOnInsufficient: true,
+			expectError:        "iam.workloadIdentityPools.delete, blocking the deletion of the workload identity pool " + testName,
+		},
+		{
+			name:    "Check skipped when the permissions cannot be tested",
+			testErr: &googleapi.Error{Code: 403},
+		},
+		{
+			name:               "Untested permissions fail with --fail-on-insufficient-permissions",
+			testErr:            &googleapi.Error{Code: 403},
+			failOnInsufficient: true,
+			expectError:        "failed to check the permissions required for deletion, no resources were deleted: failed to test the permissions on project " + testProject,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+			mockGetProjectName(mockGCPClient, 1)
+			mockGCPClient.EXPECT().TestIamPermissions(testProject, &cloudresourcemanager.TestIamPermissionsRequest{Permissions: allPermissions}).DoAndReturn(
+				func(projectName string, request *cloudresourcemanager.TestIamPermissionsRequest) (*cloudresourcemanager.TestIamPermissionsResponse, error) {
+					if test.testErr != nil {
+						return nil, test.testErr
+					}
+					return &cloudresourcemanager.TestIamPermissionsResponse{Permissions: test.granted}, nil
+				})
+
+			err := checkDeletionPermissions(mockGCPClient, testName, test.failOnInsufficient)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+		})
+	}
+}
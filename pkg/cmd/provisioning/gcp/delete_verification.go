@@ -0,0 +1,88 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/api/googleapi"
+	iamadminpb "google.golang.org/genproto/googleapis/iam/admin/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	"github.com/openshift/cloud-credential-operator/pkg/gcp"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/utils"
+)
+
+// listRemainingResources returns the resources which ccoctl gcp delete would delete for namePrefix and the
+// CredentialsRequests within credReqDirs within the project of the client. Custom roles and workload identity pools
+// which were deleted remain listed by google cloud until they are purged, they are not reported.
+func listRemainingResources(ctx context.Context, client gcp.Client, namePrefix string, credReqDirs []string) ([]string, error) {
+	projectResourceName := fmt.Sprintf("projects/%s", client.GetProjectName())
+	remaining := []string{}
+
+	bucketName := fmt.Sprintf("%s-oidc", namePrefix)
+	if _, err := client.GetBucketAttrs(ctx, bucketName); err == nil {
+		remaining = append(remaining, "OIDC bucket "+bucketName)
+	} else if !strings.Contains(err.Error(), "bucket doesn't exist") {
+		return nil, errors.Wrapf(err, "Failed to get the OIDC bucket %s", bucketName)
+	}
+
+	// Custom roles and service accounts are named after the CredentialsRequests, as when they are deleted
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to process files containing CredentialsRequests")
+	}
+	names := sets.NewString()
+	for _, cr := range credReqs {
+		name, err := utils.GenerateNameWithFieldLimits(namePrefix, 50, cr.Name, 49)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to generate names from credentials request %s", cr.Name)
+		}
+		names.Insert(name)
+	}
+
+	pageToken := ""
+	for {
+		listRolesResponse, err := client.ListRoles(ctx, &iamadminpb.ListRolesRequest{
+			Parent:    projectResourceName,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to fetch list of IAM roles")
+		}
+		for _, role := range listRolesResponse.Roles {
+			if names.Has(role.Title) && !role.Deleted {
+				remaining = append(remaining, "IAM custom role "+role.Title)
+			}
+		}
+		if listRolesResponse.NextPageToken == "" {
+			break
+		}
+		pageToken = listRolesResponse.NextPageToken
+	}
+
+	svcAcctList, err := client.ListServiceAccounts(ctx, &iamadminpb.ListServiceAccountsRequest{
+		Name: projectResourceName,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to fetch list of service accounts")
+	}
+	for _, svcAcct := range svcAcctList {
+		if names.Has(svcAcct.DisplayName) {
+			remaining = append(remaining, "IAM service account "+svcAcct.DisplayName)
+		}
+	}
+
+	poolResource := fmt.Sprintf("%s/locations/global/workloadIdentityPools/%s", projectResourceName, namePrefix)
+	pool, err := client.GetWorkloadIdentityPool(ctx, poolResource)
+	if err != nil {
+		if gerr, ok := err.(*googleapi.Error); !ok || gerr.Code != 404 {
+			return nil, errors.Wrapf(err, "Failed to get workload identity pool %s", namePrefix)
+		}
+	} else if pool.State != "DELETED" {
+		remaining = append(remaining, "Workload identity pool "+namePrefix)
+	}
+	return remaining, nil
+}
@@ -1,22 +1,74 @@
 package gcp
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/oauth2/google"
+	cloudresourcemanager "google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
 
 	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	"github.com/openshift/cloud-credential-operator/pkg/gcp"
+)
+
+var (
+	// projectIDRegexp matches a Google cloud project ID, optionally scoped to a domain
+	projectIDRegexp = regexp.MustCompile(`^([a-z0-9.-]+:)?[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	// projectNumberRegexp matches a Google cloud project number
+	projectNumberRegexp = regexp.MustCompile(`^[0-9]+$`)
+	// roleRegexp matches the name of a predefined role, or of a custom role defined within a project or organization
+	roleRegexp = regexp.MustCompile(`^(roles/[a-zA-Z0-9_.]+|projects/([^/]+)/roles/[a-zA-Z0-9_.]+|organizations/[0-9]+/roles/[a-zA-Z0-9_.]+)$`)
+	// permissionRegexp matches an IAM permission of the form <service>.<resource>.<verb>
+	permissionRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+(\.[a-zA-Z0-9_-]+){2,}$`)
+	// defaultRetryPolicy is the retry policy of GCP API calls unless overridden with --max-retries,
+	// --retry-base-delay and --retry-max-delay. The IAM API limits the requests of a project per minute, which
+	// Google recommends retrying with a truncated exponential backoff starting at one second and capped at 32 seconds.
+	defaultRetryPolicy = provisioning.RetryPolicy{
+		MaxRetries: 6,
+		BaseDelay:  time.Second,
+		MaxDelay:   32 * time.Second,
+	}
 )
 
 type options struct {
-	TargetDir                string
-	PublicKeyPath            string
-	Region                   string
-	Name                     string
-	Project                  string
-	WorkloadIdentityPool     string
-	WorkloadIdentityProvider string
-	CredRequestDir           string
-	DryRun                   bool
-	EnableTechPreview        bool
+	TargetDir                      string
+	PublicKeyPath                  string
+	PreviousPublicKeyPath          string
+	Region                         string
+	Name                           string
+	Project                        string
+	WorkloadIdentityPool           string
+	WorkloadIdentityProvider       string
+	CredRequestDirs                []string
+	CredentialsFile                string
+	ImpersonateServiceAccount      string
+	QuotaProject                   string
+	DryRun                         bool
+	EnableTechPreview              bool
+	AllowInsecureSecretsDir        bool
+	SkipQuotaCheck                 bool
+	SkipDeprecatedPermissionsCheck bool
+	SkipCostEstimate               bool
+	ServiceAccountsQuota           int
+	OutputArchive                  string
+	VerifyIssuerReachable          bool
+	DiscoveryDocument              provisioning.DiscoveryDocumentExtensions
+	IssuerURL                      string
+	Projects                       []string
+	ContinueOnError                bool
+	FailOnInsufficientPermissions  bool
+	NameFromKubeconfig             bool
+	ConfirmNameFromKubeconfig      bool
+	VerifyAfterDelete              bool
 }
 
 // NewGCPCmd implements the "gcp" subcommand for the credentials provisioning
@@ -33,6 +85,126 @@ func NewGCPCmd() *cobra.Command {
 	gcpCmd.AddCommand(NewCreateServiceAccountsCmd())
 	gcpCmd.AddCommand(NewCreateAllCmd())
 	gcpCmd.AddCommand(NewDeleteCmd())
+	gcpCmd.AddCommand(NewRotateSigningKeyCmd())
+	gcpCmd.AddCommand(NewFinalizeRotationCmd())
+	gcpCmd.AddCommand(NewPrintRequiredPermissionsCmd())
 
 	return gcpCmd
 }
+
+// projectIdentifiers identifies a Google cloud project by both its ID, which is expected by most
+// APIs, and its number, which is expected within workload identity pool principals
+type projectIdentifiers struct {
+	ID     string
+	Number int64
+}
+
+// resolveProject validates that project is either the ID or the number of a Google cloud project which
+// can be retrieved with the client's credentials, and returns both the project ID and number
+func resolveProject(ctx context.Context, client gcp.Client, project string) (*projectIdentifiers, error) {
+	if !projectIDRegexp.MatchString(project) && !projectNumberRegexp.MatchString(project) {
+		return nil, fmt.Errorf("%q is neither a valid project ID nor a valid project number", project)
+	}
+
+	resolved, err := client.GetProject(ctx, project)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve project %s with the current credentials", project)
+	}
+	if resolved.ProjectId == "" || resolved.ProjectNumber == 0 {
+		return nil, fmt.Errorf("failed to resolve both the ID and number of project %s", project)
+	}
+	if projectNumberRegexp.MatchString(project) && project != strconv.FormatInt(resolved.ProjectNumber, 10) ||
+		!projectNumberRegexp.MatchString(project) && project != resolved.ProjectId {
+		return nil, fmt.Errorf("project %s resolved to unexpected project %s (%d)", project, resolved.ProjectId, resolved.ProjectNumber)
+	}
+
+	return &projectIdentifiers{
+		ID:     resolved.ProjectId,
+		Number: resolved.ProjectNumber,
+	}, nil
+}
+
+// clientMiddleware returns the middleware of the GCP clients recording a span for every API request when tracing is
+// enabled and writing it to the file of --dump-cloud-requests, which is empty when neither is enabled
+func clientMiddleware() gcp.Middleware {
+	dumpRequests := provisioning.CloudRequestDumpEnabled()
+	if !dumpRequests && !provisioning.TracingEnabled() {
+		return gcp.Middleware{}
+	}
+	return gcp.Middleware{
+		Transport: func(base http.RoundTripper) http.RoundTripper {
+			return provisioning.NewTracingTransport(provisioning.NewCloudRequestDumpTransport("gcp", base))
+		},
+		UnaryInterceptor: func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			ctx, span := provisioning.StartSpan(ctx, "gRPC "+method, provisioning.ProviderAttribute.String("gcp"))
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if dumpRequests {
+				provisioning.DumpRPC("gcp", method, req, reply, err, time.Since(start))
+			}
+			provisioning.EndSpan(span, err)
+			return err
+		},
+	}
+}
+
+// quotaProjectPermission is the permission required on the quota project to attribute the API calls to it
+const quotaProjectPermission = "serviceusage.services.use"
+
+// newClientForProject resolves the project identified by either its ID or number and returns a
+// client for the project ID along with both forms of the project identifier. The client identifies ccoctl in the
+// User-Agent of every API call and retries the API calls which failed transiently following the effective retry policy.
+// When quotaProject is set the quota and billing of every API call are attributed to it, once verified that the
+// credentials may use it.
+func newClientForProject(ctx context.Context, project string, creds *google.Credentials, quotaProject string) (gcp.Client, *projectIdentifiers, error) {
+	retryPolicy := gcp.RetryPolicy(provisioning.EffectiveRetryPolicy(defaultRetryPolicy))
+	opts := []option.ClientOption{option.WithUserAgent(provisioning.UserAgent())}
+	if quotaProject != "" {
+		opts = append(opts, option.WithQuotaProject(quotaProject))
+	}
+	client, err := gcp.NewClientWithMiddleware(project, creds, retryPolicy, clientMiddleware(), opts...)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to initiate GCP client")
+	}
+
+	if quotaProject != "" {
+		if err := validateQuotaProject(client, quotaProject); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resolved, err := resolveProject(ctx, client, project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resolved.ID != project {
+		log.Printf("Resolved project number %s to project ID %s", project, resolved.ID)
+		client, err = gcp.NewClientWithMiddleware(resolved.ID, creds, retryPolicy, clientMiddleware(), opts...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to initiate GCP client")
+		}
+	}
+
+	return client, resolved, nil
+}
+
+// validateQuotaProject verifies that the credentials of client, whose API calls are attributed to quotaProject, are
+// granted the permission to use quotaProject for quota and billing. Otherwise every API call would fail, possibly after
+// some resources were created.
+func validateQuotaProject(client gcp.Client, quotaProject string) error {
+	if !projectIDRegexp.MatchString(quotaProject) && !projectNumberRegexp.MatchString(quotaProject) {
+		return fmt.Errorf("invalid --quota-project %q, expected a project ID or number", quotaProject)
+	}
+	response, err := client.TestIamPermissions(quotaProject, &cloudresourcemanager.TestIamPermissionsRequest{Permissions: []string{quotaProjectPermission}})
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify that the credentials may use quota project %s", quotaProject)
+	}
+	for _, permission := range response.Permissions {
+		if permission == quotaProjectPermission {
+			return nil
+		}
+	}
+	return fmt.Errorf("the credentials are not granted the %s permission on quota project %s, which is required to attribute the quota and billing of API calls to it, eg. with the Service Usage Consumer role (roles/serviceusage.serviceUsageConsumer)",
+		quotaProjectPermission, quotaProject)
+}
@@ -0,0 +1,75 @@
+package gcp
+
+import (
+	"github.com/spf13/cobra"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+// requiredOperations are the operations of the GCP client performed by ccoctl with the IAM permissions they require.
+// Getting the name of the project of the client and testing the permissions of the caller require no permission. The
+// operations are verified against the calls of the GCP client by the tests, the list must be updated along with the
+// code.
+var requiredOperations = []provisioning.ClientOperation{
+	// Resource manager
+	{Operation: "GetProject", Permissions: []string{"resourcemanager.projects.get"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetProjectIamPolicy", Permissions: []string{"resourcemanager.projects.getIamPolicy"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetProjectName", Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "SetProjectIamPolicy", Permissions: []string{"resourcemanager.projects.setIamPolicy"}, Flows: provisioning.CreateAndDeleteFlows},
+	// Permissions are tested on the project to delete from, and on the project of --quota-project
+	{Operation: "TestIamPermissions", Flows: provisioning.CreateAndDeleteFlows},
+
+	// IAM
+	{Operation: "CreateRole", Permissions: []string{"iam.roles.create"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreateServiceAccount", Permissions: []string{"iam.serviceAccounts.create"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreateWorkloadIdentityPool", Permissions: []string{"iam.workloadIdentityPools.create"}, Flows: provisioning.CreateFlows},
+	{Operation: "CreateWorkloadIdentityProvider", Permissions: []string{"iam.workloadIdentityPoolProviders.create"}, Flows: provisioning.CreateFlows},
+	{Operation: "DeleteRole", Permissions: []string{"iam.roles.delete"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteServiceAccount", Permissions: []string{"iam.serviceAccounts.delete"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteWorkloadIdentityPool", Permissions: []string{"iam.workloadIdentityPools.delete"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteWorkloadIdentityProvider", Permissions: []string{"iam.workloadIdentityPoolProviders.delete"}, Flows: provisioning.DeleteFlows},
+	{Operation: "GetServiceAccountIamPolicy", Permissions: []string{"iam.serviceAccounts.getIamPolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "GetWorkloadIdentityPool", Permissions: []string{"iam.workloadIdentityPools.get"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetWorkloadIdentityProvider", Permissions: []string{"iam.workloadIdentityPoolProviders.get"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "ListRoles", Permissions: []string{"iam.roles.list"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "ListServiceAccounts", Permissions: []string{"iam.serviceAccounts.list"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "ListWorkloadIdentityProviders", Permissions: []string{"iam.workloadIdentityPoolProviders.list"}, Flows: provisioning.DeleteFlows},
+	{Operation: "SetServiceAccountIamPolicy", Permissions: []string{"iam.serviceAccounts.setIamPolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "UndeleteWorkloadIdentityPool", Permissions: []string{"iam.workloadIdentityPools.undelete"}, Flows: provisioning.CreateFlows},
+	{Operation: "UpdateRole", Permissions: []string{"iam.roles.update"}, Flows: provisioning.CreateFlows},
+
+	// Storage
+	{Operation: "CreateBucket", Permissions: []string{"storage.buckets.create"}, Flows: provisioning.CreateFlows},
+	{Operation: "DeleteBucket", Permissions: []string{"storage.buckets.delete"}, Flows: provisioning.DeleteFlows},
+	{Operation: "DeleteObject", Permissions: []string{"storage.objects.delete"}, Flows: provisioning.DeleteFlows},
+	{Operation: "GetBucketAttrs", Permissions: []string{"storage.buckets.get"}, Flows: provisioning.CreateAndDeleteFlows},
+	{Operation: "GetBucketPolicy", Permissions: []string{"storage.buckets.getIamPolicy"}, Flows: provisioning.CreateFlows},
+	{Operation: "ListObjects", Permissions: []string{"storage.objects.list"}, Flows: provisioning.DeleteFlows},
+	// Overwriting an object, eg. the JWKS when rotating the signing key, requires deleting it
+	{Operation: "PutObject", Permissions: []string{"storage.objects.create", "storage.objects.delete"}, Flows: provisioning.CreateFlows},
+	{Operation: "SetBucketPolicy", Permissions: []string{"storage.buckets.setIamPolicy"}, Flows: provisioning.CreateFlows},
+}
+
+// requiredPermissionsRole is the definition of a custom IAM role, as expected by "gcloud iam roles create --file"
+type requiredPermissionsRole struct {
+	Title               string   `json:"title"`
+	Description         string   `json:"description"`
+	Stage               string   `json:"stage"`
+	IncludedPermissions []string `json:"includedPermissions"`
+}
+
+// requiredPermissionsRoleDefinition returns the definition of a custom IAM role including permissions
+func requiredPermissionsRoleDefinition(permissions []string) ([]byte, error) {
+	return sigsyaml.Marshal(requiredPermissionsRole{
+		Title:               "ccoctl",
+		Description:         "Permissions required by ccoctl to create and delete the resources of OpenShift clusters",
+		Stage:               "GA",
+		IncludedPermissions: permissions,
+	})
+}
+
+// NewPrintRequiredPermissionsCmd provides the "print-required-permissions" subcommand
+func NewPrintRequiredPermissionsCmd() *cobra.Command {
+	return provisioning.NewPrintRequiredPermissionsCmd("GCP", "the definition of a custom IAM role", requiredOperations, requiredPermissionsRoleDefinition)
+}
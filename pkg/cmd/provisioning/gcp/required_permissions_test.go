@@ -0,0 +1,49 @@
+package gcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	"github.com/openshift/cloud-credential-operator/pkg/gcp"
+)
+
+func TestRequiredOperations(t *testing.T) {
+	// Every operation of the GCP client called by ccoctl, including through the actuator, is listed with the flows
+	// calling it, and nothing else
+	clientType := reflect.TypeOf((*gcp.Client)(nil)).Elem()
+	called, err := provisioning.ClientOperationCalls(".", map[string]string{"actuator": "../../../gcp/actuator"}, func(receiver, method string) string {
+		if _, found := clientType.MethodByName(method); found {
+			return method
+		}
+		return ""
+	}, "delete.go")
+	require.NoError(t, err, "unexpected error")
+
+	listed := map[string][]string{}
+	for _, operation := range requiredOperations {
+		assert.NotContains(t, listed, operation.Operation, "duplicate operation %s", operation.Operation)
+		listed[operation.Operation] = operation.Flows
+	}
+	assert.Equal(t, called, listed, "the required operations do not match the operations of the GCP client called by ccoctl")
+}
+
+func TestRequiredPermissionsRoleDefinition(t *testing.T) {
+	data, err := requiredPermissionsRoleDefinition(provisioning.RequiredPermissions(requiredOperations, provisioning.FlowDelete))
+	require.NoError(t, err, "unexpected error")
+
+	role := requiredPermissionsRole{}
+	require.NoError(t, sigsyaml.Unmarshal(data, &role), "unexpected error decoding role")
+	assert.Equal(t, "GA", role.Stage, "unexpected stage")
+	assert.Contains(t, role.IncludedPermissions, "iam.serviceAccounts.delete", "expected permission to delete service accounts")
+	assert.NotContains(t, role.IncludedPermissions, "iam.serviceAccounts.create", "unexpected permission of the create flow")
+
+	// Every permission checked before deletion is required by the delete flow
+	for _, permission := range deletionPermissions("test") {
+		assert.Contains(t, role.IncludedPermissions, permission.Permission, "expected permission checked before deletion to be required")
+	}
+}
@@ -0,0 +1,141 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	"github.com/openshift/cloud-credential-operator/pkg/gcp"
+)
+
+var (
+	// RotateSigningKeyOpts captures the options that affect the rotation of the service account signing key
+	RotateSigningKeyOpts = options{}
+	// FinalizeRotationOpts captures the options that affect the removal of the previous service account signing key
+	FinalizeRotationOpts = options{}
+)
+
+// rotateSigningKey generates a new signing key pair within the output directory and publishes the JSON web key set
+// containing both the previous and the new public keys to the OIDC storage bucket created for name
+func rotateSigningKey(ctx context.Context, client gcp.Client, name, previousPublicKeyPath, targetDir string, generateOnly bool) error {
+	jwks, err := provisioning.RotateSigningKey(targetDir, previousPublicKeyPath)
+	if err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(ctx, client, jwks, fmt.Sprintf("%s-oidc", name), targetDir, generateOnly)
+}
+
+// finalizeRotation publishes the JSON web key set containing only the public key at publicKeyPath to the OIDC storage
+// bucket created for name, removing the previous public key published by rotateSigningKey
+func finalizeRotation(ctx context.Context, client gcp.Client, name, publicKeyPath, targetDir string, generateOnly bool) error {
+	jwks, err := provisioning.FinalizeRotation(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	return publishJSONWebKeySet(ctx, client, jwks, fmt.Sprintf("%s-oidc", name), targetDir, generateOnly)
+}
+
+// newRotationClient returns a client for the project within opts, the project is replaced by its resolved ID
+func newRotationClient(ctx context.Context, opts *options) gcp.Client {
+	creds, err := loadCredentials(ctx, opts.CredentialsFile, opts.ImpersonateServiceAccount)
+	if err != nil {
+		log.Fatalf("Failed to load credentials: %s", err)
+	}
+
+	gcpClient, project, err := newClientForProject(ctx, opts.Project, creds, opts.QuotaProject)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.Project = project.ID
+	return gcpClient
+}
+
+func rotateSigningKeyCmd(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	gcpClient := newRotationClient(ctx, &RotateSigningKeyOpts)
+
+	err := rotateSigningKey(ctx, gcpClient, RotateSigningKeyOpts.Name, RotateSigningKeyOpts.PreviousPublicKeyPath, RotateSigningKeyOpts.TargetDir, RotateSigningKeyOpts.DryRun)
+	if err != nil {
+		log.Fatalf("Failed to rotate the signing key: %s", err)
+	}
+}
+
+func finalizeRotationCmd(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	gcpClient := newRotationClient(ctx, &FinalizeRotationOpts)
+
+	publicKeyPath := FinalizeRotationOpts.PublicKeyPath
+	if publicKeyPath == "" {
+		publicKeyPath = filepath.Join(FinalizeRotationOpts.TargetDir, provisioning.PublicKeyFile)
+	}
+
+	err := finalizeRotation(ctx, gcpClient, FinalizeRotationOpts.Name, publicKeyPath, FinalizeRotationOpts.TargetDir, FinalizeRotationOpts.DryRun)
+	if err != nil {
+		log.Fatalf("Failed to finalize the signing key rotation: %s", err)
+	}
+}
+
+// initEnvForRotationCmd ensures the output directory is ready to receive the generated files
+func initEnvForRotationCmd(opts *options) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		targetDir, err := provisioning.InitRotationOutputDir(opts.TargetDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.TargetDir = targetDir
+	}
+}
+
+// addRotationFlags adds the flags shared by the signing key rotation subcommands to cmd
+func addRotationFlags(cmd *cobra.Command, opts *options) {
+	cmd.PersistentFlags().StringVar(&opts.Name, "name", "", "User-defined name for all created Google cloud resources (can be separate from the cluster's infra-id)")
+	cmd.MarkPersistentFlagRequired("name")
+	cmd.PersistentFlags().StringVar(&opts.Project, "project", "", "ID or number of the Google cloud project")
+	cmd.MarkPersistentFlagRequired("project")
+	cmd.PersistentFlags().BoolVar(&opts.DryRun, "dry-run", false, "Skip uploading the JSON web key set, and just save it into a file")
+	cmd.PersistentFlags().StringVar(&opts.CredentialsFile, "credentials-file", "", "File containing the service account key used to authenticate to Google cloud. Use \"-\" to read the key from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, credentials are loaded from the default locations")
+	cmd.PersistentFlags().StringVar(&opts.ImpersonateServiceAccount, "impersonate-service-account", "", "Email of a service account to impersonate, every operation is performed as the service account. The loaded credentials must be granted the Service Account Token Creator role (roles/iam.serviceAccountTokenCreator) on the service account")
+	cmd.PersistentFlags().StringVar(&opts.QuotaProject, "quota-project", "", "ID or number of the project to which the quota and billing of GCP API calls are attributed, sent as the x-goog-user-project header, eg. when the credentials belong to another project than --project. The credentials must be granted the serviceusage.services.use permission on the project. Defaults to the project inferred from the credentials when not specified")
+}
+
+// NewRotateSigningKeyCmd provides the "rotate-signing-key" subcommand
+func NewRotateSigningKeyCmd() *cobra.Command {
+	rotateSigningKeyCmd := &cobra.Command{
+		Use:   "rotate-signing-key",
+		Short: "Publish a new service account signing key alongside the previous key",
+		Long: "Generate a new service account signing key pair within the output directory and publish a JSON web key set containing both the previous and the new public keys, " +
+			"so that tokens signed with either key are trusted while the cluster cuts over to the new key. Apply the next signing key secret manifest written to the manifests directory " +
+			"to the cluster, then run finalize-rotation once the cluster has cut over to remove the previous key",
+		Run:              rotateSigningKeyCmd,
+		PersistentPreRun: initEnvForRotationCmd(&RotateSigningKeyOpts),
+	}
+
+	addRotationFlags(rotateSigningKeyCmd, &RotateSigningKeyOpts)
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.PreviousPublicKeyPath, "previous-public-key-file", "", "Path to the public ServiceAccount signing key currently used by the cluster")
+	rotateSigningKeyCmd.MarkPersistentFlagRequired("previous-public-key-file")
+	rotateSigningKeyCmd.PersistentFlags().StringVar(&RotateSigningKeyOpts.TargetDir, "output-dir", "", "Directory to place the new key pair and generated files (defaults to current directory), must not contain the previous key pair")
+
+	return rotateSigningKeyCmd
+}
+
+// NewFinalizeRotationCmd provides the "finalize-rotation" subcommand
+func NewFinalizeRotationCmd() *cobra.Command {
+	finalizeRotationCmd := &cobra.Command{
+		Use:   "finalize-rotation",
+		Short: "Remove the previous service account signing key once the cluster has cut over to the new key",
+		Long: "Publish a JSON web key set containing only the new public key generated by rotate-signing-key, so that tokens signed with the previous key are no longer trusted. " +
+			"Run it once the cluster has cut over to the new key and tokens signed with the previous key have expired",
+		Run:              finalizeRotationCmd,
+		PersistentPreRun: initEnvForRotationCmd(&FinalizeRotationOpts),
+	}
+
+	addRotationFlags(finalizeRotationCmd, &FinalizeRotationOpts)
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.PublicKeyPath, "public-key-file", "", "Path to the new public ServiceAccount signing key (defaults to the public key within --output-dir)")
+	finalizeRotationCmd.PersistentFlags().StringVar(&FinalizeRotationOpts.TargetDir, "output-dir", "", "Directory containing the new key pair generated by rotate-signing-key (defaults to current directory)")
+
+	return finalizeRotationCmd
+}
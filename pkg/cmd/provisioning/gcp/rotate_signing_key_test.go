@@ -0,0 +1,49 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	mockgcp "github.com/openshift/cloud-credential-operator/pkg/gcp/mock"
+)
+
+func TestRotateSigningKey(t *testing.T) {
+	previousDir, err := provisioning.InitRotationOutputDir(filepath.Join(t.TempDir(), "previous"))
+	require.NoError(t, err, "failed to prepare previous output directory")
+	require.NoError(t, provisioning.CreateKeys(previousDir), "failed to create previous key pair")
+	targetDir, err := provisioning.InitRotationOutputDir(filepath.Join(t.TempDir(), "rotation"))
+	require.NoError(t, err, "failed to prepare output directory")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockGCPClient := mockgcp.NewMockClient(mockCtrl)
+
+	// Capture the JSON web key sets uploaded to the OIDC bucket
+	uploadedKeySets := []provisioning.JSONWebKeySet{}
+	mockGCPClient.EXPECT().PutObject(gomock.Any(), testInfraName+"-oidc", provisioning.KeysURI, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, bucketName, objectName string, data []byte) error {
+			keySet := provisioning.JSONWebKeySet{}
+			require.NoError(t, json.Unmarshal(data, &keySet), "failed to decode uploaded JSON web key set")
+			uploadedKeySets = append(uploadedKeySets, keySet)
+			return nil
+		}).Times(2)
+
+	err = rotateSigningKey(context.TODO(), mockGCPClient, testInfraName, filepath.Join(previousDir, provisioning.PublicKeyFile), targetDir, false)
+	require.NoError(t, err, "unexpected error rotating signing key")
+	assert.FileExists(t, filepath.Join(targetDir, provisioning.ManifestsDirName, provisioning.NextSigningKeySecretFile), "expected next signing key secret manifest")
+
+	err = finalizeRotation(context.TODO(), mockGCPClient, testInfraName, filepath.Join(targetDir, provisioning.PublicKeyFile), targetDir, false)
+	require.NoError(t, err, "unexpected error finalizing rotation")
+
+	require.Len(t, uploadedKeySets, 2, "expected JSON web key sets to be uploaded by rotation and finalization")
+	require.Len(t, uploadedKeySets[0].Keys, 2, "expected rotation to publish the previous and the new key")
+	require.Len(t, uploadedKeySets[1].Keys, 1, "expected finalization to publish only the new key")
+	assert.Equal(t, uploadedKeySets[0].Keys[1].KeyID, uploadedKeySets[1].Keys[0].KeyID, "expected finalization to keep the new key")
+}
@@ -47,11 +47,12 @@ func NewCreateServiceIDCmd() *cobra.Command {
 
 	createServiceIDCmd.PersistentFlags().StringVar(&Options.Name, "name", "", "User-defined name for all created IBM Cloud resources (can be separate from the cluster's infra-id)")
 	createServiceIDCmd.MarkPersistentFlagRequired("name")
-	createServiceIDCmd.PersistentFlags().StringVar(&Options.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to create IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=ibmcloud' against an OpenShift release image)")
+	createServiceIDCmd.PersistentFlags().StringSliceVar(&Options.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to create IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=ibmcloud' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	createServiceIDCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	createServiceIDCmd.PersistentFlags().StringVar(&Options.ResourceGroupName, "resource-group-name", "", "Name of the resource group used for scoping the access policies")
 	createServiceIDCmd.PersistentFlags().StringVar(&Options.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
 	createServiceIDCmd.PersistentFlags().BoolVar(&Options.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	provisioning.AddAllowInsecureSecretsDirFlag(createServiceIDCmd.PersistentFlags(), &Options.AllowInsecureSecretsDir)
 
 	return createServiceIDCmd
 }
@@ -79,7 +80,7 @@ func createServiceIDCmd(cmd *cobra.Command, args []string) error {
 	}
 
 	err = createServiceIDs(ibmclient, apiKeyDetails.AccountID, Options.Name, Options.ResourceGroupName,
-		Options.CredRequestDir, Options.TargetDir, Options.EnableTechPreview)
+		Options.CredRequestDirs, Options.TargetDir, Options.EnableTechPreview)
 	if err != nil {
 		return err
 	}
@@ -88,7 +89,7 @@ func createServiceIDCmd(cmd *cobra.Command, args []string) error {
 }
 
 func createServiceIDs(client ibmcloud.Client, accountID *string,
-	name, resourceGroupName, credReqDir, targetDir string, enableTechPreview bool) error {
+	name, resourceGroupName string, credReqDirs []string, targetDir string, enableTechPreview bool) error {
 
 	resourceGroupID, err := getResourceGroupID(client, accountID, resourceGroupName)
 	if err != nil {
@@ -96,7 +97,7 @@ func createServiceIDs(client ibmcloud.Client, accountID *string,
 	}
 
 	// Process directory
-	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDir, enableTechPreview)
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
@@ -166,4 +167,6 @@ func initEnvForCreateServiceIDCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("failed to create manifests directory at %s", manifestsDir)
 	}
+
+	provisioning.EnsureSecretsDir(manifestsDir, Options.AllowInsecureSecretsDir)
 }
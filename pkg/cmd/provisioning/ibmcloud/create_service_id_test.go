@@ -85,7 +85,7 @@ func TestCreateSecretsCmd(t *testing.T) {
 				fmt.Sprintf("--output-dir=%s", targetDir),
 				fmt.Sprintf("--name=%s", "ibmcloud-cluster"),
 			}
-			Options.CredRequestDir = credReqDir
+			Options.CredRequestDirs = []string{credReqDir}
 			Options.TargetDir = targetDir
 			err = createServiceIDCmd(&cobra.Command{}, args)
 
@@ -424,7 +424,7 @@ func TestCreateSharedSecrets(t *testing.T) {
 			require.NoError(t, err, "unexpected error creating manifests dir for test")
 			defer os.RemoveAll(manifestsDir)
 
-			if err := createServiceIDs(mockIBMCloudClient, core.StringPtr("1234"), "name", tt.resourceGroupName, credReqDir, targetDir, false); (err != nil) != tt.wantErr {
+			if err := createServiceIDs(mockIBMCloudClient, core.StringPtr("1234"), "name", tt.resourceGroupName, []string{credReqDir}, targetDir, false); (err != nil) != tt.wantErr {
 				t.Errorf("createServiceIDs() error = %v, wantErr %v", err, tt.wantErr)
 			}
 			tt.verify(t, targetDir, manifestsDir)
@@ -477,7 +477,7 @@ func TestCreateSharedSecretsInvalidTargetDir(t *testing.T) {
 
 			targetDir := "doesnotexist"
 
-			if err := createServiceIDs(mockIBMCloudClient, core.StringPtr("1234"), "name1", tt.resourceGroupName, credReqDir, targetDir, false); (err != nil) != tt.wantErr {
+			if err := createServiceIDs(mockIBMCloudClient, core.StringPtr("1234"), "name1", tt.resourceGroupName, []string{credReqDir}, targetDir, false); (err != nil) != tt.wantErr {
 				t.Errorf("createServiceIDs() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -20,7 +20,7 @@ func NewDeleteServiceIDCmd() *cobra.Command {
 
 	deleteServiceIDCmd.PersistentFlags().StringVar(&Options.Name, "name", "", "User-defined name for all created IBM Cloud resources (can be separate from the cluster's infra-id)")
 	deleteServiceIDCmd.MarkPersistentFlagRequired("name")
-	deleteServiceIDCmd.PersistentFlags().StringVar(&Options.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests to delete IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=ibmcloud' against an OpenShift release image)")
+	deleteServiceIDCmd.PersistentFlags().StringSliceVar(&Options.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests to delete IAM Roles for (can be created by running 'oc adm release extract --credentials-requests --cloud=ibmcloud' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	deleteServiceIDCmd.MarkPersistentFlagRequired("credentials-requests-dir")
 	deleteServiceIDCmd.PersistentFlags().BoolVar(&Options.Force, "force", false, "delete all the service account forcefully(will delete all the entries with the name)")
 
@@ -49,7 +49,7 @@ func deleteServiceIDCmd(cmd *cobra.Command, args []string) error {
 		return errors.Wrap(err, "Failed to get Details for the given APIKey")
 	}
 
-	err = deleteServiceIDs(ibmclient, *apiKeyDetails.AccountID, Options.Name, Options.CredRequestDir, Options.Force)
+	err = deleteServiceIDs(ibmclient, *apiKeyDetails.AccountID, Options.Name, Options.CredRequestDirs, Options.Force)
 	if err != nil {
 		return err
 	}
@@ -57,10 +57,10 @@ func deleteServiceIDCmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func deleteServiceIDs(client ibmcloud.Client, accountID, name, credReqDir string, force bool) error {
+func deleteServiceIDs(client ibmcloud.Client, accountID, name string, credReqDirs []string, force bool) error {
 	// Process directory
 	// always tech-preview==true because we should do a full cleanup to be on the safe side
-	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDir, true)
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, true)
 	if err != nil {
 		return errors.Wrap(err, "Failed to process files containing CredentialsRequests")
 	}
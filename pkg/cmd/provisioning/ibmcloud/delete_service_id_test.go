@@ -191,7 +191,7 @@ func Test_deleteServiceIDs(t *testing.T) {
 			credReqDir := tt.setup(t)
 			defer os.RemoveAll(credReqDir)
 
-			err := deleteServiceIDs(mockIBMCloudClient, "1234", "name", credReqDir, tt.force)
+			err := deleteServiceIDs(mockIBMCloudClient, "1234", "name", []string{credReqDir}, tt.force)
 			if tt.expectError == "" {
 				assert.NoError(t, err)
 			} else {
@@ -5,14 +5,15 @@ import (
 )
 
 type options struct {
-	TargetDir         string
-	Name              string
-	CredRequestDir    string
-	ResourceGroupName string
-	Force             bool
-	KubeConfigFile    string
-	Create            bool
-	EnableTechPreview bool
+	TargetDir               string
+	Name                    string
+	CredRequestDirs         []string
+	ResourceGroupName       string
+	Force                   bool
+	AllowInsecureSecretsDir bool
+	KubeConfigFile          string
+	Create                  bool
+	EnableTechPreview       bool
 }
 
 // NewIBMCloudCmd implements the "ibmcloud" subcommand for the credentials provisioning
@@ -3,7 +3,6 @@ package ibmcloud
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/url"
 	"os"
@@ -22,6 +21,7 @@ import (
 	"github.com/IBM/platform-services-go-sdk/iampolicymanagementv1"
 
 	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
 	"github.com/openshift/cloud-credential-operator/pkg/ibmcloud"
 )
 
@@ -178,7 +178,7 @@ func (s *ServiceID) Dump(targetDir string) error {
 		return errors.Wrapf(err, "Failed to marshal the secret for the serviceID: %s", s.name)
 	}
 
-	if err := ioutil.WriteFile(filePath, data, 0600); err != nil {
+	if err := provisioning.WriteSecretFile(filePath, data); err != nil {
 		return errors.Wrap(err, "Failed to save Secret file")
 	}
 
@@ -314,7 +314,7 @@ func (s *ServiceID) UnDo(targetDir string) error {
 		ID: s.ID}
 	_, err := s.Client.DeleteServiceID(options)
 	if err != nil {
-		log.Printf("Failed to delete the Service ID: %s", *s.ID)
+		provisioning.Warnf("Failed to delete the Service ID: %s", *s.ID)
 	} else {
 		log.Printf("Successfully deleted the Service ID: %s", *s.ID)
 	}
@@ -324,7 +324,7 @@ func (s *ServiceID) UnDo(targetDir string) error {
 		log.Printf("Deleting the generated secret, file:%s", secretFileName)
 		err = os.Remove(secretFileName)
 		if err != nil {
-			log.Printf("failed to delete file: %s", secretFileName)
+			provisioning.Warnf("Failed to delete file: %s", secretFileName)
 		}
 	}
 
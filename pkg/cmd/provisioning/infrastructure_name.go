@@ -0,0 +1,99 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+)
+
+const (
+	// maxInfrastructureNameLength is the maximum length of the infrastructure name of a cluster, a DNS label
+	maxInfrastructureNameLength = 63
+)
+
+// infrastructureNameRegexp matches the infrastructure names generated by the installer, eg. "mycluster-x7k2p"
+var infrastructureNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// getInfrastructure returns the cluster infrastructure of the cluster of the current context of the kubeconfig. It
+// may be replaced by tests.
+var getInfrastructure = func(ctx context.Context) (*configv1.Infrastructure, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	kubeconfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	rawConfig, err := kubeconfig.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig: %s", err)
+	}
+	cfg, err := kubeconfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig: %s", err)
+	}
+	log.Printf("Reading the cluster infrastructure from the cluster of kubeconfig context %q (%s)", rawConfig.CurrentContext, cfg.Host)
+
+	client, err := configclient.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	infra, err := client.ConfigV1().Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the cluster infrastructure: %s", err)
+	}
+	return infra, nil
+}
+
+// ResolveNameFromKubeconfig sets name to the infrastructure name of the cluster of the current context of the
+// kubeconfig when fromKubeconfig, the --name-from-kubeconfig of the delete commands. The cluster must run on
+// platform, and confirmed, the --confirm-name-from-kubeconfig of the delete commands, must be set to confirm the
+// deletion of the resources of the discovered name since the kubeconfig may point at another cluster than intended.
+func ResolveNameFromKubeconfig(ctx context.Context, name *string, fromKubeconfig, confirmed bool, platform configv1.PlatformType) error {
+	if !fromKubeconfig {
+		if *name == "" {
+			return fmt.Errorf("either --name or --name-from-kubeconfig must be specified")
+		}
+		return nil
+	}
+	if *name != "" {
+		return fmt.Errorf("--name and --name-from-kubeconfig may not be specified together")
+	}
+
+	infra, err := getInfrastructure(ctx)
+	if err != nil {
+		return err
+	}
+	infraName, err := infrastructureName(infra, platform)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return fmt.Errorf("the infrastructure name of the cluster of the kubeconfig is %s, specify --confirm-name-from-kubeconfig to delete its resources or --name %s instead of --name-from-kubeconfig", infraName, infraName)
+	}
+	log.Printf("Deleting the resources of infrastructure name %s discovered from the kubeconfig", infraName)
+	*name = infraName
+	return nil
+}
+
+// infrastructureName returns the validated infrastructure name of infra, which must run on platform
+func infrastructureName(infra *configv1.Infrastructure, platform configv1.PlatformType) (string, error) {
+	infraPlatform := infra.Status.Platform
+	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.Type != "" {
+		infraPlatform = infra.Status.PlatformStatus.Type
+	}
+	if infraPlatform != platform {
+		return "", fmt.Errorf("the cluster of the kubeconfig runs on platform %q, not %s", infraPlatform, platform)
+	}
+
+	infraName := infra.Status.InfrastructureName
+	if infraName == "" {
+		return "", fmt.Errorf("the cluster infrastructure of the kubeconfig has no infrastructure name")
+	}
+	if len(infraName) > maxInfrastructureNameLength || !infrastructureNameRegexp.MatchString(infraName) {
+		return "", fmt.Errorf("invalid infrastructure name %q, infrastructure names must be DNS labels of at most %d lowercase letters, numbers or '-'", infraName, maxInfrastructureNameLength)
+	}
+	return infraName, nil
+}
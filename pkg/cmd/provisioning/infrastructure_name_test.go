@@ -0,0 +1,134 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func testInfrastructure(platform configv1.PlatformType, infraName string) *configv1.Infrastructure {
+	return &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: infraName,
+			PlatformStatus:     &configv1.PlatformStatus{Type: platform},
+		},
+	}
+}
+
+func TestResolveNameFromKubeconfig(t *testing.T) {
+	defaultGetInfrastructure := getInfrastructure
+	defer func() { getInfrastructure = defaultGetInfrastructure }()
+
+	tests := []struct {
+		name           string
+		flagName       string
+		fromKubeconfig bool
+		confirmed      bool
+		infra          *configv1.Infrastructure
+		infraErr       error
+		expectName     string
+		expectError    string
+	}{
+		{
+			name:       "Name specified",
+			flagName:   "test",
+			expectName: "test",
+		},
+		{
+			name:        "Neither name nor name from kubeconfig specified",
+			expectError: "either --name or --name-from-kubeconfig must be specified",
+		},
+		{
+			name:           "Name and name from kubeconfig specified",
+			flagName:       "test",
+			fromKubeconfig: true,
+			confirmed:      true,
+			expectError:    "may not be specified together",
+		},
+		{
+			name:           "Name from kubeconfig",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infra:          testInfrastructure(configv1.AWSPlatformType, "test-x7k2p"),
+			expectName:     "test-x7k2p",
+		},
+		{
+			name:           "Name from kubeconfig of a cluster without platform status",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infra: &configv1.Infrastructure{
+				Status: configv1.InfrastructureStatus{InfrastructureName: "test-x7k2p", Platform: configv1.AWSPlatformType},
+			},
+			expectName: "test-x7k2p",
+		},
+		{
+			name:           "Name from kubeconfig not confirmed",
+			fromKubeconfig: true,
+			infra:          testInfrastructure(configv1.AWSPlatformType, "test-x7k2p"),
+			expectError:    "the infrastructure name of the cluster of the kubeconfig is test-x7k2p, specify --confirm-name-from-kubeconfig",
+		},
+		{
+			name:           "Cluster of another platform",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infra:          testInfrastructure(configv1.GCPPlatformType, "test-x7k2p"),
+			expectError:    `runs on platform "GCP", not AWS`,
+		},
+		{
+			name:           "Empty infrastructure name",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infra:          testInfrastructure(configv1.AWSPlatformType, ""),
+			expectError:    "has no infrastructure name",
+		},
+		{
+			name:           "Invalid infrastructure name",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infra:          testInfrastructure(configv1.AWSPlatformType, "Test_x7k2p"),
+			expectError:    `invalid infrastructure name "Test_x7k2p"`,
+		},
+		{
+			name:           "Infrastructure name too long",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infra:          testInfrastructure(configv1.AWSPlatformType, strings.Repeat("a", maxInfrastructureNameLength+1)),
+			expectError:    "invalid infrastructure name",
+		},
+		{
+			name:           "Failure getting the cluster infrastructure",
+			fromKubeconfig: true,
+			confirmed:      true,
+			infraErr:       errors.New("failed to get the cluster infrastructure: forbidden"),
+			expectError:    "forbidden",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			getInfrastructure = func(ctx context.Context) (*configv1.Infrastructure, error) {
+				if test.infraErr != nil {
+					return nil, test.infraErr
+				}
+				require.NotNil(t, test.infra, "unexpected request of the cluster infrastructure")
+				return test.infra, nil
+			}
+
+			name := test.flagName
+			err := ResolveNameFromKubeconfig(context.Background(), &name, test.fromKubeconfig, test.confirmed, configv1.AWSPlatformType)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectName, name, "unexpected name")
+		})
+	}
+}
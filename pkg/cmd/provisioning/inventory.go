@@ -0,0 +1,181 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// InventoryFileName is the name of the file within the output directory in which ccoctl records
+// the create steps it has completed and the cloud resources created by each step
+const InventoryFileName = "ccoctl-inventory.json"
+
+// Inventory is a record of the create steps completed by ccoctl. The inventory is persisted to the
+// output directory after each completed step so that an interrupted create may be resumed. The steps and resources
+// are persisted in a stable order, regardless of the order in which they were completed, so that repeated runs with
+// the same inputs persist identical inventories.
+type Inventory struct {
+	// path is the file to which the inventory is persisted
+	path string
+
+	// Provider is the cloud provider for which resources were created, eg. "azure"
+	Provider string `json:"provider"`
+	// Name is the user-defined name provided to ccoctl for all created resources
+	Name string `json:"name"`
+	// Settings are provider specific settings with which the resources were created
+	Settings map[string]string `json:"settings,omitempty"`
+	// Steps are the completed create steps in the order that they were completed, they are persisted ordered by name
+	Steps []InventoryStep `json:"steps"`
+	// Intended are the resources which create recorded before creating them, so that a resource created by a step which
+	// was interrupted before completing may still be found, eg. by ccoctl aws reconcile-tags
+	Intended []InventoryResource `json:"intended,omitempty"`
+}
+
+// InventoryStep is a completed create step and the cloud resources it created or validated
+type InventoryStep struct {
+	Name      string              `json:"name"`
+	Resources []InventoryResource `json:"resources,omitempty"`
+}
+
+// InventoryResource identifies a cloud resource recorded within an Inventory
+type InventoryResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
+}
+
+// NewInventory returns an empty Inventory which will be persisted within dir
+func NewInventory(dir, provider, name string) *Inventory {
+	return &Inventory{
+		path:     filepath.Join(dir, InventoryFileName),
+		Provider: provider,
+		Name:     name,
+		Settings: map[string]string{},
+		Steps:    []InventoryStep{},
+	}
+}
+
+// LoadInventory reads the Inventory previously persisted within dir
+func LoadInventory(dir string) (*Inventory, error) {
+	return loadInventoryFile(filepath.Join(dir, InventoryFileName))
+}
+
+// loadInventoryFile reads the Inventory persisted to the file at path
+func loadInventoryFile(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read inventory at path %s", path)
+	}
+	inventory := &Inventory{}
+	if err := json.Unmarshal(data, inventory); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode inventory at path %s", path)
+	}
+	inventory.path = path
+	if inventory.Settings == nil {
+		inventory.Settings = map[string]string{}
+	}
+	return inventory, nil
+}
+
+// Save persists the inventory to the file from which it was loaded or within the directory
+// with which it was created
+func (i *Inventory) Save() error {
+	data, err := json.MarshalIndent(i.Sorted(), "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode inventory")
+	}
+	// ccoctl does not exit on timing out while the inventory is being written
+	timeoutMu.Lock()
+	defer timeoutMu.Unlock()
+	if err := os.WriteFile(i.path, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to save inventory at path %s", i.path)
+	}
+	recordSavedInventory(i.path, i)
+	return nil
+}
+
+// Sorted returns a copy of the inventory whose steps are ordered by name and whose resources are ordered by
+// SortInventoryResources, leaving the inventory itself untouched as callers may hold its steps
+func (i *Inventory) Sorted() *Inventory {
+	sorted := *i
+	sorted.Intended = SortInventoryResources(i.Intended)
+	sorted.Steps = make([]InventoryStep, len(i.Steps))
+	for idx, step := range i.Steps {
+		sorted.Steps[idx] = InventoryStep{
+			Name:      step.Name,
+			Resources: SortInventoryResources(step.Resources),
+		}
+	}
+	sort.SliceStable(sorted.Steps, func(a, b int) bool { return sorted.Steps[a].Name < sorted.Steps[b].Name })
+	return &sorted
+}
+
+// SortInventoryResources returns a copy of resources ordered by type, then name, then ID, so that resources created
+// concurrently are reported in the same order by every run
+func SortInventoryResources(resources []InventoryResource) []InventoryResource {
+	if resources == nil {
+		return nil
+	}
+	sorted := append([]InventoryResource{}, resources...)
+	sort.SliceStable(sorted, func(a, b int) bool { return inventoryResourceLess(sorted[a], sorted[b]) })
+	return sorted
+}
+
+// inventoryResourceLess orders resources by type, then name, then ID
+func inventoryResourceLess(a, b InventoryResource) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.ID < b.ID
+}
+
+// Step returns the completed step identified by name
+func (i *Inventory) Step(name string) (*InventoryStep, bool) {
+	for idx := range i.Steps {
+		if i.Steps[idx].Name == name {
+			return &i.Steps[idx], true
+		}
+	}
+	return nil, false
+}
+
+// CompleteStep records the step identified by name as completed along with the provided resources
+// and persists the inventory. A previously completed step with the same name is replaced.
+func (i *Inventory) CompleteStep(name string, resources ...InventoryResource) error {
+	step := InventoryStep{
+		Name:      name,
+		Resources: resources,
+	}
+	if existingStep, found := i.Step(name); found {
+		*existingStep = step
+	} else {
+		i.Steps = append(i.Steps, step)
+	}
+	return i.Save()
+}
+
+// RecordIntended records the provided resources as about to be created and persists the inventory, so that they are
+// recorded even when create is interrupted before completing the step creating them. A resource previously recorded
+// with the same type and name is replaced.
+func (i *Inventory) RecordIntended(resources ...InventoryResource) error {
+	for _, resource := range resources {
+		replaced := false
+		for idx, intended := range i.Intended {
+			if intended.Type == resource.Type && intended.Name == resource.Name {
+				i.Intended[idx] = resource
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			i.Intended = append(i.Intended, resource)
+		}
+	}
+	return i.Save()
+}
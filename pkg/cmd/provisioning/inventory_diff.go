@@ -0,0 +1,330 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// inventoryDiffOutputText writes the InventoryDiff of "inventory diff" to stdout as human-readable lines
+	inventoryDiffOutputText = "text"
+	// inventoryDiffOutputJSON writes the InventoryDiff of "inventory diff" to stdout as a single JSON document
+	inventoryDiffOutputJSON = "json"
+)
+
+// DiffInventoryOpts captures the options of the "inventory diff" subcommand
+var DiffInventoryOpts = struct {
+	Since     string
+	Inventory string
+	Output    string
+}{}
+
+// InventoryDiffResource is a resource recorded within one of the compared inventories along with the step which
+// recorded it
+type InventoryDiffResource struct {
+	InventoryResource
+	Step string `json:"step"`
+}
+
+func (r InventoryDiffResource) String() string {
+	description := r.Type + " " + r.Name
+	if r.ID != "" && r.ID != r.Name {
+		description += " (" + r.ID + ")"
+	}
+	return description
+}
+
+// InventoryResourceChange is a resource recorded within both compared inventories whose record changed
+type InventoryResourceChange struct {
+	Old InventoryDiffResource `json:"old"`
+	New InventoryDiffResource `json:"new"`
+	// Changes describe each changed field of the record, eg. "renamed from x"
+	Changes []string `json:"changes"`
+}
+
+// InventorySettingChange is a setting added, removed or changed between the compared inventories. Old is empty for
+// an added setting and New is empty for a removed setting.
+type InventorySettingChange struct {
+	Key string `json:"key"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// InventoryDiff is the difference between an inventory saved by a previous run of ccoctl and a later inventory
+type InventoryDiff struct {
+	// Since and Inventory are the paths of the previous and the later inventory
+	Since     string                    `json:"since"`
+	Inventory string                    `json:"inventory"`
+	Added     []InventoryDiffResource   `json:"added"`
+	Removed   []InventoryDiffResource   `json:"removed"`
+	Changed   []InventoryResourceChange `json:"changed"`
+	Settings  []InventorySettingChange  `json:"settings"`
+}
+
+// Empty returns true when the compared inventories record the same resources and settings
+func (d *InventoryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && len(d.Settings) == 0
+}
+
+// DiffInventories compares the inventory since, saved by a previous run of ccoctl, with the later inventory. Resources
+// are matched by their cloud ID, so that a resource renamed within the inventory, eg. as a later version of ccoctl
+// records it under another name or type, is reported as changed rather than as removed and added. Resources recorded
+// without an ID, or whose ID changed, eg. as they were recreated, are matched by their type and name instead. A
+// resource recorded by several steps is compared as recorded by the first.
+func DiffInventories(since, inventory *Inventory) *InventoryDiff {
+	diff := &InventoryDiff{
+		Added:    []InventoryDiffResource{},
+		Removed:  []InventoryDiffResource{},
+		Changed:  []InventoryResourceChange{},
+		Settings: []InventorySettingChange{},
+	}
+
+	oldResources := diffResources(since)
+	newResources := diffResources(inventory)
+	matched := make([]bool, len(oldResources))
+	oldByID := map[string]int{}
+	oldByTypeName := map[string]int{}
+	for i, resource := range oldResources {
+		if resource.ID != "" {
+			if _, found := oldByID[resource.ID]; !found {
+				oldByID[resource.ID] = i
+			}
+		}
+		if _, found := oldByTypeName[typeNameKey(resource)]; !found {
+			oldByTypeName[typeNameKey(resource)] = i
+		}
+	}
+
+	// Resources are first matched by ID so that a resource whose type and name match a resource with another ID is
+	// only matched once the resources with IDs are
+	newMatches := make([]int, len(newResources))
+	for i, resource := range newResources {
+		newMatches[i] = -1
+		if j, found := oldByID[resource.ID]; found && resource.ID != "" && !matched[j] {
+			newMatches[i] = j
+			matched[j] = true
+		}
+	}
+	for i, resource := range newResources {
+		if newMatches[i] >= 0 {
+			continue
+		}
+		if j, found := oldByTypeName[typeNameKey(resource)]; found && !matched[j] {
+			newMatches[i] = j
+			matched[j] = true
+		}
+	}
+
+	for i, resource := range newResources {
+		if newMatches[i] < 0 {
+			diff.Added = append(diff.Added, resource)
+			continue
+		}
+		if changes := resourceChanges(oldResources[newMatches[i]], resource); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, InventoryResourceChange{Old: oldResources[newMatches[i]], New: resource, Changes: changes})
+		}
+	}
+	for i, resource := range oldResources {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, resource)
+		}
+	}
+	sortDiffResources(diff.Added)
+	sortDiffResources(diff.Removed)
+	sort.SliceStable(diff.Changed, func(a, b int) bool {
+		return inventoryResourceLess(diff.Changed[a].New.InventoryResource, diff.Changed[b].New.InventoryResource)
+	})
+
+	keys := map[string]bool{}
+	for key := range since.Settings {
+		keys[key] = true
+	}
+	for key := range inventory.Settings {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		if since.Settings[key] != inventory.Settings[key] {
+			diff.Settings = append(diff.Settings, InventorySettingChange{Key: key, Old: since.Settings[key], New: inventory.Settings[key]})
+		}
+	}
+	return diff
+}
+
+// sortDiffResources orders resources by type, then name, then ID, regardless of the order in which they were recorded
+func sortDiffResources(resources []InventoryDiffResource) {
+	sort.SliceStable(resources, func(a, b int) bool {
+		return inventoryResourceLess(resources[a].InventoryResource, resources[b].InventoryResource)
+	})
+}
+
+// diffResources returns the resources recorded within inventory in the order that they were recorded, ignoring
+// the resources previously recorded by another step
+func diffResources(inventory *Inventory) []InventoryDiffResource {
+	resources := []InventoryDiffResource{}
+	seen := map[string]bool{}
+	for _, step := range inventory.Steps {
+		for _, resource := range step.Resources {
+			key := resource.ID
+			if key == "" {
+				key = typeNameKey(InventoryDiffResource{InventoryResource: resource})
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			resources = append(resources, InventoryDiffResource{InventoryResource: resource, Step: step.Name})
+		}
+	}
+	return resources
+}
+
+// typeNameKey identifies a resource by its type and name, which are separated by a character neither may contain
+func typeNameKey(resource InventoryDiffResource) string {
+	return resource.Type + "\x00" + resource.Name
+}
+
+// resourceChanges describes each field of the record of a resource which changed from old to new
+func resourceChanges(old, new InventoryDiffResource) []string {
+	changes := []string{}
+	if old.Type != new.Type {
+		changes = append(changes, fmt.Sprintf("type changed from %s", old.Type))
+	}
+	if old.Name != new.Name {
+		changes = append(changes, fmt.Sprintf("renamed from %s", old.Name))
+	}
+	if old.ID != new.ID {
+		if old.ID == "" {
+			changes = append(changes, "ID recorded")
+		} else {
+			changes = append(changes, fmt.Sprintf("ID changed from %s", old.ID))
+		}
+	}
+	if old.Step != new.Step {
+		changes = append(changes, fmt.Sprintf("recorded by step %s instead of %s", new.Step, old.Step))
+	}
+	return changes
+}
+
+// writeInventoryDiffText writes diff to w as human-readable lines, prefixing added resources with "+", removed
+// resources with "-" and changed resources and settings with "~"
+func writeInventoryDiffText(w io.Writer, diff *InventoryDiff) error {
+	lines := []string{fmt.Sprintf("Comparing inventory %s with %s", diff.Inventory, diff.Since)}
+	for _, resource := range diff.Added {
+		lines = append(lines, "+ "+resource.String())
+	}
+	for _, resource := range diff.Removed {
+		lines = append(lines, "- "+resource.String())
+	}
+	for _, change := range diff.Changed {
+		lines = append(lines, fmt.Sprintf("~ %s: %s", change.New, strings.Join(change.Changes, ", ")))
+	}
+	for _, setting := range diff.Settings {
+		switch {
+		case setting.Old == "":
+			lines = append(lines, fmt.Sprintf("+ setting %s: %s", setting.Key, setting.New))
+		case setting.New == "":
+			lines = append(lines, fmt.Sprintf("- setting %s: %s", setting.Key, setting.Old))
+		default:
+			lines = append(lines, fmt.Sprintf("~ setting %s: %s -> %s", setting.Key, setting.Old, setting.New))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("%d resources added, %d removed, %d changed, %d settings changed",
+		len(diff.Added), len(diff.Removed), len(diff.Changed), len(diff.Settings)))
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// LoadInventoryAt loads the inventory at path, which is either an inventory file or a directory within which an
+// inventory was saved
+func LoadInventoryAt(path string) (*Inventory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read inventory at path %s", path)
+	}
+	if info.IsDir() {
+		return LoadInventory(path)
+	}
+	return loadInventoryFile(path)
+}
+
+func diffInventoryCmd(cmd *cobra.Command, args []string) {
+	since, err := LoadInventoryAt(DiffInventoryOpts.Since)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inventory, err := LoadInventoryAt(DiffInventoryOpts.Inventory)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if since.Provider != inventory.Provider || since.Name != inventory.Name {
+		Warnf("Comparing the inventory of %s resources named %s with the inventory of %s resources named %s",
+			inventory.Provider, inventory.Name, since.Provider, since.Name)
+	}
+
+	diff := DiffInventories(since, inventory)
+	diff.Since = since.path
+	diff.Inventory = inventory.path
+	if DiffInventoryOpts.Output == inventoryDiffOutputJSON {
+		data, err := json.MarshalIndent(diff, "", "    ")
+		if err != nil {
+			log.Fatalf("Failed to encode inventory diff: %s", err)
+		}
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := writeInventoryDiffText(os.Stdout, diff); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// initEnvForDiffInventoryCmd validates the output format and defaults the inventory to the current directory
+func initEnvForDiffInventoryCmd(cmd *cobra.Command, args []string) {
+	if DiffInventoryOpts.Output != inventoryDiffOutputText && DiffInventoryOpts.Output != inventoryDiffOutputJSON {
+		log.Fatalf("invalid --output %q, expected one of %s, %s", DiffInventoryOpts.Output, inventoryDiffOutputText, inventoryDiffOutputJSON)
+	}
+	if DiffInventoryOpts.Inventory == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			log.Fatalf("Failed to get current directory: %s", err)
+		}
+		DiffInventoryOpts.Inventory = pwd
+	}
+}
+
+// NewInventoryCmd provides the "inventory" subcommand for inspecting the inventories saved by ccoctl
+func NewInventoryCmd() *cobra.Command {
+	inventoryCmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "Inspect the inventories of the resources created by ccoctl",
+	}
+
+	diffCmd := &cobra.Command{
+		Use:              "diff",
+		Short:            "Compare the inventory of a previous run with a later inventory",
+		Long:             "Compare the inventory saved by a previous run of ccoctl with a later inventory, reporting the resources added, removed and changed in between. Resources are matched by their cloud ID, so that resources recorded under another name are reported as changed",
+		Run:              diffInventoryCmd,
+		PersistentPreRun: initEnvForDiffInventoryCmd,
+	}
+	diffCmd.PersistentFlags().StringVar(&DiffInventoryOpts.Since, "since", "", "Inventory saved by the previous run to compare with, either its file or the output directory within which it was saved")
+	diffCmd.MarkPersistentFlagRequired("since")
+	diffCmd.PersistentFlags().StringVar(&DiffInventoryOpts.Inventory, "inventory", "", "Later inventory to compare, either its file or the output directory within which it was saved (defaults to current directory)")
+	diffCmd.Flags().StringVar(&DiffInventoryOpts.Output, "output", inventoryDiffOutputText, "Output format, either \"text\" or \"json\". With json the added, removed and changed resources and settings are written to stdout as a single JSON document")
+
+	inventoryCmd.AddCommand(diffCmd)
+	return inventoryCmd
+}
@@ -0,0 +1,171 @@
+package provisioning
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffInventories(t *testing.T) {
+	identity := InventoryResource{Type: "UserAssignedManagedIdentity", Name: "test-name-ns-secret", ID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/test-name-ns-secret"}
+	role := InventoryResource{Type: "IAMRole", Name: "test-name-ns-secret", ID: "arn:aws:iam::123456789012:role/test-name-ns-secret"}
+
+	tests := []struct {
+		name            string
+		since           []InventoryStep
+		inventory       []InventoryStep
+		sinceSettings   map[string]string
+		settings        map[string]string
+		expectedAdded   []string
+		expectedRemoved []string
+		// expectedChanged are the changes of each changed resource, keyed by the name it is recorded with later
+		expectedChanged  map[string][]string
+		expectedSettings []InventorySettingChange
+	}{
+		{
+			name:      "Same resources",
+			since:     []InventoryStep{{Name: "step1", Resources: []InventoryResource{identity, role}}},
+			inventory: []InventoryStep{{Name: "step1", Resources: []InventoryResource{identity, role}}},
+		},
+		{
+			name:            "Added and removed resources",
+			since:           []InventoryStep{{Name: "step1", Resources: []InventoryResource{identity}}},
+			inventory:       []InventoryStep{{Name: "step2", Resources: []InventoryResource{role}}},
+			expectedAdded:   []string{role.ID},
+			expectedRemoved: []string{identity.ID},
+		},
+		{
+			name:      "Renamed resource matched by ID",
+			since:     []InventoryStep{{Name: "step1", Resources: []InventoryResource{{Type: "Role", Name: "old-name", ID: role.ID}}}},
+			inventory: []InventoryStep{{Name: "step1", Resources: []InventoryResource{role}}},
+			expectedChanged: map[string][]string{
+				role.Name: {"type changed from Role", "renamed from old-name"},
+			},
+		},
+		{
+			name:      "Resource recorded with an ID by a later version matched by type and name",
+			since:     []InventoryStep{{Name: "step1", Resources: []InventoryResource{{Type: role.Type, Name: role.Name}}}},
+			inventory: []InventoryStep{{Name: "step1", Resources: []InventoryResource{role}}},
+			expectedChanged: map[string][]string{
+				role.Name: {"ID recorded"},
+			},
+		},
+		{
+			name:      "Recreated resource matched by type and name",
+			since:     []InventoryStep{{Name: "step1", Resources: []InventoryResource{{Type: role.Type, Name: role.Name, ID: "arn:aws:iam::210987654321:role/test-name-ns-secret"}}}},
+			inventory: []InventoryStep{{Name: "step2", Resources: []InventoryResource{role}}},
+			expectedChanged: map[string][]string{
+				role.Name: {"ID changed from arn:aws:iam::210987654321:role/test-name-ns-secret", "recorded by step step2 instead of step1"},
+			},
+		},
+		{
+			name: "Resource recorded by several steps compared once",
+			since: []InventoryStep{
+				{Name: "step1", Resources: []InventoryResource{identity}},
+				{Name: "step2", Resources: []InventoryResource{identity}},
+			},
+			inventory: []InventoryStep{{Name: "step1", Resources: []InventoryResource{identity}}},
+		},
+		{
+			name:          "Changed settings",
+			sinceSettings: map[string]string{"policyStyle": "inline", "removed": "value"},
+			settings:      map[string]string{"policyStyle": "managed", "added": "value"},
+			expectedSettings: []InventorySettingChange{
+				{Key: "added", New: "value"},
+				{Key: "policyStyle", Old: "inline", New: "managed"},
+				{Key: "removed", Old: "value"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			since := NewInventory(t.TempDir(), "testprovider", "test-name")
+			since.Steps = append(since.Steps, test.since...)
+			for key, value := range test.sinceSettings {
+				since.Settings[key] = value
+			}
+			inventory := NewInventory(t.TempDir(), "testprovider", "test-name")
+			inventory.Steps = append(inventory.Steps, test.inventory...)
+			for key, value := range test.settings {
+				inventory.Settings[key] = value
+			}
+
+			diff := DiffInventories(since, inventory)
+
+			added := []string{}
+			for _, resource := range diff.Added {
+				added = append(added, resource.ID)
+			}
+			removed := []string{}
+			for _, resource := range diff.Removed {
+				removed = append(removed, resource.ID)
+			}
+			changed := map[string][]string{}
+			for _, change := range diff.Changed {
+				changed[change.New.Name] = change.Changes
+			}
+			assert.ElementsMatch(t, test.expectedAdded, added, "unexpected added resources")
+			assert.ElementsMatch(t, test.expectedRemoved, removed, "unexpected removed resources")
+			if test.expectedChanged == nil {
+				test.expectedChanged = map[string][]string{}
+			}
+			assert.Equal(t, test.expectedChanged, changed, "unexpected changed resources")
+			if test.expectedSettings == nil {
+				test.expectedSettings = []InventorySettingChange{}
+			}
+			assert.Equal(t, test.expectedSettings, diff.Settings, "unexpected changed settings")
+			assert.Equal(t, len(test.expectedAdded)+len(test.expectedRemoved)+len(test.expectedChanged)+len(test.expectedSettings) == 0, diff.Empty(), "unexpected emptiness of the diff")
+		})
+	}
+}
+
+func TestWriteInventoryDiffText(t *testing.T) {
+	diff := &InventoryDiff{
+		Since:     "old/ccoctl-inventory.json",
+		Inventory: "new/ccoctl-inventory.json",
+		Added:     []InventoryDiffResource{{InventoryResource: InventoryResource{Type: "IAMRole", Name: "added", ID: "arn:aws:iam::123456789012:role/added"}, Step: "iam-role/ns/added"}},
+		Removed:   []InventoryDiffResource{{InventoryResource: InventoryResource{Type: "ResourceGroup", Name: "removed", ID: "removed"}, Step: "resource-group"}},
+		Changed: []InventoryResourceChange{{
+			Old:     InventoryDiffResource{InventoryResource: InventoryResource{Type: "IAMRole", Name: "old-name", ID: "arn:aws:iam::123456789012:role/changed"}},
+			New:     InventoryDiffResource{InventoryResource: InventoryResource{Type: "IAMRole", Name: "new-name", ID: "arn:aws:iam::123456789012:role/changed"}},
+			Changes: []string{"renamed from old-name"},
+		}},
+		Settings: []InventorySettingChange{{Key: "policyStyle", Old: "inline", New: "managed"}},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeInventoryDiffText(out, diff), "unexpected error")
+	assert.Equal(t, `Comparing inventory new/ccoctl-inventory.json with old/ccoctl-inventory.json
++ IAMRole added (arn:aws:iam::123456789012:role/added)
+- ResourceGroup removed
+~ IAMRole new-name (arn:aws:iam::123456789012:role/changed): renamed from old-name
+~ setting policyStyle: inline -> managed
+1 resources added, 1 removed, 1 changed, 1 settings changed
+`, out.String(), "unexpected text output")
+}
+
+func TestLoadInventoryAt(t *testing.T) {
+	dir := t.TempDir()
+	inventory := NewInventory(dir, "testprovider", "test-name")
+	require.NoError(t, inventory.CompleteStep("step1", InventoryResource{Type: "testtype", Name: "resource1", ID: "id1"}), "unexpected error saving inventory")
+
+	// Inventories are loaded from their output directory or from a file, eg. an inventory copied aside before a run
+	copiedPath := filepath.Join(t.TempDir(), "previous-inventory.json")
+	data, err := os.ReadFile(filepath.Join(dir, InventoryFileName))
+	require.NoError(t, err, "unexpected error reading inventory")
+	require.NoError(t, os.WriteFile(copiedPath, data, 0600), "unexpected error copying inventory")
+
+	for _, path := range []string{dir, copiedPath} {
+		loaded, err := LoadInventoryAt(path)
+		require.NoError(t, err, "unexpected error loading inventory at %s", path)
+		assert.Equal(t, inventory.Steps, loaded.Steps, "unexpected steps of inventory at %s", path)
+	}
+
+	_, err = LoadInventoryAt(filepath.Join(dir, "missing"))
+	assert.Error(t, err, "expected error loading a missing inventory")
+}
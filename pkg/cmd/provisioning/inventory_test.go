@@ -0,0 +1,113 @@
+package provisioning
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	tempDirName, err := os.MkdirTemp(os.TempDir(), "inventorytestdir")
+	require.NoError(t, err, "failed to create temp directory")
+	defer os.RemoveAll(tempDirName)
+
+	_, err = LoadInventory(tempDirName)
+	require.Error(t, err, "expected error loading inventory which was never saved")
+
+	inventory := NewInventory(tempDirName, "testprovider", "testname")
+	inventory.Settings["region"] = "testregion"
+	err = inventory.CompleteStep("step1", InventoryResource{Type: "testtype", Name: "resource1", ID: "id1"})
+	require.NoError(t, err, "unexpected error completing step")
+	err = inventory.CompleteStep("step2")
+	require.NoError(t, err, "unexpected error completing step")
+
+	info, err := os.Stat(inventory.path)
+	require.NoError(t, err, "inventory should be saved after completing a step")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "unexpected inventory file permissions")
+
+	loadedInventory, err := LoadInventory(tempDirName)
+	require.NoError(t, err, "unexpected error loading inventory")
+	assert.Equal(t, "testprovider", loadedInventory.Provider)
+	assert.Equal(t, "testname", loadedInventory.Name)
+	assert.Equal(t, "testregion", loadedInventory.Settings["region"])
+	require.Len(t, loadedInventory.Steps, 2, "unexpected number of completed steps")
+
+	step, found := loadedInventory.Step("step1")
+	require.True(t, found, "expected step1 to be completed")
+	assert.Equal(t, []InventoryResource{{Type: "testtype", Name: "resource1", ID: "id1"}}, step.Resources)
+	_, found = loadedInventory.Step("step3")
+	assert.False(t, found, "step3 should not be completed")
+
+	// Completing a step again replaces the previously recorded step
+	err = loadedInventory.CompleteStep("step1", InventoryResource{Type: "testtype", Name: "resource2"})
+	require.NoError(t, err, "unexpected error completing step")
+	reloadedInventory, err := LoadInventory(tempDirName)
+	require.NoError(t, err, "unexpected error loading inventory")
+	require.Len(t, reloadedInventory.Steps, 2, "unexpected number of completed steps")
+	assert.Equal(t, "resource2", reloadedInventory.Steps[0].Resources[0].Name)
+}
+
+func TestInventoryDeterministicOrder(t *testing.T) {
+	resources := []InventoryResource{
+		{Type: "role", Name: "b", ID: "id-b"},
+		{Type: "policy", Name: "z"},
+		{Type: "role", Name: "a", ID: "id-a2"},
+		{Type: "role", Name: "a", ID: "id-a1"},
+	}
+	steps := []string{"step-c", "step-a", "step-b"}
+
+	save := func(reverse bool) []byte {
+		tempDirName, err := os.MkdirTemp(os.TempDir(), "inventorytestdir")
+		require.NoError(t, err, "failed to create temp directory")
+		defer os.RemoveAll(tempDirName)
+
+		inventory := NewInventory(tempDirName, "testprovider", "testname")
+		for idx := range steps {
+			stepResources := append([]InventoryResource{}, resources...)
+			if reverse {
+				idx = len(steps) - 1 - idx
+				for i, j := 0, len(stepResources)-1; i < j; i, j = i+1, j-1 {
+					stepResources[i], stepResources[j] = stepResources[j], stepResources[i]
+				}
+			}
+			require.NoError(t, inventory.CompleteStep(steps[idx], stepResources...), "unexpected error completing step")
+		}
+		assert.Equal(t, map[bool]string{false: "step-c", true: "step-b"}[reverse], inventory.Steps[0].Name, "steps held in memory should keep their completion order")
+
+		data, err := os.ReadFile(inventory.path)
+		require.NoError(t, err, "failed to read inventory")
+		loadedInventory, err := LoadInventory(tempDirName)
+		require.NoError(t, err, "unexpected error loading inventory")
+		assert.Equal(t, "step-a", loadedInventory.Steps[0].Name, "steps should be persisted ordered by name")
+		assert.Equal(t, []InventoryResource{
+			{Type: "policy", Name: "z"},
+			{Type: "role", Name: "a", ID: "id-a1"},
+			{Type: "role", Name: "a", ID: "id-a2"},
+			{Type: "role", Name: "b", ID: "id-b"},
+		}, loadedInventory.Steps[0].Resources, "resources should be persisted ordered by type, name and ID")
+		return data
+	}
+
+	assert.Equal(t, string(save(false)), string(save(true)), "inventories completed in different orders should be persisted identically")
+}
+
+func TestInventoryRecordIntended(t *testing.T) {
+	tempDirName := t.TempDir()
+
+	inventory := NewInventory(tempDirName, "testprovider", "testname")
+	require.NoError(t, inventory.RecordIntended(InventoryResource{Type: "testtype", Name: "resource2"}, InventoryResource{Type: "testtype", Name: "resource1"}),
+		"unexpected error recording intended resources")
+	// Recording a resource again replaces the previously recorded resource
+	require.NoError(t, inventory.RecordIntended(InventoryResource{Type: "testtype", Name: "resource2", ID: "id2"}),
+		"unexpected error recording intended resources")
+
+	loadedInventory, err := LoadInventory(tempDirName)
+	require.NoError(t, err, "inventory should be saved after recording intended resources")
+	assert.Empty(t, loadedInventory.Steps, "no step should be completed")
+	assert.Equal(t, []InventoryResource{
+		{Type: "testtype", Name: "resource1"},
+		{Type: "testtype", Name: "resource2", ID: "id2"},
+	}, loadedInventory.Intended, "unexpected intended resources")
+}
@@ -0,0 +1,179 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// issuerRequestTimeout bounds each request for the documents of an OIDC issuer
+	issuerRequestTimeout = 30 * time.Second
+	// maxIssuerDocumentSize bounds the size of the documents read from an OIDC issuer
+	maxIssuerDocumentSize = 1 << 20
+)
+
+var (
+	// issuerVerificationTimeout is how long the documents of an OIDC issuer are retried for, since they may not be
+	// served immediately after being uploaded, eg. while a CloudFront distribution is deployed
+	issuerVerificationTimeout = 10 * time.Minute
+	// issuerVerificationInterval is the interval between attempts to fetch the documents of an OIDC issuer
+	issuerVerificationInterval = 10 * time.Second
+)
+
+// VerifyIssuerReachable fetches the OpenID configuration discovery document of issuerURL and the JSON web key set it
+// refers to over the public network, as the cloud's token service will when the cluster's service account tokens
+// are exchanged, to ensure that the OIDC issuer is publicly served. Proxy settings are honored through the
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables. The documents are retried for a few minutes before an
+// error describing the last failure is returned.
+func VerifyIssuerReachable(issuerURL string) error {
+	return verifyIssuerReachable(newIssuerClient(), issuerURL, issuerVerificationTimeout, issuerVerificationInterval)
+}
+
+// newIssuerClient returns the HTTP client fetching the documents of OIDC issuers over the public network, honoring
+// the proxy settings of the environment
+func newIssuerClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	client := &http.Client{Transport: transport, Timeout: issuerRequestTimeout}
+	if TracingEnabled() {
+		client.Transport = &tracingTransport{base: transport}
+	}
+	return client
+}
+
+// FetchDiscoveryDocumentIssuer fetches the OpenID configuration discovery document of issuerURL over the public
+// network, a single time, and returns the issuer it names
+func FetchDiscoveryDocumentIssuer(issuerURL string) (string, error) {
+	return fetchDiscoveryDocumentIssuer(newIssuerClient(), issuerURL)
+}
+
+func fetchDiscoveryDocumentIssuer(client *http.Client, issuerURL string) (string, error) {
+	discoveryDocument := struct {
+		Issuer string `json:"issuer"`
+	}{}
+	if err := fetchIssuerDocument(client, fmt.Sprintf("%s/%s", strings.TrimSuffix(issuerURL, "/"), DiscoveryDocumentURI), &discoveryDocument); err != nil {
+		return "", err
+	}
+	return discoveryDocument.Issuer, nil
+}
+
+func verifyIssuerReachable(client *http.Client, issuerURL string, timeout, interval time.Duration) error {
+	log.Printf("Verifying that the OIDC issuer %s is publicly reachable", issuerURL)
+	deadline := time.Now().Add(timeout)
+	for {
+		err := fetchIssuerDocuments(client, issuerURL)
+		if err == nil {
+			log.Printf("OIDC issuer %s is publicly reachable", issuerURL)
+			return nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return errors.Wrapf(err, "OIDC issuer %s is not publicly reachable", issuerURL)
+		}
+		log.Printf("OIDC issuer %s is not reachable yet, retrying in %s: %s", issuerURL, interval, err)
+		time.Sleep(interval)
+	}
+}
+
+// fetchIssuerDocuments fetches and validates the discovery document and the JSON web key set of issuerURL
+func fetchIssuerDocuments(client *http.Client, issuerURL string) error {
+	discoveryDocumentURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(issuerURL, "/"), DiscoveryDocumentURI)
+	discoveryDocument := struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	if err := fetchIssuerDocument(client, discoveryDocumentURL, &discoveryDocument); err != nil {
+		return err
+	}
+	if strings.TrimSuffix(discoveryDocument.Issuer, "/") != strings.TrimSuffix(issuerURL, "/") {
+		return errors.Errorf("discovery document %s names issuer %q instead of %q", discoveryDocumentURL, discoveryDocument.Issuer, issuerURL)
+	}
+	if discoveryDocument.JWKSURI == "" {
+		return errors.Errorf("discovery document %s does not name a jwks_uri", discoveryDocumentURL)
+	}
+
+	keySet := struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{}
+	if err := fetchIssuerDocument(client, discoveryDocument.JWKSURI, &keySet); err != nil {
+		return err
+	}
+	if len(keySet.Keys) == 0 {
+		return errors.Errorf("JSON web key set %s contains no keys", discoveryDocument.JWKSURI)
+	}
+	return nil
+}
+
+// fetchIssuerDocument fetches the JSON document at url into out
+func fetchIssuerDocument(client *http.Client, url string, out interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIssuerDocumentSize))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", url)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", url)
+	}
+	return nil
+}
+
+// CanonicalIssuerURL returns issuerURL in the canonical form in which it is recorded within the OIDC discovery
+// document, the federations of cloud identities with the issuer and the manifests written by ccoctl: an https URL
+// with a lowercase scheme and host, without the default port, trailing slashes, query or fragment. Clouds compare
+// the issuer of a service account token exactly with the issuer they trust, eg. Azure fails the exchange of a token
+// whose issuer differs from the issuer of the federated identity credential by a trailing slash.
+func CanonicalIssuerURL(issuerURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(issuerURL))
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid issuer URL %q", issuerURL)
+	}
+	switch {
+	case !strings.EqualFold(parsed.Scheme, "https"):
+		return "", errors.Errorf("invalid issuer URL %q, the issuer URL must be an https URL", issuerURL)
+	case parsed.Host == "":
+		return "", errors.Errorf("invalid issuer URL %q, the issuer URL must have a host", issuerURL)
+	case parsed.User != nil:
+		return "", errors.Errorf("invalid issuer URL %q, the issuer URL may not have user information", issuerURL)
+	case parsed.RawQuery != "" || parsed.ForceQuery || parsed.Fragment != "":
+		return "", errors.Errorf("invalid issuer URL %q, the issuer URL may not have a query or fragment", issuerURL)
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Host, ":443"))
+	return "https://" + host + strings.TrimRight(parsed.EscapedPath(), "/"), nil
+}
+
+// NormalizeIssuerURL returns issuerURL without its https:// scheme and trailing slashes, the form in which issuer
+// URLs are compared since clouds record them inconsistently, eg. AWS drops the scheme of an IAM Identity Provider's URL
+func NormalizeIssuerURL(issuerURL string) string {
+	return strings.TrimRight(strings.TrimPrefix(strings.TrimSpace(issuerURL), "https://"), "/")
+}
+
+// VerifyDiscoveredIssuerURL ensures that the issuer URL discoveredIssuerURL recorded by resource, one of the resources
+// about to be deleted, matches issuerURL which was provided with --issuer-url, so that the resources of another
+// cluster created with the same name are never deleted. An error is also returned when no issuer URL was discovered,
+// as the resources to be deleted can't be attributed to issuerURL.
+func VerifyDiscoveredIssuerURL(issuerURL, discoveredIssuerURL, resource string) error {
+	if discoveredIssuerURL == "" {
+		return errors.Errorf("refusing to delete resources, found no issuer URL recorded by the resources to be deleted to verify against --issuer-url %s, "+
+			"re-run without --issuer-url to delete the remaining resources", issuerURL)
+	}
+	if NormalizeIssuerURL(issuerURL) != NormalizeIssuerURL(discoveredIssuerURL) {
+		return errors.Errorf("refusing to delete resources, %s was created for issuer URL %s rather than --issuer-url %s",
+			resource, discoveredIssuerURL, issuerURL)
+	}
+	log.Printf("Verified that %s was created for issuer URL %s", resource, issuerURL)
+	return nil
+}
@@ -0,0 +1,247 @@
+package provisioning
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyIssuerReachable(t *testing.T) {
+	tests := []struct {
+		name string
+		// discoveryDocument returns the discovery document served for issuerURL, or "" for a 404
+		discoveryDocument func(issuerURL string) string
+		keys              string
+		// unavailableRequests is the number of requests answered with a 503 before the documents are served
+		unavailableRequests int
+		expectError         bool
+	}{
+		{
+			name: "Issuer reachable",
+			discoveryDocument: func(issuerURL string) string {
+				return fmt.Sprintf(DiscoveryDocumentTemplate, issuerURL, issuerURL, KeysURI)
+			},
+			keys: `{"keys": [{"kty": "RSA"}]}`,
+		},
+		{
+			name: "Issuer reachable after being unavailable",
+			discoveryDocument: func(issuerURL string) string {
+				return fmt.Sprintf(DiscoveryDocumentTemplate, issuerURL, issuerURL, KeysURI)
+			},
+			keys:                `{"keys": [{"kty": "RSA"}]}`,
+			unavailableRequests: 2,
+		},
+		{
+			name: "Discovery document not served",
+			discoveryDocument: func(issuerURL string) string {
+				return ""
+			},
+			expectError: true,
+		},
+		{
+			name: "Discovery document for another issuer",
+			discoveryDocument: func(issuerURL string) string {
+				return fmt.Sprintf(DiscoveryDocumentTemplate, "https://other.example.com", issuerURL, KeysURI)
+			},
+			keys:        `{"keys": [{"kty": "RSA"}]}`,
+			expectError: true,
+		},
+		{
+			name: "JSON web key set not served",
+			discoveryDocument: func(issuerURL string) string {
+				return fmt.Sprintf(DiscoveryDocumentTemplate, issuerURL, issuerURL, KeysURI)
+			},
+			expectError: true,
+		},
+		{
+			name: "JSON web key set without keys",
+			discoveryDocument: func(issuerURL string) string {
+				return fmt.Sprintf(DiscoveryDocumentTemplate, issuerURL, issuerURL, KeysURI)
+			},
+			keys:        `{"keys": []}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			var issuerURL string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= test.unavailableRequests {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				var document string
+				switch r.URL.Path {
+				case "/cluster/" + DiscoveryDocumentURI:
+					document = test.discoveryDocument(issuerURL)
+				case "/cluster/" + KeysURI:
+					document = test.keys
+				}
+				if document == "" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(document))
+			}))
+			defer server.Close()
+			issuerURL = server.URL + "/cluster"
+
+			err := verifyIssuerReachable(server.Client(), issuerURL, 50*time.Millisecond, time.Millisecond)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), "is not publicly reachable", "expected error to name the unreachable issuer")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestVerifyDiscoveredIssuerURL(t *testing.T) {
+	tests := []struct {
+		name                string
+		issuerURL           string
+		discoveredIssuerURL string
+		expectError         bool
+	}{
+		{
+			name:                "Matching issuer URL",
+			issuerURL:           "https://mycluster-oidc.s3.us-east-1.amazonaws.com",
+			discoveredIssuerURL: "https://mycluster-oidc.s3.us-east-1.amazonaws.com",
+		},
+		{
+			name:                "Scheme and trailing slash ignored",
+			issuerURL:           "https://mycluster-oidc.s3.us-east-1.amazonaws.com/",
+			discoveredIssuerURL: "mycluster-oidc.s3.us-east-1.amazonaws.com",
+		},
+		{
+			name:                "Issuer URL of another cluster",
+			issuerURL:           "https://mycluster-oidc.s3.us-east-1.amazonaws.com",
+			discoveredIssuerURL: "https://othercluster-oidc.s3.us-east-1.amazonaws.com",
+			expectError:         true,
+		},
+		{
+			name:                "Path of the issuer URL compared",
+			issuerURL:           "https://account.blob.core.windows.net/container",
+			discoveredIssuerURL: "https://account.blob.core.windows.net/container/prefix",
+			expectError:         true,
+		},
+		{
+			name:        "No issuer URL discovered",
+			issuerURL:   "https://mycluster-oidc.s3.us-east-1.amazonaws.com",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyDiscoveredIssuerURL(test.issuerURL, test.discoveredIssuerURL, "test resource")
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestCanonicalIssuerURL(t *testing.T) {
+	tests := []struct {
+		name              string
+		issuerURL         string
+		expectedIssuerURL string
+		expectError       bool
+	}{
+		{
+			name:              "Canonical issuer URL",
+			issuerURL:         "https://account.blob.core.windows.net/container",
+			expectedIssuerURL: "https://account.blob.core.windows.net/container",
+		},
+		{
+			name:              "Trailing slashes trimmed",
+			issuerURL:         "https://account.blob.core.windows.net/container//",
+			expectedIssuerURL: "https://account.blob.core.windows.net/container",
+		},
+		{
+			name:              "Scheme and host lowercased, path kept",
+			issuerURL:         "HTTPS://Account.Blob.Core.Windows.Net/Container/Prefix",
+			expectedIssuerURL: "https://account.blob.core.windows.net/Container/Prefix",
+		},
+		{
+			name:              "Default port and surrounding whitespace trimmed",
+			issuerURL:         " https://mycluster-oidc.s3.us-east-1.amazonaws.com:443/ ",
+			expectedIssuerURL: "https://mycluster-oidc.s3.us-east-1.amazonaws.com",
+		},
+		{
+			name:              "Other port kept",
+			issuerURL:         "https://oidc.example.com:8443",
+			expectedIssuerURL: "https://oidc.example.com:8443",
+		},
+		{
+			name:        "http issuer URL",
+			issuerURL:   "http://oidc.example.com",
+			expectError: true,
+		},
+		{
+			name:        "Issuer URL without scheme",
+			issuerURL:   "oidc.example.com/cluster",
+			expectError: true,
+		},
+		{
+			name:        "Issuer URL with query",
+			issuerURL:   "https://oidc.example.com/cluster?version=1",
+			expectError: true,
+		},
+		{
+			name:        "Issuer URL with fragment",
+			issuerURL:   "https://oidc.example.com/cluster#keys",
+			expectError: true,
+		},
+		{
+			name:        "Issuer URL with user information",
+			issuerURL:   "https://user@oidc.example.com/cluster",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issuerURL, err := CanonicalIssuerURL(test.issuerURL)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedIssuerURL, issuerURL, "unexpected issuer URL")
+
+			canonicalIssuerURL, err := CanonicalIssuerURL(issuerURL)
+			require.NoError(t, err, "unexpected error canonicalizing canonical issuer URL")
+			assert.Equal(t, issuerURL, canonicalIssuerURL, "expected canonical issuer URL to be unchanged")
+		})
+	}
+}
+
+func TestFetchDiscoveryDocumentIssuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cluster/"+DiscoveryDocumentURI {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"issuer": "https://oidc.example.com/cluster/"}`))
+	}))
+	defer server.Close()
+
+	issuer, err := fetchDiscoveryDocumentIssuer(server.Client(), server.URL+"/cluster/")
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, "https://oidc.example.com/cluster/", issuer, "expected the issuer of the discovery document verbatim")
+
+	_, err = fetchDiscoveryDocumentIssuer(server.Client(), server.URL+"/other")
+	assert.Error(t, err, "expected error fetching a discovery document which is not served")
+}
@@ -0,0 +1,63 @@
+package provisioning
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// JWKSPEMBundleFile is the name of the PEM bundle of the public keys of the JSON web key set written within the output
+// directory with --jwks-pem-bundle
+const JWKSPEMBundleFile = "keys.pem"
+
+// jwksPEMBundleEnabled is the value of --jwks-pem-bundle set by InitJWKSPEMBundle
+var jwksPEMBundleEnabled bool
+
+// InitJWKSPEMBundle configures whether the public keys of the JSON web key set published by create are also written
+// as a PEM bundle, eg. for consumers of the service account tokens which do not read JSON web key sets
+func InitJWKSPEMBundle(enabled bool) {
+	jwksPEMBundleEnabled = enabled
+}
+
+// WriteJWKSPEMBundle writes the public keys of the JSON web key set jwks to JWKSPEMBundleFile within dir when
+// --jwks-pem-bundle is provided, and does nothing otherwise
+func WriteJWKSPEMBundle(jwks []byte, dir string) error {
+	if !jwksPEMBundleEnabled {
+		return nil
+	}
+	bundle, err := jwksPEMBundle(jwks)
+	if err != nil {
+		return err
+	}
+	bundlePath := filepath.Join(dir, JWKSPEMBundleFile)
+	if err := os.WriteFile(bundlePath, bundle, 0600); err != nil {
+		return errors.Wrapf(err, "failed to save PEM bundle of the JSON web key set at %s", bundlePath)
+	}
+	log.Printf("Saved PEM bundle of the JSON web key set at path %s", bundlePath)
+	return nil
+}
+
+// jwksPEMBundle returns the public keys of the JSON web key set jwks, in the order of the key set, as PEM encoded
+// PKIX public keys. The keys are decoded from jwks itself, rather than read from the public key files, so that the
+// bundle holds exactly the keys which are published.
+func jwksPEMBundle(jwks []byte) ([]byte, error) {
+	keySet := JSONWebKeySet{}
+	if err := json.Unmarshal(jwks, &keySet); err != nil {
+		return nil, errors.Wrap(err, "failed to decode JSON web key set")
+	}
+	bundle := &bytes.Buffer{}
+	for _, key := range keySet.Keys {
+		der, err := x509.MarshalPKIXPublicKey(key.Key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encode public key %s of the JSON web key set", key.KeyID)
+		}
+		bundle.Write(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	return bundle.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+package provisioning
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJWKSPEMBundle(t *testing.T) {
+	t.Cleanup(func() { InitJWKSPEMBundle(false) })
+
+	publicKeyPaths := []string{}
+	for i := 0; i < 2; i++ {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err, "failed to generate test key")
+		der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		require.NoError(t, err, "failed to marshal test public key")
+		publicKeyPath := filepath.Join(t.TempDir(), PublicKeyFile)
+		require.NoError(t, os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+		publicKeyPaths = append(publicKeyPaths, publicKeyPath)
+	}
+	jwks, err := BuildJsonWebKeySet(publicKeyPaths...)
+	require.NoError(t, err, "failed to build JSON web key set")
+
+	outputDir := t.TempDir()
+	require.NoError(t, WriteJWKSPEMBundle(jwks, outputDir), "unexpected error without --jwks-pem-bundle")
+	assert.NoFileExists(t, filepath.Join(outputDir, JWKSPEMBundleFile), "expected no PEM bundle without --jwks-pem-bundle")
+
+	InitJWKSPEMBundle(true)
+	require.NoError(t, WriteJWKSPEMBundle(jwks, outputDir), "unexpected error writing the PEM bundle")
+	bundle, err := os.ReadFile(filepath.Join(outputDir, JWKSPEMBundleFile))
+	require.NoError(t, err, "failed to read the PEM bundle")
+
+	// The public keys of the bundle are those of the JWKS, in the same order
+	keySet := JSONWebKeySet{}
+	require.NoError(t, json.Unmarshal(jwks, &keySet), "failed to decode JSON web key set")
+	bundledKeys := []interface{}{}
+	for block, rest := pem.Decode(bundle); block != nil; block, rest = pem.Decode(rest) {
+		assert.Equal(t, "PUBLIC KEY", block.Type, "unexpected type of PEM block")
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		require.NoError(t, err, "failed to parse public key of the PEM bundle")
+		bundledKeys = append(bundledKeys, publicKey)
+	}
+	require.Len(t, bundledKeys, len(keySet.Keys), "expected the PEM bundle to hold every key of the JWKS")
+	for i, key := range keySet.Keys {
+		assert.True(t, key.Key.(*rsa.PublicKey).Equal(bundledKeys[i]), "expected key %d of the PEM bundle to match key %s of the JWKS", i, key.KeyID)
+		keyID, err := KeyIDFromPublicKey(bundledKeys[i])
+		require.NoError(t, err, "failed to derive key ID")
+		assert.Equal(t, key.KeyID, keyID, "unexpected key ID of key %d of the PEM bundle", i)
+	}
+
+	assert.ErrorContains(t, WriteJWKSPEMBundle([]byte("not a key set"), outputDir), "failed to decode JSON web key set")
+}
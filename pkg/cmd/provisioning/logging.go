@@ -0,0 +1,125 @@
+package provisioning
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// noColorEnv is the environment variable which disables colored logs when set to a non-empty value,
+	// see https://no-color.org
+	noColorEnv = "NO_COLOR"
+
+	// warningPrefix prefixes the messages logged by Warnf
+	warningPrefix = "WARNING: "
+	// debugPrefix prefixes the messages logged by Debugf
+	debugPrefix = "DEBUG: "
+
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+)
+
+// debugEnabled indicates that the messages logged by Debugf are written to the logs
+var debugEnabled bool
+
+// InitLogging configures the logs of ccoctl, which are written to stderr. Warnings and errors are colored when stderr
+// is a terminal unless noColor is set, the NO_COLOR environment variable is set or the terminal is "dumb". Output
+// written to stdout, such as the issuer URL printed by --print-issuer-url, is never colored. Debug messages are only
+// logged when debug is set. The secret material within the logs is redacted.
+func InitLogging(noColor, debug bool) {
+	debugEnabled = debug
+	out := log.Writer()
+	if redacting, ok := out.(*redactingWriter); ok {
+		out = redacting.out
+	}
+	if ColorEnabled(noColor, os.Stderr) {
+		out = NewColorWriter(os.Stderr)
+	}
+	log.SetOutput(NewRedactingWriter(out))
+}
+
+// ColorEnabled returns true when logs written to out should be colored
+func ColorEnabled(noColor bool, out *os.File) bool {
+	if noColor || os.Getenv(noColorEnv) != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(out.Fd()))
+}
+
+// Warnf logs a warning, which is highlighted when logs are colored. Every condition ccoctl warns about is logged
+// through Warnf, so that the command fails with --strict once it completes.
+func Warnf(format string, v ...interface{}) {
+	recordWarning()
+	log.Output(2, warningPrefix+fmt.Sprintf(format, v...))
+}
+
+// Debugf logs a debug message when debug logging is enabled with --debug
+func Debugf(format string, v ...interface{}) {
+	if !debugEnabled {
+		return
+	}
+	log.Output(2, debugPrefix+fmt.Sprintf(format, v...))
+}
+
+// NewColorWriter returns a writer for the standard logger which colors the lines logged by log.Fatal and log.Panic
+// red and the warnings logged by Warnf yellow before writing them to out.
+func NewColorWriter(out io.Writer) io.Writer {
+	return &colorWriter{out: out}
+}
+
+// colorWriter is only written to by the standard logger, which serializes writes
+type colorWriter struct {
+	out io.Writer
+}
+
+func (w *colorWriter) Write(p []byte) (int, error) {
+	color := ""
+	switch {
+	case loggedByFatalOrPanic():
+		color = colorRed
+	case bytes.Contains(p, []byte(warningPrefix)):
+		color = colorYellow
+	}
+	if color == "" {
+		return w.out.Write(p)
+	}
+
+	// The logger writes a single line ending in a newline, which is kept outside of the colored text
+	line := bytes.TrimSuffix(p, []byte("\n"))
+	colored := make([]byte, 0, len(p)+len(color)+len(colorReset))
+	colored = append(colored, color...)
+	colored = append(colored, line...)
+	colored = append(colored, colorReset...)
+	colored = append(colored, p[len(line):]...)
+
+	if _, err := w.out.Write(colored); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// loggedByFatalOrPanic returns true when the line being written was logged by one of the Fatal or Panic functions
+// of the log package, which ccoctl uses to report the error it exits with.
+func loggedByFatalOrPanic() bool {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		function := strings.TrimPrefix(frame.Function, "log.(*Logger).")
+		function = strings.TrimPrefix(function, "log.")
+		if frame.Function != function && (strings.HasPrefix(function, "Fatal") || strings.HasPrefix(function, "Panic")) {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package provisioning
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.New(NewColorWriter(buf), "", 0)
+
+	logger.Print("Created resource")
+	assert.Equal(t, "Created resource\n", buf.String(), "expected informational line not to be colored")
+
+	buf.Reset()
+	logger.Print(warningPrefix + "quota check skipped")
+	assert.Equal(t, colorYellow+warningPrefix+"quota check skipped"+colorReset+"\n", buf.String(), "expected warning to be colored yellow")
+
+	buf.Reset()
+	require.Panics(t, func() { logger.Panic("failed to create resource") })
+	assert.Equal(t, colorRed+"failed to create resource"+colorReset+"\n", buf.String(), "expected error to be colored red")
+}
+
+func TestColorEnabled(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	require.NoError(t, err, "failed to create temp file")
+	defer f.Close()
+
+	assert.False(t, ColorEnabled(true, f), "expected no color with --no-color")
+	assert.False(t, ColorEnabled(false, f), "expected no color when not writing to a terminal")
+	t.Setenv(noColorEnv, "1")
+	assert.False(t, ColorEnabled(false, f), "expected no color with NO_COLOR")
+}
+
+func TestDebugf(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	defer func() { debugEnabled = false }()
+
+	Debugf("requesting API version %s", "2023-01-31")
+	assert.Empty(t, buf.String(), "expected no debug message unless debug logging is enabled")
+
+	debugEnabled = true
+	Debugf("requesting API version %s", "2023-01-31")
+	assert.Contains(t, buf.String(), debugPrefix+"requesting API version 2023-01-31", "expected debug message to be logged")
+}
@@ -0,0 +1,429 @@
+package multicloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+const (
+	// inventoryFileName is the name of the file within the output directory in which the inventories of all
+	// providers are consolidated
+	inventoryFileName = "ccoctl-multicloud-inventory.json"
+
+	// credReqsDirName is the name of the directory within a provider's output directory to which the
+	// CredentialsRequests dispatched to the provider are written
+	credReqsDirName = "credrequests"
+)
+
+var (
+	// CreateAllOpts captures the options that affect creation of the generated objects across providers.
+	CreateAllOpts = options{}
+
+	// reservedProviderFlags are the flags of the providers' create-all commands which are set by
+	// "multicloud create-all" and may not be set in the --config file
+	reservedProviderFlags = []string{"credentials-requests-dir", "output-dir", "enable-tech-preview"}
+)
+
+// providerConfigs are the flags to pass to the create-all command of each provider, keyed by provider name
+type providerConfigs map[string]map[string]string
+
+// providerRun is a provider's create-all command prepared to process the CredentialsRequests dispatched to it
+type providerRun struct {
+	provider     cloudProvider
+	credReqs     []*credreqv1.CredentialsRequest
+	outputDir    string
+	createAllCmd *cobra.Command
+}
+
+// Inventory consolidates the inventories of the providers for which resources were created
+type Inventory struct {
+	Providers []*provisioning.Inventory `json:"providers"`
+}
+
+func createAllCmd(cmd *cobra.Command, args []string) {
+	configs, err := loadProviderConfigs(CreateAllOpts.ConfigFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	credReqs, err := provisioning.GetListOfCredentialsRequests(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview)
+	if err != nil {
+		log.Fatalf("Failed to process CredentialsRequests: %s", err)
+	}
+
+	credReqsByProvider, err := dispatchCredentialsRequests(credReqs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateProviderConfigs(credReqsByProvider, configs); err != nil {
+		log.Fatal(err)
+	}
+
+	// All providers share a single key pair, as the cluster signs service account tokens with a single key
+	if err := provisioning.CreateKeys(CreateAllOpts.TargetDir); err != nil {
+		log.Fatalf("Failed to create public/private key pair: %s", err)
+	}
+
+	// Prepare every provider before running any of them so that invalid configurations are reported
+	// before any cloud resources are created
+	runs := []*providerRun{}
+	for _, provider := range cloudProviders {
+		if len(credReqsByProvider[provider.name]) == 0 {
+			continue
+		}
+		run, err := prepareProviderRun(provider, credReqsByProvider[provider.name], configs[provider.name], CreateAllOpts.TargetDir, CreateAllOpts.EnableTechPreview)
+		if err != nil {
+			log.Fatal(err)
+		}
+		runs = append(runs, run)
+	}
+
+	// The providers' create-all commands exit ccoctl on failure. They are idempotent so that a failed run
+	// may be resumed by running "multicloud create-all" again. Up to --max-concurrent-clouds providers run at a time.
+	provisioning.RunCloudScopes(len(runs), func(i int) bool {
+		run := runs[i]
+		log.Printf("Creating resources for %d CredentialsRequests on %s", len(run.credReqs), run.provider.name)
+		run.createAllCmd.Run(run.createAllCmd, nil)
+		return true
+	})
+
+	inventory, err := consolidateInventories(CreateAllOpts.TargetDir, runs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, line := range summarize(runs, inventory) {
+		log.Print(line)
+	}
+}
+
+// loadProviderConfigs reads the flags to pass to each provider's create-all command from the YAML file at path,
+// eg.
+//
+//	aws:
+//	  name: mycluster
+//	  region: us-east-1
+//	gcp:
+//	  name: mycluster
+//	  project: my-project
+//	  region: us-east1
+func loadProviderConfigs(path string) (providerConfigs, error) {
+	configs, problems := decodeProviderConfigs(path)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return configs, nil
+}
+
+// decodeProviderConfigs reads the flags to pass to each provider's create-all command from the YAML file at path,
+// as documented by loadProviderConfigs. The configurations of the supported providers are returned along with a
+// problem for every unsupported provider and every flag which may not be set in the config file.
+func decodeProviderConfigs(path string) (providerConfigs, Problems) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Problems{{Source: path, Message: fmt.Sprintf("failed to read config file: %s", err)}}
+	}
+	raw := map[string]map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, Problems{{Source: path, Message: fmt.Sprintf("failed to decode config file: %s", err)}}
+	}
+
+	knownProviders := []string{}
+	for _, provider := range cloudProviders {
+		knownProviders = append(knownProviders, provider.name)
+	}
+	configs := providerConfigs{}
+	problems := Problems{}
+	for _, providerName := range sortedKeys(raw) {
+		if !contains(knownProviders, providerName) {
+			problems = append(problems, Problem{Source: path, Provider: providerName, Message: fmt.Sprintf("unsupported provider, supported providers are %v", knownProviders)})
+			continue
+		}
+		flags := raw[providerName]
+		configs[providerName] = map[string]string{}
+		for _, flagName := range sortedKeys(flags) {
+			if contains(reservedProviderFlags, flagName) {
+				problems = append(problems, Problem{Source: path, Provider: providerName, Field: flagName, Message: "set by ccoctl multicloud create-all and may not be set in the config file"})
+				continue
+			}
+			switch value := flags[flagName].(type) {
+			case string, bool, float64:
+				configs[providerName][flagName] = fmt.Sprint(value)
+			default:
+				problems = append(problems, Problem{Source: path, Provider: providerName, Field: flagName, Message: fmt.Sprintf("set to a %T, flags must be set to strings, numbers or booleans", value)})
+			}
+		}
+	}
+	return configs, problems
+}
+
+// dispatchCredentialsRequests groups the CredentialsRequests by the provider targeted by their ProviderSpec
+func dispatchCredentialsRequests(credReqs []*credreqv1.CredentialsRequest) (map[string][]*credreqv1.CredentialsRequest, error) {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create credReq codec")
+	}
+
+	credReqsByProvider := map[string][]*credreqv1.CredentialsRequest{}
+	unsupported := []string{}
+	for _, credReq := range credReqs {
+		provider, kind, err := providerOf(codec, credReq)
+		if err != nil {
+			return nil, err
+		}
+		if provider == nil {
+			unsupported = append(unsupported, fmt.Sprintf("%s/%s (%s)", credReq.Namespace, credReq.Name, kind))
+			continue
+		}
+		credReqsByProvider[provider.name] = append(credReqsByProvider[provider.name], credReq)
+	}
+	if len(unsupported) > 0 {
+		return nil, errors.Errorf("CredentialsRequests %s target providers which are not supported by ccoctl multicloud create-all", strings.Join(unsupported, ", "))
+	}
+	return credReqsByProvider, nil
+}
+
+// providerOf returns the provider targeted by the ProviderSpec of credReq along with the kind of the ProviderSpec.
+// The returned provider is nil when the kind is not supported by "multicloud create-all".
+func providerOf(codec *credreqv1.ProviderCodec, credReq *credreqv1.CredentialsRequest) (*cloudProvider, string, error) {
+	var unknown runtime.Unknown
+	if err := codec.DecodeProviderSpec(credReq.Spec.ProviderSpec, &unknown); err != nil {
+		return nil, "", errors.Wrapf(err, "failed to decode the provider spec of CredentialsRequest %s/%s", credReq.Namespace, credReq.Name)
+	}
+	for i := range cloudProviders {
+		if unknown.Kind == cloudProviders[i].providerSpecKind {
+			return &cloudProviders[i], unknown.Kind, nil
+		}
+	}
+	return nil, unknown.Kind, nil
+}
+
+// validateProviderConfigs ensures that a configuration was provided for every provider targeted by CredentialsRequests
+func validateProviderConfigs(credReqsByProvider map[string][]*credreqv1.CredentialsRequest, configs providerConfigs) error {
+	missing := []string{}
+	for _, provider := range cloudProviders {
+		credReqs := credReqsByProvider[provider.name]
+		if len(credReqs) == 0 {
+			if _, ok := configs[provider.name]; ok {
+				provisioning.Warnf("No CredentialsRequests target %s, ignoring its configuration", provider.name)
+			}
+			continue
+		}
+		if _, ok := configs[provider.name]; !ok {
+			names := []string{}
+			for _, credReq := range credReqs {
+				names = append(names, fmt.Sprintf("%s/%s", credReq.Namespace, credReq.Name))
+			}
+			missing = append(missing, fmt.Sprintf("%s, which is targeted by CredentialsRequests %s", provider.name, strings.Join(names, ", ")))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("no configuration was provided in the config file for %s", strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// prepareProviderRun writes the CredentialsRequests dispatched to the provider to its output directory, which
+// shares the key pair of targetDir, and parses and validates the flags of the provider's create-all command
+func prepareProviderRun(provider cloudProvider, credReqs []*credreqv1.CredentialsRequest, config map[string]string, targetDir string, enableTechPreview bool) (*providerRun, error) {
+	outputDir := filepath.Join(targetDir, provider.name)
+	credReqsDir := filepath.Join(outputDir, credReqsDirName)
+	if err := provisioning.EnsureDir(credReqsDir); err != nil {
+		return nil, errors.Wrapf(err, "failed to create directory for %s CredentialsRequests at %s", provider.name, credReqsDir)
+	}
+	if err := writeCredentialsRequests(credReqsDir, credReqs); err != nil {
+		return nil, err
+	}
+	for _, keyFile := range []string{provisioning.PrivateKeyFile, provisioning.PublicKeyFile} {
+		if err := copyFile(filepath.Join(targetDir, keyFile), filepath.Join(outputDir, keyFile)); err != nil {
+			return nil, errors.Wrapf(err, "failed to share key pair with %s", provider.name)
+		}
+	}
+
+	createAllCmd := provider.newCreateAllCmd()
+	if err := createAllCmd.ParseFlags(providerArgs(config, credReqsDir, outputDir, enableTechPreview)); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s configuration", provider.name)
+	}
+	if err := createAllCmd.ValidateRequiredFlags(); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s configuration", provider.name)
+	}
+	if createAllCmd.PersistentPreRun != nil {
+		createAllCmd.PersistentPreRun(createAllCmd, nil)
+	}
+
+	return &providerRun{
+		provider:     provider,
+		credReqs:     credReqs,
+		outputDir:    outputDir,
+		createAllCmd: createAllCmd,
+	}, nil
+}
+
+// providerArgs returns the command line arguments of a provider's create-all command, ordered by flag name
+func providerArgs(config map[string]string, credReqsDir, outputDir string, enableTechPreview bool) []string {
+	args := []string{
+		fmt.Sprintf("--credentials-requests-dir=%s", credReqsDir),
+		fmt.Sprintf("--output-dir=%s", outputDir),
+		fmt.Sprintf("--enable-tech-preview=%t", enableTechPreview),
+	}
+	for _, flagName := range sortedKeys(config) {
+		args = append(args, fmt.Sprintf("--%s=%s", flagName, config[flagName]))
+	}
+	return args
+}
+
+// writeCredentialsRequests writes each CredentialsRequest to its own manifest within dir
+func writeCredentialsRequests(dir string, credReqs []*credreqv1.CredentialsRequest) error {
+	for _, credReq := range credReqs {
+		data, err := yaml.Marshal(credReq)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode CredentialsRequest %s/%s", credReq.Namespace, credReq.Name)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", credReq.Namespace, credReq.Name))
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write CredentialsRequest %s/%s to %s", credReq.Namespace, credReq.Name, path)
+		}
+	}
+	return nil
+}
+
+// consolidateInventories writes the inventories of the providers to a single inventory within targetDir
+func consolidateInventories(targetDir string, runs []*providerRun) (*Inventory, error) {
+	inventory := &Inventory{Providers: []*provisioning.Inventory{}}
+	for _, run := range runs {
+		providerInventory, err := provisioning.LoadInventory(run.outputDir)
+		if err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			return nil, err
+		}
+		inventory.Providers = append(inventory.Providers, providerInventory)
+	}
+
+	data, err := json.MarshalIndent(inventory, "", "    ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode inventory")
+	}
+	path := filepath.Join(targetDir, inventoryFileName)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, errors.Wrapf(err, "failed to save inventory at path %s", path)
+	}
+	return inventory, nil
+}
+
+// summarize describes the CredentialsRequests processed and the resources recorded for each provider
+func summarize(runs []*providerRun, inventory *Inventory) []string {
+	lines := []string{}
+	for _, run := range runs {
+		resources := 0
+		for _, providerInventory := range inventory.Providers {
+			if providerInventory.Provider != run.provider.name {
+				continue
+			}
+			for _, step := range providerInventory.Steps {
+				resources += len(step.Resources)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s: processed %d CredentialsRequests, %d resources recorded, manifests written to %s",
+			run.provider.name, len(run.credReqs), resources, filepath.Join(run.outputDir, provisioning.ManifestsDirName)))
+	}
+	return lines
+}
+
+func copyFile(src, dest string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0600)
+}
+
+// sortedKeys returns the keys of m in lexical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// initEnvForCreateAllCmd will ensure the destination directory is ready to receive the generated
+// files, and will create the directory if necessary.
+func initEnvForCreateAllCmd(cmd *cobra.Command, args []string) {
+	if err := provisioning.ValidateCredentialsRequests(CreateAllOpts.CredRequestDirs, CreateAllOpts.EnableTechPreview); err != nil {
+		log.Fatal(err)
+	}
+
+	if CreateAllOpts.TargetDir == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			log.Fatalf("Failed to get current directory: %s", err)
+		}
+
+		CreateAllOpts.TargetDir = pwd
+	}
+
+	fPath, err := filepath.Abs(CreateAllOpts.TargetDir)
+	if err != nil {
+		log.Fatalf("Failed to resolve full path: %s", err)
+	}
+	CreateAllOpts.TargetDir = fPath
+
+	// create target dir if necessary
+	err = provisioning.EnsureDir(fPath)
+	if err != nil {
+		log.Fatalf("failed to create target directory at %s", fPath)
+	}
+
+	// create tls dir if necessary
+	tlsDir := filepath.Join(fPath, provisioning.TLSDirName)
+	err = provisioning.EnsureDir(tlsDir)
+	if err != nil {
+		log.Fatalf("failed to create tls directory at %s", tlsDir)
+	}
+}
+
+// NewCreateAllCmd provides the "create-all" subcommand
+func NewCreateAllCmd() *cobra.Command {
+	createAllCmd := &cobra.Command{
+		Use:              "create-all",
+		Short:            "Create all the required credentials objects across cloud providers",
+		Long:             "Dispatch each CredentialsRequest to the create-all command of the cloud provider targeted by its ProviderSpec and create the resources of all providers concurrently",
+		Run:              createAllCmd,
+		PersistentPreRun: initEnvForCreateAllCmd,
+	}
+
+	createAllCmd.PersistentFlags().StringSliceVar(&CreateAllOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests targeting several cloud providers to create credentials for (can be a filename)")
+	createAllCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.ConfigFile, "config", "", "YAML file configuring the flags of the create-all command of each cloud provider targeted by the CredentialsRequests, keyed by provider name (aws, azure or gcp)")
+	createAllCmd.MarkPersistentFlagRequired("config")
+	createAllCmd.PersistentFlags().StringVar(&CreateAllOpts.TargetDir, "output-dir", "", "Directory to place generated files, the files of each cloud provider are placed in a subdirectory named after the provider (defaults to current directory)")
+	createAllCmd.PersistentFlags().BoolVar(&CreateAllOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+
+	return createAllCmd
+}
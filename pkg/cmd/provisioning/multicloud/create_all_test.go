@@ -0,0 +1,221 @@
+package multicloud
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+)
+
+func TestLoadProviderConfigs(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expected    providerConfigs
+		expectError bool
+	}{
+		{
+			name: "Configs of several providers",
+			config: `
+aws:
+  name: test-aws
+  region: us-east-1
+  create-private-s3-bucket: true
+gcp:
+  name: test-gcp
+  project: test-project
+  service-accounts-quota: 200
+`,
+			expected: providerConfigs{
+				"aws": {"name": "test-aws", "region": "us-east-1", "create-private-s3-bucket": "true"},
+				"gcp": {"name": "test-gcp", "project": "test-project", "service-accounts-quota": "200"},
+			},
+		},
+		{
+			name:        "Unsupported provider",
+			config:      "ibmcloud:\n  name: test\n",
+			expectError: true,
+		},
+		{
+			name:        "Reserved flag",
+			config:      "aws:\n  output-dir: /tmp\n",
+			expectError: true,
+		},
+		{
+			name:        "Flag set to a list",
+			config:      "azure:\n  user-tags:\n  - a\n",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.config), 0600), "failed to write config file")
+
+			configs, err := loadProviderConfigs(path)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expected, configs, "unexpected provider configs")
+			}
+		})
+	}
+}
+
+func TestDispatchCredentialsRequests(t *testing.T) {
+	credReqs := []*credreqv1.CredentialsRequest{
+		testCredentialsRequest(t, "aws-one", &credreqv1.AWSProviderSpec{}),
+		testCredentialsRequest(t, "gcp-one", &credreqv1.GCPProviderSpec{}),
+		testCredentialsRequest(t, "aws-two", &credreqv1.AWSProviderSpec{}),
+		testCredentialsRequest(t, "azure-one", &credreqv1.AzureProviderSpec{}),
+	}
+
+	credReqsByProvider, err := dispatchCredentialsRequests(credReqs)
+	require.NoError(t, err, "unexpected error dispatching CredentialsRequests")
+	assert.Equal(t, []*credreqv1.CredentialsRequest{credReqs[0], credReqs[2]}, credReqsByProvider["aws"], "unexpected AWS CredentialsRequests")
+	assert.Equal(t, []*credreqv1.CredentialsRequest{credReqs[1]}, credReqsByProvider["gcp"], "unexpected GCP CredentialsRequests")
+	assert.Equal(t, []*credreqv1.CredentialsRequest{credReqs[3]}, credReqsByProvider["azure"], "unexpected Azure CredentialsRequests")
+
+	_, err = dispatchCredentialsRequests(append(credReqs, testCredentialsRequest(t, "nutanix-one", &credreqv1.NutanixProviderSpec{})))
+	require.Error(t, err, "expected error dispatching CredentialsRequests of unsupported providers")
+	assert.Contains(t, err.Error(), "test-namespace/nutanix-one (NutanixProviderSpec)", "expected unsupported CredentialsRequest to be named")
+}
+
+func TestValidateProviderConfigs(t *testing.T) {
+	credReqsByProvider := map[string][]*credreqv1.CredentialsRequest{
+		"aws": {testCredentialsRequest(t, "aws-one", &credreqv1.AWSProviderSpec{})},
+		"gcp": {testCredentialsRequest(t, "gcp-one", &credreqv1.GCPProviderSpec{})},
+	}
+
+	err := validateProviderConfigs(credReqsByProvider, providerConfigs{"aws": {}, "gcp": {}, "azure": {}})
+	assert.NoError(t, err, "unexpected error with configs for all targeted providers")
+
+	err = validateProviderConfigs(credReqsByProvider, providerConfigs{"aws": {}})
+	require.Error(t, err, "expected error without config for a targeted provider")
+	assert.Contains(t, err.Error(), "gcp, which is targeted by CredentialsRequests test-namespace/gcp-one", "expected missing provider to be named")
+	assert.NotContains(t, err.Error(), "aws", "expected configured provider not to be named")
+}
+
+func TestPrepareProviderRun(t *testing.T) {
+	targetDir := t.TempDir()
+	for _, keyFile := range []string{provisioning.PrivateKeyFile, provisioning.PublicKeyFile} {
+		require.NoError(t, os.WriteFile(filepath.Join(targetDir, keyFile), []byte(keyFile), 0600), "failed to write key file")
+	}
+
+	var parsed struct {
+		credReqsDir, outputDir, name string
+		enableTechPreview, preRun    bool
+	}
+	provider := cloudProvider{
+		name:             "test",
+		providerSpecKind: "AWSProviderSpec",
+		newCreateAllCmd: func() *cobra.Command {
+			cmd := &cobra.Command{
+				Use: "create-all",
+				PersistentPreRun: func(cmd *cobra.Command, args []string) {
+					parsed.preRun = true
+				},
+				Run: func(cmd *cobra.Command, args []string) {},
+			}
+			cmd.PersistentFlags().StringVar(&parsed.credReqsDir, "credentials-requests-dir", "", "")
+			cmd.PersistentFlags().StringVar(&parsed.outputDir, "output-dir", "", "")
+			cmd.PersistentFlags().BoolVar(&parsed.enableTechPreview, "enable-tech-preview", false, "")
+			cmd.PersistentFlags().StringVar(&parsed.name, "name", "", "")
+			cmd.MarkPersistentFlagRequired("name")
+			return cmd
+		},
+	}
+	credReqs := []*credreqv1.CredentialsRequest{testCredentialsRequest(t, "aws-one", &credreqv1.AWSProviderSpec{})}
+
+	_, err := prepareProviderRun(provider, credReqs, map[string]string{}, targetDir, false)
+	assert.Error(t, err, "expected error without required flag")
+	_, err = prepareProviderRun(provider, credReqs, map[string]string{"name": "test", "unknown": "value"}, targetDir, false)
+	assert.Error(t, err, "expected error with unknown flag")
+
+	run, err := prepareProviderRun(provider, credReqs, map[string]string{"name": "test-name"}, targetDir, true)
+	require.NoError(t, err, "unexpected error preparing provider")
+
+	outputDir := filepath.Join(targetDir, "test")
+	assert.Equal(t, outputDir, run.outputDir, "unexpected provider output directory")
+	assert.Equal(t, outputDir, parsed.outputDir, "unexpected --output-dir")
+	assert.Equal(t, filepath.Join(outputDir, credReqsDirName), parsed.credReqsDir, "unexpected --credentials-requests-dir")
+	assert.Equal(t, "test-name", parsed.name, "unexpected flag from config")
+	assert.True(t, parsed.enableTechPreview, "expected --enable-tech-preview to be passed")
+	assert.True(t, parsed.preRun, "expected provider validation to be run")
+
+	written, err := provisioning.GetListOfCredentialsRequests([]string{parsed.credReqsDir}, false)
+	require.NoError(t, err, "unexpected error reading dispatched CredentialsRequests")
+	require.Len(t, written, 1, "expected dispatched CredentialsRequest to be written")
+	assert.Equal(t, "aws-one", written[0].Name, "unexpected dispatched CredentialsRequest")
+
+	key, err := os.ReadFile(filepath.Join(outputDir, provisioning.PrivateKeyFile))
+	require.NoError(t, err, "expected key pair to be shared with provider")
+	assert.Equal(t, provisioning.PrivateKeyFile, string(key), "unexpected shared private key")
+}
+
+func TestConsolidateInventories(t *testing.T) {
+	targetDir := t.TempDir()
+	runs := []*providerRun{}
+	for _, provider := range []string{"aws", "gcp"} {
+		outputDir := filepath.Join(targetDir, provider)
+		require.NoError(t, os.MkdirAll(outputDir, 0700), "failed to create provider output directory")
+		runs = append(runs, &providerRun{
+			provider:  cloudProvider{name: provider},
+			credReqs:  []*credreqv1.CredentialsRequest{{}},
+			outputDir: outputDir,
+		})
+	}
+	providerInventory := provisioning.NewInventory(runs[0].outputDir, "aws", "test-name")
+	require.NoError(t, providerInventory.CompleteStep("create-roles",
+		provisioning.InventoryResource{Type: "IAMRole", Name: "one"},
+		provisioning.InventoryResource{Type: "IAMRole", Name: "two"},
+	), "failed to save provider inventory")
+
+	inventory, err := consolidateInventories(targetDir, runs)
+	require.NoError(t, err, "unexpected error consolidating inventories")
+	require.Len(t, inventory.Providers, 1, "expected only providers with an inventory to be consolidated")
+	assert.Equal(t, "aws", inventory.Providers[0].Provider, "unexpected consolidated inventory")
+	_, err = os.Stat(filepath.Join(targetDir, inventoryFileName))
+	assert.NoError(t, err, "expected consolidated inventory to be written")
+
+	assert.Equal(t, []string{
+		"aws: processed 1 CredentialsRequests, 2 resources recorded, manifests written to " + filepath.Join(targetDir, "aws", provisioning.ManifestsDirName),
+		"gcp: processed 1 CredentialsRequests, 0 resources recorded, manifests written to " + filepath.Join(targetDir, "gcp", provisioning.ManifestsDirName),
+	}, summarize(runs, inventory), "unexpected summary")
+}
+
+func testCredentialsRequest(t *testing.T, name string, providerSpec runtime.Object) *credreqv1.CredentialsRequest {
+	codec, err := credreqv1.NewCodec()
+	require.NoError(t, err, "failed to create codec")
+	encoded, err := codec.EncodeProviderSpec(providerSpec)
+	require.NoError(t, err, "failed to encode provider spec")
+	return &credreqv1.CredentialsRequest{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "cloudcredential.openshift.io/v1",
+			Kind:       "CredentialsRequest",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-namespace",
+		},
+		Spec: credreqv1.CredentialsRequestSpec{
+			SecretRef: corev1.ObjectReference{
+				Name:      name + "-secret",
+				Namespace: "test-secret-namespace",
+			},
+			ProviderSpec: encoded,
+		},
+	}
+}
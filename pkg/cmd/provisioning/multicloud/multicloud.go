@@ -0,0 +1,55 @@
+package multicloud
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/gcp"
+)
+
+type options struct {
+	TargetDir         string
+	CredRequestDirs   []string
+	ConfigFile        string
+	EnableTechPreview bool
+	Output            string
+}
+
+// cloudProvider is a provider whose create-all command is orchestrated by "multicloud create-all"
+type cloudProvider struct {
+	// name is the name of the provider's ccoctl subcommand, eg. "aws", which also names the provider's section
+	// of the --config file and its output directory
+	name string
+	// providerSpecKind is the kind of the ProviderSpec of the CredentialsRequests dispatched to the provider
+	providerSpecKind string
+	// newCreateAllCmd returns the provider's create-all command
+	newCreateAllCmd func() *cobra.Command
+	// validateProviderSpec validates the ProviderSpec of a CredentialsRequest dispatched to the provider against the
+	// rules of the provider and the flags of its configuration, without contacting the provider
+	validateProviderSpec func(codec *credreqv1.ProviderCodec, credReq *credreqv1.CredentialsRequest, config map[string]string) field.ErrorList
+}
+
+// cloudProviders are the providers to which CredentialsRequests are dispatched by the kind of their ProviderSpec
+var cloudProviders = []cloudProvider{
+	{name: "aws", providerSpecKind: "AWSProviderSpec", newCreateAllCmd: aws.NewCreateAllCmd, validateProviderSpec: validateAWSProviderSpec},
+	{name: "azure", providerSpecKind: "AzureProviderSpec", newCreateAllCmd: azure.NewCreateAllCmd, validateProviderSpec: validateAzureProviderSpec},
+	{name: "gcp", providerSpecKind: "GCPProviderSpec", newCreateAllCmd: gcp.NewCreateAllCmd, validateProviderSpec: validateGCPProviderSpec},
+}
+
+// NewMultiCloudCmd implements the "multicloud" subcommand for provisioning credentials across cloud providers
+func NewMultiCloudCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "multicloud",
+		Short: "Manage credentials objects across cloud providers",
+		Long:  "Creating cloud credentials objects for CredentialsRequests targeting several cloud providers",
+	}
+
+	cmd.AddCommand(NewCreateAllCmd())
+	cmd.AddCommand(NewValidateConfigCmd())
+
+	return cmd
+}
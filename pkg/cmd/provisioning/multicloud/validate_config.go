@@ -0,0 +1,325 @@
+package multicloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/azure"
+	"github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning/gcp"
+)
+
+const (
+	// outputText reports the problems found by "multicloud validate-config" through the logs written to stderr
+	outputText = "text"
+	// outputJSON writes the ValidationReport of "multicloud validate-config" to stdout as a single JSON document
+	outputJSON = "json"
+)
+
+var (
+	// ValidateConfigOpts captures the options that affect validation of the config file and CredentialsRequests.
+	ValidateConfigOpts = options{}
+
+	// providerSpecPath is the path of the ProviderSpec of a CredentialsRequest
+	providerSpecPath = field.NewPath("spec", "providerSpec")
+)
+
+// Problem is a single problem found validating the config file of "multicloud create-all" and the
+// CredentialsRequests it is run against
+type Problem struct {
+	// Source is the path of the config file or CredentialsRequest manifest, or the namespace/name of the
+	// CredentialsRequest, in which the problem was found
+	Source string `json:"source"`
+	// Document is the 1-based index of the document within a CredentialsRequest manifest, if greater than 1
+	Document int `json:"document,omitempty"`
+	// Provider is the cloud provider whose configuration or rules the problem concerns, if any
+	Provider string `json:"provider,omitempty"`
+	// Field is the flag of the provider's configuration or the field path within the CredentialsRequest, if any
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	parts := []string{p.Source}
+	if p.Document > 1 {
+		parts[0] = fmt.Sprintf("%s (document %d)", p.Source, p.Document)
+	}
+	for _, part := range []string{p.Provider, p.Field, p.Message} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Problems reports every problem found at once so that they may all be fixed before running ccoctl again
+type Problems []Problem
+
+func (p Problems) Error() string {
+	if len(p) == 1 {
+		return p[0].String()
+	}
+	lines := make([]string, 0, len(p)+1)
+	lines = append(lines, fmt.Sprintf("found %d problems:", len(p)))
+	for _, problem := range p {
+		lines = append(lines, "  "+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidationReport is the result of validating the config file against the CredentialsRequests
+type ValidationReport struct {
+	ConfigFile string `json:"configFile"`
+	// CredentialsRequests is the number of CredentialsRequests validated, it is 0 when their manifests are invalid
+	CredentialsRequests int      `json:"credentialsRequests"`
+	Valid               bool     `json:"valid"`
+	Problems            Problems `json:"problems"`
+}
+
+func validateConfigCmd(cmd *cobra.Command, args []string) {
+	report := validateConfig(ValidateConfigOpts.ConfigFile, ValidateConfigOpts.CredRequestDirs, ValidateConfigOpts.EnableTechPreview)
+
+	if ValidateConfigOpts.Output == outputJSON {
+		if err := writeValidationReport(os.Stdout, report); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, problem := range report.Problems {
+			log.Print(problem)
+		}
+	}
+	if !report.Valid {
+		log.Fatalf("Found %d problems validating config file %s against the CredentialsRequests", len(report.Problems), report.ConfigFile)
+	}
+	log.Printf("Config file %s is valid for %d CredentialsRequests", report.ConfigFile, report.CredentialsRequests)
+}
+
+// validateConfig validates the config file at configFile against the CredentialsRequests within credReqDirs and
+// the rules of the providers they target without contacting any cloud provider. The flags of each provider's
+// create-all command are parsed but the values are otherwise only validated by the create-all command itself.
+// Every problem is reported, except that the CredentialsRequests are not validated against the configuration when
+// their manifests are invalid.
+func validateConfig(configFile string, credReqDirs []string, enableTechPreview bool) *ValidationReport {
+	report := &ValidationReport{ConfigFile: configFile}
+	configs, problems := decodeProviderConfigs(configFile)
+	report.Problems = append(report.Problems, problems...)
+	for _, provider := range cloudProviders {
+		if config, ok := configs[provider.name]; ok {
+			report.Problems = append(report.Problems, validateProviderFlags(configFile, provider, config)...)
+		}
+	}
+
+	credReqs, problems := loadCredentialsRequests(credReqDirs, enableTechPreview)
+	report.Problems = append(report.Problems, problems...)
+	report.CredentialsRequests = len(credReqs)
+	if len(credReqs) > 0 {
+		report.Problems = append(report.Problems, validateCredentialsRequests(configFile, credReqs, configs)...)
+	}
+
+	report.Valid = len(report.Problems) == 0
+	return report
+}
+
+// loadCredentialsRequests loads the CredentialsRequests within credReqDirs, returning a problem for every invalid
+// manifest
+func loadCredentialsRequests(credReqDirs []string, enableTechPreview bool) ([]*credreqv1.CredentialsRequest, Problems) {
+	err := provisioning.ValidateCredentialsRequests(credReqDirs, enableTechPreview)
+	if manifestErrs, ok := err.(provisioning.ManifestErrors); ok {
+		problems := Problems{}
+		for _, manifestErr := range manifestErrs {
+			document := 0
+			if manifestErr.Document > 1 {
+				document = manifestErr.Document
+			}
+			if manifestErr.Err != nil {
+				problems = append(problems, Problem{Source: manifestErr.Path, Document: document, Message: manifestErr.Err.Error()})
+			}
+			for _, fieldErr := range manifestErr.Errors {
+				problems = append(problems, Problem{Source: manifestErr.Path, Document: document, Field: fieldErr.Field, Message: fieldErr.ErrorBody()})
+			}
+		}
+		return nil, problems
+	}
+	if err != nil {
+		return nil, Problems{{Source: strings.Join(credReqDirs, ", "), Message: err.Error()}}
+	}
+
+	credReqs, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
+	if err != nil {
+		return nil, Problems{{Source: strings.Join(credReqDirs, ", "), Message: err.Error()}}
+	}
+	return credReqs, nil
+}
+
+// validateProviderFlags returns a problem for every flag of config which is not a flag of the provider's create-all
+// command or whose value cannot be parsed
+func validateProviderFlags(configFile string, provider cloudProvider, config map[string]string) Problems {
+	problems := Problems{}
+	flags := provider.newCreateAllCmd().LocalFlags()
+	for _, flagName := range sortedKeys(config) {
+		flag := flags.Lookup(flagName)
+		if flag == nil {
+			problems = append(problems, Problem{Source: configFile, Provider: provider.name, Field: flagName,
+				Message: fmt.Sprintf("unknown flag of ccoctl %s create-all", provider.name)})
+			continue
+		}
+		if err := flag.Value.Set(config[flagName]); err != nil {
+			problems = append(problems, Problem{Source: configFile, Provider: provider.name, Field: flagName,
+				Message: fmt.Sprintf("invalid value %q: %s", config[flagName], err)})
+		}
+	}
+	return problems
+}
+
+// validateCredentialsRequests returns a problem for every CredentialsRequest which targets an unsupported provider
+// or does not satisfy the rules of the provider it targets, and for every targeted provider which is not configured
+// or whose configuration does not set a required flag
+func validateCredentialsRequests(configFile string, credReqs []*credreqv1.CredentialsRequest, configs providerConfigs) Problems {
+	codec, err := credreqv1.NewCodec()
+	if err != nil {
+		return Problems{{Source: configFile, Message: fmt.Sprintf("failed to create credReq codec: %s", err)}}
+	}
+
+	problems := Problems{}
+	credReqsByProvider := map[string][]*credreqv1.CredentialsRequest{}
+	for _, credReq := range credReqs {
+		source := fmt.Sprintf("%s/%s", credReq.Namespace, credReq.Name)
+		provider, kind, err := providerOf(codec, credReq)
+		if err != nil {
+			problems = append(problems, Problem{Source: source, Field: providerSpecPath.String(), Message: errors.Cause(err).Error()})
+			continue
+		}
+		if provider == nil {
+			problems = append(problems, Problem{Source: source, Field: providerSpecPath.Child("kind").String(),
+				Message: fmt.Sprintf("%s targets a provider which is not supported by ccoctl multicloud create-all", kind)})
+			continue
+		}
+		credReqsByProvider[provider.name] = append(credReqsByProvider[provider.name], credReq)
+	}
+
+	for _, provider := range cloudProviders {
+		providerCredReqs := credReqsByProvider[provider.name]
+		if len(providerCredReqs) == 0 {
+			continue
+		}
+		config, ok := configs[provider.name]
+		if !ok {
+			names := []string{}
+			for _, credReq := range providerCredReqs {
+				names = append(names, fmt.Sprintf("%s/%s", credReq.Namespace, credReq.Name))
+			}
+			problems = append(problems, Problem{Source: configFile, Provider: provider.name,
+				Message: fmt.Sprintf("no configuration was provided, which is required by CredentialsRequests %s", strings.Join(names, ", "))})
+			continue
+		}
+		problems = append(problems, missingRequiredFlags(configFile, provider, config)...)
+		if provider.validateProviderSpec == nil {
+			continue
+		}
+		for _, credReq := range providerCredReqs {
+			for _, fieldErr := range provider.validateProviderSpec(codec, credReq, config) {
+				problems = append(problems, Problem{Source: fmt.Sprintf("%s/%s", credReq.Namespace, credReq.Name), Provider: provider.name,
+					Field: fieldErr.Field, Message: fieldErr.ErrorBody()})
+			}
+		}
+	}
+	return problems
+}
+
+// missingRequiredFlags returns a problem for every required flag of the provider's create-all command which is
+// neither set by config nor by "multicloud create-all"
+func missingRequiredFlags(configFile string, provider cloudProvider, config map[string]string) Problems {
+	problems := Problems{}
+	provider.newCreateAllCmd().LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		required, ok := flag.Annotations[cobra.BashCompOneRequiredFlag]
+		if !ok || len(required) == 0 || required[0] != "true" || contains(reservedProviderFlags, flag.Name) {
+			return
+		}
+		if _, ok := config[flag.Name]; !ok {
+			problems = append(problems, Problem{Source: configFile, Provider: provider.name, Field: flag.Name,
+				Message: fmt.Sprintf("required flag of ccoctl %s create-all is not set", provider.name)})
+		}
+	})
+	return problems
+}
+
+// writeValidationReport writes report to w as indented JSON
+func writeValidationReport(w io.Writer, report *ValidationReport) error {
+	if report.Problems == nil {
+		report.Problems = Problems{}
+	}
+	data, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode validation report")
+	}
+	if _, err := fmt.Fprintln(w, provisioning.Redact(string(data))); err != nil {
+		return errors.Wrap(err, "failed to write validation report")
+	}
+	return nil
+}
+
+func validateAWSProviderSpec(codec *credreqv1.ProviderCodec, credReq *credreqv1.CredentialsRequest, config map[string]string) field.ErrorList {
+	spec := &credreqv1.AWSProviderSpec{}
+	if err := codec.DecodeProviderSpec(credReq.Spec.ProviderSpec, spec); err != nil {
+		return field.ErrorList{field.Invalid(providerSpecPath, "", err.Error())}
+	}
+	errs := aws.ValidateProviderSpec(spec, providerSpecPath)
+	if config["region"] != "" && config["skip-region-consistency-check"] != "true" {
+		errs = append(errs, aws.RegionConflicts(spec, strings.Split(config["region"], ","), providerSpecPath)...)
+	}
+	return errs
+}
+
+func validateAzureProviderSpec(codec *credreqv1.ProviderCodec, credReq *credreqv1.CredentialsRequest, config map[string]string) field.ErrorList {
+	spec := &credreqv1.AzureProviderSpec{}
+	if err := codec.DecodeProviderSpec(credReq.Spec.ProviderSpec, spec); err != nil {
+		return field.ErrorList{field.Invalid(providerSpecPath, "", err.Error())}
+	}
+	return azure.ValidateProviderSpec(spec, config["subscription-id"], providerSpecPath)
+}
+
+func validateGCPProviderSpec(codec *credreqv1.ProviderCodec, credReq *credreqv1.CredentialsRequest, config map[string]string) field.ErrorList {
+	spec := &credreqv1.GCPProviderSpec{}
+	if err := codec.DecodeProviderSpec(credReq.Spec.ProviderSpec, spec); err != nil {
+		return field.ErrorList{field.Invalid(providerSpecPath, "", err.Error())}
+	}
+	return gcp.ValidateProviderSpec(spec, config["project"], providerSpecPath)
+}
+
+// initEnvForValidateConfigCmd validates the output format
+func initEnvForValidateConfigCmd(cmd *cobra.Command, args []string) {
+	if ValidateConfigOpts.Output != outputText && ValidateConfigOpts.Output != outputJSON {
+		log.Fatalf("invalid --output %q, expected one of %s, %s", ValidateConfigOpts.Output, outputText, outputJSON)
+	}
+}
+
+// NewValidateConfigCmd provides the "validate-config" subcommand
+func NewValidateConfigCmd() *cobra.Command {
+	validateConfigCmd := &cobra.Command{
+		Use:              "validate-config",
+		Short:            "Validate the config file of create-all against the CredentialsRequests",
+		Long:             "Validate the config file of create-all against the CredentialsRequests and the rules of the cloud providers they target without contacting any cloud provider, reporting every problem found at once",
+		Run:              validateConfigCmd,
+		PersistentPreRun: initEnvForValidateConfigCmd,
+	}
+
+	validateConfigCmd.PersistentFlags().StringSliceVar(&ValidateConfigOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests targeting several cloud providers to validate the config file against (can be a filename)")
+	validateConfigCmd.MarkPersistentFlagRequired("credentials-requests-dir")
+	validateConfigCmd.PersistentFlags().StringVar(&ValidateConfigOpts.ConfigFile, "config", "", "YAML file configuring the flags of the create-all command of each cloud provider, as provided to create-all")
+	validateConfigCmd.MarkPersistentFlagRequired("config")
+	validateConfigCmd.PersistentFlags().BoolVar(&ValidateConfigOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into validating CredentialsRequests marked as tech-preview")
+	validateConfigCmd.Flags().StringVar(&ValidateConfigOpts.Output, "output", outputText, "Output format, either \"text\" or \"json\". With json a report listing every problem found is written to stdout. The command fails when any problem is found regardless of the output format")
+
+	return validateConfigCmd
+}
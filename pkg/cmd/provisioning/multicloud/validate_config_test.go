@@ -0,0 +1,209 @@
+package multicloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+func TestValidateConfig(t *testing.T) {
+	validConfig := `
+aws:
+  name: test-aws
+  region: us-east-1
+azure:
+  name: test-azure
+  region: eastus
+  subscription-id: 00000000-0000-0000-0000-000000000001
+  dnszone-resource-group-name: test-dns
+gcp:
+  name: test-gcp
+  project: test-project
+`
+	validCredReqs := []runtime.Object{
+		&credreqv1.AWSProviderSpec{StatementEntries: []credreqv1.StatementEntry{{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: "*"}}},
+		&credreqv1.AzureProviderSpec{RoleBindings: []credreqv1.RoleBinding{{Role: "Contributor"}, {Role: "b24988ac-6180-42a0-ab88-20f7382dd24c"}}},
+		&credreqv1.GCPProviderSpec{PredefinedRoles: []string{"roles/storage.admin", "projects/test-project/roles/custom"}, Permissions: []string{"compute.instances.get"}},
+	}
+
+	tests := []struct {
+		name             string
+		config           string
+		providerSpecs    []runtime.Object
+		manifest         string
+		expectedProblems []Problem
+	}{
+		{
+			name:          "Valid config",
+			config:        validConfig,
+			providerSpecs: validCredReqs,
+		},
+		{
+			name: "Invalid config",
+			config: `
+aws:
+  name: test-aws
+  regoin: us-east-1
+  max-session-duration: long
+gcp:
+  project: test-project
+  output-dir: /tmp
+ibmcloud:
+  name: test
+`,
+			providerSpecs: validCredReqs,
+			expectedProblems: []Problem{
+				{Provider: "gcp", Field: "output-dir", Message: "set by ccoctl multicloud create-all and may not be set in the config file"},
+				{Provider: "ibmcloud", Message: "unsupported provider, supported providers are [aws azure gcp]"},
+				{Provider: "aws", Field: "max-session-duration", Message: `invalid value "long": strconv.ParseInt: parsing "long": invalid syntax`},
+				{Provider: "aws", Field: "regoin", Message: "unknown flag of ccoctl aws create-all"},
+				{Provider: "aws", Field: "region", Message: "required flag of ccoctl aws create-all is not set"},
+				{Provider: "azure", Message: "no configuration was provided, which is required by CredentialsRequests test-namespace/cr-1"},
+				{Provider: "gcp", Field: "name", Message: "required flag of ccoctl gcp create-all is not set"},
+			},
+		},
+		{
+			name:   "CredentialsRequests breaking provider rules",
+			config: validConfig,
+			providerSpecs: []runtime.Object{
+				&credreqv1.AWSProviderSpec{StatementEntries: []credreqv1.StatementEntry{{Effect: "allow", Action: []string{"GetObject"}}}},
+				&credreqv1.AzureProviderSpec{RoleBindings: []credreqv1.RoleBinding{
+					{Role: ""},
+					{Role: "/providers/Microsoft.Authorization/roleDefinitions/contributor"},
+					{Role: "/subscriptions/00000000-0000-0000-0000-000000000002/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c"},
+				}},
+				&credreqv1.GCPProviderSpec{},
+				&credreqv1.GCPProviderSpec{PredefinedRoles: []string{"storage.admin", "projects/other-project/roles/custom"}, Permissions: []string{"compute"}},
+				&credreqv1.NutanixProviderSpec{},
+			},
+			expectedProblems: []Problem{
+				{Source: "test-namespace/cr-4", Field: "spec.providerSpec.kind", Message: "NutanixProviderSpec targets a provider which is not supported by ccoctl multicloud create-all"},
+				{Source: "test-namespace/cr-0", Provider: "aws", Field: "spec.providerSpec.statementEntries[0].effect", Message: `Unsupported value: "allow": supported values: "Allow", "Deny"`},
+				{Source: "test-namespace/cr-0", Provider: "aws", Field: "spec.providerSpec.statementEntries[0].action[0]", Message: `Invalid value: "GetObject": actions must be "*" or of the form <service>:<action>`},
+				{Source: "test-namespace/cr-0", Provider: "aws", Field: "spec.providerSpec.statementEntries[0].resource", Message: "Required value"},
+				{Source: "test-namespace/cr-1", Provider: "azure", Field: "spec.providerSpec.roleBindings[0].role", Message: "Required value: the name of a role or the ID of a role definition"},
+				{Source: "test-namespace/cr-1", Provider: "azure", Field: "spec.providerSpec.roleBindings[1].role",
+					Message: `Invalid value: "/providers/Microsoft.Authorization/roleDefinitions/contributor": role definition IDs must be of the form /subscriptions/<subscription-id>/providers/Microsoft.Authorization/roleDefinitions/<GUID>`},
+				{Source: "test-namespace/cr-1", Provider: "azure", Field: "spec.providerSpec.roleBindings[2].role",
+					Message: `Invalid value: "/subscriptions/00000000-0000-0000-0000-000000000002/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c": role definition is defined within subscription 00000000-0000-0000-0000-000000000002 which is not the subscription 00000000-0000-0000-0000-000000000001 within which roles are assigned`},
+				{Source: "test-namespace/cr-2", Provider: "gcp", Field: "spec.providerSpec.predefinedRoles", Message: "Required value: the roles or permissions to grant"},
+				{Source: "test-namespace/cr-3", Provider: "gcp", Field: "spec.providerSpec.predefinedRoles[0]",
+					Message: `Invalid value: "storage.admin": roles must be of the form roles/<role>, projects/<project>/roles/<role> or organizations/<organization>/roles/<role>`},
+				{Source: "test-namespace/cr-3", Provider: "gcp", Field: "spec.providerSpec.predefinedRoles[1]",
+					Message: `Invalid value: "projects/other-project/roles/custom": role is defined within project other-project which is not the project test-project within which roles are granted`},
+				{Source: "test-namespace/cr-3", Provider: "gcp", Field: "spec.providerSpec.permissions[0]", Message: `Invalid value: "compute": permissions must be of the form <service>.<resource>.<verb>`},
+			},
+		},
+		{
+			name: "CredentialsRequests naming another region",
+			config: `
+aws:
+  name: test-aws
+  region: us-east-1,eu-west-1
+`,
+			providerSpecs: []runtime.Object{
+				&credreqv1.AWSProviderSpec{StatementEntries: []credreqv1.StatementEntry{
+					{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:eu-west-1:123456789012:key/test"},
+					{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:us-west-2:123456789012:key/test"},
+				}},
+			},
+			expectedProblems: []Problem{
+				{Source: "test-namespace/cr-0", Provider: "aws", Field: "spec.providerSpec.statementEntries[1].resource",
+					Message: `Invalid value: "arn:aws:kms:us-west-2:123456789012:key/test": the resource is within region us-west-2, not one of the regions provided to --region, us-east-1, eu-west-1`},
+			},
+		},
+		{
+			name: "Region conflicts skipped",
+			config: `
+aws:
+  name: test-aws
+  region: us-east-1
+  skip-region-consistency-check: true
+`,
+			providerSpecs: []runtime.Object{
+				&credreqv1.AWSProviderSpec{StatementEntries: []credreqv1.StatementEntry{
+					{Effect: "Allow", Action: []string{"kms:Decrypt"}, Resource: "arn:aws:kms:us-west-2:123456789012:key/test"},
+				}},
+			},
+		},
+		{
+			name:          "Invalid CredentialsRequest manifest",
+			config:        validConfig,
+			providerSpecs: validCredReqs,
+			manifest:      "apiVersion: cloudcredential.openshift.io/v1\nkind: CredentialsRequest\nmetadata:\n  name: invalid\n",
+			expectedProblems: []Problem{
+				{Source: "invalid.yaml", Field: "spec.secretRef.name", Message: "Required value: the name of the secret to create"},
+				{Source: "invalid.yaml", Field: "spec.secretRef.namespace", Message: "Required value: the namespace of the secret to create"},
+				{Source: "invalid.yaml", Field: "spec.providerSpec", Message: "Required value: the permissions to grant"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			configFile := filepath.Join(dir, "config.yaml")
+			require.NoError(t, os.WriteFile(configFile, []byte(test.config), 0600), "failed to write config file")
+			credReqDir := filepath.Join(dir, "credrequests")
+			require.NoError(t, os.Mkdir(credReqDir, 0700), "failed to create CredentialsRequests directory")
+			for i, providerSpec := range test.providerSpecs {
+				name := fmt.Sprintf("cr-%d", i)
+				data, err := yaml.Marshal(testCredentialsRequest(t, name, providerSpec))
+				require.NoError(t, err, "failed to encode CredentialsRequest")
+				require.NoError(t, os.WriteFile(filepath.Join(credReqDir, name+".yaml"), data, 0600), "failed to write CredentialsRequest")
+			}
+			if test.manifest != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(credReqDir, "invalid.yaml"), []byte(test.manifest), 0600), "failed to write manifest")
+			}
+
+			report := validateConfig(configFile, []string{credReqDir}, false)
+
+			expectedProblems := Problems{}
+			for _, problem := range test.expectedProblems {
+				switch {
+				case problem.Source == "":
+					problem.Source = configFile
+				case problem.Source == "invalid.yaml":
+					problem.Source = filepath.Join(credReqDir, "invalid.yaml")
+				}
+				expectedProblems = append(expectedProblems, problem)
+			}
+			assert.Equal(t, expectedProblems, append(Problems{}, report.Problems...), "unexpected problems")
+			assert.Equal(t, len(expectedProblems) == 0, report.Valid, "unexpected validity")
+			if test.manifest == "" {
+				assert.Equal(t, len(test.providerSpecs), report.CredentialsRequests, "unexpected number of CredentialsRequests")
+			}
+		})
+	}
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	out := &bytes.Buffer{}
+	require.NoError(t, writeValidationReport(out, &ValidationReport{ConfigFile: "config.yaml", CredentialsRequests: 2, Valid: true}), "unexpected error writing report")
+	decoded := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded), "expected report to be a JSON document")
+	assert.Equal(t, map[string]interface{}{
+		"configFile":          "config.yaml",
+		"credentialsRequests": float64(2),
+		"valid":               true,
+		"problems":            []interface{}{},
+	}, decoded, "unexpected report")
+}
+
+func TestProblemsError(t *testing.T) {
+	problem := Problem{Source: "credreqs.yaml", Document: 2, Field: "spec.providerSpec", Message: "Required value"}
+	assert.EqualError(t, Problems{problem}, "credreqs.yaml (document 2): spec.providerSpec: Required value", "unexpected error of a single problem")
+	assert.EqualError(t, Problems{problem, {Source: "config.yaml", Provider: "aws", Field: "region", Message: "required flag of ccoctl aws create-all is not set"}},
+		"found 2 problems:\n  credreqs.yaml (document 2): spec.providerSpec: Required value\n  config.yaml: aws: region: required flag of ccoctl aws create-all is not set",
+		"unexpected error of several problems")
+}
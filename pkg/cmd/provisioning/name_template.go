@@ -0,0 +1,242 @@
+package provisioning
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/spf13/cobra"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+const (
+	// NameTemplateAWSIAMRole is the resource type of --name-template naming the IAM Roles created for the
+	// CredentialsRequests
+	NameTemplateAWSIAMRole = "aws-iam-role"
+	// NameTemplateAzureManagedIdentity is the resource type of --name-template naming the user-assigned managed
+	// identities created for the CredentialsRequests
+	NameTemplateAzureManagedIdentity = "azure-managed-identity"
+
+	// nameHashLength is the number of hexadecimal characters of the hash appended to rendered names to keep them
+	// unique
+	nameHashLength = 8
+)
+
+// NameTemplateResourceTypes are the resource types whose names may be templated with --name-template
+var NameTemplateResourceTypes = []string{NameTemplateAWSIAMRole, NameTemplateAzureManagedIdentity}
+
+var (
+	// nameTemplates are the templates of --name-template keyed by resource type
+	nameTemplates = map[string]*template.Template{}
+	// nameTemplateRegion is the --region of the running command, which is rendered as .Region
+	nameTemplateRegion string
+)
+
+// NameTemplateVars are the variables of the templates of --name-template
+type NameTemplateVars struct {
+	// ClusterName is the --name of the command
+	ClusterName string
+	// Component identifies the component of the CredentialsRequest as "<secret namespace>-<secret name>" after the
+	// secret it targets
+	Component string
+	// Namespace and SecretName are the namespace and the name of the secret targeted by the CredentialsRequest
+	Namespace  string
+	SecretName string
+	// Region is the --region of the command, empty for the commands without a region
+	Region string
+}
+
+// NameConstraints are the constraints of a cloud provider on the names of a resource type
+type NameConstraints struct {
+	// MaxLength is the maximum length of a name
+	MaxLength int
+	// Pattern matches the valid names
+	Pattern *regexp.Regexp
+	// Description describes the valid names, eg. "alphanumeric characters or any of _+=,.@-"
+	Description string
+}
+
+// InitNameTemplates parses the templates of --name-template, each of the form <resource type>=<Go template>. The
+// names of the resources of the types without a template are derived from --name.
+func InitNameTemplates(templates []string) error {
+	nameTemplates = map[string]*template.Template{}
+	for _, value := range templates {
+		resourceType, text, found := strings.Cut(value, "=")
+		if !found || text == "" {
+			return fmt.Errorf("invalid --name-template %q, expected <resource type>=<template>", value)
+		}
+		if !isNameTemplateResourceType(resourceType) {
+			return fmt.Errorf("invalid --name-template %q, unknown resource type %s, expected one of %s", value, resourceType, strings.Join(NameTemplateResourceTypes, ", "))
+		}
+		if _, found := nameTemplates[resourceType]; found {
+			return fmt.Errorf("invalid --name-template %q, the template of %s was already provided", value, resourceType)
+		}
+		tmpl, err := template.New(resourceType).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid --name-template %q: %s", value, err)
+		}
+		// Unknown variables are otherwise only reported once the template is executed
+		for field := range referredFields(tmpl) {
+			if _, found := reflect.TypeOf(NameTemplateVars{}).FieldByName(field); !found {
+				return fmt.Errorf("invalid --name-template %q, unknown variable .%s", value, field)
+			}
+		}
+		nameTemplates[resourceType] = tmpl
+	}
+	return nil
+}
+
+func isNameTemplateResourceType(resourceType string) bool {
+	for _, t := range NameTemplateResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// NameTemplateCommands records the --region of cmd and of its subcommands, when they have one, as the .Region of the
+// templates of --name-template before they run
+func NameTemplateCommands(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		NameTemplateCommands(subCmd)
+	}
+	if cmd.Run == nil {
+		return
+	}
+	run := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		nameTemplateRegion = ""
+		if flag := cmd.Flags().Lookup("region"); flag != nil {
+			nameTemplateRegion = flag.Value.String()
+		}
+		run(cmd, args)
+	}
+}
+
+// CredentialsRequestComponentTag returns the value of the tag identifying the component of cr on the cloud identity
+// created for it, "<secret namespace>/<secret name>", so that the identity may be matched to its component whatever
+// its name
+func CredentialsRequestComponentTag(cr *credreqv1.CredentialsRequest) string {
+	return cr.Spec.SecretRef.Namespace + "/" + cr.Spec.SecretRef.Name
+}
+
+// ComponentName returns the name of the resource of resourceType created for cr by ccoctl for clusterName, rendered
+// from the template of --name-template. defaultName is returned when no template was provided for resourceType.
+//
+// The rendered names are unique: a hash of the component is appended when the template refers to neither .Component
+// nor both .Namespace and .SecretName, and names longer than the MaxLength of constraints are shortened and suffixed
+// with a hash of the full name. An error is returned when the name is not valid according to constraints.
+func ComponentName(resourceType, clusterName string, cr *credreqv1.CredentialsRequest, constraints NameConstraints, defaultName string) (string, error) {
+	tmpl, found := nameTemplates[resourceType]
+	if !found {
+		return defaultName, nil
+	}
+	vars := NameTemplateVars{
+		ClusterName: clusterName,
+		Component:   cr.Spec.SecretRef.Namespace + "-" + cr.Spec.SecretRef.Name,
+		Namespace:   cr.Spec.SecretRef.Namespace,
+		SecretName:  cr.Spec.SecretRef.Name,
+		Region:      nameTemplateRegion,
+	}
+	return renderName(tmpl, vars, constraints)
+}
+
+// renderName renders tmpl with vars as a name following constraints
+func renderName(tmpl *template.Template, vars NameTemplateVars, constraints NameConstraints) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render the --name-template of %s for component %s: %s", tmpl.Name(), vars.Component, err)
+	}
+	name := buf.String()
+	if name == "" {
+		return "", fmt.Errorf("the --name-template of %s rendered an empty name for component %s", tmpl.Name(), vars.Component)
+	}
+
+	if !distinguishesComponents(tmpl) {
+		name += "-" + nameHash(vars.Namespace+"/"+vars.SecretName)
+	}
+	if constraints.MaxLength > 0 && len(name) > constraints.MaxLength {
+		name = name[:constraints.MaxLength-nameHashLength-1] + "-" + nameHash(name)
+	}
+	if constraints.Pattern != nil && !constraints.Pattern.MatchString(name) {
+		return "", fmt.Errorf("the --name-template of %s rendered the invalid name %q for component %s, names must consist of %s",
+			tmpl.Name(), name, vars.Component, constraints.Description)
+	}
+	return name, nil
+}
+
+// nameHash returns a hash of s which is short enough to be appended to names
+func nameHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:nameHashLength]
+}
+
+// distinguishesComponents returns true when tmpl refers to .Component or to both .Namespace and .SecretName, so that
+// it renders distinct names for distinct components
+func distinguishesComponents(tmpl *template.Template) bool {
+	fields := referredFields(tmpl)
+	return fields["Component"] || fields["Namespace"] && fields["SecretName"]
+}
+
+// referredFields returns the names of the fields referred to within tmpl and its associated templates
+func referredFields(tmpl *template.Template) map[string]bool {
+	fields := map[string]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			templateFields(t.Tree.Root, fields)
+		}
+	}
+	return fields
+}
+
+// templateFields adds the names of the fields referred to within node to fields
+func templateFields(node parse.Node, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			templateFields(child, fields)
+		}
+	case *parse.ActionNode:
+		templateFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			templateFields(cmd, fields)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			templateFields(arg, fields)
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) > 0 {
+			fields[n.Ident[0]] = true
+		}
+	case *parse.IfNode:
+		templateBranchFields(&n.BranchNode, fields)
+	case *parse.RangeNode:
+		templateBranchFields(&n.BranchNode, fields)
+	case *parse.WithNode:
+		templateBranchFields(&n.BranchNode, fields)
+	case *parse.TemplateNode:
+		templateFields(n.Pipe, fields)
+	}
+}
+
+func templateBranchFields(n *parse.BranchNode, fields map[string]bool) {
+	templateFields(n.Pipe, fields)
+	templateFields(n.List, fields)
+	templateFields(n.ElseList, fields)
+}
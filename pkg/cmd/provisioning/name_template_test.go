@@ -0,0 +1,194 @@
+package provisioning
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var testNameConstraints = NameConstraints{
+	MaxLength:   64,
+	Pattern:     regexp.MustCompile(`^[a-zA-Z0-9-]+$`),
+	Description: "alphanumeric characters or -",
+}
+
+func testNameTemplateCredentialsRequest(namespace, secretName string) *credreqv1.CredentialsRequest {
+	return &credreqv1.CredentialsRequest{
+		Spec: credreqv1.CredentialsRequestSpec{
+			SecretRef: corev1.ObjectReference{Namespace: namespace, Name: secretName},
+		},
+	}
+}
+
+func TestInitNameTemplates(t *testing.T) {
+	defer InitNameTemplates(nil)
+
+	tests := []struct {
+		name        string
+		templates   []string
+		expectError string
+	}{
+		{
+			name:      "No templates",
+			templates: nil,
+		},
+		{
+			name:      "Templates of each resource type",
+			templates: []string{"aws-iam-role={{.ClusterName}}-{{.Component}}", "azure-managed-identity=mi-{{.Namespace}}-{{.SecretName}}"},
+		},
+		{
+			name:        "Missing template",
+			templates:   []string{"aws-iam-role"},
+			expectError: "expected <resource type>=<template>",
+		},
+		{
+			name:        "Unknown resource type",
+			templates:   []string{"gcp-service-account={{.Component}}"},
+			expectError: "unknown resource type gcp-service-account",
+		},
+		{
+			name:        "Duplicate resource type",
+			templates:   []string{"aws-iam-role={{.Component}}", "aws-iam-role=role-{{.Component}}"},
+			expectError: "the template of aws-iam-role was already provided",
+		},
+		{
+			name:        "Malformed template",
+			templates:   []string{"aws-iam-role={{.Component"},
+			expectError: "invalid --name-template",
+		},
+		{
+			name:        "Unknown variable",
+			templates:   []string{"aws-iam-role={{.Cluster}}-{{.Component}}"},
+			expectError: "unknown variable .Cluster",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := InitNameTemplates(test.templates)
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestComponentName(t *testing.T) {
+	defer InitNameTemplates(nil)
+	cr := testNameTemplateCredentialsRequest("openshift-image-registry", "installer-cloud-credentials")
+
+	tests := []struct {
+		name        string
+		template    string
+		expectName  string
+		expectError string
+	}{
+		{
+			name:       "No template, default name",
+			expectName: "default-name",
+		},
+		{
+			name:       "Template distinguishing components with .Component",
+			template:   "{{.ClusterName}}-{{.Component}}",
+			expectName: "test-openshift-image-registry-installer-cloud-credentials",
+		},
+		{
+			name:       "Template distinguishing components with .Namespace and .SecretName",
+			template:   "{{.SecretName}}-{{slice .Namespace 10}}",
+			expectName: "installer-cloud-credentials-image-registry",
+		},
+		{
+			name:       "Template not distinguishing components, component hash appended",
+			template:   "{{.ClusterName}}-{{.Region}}",
+			expectName: "test-us-east-1-" + nameHash("openshift-image-registry/installer-cloud-credentials"),
+		},
+		{
+			name:       "Template referring to .Namespace only, component hash appended",
+			template:   "{{.Namespace}}",
+			expectName: "openshift-image-registry-" + nameHash("openshift-image-registry/installer-cloud-credentials"),
+		},
+		{
+			name:        "Template rendering invalid characters",
+			template:    "{{.ClusterName}}_{{.Component}}",
+			expectError: `invalid name "test_openshift-image-registry-installer-cloud-credentials"`,
+		},
+		{
+			name:        "Template rendering an empty name",
+			template:    `{{if eq .ClusterName "other"}}{{.Component}}{{end}}`,
+			expectError: "rendered an empty name",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var templates []string
+			if test.template != "" {
+				templates = []string{NameTemplateAWSIAMRole + "=" + test.template}
+			}
+			require.NoError(t, InitNameTemplates(templates), "unexpected error parsing templates")
+			nameTemplateRegion = "us-east-1"
+			defer func() { nameTemplateRegion = "" }()
+
+			name, err := ComponentName(NameTemplateAWSIAMRole, "test", cr, testNameConstraints, "default-name")
+			if test.expectError != "" {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectError, "unexpected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectName, name, "unexpected name")
+			assert.LessOrEqual(t, len(name), testNameConstraints.MaxLength, "name should not exceed the maximum length")
+		})
+	}
+}
+
+func TestComponentNameMaxLength(t *testing.T) {
+	defer InitNameTemplates(nil)
+	require.NoError(t, InitNameTemplates([]string{NameTemplateAzureManagedIdentity + "={{.Component}}"}), "unexpected error parsing templates")
+
+	// Names of the maximum length are not shortened
+	cr := testNameTemplateCredentialsRequest("ns", strings.Repeat("a", testNameConstraints.MaxLength-3))
+	name, err := ComponentName(NameTemplateAzureManagedIdentity, "test", cr, testNameConstraints, "")
+	require.NoError(t, err, "unexpected error")
+	assert.Equal(t, "ns-"+strings.Repeat("a", testNameConstraints.MaxLength-3), name, "unexpected name")
+
+	// Longer names are shortened and remain distinct
+	cr = testNameTemplateCredentialsRequest("ns", strings.Repeat("a", testNameConstraints.MaxLength-2))
+	name, err = ComponentName(NameTemplateAzureManagedIdentity, "test", cr, testNameConstraints, "")
+	require.NoError(t, err, "unexpected error")
+	assert.Len(t, name, testNameConstraints.MaxLength, "unexpected name length")
+	otherCR := testNameTemplateCredentialsRequest("ns", strings.Repeat("a", testNameConstraints.MaxLength-1))
+	otherName, err := ComponentName(NameTemplateAzureManagedIdentity, "test", otherCR, testNameConstraints, "")
+	require.NoError(t, err, "unexpected error")
+	assert.NotEqual(t, name, otherName, "shortened names of distinct components should differ")
+}
+
+func TestNameTemplateCommands(t *testing.T) {
+	defer func() { nameTemplateRegion = "" }()
+
+	var region string
+	rootCmd := &cobra.Command{Use: "root"}
+	regionCmd := &cobra.Command{Use: "region", Run: func(cmd *cobra.Command, args []string) { region = nameTemplateRegion }}
+	regionCmd.Flags().String("region", "", "")
+	noRegionCmd := &cobra.Command{Use: "no-region", Run: func(cmd *cobra.Command, args []string) { region = nameTemplateRegion }}
+	rootCmd.AddCommand(regionCmd, noRegionCmd)
+	NameTemplateCommands(rootCmd)
+
+	rootCmd.SetArgs([]string{"region", "--region", "eastus"})
+	require.NoError(t, rootCmd.Execute(), "unexpected error")
+	assert.Equal(t, "eastus", region, "expected .Region to be the --region of the command")
+
+	rootCmd.SetArgs([]string{"no-region"})
+	require.NoError(t, rootCmd.Execute(), "unexpected error")
+	assert.Empty(t, region, "expected .Region to be empty for commands without a region")
+}
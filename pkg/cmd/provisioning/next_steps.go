@@ -0,0 +1,238 @@
+package provisioning
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// clusterAuthenticationFile is the name of the manifest within the manifests directory which sets the issuer URL of
+// the cluster, as written by CreateClusterAuthentication
+const clusterAuthenticationFile = "cluster-authentication-02-config.yaml"
+
+var (
+	// installConfigOut is the writer of the install-config snippets of the next steps, it is nil unless the yaml
+	// output format is enabled
+	installConfigOut io.Writer
+	// installConfigSnippets is the number of install-config snippets written, the snippets of the providers of
+	// multicloud create-all being written as separate YAML documents
+	installConfigSnippets int
+)
+
+// NextSteps is the checklist of the steps remaining to install a cluster with the credentials created by ccoctl. It
+// is derived from the files written to the output directory by the create command which ran, so that it reflects
+// the paths and names chosen through its flags.
+type NextSteps struct {
+	// Provider is the cloud provider for which credentials were created, eg. "aws"
+	Provider string `json:"provider"`
+	// ManifestsDir is the directory containing the manifests to provide to the installer
+	ManifestsDir string `json:"manifestsDir"`
+	// Manifests are the names of the manifests within ManifestsDir
+	Manifests []string `json:"manifests"`
+	// OutputArchive is the archive into which the manifests were packaged, when --output-archive was provided
+	OutputArchive string `json:"outputArchive,omitempty"`
+	// IssuerURL is the issuer URL of the cluster set by the cluster authentication manifest
+	IssuerURL string `json:"issuerURL,omitempty"`
+	// TLSDir is the directory containing the private key signing service account tokens to provide to the installer
+	TLSDir string `json:"tlsDir"`
+	// PrivateKeyPath is the private key signing service account tokens, empty when it is not within the output
+	// directory, eg. when the key pair was provided through --public-key-file
+	PrivateKeyPath string `json:"privateKeyPath,omitempty"`
+	// PrivateKeyLocation describes where the private key is stored when it is not within the output directory
+	PrivateKeyLocation string `json:"privateKeyLocation,omitempty"`
+	// PublicKeyPath is the public key matching the private key, as uploaded to the OIDC issuer
+	PublicKeyPath string `json:"publicKeyPath"`
+	// SigningKeySize is the size in bits of the RSA key pair, omitted when the public key may not be read
+	SigningKeySize int `json:"signingKeySize,omitempty"`
+	// InstallConfig are the fields to set within install-config.yaml, keyed by path, eg. "credentialsMode"
+	InstallConfig map[string]string `json:"installConfig"`
+	// Checklist are the human-readable steps in the order that they should be performed
+	Checklist []string `json:"checklist"`
+}
+
+// NewNextSteps returns the next steps after creating the credentials of provider within outputDir, using the key
+// pair of which publicKeyPath is the public key
+func NewNextSteps(provider, outputDir, publicKeyPath string) (*NextSteps, error) {
+	manifestsDir := filepath.Join(outputDir, ManifestsDirName)
+	entries, err := os.ReadDir(manifestsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifests directory %s", manifestsDir)
+	}
+	manifests := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			manifests = append(manifests, entry.Name())
+		}
+	}
+	sort.Strings(manifests)
+
+	issuerURL, err := ClusterAuthenticationIssuerURL(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsDir := filepath.Join(outputDir, TLSDirName)
+	privateKeyPath := filepath.Join(tlsDir, BoundSAKeyFile)
+	if _, err := os.Stat(privateKeyPath); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "failed to stat private key %s", privateKeyPath)
+		}
+		privateKeyPath = ""
+	}
+
+	// The key pair was validated before the resources trusting it were created
+	signingKeySize, _ := SigningKeySize(publicKeyPath)
+
+	return &NextSteps{
+		Provider:       provider,
+		ManifestsDir:   manifestsDir,
+		Manifests:      manifests,
+		IssuerURL:      Redact(issuerURL),
+		TLSDir:         tlsDir,
+		PrivateKeyPath: privateKeyPath,
+		PublicKeyPath:  publicKeyPath,
+		SigningKeySize: signingKeySize,
+		InstallConfig:  map[string]string{"credentialsMode": "Manual"},
+	}, nil
+}
+
+// ClusterAuthenticationIssuerURL returns the issuer URL set by the cluster authentication manifest written to the
+// manifests directory of outputDir by CreateClusterAuthentication, or an empty string when there is no such manifest
+func ClusterAuthenticationIssuerURL(outputDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, ManifestsDirName, clusterAuthenticationFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to read cluster authentication manifest %s", clusterAuthenticationFile)
+	}
+	authentication := &configv1.Authentication{}
+	if err := sigsyaml.Unmarshal(data, authentication); err != nil {
+		return "", errors.Wrapf(err, "failed to parse cluster authentication manifest %s", clusterAuthenticationFile)
+	}
+	return authentication.Spec.ServiceAccountIssuer, nil
+}
+
+// checklist returns the human-readable steps of n in the order that they should be performed
+func (n *NextSteps) checklist() []string {
+	steps := []string{}
+	if n.OutputArchive != "" {
+		steps = append(steps, fmt.Sprintf("Extract the %s directory from the output archive %s", ManifestsDirName, n.OutputArchive))
+	}
+
+	fields := make([]string, 0, len(n.InstallConfig))
+	for field := range n.InstallConfig {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		steps = append(steps, fmt.Sprintf("Set %s: %s within install-config.yaml", field, n.InstallConfig[field]))
+	}
+
+	manifestsDir := n.ManifestsDir
+	if n.OutputArchive != "" {
+		manifestsDir = fmt.Sprintf("the %s directory extracted from %s", ManifestsDirName, n.OutputArchive)
+	}
+	steps = append(steps, fmt.Sprintf("Run 'openshift-install create manifests', then copy the %d manifests within %s (%s) to the manifests directory of the installation directory",
+		len(n.Manifests), manifestsDir, strings.Join(n.Manifests, ", ")))
+
+	if n.IssuerURL != "" {
+		steps = append(steps, fmt.Sprintf("Keep manifest %s, which sets the serviceAccountIssuer of the cluster to the issuer URL %s", clusterAuthenticationFile, n.IssuerURL))
+	}
+
+	bundledPrivateKey := filepath.Join(TLSDirName, BoundSAKeyFile)
+	switch {
+	case n.PrivateKeyPath != "":
+		steps = append(steps, fmt.Sprintf("Copy the %s directory %s, containing the private key %s, to the installation directory", TLSDirName, n.TLSDir, n.PrivateKeyPath))
+	case n.PrivateKeyLocation != "":
+		steps = append(steps, fmt.Sprintf("Restore the private key stored as %s to %s within the installation directory", n.PrivateKeyLocation, bundledPrivateKey))
+	default:
+		steps = append(steps, fmt.Sprintf("Copy the private key matching the public key %s to %s within the installation directory", n.PublicKeyPath, bundledPrivateKey))
+	}
+
+	steps = append(steps, "Run 'openshift-install create cluster'")
+	return steps
+}
+
+// InstallConfigSnippet returns the fields of install-config.yaml to set for the credentials of n as a YAML document,
+// eg. credentialsMode: Manual, ready to be merged into install-config.yaml. The document is preceded by comments
+// naming the provider and the issuer URL set by the cluster authentication manifest, which install-config.yaml has
+// no field for.
+func (n *NextSteps) InstallConfigSnippet() ([]byte, error) {
+	fields := map[string]interface{}{}
+	for path, value := range n.InstallConfig {
+		keys := strings.Split(path, ".")
+		parent := fields
+		for _, key := range keys[:len(keys)-1] {
+			child, found := parent[key]
+			if !found {
+				child = map[string]interface{}{}
+				parent[key] = child
+			}
+			childFields, ok := child.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("install-config field %s conflicts with field %s", path, key)
+			}
+			parent = childFields
+		}
+		if _, found := parent[keys[len(keys)-1]]; found {
+			return nil, fmt.Errorf("install-config field %s conflicts with its subfields", path)
+		}
+		parent[keys[len(keys)-1]] = value
+	}
+	data, err := sigsyaml.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal install-config snippet")
+	}
+
+	header := fmt.Sprintf("# install-config.yaml fields for the credentials created by ccoctl on %s\n", n.Provider)
+	if n.IssuerURL != "" {
+		header += fmt.Sprintf("# The serviceAccountIssuer %s is set by manifest %s\n", n.IssuerURL, clusterAuthenticationFile)
+	}
+	return append([]byte(header), data...), nil
+}
+
+// writeInstallConfigSnippet writes the install-config snippet of n to out, separated from any snippet written
+// previously as YAML documents
+func writeInstallConfigSnippet(out io.Writer, n *NextSteps) error {
+	snippet, err := n.InstallConfigSnippet()
+	if err != nil {
+		return err
+	}
+	if installConfigSnippets > 0 {
+		snippet = append([]byte("---\n"), snippet...)
+	}
+	if _, err := out.Write(snippet); err != nil {
+		return errors.Wrap(err, "failed to write install-config snippet")
+	}
+	installConfigSnippets++
+	return nil
+}
+
+// PrintNextSteps logs the checklist of n once credentials have been successfully created. When the json-stream
+// output format is enabled n is reported within the final summary event, and when the yaml output format is enabled
+// the install-config snippet of n is written to stdout.
+func PrintNextSteps(n *NextSteps) {
+	n.Checklist = n.checklist()
+	log.Printf("Next steps to install a cluster on %s:", n.Provider)
+	for i, step := range n.Checklist {
+		log.Printf("  %d. %s", i+1, step)
+	}
+	if stream != nil {
+		stream.addNextSteps(n)
+	}
+	if installConfigOut != nil {
+		if err := writeInstallConfigSnippet(installConfigOut, n); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package provisioning
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextSteps(t *testing.T) {
+	tests := []struct {
+		name            string
+		issuerURL       string
+		privateKey      bool
+		customize       func(n *NextSteps)
+		expectChecklist func(outputDir string) []string
+	}{
+		{
+			name:       "Outputs of the output directory",
+			issuerURL:  "https://test-oidc.s3.us-east-1.amazonaws.com",
+			privateKey: true,
+			expectChecklist: func(outputDir string) []string {
+				return []string{
+					"Set credentialsMode: Manual within install-config.yaml",
+					"Run 'openshift-install create manifests', then copy the 2 manifests within " + filepath.Join(outputDir, ManifestsDirName) +
+						" (cluster-authentication-02-config.yaml, openshift-image-registry-installer-cloud-credentials-credentials.yaml) to the manifests directory of the installation directory",
+					"Keep manifest cluster-authentication-02-config.yaml, which sets the serviceAccountIssuer of the cluster to the issuer URL https://test-oidc.s3.us-east-1.amazonaws.com",
+					"Copy the tls directory " + filepath.Join(outputDir, TLSDirName) + ", containing the private key " + filepath.Join(outputDir, TLSDirName, BoundSAKeyFile) + ", to the installation directory",
+					"Run 'openshift-install create cluster'",
+				}
+			},
+		},
+		{
+			name:       "Manifests within the output archive and provider specific install-config fields",
+			issuerURL:  "https://test.blob.core.windows.net/test",
+			privateKey: true,
+			customize: func(n *NextSteps) {
+				n.OutputArchive = "/archive/test.tar.gz"
+				n.InstallConfig["platform.azure.resourceGroupName"] = "test-install"
+			},
+			expectChecklist: func(outputDir string) []string {
+				return []string{
+					"Extract the manifests directory from the output archive /archive/test.tar.gz",
+					"Set credentialsMode: Manual within install-config.yaml",
+					"Set platform.azure.resourceGroupName: test-install within install-config.yaml",
+					"Run 'openshift-install create manifests', then copy the 2 manifests within the manifests directory extracted from /archive/test.tar.gz" +
+						" (cluster-authentication-02-config.yaml, openshift-image-registry-installer-cloud-credentials-credentials.yaml) to the manifests directory of the installation directory",
+					"Keep manifest cluster-authentication-02-config.yaml, which sets the serviceAccountIssuer of the cluster to the issuer URL https://test.blob.core.windows.net/test",
+					"Copy the tls directory " + filepath.Join(outputDir, TLSDirName) + ", containing the private key " + filepath.Join(outputDir, TLSDirName, BoundSAKeyFile) + ", to the installation directory",
+					"Run 'openshift-install create cluster'",
+				}
+			},
+		},
+		{
+			name: "Private key stored outside of the output directory",
+			customize: func(n *NextSteps) {
+				n.PrivateKeyLocation = "secret test-signing-key within key vault https://test.vault.azure.net"
+			},
+			expectChecklist: func(outputDir string) []string {
+				return []string{
+					"Set credentialsMode: Manual within install-config.yaml",
+					"Run 'openshift-install create manifests', then copy the 1 manifests within " + filepath.Join(outputDir, ManifestsDirName) +
+						" (openshift-image-registry-installer-cloud-credentials-credentials.yaml) to the manifests directory of the installation directory",
+					"Restore the private key stored as secret test-signing-key within key vault https://test.vault.azure.net to tls/bound-service-account-signing-key.key within the installation directory",
+					"Run 'openshift-install create cluster'",
+				}
+			},
+		},
+		{
+			name: "Key pair provided through --public-key-file",
+			expectChecklist: func(outputDir string) []string {
+				return []string{
+					"Set credentialsMode: Manual within install-config.yaml",
+					"Run 'openshift-install create manifests', then copy the 1 manifests within " + filepath.Join(outputDir, ManifestsDirName) +
+						" (openshift-image-registry-installer-cloud-credentials-credentials.yaml) to the manifests directory of the installation directory",
+					"Copy the private key matching the public key /keys/custom.pub to tls/bound-service-account-signing-key.key within the installation directory",
+					"Run 'openshift-install create cluster'",
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(outputDir, ManifestsDirName), 0700))
+			require.NoError(t, os.MkdirAll(filepath.Join(outputDir, TLSDirName), 0700))
+			require.NoError(t, os.WriteFile(filepath.Join(outputDir, ManifestsDirName, "openshift-image-registry-installer-cloud-credentials-credentials.yaml"), []byte("kind: Secret"), 0600))
+			if test.issuerURL != "" {
+				require.NoError(t, CreateClusterAuthentication(test.issuerURL, outputDir, false))
+			}
+			if test.privateKey {
+				require.NoError(t, os.WriteFile(filepath.Join(outputDir, TLSDirName, BoundSAKeyFile), []byte("private key"), 0600))
+			}
+
+			nextSteps, err := NewNextSteps("test", outputDir, "/keys/custom.pub")
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.issuerURL, nextSteps.IssuerURL)
+			if test.customize != nil {
+				test.customize(nextSteps)
+			}
+			assert.Equal(t, test.expectChecklist(outputDir), nextSteps.checklist())
+		})
+	}
+}
+
+func TestNextStepsWithinSummaryEvent(t *testing.T) {
+	out := &bytes.Buffer{}
+	stream = testProgressStream(out)
+	defer func() { stream = nil }()
+
+	PrintNextSteps(&NextSteps{Provider: "aws", InstallConfig: map[string]string{"credentialsMode": "Manual"}})
+	PrintNextSteps(&NextSteps{Provider: "gcp", InstallConfig: map[string]string{"credentialsMode": "Manual"}})
+	FinishOutput(nil)
+
+	events := decodeProgressEvents(t, out)
+	require.Len(t, events, 1, "expected a single summary event")
+	require.Len(t, events[0].NextSteps, 2, "expected the next steps of every provider within the summary event")
+	assert.Equal(t, "aws", events[0].NextSteps[0].Provider)
+	assert.Equal(t, "gcp", events[0].NextSteps[1].Provider)
+	assert.NotEmpty(t, events[0].NextSteps[0].Checklist, "expected the checklist to be reported")
+}
+
+func TestInstallConfigSnippet(t *testing.T) {
+	tests := []struct {
+		name          string
+		nextSteps     *NextSteps
+		expectSnippet string
+		expectError   bool
+	}{
+		{
+			name:      "Credentials mode and issuer URL",
+			nextSteps: &NextSteps{Provider: "aws", IssuerURL: "https://test-oidc.s3.us-east-1.amazonaws.com", InstallConfig: map[string]string{"credentialsMode": "Manual"}},
+			expectSnippet: `# install-config.yaml fields for the credentials created by ccoctl on aws
+# The serviceAccountIssuer https://test-oidc.s3.us-east-1.amazonaws.com is set by manifest cluster-authentication-02-config.yaml
+credentialsMode: Manual
+`,
+		},
+		{
+			name: "Nested provider specific fields",
+			nextSteps: &NextSteps{Provider: "azure", InstallConfig: map[string]string{
+				"credentialsMode":                  "Manual",
+				"platform.azure.resourceGroupName": "test-install",
+			}},
+			expectSnippet: `# install-config.yaml fields for the credentials created by ccoctl on azure
+credentialsMode: Manual
+platform:
+  azure:
+    resourceGroupName: test-install
+`,
+		},
+		{
+			name: "Conflicting fields",
+			nextSteps: &NextSteps{Provider: "azure", InstallConfig: map[string]string{
+				"platform":                         "azure",
+				"platform.azure.resourceGroupName": "test-install",
+			}},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snippet, err := test.nextSteps.InstallConfigSnippet()
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectSnippet, string(snippet))
+		})
+	}
+}
+
+func TestNextStepsInstallConfigSnippets(t *testing.T) {
+	log.SetOutput(&bytes.Buffer{})
+	defer log.SetOutput(os.Stderr)
+	out := &bytes.Buffer{}
+	installConfigOut = out
+	defer func() { installConfigOut, installConfigSnippets = nil, 0 }()
+
+	PrintNextSteps(&NextSteps{Provider: "aws", InstallConfig: map[string]string{"credentialsMode": "Manual"}})
+	PrintNextSteps(&NextSteps{Provider: "gcp", InstallConfig: map[string]string{"credentialsMode": "Manual"}})
+
+	assert.Equal(t, `# install-config.yaml fields for the credentials created by ccoctl on aws
+credentialsMode: Manual
+---
+# install-config.yaml fields for the credentials created by ccoctl on gcp
+credentialsMode: Manual
+`, out.String(), "expected the snippet of every provider as separate YAML documents")
+}
@@ -4,11 +4,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"os"
 	"os/user"
 	"path/filepath"
+	"strings"
 
 	"github.com/nutanix-cloud-native/prism-go-client/environment/providers/kubernetes"
 	"github.com/pkg/errors"
@@ -25,7 +26,8 @@ const (
 	secretManifestsTemplate = `apiVersion: v1
 kind: Secret
 metadata:
-  name: %s
+  annotations:
+%s  name: %s
   namespace: %s
 type: Opaque
 data:
@@ -52,11 +54,12 @@ func createSharedSecretsCmd() *cobra.Command {
 		PersistentPreRun: initEnvForCreateCmd,
 	}
 
-	cmd.PersistentFlags().StringVar(&CreateSharedSecretsOpts.CredRequestDir, "credentials-requests-dir", "", "Directory containing files of CredentialsRequests (can be created by running 'oc adm release extract --credentials-requests --cloud=nutanix' against an OpenShift release image)")
+	cmd.PersistentFlags().StringSliceVar(&CreateSharedSecretsOpts.CredRequestDirs, "credentials-requests-dir", []string{}, "Directory containing files of CredentialsRequests (can be created by running 'oc adm release extract --credentials-requests --cloud=nutanix' against an OpenShift release image). May be specified multiple times, or as a comma-separated list, to merge CredentialsRequests from several directories")
 	cmd.MarkPersistentFlagRequired("credentials-requests-dir")
-	cmd.PersistentFlags().StringVar(&CreateSharedSecretsOpts.CredentialsSourceFilePath, "credentials-source-filepath", "", "The filepath of the nutanix credentials data. If not specified, will use the default path ~/.nutanix/credentials")
+	cmd.PersistentFlags().StringVar(&CreateSharedSecretsOpts.CredentialsSourceFilePath, "credentials-source-filepath", "", "The filepath of the nutanix credentials data. Use \"-\" to read the credentials data from stdin or \"env:NAME\" to read it from the environment variable NAME. If not specified, will use the default path ~/.nutanix/credentials")
 	cmd.PersistentFlags().StringVar(&CreateSharedSecretsOpts.TargetDir, "output-dir", "", "Directory to place generated files (defaults to current directory)")
 	cmd.PersistentFlags().BoolVar(&CreateSharedSecretsOpts.EnableTechPreview, "enable-tech-preview", false, "Opt into processing CredentialsRequests marked as tech-preview")
+	provisioning.AddAllowInsecureSecretsDirFlag(cmd.PersistentFlags(), &CreateSharedSecretsOpts.AllowInsecureSecretsDir)
 
 	return cmd
 }
@@ -74,32 +77,35 @@ func createSecretsCmd(cmd *cobra.Command, args []string) error {
 		filePath = filepath.Join(currentUser.HomeDir, ".nutanix", "credentials")
 	}
 
-	creds, err := getCredentialsFromFile(filePath)
+	creds, err := getCredentialsFromSource(filePath, cmd.InOrStdin())
 	if err != nil {
-		return errors.Wrapf(err, "credentials data read from file %s is invalid", filePath)
+		return errors.Wrapf(err, "credentials data read from %s is invalid", provisioning.DescribeCredentialsSource(filePath))
 	}
 
-	if err := createSecrets(CreateSharedSecretsOpts.CredRequestDir, CreateSharedSecretsOpts.TargetDir, creds, CreateSharedSecretsOpts.EnableTechPreview); err != nil {
+	if err := createSecrets(CreateSharedSecretsOpts.CredRequestDirs, CreateSharedSecretsOpts.TargetDir, creds, CreateSharedSecretsOpts.EnableTechPreview); err != nil {
 		return errors.Wrap(err, "failed to create credentials secrets")
 	}
 
 	return nil
 }
 
-// Retrieve the credentials data
-func getCredentialsFromFile(filePath string) (*kubernetes.NutanixCredentials, error) {
-	if _, err := os.Stat(filePath); err != nil {
-		return nil, errors.Wrapf(err, "source credentials file %s does not exist", filePath)
+// Retrieve the credentials data from a file, stdin or an environment variable, see provisioning.ReadCredentialsSource
+func getCredentialsFromSource(source string, stdin io.Reader) (*kubernetes.NutanixCredentials, error) {
+	if source != provisioning.CredentialsSourceStdin && !strings.HasPrefix(source, provisioning.CredentialsSourceEnvPrefix) {
+		if _, err := os.Stat(source); err != nil {
+			return nil, errors.Wrapf(err, "source credentials file %s does not exist", source)
+		}
 	}
 
-	bytes, err := ioutil.ReadFile(filePath)
+	bytes, err := provisioning.ReadCredentialsSource(source, stdin)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read the credentials file %s", filePath)
+		return nil, err
 	}
 
 	creds := &kubernetes.NutanixCredentials{}
 	if err = yaml.Unmarshal(bytes, creds); err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal the credentials data read from file %s", filePath)
+		// The error of the yaml decoder may quote the credentials data which must not be logged
+		return nil, fmt.Errorf("failed to unmarshal the credentials data read from %s", provisioning.DescribeCredentialsSource(source))
 	}
 
 	retCreds := &kubernetes.NutanixCredentials{}
@@ -131,14 +137,14 @@ func getCredentialsFromFile(filePath string) (*kubernetes.NutanixCredentials, er
 	return retCreds, nil
 }
 
-func createSecrets(credReqDir, targetDir string, creds *kubernetes.NutanixCredentials, enableTechPreview bool) error {
-	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDir, enableTechPreview)
+func createSecrets(credReqDirs []string, targetDir string, creds *kubernetes.NutanixCredentials, enableTechPreview bool) error {
+	credRequests, err := provisioning.GetListOfCredentialsRequests(credReqDirs, enableTechPreview)
 	if err != nil {
 		return errors.Wrap(err, "failed to process files containing CredentialsRequests")
 	}
 
 	if len(credRequests) == 0 {
-		return errors.Errorf("no CredentialsRequest manifests found in %q", credReqDir)
+		return errors.Errorf("no CredentialsRequest manifests found in %q", credReqDirs)
 	}
 
 	for _, cr := range credRequests {
@@ -162,8 +168,8 @@ func writeCredReqSecret(cr *credreqv1.CredentialsRequest, targetDir string, cred
 	}
 
 	b64CredsJson := base64.StdEncoding.EncodeToString(credsJsonBytes)
-	fileData := fmt.Sprintf(secretManifestsTemplate, cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace, b64CredsJson)
-	if err := ioutil.WriteFile(filePath, []byte(fileData), 0600); err != nil {
+	fileData := fmt.Sprintf(secretManifestsTemplate, provisioning.ProvenanceAnnotations(cr, ""), cr.Spec.SecretRef.Name, cr.Spec.SecretRef.Namespace, b64CredsJson)
+	if err := provisioning.WriteSecretFile(filePath, []byte(fileData)); err != nil {
 		return errors.Wrap(err, "failed to save secret manifest")
 	}
 
@@ -219,4 +225,6 @@ func initEnvForCreateCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		log.Fatalf("failed to create manifests directory at %s", manifestsDir)
 	}
+
+	provisioning.EnsureSecretsDir(manifestsDir, CreateSharedSecretsOpts.AllowInsecureSecretsDir)
 }
@@ -64,6 +64,8 @@ func TestCreateSharedSecrets(t *testing.T) {
 		setup       func(*testing.T) (credReqDir, targetDir, credentialsSourceFilepath string)
 		verify      func(*testing.T, string)
 		expectedErr string
+		// stdin is provided to the command as its standard input
+		stdin string
 	}{
 		{
 			name: "No CredentialsRequest manifests in directory",
@@ -182,6 +184,73 @@ func TestCreateSharedSecrets(t *testing.T) {
 			},
 			expectedErr: "source credentials file does/not/exist does not exist",
 		},
+		{
+			name: "Credentials data read from stdin",
+			setup: func(t *testing.T) (credReqDir, targetDir, credentialsSourceFilepath string) {
+				credReqDir, err := ioutil.TempDir(os.TempDir(), testCredReqDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory for credentials requests")
+				testCredentialsRequest(t, "credreq-test", "NutanixProviderSpec", "secret-ns", "secret-name", credReqDir)
+
+				targetDir, err = ioutil.TempDir(os.TempDir(), testTargetDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory for credentials requests")
+
+				credentialsSourceFilepath = "-"
+				return
+			},
+			stdin: getBasicAuthCredentials("stdinusername", "stdinpassword"),
+			verify: func(t *testing.T, manifestsDir string) {
+				files, err := ioutil.ReadDir(manifestsDir)
+				require.NoError(t, err, "unexpected error listing files in manifestsDir")
+				assert.Len(t, files, 1, "Should be exactly one files in manifestsDir when one CredReq to process")
+				contents := getSecretFromFileContents(t, filepath.Join(manifestsDir, files[0].Name()))
+				assert.Equal(t, "stdinusername", contents.PrismCentral.Username)
+				assert.Equal(t, "stdinpassword", contents.PrismCentral.Password)
+			},
+			expectedErr: "",
+		},
+		{
+			name: "Credentials data read from environment variable",
+			setup: func(t *testing.T) (credReqDir, targetDir, credentialsSourceFilepath string) {
+				credReqDir, err := ioutil.TempDir(os.TempDir(), testCredReqDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory for credentials requests")
+				testCredentialsRequest(t, "credreq-test", "NutanixProviderSpec", "secret-ns", "secret-name", credReqDir)
+
+				targetDir, err = ioutil.TempDir(os.TempDir(), testTargetDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory for credentials requests")
+
+				t.Setenv("NUTANIX_TEST_CREDENTIALS", getBasicAuthCredentials("envusername", "envpassword"))
+				credentialsSourceFilepath = "env:NUTANIX_TEST_CREDENTIALS"
+				return
+			},
+			verify: func(t *testing.T, manifestsDir string) {
+				files, err := ioutil.ReadDir(manifestsDir)
+				require.NoError(t, err, "unexpected error listing files in manifestsDir")
+				assert.Len(t, files, 1, "Should be exactly one files in manifestsDir when one CredReq to process")
+				contents := getSecretFromFileContents(t, filepath.Join(manifestsDir, files[0].Name()))
+				assert.Equal(t, "envusername", contents.PrismCentral.Username)
+				assert.Equal(t, "envpassword", contents.PrismCentral.Password)
+			},
+			expectedErr: "",
+		},
+		{
+			name: "Empty stdin",
+			setup: func(t *testing.T) (credReqDir, targetDir, credentialsSourceFilepath string) {
+				credReqDir, err := ioutil.TempDir(os.TempDir(), testCredReqDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory for credentials requests")
+
+				targetDir, err = ioutil.TempDir(os.TempDir(), testTargetDirPrefix)
+				require.NoError(t, err, "Failed to create temp directory for credentials requests")
+
+				credentialsSourceFilepath = "-"
+				return
+			},
+			verify: func(t *testing.T, manifestsDir string) {
+				files, err := ioutil.ReadDir(manifestsDir)
+				require.NoError(t, err, "unexpected error listing files in manifestsDir")
+				assert.Zero(t, len(files), "Should be no files in manifestsDir when no credentials data is provided")
+			},
+			expectedErr: "no credentials were provided on stdin",
+		},
 		{
 			name: "Non-existent credentials requests directory",
 			setup: func(t *testing.T) (credReqDir, targetDir, credentialsSourceFilepath string) {
@@ -237,6 +306,7 @@ func TestCreateSharedSecrets(t *testing.T) {
 			b := bytes.NewBufferString("")
 			cmd.SetOut(b)
 			cmd.SetErr(b)
+			cmd.SetIn(bytes.NewBufferString(tt.stdin))
 
 			cmd.SetArgs([]string{"create-shared-secrets",
 				"--credentials-requests-dir", credReqDir,
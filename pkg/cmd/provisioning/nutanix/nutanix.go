@@ -6,9 +6,10 @@ import (
 
 type options struct {
 	TargetDir                 string
-	CredRequestDir            string
+	CredRequestDirs           []string
 	CredentialsSourceFilePath string
 	EnableTechPreview         bool
+	AllowInsecureSecretsDir   bool
 }
 
 // NewNutanixCmd implements the "nutanix" subcommand for the credentials provisioning
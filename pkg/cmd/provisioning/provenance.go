@@ -0,0 +1,68 @@
+package provisioning
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+// The provenance annotations of the secret manifests generated by ccoctl trace a secret back to the run which
+// generated it. They are namespaced under the domain of the operator but prefixed with "ccoctl-", so that they are
+// not mistaken for the annotations with which the operator tracks the secrets it manages itself, eg.
+// cloudcredential.openshift.io/credentials-request.
+const (
+	// ProvenanceVersionAnnotation records the version of ccoctl which generated the secret manifest
+	ProvenanceVersionAnnotation = "cloudcredential.openshift.io/ccoctl-version"
+	// ProvenanceCredentialsRequestAnnotation records the namespace and name of the CredentialsRequest of the secret
+	ProvenanceCredentialsRequestAnnotation = "cloudcredential.openshift.io/ccoctl-credentials-request"
+	// ProvenanceIssuerURLAnnotation records the issuer URL trusted by the cloud identity of the secret
+	ProvenanceIssuerURLAnnotation = "cloudcredential.openshift.io/ccoctl-issuer-url"
+	// ProvenanceGeneratedAtAnnotation records the time at which the secret manifest was generated
+	ProvenanceGeneratedAtAnnotation = "cloudcredential.openshift.io/ccoctl-generated-at"
+
+	// sourceDateEpochEnv is the environment variable which, when set to a Unix timestamp, is recorded as the time at
+	// which secret manifests were generated so that the manifests of several runs may be reproduced identically
+	sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+)
+
+// runStartTime is the time at which ccoctl started, recorded within every secret manifest generated by the run
+var runStartTime = time.Now()
+
+// ProvenanceAnnotations returns the provenance annotations of the secret manifest generated for cr, rendered as the
+// entries of the annotations of a manifest's metadata, one per line. The issuer URL is only recorded when known.
+func ProvenanceAnnotations(cr *credreqv1.CredentialsRequest, issuerURL string) string {
+	annotations := map[string]string{
+		ProvenanceVersionAnnotation:            ccoctlVersion(),
+		ProvenanceCredentialsRequestAnnotation: fmt.Sprintf("%s/%s", cr.Namespace, cr.Name),
+		ProvenanceGeneratedAtAnnotation:        provenanceTime().UTC().Format(time.RFC3339),
+	}
+	if issuerURL != "" {
+		annotations[ProvenanceIssuerURLAnnotation] = issuerURL
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var rendered strings.Builder
+	for _, key := range keys {
+		// Values are double-quoted so that they are always read as strings, eg. a version which looks like a number
+		fmt.Fprintf(&rendered, "    %s: %s\n", key, strconv.Quote(annotations[key]))
+	}
+	return rendered.String()
+}
+
+// provenanceTime returns the time recorded within the secret manifests generated by the run, the time of
+// SOURCE_DATE_EPOCH when it is set to a valid Unix timestamp and otherwise the time at which ccoctl started
+func provenanceTime() time.Time {
+	if epoch, err := strconv.ParseInt(os.Getenv(sourceDateEpochEnv), 10, 64); err == nil {
+		return time.Unix(epoch, 0)
+	}
+	return runStartTime
+}
@@ -0,0 +1,76 @@
+package provisioning
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+func TestProvenanceAnnotations(t *testing.T) {
+	cr := &credreqv1.CredentialsRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "openshift-image-registry", Namespace: "openshift-cloud-credential-operator"},
+	}
+	manifestTemplate := `apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+%s  name: installer-cloud-credentials
+  namespace: openshift-image-registry
+type: Opaque`
+
+	tests := []struct {
+		name            string
+		issuerURL       string
+		sourceDateEpoch string
+		expected        map[string]string
+	}{
+		{
+			name:            "Issuer URL recorded",
+			issuerURL:       "https://test-oidc.s3.us-east-1.amazonaws.com",
+			sourceDateEpoch: "1700000000",
+			expected: map[string]string{
+				ProvenanceVersionAnnotation:            "unknown",
+				ProvenanceCredentialsRequestAnnotation: "openshift-cloud-credential-operator/openshift-image-registry",
+				ProvenanceIssuerURLAnnotation:          "https://test-oidc.s3.us-east-1.amazonaws.com",
+				ProvenanceGeneratedAtAnnotation:        "2023-11-14T22:13:20Z",
+			},
+		},
+		{
+			name:            "Unknown issuer URL not recorded",
+			sourceDateEpoch: "1700000000",
+			expected: map[string]string{
+				ProvenanceVersionAnnotation:            "unknown",
+				ProvenanceCredentialsRequestAnnotation: "openshift-cloud-credential-operator/openshift-image-registry",
+				ProvenanceGeneratedAtAnnotation:        "2023-11-14T22:13:20Z",
+			},
+		},
+		{
+			name:            "Start of the run recorded without a valid SOURCE_DATE_EPOCH",
+			sourceDateEpoch: "yesterday",
+			expected: map[string]string{
+				ProvenanceVersionAnnotation:            "unknown",
+				ProvenanceCredentialsRequestAnnotation: "openshift-cloud-credential-operator/openshift-image-registry",
+				ProvenanceGeneratedAtAnnotation:        runStartTime.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(sourceDateEpochEnv, test.sourceDateEpoch)
+			secret := &corev1.Secret{}
+			err := sigsyaml.UnmarshalStrict([]byte(fmt.Sprintf(manifestTemplate, ProvenanceAnnotations(cr, test.issuerURL))), secret)
+			require.NoError(t, err, "expected the annotated manifest to be a valid secret")
+			assert.Equal(t, test.expected, secret.Annotations, "unexpected annotations")
+			assert.NotContains(t, secret.Annotations, credreqv1.AnnotationCredentialsRequest, "expected the annotations of the operator not to be used")
+		})
+	}
+}
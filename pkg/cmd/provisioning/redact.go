@@ -0,0 +1,87 @@
+package provisioning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// secretPatterns match the secret material which may never be written to the output of ccoctl, ie. its logs, the
+// json-stream progress events, the configuration written by --dump-config, the records of --audit-log and the reports
+// written to stdout. The material to redact is the submatch named "secret", the remainder of a match is kept so that
+// the output still tells what was redacted.
+var secretPatterns = []*regexp.Regexp{
+	// PEM encoded private keys, eg. the private key signing service account tokens
+	regexp.MustCompile(`(?P<secret>-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----)`),
+	// JSON web tokens, eg. service account tokens
+	regexp.MustCompile(`(?P<secret>\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+)`),
+	// Bearer tokens of authorization headers
+	regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/=-]{8,})`),
+	// Signatures of shared access signatures and of pre-signed URLs
+	regexp.MustCompile(`(?i)[?&](?:sig|signature|x-amz-signature|x-goog-signature)=(?P<secret>[^&\s"']+)`),
+	// Passwords within the user information of URLs
+	regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:(?P<secret>[^/\s@]+)@`),
+	// Credentials within XML responses, eg. of the AWS STS API
+	regexp.MustCompile(`(?i)<(?:SecretAccessKey|SessionToken)>(?P<secret>[^<]+)<`),
+	// Values of sensitive keys, eg. aws_secret_access_key = ..., "client_secret": "..." or AccountKey=...
+	regexp.MustCompile(`(?i)\b[\w-]*(?:password|passwd|client[_-]?secret|secret[_-]?access[_-]?key|secret[_-]?key|api[_-]?key|account[_-]?key|private[_-]?key|access[_-]?token|refresh[_-]?token|session[_-]?token|id[_-]?token)["']?\s*[:=]\s*["']?(?P<secret>[^\s"'&,;]+)`),
+}
+
+// Redact returns s with the secret material it contains replaced by a fingerprint of the material, so that the
+// output of ccoctl may still be correlated, eg. to tell whether two runs used the same key, without revealing it.
+// Redact must be applied to anything ccoctl writes which is derived from values it did not define itself.
+func Redact(s string) string {
+	for _, pattern := range secretPatterns {
+		s = redactPattern(s, pattern)
+	}
+	return s
+}
+
+// redactPattern replaces the "secret" submatch of every match of pattern within s by its fingerprint
+func redactPattern(s string, pattern *regexp.Regexp) string {
+	group := pattern.SubexpIndex("secret")
+	matches := pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	var redacted strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 || strings.HasPrefix(s[start:end], redactedValue) {
+			continue
+		}
+		redacted.WriteString(s[last:start])
+		redacted.WriteString(RedactedFingerprint(s[start:end]))
+		last = end
+	}
+	redacted.WriteString(s[last:])
+	return redacted.String()
+}
+
+// RedactedFingerprint returns the value replacing secret within the output of ccoctl, eg.
+// REDACTED(sha256:2c26b46b68ffc68f), which identifies the secret without revealing it
+func RedactedFingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return redactedValue + "(sha256:" + hex.EncodeToString(sum[:8]) + ")"
+}
+
+// NewRedactingWriter returns a writer for the standard logger which redacts the secret material within the lines
+// logged before writing them to out
+func NewRedactingWriter(out io.Writer) io.Writer {
+	return &redactingWriter{out: out}
+}
+
+// redactingWriter is only written to by the standard logger, which writes a single line at a time
+type redactingWriter struct {
+	out io.Writer
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,296 @@
+package provisioning
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// FlowCreate is the flow of the commands which create or update resources, eg. create-all or rotate-signing-key
+	FlowCreate = "create"
+	// FlowDelete is the flow of the commands which delete resources, eg. delete or reconcile-delete
+	FlowDelete = "delete"
+	// flowAll selects the permissions required by every flow
+	flowAll = "all"
+
+	// requiredPermissionsOutputText writes the required permissions to stdout one per line
+	requiredPermissionsOutputText = "text"
+	// requiredPermissionsOutputPolicy writes the required permissions to stdout as a policy or role document of the
+	// cloud provider granting them
+	requiredPermissionsOutputPolicy = "policy"
+)
+
+// The flows of the client operations which are performed by either or both of the create and delete flows
+var (
+	CreateFlows          = []string{FlowCreate}
+	DeleteFlows          = []string{FlowDelete}
+	CreateAndDeleteFlows = []string{FlowCreate, FlowDelete}
+)
+
+// PrintRequiredPermissionsOpts captures the options of the "print-required-permissions" subcommands
+var PrintRequiredPermissionsOpts = struct {
+	Flow   string
+	Output string
+}{}
+
+// ClientOperation is an operation of a cloud provider client performed by ccoctl along with the permissions it
+// requires
+type ClientOperation struct {
+	// Operation is the method of the client, eg. "CreateRole" or "ResourceGroupsClient.Get"
+	Operation string
+	// Permissions are the permissions of the cloud provider required by the operation, eg. "iam:CreateRole". It is
+	// empty for operations which require no permission.
+	Permissions []string
+	// Flows are the flows performing the operation, FlowCreate and/or FlowDelete
+	Flows []string
+}
+
+// RequiredPermissions returns the sorted permissions required by the operations performed by flow, or by every flow
+// when flow is empty or "all"
+func RequiredPermissions(operations []ClientOperation, flow string) []string {
+	required := map[string]bool{}
+	for _, operation := range operations {
+		performed := flow == "" || flow == flowAll
+		for _, operationFlow := range operation.Flows {
+			performed = performed || operationFlow == flow
+		}
+		if !performed {
+			continue
+		}
+		for _, permission := range operation.Permissions {
+			required[permission] = true
+		}
+	}
+
+	permissions := make([]string, 0, len(required))
+	for permission := range required {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// writeRequiredPermissions writes the permissions required by the operations performed by flow to w, either one per
+// line or as the document returned by policyDocument
+func writeRequiredPermissions(w io.Writer, operations []ClientOperation, flow, output string, policyDocument func([]string) ([]byte, error)) error {
+	permissions := RequiredPermissions(operations, flow)
+	if output == requiredPermissionsOutputPolicy {
+		data, err := policyDocument(permissions)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	for _, permission := range permissions {
+		if _, err := fmt.Fprintln(w, permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePrintRequiredPermissionsOpts validates the flow and the output format of "print-required-permissions"
+func validatePrintRequiredPermissionsOpts(cmd *cobra.Command, args []string) {
+	switch PrintRequiredPermissionsOpts.Flow {
+	case FlowCreate, FlowDelete, flowAll:
+	default:
+		log.Fatalf("invalid --flow %q, expected one of %s, %s, %s", PrintRequiredPermissionsOpts.Flow, FlowCreate, FlowDelete, flowAll)
+	}
+	if PrintRequiredPermissionsOpts.Output != requiredPermissionsOutputText && PrintRequiredPermissionsOpts.Output != requiredPermissionsOutputPolicy {
+		log.Fatalf("invalid --output %q, expected one of %s, %s", PrintRequiredPermissionsOpts.Output, requiredPermissionsOutputText, requiredPermissionsOutputPolicy)
+	}
+}
+
+// NewPrintRequiredPermissionsCmd provides the "print-required-permissions" subcommand of provider, which prints the
+// permissions required by operations, the operations of the cloud provider client performed by ccoctl. With
+// --output=policy the permissions are printed as the document returned by policyDocument.
+func NewPrintRequiredPermissionsCmd(provider, policyDescription string, operations []ClientOperation, policyDocument func([]string) ([]byte, error)) *cobra.Command {
+	printRequiredPermissionsCmd := &cobra.Command{
+		Use:   "print-required-permissions",
+		Short: fmt.Sprintf("Print the %s permissions required by ccoctl", provider),
+		Long: fmt.Sprintf("Print the %s permissions required by the identity running ccoctl to create and delete resources, "+
+			"so that an identity with least privilege may be provisioned for ccoctl ahead of time", provider),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := writeRequiredPermissions(os.Stdout, operations, PrintRequiredPermissionsOpts.Flow, PrintRequiredPermissionsOpts.Output, policyDocument); err != nil {
+				log.Fatal(err)
+			}
+		},
+		PersistentPreRun: validatePrintRequiredPermissionsOpts,
+	}
+	printRequiredPermissionsCmd.PersistentFlags().StringVar(&PrintRequiredPermissionsOpts.Flow, "flow", flowAll,
+		fmt.Sprintf("Flow whose permissions are printed, either %q for the commands creating or updating resources, %q for the commands deleting them, or %q", FlowCreate, FlowDelete, flowAll))
+	printRequiredPermissionsCmd.PersistentFlags().StringVar(&PrintRequiredPermissionsOpts.Output, "output", requiredPermissionsOutputText,
+		fmt.Sprintf("Output format, either \"text\" to print one permission per line or \"policy\" to print %s granting the permissions", policyDescription))
+	return printRequiredPermissionsCmd
+}
+
+// packageFunction is a function declared at the top level of a package, along with the client operations it calls and
+// the functions it calls or refers to
+type packageFunction struct {
+	file       string
+	operations []string
+	references []string
+}
+
+// parsePackageFunctions adds the functions declared by the non-test sources of the package within dir to functions,
+// keyed by their names prefixed with prefix. The functions declared within other packages are referred to by the name
+// of their package followed by a dot and their name.
+func parsePackageFunctions(functions map[string]*packageFunction, dir, prefix string, operation func(receiver, method string) string) error {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the sources within %s", dir)
+	}
+
+	for _, pkg := range packages {
+		for path, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+				name := prefix + funcDecl.Name.Name
+				if funcDecl.Recv != nil || funcDecl.Name.Name == "init" {
+					// Methods are only referred to through their receivers and init functions are not referred to,
+					// they are performed by the flow of the file declaring them
+					name = fmt.Sprintf("%s%s:%s", prefix, filepath.Base(path), funcDecl.Name.Name)
+				}
+				function := &packageFunction{file: filepath.Base(path)}
+				functions[name] = function
+
+				var inspect func(node ast.Node) bool
+				inspect = func(node ast.Node) bool {
+					switch x := node.(type) {
+					case *ast.Ident:
+						function.references = append(function.references, prefix+x.Name)
+					case *ast.SelectorExpr:
+						if pkgIdent, ok := x.X.(*ast.Ident); ok {
+							function.references = append(function.references, pkgIdent.Name+"."+x.Sel.Name)
+						}
+						// The selected name is a field or method, to not be mistaken for a function of the package
+						ast.Inspect(x.X, inspect)
+						return false
+					case *ast.CallExpr:
+						selector, ok := x.Fun.(*ast.SelectorExpr)
+						if !ok {
+							return true
+						}
+						receiver := ""
+						switch recv := selector.X.(type) {
+						case *ast.Ident:
+							receiver = recv.Name
+						case *ast.SelectorExpr:
+							receiver = recv.Sel.Name
+						}
+						if op := operation(receiver, selector.Sel.Name); op != "" {
+							function.operations = append(function.operations, op)
+						}
+					}
+					return true
+				}
+				ast.Inspect(funcDecl.Body, inspect)
+			}
+		}
+	}
+	return nil
+}
+
+// ClientOperationCalls returns the flows performing each client operation called by the non-test sources of the
+// package within dir, so that the operations listed with their required permissions can be verified against the
+// code. operation returns the client operation of a call of method on receiver, the name of the variable or field on
+// which the method is called, or "" when the call is not a client operation. The client operations called by the
+// functions of the packages within dependencies, keyed by the name the package within dir imports them as, are
+// attributed to the functions of the package within dir calling them.
+//
+// The functions declared within deleteFlowFiles, and every function they refer to, are performed by FlowDelete. The
+// functions declared within the other files which no other function refers to, eg. the commands, and every function
+// they refer to outside of deleteFlowFiles are performed by FlowCreate, so that the helpers shared by both flows are
+// attributed to both.
+func ClientOperationCalls(dir string, dependencies map[string]string, operation func(receiver, method string) string, deleteFlowFiles ...string) (map[string][]string, error) {
+	deleteFlow := map[string]bool{}
+	for _, file := range deleteFlowFiles {
+		deleteFlow[file] = true
+	}
+
+	clientOperation := func(receiver, method string) string {
+		if _, found := dependencies[receiver]; found {
+			return ""
+		}
+		return operation(receiver, method)
+	}
+
+	functions := map[string]*packageFunction{}
+	if err := parsePackageFunctions(functions, dir, "", clientOperation); err != nil {
+		return nil, err
+	}
+	roots := map[string]*packageFunction{}
+	for name, function := range functions {
+		roots[name] = function
+	}
+	for pkgName, pkgDir := range dependencies {
+		if err := parsePackageFunctions(functions, pkgDir, pkgName+".", clientOperation); err != nil {
+			return nil, err
+		}
+	}
+
+	referred := map[string]bool{}
+	for name, function := range roots {
+		for _, reference := range function.references {
+			if reference != name {
+				referred[reference] = true
+			}
+		}
+	}
+
+	flows := map[string]map[string]bool{}
+	var perform func(name, flow string, visited map[string]bool)
+	perform = func(name, flow string, visited map[string]bool) {
+		function, found := functions[name]
+		if !found || visited[name] || (flow == FlowCreate && roots[name] != nil && deleteFlow[function.file]) {
+			return
+		}
+		visited[name] = true
+		for _, op := range function.operations {
+			if flows[op] == nil {
+				flows[op] = map[string]bool{}
+			}
+			flows[op][flow] = true
+		}
+		for _, reference := range function.references {
+			perform(reference, flow, visited)
+		}
+	}
+	createVisited, deleteVisited := map[string]bool{}, map[string]bool{}
+	for name, function := range roots {
+		switch {
+		case deleteFlow[function.file]:
+			perform(name, FlowDelete, deleteVisited)
+		case !referred[name]:
+			perform(name, FlowCreate, createVisited)
+		}
+	}
+
+	calls := map[string][]string{}
+	for op, opFlows := range flows {
+		for _, flow := range []string{FlowCreate, FlowDelete} {
+			if opFlows[flow] {
+				calls[op] = append(calls[op], flow)
+			}
+		}
+	}
+	return calls, nil
+}
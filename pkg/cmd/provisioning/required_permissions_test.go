@@ -0,0 +1,44 @@
+package provisioning
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredPermissions(t *testing.T) {
+	operations := []ClientOperation{
+		{Operation: "CreateRole", Permissions: []string{"iam:CreateRole", "iam:TagRole"}, Flows: CreateFlows},
+		{Operation: "GetRole", Permissions: []string{"iam:GetRole"}, Flows: CreateAndDeleteFlows},
+		{Operation: "DeleteRole", Permissions: []string{"iam:DeleteRole"}, Flows: DeleteFlows},
+		{Operation: "GetCallerIdentity", Flows: CreateAndDeleteFlows},
+	}
+
+	tests := []struct {
+		name     string
+		flow     string
+		expected []string
+	}{
+		{name: "create", flow: FlowCreate, expected: []string{"iam:CreateRole", "iam:GetRole", "iam:TagRole"}},
+		{name: "delete", flow: FlowDelete, expected: []string{"iam:DeleteRole", "iam:GetRole"}},
+		{name: "all", flow: flowAll, expected: []string{"iam:CreateRole", "iam:DeleteRole", "iam:GetRole", "iam:TagRole"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, RequiredPermissions(operations, test.flow), "unexpected permissions")
+		})
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeRequiredPermissions(out, operations, FlowDelete, requiredPermissionsOutputText, nil), "unexpected error")
+	assert.Equal(t, "iam:DeleteRole\niam:GetRole\n", out.String(), "unexpected text output")
+
+	out.Reset()
+	require.NoError(t, writeRequiredPermissions(out, operations, FlowDelete, requiredPermissionsOutputPolicy, func(permissions []string) ([]byte, error) {
+		return []byte(strings.Join(permissions, ",")), nil
+	}), "unexpected error")
+	assert.Equal(t, "iam:DeleteRole,iam:GetRole\n", out.String(), "unexpected policy output")
+}
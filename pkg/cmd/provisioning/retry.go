@@ -0,0 +1,72 @@
+package provisioning
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryPolicy is the policy with which the clients of cloud APIs retry the API calls which failed transiently, eg. as
+// they were throttled. The delay before a retry starts at BaseDelay and doubles with every retry up to MaxDelay.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a failed API call is retried, API calls are not retried when zero
+	MaxRetries int
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before a retry
+	MaxDelay time.Duration
+}
+
+// retryOverrides are the values of --max-retries, --retry-base-delay and --retry-max-delay set by InitRetryPolicy. A
+// negative MaxRetries and zero delays are not set and leave the default of the cloud provider in effect.
+var retryOverrides = RetryPolicy{MaxRetries: -1}
+
+// retriesDisabled is set by InitRetryPolicy when --disable-retries was provided
+var retriesDisabled bool
+
+// InitRetryPolicy overrides the retry policies of the cloud providers with the values of --max-retries,
+// --retry-base-delay and --retry-max-delay. A negative maxRetries and zero delays leave the default of the cloud
+// provider in effect. When disable is set, failed API calls are never retried.
+func InitRetryPolicy(maxRetries int, baseDelay, maxDelay time.Duration, disable bool) error {
+	switch {
+	case maxRetries < -1:
+		return fmt.Errorf("invalid --max-retries %d, the number of retries must not be negative", maxRetries)
+	case baseDelay < 0:
+		return fmt.Errorf("invalid --retry-base-delay %s, the delay must not be negative", baseDelay)
+	case maxDelay < 0:
+		return fmt.Errorf("invalid --retry-max-delay %s, the delay must not be negative", maxDelay)
+	case baseDelay > 0 && maxDelay > 0 && baseDelay > maxDelay:
+		return fmt.Errorf("invalid --retry-base-delay %s, the delay must not exceed --retry-max-delay %s", baseDelay, maxDelay)
+	case disable && maxRetries > 0:
+		return fmt.Errorf("--disable-retries may not be combined with --max-retries %d", maxRetries)
+	}
+	retryOverrides = RetryPolicy{
+		MaxRetries: maxRetries,
+		BaseDelay:  baseDelay,
+		MaxDelay:   maxDelay,
+	}
+	retriesDisabled = disable
+	return nil
+}
+
+// EffectiveRetryPolicy returns the retry policy of a cloud provider of which defaults is the default retry policy,
+// overridden by the values provided to InitRetryPolicy. The maximum delay is raised to the base delay when only
+// the base delay was overridden with a delay exceeding the default maximum delay.
+func EffectiveRetryPolicy(defaults RetryPolicy) RetryPolicy {
+	policy := defaults
+	if retryOverrides.MaxRetries >= 0 {
+		policy.MaxRetries = retryOverrides.MaxRetries
+	}
+	if retriesDisabled {
+		policy.MaxRetries = 0
+	}
+	if retryOverrides.BaseDelay > 0 {
+		policy.BaseDelay = retryOverrides.BaseDelay
+	}
+	if retryOverrides.MaxDelay > 0 {
+		policy.MaxDelay = retryOverrides.MaxDelay
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+	return policy
+}
@@ -0,0 +1,93 @@
+package provisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveRetryPolicy(t *testing.T) {
+	defer func() { retryOverrides = RetryPolicy{MaxRetries: -1}; retriesDisabled = false }()
+
+	defaults := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+	tests := []struct {
+		name        string
+		maxRetries  int
+		baseDelay   time.Duration
+		maxDelay    time.Duration
+		disable     bool
+		expected    RetryPolicy
+		expectError bool
+	}{
+		{
+			name:       "Defaults of the cloud provider",
+			maxRetries: -1,
+			expected:   defaults,
+		},
+		{
+			name:       "Every value overridden",
+			maxRetries: 2,
+			baseDelay:  100 * time.Millisecond,
+			maxDelay:   5 * time.Second,
+			expected:   RetryPolicy{MaxRetries: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second},
+		},
+		{
+			name:       "Zero retries",
+			maxRetries: 0,
+			expected:   RetryPolicy{MaxRetries: 0, BaseDelay: time.Second, MaxDelay: 30 * time.Second},
+		},
+		{
+			name:       "Retries disabled",
+			maxRetries: -1,
+			disable:    true,
+			expected:   RetryPolicy{MaxRetries: 0, BaseDelay: time.Second, MaxDelay: 30 * time.Second},
+		},
+		{
+			name:       "Maximum delay raised to a base delay exceeding the default maximum delay",
+			maxRetries: -1,
+			baseDelay:  time.Minute,
+			expected:   RetryPolicy{MaxRetries: 5, BaseDelay: time.Minute, MaxDelay: time.Minute},
+		},
+		{
+			name:        "Negative number of retries",
+			maxRetries:  -2,
+			expectError: true,
+		},
+		{
+			name:        "Negative delay",
+			maxRetries:  -1,
+			maxDelay:    -time.Second,
+			expectError: true,
+		},
+		{
+			name:        "Base delay exceeding the maximum delay",
+			maxRetries:  -1,
+			baseDelay:   time.Minute,
+			maxDelay:    time.Second,
+			expectError: true,
+		},
+		{
+			name:        "Retries disabled along with a number of retries",
+			maxRetries:  3,
+			disable:     true,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			retryOverrides = RetryPolicy{MaxRetries: -1}
+			retriesDisabled = false
+			err := InitRetryPolicy(test.maxRetries, test.baseDelay, test.maxDelay, test.disable)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				assert.Equal(t, defaults, EffectiveRetryPolicy(defaults), "expected the defaults to be left in effect")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expected, EffectiveRetryPolicy(defaults), "unexpected retry policy")
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package provisioning
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NextSigningKeySecretFile is the name of the manifest, within the manifests directory, of the secret from which
+	// the kube-apiserver-operator of a running cluster picks up the next service account signing key
+	NextSigningKeySecretFile = "next-bound-service-account-signing-key.yaml"
+
+	nextSigningKeySecretTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  name: next-bound-service-account-signing-key
+  namespace: openshift-kube-apiserver-operator
+type: Opaque
+data:
+  service-account.key: %s
+  service-account.pub: %s
+`
+)
+
+// RotateSigningKey generates a new service account signing key pair within outputDir and returns the JSON web key
+// set containing both the public key at previousPublicKeyPath and the new public key, so that tokens signed with
+// either key are trusted while the cluster cuts over to the new key. The key pair already within outputDir is reused
+// so that an interrupted rotation may be re-run, which fails if the key pair is the previous key pair.
+//
+// The new private key is written to the tls directory and to a manifest of the secret from which the
+// kube-apiserver-operator of a running cluster picks up the next signing key.
+func RotateSigningKey(outputDir, previousPublicKeyPath string) ([]byte, error) {
+	if err := CreateKeys(outputDir); err != nil {
+		return nil, errors.Wrap(err, "failed to create the new key pair")
+	}
+
+	publicKeyPath := filepath.Join(outputDir, PublicKeyFile)
+	jwks, err := buildJSONWebKeySet([]string{previousPublicKeyPath}, []string{publicKeyPath})
+	if err != nil {
+		return nil, errors.Wrapf(err, "the key pair within %s must differ from the previous signing key", outputDir)
+	}
+
+	if err := writeNextSigningKeySecret(outputDir); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+// FinalizeRotation returns the JSON web key set containing only the public key at publicKeyPath, the key to which the
+// cluster has cut over, to replace the JSON web key set published by RotateSigningKey once tokens signed with the
+// previous key are no longer in use.
+func FinalizeRotation(publicKeyPath string) ([]byte, error) {
+	return BuildJsonWebKeySet(publicKeyPath)
+}
+
+// writeNextSigningKeySecret writes the manifest of the secret holding the key pair within outputDir which the
+// kube-apiserver-operator uses as the next service account signing key
+func writeNextSigningKeySecret(outputDir string) error {
+	privateKey, err := os.ReadFile(filepath.Join(outputDir, PrivateKeyFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the new private key")
+	}
+	publicKey, err := os.ReadFile(filepath.Join(outputDir, PublicKeyFile))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the new public key")
+	}
+
+	secretPath := filepath.Join(outputDir, ManifestsDirName, NextSigningKeySecretFile)
+	fileData := fmt.Sprintf(nextSigningKeySecretTemplate, base64.StdEncoding.EncodeToString(privateKey), base64.StdEncoding.EncodeToString(publicKey))
+	if err := WriteSecretFile(secretPath, []byte(fileData)); err != nil {
+		return errors.Wrap(err, "failed to save the next signing key secret manifest")
+	}
+	log.Printf("Wrote the next signing key secret manifest at path %s, apply it to the cluster with 'oc apply -f %s'", secretPath, secretPath)
+	return nil
+}
+
+// InitRotationOutputDir returns the absolute path of outputDir, defaulting to the current directory, and ensures that
+// it and the manifests and tls directories within it exist to receive the files generated by a signing key rotation
+func InitRotationOutputDir(outputDir string) (string, error) {
+	if outputDir == "" {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get current directory")
+		}
+		outputDir = pwd
+	}
+
+	fPath, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve full path")
+	}
+	for _, dir := range []string{fPath, filepath.Join(fPath, ManifestsDirName), filepath.Join(fPath, TLSDirName)} {
+		if err := EnsureDir(dir); err != nil {
+			return "", errors.Wrapf(err, "failed to create directory at %s", dir)
+		}
+	}
+	return fPath, nil
+}
@@ -0,0 +1,53 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSigningKey(t *testing.T) {
+	previousDir, err := InitRotationOutputDir(filepath.Join(t.TempDir(), "previous"))
+	require.NoError(t, err, "failed to prepare previous output directory")
+	require.NoError(t, CreateKeys(previousDir), "failed to create previous key pair")
+	previousPublicKeyPath := filepath.Join(previousDir, PublicKeyFile)
+
+	outputDir, err := InitRotationOutputDir(filepath.Join(t.TempDir(), "rotation"))
+	require.NoError(t, err, "failed to prepare output directory")
+
+	jwks, err := RotateSigningKey(outputDir, previousPublicKeyPath)
+	require.NoError(t, err, "unexpected error rotating signing key")
+	keySet := JSONWebKeySet{}
+	require.NoError(t, json.Unmarshal(jwks, &keySet), "failed to decode JSON web key set")
+	require.Len(t, keySet.Keys, 2, "expected JSON web key set to contain the previous and the new key")
+	previousKeyID, newKeyID := keySet.Keys[0].KeyID, keySet.Keys[1].KeyID
+	assert.NotEqual(t, previousKeyID, newKeyID, "expected distinct key IDs")
+
+	previousKey, err := jsonWebKeyFromPublicKey(previousPublicKeyPath)
+	require.NoError(t, err, "failed to read previous public key")
+	assert.Equal(t, previousKey.KeyID, previousKeyID, "expected the previous key to keep its key ID")
+
+	secretPath := filepath.Join(outputDir, ManifestsDirName, NextSigningKeySecretFile)
+	info, err := os.Stat(secretPath)
+	require.NoError(t, err, "expected next signing key secret manifest to be written")
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "unexpected permissions of the secret manifest")
+	assert.FileExists(t, filepath.Join(outputDir, TLSDirName, BoundSAKeyFile), "expected new private key to be written to the tls directory")
+
+	rerunJWKS, err := RotateSigningKey(outputDir, previousPublicKeyPath)
+	require.NoError(t, err, "unexpected error re-running rotation")
+	assert.Equal(t, jwks, rerunJWKS, "expected a re-run rotation to reuse the new key pair")
+
+	_, err = RotateSigningKey(previousDir, previousPublicKeyPath)
+	assert.Error(t, err, "expected error rotating to the previous key pair")
+
+	finalJWKS, err := FinalizeRotation(filepath.Join(outputDir, PublicKeyFile))
+	require.NoError(t, err, "unexpected error finalizing rotation")
+	finalKeySet := JSONWebKeySet{}
+	require.NoError(t, json.Unmarshal(finalJWKS, &finalKeySet), "failed to decode JSON web key set")
+	require.Len(t, finalKeySet.Keys, 1, "expected JSON web key set to contain only the new key")
+	assert.Equal(t, newKeyID, finalKeySet.Keys[0].KeyID, "expected the new key to keep its key ID")
+}
@@ -0,0 +1,151 @@
+package provisioning
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinSigningKeySize is the minimum size in bits of the RSA keys signing service account tokens, smaller keys are
+	// rejected whether they are generated or provided
+	MinSigningKeySize = 2048
+	// DefaultSigningKeySize is the size in bits of the RSA keys generated by CreateKeys unless --signing-key-size is
+	// provided
+	DefaultSigningKeySize = 4096
+
+	// SigningKeySizeInventorySetting is the inventory setting recording the size in bits of the signing key
+	SigningKeySizeInventorySetting = "signingKeySize"
+)
+
+// supportedSigningKeySizes are the sizes in bits which may be required with --signing-key-size
+var supportedSigningKeySizes = []int{2048, 3072, 4096}
+
+// requiredSigningKeySize is the value of --signing-key-size set by InitSigningKeySize, zero when not provided
+var requiredSigningKeySize int
+
+// InitSigningKeySize requires the service account signing keys generated or provided to ccoctl to be RSA keys of size
+// bits, eg. to comply with a policy mandating 3072-bit keys. Keys of at least MinSigningKeySize bits are accepted when
+// size is zero.
+func InitSigningKeySize(size int) error {
+	if size != 0 {
+		supported := false
+		sizes := make([]string, 0, len(supportedSigningKeySizes))
+		for _, supportedSize := range supportedSigningKeySizes {
+			supported = supported || size == supportedSize
+			sizes = append(sizes, fmt.Sprint(supportedSize))
+		}
+		if !supported {
+			return fmt.Errorf("invalid --signing-key-size %d, the supported sizes are %s", size, strings.Join(sizes, ", "))
+		}
+	}
+	requiredSigningKeySize = size
+	return nil
+}
+
+// generatedSigningKeySize returns the size in bits of the RSA keys generated by CreateKeys
+func generatedSigningKeySize() int {
+	if requiredSigningKeySize != 0 {
+		return requiredSigningKeySize
+	}
+	return DefaultSigningKeySize
+}
+
+// validateSigningKey returns the size in bits of publicKey, the public key of a service account signing key described
+// by source in errors. An error is returned unless publicKey is an RSA key of at least MinSigningKeySize bits, and of
+// exactly the size required with --signing-key-size.
+func validateSigningKey(publicKey interface{}, source string) (int, error) {
+	rsaKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return 0, fmt.Errorf("%s uses the %s algorithm, only RSA service account signing keys are supported", source, keyAlgorithm(publicKey))
+	}
+	size := rsaKey.N.BitLen()
+	switch {
+	case size < MinSigningKeySize:
+		return 0, fmt.Errorf("%s is a %d-bit RSA key, service account signing keys must be at least %d bits", source, size, MinSigningKeySize)
+	case requiredSigningKeySize != 0 && size != requiredSigningKeySize:
+		return 0, fmt.Errorf("%s is a %d-bit RSA key, --signing-key-size requires %d-bit keys", source, size, requiredSigningKeySize)
+	}
+	return size, nil
+}
+
+// keyAlgorithm names the algorithm of publicKey in errors
+func keyAlgorithm(publicKey interface{}) string {
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return "ECDSA"
+	case ed25519.PublicKey:
+		return "Ed25519"
+	case *dsa.PublicKey:
+		return "DSA"
+	default:
+		return fmt.Sprintf("%T", publicKey)
+	}
+}
+
+// readPublicKey reads the PEM encoded public key at publicKeyPath
+func readPublicKey(publicKeyPath string) (interface{}, error) {
+	publicKeyContent, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read public key")
+	}
+
+	block, _ := pem.Decode(publicKeyContent)
+	if block == nil {
+		return nil, fmt.Errorf("error decoding PEM file %s", publicKeyPath)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing key content")
+	}
+	return publicKey, nil
+}
+
+// validatePrivateKeyFile validates the PEM encoded private key at privateKeyPath as a service account signing key and
+// returns its size in bits. The key is either a PKCS #1 RSA key, as generated by CreateKeys, or a PKCS #8 key.
+func validatePrivateKeyFile(privateKeyPath string) (int, error) {
+	data, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read private key %s", privateKeyPath)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return 0, fmt.Errorf("error decoding PEM file %s", privateKeyPath)
+	}
+
+	var publicKey interface{}
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		publicKey = &privateKey.PublicKey
+	} else {
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return 0, errors.Wrapf(err, "error parsing private key %s", privateKeyPath)
+		}
+		signer, ok := privateKey.(crypto.Signer)
+		if !ok {
+			return 0, fmt.Errorf("private key %s is of unsupported type %T", privateKeyPath, privateKey)
+		}
+		publicKey = signer.Public()
+	}
+	return validateSigningKey(publicKey, "private key "+privateKeyPath)
+}
+
+// SigningKeySize returns the size in bits of the RSA service account signing key of which publicKeyPath is the public
+// key, eg. to record it within the inventory
+func SigningKeySize(publicKeyPath string) (int, error) {
+	publicKey, err := readPublicKey(publicKeyPath)
+	if err != nil {
+		return 0, err
+	}
+	return validateSigningKey(publicKey, "public key "+publicKeyPath)
+}
@@ -0,0 +1,186 @@
+package provisioning
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitSigningKeySize(t *testing.T) {
+	t.Cleanup(func() { requiredSigningKeySize = 0 })
+
+	assert.ErrorContains(t, InitSigningKeySize(1024), "invalid --signing-key-size 1024, the supported sizes are 2048, 3072, 4096")
+	assert.ErrorContains(t, InitSigningKeySize(5000), "invalid --signing-key-size 5000")
+
+	require.NoError(t, InitSigningKeySize(3072), "unexpected error requiring a supported size")
+	assert.Equal(t, 3072, generatedSigningKeySize(), "expected the required size to be generated")
+
+	require.NoError(t, InitSigningKeySize(0), "unexpected error not requiring a size")
+	assert.Equal(t, DefaultSigningKeySize, generatedSigningKeySize(), "expected the default size to be generated")
+}
+
+func TestSigningKeySize(t *testing.T) {
+	writePublicKey := func(t *testing.T, publicKey interface{}) string {
+		der, err := x509.MarshalPKIXPublicKey(publicKey)
+		require.NoError(t, err, "failed to marshal test public key")
+		path := filepath.Join(t.TempDir(), PublicKeyFile)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+		return path
+	}
+	rsaKey := func(bits int) func(t *testing.T) interface{} {
+		return func(t *testing.T) interface{} {
+			privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+			require.NoError(t, err, "failed to generate test key")
+			return &privateKey.PublicKey
+		}
+	}
+
+	tests := []struct {
+		name         string
+		publicKey    func(t *testing.T) interface{}
+		requiredSize int
+		expectSize   int
+		expectError  string
+	}{
+		{
+			name:       "RSA key of the minimum size",
+			publicKey:  rsaKey(2048),
+			expectSize: 2048,
+		},
+		{
+			name:         "RSA key of the required size",
+			publicKey:    rsaKey(3072),
+			requiredSize: 3072,
+			expectSize:   3072,
+		},
+		{
+			name:        "RSA key smaller than the minimum size",
+			publicKey:   rsaKey(1024),
+			expectError: "is a 1024-bit RSA key, service account signing keys must be at least 2048 bits",
+		},
+		{
+			name:         "RSA key of another size than required",
+			publicKey:    rsaKey(2048),
+			requiredSize: 4096,
+			expectError:  "is a 2048-bit RSA key, --signing-key-size requires 4096-bit keys",
+		},
+		{
+			name: "ECDSA key",
+			publicKey: func(t *testing.T) interface{} {
+				privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				require.NoError(t, err, "failed to generate test key")
+				return &privateKey.PublicKey
+			},
+			expectError: "uses the ECDSA algorithm, only RSA service account signing keys are supported",
+		},
+		{
+			name: "Ed25519 key",
+			publicKey: func(t *testing.T) interface{} {
+				publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+				require.NoError(t, err, "failed to generate test key")
+				return publicKey
+			},
+			expectError: "uses the Ed25519 algorithm, only RSA service account signing keys are supported",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, InitSigningKeySize(test.requiredSize))
+			t.Cleanup(func() { requiredSigningKeySize = 0 })
+
+			publicKeyPath := writePublicKey(t, test.publicKey(t))
+			size, err := SigningKeySize(publicKeyPath)
+			if test.expectError != "" {
+				assert.ErrorContains(t, err, test.expectError)
+				_, err = BuildJsonWebKeySet(publicKeyPath)
+				assert.ErrorContains(t, err, test.expectError, "expected the key to be rejected from the JSON web key set")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectSize, size, "unexpected key size")
+			}
+		})
+	}
+}
+
+func TestValidatePrivateKeyFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "failed to generate test key")
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "failed to generate test key")
+	marshalPKCS8 := func(privateKey interface{}) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		require.NoError(t, err, "failed to marshal test key")
+		return der
+	}
+
+	tests := []struct {
+		name        string
+		block       *pem.Block
+		expectError string
+	}{
+		{
+			name:  "PKCS #1 RSA key",
+			block: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		},
+		{
+			name:  "PKCS #8 RSA key",
+			block: &pem.Block{Type: "PRIVATE KEY", Bytes: marshalPKCS8(rsaKey)},
+		},
+		{
+			name:        "PKCS #8 ECDSA key",
+			block:       &pem.Block{Type: "PRIVATE KEY", Bytes: marshalPKCS8(ecdsaKey)},
+			expectError: "uses the ECDSA algorithm",
+		},
+		{
+			name:        "Not a private key",
+			block:       &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("some data")},
+			expectError: "error parsing private key",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			privateKeyPath := filepath.Join(t.TempDir(), PrivateKeyFile)
+			require.NoError(t, os.WriteFile(privateKeyPath, pem.EncodeToMemory(test.block), 0600))
+
+			size, err := validatePrivateKeyFile(privateKeyPath)
+			if test.expectError != "" {
+				assert.ErrorContains(t, err, test.expectError)
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, 2048, size, "unexpected key size")
+			}
+		})
+	}
+}
+
+func TestRotateWeakSigningKey(t *testing.T) {
+	previousDir, err := InitRotationOutputDir(filepath.Join(t.TempDir(), "previous"))
+	require.NoError(t, err, "failed to prepare previous output directory")
+	require.NoError(t, CreateKeys(previousDir), "failed to create previous key pair")
+	previousPublicKeyPath := filepath.Join(previousDir, PublicKeyFile)
+
+	// The previous 4096-bit key no longer meets the required size, the rotation replaces it rather than failing
+	require.NoError(t, InitSigningKeySize(2048))
+	t.Cleanup(func() { requiredSigningKeySize = 0 })
+
+	outputDir, err := InitRotationOutputDir(filepath.Join(t.TempDir(), "rotation"))
+	require.NoError(t, err, "failed to prepare output directory")
+	_, err = RotateSigningKey(outputDir, previousPublicKeyPath)
+	require.NoError(t, err, "unexpected error rotating a signing key weaker than required")
+
+	size, err := SigningKeySize(filepath.Join(outputDir, PublicKeyFile))
+	require.NoError(t, err, "unexpected error reading the new public key")
+	assert.Equal(t, 2048, size, "expected the new key to be of the required size")
+}
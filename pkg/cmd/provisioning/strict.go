@@ -0,0 +1,53 @@
+package provisioning
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	// strictEnabled indicates that the warnings logged by Warnf fail the command, set by --strict
+	strictEnabled bool
+
+	// warningsMu guards warningCount, as warnings may be logged concurrently by operations running in parallel
+	warningsMu sync.Mutex
+	// warningCount is the number of warnings logged by Warnf since InitStrict
+	warningCount int
+)
+
+// InitStrict configures whether the warnings logged by Warnf are promoted to errors. When strict is set the command
+// still completes, so that resources are not left partially created or deleted, and StrictError then fails it when
+// any warning was logged.
+func InitStrict(strict bool) {
+	strictEnabled = strict
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warningCount = 0
+}
+
+// Strict returns true when warnings are promoted to errors with --strict. Checks whose warnings are logged before
+// any resource is changed, eg. the check of the permissions to delete resources, fail right away instead.
+func Strict() bool {
+	return strictEnabled
+}
+
+// recordWarning counts a warning logged by Warnf
+func recordWarning() {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warningCount++
+}
+
+// StrictError returns the error with which the command fails once it has completed when warnings were logged with
+// --strict, or nil otherwise
+func StrictError() error {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	if !strictEnabled || warningCount == 0 {
+		return nil
+	}
+	if warningCount == 1 {
+		return fmt.Errorf("1 warning was logged, failing as --strict was provided")
+	}
+	return fmt.Errorf("%d warnings were logged, failing as --strict was provided", warningCount)
+}
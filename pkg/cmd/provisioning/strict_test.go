@@ -0,0 +1,34 @@
+package provisioning
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictError(t *testing.T) {
+	log.SetOutput(&bytes.Buffer{})
+	defer log.SetOutput(os.Stderr)
+	defer InitStrict(false)
+
+	InitStrict(false)
+	Warnf("quota check skipped")
+	assert.NoError(t, StrictError(), "expected warnings not to fail the command without --strict")
+
+	InitStrict(true)
+	assert.NoError(t, StrictError(), "expected no error without warnings")
+	Warnf("quota check skipped")
+	err := StrictError()
+	require.Error(t, err, "expected warning to fail the command with --strict")
+	assert.Equal(t, "1 warning was logged, failing as --strict was provided", err.Error(), "unexpected error")
+	Warnf("deprecated permission requested")
+	assert.EqualError(t, StrictError(), "2 warnings were logged, failing as --strict was provided", "unexpected error")
+
+	// Missing permissions to delete resources fail before anything is deleted
+	missing := []DeletionPermission{{Permission: "iam:DeleteRole", Blocks: "the IAM Roles of test-name"}}
+	assert.Error(t, ReportMissingDeletionPermissions(missing, false), "expected missing permissions to fail with --strict")
+}
@@ -0,0 +1,223 @@
+package provisioning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// tagSelectorOperator is the operator of a requirement of a TagSelector
+type tagSelectorOperator string
+
+const (
+	tagSelectorEquals    tagSelectorOperator = "="
+	tagSelectorNotEquals tagSelectorOperator = "!="
+	tagSelectorIn        tagSelectorOperator = "in"
+	tagSelectorNotIn     tagSelectorOperator = "notin"
+)
+
+// tagRequirement is a single requirement of a TagSelector on the value of the tag identified by key
+type tagRequirement struct {
+	key      string
+	operator tagSelectorOperator
+	values   []string
+}
+
+// TagSelector selects resources by their tags, eg. to restrict ccoctl delete to a subset of the resources it owns. The
+// selector is a comma-separated list of requirements, all of which must be met by the tags of a selected resource:
+//
+//	key=value           the resource has the tag key with the value value
+//	key!=value          the resource does not have the tag key with the value value
+//	key in (v1,v2)      the resource has the tag key with either the value v1 or v2
+//	key notin (v1,v2)   the resource does not have the tag key with either the value v1 or v2
+//
+// TagSelector is a pflag.Value, the requirements of every occurrence of the flag are combined. The empty TagSelector
+// selects every resource.
+type TagSelector struct {
+	requirements []tagRequirement
+}
+
+// ParseTagSelector parses the tag selector expression, returning an error describing the first invalid requirement
+func ParseTagSelector(expression string) (TagSelector, error) {
+	selector := TagSelector{}
+	if err := selector.Set(expression); err != nil {
+		return TagSelector{}, err
+	}
+	return selector, nil
+}
+
+// Empty returns true when the selector has no requirements and so selects every resource
+func (s TagSelector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches returns true when tags meet every requirement of the selector
+func (s TagSelector) Matches(tags map[string]string) bool {
+	for _, requirement := range s.requirements {
+		value, found := tags[requirement.key]
+		listed := found && containsString(requirement.values, value)
+		switch requirement.operator {
+		case tagSelectorEquals, tagSelectorIn:
+			if !listed {
+				return false
+			}
+		case tagSelectorNotEquals, tagSelectorNotIn:
+			if listed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Set parses the requirements of expression, appending them to those of the selector
+func (s *TagSelector) Set(expression string) error {
+	terms, err := splitTagSelector(expression)
+	if err != nil {
+		return err
+	}
+	requirements := make([]tagRequirement, 0, len(terms))
+	for _, term := range terms {
+		requirement, err := parseTagRequirement(term)
+		if err != nil {
+			return err
+		}
+		requirements = append(requirements, requirement)
+	}
+	s.requirements = append(s.requirements, requirements...)
+	return nil
+}
+
+// String returns the canonical expression of the selector, eg. "env in (dev,test),team=platform"
+func (s *TagSelector) String() string {
+	terms := make([]string, 0, len(s.requirements))
+	for _, requirement := range s.requirements {
+		switch requirement.operator {
+		case tagSelectorIn, tagSelectorNotIn:
+			terms = append(terms, fmt.Sprintf("%s %s (%s)", requirement.key, requirement.operator, strings.Join(requirement.values, ",")))
+		default:
+			terms = append(terms, requirement.key+string(requirement.operator)+requirement.values[0])
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+func (s *TagSelector) Type() string {
+	return "string"
+}
+
+// splitTagSelector splits expression at the commas separating its requirements, ie. those outside of parentheses
+func splitTagSelector(expression string) ([]string, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("empty tag selector, expected a comma-separated list of requirements")
+	}
+	terms := []string{}
+	depth := 0
+	start := 0
+	for i, r := range expression {
+		switch r {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, fmt.Errorf("invalid tag selector %q, parentheses may not be nested", expression)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("invalid tag selector %q, unbalanced parentheses", expression)
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, expression[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("invalid tag selector %q, unbalanced parentheses", expression)
+	}
+	return append(terms, expression[start:]), nil
+}
+
+// parseTagRequirement parses a single requirement of a tag selector
+func parseTagRequirement(term string) (tagRequirement, error) {
+	invalid := func(reason string) (tagRequirement, error) {
+		return tagRequirement{}, fmt.Errorf("invalid tag selector requirement %q, %s. "+
+			"Expected key=value, key!=value, key in (value,...) or key notin (value,...)", strings.TrimSpace(term), reason)
+	}
+
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return invalid("the requirement is empty")
+	}
+
+	if open := strings.Index(term, "("); open != -1 {
+		if !strings.HasSuffix(term, ")") {
+			return invalid("the set of values must end the requirement")
+		}
+		fields := strings.Fields(term[:open])
+		if len(fields) != 2 || (fields[1] != string(tagSelectorIn) && fields[1] != string(tagSelectorNotIn)) {
+			return invalid("a set of values must follow \"<key> in\" or \"<key> notin\"")
+		}
+		values := []string{}
+		for _, value := range strings.Split(term[open+1:len(term)-1], ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return invalid("the values of a set may not be empty")
+			}
+			if strings.ContainsAny(value, "=!") {
+				return invalid(fmt.Sprintf("the value %q may not contain '=' or '!'", value))
+			}
+			values = append(values, value)
+		}
+		return validateTagRequirement(tagRequirement{key: fields[0], operator: tagSelectorOperator(fields[1]), values: values}, invalid)
+	}
+	if strings.Contains(term, ")") {
+		return invalid("unbalanced parentheses")
+	}
+
+	operator := tagSelectorEquals
+	key, value, found := strings.Cut(term, string(tagSelectorNotEquals))
+	if found {
+		operator = tagSelectorNotEquals
+	} else if key, value, found = strings.Cut(term, "=="); !found {
+		key, value, found = strings.Cut(term, string(tagSelectorEquals))
+	}
+	if !found {
+		return invalid("no operator was found")
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if value == "" {
+		return invalid("the value may not be empty")
+	}
+	if strings.ContainsAny(value, "=!") {
+		return invalid(fmt.Sprintf("the value %q may not contain '=' or '!'", value))
+	}
+	return validateTagRequirement(tagRequirement{key: key, operator: operator, values: []string{value}}, invalid)
+}
+
+// validateTagRequirement ensures that the key of requirement is neither empty nor contains whitespace or any of the
+// characters of the selector syntax, and sorts the values of sets so that String is canonical
+func validateTagRequirement(requirement tagRequirement, invalid func(string) (tagRequirement, error)) (tagRequirement, error) {
+	if requirement.key == "" {
+		return invalid("the tag key may not be empty")
+	}
+	if strings.IndexFunc(requirement.key, unicode.IsSpace) != -1 || strings.ContainsAny(requirement.key, "=!(),") {
+		return invalid(fmt.Sprintf("the tag key %q may not contain whitespace or any of '=!(),'", requirement.key))
+	}
+	if requirement.operator == tagSelectorIn || requirement.operator == tagSelectorNotIn {
+		sort.Strings(requirement.values)
+	}
+	return requirement, nil
+}
+
+// containsString returns true when values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagSelector(t *testing.T) {
+	tests := []struct {
+		name         string
+		expression   string
+		expectString string
+		expectError  bool
+	}{
+		{
+			name:         "Single equality requirement",
+			expression:   "env=dev",
+			expectString: "env=dev",
+		},
+		{
+			name:         "Double equals and inequality",
+			expression:   "env==dev, team != platform",
+			expectString: "env=dev,team!=platform",
+		},
+		{
+			name:         "Set membership",
+			expression:   "env in (test, dev),tier notin (frontend)",
+			expectString: "env in (dev,test),tier notin (frontend)",
+		},
+		{
+			name:         "Value containing spaces",
+			expression:   "owner=Platform Team",
+			expectString: "owner=Platform Team",
+		},
+		{
+			name:        "Empty selector",
+			expression:  " ",
+			expectError: true,
+		},
+		{
+			name:        "Empty requirement",
+			expression:  "env=dev,",
+			expectError: true,
+		},
+		{
+			name:        "Requirement without operator",
+			expression:  "env",
+			expectError: true,
+		},
+		{
+			name:        "Empty key",
+			expression:  "=dev",
+			expectError: true,
+		},
+		{
+			name:        "Empty value",
+			expression:  "env=",
+			expectError: true,
+		},
+		{
+			name:        "Value containing an operator",
+			expression:  "env=dev=test",
+			expectError: true,
+		},
+		{
+			name:        "Unknown set operator",
+			expression:  "env within (dev)",
+			expectError: true,
+		},
+		{
+			name:        "Empty value within set",
+			expression:  "env in (dev,)",
+			expectError: true,
+		},
+		{
+			name:        "Unbalanced parentheses",
+			expression:  "env in (dev,test",
+			expectError: true,
+		},
+		{
+			name:        "Nested parentheses",
+			expression:  "env in ((dev))",
+			expectError: true,
+		},
+		{
+			name:        "Set not ending the requirement",
+			expression:  "env in (dev) test",
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selector, err := ParseTagSelector(test.expression)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectString, selector.String())
+		})
+	}
+}
+
+func TestTagSelectorMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		expressions []string
+		tags        map[string]string
+		expectMatch bool
+	}{
+		{
+			name:        "Empty selector matches every resource",
+			tags:        map[string]string{},
+			expectMatch: true,
+		},
+		{
+			name:        "Equal value matched",
+			expressions: []string{"env=dev"},
+			tags:        map[string]string{"env": "dev"},
+			expectMatch: true,
+		},
+		{
+			name:        "Different value not matched",
+			expressions: []string{"env=dev"},
+			tags:        map[string]string{"env": "prod"},
+		},
+		{
+			name:        "Missing tag not matched by equality",
+			expressions: []string{"env=dev"},
+			tags:        map[string]string{},
+		},
+		{
+			name:        "Missing tag matched by inequality",
+			expressions: []string{"env!=prod"},
+			tags:        map[string]string{},
+			expectMatch: true,
+		},
+		{
+			name:        "Value within set matched",
+			expressions: []string{"env in (dev,test)"},
+			tags:        map[string]string{"env": "test"},
+			expectMatch: true,
+		},
+		{
+			name:        "Value within set not matched by notin",
+			expressions: []string{"env notin (dev,test)"},
+			tags:        map[string]string{"env": "test"},
+		},
+		{
+			name:        "Every requirement must be met",
+			expressions: []string{"env in (dev,test),team=platform"},
+			tags:        map[string]string{"env": "dev", "team": "storage"},
+		},
+		{
+			name:        "Requirements of every occurrence of the flag combined",
+			expressions: []string{"env in (dev,test)", "team=platform"},
+			tags:        map[string]string{"env": "dev", "team": "platform"},
+			expectMatch: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selector := TagSelector{}
+			for _, expression := range test.expressions {
+				require.NoError(t, selector.Set(expression))
+			}
+			assert.Equal(t, test.expectMatch, selector.Matches(test.tags))
+		})
+	}
+}
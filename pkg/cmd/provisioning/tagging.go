@@ -0,0 +1,123 @@
+package provisioning
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TaggingConcurrency is the number of resources tagged concurrently by TagResources
+	TaggingConcurrency = 5
+)
+
+// taggingRetryPolicy is the retry policy of throttled tagging calls unless overridden with --max-retries,
+// --retry-base-delay and --retry-max-delay. Tagging calls are retried on top of the retries of the cloud API clients
+// since tagging many resources at once is throttled for longer than the clients retry.
+var taggingRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  2 * time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// TagOperation applies tags to a single resource which has already been created
+type TagOperation struct {
+	// ResourceType is the type of the tagged resource, eg. "S3Bucket"
+	ResourceType string
+	// ResourceName identifies the tagged resource, eg. the name or ARN of the resource
+	ResourceName string
+	// Tag applies the tags to the resource
+	Tag func() error
+}
+
+// TaggingError is returned when resources were created but could not be tagged, so that tagging failures can be told
+// apart from failures to create resources. Resources which are not tagged may not be found by ccoctl delete.
+type TaggingError struct {
+	// Failures are the errors tagging each untagged resource, keyed by "<resource type> <resource name>"
+	Failures map[string]error
+}
+
+func (e *TaggingError) Error() string {
+	resources := make([]string, 0, len(e.Failures))
+	for resource := range e.Failures {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	messages := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		messages = append(messages, fmt.Sprintf("%s: %s", resource, e.Failures[resource]))
+	}
+	return fmt.Sprintf("%d resources were created but could not be tagged, retry tagging them before they can be found by ccoctl delete: %s",
+		len(resources), strings.Join(messages, "; "))
+}
+
+// IsTaggingError returns true when err indicates that resources were created but could not be tagged
+func IsTaggingError(err error) bool {
+	var taggingErr *TaggingError
+	return errors.As(err, &taggingErr)
+}
+
+// TagResources runs operations, at most TaggingConcurrency at a time. Operations failing with an error for which
+// isThrottled returns true are retried with an exponential backoff. When some resources could not be tagged, a
+// TaggingError recording every untagged resource is returned once all operations have completed.
+func TagResources(operations []TagOperation, isThrottled func(error) bool) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures = map[string]error{}
+		slots    = make(chan struct{}, TaggingConcurrency)
+	)
+	for _, operation := range operations {
+		operation := operation
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := tagWithBackoff(operation, isThrottled); err != nil {
+				mu.Lock()
+				failures[fmt.Sprintf("%s %s", operation.ResourceType, operation.ResourceName)] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &TaggingError{Failures: failures}
+	}
+	return nil
+}
+
+// TagResource runs the single operation, retrying it with an exponential backoff while it is throttled. A
+// TaggingError is returned when the resource could not be tagged.
+func TagResource(operation TagOperation, isThrottled func(error) bool) error {
+	return TagResources([]TagOperation{operation}, isThrottled)
+}
+
+// tagWithBackoff runs operation until it succeeds, fails with an error which isThrottled does not consider a
+// throttling error or has been retried as many times as allowed by the effective tagging retry policy
+func tagWithBackoff(operation TagOperation, isThrottled func(error) bool) error {
+	retryPolicy := EffectiveRetryPolicy(taggingRetryPolicy)
+	interval := retryPolicy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := operation.Tag()
+		if err == nil || !isThrottled(err) {
+			return err
+		}
+		if attempt > retryPolicy.MaxRetries {
+			return errors.Wrapf(err, "tagging still throttled after %d attempts", attempt)
+		}
+		log.Printf("Tagging %s %s was throttled, retrying in %s", operation.ResourceType, operation.ResourceName, interval)
+		time.Sleep(interval)
+		interval *= 2
+		if interval > retryPolicy.MaxDelay {
+			interval = retryPolicy.MaxDelay
+		}
+	}
+}
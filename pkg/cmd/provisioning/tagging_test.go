@@ -0,0 +1,120 @@
+package provisioning
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errThrottled = errors.New("throttled")
+
+func isTestThrottle(err error) bool {
+	return errors.Is(err, errThrottled)
+}
+
+func TestTagResources(t *testing.T) {
+	taggingRetryPolicy.BaseDelay, taggingRetryPolicy.MaxDelay = time.Millisecond, time.Millisecond
+	taggingMaxAttempts := taggingRetryPolicy.MaxRetries + 1
+
+	tests := []struct {
+		name string
+		// throttledAttempts is the number of attempts to tag each resource which are throttled
+		throttledAttempts int
+		tagErr            error
+		expectAttempts    int
+		expectTaggingErr  bool
+	}{
+		{
+			name:           "Resources tagged",
+			expectAttempts: 1,
+		},
+		{
+			name:              "Throttled tagging retried",
+			throttledAttempts: 3,
+			expectAttempts:    4,
+		},
+		{
+			name:              "Tagging throttled on every attempt",
+			throttledAttempts: taggingMaxAttempts,
+			expectAttempts:    taggingMaxAttempts,
+			expectTaggingErr:  true,
+		},
+		{
+			name:             "Tagging error not retried",
+			tagErr:           errors.New("access denied"),
+			expectAttempts:   1,
+			expectTaggingErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts = map[string]int{}
+			)
+			operations := []TagOperation{}
+			for _, name := range []string{"first", "second", "third"} {
+				name := name
+				operations = append(operations, TagOperation{
+					ResourceType: "TestResource",
+					ResourceName: name,
+					Tag: func() error {
+						mu.Lock()
+						defer mu.Unlock()
+						attempts[name]++
+						if attempts[name] <= test.throttledAttempts {
+							return errThrottled
+						}
+						return test.tagErr
+					},
+				})
+			}
+
+			err := TagResources(operations, isTestThrottle)
+
+			for _, operation := range operations {
+				assert.Equal(t, test.expectAttempts, attempts[operation.ResourceName], "unexpected number of attempts tagging %s", operation.ResourceName)
+			}
+			if test.expectTaggingErr {
+				require.Error(t, err)
+				assert.True(t, IsTaggingError(err), "expected a tagging error")
+				assert.Len(t, err.(*TaggingError).Failures, len(operations))
+				assert.Contains(t, err.Error(), "TestResource first")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTagResourcesConcurrency(t *testing.T) {
+	var active, maxActive int32
+	operations := []TagOperation{}
+	for i := 0; i < 3*TaggingConcurrency; i++ {
+		operations = append(operations, TagOperation{
+			ResourceType: "TestResource",
+			ResourceName: "resource",
+			Tag: func() error {
+				current := atomic.AddInt32(&active, 1)
+				defer atomic.AddInt32(&active, -1)
+				for {
+					observed := atomic.LoadInt32(&maxActive)
+					if current <= observed || atomic.CompareAndSwapInt32(&maxActive, observed, current) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			},
+		})
+	}
+
+	require.NoError(t, TagResources(operations, isTestThrottle))
+	assert.LessOrEqual(t, maxActive, int32(TaggingConcurrency))
+}
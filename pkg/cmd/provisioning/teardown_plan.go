@@ -0,0 +1,68 @@
+package provisioning
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// TeardownPlanResource is a resource listed within a teardown plan, identified by its type, eg. "StorageAccount", and
+// by its ID, which is either the name of the resource or its full ID as reported by the cloud provider
+type TeardownPlanResource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// String describes the resource in logs and errors, eg. "StorageAccount mycluster"
+func (r TeardownPlanResource) String() string {
+	return r.Type + " " + r.ID
+}
+
+// TeardownPlan is the ordered list of resources which ccoctl delete deletes in turn when provided with --teardown-plan,
+// overriding its default order, eg. as external dependencies require some resources to be deleted before others. It
+// is read from a YAML or JSON file such as
+//
+//	resources:
+//	- type: UserAssignedManagedIdentity
+//	  id: mycluster-openshift-image-registry-installer-cloud-credentials
+//	- type: StorageAccount
+//	  id: mycluster
+type TeardownPlan struct {
+	Resources []TeardownPlanResource `json:"resources"`
+}
+
+// LoadTeardownPlan reads the teardown plan at path. An error is returned when the plan lists no resources, lists a
+// resource of a type other than resourceTypes, the types of the resources the provider deletes, lists a resource
+// without an ID or lists a resource more than once.
+func LoadTeardownPlan(path string, resourceTypes []string) (*TeardownPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read teardown plan %s", path)
+	}
+	plan := &TeardownPlan{}
+	if err := sigsyaml.UnmarshalStrict(data, plan); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode teardown plan %s", path)
+	}
+	if len(plan.Resources) == 0 {
+		return nil, fmt.Errorf("teardown plan %s lists no resources", path)
+	}
+
+	listed := map[TeardownPlanResource]bool{}
+	for i, resource := range plan.Resources {
+		if !containsString(resourceTypes, resource.Type) {
+			return nil, fmt.Errorf("resource %d of teardown plan %s has unsupported type %q, the supported types are %s",
+				i+1, path, resource.Type, strings.Join(resourceTypes, ", "))
+		}
+		if resource.ID == "" {
+			return nil, fmt.Errorf("resource %d of teardown plan %s has no id", i+1, path)
+		}
+		if listed[resource] {
+			return nil, fmt.Errorf("teardown plan %s lists %s more than once", path, resource)
+		}
+		listed[resource] = true
+	}
+	return plan, nil
+}
@@ -0,0 +1,85 @@
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTeardownPlan(t *testing.T) {
+	resourceTypes := []string{"UserAssignedManagedIdentity", "StorageAccount", "ResourceGroup"}
+
+	tests := []struct {
+		name        string
+		plan        string
+		expectPlan  *TeardownPlan
+		expectError string
+	}{
+		{
+			name: "YAML teardown plan",
+			plan: `resources:
+- type: StorageAccount
+  id: mycluster
+- type: ResourceGroup
+  id: mycluster-oidc
+`,
+			expectPlan: &TeardownPlan{Resources: []TeardownPlanResource{
+				{Type: "StorageAccount", ID: "mycluster"},
+				{Type: "ResourceGroup", ID: "mycluster-oidc"},
+			}},
+		},
+		{
+			name: "JSON teardown plan",
+			plan: `{"resources": [{"type": "UserAssignedManagedIdentity", "id": "mycluster-openshift-ingress-operator-cloud-credentials"}]}`,
+			expectPlan: &TeardownPlan{Resources: []TeardownPlanResource{
+				{Type: "UserAssignedManagedIdentity", ID: "mycluster-openshift-ingress-operator-cloud-credentials"},
+			}},
+		},
+		{
+			name:        "Empty teardown plan",
+			plan:        "resources: []\n",
+			expectError: "lists no resources",
+		},
+		{
+			name:        "Unsupported resource type",
+			plan:        "resources:\n- type: VirtualMachine\n  id: mycluster\n",
+			expectError: `resource 1 of teardown plan`,
+		},
+		{
+			name:        "Resource without ID",
+			plan:        "resources:\n- type: StorageAccount\n",
+			expectError: "has no id",
+		},
+		{
+			name:        "Resource listed twice",
+			plan:        "resources:\n- type: StorageAccount\n  id: mycluster\n- type: StorageAccount\n  id: mycluster\n",
+			expectError: "lists StorageAccount mycluster more than once",
+		},
+		{
+			name:        "Unknown key",
+			plan:        "resources:\n- type: StorageAccount\n  name: mycluster\n",
+			expectError: "failed to decode teardown plan",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "teardown-plan.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(test.plan), 0600))
+
+			plan, err := LoadTeardownPlan(path, resourceTypes)
+			if test.expectError != "" {
+				assert.ErrorContains(t, err, test.expectError)
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectPlan, plan, "unexpected teardown plan")
+			}
+		})
+	}
+
+	_, err := LoadTeardownPlan(filepath.Join(t.TempDir(), "missing.yaml"), resourceTypes)
+	assert.ErrorContains(t, err, "failed to read teardown plan", "expected error reading a missing teardown plan")
+}
@@ -0,0 +1,78 @@
+package provisioning
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TerraformImportFileName is the name of the file within the output directory to which ccoctl writes the
+// terraform import commands of the cloud resources recorded within the inventory
+const TerraformImportFileName = "ccoctl-terraform-import.sh"
+
+// invalidTerraformNameChars matches the characters which may not be used within the name of a terraform resource
+var invalidTerraformNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// TerraformImport identifies the terraform resource into which a cloud resource recorded within an inventory is imported
+type TerraformImport struct {
+	// Address is the address of the terraform resource, eg. "aws_iam_role.mycluster-openshift-image-registry"
+	Address string
+	// ID is the ID with which the terraform provider imports the cloud resource, eg. the name of an IAM Role
+	ID string
+}
+
+// TerraformImportsFunc returns the terraform imports of the cloud resources recorded within a completed step, the
+// resources which can't be imported are omitted
+type TerraformImportsFunc func(step InventoryStep) []TerraformImport
+
+// TerraformAddress returns the address of the terraform resource of resourceType named after name. Characters which
+// may not be used within the names of terraform resources are replaced by underscores.
+func TerraformAddress(resourceType, name string) string {
+	name = invalidTerraformNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return resourceType + "." + name
+}
+
+// WriteTerraformImports writes a script containing a terraform import command for every cloud resource recorded within
+// inventory to the directory of the inventory, so that the resources may be brought under terraform management. The
+// terraform imports of each completed step are returned by imports, the steps are processed in the order in which
+// they are persisted so that the script is identical for identical inventories. The path of the script is returned.
+func WriteTerraformImports(inventory *Inventory, imports TerraformImportsFunc) (string, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "#!/bin/sh\n# terraform import commands of the %s resources created by ccoctl for %s\nset -e\n", inventory.Provider, inventory.Name)
+
+	// Resources recorded by more than one step are imported once, while distinct resources whose names collide once
+	// sanitized are imported to distinct addresses
+	importedIDs := map[string]string{}
+	for _, step := range inventory.Sorted().Steps {
+		for _, terraformImport := range imports(step) {
+			address := terraformImport.Address
+			for i := 2; importedIDs[address] != "" && importedIDs[address] != terraformImport.ID; i++ {
+				address = fmt.Sprintf("%s_%d", terraformImport.Address, i)
+			}
+			if importedIDs[address] == terraformImport.ID {
+				continue
+			}
+			importedIDs[address] = terraformImport.ID
+			fmt.Fprintf(buf, "terraform import %s %s\n", shellQuote(address), shellQuote(terraformImport.ID))
+		}
+	}
+
+	path := filepath.Join(filepath.Dir(inventory.path), TerraformImportFileName)
+	if err := os.WriteFile(path, buf.Bytes(), 0700); err != nil {
+		return "", errors.Wrapf(err, "failed to save terraform import commands at path %s", path)
+	}
+	return path, nil
+}
+
+// shellQuote quotes s as a single argument of a shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
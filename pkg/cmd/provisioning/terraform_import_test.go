@@ -0,0 +1,82 @@
+package provisioning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformAddress(t *testing.T) {
+	tests := []struct {
+		name            string
+		resourceName    string
+		expectedAddress string
+	}{
+		{
+			name:            "valid name",
+			resourceName:    "mycluster-openshift_image-registry",
+			expectedAddress: "aws_iam_role.mycluster-openshift_image-registry",
+		},
+		{
+			name:            "invalid characters replaced",
+			resourceName:    "system:serviceaccount/name.1",
+			expectedAddress: "aws_iam_role.system_serviceaccount_name_1",
+		},
+		{
+			name:            "leading digit prefixed",
+			resourceName:    "1cluster",
+			expectedAddress: "aws_iam_role._1cluster",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedAddress, TerraformAddress("aws_iam_role", test.resourceName))
+		})
+	}
+}
+
+func TestWriteTerraformImports(t *testing.T) {
+	tempDirName := t.TempDir()
+	inventory := NewInventory(tempDirName, "testprovider", "testname")
+	inventory.Steps = []InventoryStep{
+		{Name: "step1", Resources: []InventoryResource{{Type: "testtype", Name: "resource1", ID: "id1"}}},
+		{Name: "step2", Resources: []InventoryResource{
+			// Recorded by an earlier step
+			{Type: "testtype", Name: "resource1", ID: "id1"},
+			{Type: "testtype", Name: "resource.1", ID: "id'2"},
+			// Collides with resource.1 once sanitized
+			{Type: "testtype", Name: "resource:1", ID: "id4"},
+			{Type: "untracked", Name: "resource3", ID: "id3"},
+		}},
+	}
+
+	path, err := WriteTerraformImports(inventory, func(step InventoryStep) []TerraformImport {
+		imports := []TerraformImport{}
+		for _, resource := range step.Resources {
+			if resource.Type != "testtype" {
+				continue
+			}
+			imports = append(imports, TerraformImport{
+				Address: TerraformAddress("test_resource", resource.Name),
+				ID:      resource.ID,
+			})
+		}
+		return imports
+	})
+	require.NoError(t, err, "unexpected error writing terraform imports")
+	assert.Equal(t, filepath.Join(tempDirName, TerraformImportFileName), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read terraform imports")
+	assert.Equal(t, `#!/bin/sh
+# terraform import commands of the testprovider resources created by ccoctl for testname
+set -e
+terraform import 'test_resource.resource1' 'id1'
+terraform import 'test_resource.resource_1' 'id'\''2'
+terraform import 'test_resource.resource_1_2' 'id4'
+`, string(data))
+}
@@ -0,0 +1,115 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// commandTimeout bounds the run of the command set by --timeout, the run is not bounded when it is zero
+	commandTimeout time.Duration
+
+	// timeoutMu guards the fields below. It is held from the moment the command times out until ccoctl exits so that
+	// no inventory is left partially written.
+	timeoutMu sync.Mutex
+	// phase describes what the command is doing, eg. "creating the OIDC issuer", it is reported when the command
+	// times out
+	phase string
+	// savedInventoryPath is the path of the inventory most recently persisted by the command
+	savedInventoryPath string
+	// savedSteps are the steps recorded as completed within the inventory most recently persisted by the command
+	savedSteps []string
+
+	// timeoutExit exits ccoctl in failure with message once the command has timed out
+	timeoutExit = func(message string) { log.Fatal(message) }
+)
+
+// InitTimeout bounds the run of every command to timeout. Commands are not bounded when timeout is zero.
+func InitTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("invalid --timeout %s, the timeout may not be negative", timeout)
+	}
+	commandTimeout = timeout
+	timeoutMu.Lock()
+	defer timeoutMu.Unlock()
+	phase = ""
+	savedInventoryPath = ""
+	savedSteps = nil
+	return nil
+}
+
+// SetPhase records p as the phase of the running command, eg. "creating the OIDC issuer". The phase is reported
+// when the command exceeds --timeout.
+func SetPhase(p string) {
+	timeoutMu.Lock()
+	defer timeoutMu.Unlock()
+	phase = p
+}
+
+// recordSavedInventory records the steps of inventory once it has been persisted to path, to be reported should the
+// command time out. It must be called with timeoutMu held.
+func recordSavedInventory(path string, inventory *Inventory) {
+	savedInventoryPath = path
+	savedSteps = make([]string, 0, len(inventory.Steps))
+	for _, step := range inventory.Steps {
+		savedSteps = append(savedSteps, step.Name)
+	}
+}
+
+// TimeoutCommands wraps the Run function of cmd and of each of its descendant commands so that every run of a
+// command is bounded by --timeout. The context of the command carries the deadline. Once the deadline is exceeded
+// the phase which was active and the steps completed beforehand are logged, the context of the command is cancelled
+// and ccoctl exits in failure, abandoning any in-flight cloud API calls.
+func TimeoutCommands(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		TimeoutCommands(subCmd)
+	}
+	if cmd.Run == nil {
+		return
+	}
+	run := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if commandTimeout == 0 {
+			run(cmd, args)
+			return
+		}
+		timeout := commandTimeout
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		defer cancel()
+		// The timer rather than the context reports the timeout so that the phase is logged before the cancellation
+		// of in-flight calls results in errors
+		timer := time.AfterFunc(timeout, func() { timedOut(cmd.CommandPath(), timeout, cancel) })
+		defer timer.Stop()
+		cmd.SetContext(ctx)
+		run(cmd, args)
+	}
+}
+
+// timedOut reports that the command identified by commandPath exceeded timeout, cancels the context of the command
+// and exits
+func timedOut(commandPath string, timeout time.Duration, cancel context.CancelFunc) {
+	timeoutMu.Lock()
+	defer timeoutMu.Unlock()
+
+	message := fmt.Sprintf("%s timed out after %s", commandPath, timeout)
+	if phase != "" {
+		message = fmt.Sprintf("%s while %s", message, phase)
+	}
+	switch {
+	case savedInventoryPath == "":
+		log.Print("No steps were recorded as completed before timing out")
+	case len(savedSteps) == 0:
+		log.Printf("No steps were completed before timing out, see inventory %s", savedInventoryPath)
+	default:
+		log.Printf("%d steps were completed before timing out and are recorded within inventory %s: %s",
+			len(savedSteps), savedInventoryPath, strings.Join(savedSteps, ", "))
+	}
+	cancel()
+	timeoutExit(message)
+}
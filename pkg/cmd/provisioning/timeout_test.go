@@ -0,0 +1,110 @@
+package provisioning
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutCommands(t *testing.T) {
+	defer func() {
+		timeoutExit = func(message string) { log.Fatal(message) }
+		InitTimeout(0)
+		log.SetOutput(os.Stderr)
+	}()
+
+	tests := []struct {
+		name            string
+		timeout         time.Duration
+		completedSteps  []string
+		expectTimeout   bool
+		expectMessage   string
+		expectLogged    string
+		expectNoLogging bool
+	}{
+		{
+			name:            "Run not bounded without a timeout",
+			timeout:         0,
+			expectNoLogging: true,
+		},
+		{
+			name:           "Phase and completed steps reported on timing out",
+			timeout:        50 * time.Millisecond,
+			completedSteps: []string{"create-oidc-issuer", "create-managed-identity/namespace1/secretName1"},
+			expectTimeout:  true,
+			expectMessage:  "ccoctl test timed out after 50ms while creating the user-assigned managed identity for namespace2/secretName2",
+			expectLogged:   "2 steps were completed before timing out and are recorded within inventory",
+		},
+		{
+			name:          "Timing out without an inventory",
+			timeout:       50 * time.Millisecond,
+			expectTimeout: true,
+			expectMessage: "ccoctl test timed out after 50ms while creating the user-assigned managed identity for namespace2/secretName2",
+			expectLogged:  "No steps were recorded as completed before timing out",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+			log.SetOutput(logs)
+			require.NoError(t, InitTimeout(test.timeout))
+			exitMessages := make(chan string, 1)
+			timeoutExit = func(message string) { exitMessages <- message }
+
+			tempDir := t.TempDir()
+			rootCmd := &cobra.Command{Use: "ccoctl"}
+			testCmd := &cobra.Command{
+				Use: "test",
+				Run: func(cmd *cobra.Command, args []string) {
+					if len(test.completedSteps) > 0 {
+						inventory := NewInventory(tempDir, "azure", "test-name")
+						for _, step := range test.completedSteps {
+							require.NoError(t, inventory.CompleteStep(step))
+						}
+					}
+					SetPhase("creating the user-assigned managed identity for namespace2/secretName2")
+					_, hasDeadline := cmd.Context().Deadline()
+					assert.Equal(t, test.timeout != 0, hasDeadline, "unexpected deadline of the command's context")
+					if test.expectTimeout {
+						<-cmd.Context().Done()
+					}
+				},
+			}
+			rootCmd.AddCommand(testCmd)
+			TimeoutCommands(rootCmd)
+
+			rootCmd.SetArgs([]string{"test"})
+			require.NoError(t, rootCmd.Execute())
+
+			if !test.expectTimeout {
+				assert.Empty(t, exitMessages, "expected the command not to time out")
+			} else {
+				select {
+				case message := <-exitMessages:
+					assert.Equal(t, test.expectMessage, message)
+				case <-time.After(5 * time.Second):
+					t.Fatal("expected the command to time out")
+				}
+			}
+			if test.expectNoLogging {
+				assert.Empty(t, logs.String(), "expected nothing logged")
+			} else {
+				assert.Contains(t, logs.String(), test.expectLogged)
+			}
+		})
+	}
+}
+
+func TestInitTimeout(t *testing.T) {
+	defer InitTimeout(0)
+	assert.Error(t, InitTimeout(-time.Minute), "expected a negative timeout to be rejected")
+	assert.NoError(t, InitTimeout(time.Minute))
+	assert.Equal(t, time.Minute, commandTimeout)
+}
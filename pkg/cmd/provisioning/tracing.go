@@ -0,0 +1,213 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the instrumentation emitting the spans of ccoctl
+	tracerName = "github.com/openshift/cloud-credential-operator/pkg/cmd/provisioning"
+	// tracingServiceName is the service name reported in the resource of every exported span
+	tracingServiceName = "ccoctl"
+
+	// ProviderAttribute is the span attribute identifying the cloud provider subcommand, eg. "azure"
+	ProviderAttribute = attribute.Key("ccoctl.provider")
+	// ResourceTypeAttribute is the span attribute identifying the type of cloud resource being operated upon
+	ResourceTypeAttribute = attribute.Key("ccoctl.resource.type")
+	// ResourceNameAttribute is the span attribute identifying the name of the cloud resource being operated upon
+	ResourceNameAttribute = attribute.Key("ccoctl.resource.name")
+	// RegionAttribute is the span attribute identifying the region of the cloud resource being operated upon
+	RegionAttribute = semconv.CloudRegionKey
+)
+
+var (
+	// tracerProvider exports spans to the OTLP endpoint configured by InitTracing. It is nil when tracing is disabled.
+	tracerProvider *sdktrace.TracerProvider
+
+	// commandSpan is the span of the ccoctl command being run. Spans started from a context which carries no span,
+	// such as spans of cloud API calls made by clients which are not passed a context, are parented to commandSpan.
+	commandSpan trace.Span
+)
+
+// InitTracing configures ccoctl to export traces via OTLP over HTTP to endpoint. The endpoint is either a
+// "host:port" pair, which is sent spans over HTTPS, or an http:// or https:// URL optionally including the path
+// to which spans are sent. Tracing remains disabled, and spans are not recorded, when endpoint is empty.
+func InitTracing(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+
+	options, err := otlpOptions(endpoint)
+	if err != nil {
+		return err
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	// ccoctl exits through log.Fatal when it encounters an error so spans are exported synchronously as they end,
+	// otherwise the spans of failing runs which are the most interesting to debug would be lost.
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(tracingServiceName))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	return nil
+}
+
+// otlpOptions returns the options of the OTLP exporter sending spans to endpoint
+func otlpOptions(endpoint string) ([]otlptracehttp.Option, error) {
+	if !strings.Contains(endpoint, "://") {
+		return []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}, nil
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s: %w", endpoint, err)
+	}
+	if endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s, no host was provided", endpoint)
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpointURL.Host)}
+	switch endpointURL.Scheme {
+	case "https":
+	case "http":
+		options = append(options, otlptracehttp.WithInsecure())
+	default:
+		return nil, fmt.Errorf("invalid OTLP endpoint %s, the scheme must be http or https", endpoint)
+	}
+	if endpointURL.Path != "" && endpointURL.Path != "/" {
+		options = append(options, otlptracehttp.WithURLPath(endpointURL.Path))
+	}
+	return options, nil
+}
+
+// TracingEnabled returns true when spans are exported to an OTLP endpoint
+func TracingEnabled() bool {
+	return tracerProvider != nil
+}
+
+// ShutdownTracing exports any remaining spans and stops the OTLP exporter
+func ShutdownTracing() {
+	if tracerProvider == nil {
+		return
+	}
+	if err := tracerProvider.Shutdown(context.Background()); err != nil {
+		Warnf("Failed to export traces: %s", err)
+	}
+}
+
+// StartSpan starts a span named name as a child of the span within ctx, or of the span of the running ccoctl
+// command when ctx carries no span. The returned context carries the new span. When tracing is disabled no span
+// is started and a non-recording span is returned.
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return startSpan(ctx, name, trace.WithAttributes(attributes...))
+}
+
+func startSpan(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !TracingEnabled() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() && commandSpan != nil {
+		ctx = trace.ContextWithSpan(ctx, commandSpan)
+	}
+	return tracerProvider.Tracer(tracerName).Start(ctx, name, options...)
+}
+
+// EndSpan records err, if any, on span and ends span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceCommands wraps the Run function of cmd and of each of its descendant commands so that every run of a
+// command is recorded by a span named after the command path, eg. "ccoctl azure create-all".
+func TraceCommands(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		TraceCommands(subCmd)
+	}
+	if cmd.Run == nil {
+		return
+	}
+	run := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		attributes := []attribute.KeyValue{}
+		if path := strings.Fields(cmd.CommandPath()); len(path) > 1 {
+			attributes = append(attributes, ProviderAttribute.String(path[1]))
+		}
+		ctx, span := StartSpan(cmd.Context(), cmd.CommandPath(), attributes...)
+		defer span.End()
+		if TracingEnabled() {
+			commandSpan = span
+			defer func() { commandSpan = nil }()
+		}
+		cmd.SetContext(ctx)
+		run(cmd, args)
+	}
+}
+
+// NewTracingHTTPClient returns an HTTP client recording a span for every request it sends when tracing is
+// enabled, otherwise nil is returned so that cloud SDKs fall back to their default HTTP client.
+func NewTracingHTTPClient() *http.Client {
+	if !TracingEnabled() {
+		return nil
+	}
+	return &http.Client{Transport: &tracingTransport{base: http.DefaultTransport}}
+}
+
+// NewTracingTransport returns a transport recording a span for every request sent through base when tracing is
+// enabled, otherwise base is returned
+func NewTracingTransport(base http.RoundTripper) http.RoundTripper {
+	if !TracingEnabled() {
+		return base
+	}
+	return &tracingTransport{base: base}
+}
+
+// tracingTransport is an http.RoundTripper recording a client span for every request sent through base
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The query is omitted from the recorded URL as it may carry credentials, such as shared access signatures
+	ctx, span := startSpan(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.NetPeerNameKey.String(req.URL.Hostname()),
+			semconv.HTTPURLKey.String((&url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: req.URL.Path}).String()),
+		),
+	)
+	defer span.End()
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,108 @@
+package provisioning
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+func TestOTLPOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		endpoint        string
+		expectedOptions int
+		expectError     bool
+	}{
+		{
+			name:            "Host and port",
+			endpoint:        "collector.example.com:4318",
+			expectedOptions: 1,
+		},
+		{
+			name:            "HTTPS URL",
+			endpoint:        "https://collector.example.com:4318",
+			expectedOptions: 1,
+		},
+		{
+			name:            "HTTP URL is sent spans insecurely",
+			endpoint:        "http://localhost:4318",
+			expectedOptions: 2,
+		},
+		{
+			name:            "URL with path",
+			endpoint:        "https://collector.example.com/otlp/v1/traces",
+			expectedOptions: 2,
+		},
+		{
+			name:        "URL with unsupported scheme",
+			endpoint:    "grpc://localhost:4317",
+			expectError: true,
+		},
+		{
+			name:        "URL without host",
+			endpoint:    "http://",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options, err := otlpOptions(test.endpoint)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Len(t, options, test.expectedOptions, "unexpected number of exporter options")
+			}
+		})
+	}
+}
+
+func TestTracing(t *testing.T) {
+	require.Nil(t, NewTracingHTTPClient(), "expected no HTTP client while tracing is disabled")
+	require.Equal(t, http.DefaultTransport, NewTracingTransport(http.DefaultTransport), "expected the transport not to be wrapped while tracing is disabled")
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer func() { tracerProvider = nil }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	rootCmd := &cobra.Command{Use: "ccoctl"}
+	providerCmd := &cobra.Command{Use: "testprovider"}
+	createCmd := &cobra.Command{
+		Use: "create",
+		Run: func(cmd *cobra.Command, args []string) {
+			// The client is not passed the command's context, as is the case for most cloud SDK calls
+			resp, err := NewTracingHTTPClient().Get(server.URL + "/resource?sig=secret")
+			require.NoError(t, err, "unexpected error sending request")
+			resp.Body.Close()
+		},
+	}
+	providerCmd.AddCommand(createCmd)
+	rootCmd.AddCommand(providerCmd)
+	TraceCommands(rootCmd)
+	rootCmd.SetArgs([]string{"testprovider", "create"})
+	require.NoError(t, rootCmd.Execute(), "unexpected error running command")
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2, "expected spans of the HTTP request and of the command")
+	requestSpan, commandSpan := spans[0], spans[1]
+
+	assert.Equal(t, "ccoctl testprovider create", commandSpan.Name())
+	assert.Contains(t, commandSpan.Attributes(), ProviderAttribute.String("testprovider"))
+
+	assert.Equal(t, "HTTP GET", requestSpan.Name())
+	assert.Equal(t, commandSpan.SpanContext().SpanID(), requestSpan.Parent().SpanID(), "expected HTTP request span to be parented to the command span")
+	assert.Contains(t, requestSpan.Attributes(), semconv.HTTPURLKey.String(server.URL+"/resource"), "expected the query to be omitted from the recorded URL")
+	assert.Contains(t, requestSpan.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+}
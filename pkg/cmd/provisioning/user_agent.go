@@ -0,0 +1,58 @@
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/cloud-credential-operator/pkg/version"
+)
+
+const (
+	// userAgentProduct is the product with which ccoctl identifies itself in the User-Agent of cloud API requests
+	userAgentProduct = "ccoctl"
+	// maxUserAgentSuffixLength is the maximum length of --user-agent-suffix
+	maxUserAgentSuffixLength = 128
+)
+
+// userAgent is the User-Agent with which ccoctl identifies itself, it is extended by InitUserAgent
+var userAgent = defaultUserAgent()
+
+// defaultUserAgent returns "ccoctl/<version>"
+func defaultUserAgent() string {
+	return fmt.Sprintf("%s/%s", userAgentProduct, ccoctlVersion())
+}
+
+// ccoctlVersion returns the version of ccoctl, "unknown" when it was not set when ccoctl was built
+func ccoctlVersion() string {
+	v := version.Get().String()
+	if v == "" {
+		v = "unknown"
+	}
+	return v
+}
+
+// InitUserAgent appends suffix, eg. identifying the pipeline running ccoctl, to the User-Agent with which ccoctl
+// identifies itself in every cloud API request. The suffix may only contain printable ASCII characters so that it is
+// a valid HTTP header value.
+func InitUserAgent(suffix string) error {
+	suffix = strings.TrimSpace(suffix)
+	if len(suffix) > maxUserAgentSuffixLength {
+		return fmt.Errorf("invalid --user-agent-suffix %q, the suffix must not be longer than %d characters", suffix, maxUserAgentSuffixLength)
+	}
+	for _, r := range suffix {
+		if r < ' ' || r > '~' {
+			return fmt.Errorf("invalid --user-agent-suffix %q, the suffix may only contain printable ASCII characters", suffix)
+		}
+	}
+	userAgent = defaultUserAgent()
+	if suffix != "" {
+		userAgent = fmt.Sprintf("%s %s", userAgent, suffix)
+	}
+	return nil
+}
+
+// UserAgent returns the User-Agent with which ccoctl identifies itself in cloud API requests, "ccoctl/<version>"
+// followed by the suffix provided to InitUserAgent
+func UserAgent() string {
+	return userAgent
+}
@@ -0,0 +1,59 @@
+package provisioning
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitUserAgent(t *testing.T) {
+	defer func() { userAgent = defaultUserAgent() }()
+
+	tests := []struct {
+		name        string
+		suffix      string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "No suffix",
+			expected: "ccoctl/unknown",
+		},
+		{
+			name:     "Suffix appended",
+			suffix:   " pipeline/nightly (run 42) ",
+			expected: "ccoctl/unknown pipeline/nightly (run 42)",
+		},
+		{
+			name:        "Suffix with a newline",
+			suffix:      "pipeline\r\nX-Injected: true",
+			expectError: true,
+		},
+		{
+			name:        "Suffix with non-ASCII characters",
+			suffix:      "pipeline-é",
+			expectError: true,
+		},
+		{
+			name:        "Suffix too long",
+			suffix:      strings.Repeat("a", maxUserAgentSuffixLength+1),
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userAgent = defaultUserAgent()
+			err := InitUserAgent(test.suffix)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				assert.Equal(t, defaultUserAgent(), UserAgent(), "expected User-Agent to be left unchanged")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expected, UserAgent(), "unexpected User-Agent")
+			}
+		})
+	}
+}
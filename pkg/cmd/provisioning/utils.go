@@ -1,39 +1,47 @@
 package provisioning
 
 import (
+	"bufio"
 	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 	"gopkg.in/square/go-jose.v2"
 
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
 
 	configv1 "github.com/openshift/api/config/v1"
 
 	credreqv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 )
 
+// clusterIDRegexp matches the cluster IDs which may be used within tag keys of every supported cloud provider
+var clusterIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._-]*[a-zA-Z0-9])?$`)
+
 type JSONWebKeySet struct {
 	Keys []jose.JSONWebKey `json:"keys"`
 }
 
-// EnsureDir ensures that directory exists at a given path
+// EnsureDir ensures that directory exists at a given path, creating the directory and any missing parents if necessary
 func EnsureDir(path string) error {
 	sResult, err := os.Stat(path)
 	if os.IsNotExist(err) {
-		if err := os.Mkdir(path, 0700); err != nil {
+		if err := os.MkdirAll(path, 0700); err != nil {
 			return fmt.Errorf("failed to create directory: %s", err)
 		}
 		sResult, err = os.Stat(path)
@@ -48,6 +56,50 @@ func EnsureDir(path string) error {
 	return nil
 }
 
+// AllowInsecureSecretsDirFlag is the flag of the commands writing secret manifests which allows them to write the
+// secrets to a manifests directory which is group or world writable
+const AllowInsecureSecretsDirFlag = "allow-insecure-secrets-dir"
+
+// AddAllowInsecureSecretsDirFlag adds --allow-insecure-secrets-dir to flags, setting allow
+func AddAllowInsecureSecretsDirFlag(flags *pflag.FlagSet, allow *bool) {
+	flags.BoolVar(allow, AllowInsecureSecretsDirFlag, false, "Write secrets to the manifests directory even if the directory is group or world writable")
+}
+
+// EnsureSecretsDir exits when the directory at the given path, within which secret manifests will be written, is
+// group or world writable, as other users could then replace the secrets or read them before their permissions are
+// restricted. When allowInsecure is true a message is logged instead.
+func EnsureSecretsDir(path string, allowInsecure bool) {
+	if err := validateSecretsDir(path, allowInsecure); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func validateSecretsDir(path string, allowInsecure bool) error {
+	sResult, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat: %+v", err)
+	}
+	perm := sResult.Mode().Perm()
+	if perm&0022 == 0 {
+		return nil
+	}
+	if allowInsecure {
+		log.Printf("Writing secrets to directory %s which is group or world writable (%s) because --%s was provided", path, perm, AllowInsecureSecretsDirFlag)
+		return nil
+	}
+	return fmt.Errorf("refusing to write secrets to directory %s which is group or world writable (%s), "+
+		"remove group and world write permissions from the directory or use --%s to write secrets regardless", path, perm, AllowInsecureSecretsDirFlag)
+}
+
+// WriteSecretFile writes data to the secret manifest file at the given path with 0600 permissions. The permissions
+// of a pre-existing file are also restricted to 0600 as they are not modified when the file is overwritten.
+func WriteSecretFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0600)
+}
+
 // CreateShellScript creates a shell script given commands to execute
 func CreateShellScript(commands []string) string {
 	return fmt.Sprintf("#!/bin/sh\n%s", strings.Join(commands, "\n"))
@@ -64,18 +116,23 @@ func CountNonDirectoryFiles(files []os.FileInfo) int {
 	return NonDirectoryFiles
 }
 
-// CreateClusterAuthentication creates the authentication manifest file for the installer
-func CreateClusterAuthentication(issuerURL, targetDir string) error {
+// CreateClusterAuthentication creates the authentication manifest file for the installer. The manifest is annotated as
+// a dry-run artifact when dryRun is true.
+func CreateClusterAuthentication(issuerURL, targetDir string, dryRun bool) error {
 	clusterAuthenticationTemplate := `apiVersion: config.openshift.io/v1
 kind: Authentication
 metadata:
-  name: cluster
+%s  name: cluster
 spec:
   serviceAccountIssuer: %s`
 
-	clusterAuthFile := filepath.Join(targetDir, ManifestsDirName, "cluster-authentication-02-config.yaml")
+	clusterAuthFile := filepath.Join(targetDir, ManifestsDirName, clusterAuthenticationFile)
 
-	fileData := fmt.Sprintf(clusterAuthenticationTemplate, issuerURL)
+	annotations := ""
+	if dryRun {
+		annotations = "  annotations:\n" + DryRunAnnotations(nil)
+	}
+	fileData := fmt.Sprintf(clusterAuthenticationTemplate, annotations, issuerURL)
 	if err := ioutil.WriteFile(clusterAuthFile, []byte(fileData), 0600); err != nil {
 		return errors.Wrap(err, "failed to save cluster authentication file")
 	}
@@ -85,23 +142,61 @@ spec:
 	return nil
 }
 
-// BuildJsonWebKeySet builds JSON web key set from the public key
-func BuildJsonWebKeySet(publicKeyPath string) ([]byte, error) {
-	log.Print("Reading public key")
-	publicKeyContent, err := ioutil.ReadFile(publicKeyPath)
+// PrintIssuerURL writes only the issuerURL to w so that it may be captured by scripts,
+// eg. ISSUER=$(ccoctl ... --print-issuer-url). Log output is written to stderr and is not captured.
+func PrintIssuerURL(w io.Writer, issuerURL string) error {
+	if _, err := fmt.Fprintln(w, Redact(issuerURL)); err != nil {
+		return errors.Wrap(err, "failed to print issuer URL")
+	}
+	return nil
+}
 
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to read public key")
+// BuildJsonWebKeySet builds JSON web key set from the public keys, in the order provided. The key IDs are derived
+// from the public keys so an error is returned when the same public key is provided twice. Every public key must be
+// accepted as a service account signing key by validateSigningKey.
+func BuildJsonWebKeySet(publicKeyPaths ...string) ([]byte, error) {
+	return buildJSONWebKeySet(nil, publicKeyPaths)
+}
+
+// buildJSONWebKeySet builds the JSON web key set of the public keys at previousPublicKeyPaths followed by those at
+// publicKeyPaths. The previous public keys are being rotated away from, so they are only warned about when they would
+// be rejected as signing keys, so that a weak key may be replaced by rotating it.
+func buildJSONWebKeySet(previousPublicKeyPaths, publicKeyPaths []string) ([]byte, error) {
+	var keys []jose.JSONWebKey
+	keyPaths := map[string]string{}
+	for idx, publicKeyPath := range append(append([]string{}, previousPublicKeyPaths...), publicKeyPaths...) {
+		key, err := jsonWebKeyFromPublicKey(publicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := validateSigningKey(key.Key, "public key "+publicKeyPath); err != nil {
+			if idx >= len(previousPublicKeyPaths) {
+				return nil, err
+			}
+			Warnf("The previous signing key is weaker than required, it is replaced by the rotation: %s", err)
+		}
+		if previousPath, found := keyPaths[key.KeyID]; found {
+			return nil, fmt.Errorf("public key %s is the same as public key %s, JSON web key sets may not contain the same key twice", publicKeyPath, previousPath)
+		}
+		keyPaths[key.KeyID] = publicKeyPath
+		keys = append(keys, key)
 	}
 
-	block, _ := pem.Decode(publicKeyContent)
-	if block == nil {
-		return nil, errors.Wrap(err, "error decoding PEM file")
+	keySet, err := json.MarshalIndent(JSONWebKeySet{Keys: keys}, "", "    ")
+	if err != nil {
+		return nil, errors.New("JSON encoding of web key set failed")
 	}
 
-	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	return keySet, nil
+}
+
+// jsonWebKeyFromPublicKey reads the PEM encoded RSA public key at publicKeyPath and returns the JSON web key of the
+// public key, identified by the key ID derived from the public key
+func jsonWebKeyFromPublicKey(publicKeyPath string) (jose.JSONWebKey, error) {
+	log.Print("Reading public key")
+	publicKey, err := readPublicKey(publicKeyPath)
 	if err != nil {
-		return nil, errors.Wrap(err, "error parsing key content")
+		return jose.JSONWebKey{}, err
 	}
 
 	var alg jose.SignatureAlgorithm
@@ -109,28 +204,20 @@ func BuildJsonWebKeySet(publicKeyPath string) ([]byte, error) {
 	case *rsa.PublicKey:
 		alg = jose.RS256
 	default:
-		return nil, errors.New("public key is not of type RSA")
+		return jose.JSONWebKey{}, fmt.Errorf("public key %s uses the %s algorithm, only RSA service account signing keys are supported", publicKeyPath, keyAlgorithm(publicKey))
 	}
 
 	kid, err := KeyIDFromPublicKey(publicKey)
 	if err != nil {
-		return nil, errors.New("Failed to fetch key ID from public key")
+		return jose.JSONWebKey{}, errors.New("Failed to fetch key ID from public key")
 	}
 
-	var keys []jose.JSONWebKey
-	keys = append(keys, jose.JSONWebKey{
+	return jose.JSONWebKey{
 		Key:       publicKey,
 		KeyID:     kid,
 		Algorithm: string(alg),
 		Use:       "sig",
-	})
-
-	keySet, err := json.MarshalIndent(JSONWebKeySet{Keys: keys}, "", "    ")
-	if err != nil {
-		return nil, errors.New("JSON encoding of web key set failed")
-	}
-
-	return keySet, nil
+	}, nil
 }
 
 // KeyIDFromPublicKey derives a key ID non-reversibly from a public key
@@ -150,57 +237,427 @@ func KeyIDFromPublicKey(publicKey interface{}) (string, error) {
 	return keyID, nil
 }
 
-// GetListOfCredentialsRequests decodes manifests in a given directory and returns a list of CredentialsRequests
-func GetListOfCredentialsRequests(dir string, enableTechPreview bool) ([]*credreqv1.CredentialsRequest, error) {
+// ReadCredentialsSource reads the credentials identified by source, which is either the path of a file, "-" to read
+// the credentials from stdin or "env:NAME" to read the credentials from the environment variable NAME. Errors never
+// include the credentials that were read.
+func ReadCredentialsSource(source string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case source == CredentialsSourceStdin:
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read credentials from stdin")
+		}
+		if len(data) == 0 {
+			return nil, errors.New("no credentials were provided on stdin")
+		}
+		return data, nil
+	case strings.HasPrefix(source, CredentialsSourceEnvPrefix):
+		env := strings.TrimPrefix(source, CredentialsSourceEnvPrefix)
+		data, ok := os.LookupEnv(env)
+		if !ok || data == "" {
+			return nil, fmt.Errorf("environment variable %q is not set", env)
+		}
+		return []byte(data), nil
+	default:
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read credentials file %s", source)
+		}
+		return data, nil
+	}
+}
+
+// ValidateClusterID validates that the OpenShift cluster ID with which cloud resources are tagged is usable within the
+// tag keys of every cloud provider supporting --cluster-id, which restrict the characters and length of keys.
+func ValidateClusterID(clusterID string) error {
+	if clusterID == "" {
+		return nil
+	}
+	if len(clusterID) > maxClusterIDLength {
+		return fmt.Errorf("invalid --cluster-id %q, cluster ID must not be longer than %d characters", clusterID, maxClusterIDLength)
+	}
+	if !clusterIDRegexp.MatchString(clusterID) {
+		return fmt.Errorf("invalid --cluster-id %q, cluster ID may only contain letters, numbers, '-', '_' and '.' and must start and end with a letter or number", clusterID)
+	}
+	return nil
+}
+
+// Quota is the current usage of a cloud quota limiting the number of resources which ccoctl may create
+type Quota struct {
+	// Name describes the quota, eg. "IAM roles per account"
+	Name  string
+	Usage int
+	Limit int
+}
+
+// CheckQuota returns an error naming the quota and how many more resources it must allow when creating required
+// resources would exceed quota.
+func CheckQuota(quota Quota, required int) error {
+	available := quota.Limit - quota.Usage
+	if available < 0 {
+		available = 0
+	}
+	if required <= available {
+		return nil
+	}
+	return fmt.Errorf("creating %d resources would exceed the quota of %d %s, %d are already in use so the quota must allow %d more, "+
+		"request a quota increase or remove unused resources before retrying (or pass --skip-quota-check to skip this check)",
+		required, quota.Limit, quota.Name, quota.Usage, required-available)
+}
+
+// LimitViolation is an artifact generated by ccoctl, such as a policy document, exceeding a hard limit of the cloud
+// provider on its size or on the number of its elements
+type LimitViolation struct {
+	// Component identifies the input the artifact was generated from, eg. "CredentialsRequest openshift-ingress-operator/openshift-ingress"
+	Component string
+	// Artifact describes the generated artifact, eg. "the policy of IAM Role mycluster-openshift-ingress-operator-cloud-credentials"
+	Artifact string
+	Size     int
+	Limit    int
+	// Unit is the unit of Size and Limit, eg. "characters"
+	Unit string
+	// Limited describes what the cloud provider applies the limit to, eg. "of the inline policies of an IAM Role"
+	Limited string
+	// Hint describes how the violation may be resolved, if it may
+	Hint string
+}
+
+func (v LimitViolation) Error() string {
+	message := fmt.Sprintf("%s requires %d %s, exceeding the limit of %d %s %s", v.Artifact, v.Size, v.Unit, v.Limit, v.Unit, v.Limited)
+	if v.Component != "" {
+		message = v.Component + ": " + message
+	}
+	if v.Hint != "" {
+		message += ", " + v.Hint
+	}
+	return message
+}
+
+// LimitViolations is returned when artifacts generated by ccoctl exceed the limits of the cloud provider. It reports
+// every violation at once so that the CredentialsRequests may all be fixed before running ccoctl again.
+type LimitViolations []LimitViolation
+
+func (v LimitViolations) Error() string {
+	lines := make([]string, 0, len(v)+1)
+	lines = append(lines, fmt.Sprintf("found %d generated artifacts exceeding the limits of the cloud provider:", len(v)))
+	for _, violation := range v {
+		lines = append(lines, "  "+violation.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Add records that the artifact of component requires size units, unless it is within limit. Violations returned as
+// errors by the generation of artifacts may be recorded with AddError.
+func (v *LimitViolations) Add(violation LimitViolation) {
+	if violation.Size <= violation.Limit {
+		return
+	}
+	*v = append(*v, violation)
+}
+
+// AddError records err as a violation of component when it is a LimitViolation, which is returned by the generation
+// of artifacts failing on a limit. Any other error is returned.
+func (v *LimitViolations) AddError(component string, err error) error {
+	var violation LimitViolation
+	if !errors.As(err, &violation) {
+		return err
+	}
+	if violation.Component == "" {
+		violation.Component = component
+	}
+	*v = append(*v, violation)
+	return nil
+}
+
+// Err returns the violations as an error, or nil when no artifact exceeds the limits of the cloud provider
+func (v LimitViolations) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// CredentialsRequestComponent identifies cr as the component from which an artifact is generated within a
+// LimitViolation
+func CredentialsRequestComponent(cr *credreqv1.CredentialsRequest) string {
+	return fmt.Sprintf("CredentialsRequest %s/%s", cr.Namespace, cr.Name)
+}
+
+// CheckPriorProvisioning returns an error listing resources, the existing resources created by ccoctl for name by a
+// prior provisioning, so that ccoctl refuses to adopt or overwrite the resources of another cluster provisioned with
+// the same --name. override describes the flags with which the check is skipped.
+func CheckPriorProvisioning(name string, resources []string, override string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	return fmt.Errorf("a prior provisioning with --name %s exists, found resources created by ccoctl for the name: %s. "+
+		"Another cluster may have been provisioned with the same --name, provide a different --name or %s",
+		name, strings.Join(resources, ", "), override)
+}
+
+// DescribeCredentialsSource returns a description of the credentials source suitable for logging
+func DescribeCredentialsSource(source string) string {
+	switch {
+	case source == CredentialsSourceStdin:
+		return "stdin"
+	case strings.HasPrefix(source, CredentialsSourceEnvPrefix):
+		return fmt.Sprintf("environment variable %q", strings.TrimPrefix(source, CredentialsSourceEnvPrefix))
+	default:
+		return fmt.Sprintf("file %q", source)
+	}
+}
+
+// GetListOfCredentialsRequests decodes manifests in the given directories and returns a list of CredentialsRequests.
+// A ManifestErrors reporting every malformed or invalid manifest is returned when any of the manifests does not describe
+// a valid CredentialsRequest.
+//
+// Several directories may be provided, as recorded for a --credentials-requests-dir flag which was specified multiple
+// times, in which case the CredentialsRequests decoded from each directory are merged. An error is returned when
+// CredentialsRequests from different directories target the same secret.
+func GetListOfCredentialsRequests(dirs []string, enableTechPreview bool) ([]*credreqv1.CredentialsRequest, error) {
+	if len(dirs) == 1 {
+		return getCredentialsRequestsFromDir(dirs[0], enableTechPreview)
+	}
+
+	// The manifests of every directory are validated before merging so that all invalid manifests are reported at once
+	dirsCredRequests := make([][]*credreqv1.CredentialsRequest, 0, len(dirs))
+	manifestErrs := ManifestErrors{}
+	for _, d := range dirs {
+		dirCredRequests, dirManifestErrs, err := loadCredentialsRequestsFromDir(d, enableTechPreview, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to process CredentialsRequests in directory %s", d)
+		}
+		dirsCredRequests = append(dirsCredRequests, dirCredRequests)
+		manifestErrs = append(manifestErrs, dirManifestErrs...)
+	}
+	if len(manifestErrs) > 0 {
+		return nil, manifestErrs
+	}
+
+	credRequests := make([]*credreqv1.CredentialsRequest, 0)
+	// sources maps the namespace/name of each targeted secret to the directory of the CredentialsRequest targeting it
+	sources := map[string]string{}
+	for i, d := range dirs {
+		dirCredRequests := dirsCredRequests[i]
+		for _, cr := range dirCredRequests {
+			secretTarget := fmt.Sprintf("%s/%s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+			if source, found := sources[secretTarget]; found && source != d {
+				return nil, fmt.Errorf("CredentialsRequest %s/%s in directory %s targets secret %s which is also targeted by a CredentialsRequest in directory %s",
+					cr.Namespace, cr.Name, d, secretTarget, source)
+			}
+			sources[secretTarget] = d
+			log.Printf("Using CredentialsRequest %s/%s targeting secret %s from directory %s", cr.Namespace, cr.Name, secretTarget, d)
+			credRequests = append(credRequests, cr)
+		}
+	}
+	log.Printf("Merged %d CredentialsRequests from directories %s", len(credRequests), strings.Join(dirs, ", "))
+
+	return credRequests, nil
+}
+
+// getCredentialsRequestsFromDir decodes manifests in a given directory and returns a list of CredentialsRequests.
+// A ManifestErrors is returned when any of the manifests is malformed or does not describe a valid CredentialsRequest.
+func getCredentialsRequestsFromDir(dir string, enableTechPreview bool) ([]*credreqv1.CredentialsRequest, error) {
+	credRequests, manifestErrs, err := loadCredentialsRequestsFromDir(dir, enableTechPreview, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifestErrs) > 0 {
+		return nil, manifestErrs
+	}
+	return credRequests, nil
+}
+
+// loadCredentialsRequestsFromDir decodes and validates the manifests in a given directory. The CredentialsRequests to
+// be processed by ccoctl are returned along with the errors of every manifest which is malformed or invalid. The
+// CredentialsRequests which are ignored are logged when logIgnored is set.
+func loadCredentialsRequestsFromDir(dir string, enableTechPreview, logIgnored bool) ([]*credreqv1.CredentialsRequest, ManifestErrors, error) {
+	manifests, err := decodeCredentialsRequestManifests(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	credRequests := make([]*credreqv1.CredentialsRequest, 0)
+	manifestErrs := ManifestErrors{}
+	for _, manifest := range manifests {
+		if manifest.err != nil {
+			manifestErrs = append(manifestErrs, ManifestError{Path: manifest.path, Document: manifest.document, Err: manifest.err})
+			continue
+		}
+		cr := manifest.credRequest
+		if reason := ignoredCredentialsRequestReason(cr, enableTechPreview); reason != "" {
+			if logIgnored {
+				log.Printf("Ignoring CredentialsRequest %s/%s %s", cr.Namespace, cr.Name, reason)
+			}
+			continue
+		}
+		if errs := ValidateCredentialsRequest(cr); len(errs) > 0 {
+			manifestErrs = append(manifestErrs, ManifestError{Path: manifest.path, Document: manifest.document, Errors: errs})
+			continue
+		}
+		credRequests = append(credRequests, cr)
+	}
+	return credRequests, manifestErrs, nil
+}
+
+// ignoredCredentialsRequestReason returns why the CredentialsRequest should not be consumed by ccoctl, or an empty
+// string when the CredentialsRequest should be processed.
+func ignoredCredentialsRequestReason(cr *credreqv1.CredentialsRequest, enableTechPreview bool) string {
+	// Ignore CredentialsRequest manifest if it has "release.openshift.io/delete" annotation with value "true"
+	// These manifests are marked for in-cluster deletion and should not be consumed by ccoctl to create credentials
+	// infrastructure.
+	if value, ok := cr.Annotations[deletionAnnotation]; ok && value == "true" {
+		return "as it is marked for in-cluster deletion"
+	}
+
+	// Handle CredentialsRequest with the feature-gate annotation
+	if value, ok := cr.Annotations[featureGateAnnotation]; ok {
+		if !enableTechPreview {
+			return "with tech-preview annotation"
+		}
+		if value != string(configv1.TechPreviewNoUpgrade) {
+			return fmt.Sprintf("with tech-preview value %s", value)
+		} // else allow it to be added it to the list of CredReqs to process
+	}
+	return ""
+}
+
+// credentialsRequestManifest is a document of a manifest file along with the CredentialsRequest decoded from it, or
+// the error encountered decoding it
+type credentialsRequestManifest struct {
+	path        string
+	document    int
+	credRequest *credreqv1.CredentialsRequest
+	err         error
+}
+
+// decodeCredentialsRequestManifests decodes every document of the .yaml and .yml manifest files in dir. Documents
+// which cannot be decoded are returned with the decoding error so that the errors of every manifest may be reported
+// together.
+func decodeCredentialsRequestManifests(dir string) ([]credentialsRequestManifest, error) {
 	files, err := ioutil.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
 
+	manifests := []credentialsRequestManifest{}
 	for _, file := range files {
 		if file.IsDir() || !(strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml")) {
 			continue
 		}
-		f, err := os.Open(filepath.Join(dir, file.Name()))
+		path := filepath.Join(dir, file.Name())
+		f, err := os.Open(path)
 		if err != nil {
 			return nil, errors.Wrap(err, "Failed to open file")
 		}
 		defer f.Close()
-		decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
-		for {
-			cr := &credreqv1.CredentialsRequest{}
-			if err := decoder.Decode(cr); err != nil {
+		reader := yaml.NewYAMLReader(bufio.NewReader(f))
+		for document := 1; ; document++ {
+			data, err := reader.Read()
+			if err != nil {
 				if err == io.EOF {
 					break
 				}
-				return nil, errors.Wrap(err, "Failed to decode to CredentialsRequest")
+				return nil, errors.Wrapf(err, "Failed to read file %s", path)
 			}
-			// Ignore CredentialsRequest manifest if it has "release.openshift.io/delete" annotation with value "true"
-			// These manifests are marked for in-cluster deletion and should not be consumed by ccoctl to create credentials
-			// infrastructure.
-			if value, ok := cr.Annotations["release.openshift.io/delete"]; ok && value == "true" {
-				log.Printf("Ignoring CredentialsRequest %s/%s as it is marked for in-cluster deletion", cr.Namespace, cr.Name)
+			cr := &credreqv1.CredentialsRequest{}
+			if err := sigsyaml.Unmarshal(data, cr); err != nil {
+				manifests = append(manifests, credentialsRequestManifest{path: path, document: document, err: errors.Wrap(err, "Failed to decode to CredentialsRequest")})
 				continue
 			}
-
-			// Handle CredentialsRequest with the feature-gate annotation
-			if value, ok := cr.Annotations[featureGateAnnotation]; ok {
-				if !enableTechPreview {
-					log.Printf("Ignoring CredentialsRequest %s/%s with tech-preview annotation", cr.Namespace, cr.Name)
-					continue
-				}
-				if value != string(configv1.TechPreviewNoUpgrade) {
-					log.Printf("Ignoring CredentialsRequest %s/%s with tech-preview value %s", cr.Namespace, cr.Name, value)
-					continue
-				} // else allow it to be added it to the list of CredReqs to process
+			// Skip documents without content, eg. a leading document made only of comments
+			if reflect.DeepEqual(*cr, credreqv1.CredentialsRequest{}) {
+				continue
 			}
-
-			credRequests = append(credRequests, cr)
+			manifests = append(manifests, credentialsRequestManifest{path: path, document: document, credRequest: cr})
 		}
 	}
+	return manifests, nil
+}
 
-	return credRequests, nil
+// ValidateCredentialsRequest validates the fields of a CredentialsRequest required by the CredentialsRequest
+// CustomResourceDefinition and by ccoctl, which creates the secret identified by spec.secretRef from spec.providerSpec
+func ValidateCredentialsRequest(cr *credreqv1.CredentialsRequest) field.ErrorList {
+	errs := field.ErrorList{}
+	if cr.APIVersion != "" && cr.APIVersion != credreqv1.SchemeGroupVersion.String() {
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), cr.APIVersion, []string{credreqv1.SchemeGroupVersion.String()}))
+	}
+	if cr.Kind != "" && cr.Kind != credentialsRequestKind {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), cr.Kind, []string{credentialsRequestKind}))
+	}
+	if cr.Name == "" {
+		errs = append(errs, field.Required(field.NewPath("metadata", "name"), ""))
+	}
+	specPath := field.NewPath("spec")
+	if cr.Spec.SecretRef.Name == "" {
+		errs = append(errs, field.Required(specPath.Child("secretRef", "name"), "the name of the secret to create"))
+	}
+	if cr.Spec.SecretRef.Namespace == "" {
+		errs = append(errs, field.Required(specPath.Child("secretRef", "namespace"), "the namespace of the secret to create"))
+	}
+	if cr.Spec.ProviderSpec == nil || len(cr.Spec.ProviderSpec.Raw) == 0 {
+		errs = append(errs, field.Required(specPath.Child("providerSpec"), "the permissions to grant"))
+	}
+	return errs
+}
+
+// ManifestError records the errors of a single document of a CredentialsRequest manifest file
+type ManifestError struct {
+	// Path is the path of the manifest file
+	Path string
+	// Document is the 1-based index of the document within the manifest file
+	Document int
+	// Err is the error encountered decoding the document, if any
+	Err error
+	// Errors are the errors found validating the decoded CredentialsRequest, identified by field path
+	Errors field.ErrorList
+}
+
+func (e ManifestError) Error() string {
+	location := e.Path
+	if e.Document > 1 {
+		location = fmt.Sprintf("%s (document %d)", e.Path, e.Document)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", location, e.Err)
+	}
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%s: %s", location, strings.Join(messages, ", "))
+}
+
+// ManifestErrors is returned when CredentialsRequest manifests are malformed or invalid. It reports the errors of
+// every invalid manifest at once so that they may all be fixed before running ccoctl again.
+type ManifestErrors []ManifestError
+
+func (e ManifestErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("found %d invalid CredentialsRequest manifests:", len(e)))
+	for _, err := range e {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateCredentialsRequests decodes and validates the CredentialsRequest manifests within dirs, as accepted by
+// GetListOfCredentialsRequests, without logging the CredentialsRequests which are ignored. A ManifestErrors reporting
+// every invalid manifest is returned so that commands may report every malformed manifest at once, before any cloud
+// resources are created.
+func ValidateCredentialsRequests(dirs []string, enableTechPreview bool) error {
+	manifestErrs := ManifestErrors{}
+	for _, d := range dirs {
+		_, dirManifestErrs, err := loadCredentialsRequestsFromDir(d, enableTechPreview, false)
+		if err != nil {
+			return errors.Wrapf(err, "failed to process CredentialsRequests in directory %s", d)
+		}
+		manifestErrs = append(manifestErrs, dirManifestErrs...)
+	}
+	if len(manifestErrs) > 0 {
+		return manifestErrs
+	}
+	return nil
 }
 
 func ShortenName(name string, maxLength int) string {
@@ -1,8 +1,12 @@
 package provisioning
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -95,6 +99,214 @@ func TestEnsureDir(t *testing.T) {
 	}
 }
 
+func TestEnsureDirCreatesParents(t *testing.T) {
+	tempDirName, err := os.MkdirTemp(os.TempDir(), "ensuredirtestdir")
+	require.NoError(t, err, "failed to create temp directory")
+	defer os.RemoveAll(tempDirName)
+
+	nestedDirPath := filepath.Join(tempDirName, "missing", "output")
+	err = EnsureDir(nestedDirPath)
+	require.NoError(t, err, "unexpected error creating nested directory")
+
+	sResult, err := os.Stat(nestedDirPath)
+	require.NoError(t, err, "failed to stat")
+	assert.True(t, sResult.IsDir(), "expected directory to be created")
+	assert.Equal(t, os.FileMode(0), sResult.Mode().Perm()&0077, "directory should only be accessible by its owner")
+}
+
+func TestValidateSecretsDir(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        os.FileMode
+		allow       bool
+		expectError bool
+	}{
+		{
+			name: "Directory only writable by owner",
+			mode: 0700,
+		},
+		{
+			name: "Directory readable by group and world",
+			mode: 0755,
+		},
+		{
+			name:        "Directory writable by group",
+			mode:        0770,
+			expectError: true,
+		},
+		{
+			name:        "Directory writable by world",
+			mode:        0707,
+			expectError: true,
+		},
+		{
+			name:  "Directory writable by world allowed",
+			mode:  0777,
+			allow: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tempDirName, err := os.MkdirTemp(os.TempDir(), "secretsdirtestdir")
+			require.NoError(t, err, "failed to create temp directory")
+			defer os.RemoveAll(tempDirName)
+			// Chmod is not subject to the umask
+			err = os.Chmod(tempDirName, test.mode)
+			require.NoError(t, err, "error setting up test environment")
+
+			err = validateSecretsDir(tempDirName, test.allow)
+			if test.expectError {
+				assert.Error(t, err, "expected error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestWriteSecretFile(t *testing.T) {
+	tempDirName, err := os.MkdirTemp(os.TempDir(), "secretfiletestdir")
+	require.NoError(t, err, "failed to create temp directory")
+	defer os.RemoveAll(tempDirName)
+
+	secretFilePath := filepath.Join(tempDirName, "secret.yaml")
+	err = WriteSecretFile(secretFilePath, []byte("secret"))
+	require.NoError(t, err, "unexpected error writing secret file")
+	sResult, err := os.Stat(secretFilePath)
+	require.NoError(t, err, "failed to stat")
+	assert.Equal(t, os.FileMode(0600), sResult.Mode().Perm(), "unexpected secret file permissions")
+
+	// The permissions of a pre-existing world readable file are restricted
+	err = os.Chmod(secretFilePath, 0644)
+	require.NoError(t, err, "error setting up test environment")
+	err = WriteSecretFile(secretFilePath, []byte("updated secret"))
+	require.NoError(t, err, "unexpected error writing secret file")
+	sResult, err = os.Stat(secretFilePath)
+	require.NoError(t, err, "failed to stat")
+	assert.Equal(t, os.FileMode(0600), sResult.Mode().Perm(), "unexpected secret file permissions")
+}
+
+func TestPrintIssuerURL(t *testing.T) {
+	var stdout bytes.Buffer
+	err := PrintIssuerURL(&stdout, "https://testissuer.example.com/prefix")
+	require.NoError(t, err, "unexpected error printing issuer URL")
+	assert.Equal(t, "https://testissuer.example.com/prefix\n", stdout.String(), "only the issuer URL should be printed")
+}
+
+func TestReadCredentialsSource(t *testing.T) {
+	tempDirName, err := os.MkdirTemp(os.TempDir(), "credentialssourcetestdir")
+	require.NoError(t, err, "failed to create temp directory")
+	defer os.RemoveAll(tempDirName)
+	credentialsFile := filepath.Join(tempDirName, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("filecredentials"), 0600), "failed to write credentials file")
+	t.Setenv("CCOCTL_TEST_CREDENTIALS", "envcredentials")
+
+	tests := []struct {
+		name                string
+		source              string
+		stdin               string
+		expectedCredentials string
+		expectedDescription string
+		expectError         bool
+	}{
+		{
+			name:                "Credentials read from file",
+			source:              credentialsFile,
+			expectedCredentials: "filecredentials",
+			expectedDescription: fmt.Sprintf("file %q", credentialsFile),
+		},
+		{
+			name:                "Credentials read from stdin",
+			source:              "-",
+			stdin:               "stdincredentials",
+			expectedCredentials: "stdincredentials",
+			expectedDescription: "stdin",
+		},
+		{
+			name:                "Credentials read from environment variable",
+			source:              "env:CCOCTL_TEST_CREDENTIALS",
+			expectedCredentials: "envcredentials",
+			expectedDescription: `environment variable "CCOCTL_TEST_CREDENTIALS"`,
+		},
+		{
+			name:                "Non-existent credentials file",
+			source:              filepath.Join(tempDirName, "missing"),
+			expectedDescription: fmt.Sprintf("file %q", filepath.Join(tempDirName, "missing")),
+			expectError:         true,
+		},
+		{
+			name:                "Empty stdin",
+			source:              "-",
+			expectedDescription: "stdin",
+			expectError:         true,
+		},
+		{
+			name:                "Unset environment variable",
+			source:              "env:CCOCTL_TEST_UNSET_CREDENTIALS",
+			expectedDescription: `environment variable "CCOCTL_TEST_UNSET_CREDENTIALS"`,
+			expectError:         true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			credentials, err := ReadCredentialsSource(test.source, bytes.NewBufferString(test.stdin))
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectedCredentials, string(credentials))
+			}
+			assert.Equal(t, test.expectedDescription, DescribeCredentialsSource(test.source))
+		})
+	}
+}
+
+func TestValidateClusterID(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterID   string
+		expectError bool
+	}{
+		{
+			name: "No cluster ID",
+		},
+		{
+			name:      "UUID cluster ID",
+			clusterID: "5a1c7b3e-0d6f-4c2a-9b8e-1f2d3c4b5a69",
+		},
+		{
+			name:      "Infrastructure name cluster ID",
+			clusterID: "mycluster-x7k2p.example_1",
+		},
+		{
+			name:        "Cluster ID with slash",
+			clusterID:   "my/cluster",
+			expectError: true,
+		},
+		{
+			name:        "Cluster ID ending with separator",
+			clusterID:   "mycluster-",
+			expectError: true,
+		},
+		{
+			name:        "Cluster ID too long for AWS tag keys",
+			clusterID:   strings.Repeat("a", maxClusterIDLength+1),
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateClusterID(test.clusterID)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
 func TestFilteringCredReqs(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -158,7 +370,7 @@ func TestFilteringCredReqs(t *testing.T) {
 
 			test.setup(t)
 
-			credReqs, err := GetListOfCredentialsRequests(testDirPath, test.enableTechPreview)
+			credReqs, err := GetListOfCredentialsRequests([]string{testDirPath}, test.enableTechPreview)
 			require.NoError(t, err, "unexpected error")
 
 			test.verify(t, credReqs)
@@ -169,6 +381,179 @@ func TestFilteringCredReqs(t *testing.T) {
 	}
 }
 
+func TestMergingCredReqsFromMultipleDirs(t *testing.T) {
+	tests := []struct {
+		name        string
+		dirA        []*credreqv1.CredentialsRequest
+		dirB        []*credreqv1.CredentialsRequest
+		expectError bool
+		expectCount int
+	}{
+		{
+			name: "CredentialsRequests from both directories are merged",
+			dirA: []*credreqv1.CredentialsRequest{
+				NewCredentialsRequestBuilder().Build(WithName("credReqA"), WithSecretRef("namespaceA", "secretA")),
+			},
+			dirB: []*credreqv1.CredentialsRequest{
+				NewCredentialsRequestBuilder().Build(WithName("credReqB"), WithSecretRef("namespaceB", "secretB")),
+				NewCredentialsRequestBuilder().Build(WithName("credReqC"), WithSecretRef("namespaceB", "secretC")),
+			},
+			expectCount: 3,
+		},
+		{
+			name: "CredentialsRequests from different directories targeting the same secret",
+			dirA: []*credreqv1.CredentialsRequest{
+				NewCredentialsRequestBuilder().Build(WithName("credReqA"), WithSecretRef("namespaceA", "secretA")),
+			},
+			dirB: []*credreqv1.CredentialsRequest{
+				NewCredentialsRequestBuilder().Build(WithName("credReqB"), WithSecretRef("namespaceA", "secretA")),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dirA, err := os.MkdirTemp(os.TempDir(), "credreqdira")
+			require.NoError(t, err, "failed to create temp directory")
+			defer os.RemoveAll(dirA)
+			dirB, err := os.MkdirTemp(os.TempDir(), "credreqdirb")
+			require.NoError(t, err, "failed to create temp directory")
+			defer os.RemoveAll(dirB)
+
+			for _, cr := range test.dirA {
+				saveCredReqToDir(t, dirA, cr)
+			}
+			for _, cr := range test.dirB {
+				saveCredReqToDir(t, dirB, cr)
+			}
+
+			credReqs, err := GetListOfCredentialsRequests([]string{dirA, dirB}, false)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+			} else {
+				require.NoError(t, err, "unexpected error")
+				assert.Equal(t, test.expectCount, len(credReqs))
+			}
+		})
+	}
+}
+
+func TestValidateCredentialsRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		credReq      *credreqv1.CredentialsRequest
+		expectFields []string
+	}{
+		{
+			name:    "Valid CredentialsRequest",
+			credReq: NewCredentialsRequestBuilder().Build(WithName("credReqA")),
+		},
+		{
+			name: "CredentialsRequest missing required fields",
+			credReq: Build(func(credreq *credreqv1.CredentialsRequest) {
+				credreq.Spec.SecretRef.Namespace = "test-namespace"
+			}),
+			expectFields: []string{"metadata.name", "spec.secretRef.name", "spec.providerSpec"},
+		},
+		{
+			name: "Manifest of another kind",
+			credReq: NewCredentialsRequestBuilder().Build(WithName("credReqA"), func(credreq *credreqv1.CredentialsRequest) {
+				credreq.APIVersion = "v1"
+				credreq.Kind = "Secret"
+			}),
+			expectFields: []string{"apiVersion", "kind"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := ValidateCredentialsRequest(test.credReq)
+			fields := []string{}
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.ElementsMatch(t, test.expectFields, fields)
+		})
+	}
+}
+
+func TestMalformedCredReqManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "malformedcredreqs")
+	require.NoError(t, err, "failed to create temp directory")
+	defer os.RemoveAll(dir)
+
+	saveCredReqToDir(t, dir, NewCredentialsRequestBuilder().Build(WithName("credReqA")))
+	manifests := map[string]string{
+		"a-invalid-yaml.yaml": "apiVersion: cloudcredential.openshift.io/v1\nkind: CredentialsRequest\nmetadata: [\n",
+		"b-wrong-type.yaml": `apiVersion: cloudcredential.openshift.io/v1
+kind: CredentialsRequest
+metadata:
+  name: credReqB
+spec:
+  secretRef: test-namespace/test-secret
+`,
+		"c-missing-fields.yaml": `# leading comment
+---
+apiVersion: cloudcredential.openshift.io/v1
+kind: CredentialsRequest
+metadata:
+  name: credReqC
+spec:
+  secretRef:
+    name: test-secret
+    namespace: test-namespace
+  providerSpec:
+    kind: AWSProviderSpec
+---
+apiVersion: cloudcredential.openshift.io/v1
+kind: CredentialsRequest
+metadata:
+  name: credReqD
+spec:
+  secretRef:
+    namespace: test-namespace
+`,
+	}
+	for name, content := range manifests {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600), "failed to write manifest")
+	}
+
+	for _, load := range []struct {
+		name string
+		load func() error
+	}{
+		{
+			name: "GetListOfCredentialsRequests",
+			load: func() error {
+				_, err := GetListOfCredentialsRequests([]string{dir}, false)
+				return err
+			},
+		},
+		{
+			name: "ValidateCredentialsRequests",
+			load: func() error {
+				return ValidateCredentialsRequests([]string{dir}, false)
+			},
+		},
+	} {
+		t.Run(load.name, func(t *testing.T) {
+			err := load.load()
+			require.Error(t, err, "expected error")
+
+			manifestErrs, ok := err.(ManifestErrors)
+			require.True(t, ok, "expected ManifestErrors, got %T", err)
+			require.Len(t, manifestErrs, 3, "expected an error for every invalid manifest: %s", err)
+			assert.Equal(t, filepath.Join(dir, "a-invalid-yaml.yaml"), manifestErrs[0].Path)
+			assert.Equal(t, filepath.Join(dir, "b-wrong-type.yaml"), manifestErrs[1].Path)
+			assert.Contains(t, manifestErrs[1].Error(), "spec.secretRef")
+			assert.Equal(t, filepath.Join(dir, "c-missing-fields.yaml"), manifestErrs[2].Path)
+			assert.Equal(t, 3, manifestErrs[2].Document)
+			assert.Contains(t, manifestErrs[2].Error(), "(document 3): spec.secretRef.name: Required value")
+			assert.Contains(t, manifestErrs[2].Error(), "spec.providerSpec: Required value")
+		})
+	}
+}
+
 func testNewCredReq(t *testing.T, crName string) {
 	cr := NewCredentialsRequestBuilder().
 		Options(WithName(crName)).
@@ -196,6 +581,10 @@ func testNewMarkedForDeletionCredReq(t *testing.T, crName string) {
 }
 
 func saveCredReq(t *testing.T, credReq *credreqv1.CredentialsRequest) {
+	saveCredReqToDir(t, testDirPath, credReq)
+}
+
+func saveCredReqToDir(t *testing.T, dir string, credReq *credreqv1.CredentialsRequest) {
 	re := &runtime.RawExtension{
 		Object: credReq,
 	}
@@ -203,7 +592,7 @@ func saveCredReq(t *testing.T, credReq *credreqv1.CredentialsRequest) {
 	out, err := re.MarshalJSON()
 	require.NoError(t, err, "error marshaling CredReq")
 
-	f, err := ioutil.TempFile(testDirPath, "credreq-testing-*.yaml")
+	f, err := ioutil.TempFile(dir, "credreq-testing-*.yaml")
 	require.NoError(t, err, "error creating temp file")
 	defer f.Close()
 
@@ -242,8 +631,16 @@ func (b *builder) Options(opts ...option) Builder {
 		options: append(b.options, opts...),
 	}
 }
+
+// NewCredentialsRequestBuilder returns a builder of CredentialsRequests which carry the fields validated by
+// ValidateCredentialsRequest, to which the provided options are applied
 func NewCredentialsRequestBuilder() Builder {
-	return &builder{}
+	return &builder{
+		options: []option{
+			WithSecretRef("test-namespace", "test-secret"),
+			WithProviderSpec(),
+		},
+	}
 }
 
 func WithName(name string) option {
@@ -269,3 +666,89 @@ func WithDeletionAnnotation() option {
 		credreq.Annotations[deletionAnnotation] = "true"
 	}
 }
+
+func WithProviderSpec() option {
+	return func(credreq *credreqv1.CredentialsRequest) {
+		credreq.Spec.ProviderSpec = &runtime.RawExtension{Raw: []byte(`{"apiVersion":"cloudcredential.openshift.io/v1","kind":"AWSProviderSpec"}`)}
+	}
+}
+
+func WithSecretRef(namespace, name string) option {
+	return func(credreq *credreqv1.CredentialsRequest) {
+		credreq.Spec.SecretRef.Namespace = namespace
+		credreq.Spec.SecretRef.Name = name
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		quota         Quota
+		required      int
+		expectError   bool
+		expectedError string
+	}{
+		{
+			name:     "Within quota",
+			quota:    Quota{Name: "IAM roles per account", Usage: 990, Limit: 1000},
+			required: 10,
+		},
+		{
+			name:          "Exceeds quota",
+			quota:         Quota{Name: "IAM roles per account", Usage: 995, Limit: 1000},
+			required:      10,
+			expectError:   true,
+			expectedError: "the quota must allow 5 more",
+		},
+		{
+			name:          "Usage above quota",
+			quota:         Quota{Name: "IAM roles per account", Usage: 1010, Limit: 1000},
+			required:      2,
+			expectError:   true,
+			expectedError: "the quota must allow 2 more",
+		},
+		{
+			name:  "Nothing required",
+			quota: Quota{Name: "IAM roles per account", Usage: 1000, Limit: 1000},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckQuota(test.quota, test.required)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				assert.Contains(t, err.Error(), test.expectedError)
+				assert.Contains(t, err.Error(), test.quota.Name)
+			} else {
+				require.NoError(t, err, "unexpected error")
+			}
+		})
+	}
+}
+
+func TestLimitViolations(t *testing.T) {
+	violations := LimitViolations{}
+	violations.Add(LimitViolation{Component: "CredentialsRequest ns/within", Artifact: "the policy of IAM Role within", Size: 10240, Limit: 10240, Unit: "characters", Limited: "of the inline policies of an IAM Role"})
+	assert.NoError(t, violations.Err(), "unexpected error for an artifact at the limit")
+
+	violations.Add(LimitViolation{Component: "CredentialsRequest ns/exceeding", Artifact: "the policy of IAM Role exceeding", Size: 10241, Limit: 10240, Unit: "characters", Limited: "of the inline policies of an IAM Role", Hint: "use --policy-style=managed"})
+	require.NoError(t, violations.AddError("CredentialsRequest ns/generated", LimitViolation{Artifact: "the policy of IAM Role generated", Size: 11, Limit: 10, Unit: "managed policies", Limited: "attached to an IAM Role"}), "unexpected error recording a violation")
+	otherErr := fmt.Errorf("unsupported policy style")
+	assert.Equal(t, otherErr, violations.AddError("CredentialsRequest ns/other", otherErr), "expected errors other than violations to be returned")
+
+	err := violations.Err()
+	require.Error(t, err, "expected error")
+	assert.Equal(t, `found 2 generated artifacts exceeding the limits of the cloud provider:
+  CredentialsRequest ns/exceeding: the policy of IAM Role exceeding requires 10241 characters, exceeding the limit of 10240 characters of the inline policies of an IAM Role, use --policy-style=managed
+  CredentialsRequest ns/generated: the policy of IAM Role generated requires 11 managed policies, exceeding the limit of 10 managed policies attached to an IAM Role`, err.Error(), "unexpected error")
+}
+
+func TestCheckPriorProvisioning(t *testing.T) {
+	assert.NoError(t, CheckPriorProvisioning("test-name", nil, "--force"), "unexpected error without prior provisioning")
+
+	err := CheckPriorProvisioning("test-name", []string{"resource group test-name-oidc", "user-assigned managed identity test-name-ns-secret"}, "--force to proceed regardless")
+	require.Error(t, err, "expected error with prior provisioning")
+	assert.Contains(t, err.Error(), "a prior provisioning with --name test-name exists", "expected error to report the prior provisioning")
+	assert.Contains(t, err.Error(), "resource group test-name-oidc, user-assigned managed identity test-name-ns-secret", "expected error to list the resources")
+	assert.Contains(t, err.Error(), "--force to proceed regardless", "expected error to describe the override")
+}
@@ -62,6 +62,16 @@ type Actuator struct {
 	Client           client.Client
 	Codec            *minterv1.ProviderCodec
 	GCPClientBuilder func(string, []byte) (ccgcp.Client, error)
+	// SecretSink stores the minted credentials, they are stored as Secrets in the cluster when nil.
+	SecretSink actuatoriface.SecretSink
+}
+
+// GetSecretSink returns the SecretSink through which credentials are stored.
+func (a *Actuator) GetSecretSink() actuatoriface.SecretSink {
+	if a.SecretSink == nil {
+		return actuatoriface.NewInClusterSecretSink(a.Client)
+	}
+	return a.SecretSink
 }
 
 func (a *Actuator) STSFeatureGateEnabled() bool {
@@ -156,7 +166,7 @@ func (a *Actuator) Exists(ctx context.Context, cr *minterv1.CredentialsRequest)
 	}
 
 	existingSecret := &corev1.Secret{}
-	err = a.Client.Get(ctx, types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, existingSecret)
+	err = a.GetSecretSink().Get(ctx, cr, existingSecret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Debug("target secret does not exist")
@@ -529,6 +539,15 @@ func (a *Actuator) needsUpdate(ctx context.Context, cr *minterv1.CredentialsRequ
 		return serviceAPIsEnabled, true, nil
 	}
 
+	existingSecret, err := a.loadExistingSecret(cr)
+	if err != nil {
+		return serviceAPIsEnabled, true, err
+	}
+	if actuatoriface.SecretTemplateOutdated(cr, existingSecret) {
+		logger.Info("secret keys rendered from spec.secretTemplate need updating")
+		return serviceAPIsEnabled, true, nil
+	}
+
 	if gcpStatus.ServiceAccountID != "" {
 		// serviceAccountID non-"" means we're in mint-mode
 
@@ -750,7 +769,7 @@ func (a *Actuator) secretAlreadySynced(cr *minterv1.CredentialsRequest) (bool, e
 	})
 
 	targetSecret := &corev1.Secret{}
-	err := a.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, targetSecret)
+	err := a.GetSecretSink().Get(context.TODO(), cr, targetSecret)
 	if err != nil {
 		logger.WithError(err).Error("error retrieving existing secret")
 		return false, err
@@ -782,7 +801,7 @@ func (a *Actuator) syncSecret(cr *minterv1.CredentialsRequest, privateKeyData []
 	})
 
 	existingSecret := &corev1.Secret{}
-	err := a.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, existingSecret)
+	err := a.GetSecretSink().Get(context.TODO(), cr, existingSecret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("no existing secret found, will create one")
@@ -799,8 +818,12 @@ func (a *Actuator) syncSecret(cr *minterv1.CredentialsRequest, privateKeyData []
 					gcpSecretJSONKey: privateKeyData,
 				},
 			}
+			if err := actuatoriface.ApplySecretTemplate(cr, secret); err != nil {
+				sLog.WithError(err).Error("error applying secret template")
+				return err
+			}
 
-			err := a.Client.Create(context.TODO(), secret)
+			err := a.GetSecretSink().Create(context.TODO(), cr, secret)
 			if err != nil {
 				sLog.WithError(err).Error("error creating secret")
 				return err
@@ -821,10 +844,14 @@ func (a *Actuator) syncSecret(cr *minterv1.CredentialsRequest, privateKeyData []
 	}
 	existingSecret.Annotations[minterv1.AnnotationCredentialsRequest] = fmt.Sprintf("%s/%s", cr.Namespace, cr.Name)
 	existingSecret.Data[gcpSecretJSONKey] = privateKeyData
+	if err := actuatoriface.ApplySecretTemplate(cr, existingSecret); err != nil {
+		sLog.WithError(err).Error("error applying secret template")
+		return err
+	}
 
 	if !reflect.DeepEqual(existingSecret, origSecret) {
 		sLog.Info("secret changed, updating")
-		err := a.Client.Update(context.TODO(), existingSecret)
+		err := a.GetSecretSink().Update(context.TODO(), cr, existingSecret)
 		if err != nil {
 			return fmt.Errorf("error updating existing secret: %v", err)
 		}
@@ -868,7 +895,7 @@ func (a *Actuator) loadExistingSecretKeyAuthID(cr *minterv1.CredentialsRequest)
 
 func (a *Actuator) loadExistingSecret(cr *minterv1.CredentialsRequest) (*corev1.Secret, error) {
 	secret := &corev1.Secret{}
-	err := a.Client.Get(context.TODO(), types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, secret)
+	err := a.GetSecretSink().Get(context.TODO(), cr, secret)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil, err
@@ -19,6 +19,7 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	iamcloud "cloud.google.com/go/iam"
@@ -32,7 +33,9 @@ import (
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	serviceusage "google.golang.org/api/serviceusage/v1"
+	htransport "google.golang.org/api/transport/http"
 	iamadminpb "google.golang.org/genproto/googleapis/iam/admin/v1"
+	"google.golang.org/grpc"
 )
 
 //go:generate mockgen -source=./client.go -destination=./mock/client_generated.go -package=mock
@@ -59,6 +62,8 @@ type Client interface {
 	UndeleteWorkloadIdentityPool(context.Context, string, *iam.UndeleteWorkloadIdentityPoolRequest) (*iam.Operation, error)
 	CreateWorkloadIdentityProvider(context.Context, string, string, *iam.WorkloadIdentityPoolProvider) (*iam.Operation, error)
 	GetWorkloadIdentityProvider(context.Context, string) (*iam.WorkloadIdentityPoolProvider, error)
+	ListWorkloadIdentityProviders(context.Context, string, string) (*iam.ListWorkloadIdentityPoolProvidersResponse, error)
+	DeleteWorkloadIdentityProvider(context.Context, string) (*iam.Operation, error)
 
 	//CloudResourceManager
 	GetProjectName() string
@@ -272,6 +277,18 @@ func (c *gcpClient) GetWorkloadIdentityProvider(ctx context.Context, resource st
 	return c.iamService.Projects.Locations.WorkloadIdentityPools.Providers.Get(resource).Context(ctx).Do()
 }
 
+func (c *gcpClient) ListWorkloadIdentityProviders(ctx context.Context, parent, pageToken string) (*iam.ListWorkloadIdentityPoolProvidersResponse, error) {
+	ctx, cancel := contextWithTimeout(ctx)
+	defer cancel()
+	return c.iamService.Projects.Locations.WorkloadIdentityPools.Providers.List(parent).PageToken(pageToken).Context(ctx).Do()
+}
+
+func (c *gcpClient) DeleteWorkloadIdentityProvider(ctx context.Context, resource string) (*iam.Operation, error) {
+	ctx, cancel := contextWithTimeout(ctx)
+	defer cancel()
+	return c.iamService.Projects.Locations.WorkloadIdentityPools.Providers.Delete(resource).Context(ctx).Do()
+}
+
 func (c *gcpClient) ListServicesEnabled() (map[string]bool, error) {
 	serviceMap := map[string]bool{}
 
@@ -386,31 +403,85 @@ func (c *gcpClient) DeleteObject(ctx context.Context, bucketName, objectName str
 	return c.storageClient.Bucket(bucketName).Object(objectName).Delete(ctx)
 }
 
-// NewClient creates our client wrapper object for interacting with GCP.
-func NewClient(projectName string, creds *google.Credentials) (Client, error) {
+// NewClient creates our client wrapper object for interacting with GCP. The provided options, eg. a user agent, are
+// applied to every API client.
+func NewClient(projectName string, creds *google.Credentials, opts ...option.ClientOption) (Client, error) {
+	opts = append([]option.ClientOption{option.WithCredentials(creds)}, opts...)
+	return newClient(projectName, creds, opts, opts)
+}
+
+// NewClientWithRetryPolicy creates our client wrapper object for interacting with GCP like NewClient, retrying the API
+// calls which failed transiently following retryPolicy instead of the default retries of the API clients.
+func NewClientWithRetryPolicy(projectName string, creds *google.Credentials, retryPolicy RetryPolicy, opts ...option.ClientOption) (Client, error) {
+	return NewClientWithMiddleware(projectName, creds, retryPolicy, Middleware{}, opts...)
+}
+
+// Middleware observes the requests sent by the API clients of our client wrapper object. The middleware is applied
+// below the retries of the API clients so that every attempt of a request is observed.
+type Middleware struct {
+	// Transport wraps the transport through which the REST API clients send their requests, when set
+	Transport func(http.RoundTripper) http.RoundTripper
+	// UnaryInterceptor intercepts the calls of the gRPC API client, when set
+	UnaryInterceptor grpc.UnaryClientInterceptor
+}
+
+// NewClientWithMiddleware creates our client wrapper object for interacting with GCP like NewClientWithRetryPolicy,
+// sending the requests of the API clients through middleware.
+func NewClientWithMiddleware(projectName string, creds *google.Credentials, retryPolicy RetryPolicy, middleware Middleware, opts ...option.ClientOption) (Client, error) {
+	opts = append([]option.ClientOption{option.WithCredentials(creds)}, opts...)
+
+	// The REST API clients send their requests through an authenticated transport retrying them, the gRPC API client
+	// retries its calls with an interceptor
+	var base http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+	if middleware.Transport != nil {
+		base = middleware.Transport(base)
+	}
+	transport, err := htransport.NewTransport(context.TODO(), newRetryTransport(base, retryPolicy), opts...)
+	if err != nil {
+		return nil, err
+	}
+	restOpts := []option.ClientOption{option.WithHTTPClient(&http.Client{Transport: transport})}
+	interceptors := []grpc.UnaryClientInterceptor{retryUnaryInterceptor(retryPolicy)}
+	if middleware.UnaryInterceptor != nil {
+		interceptors = append(interceptors, middleware.UnaryInterceptor)
+	}
+	grpcOpts := append(opts, option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(interceptors...)))
+
+	client, err := newClient(projectName, creds, restOpts, grpcOpts)
+	if err != nil {
+		return nil, err
+	}
+	// Drop the default retries of the calls of the gRPC API client, which would otherwise retry on top of the interceptor
+	client.iamClient.CallOptions = &iamadmin.IamCallOptions{}
+	return client, nil
+}
+
+// newClient creates the API clients of our client wrapper object, applying restOpts to the REST API clients and
+// grpcOpts to the gRPC API client
+func newClient(projectName string, creds *google.Credentials, restOpts, grpcOpts []option.ClientOption) (*gcpClient, error) {
 	ctx := context.TODO()
 
-	cloudResourceManagerClient, err := cloudresourcemanager.NewService(ctx, option.WithCredentials(creds))
+	cloudResourceManagerClient, err := cloudresourcemanager.NewService(ctx, restOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	iamClient, err := iamadmin.NewIamClient(ctx, option.WithCredentials(creds))
+	iamClient, err := iamadmin.NewIamClient(ctx, grpcOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	iamService, err := iam.NewService(ctx, option.WithCredentials(creds))
+	iamService, err := iam.NewService(ctx, restOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	serviceUsageClient, err := serviceusage.NewService(ctx, option.WithCredentials(creds))
+	serviceUsageClient, err := serviceusage.NewService(ctx, restOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	storageClient, err := storage.NewClient(ctx, option.WithCredentials(creds))
+	storageClient, err := storage.NewClient(ctx, restOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -214,6 +214,21 @@ func (mr *MockClientMockRecorder) DeleteWorkloadIdentityPool(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkloadIdentityPool", reflect.TypeOf((*MockClient)(nil).DeleteWorkloadIdentityPool), arg0, arg1)
 }
 
+// DeleteWorkloadIdentityProvider mocks base method.
+func (m *MockClient) DeleteWorkloadIdentityProvider(arg0 context.Context, arg1 string) (*iam0.Operation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkloadIdentityProvider", arg0, arg1)
+	ret0, _ := ret[0].(*iam0.Operation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWorkloadIdentityProvider indicates an expected call of DeleteWorkloadIdentityProvider.
+func (mr *MockClientMockRecorder) DeleteWorkloadIdentityProvider(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkloadIdentityProvider", reflect.TypeOf((*MockClient)(nil).DeleteWorkloadIdentityProvider), arg0, arg1)
+}
+
 // GetBucketAttrs mocks base method.
 func (m *MockClient) GetBucketAttrs(arg0 context.Context, arg1 string) (*storage.BucketAttrs, error) {
 	m.ctrl.T.Helper()
@@ -393,6 +408,21 @@ func (mr *MockClientMockRecorder) ListRoles(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockClient)(nil).ListRoles), arg0, arg1)
 }
 
+// ListWorkloadIdentityProviders mocks base method.
+func (m *MockClient) ListWorkloadIdentityProviders(arg0 context.Context, arg1, arg2 string) (*iam0.ListWorkloadIdentityPoolProvidersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkloadIdentityProviders", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*iam0.ListWorkloadIdentityPoolProvidersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkloadIdentityProviders indicates an expected call of ListWorkloadIdentityProviders.
+func (mr *MockClientMockRecorder) ListWorkloadIdentityProviders(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkloadIdentityProviders", reflect.TypeOf((*MockClient)(nil).ListWorkloadIdentityProviders), arg0, arg1, arg2)
+}
+
 // ListServiceAccountKeys mocks base method.
 func (m *MockClient) ListServiceAccountKeys(arg0 context.Context, arg1 *admin.ListServiceAccountKeysRequest) (*admin.ListServiceAccountKeysResponse, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,125 @@
+package gcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures the retries of the GCP API calls which failed transiently, ie. which were throttled or
+// failed with a server error. The delay before a retry starts at BaseDelay and doubles with every retry up to MaxDelay.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a failed API call is retried, API calls are not retried when zero
+	MaxRetries int
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before a retry
+	MaxDelay time.Duration
+}
+
+// delay returns the delay before the retry numbered retry, starting at zero
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// wait waits for delay unless ctx is done first, in which case the error of ctx is returned
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryTransport retries the requests of the REST API clients sent through base following policy
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	return &retryTransport{base: base, policy: policy}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		resp, err := t.base.RoundTrip(req)
+		if retry >= t.policy.MaxRetries || !retryableResponse(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := t.policy.delay(retry)
+		if resp != nil {
+			// Honour the delay requested by the API, as long as it does not exceed the maximum delay
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				delay = time.Duration(seconds) * time.Second
+				if delay > t.policy.MaxDelay {
+					delay = t.policy.MaxDelay
+				}
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		req = req.Clone(req.Context())
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retryableResponse returns whether the request req, which resulted in either resp or err, failed transiently. Throttled
+// requests and requests rejected as the API is unavailable were not processed and are always retried. Requests which
+// failed otherwise may have been processed, they are only retried when req is idempotent.
+func retryableResponse(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	default:
+		return false
+	}
+	return err != nil || resp.StatusCode == http.StatusInternalServerError || resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout
+}
+
+// retryUnaryInterceptor retries the calls of the gRPC API client following policy. Calls which were throttled or
+// rejected as the API is unavailable were not processed and are retried.
+func retryUnaryInterceptor(policy RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		for retry := 0; ; retry++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			code := status.Code(err)
+			if retry >= policy.MaxRetries || (code != codes.ResourceExhausted && code != codes.Unavailable) {
+				return err
+			}
+			if err := wait(ctx, policy.delay(retry)); err != nil {
+				return err
+			}
+		}
+	}
+}
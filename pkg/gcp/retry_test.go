@@ -0,0 +1,138 @@
+package gcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.delay(0))
+	assert.Equal(t, 2*time.Second, policy.delay(1))
+	assert.Equal(t, 4*time.Second, policy.delay(2))
+	assert.Equal(t, 5*time.Second, policy.delay(3), "expected the delay to be capped")
+	assert.Equal(t, 5*time.Second, policy.delay(100), "expected the delay to be capped")
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		statusCodes    []int
+		maxRetries     int
+		expectStatus   int
+		expectRequests int
+	}{
+		{
+			name:           "Throttled request retried",
+			method:         http.MethodPost,
+			statusCodes:    []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:     3,
+			expectStatus:   http.StatusOK,
+			expectRequests: 3,
+		},
+		{
+			name:           "Server error of an idempotent request retried",
+			method:         http.MethodGet,
+			statusCodes:    []int{http.StatusInternalServerError, http.StatusOK},
+			maxRetries:     3,
+			expectStatus:   http.StatusOK,
+			expectRequests: 2,
+		},
+		{
+			name:           "Server error of a non-idempotent request not retried",
+			method:         http.MethodPost,
+			statusCodes:    []int{http.StatusInternalServerError, http.StatusOK},
+			maxRetries:     3,
+			expectStatus:   http.StatusInternalServerError,
+			expectRequests: 1,
+		},
+		{
+			name:           "Client error not retried",
+			method:         http.MethodGet,
+			statusCodes:    []int{http.StatusNotFound, http.StatusOK},
+			maxRetries:     3,
+			expectStatus:   http.StatusNotFound,
+			expectRequests: 1,
+		},
+		{
+			name:           "Retries exhausted",
+			method:         http.MethodGet,
+			statusCodes:    []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			maxRetries:     2,
+			expectStatus:   http.StatusTooManyRequests,
+			expectRequests: 3,
+		},
+		{
+			name:           "Retries disabled",
+			method:         http.MethodGet,
+			statusCodes:    []int{http.StatusTooManyRequests, http.StatusOK},
+			maxRetries:     0,
+			expectStatus:   http.StatusTooManyRequests,
+			expectRequests: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			bodies := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err, "unexpected error reading the request body")
+				bodies = append(bodies, string(body))
+				w.WriteHeader(test.statusCodes[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, RetryPolicy{MaxRetries: test.maxRetries, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})}
+			req, err := http.NewRequest(test.method, server.URL, strings.NewReader("request"))
+			require.NoError(t, err, "unexpected error creating the request")
+			resp, err := client.Do(req)
+			require.NoError(t, err, "unexpected error")
+			resp.Body.Close()
+
+			assert.Equal(t, test.expectStatus, resp.StatusCode, "unexpected status code")
+			assert.Equal(t, test.expectRequests, requests, "unexpected number of requests")
+			for _, body := range bodies {
+				assert.Equal(t, "request", body, "expected the body to be sent again with every retry")
+			}
+		})
+	}
+}
+
+func TestRetryUnaryInterceptor(t *testing.T) {
+	interceptor := retryUnaryInterceptor(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	invoke := func(errs ...error) (int, error) {
+		calls := 0
+		err := interceptor(context.Background(), "/google.iam.admin.v1.IAM/GetRole", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			calls++
+			return errs[calls-1]
+		})
+		return calls, err
+	}
+
+	calls, err := invoke(status.Error(codes.Unavailable, "unavailable"), status.Error(codes.ResourceExhausted, "quota exceeded"), nil)
+	assert.NoError(t, err, "expected the call to eventually succeed")
+	assert.Equal(t, 3, calls, "expected unavailable and throttled calls to be retried")
+
+	calls, err = invoke(status.Error(codes.PermissionDenied, "denied"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the error of the call")
+	assert.Equal(t, 1, calls, "expected a call denied not to be retried")
+
+	calls, err = invoke(status.Error(codes.Unavailable, "1"), status.Error(codes.Unavailable, "2"), status.Error(codes.Unavailable, "3"), status.Error(codes.Unavailable, "4"))
+	assert.Equal(t, codes.Unavailable, status.Code(err), "expected the error of the last retry")
+	assert.Equal(t, 4, calls, "expected the call to be retried up to the maximum number of retries")
+}
@@ -131,6 +131,14 @@ const (
 	// CR when determining upgradeability.
 	UpgradeableAnnotation = "cloudcredential.openshift.io/upgradeable-to"
 
+	// PauseMintingAnnotation is the annotation CCO will check for on the cloudcredential.operator.openshift.io
+	// CR to determine whether minting and rotating credentials has been paused by an admin.
+	PauseMintingAnnotation = "cloudcredential.openshift.io/pause-minting"
+
+	// DryRunAnnotation is the annotation CCO will check for on a CredentialsRequest to determine whether an admin
+	// has requested that the changes syncing the CredentialsRequest are reported without being made.
+	DryRunAnnotation = "cloudcredential.openshift.io/dry-run"
+
 	// CCONameSpace Namespace defined for CCO to use
 	CCONameSpace = "openshift-cloud-credential-operator"
 
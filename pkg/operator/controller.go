@@ -65,12 +65,18 @@ var AddToManagerFuncs []func(manager.Manager, string) error
 var AddToManagerWithActuatorFuncs []func(manager.Manager, actuator.Actuator, configv1.PlatformType) error
 
 // AddToManager adds all Controllers to the Manager
-func AddToManager(m manager.Manager, explicitKubeconfig string, awsSecurityTokenServiveGateEnaled bool) error {
+func AddToManager(m manager.Manager, explicitKubeconfig string, awsSecurityTokenServiveGateEnaled bool, secretSinkName string) error {
 	for _, f := range AddToManagerFuncs {
 		if err := f(m, explicitKubeconfig); err != nil {
 			return err
 		}
 	}
+	// The sink stores the credentials provisioned by the AWS, Azure and GCP actuators, other actuators store them
+	// as Secrets in the cluster
+	secretSink, err := actuator.NewSecretSink(secretSinkName, m.GetClient())
+	if err != nil {
+		return err
+	}
 	for _, f := range AddToManagerWithActuatorFuncs {
 		// Check for supported platform types, dummy if not found:
 		// TODO: Use infrastructure type to determine this in future, it's not being populated yet:
@@ -85,16 +91,20 @@ func AddToManager(m manager.Manager, explicitKubeconfig string, awsSecurityToken
 		switch platformType {
 		case configv1.AWSPlatformType:
 			log.Info("initializing AWS actuator")
-			a, err = awsactuator.NewAWSActuator(m.GetClient(), m.GetScheme(), awsSecurityTokenServiveGateEnaled)
+			awsActuator, err := awsactuator.NewAWSActuator(m.GetClient(), m.GetScheme(), awsSecurityTokenServiveGateEnaled)
 			if err != nil {
 				return err
 			}
+			awsActuator.SecretSink = secretSink
+			a = awsActuator
 		case configv1.AzurePlatformType:
 			log.Info("initializing Azure actuator")
-			a, err = azure.NewActuator(m.GetClient(), util.GetAzureCloudName(infraStatus))
+			azureActuator, err := azure.NewActuator(m.GetClient(), util.GetAzureCloudName(infraStatus))
 			if err != nil {
 				return err
 			}
+			azureActuator.SecretSink = secretSink
+			a = azureActuator
 		case configv1.OpenStackPlatformType:
 			log.Info("initializing OpenStack actuator")
 			a, err = openstack.NewOpenStackActuator(m.GetClient())
@@ -106,10 +116,12 @@ func AddToManager(m manager.Manager, explicitKubeconfig string, awsSecurityToken
 			if infraStatus.PlatformStatus == nil || infraStatus.PlatformStatus.GCP == nil {
 				log.Fatalf("missing GCP configuration in platform status")
 			}
-			a, err = gcpactuator.NewActuator(m.GetClient(), infraStatus.PlatformStatus.GCP.ProjectID)
+			gcpActuator, err := gcpactuator.NewActuator(m.GetClient(), infraStatus.PlatformStatus.GCP.ProjectID)
 			if err != nil {
 				return err
 			}
+			gcpActuator.SecretSink = secretSink
+			a = gcpActuator
 		case configv1.OvirtPlatformType:
 			log.Info("initializing Ovirt actuator")
 			if infraStatus.PlatformStatus == nil || infraStatus.PlatformStatus.Ovirt == nil {
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The OpenShift Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package actuator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+const (
+	// InClusterSecretSinkName is the name of the SecretSink which stores minted credentials as Secrets in the
+	// cluster, which is the default.
+	InClusterSecretSinkName = "in-cluster"
+)
+
+// SecretSink stores the credentials minted for CredentialsRequests. Credentials are passed as the Secret which
+// the CredentialsRequest's spec.secretRef refers to, an implementation may store them in the cluster or route
+// them to an external secret store, eg. one consumed through the Secrets Store CSI driver.
+//
+// Implementations are registered with RegisterSecretSink and selected with the operator's --secret-sink flag.
+type SecretSink interface {
+	// Get reads the credentials stored for the CredentialsRequest into secret. A NotFound error is returned
+	// when no credentials are stored.
+	Get(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error
+	// Create stores new credentials for the CredentialsRequest.
+	Create(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error
+	// Update replaces the credentials stored for the CredentialsRequest, secret was previously read with Get.
+	Update(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error
+	// Delete removes the credentials stored for the CredentialsRequest. No error is returned when no
+	// credentials are stored.
+	Delete(ctx context.Context, cr *minterv1.CredentialsRequest) error
+}
+
+// SecretSinkActuator is implemented by actuators which store the credentials they mint through a SecretSink, the
+// CredentialsRequest controller then deletes the credentials of deleted CredentialsRequests through the same sink.
+type SecretSinkActuator interface {
+	GetSecretSink() SecretSink
+}
+
+// SecretSinkFactory creates a SecretSink. The client is the operator's client to the cluster.
+type SecretSinkFactory func(client.Client) (SecretSink, error)
+
+var (
+	secretSinkFactoriesMutex sync.Mutex
+	secretSinkFactories      = map[string]SecretSinkFactory{
+		InClusterSecretSinkName: func(c client.Client) (SecretSink, error) {
+			return NewInClusterSecretSink(c), nil
+		},
+	}
+)
+
+// RegisterSecretSink registers a SecretSink under the given name so that it may be selected with the operator's
+// --secret-sink flag. It is meant to be called from the init function of the package implementing the sink and
+// panics when the name is already registered.
+func RegisterSecretSink(name string, factory SecretSinkFactory) {
+	secretSinkFactoriesMutex.Lock()
+	defer secretSinkFactoriesMutex.Unlock()
+	if _, ok := secretSinkFactories[name]; ok {
+		panic(fmt.Sprintf("secret sink %q already registered", name))
+	}
+	secretSinkFactories[name] = factory
+}
+
+// SecretSinkNames returns the sorted names of the registered SecretSinks.
+func SecretSinkNames() []string {
+	secretSinkFactoriesMutex.Lock()
+	defer secretSinkFactoriesMutex.Unlock()
+	names := []string{}
+	for name := range secretSinkFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSecretSink creates the SecretSink registered under the given name.
+func NewSecretSink(name string, c client.Client) (SecretSink, error) {
+	secretSinkFactoriesMutex.Lock()
+	factory, ok := secretSinkFactories[name]
+	secretSinkFactoriesMutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown secret sink %q, must be one of %v", name, SecretSinkNames())
+	}
+	return factory(c)
+}
+
+// SecretSinkFor returns the SecretSink through which the actuator stores credentials, which is the in-cluster
+// sink for actuators which do not implement SecretSinkActuator.
+func SecretSinkFor(a Actuator, c client.Client) SecretSink {
+	if sinkActuator, ok := a.(SecretSinkActuator); ok {
+		if sink := sinkActuator.GetSecretSink(); sink != nil {
+			return sink
+		}
+	}
+	return NewInClusterSecretSink(c)
+}
+
+// IsInClusterSecretSink returns whether the sink stores credentials as Secrets in the cluster.
+func IsInClusterSecretSink(sink SecretSink) bool {
+	_, ok := sink.(*inClusterSecretSink)
+	return ok
+}
+
+// inClusterSecretSink stores credentials as the Secrets referenced by CredentialsRequests' spec.secretRef.
+type inClusterSecretSink struct {
+	client client.Client
+}
+
+// NewInClusterSecretSink returns a SecretSink which stores credentials as Secrets in the cluster.
+func NewInClusterSecretSink(c client.Client) SecretSink {
+	return &inClusterSecretSink{client: c}
+}
+
+func (s *inClusterSecretSink) Get(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	return s.client.Get(ctx, types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, secret)
+}
+
+func (s *inClusterSecretSink) Create(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	return s.client.Create(ctx, secret)
+}
+
+func (s *inClusterSecretSink) Update(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	return s.client.Update(ctx, secret)
+}
+
+func (s *inClusterSecretSink) Delete(ctx context.Context, cr *minterv1.CredentialsRequest) error {
+	secret := &corev1.Secret{}
+	if err := s.Get(ctx, cr, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := s.client.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The OpenShift Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package actuator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+// fakeSecretSink records the credentials it is asked to store
+type fakeSecretSink struct {
+	secrets map[string]*corev1.Secret
+}
+
+func (s *fakeSecretSink) Get(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	stored, ok := s.secrets[cr.Name]
+	if !ok {
+		return errors.NewNotFound(corev1.Resource("secrets"), cr.Spec.SecretRef.Name)
+	}
+	stored.DeepCopyInto(secret)
+	return nil
+}
+
+func (s *fakeSecretSink) Create(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.secrets[cr.Name] = secret.DeepCopy()
+	return nil
+}
+
+func (s *fakeSecretSink) Update(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.secrets[cr.Name] = secret.DeepCopy()
+	return nil
+}
+
+func (s *fakeSecretSink) Delete(ctx context.Context, cr *minterv1.CredentialsRequest) error {
+	delete(s.secrets, cr.Name)
+	return nil
+}
+
+type fakeSecretSinkActuator struct {
+	DummyActuator
+	sink SecretSink
+}
+
+func (a *fakeSecretSinkActuator) GetSecretSink() SecretSink {
+	return a.sink
+}
+
+func TestInClusterSecretSink(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().Build()
+	sink := NewInClusterSecretSink(fakeClient)
+	cr := testCredentialsRequest()
+	secretKey := types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}
+
+	err := sink.Get(context.TODO(), cr, &corev1.Secret{})
+	assert.True(t, errors.IsNotFound(err), "expected NotFound error before credentials are stored")
+
+	err = sink.Create(context.TODO(), cr, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: secretKey.Namespace, Name: secretKey.Name},
+		Data:       map[string][]byte{"key": []byte("first")},
+	})
+	require.NoError(t, err, "unexpected error creating credentials")
+
+	secret := &corev1.Secret{}
+	require.NoError(t, sink.Get(context.TODO(), cr, secret), "unexpected error reading credentials")
+	secret.Data["key"] = []byte("second")
+	require.NoError(t, sink.Update(context.TODO(), cr, secret), "unexpected error updating credentials")
+
+	clusterSecret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(context.TODO(), secretKey, clusterSecret), "expected credentials to be stored as a secret in the cluster")
+	assert.Equal(t, "second", string(clusterSecret.Data["key"]), "unexpected credentials stored in the cluster")
+
+	require.NoError(t, sink.Delete(context.TODO(), cr), "unexpected error deleting credentials")
+	err = fakeClient.Get(context.TODO(), secretKey, &corev1.Secret{})
+	assert.True(t, errors.IsNotFound(err), "expected secret to be deleted from the cluster")
+	assert.NoError(t, sink.Delete(context.TODO(), cr), "expected no error deleting credentials which are not stored")
+}
+
+func TestNewSecretSink(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().Build()
+	fakeSink := &fakeSecretSink{secrets: map[string]*corev1.Secret{}}
+	RegisterSecretSink("test-fake", func(client.Client) (SecretSink, error) {
+		return fakeSink, nil
+	})
+
+	assert.Contains(t, SecretSinkNames(), InClusterSecretSinkName, "expected in-cluster secret sink to be registered")
+	assert.Contains(t, SecretSinkNames(), "test-fake", "expected registered secret sink to be listed")
+
+	sink, err := NewSecretSink("test-fake", fakeClient)
+	require.NoError(t, err, "unexpected error creating registered secret sink")
+	assert.Equal(t, fakeSink, sink, "expected registered secret sink to be returned")
+
+	sink, err = NewSecretSink(InClusterSecretSinkName, fakeClient)
+	require.NoError(t, err, "unexpected error creating in-cluster secret sink")
+	assert.IsType(t, &inClusterSecretSink{}, sink, "expected in-cluster secret sink to be returned")
+
+	_, err = NewSecretSink("unknown", fakeClient)
+	assert.Error(t, err, "expected error creating unknown secret sink")
+
+	assert.Panics(t, func() {
+		RegisterSecretSink("test-fake", func(client.Client) (SecretSink, error) {
+			return fakeSink, nil
+		})
+	}, "expected registering a secret sink twice to panic")
+}
+
+func TestSecretSinkFor(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().Build()
+	fakeSink := &fakeSecretSink{secrets: map[string]*corev1.Secret{}}
+	cr := testCredentialsRequest()
+
+	sink := SecretSinkFor(&fakeSecretSinkActuator{sink: fakeSink}, fakeClient)
+	require.NoError(t, sink.Create(context.TODO(), cr, &corev1.Secret{}), "unexpected error creating credentials")
+	assert.Contains(t, fakeSink.secrets, cr.Name, "expected credentials to be stored in the actuator's sink")
+	require.NoError(t, sink.Delete(context.TODO(), cr), "unexpected error deleting credentials")
+	assert.NotContains(t, fakeSink.secrets, cr.Name, "expected credentials to be deleted from the actuator's sink")
+
+	assert.IsType(t, &inClusterSecretSink{}, SecretSinkFor(&DummyActuator{}, fakeClient), "expected in-cluster secret sink for actuators without a sink")
+	assert.IsType(t, &inClusterSecretSink{}, SecretSinkFor(&fakeSecretSinkActuator{}, fakeClient), "expected in-cluster secret sink for actuators with a nil sink")
+}
+
+func testCredentialsRequest() *minterv1.CredentialsRequest {
+	return &minterv1.CredentialsRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testcr",
+			Namespace: "testnamespace",
+		},
+		Spec: minterv1.CredentialsRequestSpec{
+			SecretRef: corev1.ObjectReference{
+				Name:      "test-secret",
+				Namespace: "test-namespace",
+			},
+		},
+	}
+}
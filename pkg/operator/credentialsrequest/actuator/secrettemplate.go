@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The OpenShift Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package actuator
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+// ApplySecretTemplate renders the keys of the CredentialsRequest's spec.secretTemplate into secret, which holds
+// the credentials in the default format of the cloud provider. Keys rendered by a previous sync which are no longer
+// part of the template are removed, as recorded by the AnnotationSecretTemplateKeys annotation. The secret is left
+// in the default format when the CredentialsRequest has no template.
+//
+// An ActuatorError is returned when a template fails to render or would not produce a valid Secret, in which case
+// secret is left unchanged.
+func ApplySecretTemplate(cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	data := map[string][]byte{}
+	for key, value := range secret.Data {
+		data[key] = value
+	}
+	for _, key := range strings.Split(secret.Annotations[minterv1.AnnotationSecretTemplateKeys], ",") {
+		delete(data, key)
+	}
+
+	rendered, err := renderSecretTemplate(cr.Spec.SecretTemplate, data)
+	if err != nil {
+		return &ActuatorError{
+			ErrReason: minterv1.CredentialsProvisionFailure,
+			Message:   fmt.Sprintf("error rendering spec.secretTemplate: %v", err),
+		}
+	}
+
+	keys := make([]string, 0, len(rendered))
+	for key, value := range rendered {
+		data[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	secret.Data = data
+	if len(keys) == 0 {
+		delete(secret.Annotations, minterv1.AnnotationSecretTemplateKeys)
+		return nil
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[minterv1.AnnotationSecretTemplateKeys] = strings.Join(keys, ",")
+	return nil
+}
+
+// renderSecretTemplate renders the keys of secretTemplate with the data of a secret in the default format, the
+// rendered keys may neither override the default format nor make the secret exceed the maximum size of a Secret
+func renderSecretTemplate(secretTemplate *minterv1.SecretTemplate, data map[string][]byte) (map[string][]byte, error) {
+	if secretTemplate == nil || len(secretTemplate.Data) == 0 {
+		return nil, nil
+	}
+
+	values := map[string]string{}
+	size := 0
+	for key, value := range data {
+		values[key] = string(value)
+		size += len(value)
+	}
+
+	keys := make([]string, 0, len(secretTemplate.Data))
+	for key := range secretTemplate.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rendered := map[string][]byte{}
+	for _, key := range keys {
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if _, ok := data[key]; ok {
+			return nil, fmt.Errorf("key %q may not override the default format of the secret", key)
+		}
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(secretTemplate.Data[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for key %q: %v", key, err)
+		}
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, values); err != nil {
+			return nil, fmt.Errorf("failed to render template for key %q: %v", key, err)
+		}
+		rendered[key] = buf.Bytes()
+		size += buf.Len()
+	}
+
+	if size > corev1.MaxSecretSize {
+		return nil, fmt.Errorf("rendered secret exceeds the maximum size of a Secret of %d bytes", corev1.MaxSecretSize)
+	}
+	return rendered, nil
+}
+
+// SecretTemplateOutdated returns true when the keys rendered into secret from the CredentialsRequest's
+// spec.secretTemplate are out of sync with the template, or the template fails to render so that the error is
+// surfaced by syncing the secret.
+func SecretTemplateOutdated(cr *minterv1.CredentialsRequest, secret *corev1.Secret) bool {
+	if secret == nil {
+		return false
+	}
+	desired := secret.DeepCopy()
+	if err := ApplySecretTemplate(cr, desired); err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(desired.Data, secret.Data) ||
+		desired.Annotations[minterv1.AnnotationSecretTemplateKeys] != secret.Annotations[minterv1.AnnotationSecretTemplateKeys]
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The OpenShift Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package actuator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+func TestApplySecretTemplate(t *testing.T) {
+	defaultData := map[string][]byte{
+		"aws_access_key_id":     []byte("AKIAEXAMPLE"),
+		"aws_secret_access_key": []byte("secret"),
+	}
+	tests := []struct {
+		name           string
+		secretTemplate *minterv1.SecretTemplate
+		existingData   map[string][]byte
+		existingKeys   string
+		expectedData   map[string][]byte
+		expectedKeys   string
+		expectError    bool
+	}{
+		{
+			name:         "Default format without a template",
+			expectedData: defaultData,
+		},
+		{
+			name: "Custom keys rendered",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"config.ini": "[default]\naccess_key = {{ .aws_access_key_id }}\nsecret_key = {{ index . \"aws_secret_access_key\" }}",
+					"key-id":     "{{ .aws_access_key_id }}",
+				},
+			},
+			expectedData: map[string][]byte{
+				"aws_access_key_id":     []byte("AKIAEXAMPLE"),
+				"aws_secret_access_key": []byte("secret"),
+				"config.ini":            []byte("[default]\naccess_key = AKIAEXAMPLE\nsecret_key = secret"),
+				"key-id":                []byte("AKIAEXAMPLE"),
+			},
+			expectedKeys: "config.ini,key-id",
+		},
+		{
+			name: "Keys dropped from the template removed",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"key-id": "{{ .aws_access_key_id }}",
+				},
+			},
+			existingData: map[string][]byte{
+				"config.ini": []byte("stale"),
+				"key-id":     []byte("stale"),
+			},
+			existingKeys: "config.ini,key-id",
+			expectedData: map[string][]byte{
+				"aws_access_key_id":     []byte("AKIAEXAMPLE"),
+				"aws_secret_access_key": []byte("secret"),
+				"key-id":                []byte("AKIAEXAMPLE"),
+			},
+			expectedKeys: "key-id",
+		},
+		{
+			name: "Rendered keys removed once the template is removed",
+			existingData: map[string][]byte{
+				"key-id": []byte("AKIAEXAMPLE"),
+			},
+			existingKeys: "key-id",
+			expectedData: defaultData,
+		},
+		{
+			name: "Default format may not be overridden",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"aws_access_key_id": "{{ .aws_secret_access_key }}",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid key",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"invalid/key": "{{ .aws_access_key_id }}",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid template",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"key-id": "{{ .aws_access_key_id",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Missing key of the default format",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"key-id": "{{ .missing }}",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Rendered secret too large",
+			secretTemplate: &minterv1.SecretTemplate{
+				Data: map[string]string{
+					"large": strings.Repeat("x", corev1.MaxSecretSize),
+				},
+			},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := testCredentialsRequest()
+			cr.Spec.SecretTemplate = test.secretTemplate
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        cr.Spec.SecretRef.Name,
+					Namespace:   cr.Spec.SecretRef.Namespace,
+					Annotations: map[string]string{},
+				},
+				Data: map[string][]byte{},
+			}
+			for key, value := range defaultData {
+				secret.Data[key] = value
+			}
+			for key, value := range test.existingData {
+				secret.Data[key] = value
+			}
+			if test.existingKeys != "" {
+				secret.Annotations[minterv1.AnnotationSecretTemplateKeys] = test.existingKeys
+			}
+			origSecret := secret.DeepCopy()
+
+			err := ApplySecretTemplate(cr, secret)
+			if test.expectError {
+				require.Error(t, err, "expected error")
+				assert.Equal(t, minterv1.CredentialsProvisionFailure, err.(*ActuatorError).ErrReason)
+				assert.Equal(t, origSecret, secret, "secret changed despite the error")
+				assert.True(t, SecretTemplateOutdated(cr, secret), "expected outdated secret template")
+				return
+			}
+			require.NoError(t, err, "unexpected error")
+			assert.Equal(t, test.expectedData, secret.Data)
+			keys, ok := secret.Annotations[minterv1.AnnotationSecretTemplateKeys]
+			assert.Equal(t, test.expectedKeys != "", ok, "unexpected presence of the secret template keys annotation")
+			assert.Equal(t, test.expectedKeys, keys)
+			assert.False(t, SecretTemplateOutdated(cr, secret), "unexpected outdated secret template once applied")
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -72,6 +74,9 @@ const (
 
 	cloudResourceOrphaned = "CloudResourceOrphaned"
 	cloudResourceCleaned  = "CloudResourceCleaned"
+
+	credentialMintingPaused   = "CredentialMintingPaused"
+	credentialMintingUnpaused = "CredentialMintingUnpaused"
 )
 
 var (
@@ -79,6 +84,15 @@ var (
 	// Set some extra time when requeueing so we are guaranteed that the
 	// syncPeriod has elapsed when we re-reconcile an object.
 	defaultRequeueTime = syncPeriod + time.Minute*10
+
+	// RevalidationInterval is the interval at which provisioned credentials are re-validated against
+	// the cloud between the regular syncs. Re-validation is disabled when the interval is zero, and
+	// has no effect when it is not shorter than the syncPeriod.
+	RevalidationInterval time.Duration
+
+	// BackupSecretNamespace is the namespace to which the target secrets of CredentialsRequests are
+	// mirrored to ease disaster recovery. Mirroring is disabled when the namespace is empty.
+	BackupSecretNamespace string
 )
 
 // AddWithActuator creates a new CredentialsRequest Controller and adds it to the Manager with
@@ -91,9 +105,12 @@ func AddWithActuator(mgr manager.Manager, actuator actuator.Actuator, platType c
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager, actuator actuator.Actuator, platType configv1.PlatformType) reconcile.Reconciler {
 	r := &ReconcileCredentialsRequest{
-		Client:       mgr.GetClient(),
-		Actuator:     actuator,
-		platformType: platType,
+		Client:                mgr.GetClient(),
+		Actuator:              actuator,
+		platformType:          platType,
+		revalidationInterval:  RevalidationInterval,
+		backupSecretNamespace: BackupSecretNamespace,
+		eventRecorder:         newDeduplicatingRecorder(mgr.GetEventRecorderFor(controllerName), eventDeduplicationInterval),
 	}
 	status.AddHandler(controllerName, r)
 
@@ -127,6 +144,13 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Reconcile the other CredentialsRequests targeting the secret of a changed CredentialsRequest, or reporting a
+	// conflict it may have resolved, so that conflicting secretRefs are detected and cleared on both sides.
+	err = c.Watch(source.Kind(operatorCache, &minterv1.CredentialsRequest{}), handler.EnqueueRequestsFromMapFunc(conflictMapFunc(mgr.GetClient())))
+	if err != nil {
+		return err
+	}
+
 	// Define a mapping for secrets to the credentials requests that created them. (if applicable)
 	// We use an annotation on secrets that refers back to their owning credentials request because
 	// the normal owner reference is not namespaced, and we want to support credentials requests being
@@ -344,6 +368,30 @@ type ReconcileCredentialsRequest struct {
 	client.Client
 	Actuator     actuator.Actuator
 	platformType configv1.PlatformType
+	// revalidationInterval is the interval at which recently synced credentials are re-validated
+	// against the cloud, zero disables re-validation
+	revalidationInterval time.Duration
+	// backupSecretNamespace is the namespace to which target secrets are mirrored, empty disables
+	// mirroring
+	backupSecretNamespace string
+	// eventRecorder records the outcomes of syncing CredentialsRequests as events, nil disables events
+	eventRecorder record.EventRecorder
+}
+
+// revalidationEnabled returns whether recently synced credentials are periodically re-validated
+// against the cloud
+func (r *ReconcileCredentialsRequest) revalidationEnabled() bool {
+	return r.revalidationInterval > 0 && r.revalidationInterval < syncPeriod
+}
+
+// requeueTime returns the time after which a successfully reconciled CredentialsRequest should be
+// reconciled again to catch changes made directly to the cloud/platform
+func (r *ReconcileCredentialsRequest) requeueTime() time.Duration {
+	if r.revalidationEnabled() {
+		// Set some extra time so we are guaranteed that the revalidationInterval has elapsed
+		return r.revalidationInterval + time.Minute
+	}
+	return defaultRequeueTime
 }
 
 // Reconcile reads that state of the cluster for a CredentialsRequest object and
@@ -426,6 +474,40 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 		return reconcile.Result{}, err
 	}
 
+	// Report the changes syncing the CredentialsRequest would make, without making any, while an admin has requested
+	// a dry run. Nothing is changed, not even the finalizer, so a CredentialsRequest being deleted is not deprovisioned
+	// until the dry run is no longer requested.
+	wasDryRun := false
+	if dryRunCondition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, minterv1.DryRun); dryRunCondition != nil {
+		wasDryRun = dryRunCondition.Status == corev1.ConditionTrue
+	}
+	if isDryRun(cr) {
+		changes, err := r.dryRunChanges(ctx, cr, logger)
+		if err != nil {
+			logger.WithError(err).Error("error determining the changes of the dry run")
+			return reconcile.Result{}, err
+		}
+		logger.WithField("changes", changes).Info("dry run requested, skipping sync")
+		setDryRunCondition(cr, true, changes)
+		r.recordEvent(cr, corev1.EventTypeNormal, eventReasonDryRun, "%s", dryRunMessage(changes))
+		if err := utils.UpdateStatus(r.Client, origCR, cr, logger); err != nil {
+			logger.WithError(err).Error("error updating condition")
+			return reconcile.Result{}, err
+		}
+		// The changes depend on the time the credentials were last synced
+		return reconcile.Result{RequeueAfter: r.requeueTime()}, nil
+	}
+	if wasDryRun {
+		logger.Info("dry run no longer requested, resuming sync")
+		setDryRunCondition(cr, false, nil)
+		if err := utils.UpdateStatus(r.Client, origCR, cr, logger); err != nil {
+			logger.WithError(err).Error("error updating condition")
+			return reconcile.Result{}, err
+		}
+		// Compare any further status changes against the status just persisted
+		origCR = cr.DeepCopy()
+	}
+
 	// Handle deletion and the deprovision finalizer:
 	if cr.DeletionTimestamp != nil {
 		if HasFinalizer(cr, minterv1.FinalizerDeprovision) {
@@ -434,6 +516,8 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 				logger.WithError(err).Error("actuator error deleting credentials exist")
 
 				setCredentialsDeprovisionFailureCondition(cr, true, err)
+				r.recordEvent(cr, corev1.EventTypeWarning, eventReasonCredentialsDeprovisionFailed,
+					"Failed to deprovision credentials (category %s): %v", cloudErrorCategory(err), err)
 				if err := utils.UpdateStatus(r.Client, origCR, cr, logger); err != nil {
 					logger.WithError(err).Error("failed to update condition")
 					return reconcile.Result{}, err
@@ -448,27 +532,19 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 				}
 			}
 
-			// Delete the target secret if it exists:
-			targetSecret := &corev1.Secret{}
-			err := r.Client.Get(ctx, types.NamespacedName{Namespace: cr.Spec.SecretRef.Namespace, Name: cr.Spec.SecretRef.Name}, targetSecret)
+			// Delete the target secret if it exists, through the sink in which the actuator stored it:
 			sLog := logger.WithFields(log.Fields{
 				"targetSecret": fmt.Sprintf("%s/%s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name),
 			})
-			if err != nil {
-				if errors.IsNotFound(err) {
-					sLog.Debug("target secret does not exist")
-				} else {
-					sLog.WithError(err).Error("unexpected error getting target secret to delete")
-					return reconcile.Result{}, err
-				}
-			} else {
-				err := r.Client.Delete(ctx, targetSecret)
-				if err != nil {
-					sLog.WithError(err).Error("error deleting target secret")
-					return reconcile.Result{}, err
-				} else {
-					sLog.Info("target secret deleted successfully")
-				}
+			if err := actuator.SecretSinkFor(r.Actuator, r.Client).Delete(ctx, cr); err != nil {
+				sLog.WithError(err).Error("error deleting target secret")
+				return reconcile.Result{}, err
+			}
+			sLog.Info("target secret deleted successfully")
+
+			if err := r.deleteBackupSecret(ctx, cr, logger); err != nil {
+				logger.WithError(err).Error("error deleting backup secret")
+				return reconcile.Result{}, err
 			}
 
 			logger.Info("actuator deletion complete, removing finalizer")
@@ -494,6 +570,55 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 		}
 	}
 
+	// Skip minting and rotating credentials while an admin has paused credential minting. Changes to the
+	// operator config trigger a reconcile of every CredentialsRequest, so syncing resumes once unpaused.
+	paused, err := utils.IsCredentialMintingPaused(r.Client, logger)
+	if err != nil {
+		logger.WithError(err).Error("error checking if credential minting is paused")
+		return reconcile.Result{}, err
+	}
+	wasPaused := false
+	if pausedCondition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, minterv1.Paused); pausedCondition != nil {
+		wasPaused = pausedCondition.Status == corev1.ConditionTrue
+	}
+	setPausedCondition(cr, paused)
+	if paused {
+		logger.Info("credential minting is paused, skipping sync")
+		if err := utils.UpdateStatus(r.Client, origCR, cr, logger); err != nil {
+			logger.WithError(err).Error("error updating condition")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+	if wasPaused {
+		logger.Info("credential minting is no longer paused, resuming sync")
+		if err := utils.UpdateStatus(r.Client, origCR, cr, logger); err != nil {
+			logger.WithError(err).Error("error updating condition")
+			return reconcile.Result{}, err
+		}
+		// Compare any further status changes against the status just persisted
+		origCR = cr.DeepCopy()
+	}
+
+	// Refuse to sync the target secret while other CredentialsRequests target it, as each would overwrite the
+	// credentials minted for the others. The secret is left as it is until the conflict is resolved.
+	conflicting, err := r.conflictingCredentialsRequests(ctx, cr, logger)
+	if err != nil {
+		logger.WithError(err).Error("error checking for CredentialsRequests targeting the same secret")
+		return reconcile.Result{}, err
+	}
+	setConflictCondition(cr, conflicting)
+	if len(conflicting) > 0 {
+		logger.WithField("conflicting", conflicting).Warn("other CredentialsRequests target the same secret, skipping sync")
+		r.recordEvent(cr, corev1.EventTypeWarning, eventReasonSecretRefConflict,
+			"Secret %s/%s is also targeted by CredentialsRequests %s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name, strings.Join(conflicting, ", "))
+		if err := utils.UpdateStatus(r.Client, origCR, cr, logger); err != nil {
+			logger.WithError(err).Error("error updating condition")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
 	// Ensure the target namespace exists for the secret, if not, there's no point
 	// continuing:
 	targetNS := &corev1.Namespace{}
@@ -522,8 +647,7 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 	// Check if the secret the credRequest wants created already exists
 	var crSecretExists bool
 	crSecret := &corev1.Secret{}
-	secretKey := types.NamespacedName{Name: cr.Spec.SecretRef.Name, Namespace: cr.Spec.SecretRef.Namespace}
-	if err := r.Get(ctx, secretKey, crSecret); err != nil {
+	if err := r.getTargetSecret(ctx, cr, crSecret); err != nil {
 		if errors.IsNotFound(err) {
 			crSecretExists = false
 		} else {
@@ -564,6 +688,7 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 				r.updateActuatorConditions(cr, t.Reason(), syncErr)
 			default:
 				logger.Errorf("unexpected error while syncing credentialsrequest: %v", syncErr)
+				r.recordSyncEvents(ctx, cr, origCR.Status.Provisioned, nil, syncErr, true, logger)
 				return reconcile.Result{}, syncErr
 			}
 
@@ -572,6 +697,7 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 			r.updateActuatorConditions(cr, "", nil)
 			cr.Status.Provisioned = true
 		}
+		r.recordSyncEvents(ctx, cr, origCR.Status.Provisioned, existingSecret(crSecretExists, crSecret), syncErr, provisionErr, logger)
 	} else {
 		credentialsRootSecret, err := r.Actuator.GetCredentialsRootSecret(ctx, cr)
 		if err != nil {
@@ -590,12 +716,21 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 			"NOT hasActiveFailureConditions": hasActiveFailureConditions,
 			"cr.Status.Provisioned":          cr.Status.Provisioned,
 		}).Debug("The above are ANDed together to determine: lastsyncgeneration is current and lastsynctimestamp < an hour ago")
+		revalidating := false
 		if !cloudCredsSecretUpdated && !isStale && hasRecentlySynced && crSecretExists && !hasActiveFailureConditions && cr.Status.Provisioned {
-			logger.Debug("lastsyncgeneration is current and lastsynctimestamp was less than an hour ago, so no need to sync")
-			// Since we get no events for changes made directly to the cloud/platform, set the requeueAfter so that we at
-			// least periodically check that nothing out in the cloud/platform was modified that would require us to fix up
-			// users/permissions/tags/etc.
-			return reconcile.Result{RequeueAfter: defaultRequeueTime}, nil
+			if !r.revalidationEnabled() || cr.Status.LastSyncTimestamp.Add(r.revalidationInterval).After(time.Now()) {
+				logger.Debug("lastsyncgeneration is current and lastsynctimestamp was less than an hour ago, so no need to sync")
+				// Since we get no events for changes made directly to the cloud/platform, set the requeueAfter so that we at
+				// least periodically check that nothing out in the cloud/platform was modified that would require us to fix up
+				// users/permissions/tags/etc.
+				if err := r.syncBackupSecret(ctx, cr, logger); err != nil {
+					logger.WithError(err).Error("error syncing backup secret")
+					return reconcile.Result{}, err
+				}
+				return reconcile.Result{RequeueAfter: r.requeueTime()}, nil
+			}
+			logger.WithField("revalidationInterval", r.revalidationInterval).Info("re-validating provisioned credentials against the cloud")
+			revalidating = true
 		}
 
 		credsExists, err := r.Actuator.Exists(ctx, cr)
@@ -626,6 +761,7 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 				r.updateActuatorConditions(cr, t.Reason(), syncErr)
 			default:
 				logger.Errorf("unexpected error while syncing credentialsrequest: %v", syncErr)
+				r.recordSyncEvents(ctx, cr, origCR.Status.Provisioned, nil, syncErr, true, logger)
 				return reconcile.Result{}, syncErr
 			}
 
@@ -634,6 +770,12 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 			r.updateActuatorConditions(cr, "", nil)
 			cr.Status.Provisioned = true
 		}
+		r.recordSyncEvents(ctx, cr, origCR.Status.Provisioned, existingSecret(crSecretExists, crSecret), syncErr, provisionErr, logger)
+
+		if revalidating {
+			metrics.MetricCredentialsRequestRevalidations.WithLabelValues(string(r.platformType)).Inc()
+			r.recordRevalidationCorrection(ctx, cr, crSecret, syncErr, logger)
+		}
 
 		// if provisionErr == false, it means we successfully provisioned even if there
 		// were non-critical errors encountered
@@ -653,6 +795,14 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 			return reconcile.Result{}, err
 		}
 
+		// Mirror the (possibly rotated) credentials so that the backup never lags the target secret
+		if !provisionErr {
+			if err := r.syncBackupSecret(ctx, cr, logger); err != nil {
+				logger.WithError(err).Error("error syncing backup secret")
+				return reconcile.Result{}, err
+			}
+		}
+
 		// Since we get no events for changes made directly to the cloud/platform, set the requeueAfter so that we at
 		// least periodically check that nothing out in the cloud/platform was modified that would require us to fix up
 		// users/permissions/tags/etc.
@@ -660,12 +810,143 @@ func (r *ReconcileCredentialsRequest) Reconcile(ctx context.Context, request rec
 			// We could have a non-critical error (eg OrphanedCloudResource) in the syncErr
 			// but we wouldn't want to treat that as an overal controller error while
 			// reconciling.
-			return reconcile.Result{RequeueAfter: defaultRequeueTime}, nil
+			return reconcile.Result{RequeueAfter: r.requeueTime()}, nil
 		} else {
-			return reconcile.Result{RequeueAfter: defaultRequeueTime}, syncErr
+			return reconcile.Result{RequeueAfter: r.requeueTime()}, syncErr
+		}
+	}
+	return reconcile.Result{RequeueAfter: r.requeueTime()}, nil
+}
+
+// existingSecret returns secret when it exists, nil otherwise
+func existingSecret(exists bool, secret *corev1.Secret) *corev1.Secret {
+	if !exists {
+		return nil
+	}
+	return secret
+}
+
+// getTargetSecret reads the credentials stored for cr into secret through the SecretSink of the actuator, so that
+// the target secret is never read from the cluster when credentials are stored elsewhere. A NotFound error is
+// returned when no credentials are stored.
+func (r *ReconcileCredentialsRequest) getTargetSecret(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	return actuator.SecretSinkFor(r.Actuator, r.Client).Get(ctx, cr, secret)
+}
+
+// recordRevalidationCorrection records whether re-validating the recently synced credentials resulted in
+// a correction, which is the case when the credentials had to be re-minted into the target secret or
+// could no longer be re-asserted.
+func (r *ReconcileCredentialsRequest) recordRevalidationCorrection(ctx context.Context, cr *minterv1.CredentialsRequest, origSecret *corev1.Secret, syncErr error, logger log.FieldLogger) {
+	if syncErr != nil {
+		logger.WithError(syncErr).Warn("re-validation failed to re-assert provisioned credentials")
+		return
+	}
+	secret := &corev1.Secret{}
+	if err := r.getTargetSecret(ctx, cr, secret); err != nil {
+		logger.WithError(err).Warn("unable to determine whether re-validation corrected the provisioned credentials")
+		return
+	}
+	if secret.ResourceVersion != origSecret.ResourceVersion {
+		logger.Info("re-validation corrected the provisioned credentials")
+		metrics.MetricCredentialsRequestRevalidationCorrections.WithLabelValues(string(r.platformType)).Inc()
+	}
+}
+
+// backupSecretName returns the name of the secret within the backup namespace which mirrors the
+// target secret of the CredentialsRequest. The namespace and the name of the target secret are
+// separated by a ".", which namespace names may not contain, so that the backups of distinct target
+// secrets never share a name, eg. those of "a-b/c" and "a/b-c".
+func backupSecretName(cr *minterv1.CredentialsRequest) string {
+	return fmt.Sprintf("%s.%s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+}
+
+// syncBackupSecret mirrors the target secret of the CredentialsRequest to the backup namespace when
+// mirroring is enabled. An existing backup is left untouched when the target secret does not exist so
+// that it remains available to restore the target secret. Credentials stored outside of the cluster by
+// the SecretSink of the actuator are not backed up, so that they are never copied into a Secret.
+func (r *ReconcileCredentialsRequest) syncBackupSecret(ctx context.Context, cr *minterv1.CredentialsRequest, logger log.FieldLogger) error {
+	if r.backupSecretNamespace == "" {
+		return nil
+	}
+	sink := actuator.SecretSinkFor(r.Actuator, r.Client)
+	if !actuator.IsInClusterSecretSink(sink) {
+		logger.Debug("credentials are not stored in the cluster, skipping backup secret")
+		return nil
+	}
+
+	targetSecret := &corev1.Secret{}
+	if err := sink.Get(ctx, cr, targetSecret); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Debug("target secret does not exist, leaving backup secret untouched")
+			return nil
+		}
+		return err
+	}
+
+	backupKey := types.NamespacedName{Name: backupSecretName(cr), Namespace: r.backupSecretNamespace}
+	bLog := logger.WithField("backupSecret", backupKey.String())
+	crKey := fmt.Sprintf("%s/%s", cr.Namespace, cr.Name)
+
+	backupSecret := &corev1.Secret{}
+	err := r.Get(ctx, backupKey, backupSecret)
+	if errors.IsNotFound(err) {
+		backupSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      backupKey.Name,
+				Namespace: backupKey.Namespace,
+				Annotations: map[string]string{
+					minterv1.AnnotationCredentialsRequest: crKey,
+				},
+			},
+			Type: targetSecret.Type,
+			Data: targetSecret.Data,
+		}
+		if err := r.Create(ctx, backupSecret); err != nil {
+			return err
+		}
+		bLog.Info("backup secret created successfully")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(backupSecret.Data, targetSecret.Data) && backupSecret.Annotations[minterv1.AnnotationCredentialsRequest] == crKey {
+		bLog.Debug("backup secret is current")
+		return nil
+	}
+	if backupSecret.Annotations == nil {
+		backupSecret.Annotations = map[string]string{}
+	}
+	backupSecret.Annotations[minterv1.AnnotationCredentialsRequest] = crKey
+	backupSecret.Data = targetSecret.Data
+	if err := r.Update(ctx, backupSecret); err != nil {
+		return err
+	}
+	bLog.Info("backup secret updated successfully")
+	return nil
+}
+
+// deleteBackupSecret deletes the backup of the target secret of the CredentialsRequest if it exists
+func (r *ReconcileCredentialsRequest) deleteBackupSecret(ctx context.Context, cr *minterv1.CredentialsRequest, logger log.FieldLogger) error {
+	if r.backupSecretNamespace == "" {
+		return nil
+	}
+	backupSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupSecretName(cr),
+			Namespace: r.backupSecretNamespace,
+		},
+	}
+	bLog := logger.WithField("backupSecret", fmt.Sprintf("%s/%s", backupSecret.Namespace, backupSecret.Name))
+	if err := r.Delete(ctx, backupSecret); err != nil {
+		if errors.IsNotFound(err) {
+			bLog.Debug("backup secret does not exist")
+			return nil
 		}
+		return err
 	}
-	return reconcile.Result{RequeueAfter: defaultRequeueTime}, nil
+	bLog.Info("backup secret deleted successfully")
+	return nil
 }
 
 func (r *ReconcileCredentialsRequest) CreateOrUpdateOnCredsExist(ctx context.Context, credsExists bool, syncErr error, cr *minterv1.CredentialsRequest) error {
@@ -808,6 +1089,27 @@ func setCredentialsDeprovisionFailureCondition(cr *minterv1.CredentialsRequest,
 		status, reason, msg, updateCheck)
 }
 
+func setPausedCondition(cr *minterv1.CredentialsRequest, paused bool) {
+	var (
+		msg, reason string
+		status      corev1.ConditionStatus
+		updateCheck utils.UpdateConditionCheck
+	)
+	if paused {
+		msg = fmt.Sprintf("credential minting is paused by the %s annotation of the operator config", constants.PauseMintingAnnotation)
+		status = corev1.ConditionTrue
+		reason = credentialMintingPaused
+		updateCheck = utils.UpdateConditionIfReasonOrMessageChange
+	} else {
+		msg = "credential minting is not paused"
+		status = corev1.ConditionFalse
+		reason = credentialMintingUnpaused
+		updateCheck = utils.UpdateConditionNever
+	}
+	cr.Status.Conditions = utils.SetCredentialsRequestCondition(cr.Status.Conditions, minterv1.Paused,
+		status, reason, msg, updateCheck)
+}
+
 func setIgnoredCondition(cr *minterv1.CredentialsRequest, clusterPlatform configv1.PlatformType) {
 	// Only supporting the ability to set the condition
 	msg := fmt.Sprintf("CredentialsRequest is not for platform %s", clusterPlatform)
@@ -29,6 +29,7 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -36,6 +37,7 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -277,6 +279,122 @@ func TestCredentialsRequestAzureReconcile(t *testing.T) {
 	}
 }
 
+// fakeSecretSink stores credentials secrets in memory, keyed by the namespace and name of their CredentialsRequest
+type fakeSecretSink struct {
+	secrets map[types.NamespacedName]*corev1.Secret
+	gets    int
+	creates int
+}
+
+func (s *fakeSecretSink) Get(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.gets++
+	stored, ok := s.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}]
+	if !ok {
+		return apierrors.NewNotFound(corev1.Resource("secrets"), cr.Spec.SecretRef.Name)
+	}
+	stored.DeepCopyInto(secret)
+	return nil
+}
+
+func (s *fakeSecretSink) Create(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.creates++
+	s.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}] = secret.DeepCopy()
+	return nil
+}
+
+func (s *fakeSecretSink) Update(ctx context.Context, cr *minterv1.CredentialsRequest, secret *corev1.Secret) error {
+	s.secrets[types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}] = secret.DeepCopy()
+	return nil
+}
+
+func (s *fakeSecretSink) Delete(ctx context.Context, cr *minterv1.CredentialsRequest) error {
+	delete(s.secrets, types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name})
+	return nil
+}
+
+func TestCredentialsRequestAzureReconcileSecretSink(t *testing.T) {
+	schemeutils.SetupScheme(scheme.Scheme)
+
+	codec, err := minterv1.NewCodec()
+	require.NoError(t, err, "error creating codec")
+
+	// The target secret may neither be read from nor written to the cluster when credentials are stored by a sink
+	targetSecretKey := types.NamespacedName{Namespace: testSecretNamespace, Name: testSecretName}
+	isTargetSecret := func(obj client.Object) bool {
+		_, isSecret := obj.(*corev1.Secret)
+		return isSecret && client.ObjectKeyFromObject(obj) == targetSecretKey
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithStatusSubresource(&minterv1.CredentialsRequest{}).
+		WithRuntimeObjects(
+			testOperatorConfig(""),
+			createTestNamespace(testNamespace),
+			createTestNamespace(testSecretNamespace),
+			createTestNamespace(testBackupNamespace),
+			testAzureCredsSecret(constants.CloudCredSecretNamespace, constants.AzureCloudCredSecretName),
+			testAzureCredentialsRequest(t),
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, isSecret := obj.(*corev1.Secret); isSecret && key == targetSecretKey {
+					t.Errorf("unexpected read of the target secret from the cluster")
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if isTargetSecret(obj) {
+					t.Errorf("unexpected creation of the target secret within the cluster")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if isTargetSecret(obj) {
+					t.Errorf("unexpected update of the target secret within the cluster")
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).Build()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAzureAppClient := mockazure.NewMockAppClient(mockCtrl)
+	azureActuator := azureactuator.NewFakeActuator(
+		fakeClient,
+		codec,
+		func(logger log.FieldLogger, clientID, clientSecret, tenantID, subscriptionID string) (*azureactuator.AzureCredentialsMinter, error) {
+			return azureactuator.NewFakeAzureCredentialsMinter(logger, clientID, clientSecret, tenantID, subscriptionID, mockAzureAppClient)
+		},
+	)
+	sink := &fakeSecretSink{secrets: map[types.NamespacedName]*corev1.Secret{}}
+	azureActuator.SecretSink = sink
+	rcr := &ReconcileCredentialsRequest{
+		Client:                fakeClient,
+		Actuator:              azureActuator,
+		platformType:          configv1.AzurePlatformType,
+		backupSecretNamespace: testBackupNamespace,
+	}
+
+	// The credentials are provisioned by the first reconcile and found in the sink by the second
+	for i := 0; i < 2; i++ {
+		_, err = rcr.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: testCRName, Namespace: testNamespace},
+		})
+		require.NoError(t, err, "unexpected error reconciling")
+	}
+
+	assert.Equal(t, 1, sink.creates, "expected the credentials to be created once in the sink")
+	assert.NotZero(t, sink.gets, "expected the credentials to be read from the sink")
+	stored := sink.secrets[types.NamespacedName{Namespace: testNamespace, Name: testCRName}]
+	require.NotNil(t, stored, "expected the credentials to be stored in the sink")
+	assert.Equal(t, testAzureClientID, string(stored.Data[azureactuator.AzureClientID]), "unexpected AzureClientID stored in the sink")
+
+	cr := getCredRequest(fakeClient)
+	assert.True(t, cr.Status.Provisioned, "expected the CredentialsRequest to be provisioned")
+	backupSecrets := &corev1.SecretList{}
+	require.NoError(t, fakeClient.List(context.TODO(), backupSecrets, client.InNamespace(testBackupNamespace)), "error listing backup secrets")
+	assert.Empty(t, backupSecrets.Items, "expected credentials stored in the sink not to be backed up")
+}
+
 func testAzureCredentialsRequestWithOrphanedCloudResource(t *testing.T) *minterv1.CredentialsRequest {
 	cr := testAzureCredentialsRequestNeedingCleanup(t)
 	cr.Status.Conditions = append(cr.Status.Conditions, minterv1.CredentialsRequestCondition{
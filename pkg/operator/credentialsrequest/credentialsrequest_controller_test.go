@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"math/rand"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,6 +41,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -51,6 +55,7 @@ import (
 	"github.com/openshift/cloud-credential-operator/pkg/aws/actuator"
 	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
 	"github.com/openshift/cloud-credential-operator/pkg/operator/constants"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/metrics"
 	"github.com/openshift/cloud-credential-operator/pkg/operator/utils"
 	schemeutils "github.com/openshift/cloud-credential-operator/pkg/util"
 )
@@ -99,6 +104,15 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 		return nil
 	}
 
+	getBackupSecret := func(c client.Client) *corev1.Secret {
+		secret := &corev1.Secret{}
+		err := c.Get(context.TODO(), client.ObjectKey{Name: testSecretNamespace + "." + testSecretName, Namespace: testBackupNamespace}, secret)
+		if err == nil {
+			return secret
+		}
+		return nil
+	}
+
 	codec, err := minterv1.NewCodec()
 	if err != nil {
 		fmt.Printf("error creating codec: %v", err)
@@ -118,6 +132,14 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 		expectedConditions []ExpectedCondition
 		// Expected conditions on the credentials cluster operator:
 		expectedCOConditions []ExpectedCOCondition
+		// revalidationInterval enables periodic re-validation of recently synced credentials
+		revalidationInterval time.Duration
+		expectRevalidation   bool
+		expectCorrection     bool
+		// backupSecretNamespace enables mirroring of target secrets to the backup namespace
+		backupSecretNamespace string
+		// expectedEvents are the prefixes of the events expected to be recorded for the credentials request, in order
+		expectedEvents []string
 	}{
 		{
 			name: "add finalizer",
@@ -186,6 +208,9 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 				assert.Equal(t, int64(testCRGeneration), int64(cr.Status.LastSyncGeneration))
 				assert.NotNil(t, cr.Status.LastSyncTimestamp)
 			},
+			expectedEvents: []string{
+				"Normal CredentialsProvisioned Provisioned credentials into secret myproject/test-secret",
+			},
 		},
 		{
 			name: "new credential cluster has no infra name",
@@ -709,6 +734,9 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 				assert.True(t, cr.Status.Provisioned)
 				assert.Equal(t, "0002", cr.Status.LastSyncCloudCredsSecretResourceVersion)
 			},
+			expectedEvents: []string{
+				"Normal CredentialsRotated Rotated credentials within secret myproject/test-secret",
+			},
 		},
 		{
 			name: "existing passthrough credential bypassing simulations",
@@ -773,6 +801,109 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "minting paused",
+			existing: []runtime.Object{
+				testPausedOperatorConfig("true"),
+				createTestNamespace(testNamespace),
+				createTestNamespace(testSecretNamespace),
+				testCredentialsRequest(t),
+				testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey),
+				testAWSCredsSecret("openshift-cloud-credential-operator", "cloud-credential-operator-iam-ro-creds", testReadAWSAccessKeyID, testReadAWSSecretAccessKey),
+				testClusterVersion(),
+				testInfrastructure(testInfraName),
+			},
+			// No AWS calls are expected while minting is paused
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			mockReadAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			validate: func(c client.Client, t *testing.T) {
+				assert.Nil(t, getSecret(c), "expected no secret to be minted while paused")
+				cr := getCR(c)
+				assert.False(t, cr.Status.Provisioned)
+			},
+			expectedConditions: []ExpectedCondition{
+				{
+					conditionType: minterv1.Paused,
+					reason:        "CredentialMintingPaused",
+					status:        corev1.ConditionTrue,
+				},
+			},
+		},
+		{
+			name: "minting resumed",
+			existing: []runtime.Object{
+				testPausedOperatorConfig("false"),
+				createTestNamespace(testNamespace),
+				createTestNamespace(testSecretNamespace),
+				func() *minterv1.CredentialsRequest {
+					cr := testCredentialsRequest(t)
+					cr.Status.Conditions = []minterv1.CredentialsRequestCondition{
+						{
+							Type:   minterv1.Paused,
+							Status: corev1.ConditionTrue,
+							Reason: "CredentialMintingPaused",
+						},
+					}
+					return cr
+				}(),
+				testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey),
+				testAWSCredsSecret("openshift-cloud-credential-operator", "cloud-credential-operator-iam-ro-creds", testReadAWSAccessKeyID, testReadAWSSecretAccessKey),
+				testClusterVersion(),
+				testInfrastructure(testInfraName),
+			},
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUser(mockAWSClient)
+				mockCreateUser(mockAWSClient)
+				mockPutUserPolicy(mockAWSClient)
+				mockCreateAccessKey(mockAWSClient, testAWSAccessKeyID, testAWSSecretAccessKey)
+				mockTagUser(mockAWSClient)
+				return mockAWSClient
+			},
+			mockReadAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUserNotFound(mockAWSClient)
+				mockGetUserPolicyMissing(mockAWSClient)
+				mockListAccessKeysEmpty(mockAWSClient)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				targetSecret := getSecret(c)
+				require.NotNil(t, targetSecret)
+				assert.Equal(t, testAWSAccessKeyID, string(targetSecret.Data["aws_access_key_id"]))
+				cr := getCR(c)
+				assert.True(t, cr.Status.Provisioned)
+			},
+			expectedConditions: []ExpectedCondition{
+				{
+					conditionType: minterv1.Paused,
+					reason:        "CredentialMintingUnpaused",
+					status:        corev1.ConditionFalse,
+				},
+			},
+		},
+		{
+			name: "invalid pause minting annotation",
+			existing: []runtime.Object{
+				testPausedOperatorConfig("maybe"),
+				createTestNamespace(testNamespace),
+				createTestNamespace(testSecretNamespace),
+				testCredentialsRequest(t),
+				testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey),
+				testInfrastructure(testInfraName),
+			},
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				return mockaws.NewMockClient(mockCtrl)
+			},
+			expectErr: true,
+			validate: func(c client.Client, t *testing.T) {
+				assert.Nil(t, getSecret(c), "expected no secret to be minted with an invalid annotation")
+			},
+		},
 		{
 			name: "insufficient creds",
 			existing: []runtime.Object{
@@ -794,6 +925,9 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 					status:        corev1.ConditionTrue,
 				},
 			},
+			expectedEvents: []string{
+				"Warning CredentialsProvisionFailed Failed to provision credentials into secret myproject/test-secret (category InsufficientCloudCreds)",
+			},
 		},
 		{
 			name: "failed to mint condition",
@@ -823,6 +957,9 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 					status:        corev1.ConditionTrue,
 				},
 			},
+			expectedEvents: []string{
+				"Warning CredentialsProvisionFailed Failed to provision credentials into secret myproject/test-secret (category CredentialsProvisionFailure)",
+			},
 		},
 		{
 			name: "cred deletion failure condition",
@@ -852,6 +989,9 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 					status:        corev1.ConditionTrue,
 				},
 			},
+			expectedEvents: []string{
+				"Warning CredentialsDeprovisionFailed Failed to deprovision credentials (category Unknown)",
+			},
 		},
 		{
 			name: "skip AWS if recently synced",
@@ -938,6 +1078,205 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 				assert.NotEqual(t, testTwentyMinuteOldTimestamp.Unix(), cr.Status.LastSyncTimestamp.Time.Unix())
 			},
 		},
+		{
+			name:                 "skip re-validation if revalidation interval has not elapsed",
+			revalidationInterval: 30 * time.Minute,
+			existing: []runtime.Object{
+				testOperatorConfig(""),
+				createTestNamespace(testNamespace),
+				testInfrastructure(testInfraName),
+				createTestNamespace(testSecretNamespace),
+				testCredentialsRequestWithRecentLastSync(t),
+				testAWSCredsSecret(testSecretNamespace, testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+			},
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				cr := getCR(c)
+				assert.Equal(t, testTwentyMinuteOldTimestamp.Unix(), cr.Status.LastSyncTimestamp.Time.Unix())
+			},
+		},
+		{
+			name:                 "re-validate recently synced credentials",
+			revalidationInterval: 10 * time.Minute,
+			expectRevalidation:   true,
+			existing:             testRevalidationObjects(t),
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				return mockAWSClient
+			},
+			mockReadAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUser(mockAWSClient)
+				mockListAccessKeys(mockAWSClient, testAWSAccessKeyID)
+				mockGetUserPolicy(mockAWSClient, testPolicy1)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				cr := getCR(c)
+				assert.NotEqual(t, testTwentyMinuteOldTimestamp.Unix(), cr.Status.LastSyncTimestamp.Time.Unix())
+				targetSecret := getSecret(c)
+				require.NotNil(t, targetSecret)
+				assert.Equal(t, testAWSAccessKeyID, string(targetSecret.Data["aws_access_key_id"]))
+			},
+		},
+		{
+			name:                 "re-validation re-mints access key deleted from the cloud",
+			revalidationInterval: 10 * time.Minute,
+			expectRevalidation:   true,
+			expectCorrection:     true,
+			existing:             testRevalidationObjects(t),
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockCreateAccessKey(mockAWSClient, testAWSAccessKeyID2, testAWSSecretAccessKey2)
+				return mockAWSClient
+			},
+			mockReadAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUser(mockAWSClient)
+				mockListAccessKeysEmpty(mockAWSClient)
+				mockGetUserPolicy(mockAWSClient, testPolicy1)
+				mockListAccessKeysEmpty(mockAWSClient)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				targetSecret := getSecret(c)
+				require.NotNil(t, targetSecret)
+				assert.Equal(t, testAWSAccessKeyID2, string(targetSecret.Data["aws_access_key_id"]))
+				assert.Equal(t, testAWSSecretAccessKey2, string(targetSecret.Data["aws_secret_access_key"]))
+				cr := getCR(c)
+				assert.True(t, cr.Status.Provisioned)
+			},
+		},
+		{
+			name:                  "new credential mirrored to backup secret",
+			backupSecretNamespace: testBackupNamespace,
+			existing: []runtime.Object{
+				testOperatorConfig(""),
+				createTestNamespace(testNamespace),
+				createTestNamespace(testSecretNamespace),
+				createTestNamespace(testBackupNamespace),
+				testCredentialsRequest(t),
+				testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey),
+				testAWSCredsSecret("openshift-cloud-credential-operator", "cloud-credential-operator-iam-ro-creds", testReadAWSAccessKeyID, testReadAWSSecretAccessKey),
+				testClusterVersion(),
+				testInfrastructure(testInfraName),
+			},
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUser(mockAWSClient)
+				mockCreateUser(mockAWSClient)
+				mockPutUserPolicy(mockAWSClient)
+				mockCreateAccessKey(mockAWSClient, testAWSAccessKeyID, testAWSSecretAccessKey)
+				mockTagUser(mockAWSClient)
+				return mockAWSClient
+			},
+			mockReadAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUserNotFound(mockAWSClient)
+				mockGetUserPolicyMissing(mockAWSClient)
+				mockListAccessKeysEmpty(mockAWSClient)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				targetSecret := getSecret(c)
+				require.NotNil(t, targetSecret)
+				backupSecret := getBackupSecret(c)
+				require.NotNil(t, backupSecret, "expected backup secret to be created")
+				assert.Equal(t, targetSecret.Data, backupSecret.Data)
+				assert.Equal(t, fmt.Sprintf("%s/%s", testNamespace, testCRName), backupSecret.Annotations[minterv1.AnnotationCredentialsRequest])
+			},
+		},
+		{
+			name:                  "stale backup secret updated without syncing credential",
+			backupSecretNamespace: testBackupNamespace,
+			existing: []runtime.Object{
+				testOperatorConfig(""),
+				createTestNamespace(testNamespace),
+				testInfrastructure(testInfraName),
+				createTestNamespace(testSecretNamespace),
+				createTestNamespace(testBackupNamespace),
+				testCredentialsRequestWithRecentLastSync(t),
+				testAWSCredsSecret(testSecretNamespace, testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+				testAWSCredsSecret(testBackupNamespace, testSecretNamespace+"."+testSecretName, testAWSAccessKeyID2, testAWSSecretAccessKey2),
+			},
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				cr := getCR(c)
+				assert.Equal(t, testTwentyMinuteOldTimestamp.Unix(), cr.Status.LastSyncTimestamp.Time.Unix())
+				backupSecret := getBackupSecret(c)
+				require.NotNil(t, backupSecret)
+				assert.Equal(t, testAWSAccessKeyID, string(backupSecret.Data["aws_access_key_id"]))
+				assert.Equal(t, testAWSSecretAccessKey, string(backupSecret.Data["aws_secret_access_key"]))
+			},
+		},
+		{
+			name:                  "rotated credential updates backup secret",
+			revalidationInterval:  10 * time.Minute,
+			expectRevalidation:    true,
+			expectCorrection:      true,
+			backupSecretNamespace: testBackupNamespace,
+			existing: append(testRevalidationObjects(t),
+				createTestNamespace(testBackupNamespace),
+				testAWSCredsSecret(testBackupNamespace, testSecretNamespace+"."+testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+			),
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockCreateAccessKey(mockAWSClient, testAWSAccessKeyID2, testAWSSecretAccessKey2)
+				return mockAWSClient
+			},
+			mockReadAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockGetUser(mockAWSClient)
+				mockListAccessKeysEmpty(mockAWSClient)
+				mockGetUserPolicy(mockAWSClient, testPolicy1)
+				mockListAccessKeysEmpty(mockAWSClient)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				targetSecret := getSecret(c)
+				require.NotNil(t, targetSecret)
+				assert.Equal(t, testAWSAccessKeyID2, string(targetSecret.Data["aws_access_key_id"]))
+				backupSecret := getBackupSecret(c)
+				require.NotNil(t, backupSecret)
+				assert.Equal(t, testAWSAccessKeyID2, string(backupSecret.Data["aws_access_key_id"]))
+				assert.Equal(t, testAWSSecretAccessKey2, string(backupSecret.Data["aws_secret_access_key"]))
+			},
+		},
+		{
+			name:                  "cred deletion removes backup secret",
+			backupSecretNamespace: testBackupNamespace,
+			existing: []runtime.Object{
+				testOperatorConfig(""),
+				createTestNamespace(testNamespace),
+				createTestNamespace(testSecretNamespace),
+				createTestNamespace(testBackupNamespace),
+				testCredentialsRequestWithDeletionTimestamp(t),
+				testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey),
+				testAWSCredsSecret("openshift-cloud-credential-operator", "cloud-credential-operator-iam-ro-creds", testReadAWSAccessKeyID, testReadAWSSecretAccessKey),
+				testAWSCredsSecret(testSecretNamespace, testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+				testAWSCredsSecret(testBackupNamespace, testSecretNamespace+"."+testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+				testClusterVersion(),
+				testInfrastructure(testInfraName),
+			},
+			mockRootAWSClient: func(mockCtrl *gomock.Controller) *mockaws.MockClient {
+				mockAWSClient := mockaws.NewMockClient(mockCtrl)
+				mockListAccessKeys(mockAWSClient, testAWSAccessKeyID)
+				mockDeleteUser(mockAWSClient)
+				mockDeleteUserPolicy(mockAWSClient)
+				mockDeleteAccessKey(mockAWSClient, testAWSAccessKeyID)
+				return mockAWSClient
+			},
+			validate: func(c client.Client, t *testing.T) {
+				assert.Nil(t, getSecret(c))
+				assert.Nil(t, getBackupSecret(c), "expected backup secret to be deleted")
+			},
+		},
 		{
 			name: "skip nonAWS credreq",
 			existing: []runtime.Object{
@@ -1377,6 +1716,7 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 			fakeClient := fake.NewClientBuilder().
 				WithStatusSubresource(&minterv1.CredentialsRequest{}).
 				WithRuntimeObjects(test.existing...).Build()
+			eventRecorder := record.NewFakeRecorder(10)
 			rcr := &ReconcileCredentialsRequest{
 				Client: fakeClient,
 				Actuator: &actuator.AWSActuator{
@@ -1393,9 +1733,15 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 						}
 					},
 				},
-				platformType: configv1.AWSPlatformType,
+				platformType:          configv1.AWSPlatformType,
+				revalidationInterval:  test.revalidationInterval,
+				backupSecretNamespace: test.backupSecretNamespace,
+				eventRecorder:         newDeduplicatingRecorder(eventRecorder, eventDeduplicationInterval),
 			}
 
+			revalidations := counterValue(t, metrics.MetricCredentialsRequestRevalidations.WithLabelValues(string(configv1.AWSPlatformType)))
+			corrections := counterValue(t, metrics.MetricCredentialsRequestRevalidationCorrections.WithLabelValues(string(configv1.AWSPlatformType)))
+
 			_, err = rcr.Reconcile(context.TODO(), reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name:      testCRName,
@@ -1407,6 +1753,17 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 				test.validate(fakeClient, t)
 			}
 
+			if test.expectRevalidation {
+				assert.Equal(t, revalidations+1, counterValue(t, metrics.MetricCredentialsRequestRevalidations.WithLabelValues(string(configv1.AWSPlatformType))), "expected re-validation to be recorded")
+			} else {
+				assert.Equal(t, revalidations, counterValue(t, metrics.MetricCredentialsRequestRevalidations.WithLabelValues(string(configv1.AWSPlatformType))), "unexpected re-validation recorded")
+			}
+			if test.expectCorrection {
+				assert.Equal(t, corrections+1, counterValue(t, metrics.MetricCredentialsRequestRevalidationCorrections.WithLabelValues(string(configv1.AWSPlatformType))), "expected correction to be recorded")
+			} else {
+				assert.Equal(t, corrections, counterValue(t, metrics.MetricCredentialsRequestRevalidationCorrections.WithLabelValues(string(configv1.AWSPlatformType))), "unexpected correction recorded")
+			}
+
 			if err != nil && !test.expectErr {
 				require.NoError(t, err, "Unexpected error: %v", err)
 			}
@@ -1414,6 +1771,18 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 				t.Errorf("Expected error but got none")
 			}
 
+			close(eventRecorder.Events)
+			events := []string{}
+			for event := range eventRecorder.Events {
+				events = append(events, event)
+			}
+			if test.expectedEvents != nil {
+				require.Len(t, events, len(test.expectedEvents), "unexpected events recorded: %v", events)
+				for i, expectedEvent := range test.expectedEvents {
+					assert.True(t, strings.HasPrefix(events[i], expectedEvent), "expected event %q, got %q", expectedEvent, events[i])
+				}
+			}
+
 			cr := getCR(fakeClient)
 			for _, condition := range test.expectedConditions {
 				foundCondition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, condition.conditionType)
@@ -1440,6 +1809,18 @@ func TestCredentialsRequestReconcile(t *testing.T) {
 	}
 }
 
+func TestBackupSecretName(t *testing.T) {
+	backupSecretNameOf := func(namespace, name string) string {
+		cr := &minterv1.CredentialsRequest{}
+		cr.Spec.SecretRef = corev1.ObjectReference{Namespace: namespace, Name: name}
+		return backupSecretName(cr)
+	}
+
+	assert.Equal(t, testSecretNamespace+"."+testSecretName, backupSecretNameOf(testSecretNamespace, testSecretName))
+	assert.NotEqual(t, backupSecretNameOf("a-b", "c"), backupSecretNameOf("a", "b-c"),
+		"expected the target secrets a-b/c and a/b-c to be backed up to distinct secrets")
+}
+
 const (
 	testCRGeneration                  = 1 // just non-zero
 	testCredRootSecretResourceVersion = "123"
@@ -1450,6 +1831,7 @@ const (
 	testInfraName                     = "testcluster-abc123"
 	testSecretName                    = "test-secret"
 	testSecretNamespace               = "myproject"
+	testBackupNamespace               = "credentials-backup"
 	testAWSUser                       = "mycluster-test-aws-user"
 	testAWSARN                        = "arn:aws:iam::1234:user/testuser"
 	testAWSUserID                     = "FAKEAWSUSERID"
@@ -1490,6 +1872,34 @@ func testCredentialsRequestWithRecentLastSync(t *testing.T) *minterv1.Credential
 	return cr
 }
 
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, counter.Write(m), "failed to read counter")
+	return m.GetCounter().GetValue()
+}
+
+// testRevalidationObjects returns the objects for a recently synced credentials request which would
+// not otherwise be synced again until the syncPeriod has elapsed
+func testRevalidationObjects(t *testing.T) []runtime.Object {
+	cr := testCredentialsRequestWithRecentLastSync(t)
+	cr.Status.LastSyncCloudCredsSecretResourceVersion = testCredRootSecretResourceVersion
+
+	rootSecret := testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey)
+	rootSecret.ResourceVersion = testCredRootSecretResourceVersion
+
+	return []runtime.Object{
+		testOperatorConfig(""),
+		createTestNamespace(testNamespace),
+		createTestNamespace(testSecretNamespace),
+		cr,
+		rootSecret,
+		testAWSCredsSecret("openshift-cloud-credential-operator", "cloud-credential-operator-iam-ro-creds", testReadAWSAccessKeyID, testReadAWSSecretAccessKey),
+		testAWSCredsSecret(testSecretNamespace, testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+		testClusterVersion(),
+		testInfrastructure(testInfraName),
+	}
+}
+
 func testCredentialsRequestWithDeletionTimestamp(t *testing.T) *minterv1.CredentialsRequest {
 	cr := testCredentialsRequest(t)
 	now := metav1.Now()
@@ -1893,6 +2303,14 @@ func testOperatorConfigMap(disabled string) *corev1.ConfigMap {
 	}
 }
 
+func testPausedOperatorConfig(paused string) *operatorv1.CloudCredential {
+	conf := testOperatorConfig("")
+	conf.Annotations = map[string]string{
+		constants.PauseMintingAnnotation: paused,
+	}
+	return conf
+}
+
 func testOperatorConfig(mode operatorv1.CloudCredentialsMode) *operatorv1.CloudCredential {
 	conf := &operatorv1.CloudCredential{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,142 @@
+package credentialsrequest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/constants"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/utils"
+)
+
+const (
+	dryRunChangesPending = "DryRunChangesPending"
+	dryRunNoChanges      = "DryRunNoChanges"
+	dryRunDisabled       = "DryRunDisabled"
+
+	eventReasonDryRun = "DryRun"
+)
+
+// isDryRun returns whether an admin has requested a dry run of cr with the dry-run annotation
+func isDryRun(cr *minterv1.CredentialsRequest) bool {
+	return cr.Annotations[constants.DryRunAnnotation] == "true"
+}
+
+// dryRunChanges returns the changes which syncing cr would make, in the order they would be made. The changes are
+// determined from the state of the cluster and the status of cr alone, no cloud API is called, so the credentials
+// are reported as synced whenever the controller would sync them, even when the cloud already matches the request.
+func (r *ReconcileCredentialsRequest) dryRunChanges(ctx context.Context, cr *minterv1.CredentialsRequest, logger log.FieldLogger) ([]string, error) {
+	secretRef := fmt.Sprintf("%s/%s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+	if cr.DeletionTimestamp != nil {
+		if !HasFinalizer(cr, minterv1.FinalizerDeprovision) {
+			return []string{}, nil
+		}
+		return []string{
+			"deprovision the cloud credentials",
+			fmt.Sprintf("delete secret %s", secretRef),
+			fmt.Sprintf("remove finalizer %s", minterv1.FinalizerDeprovision),
+		}, nil
+	}
+
+	changes := []string{}
+	if !HasFinalizer(cr, minterv1.FinalizerDeprovision) {
+		changes = append(changes, fmt.Sprintf("add finalizer %s", minterv1.FinalizerDeprovision))
+	}
+
+	paused, err := utils.IsCredentialMintingPaused(r.Client, logger)
+	if err != nil {
+		return nil, err
+	}
+	if paused {
+		return changes, nil
+	}
+	conflicting, err := r.conflictingCredentialsRequests(ctx, cr, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicting) > 0 {
+		return changes, nil
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: cr.Spec.SecretRef.Namespace}, &corev1.Namespace{}); err != nil {
+		if errors.IsNotFound(err) {
+			return changes, nil
+		}
+		return nil, err
+	}
+
+	secretExists := true
+	if err := r.getTargetSecret(ctx, cr, &corev1.Secret{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		secretExists = false
+	}
+	credentialsRootSecret, err := r.Actuator.GetCredentialsRootSecret(ctx, cr)
+	if err != nil {
+		logger.WithError(err).Debug("error retrieving cloud credentials secret")
+	}
+
+	switch {
+	case !cr.Status.Provisioned:
+		changes = append(changes, "provision the cloud credentials")
+	case cr.Generation != cr.Status.LastSyncGeneration:
+		changes = append(changes, fmt.Sprintf("update the cloud credentials to generation %d of the CredentialsRequest, generation %d was last synced",
+			cr.Generation, cr.Status.LastSyncGeneration))
+	case credentialsRootSecret != nil && credentialsRootSecret.ResourceVersion != cr.Status.LastSyncCloudCredsSecretResourceVersion:
+		changes = append(changes, "sync the cloud credentials as the root credentials changed")
+	case checkForFailureConditions(cr):
+		changes = append(changes, "retry syncing the cloud credentials, which last failed")
+	case !secretExists:
+		changes = append(changes, "sync the cloud credentials as the secret is missing")
+	case cr.Status.LastSyncTimestamp == nil || !cr.Status.LastSyncTimestamp.Add(syncPeriod).After(time.Now()):
+		changes = append(changes, "re-validate the cloud credentials, which were last synced more than an hour ago")
+	default:
+		return changes, nil
+	}
+	if secretExists {
+		changes = append(changes, fmt.Sprintf("update secret %s", secretRef))
+	} else {
+		changes = append(changes, fmt.Sprintf("create secret %s", secretRef))
+	}
+	return changes, nil
+}
+
+// dryRunMessage describes the changes which syncing cr would make
+func dryRunMessage(changes []string) string {
+	if len(changes) == 0 {
+		return "dry run: no changes would be made"
+	}
+	return fmt.Sprintf("dry run: would %s", strings.Join(changes, ", then "))
+}
+
+func setDryRunCondition(cr *minterv1.CredentialsRequest, dryRun bool, changes []string) {
+	var (
+		msg, reason string
+		status      corev1.ConditionStatus
+		updateCheck utils.UpdateConditionCheck
+	)
+	if dryRun {
+		msg = dryRunMessage(changes)
+		status = corev1.ConditionTrue
+		reason = dryRunNoChanges
+		if len(changes) > 0 {
+			reason = dryRunChangesPending
+		}
+		updateCheck = utils.UpdateConditionIfReasonOrMessageChange
+	} else {
+		msg = fmt.Sprintf("no dry run is requested by the %s annotation", constants.DryRunAnnotation)
+		status = corev1.ConditionFalse
+		reason = dryRunDisabled
+		updateCheck = utils.UpdateConditionNever
+	}
+	cr.Status.Conditions = utils.SetCredentialsRequestCondition(cr.Status.Conditions, minterv1.DryRun,
+		status, reason, msg, updateCheck)
+}
@@ -0,0 +1,171 @@
+package credentialsrequest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	minteraws "github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/aws/actuator"
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/constants"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/utils"
+	schemeutils "github.com/openshift/cloud-credential-operator/pkg/util"
+)
+
+func TestCredentialsRequestDryRun(t *testing.T) {
+	schemeutils.SetupScheme(scheme.Scheme)
+
+	codec, err := minterv1.NewCodec()
+	require.NoError(t, err, "error creating codec")
+
+	tests := []struct {
+		name               string
+		credentialsRequest func(t *testing.T) *minterv1.CredentialsRequest
+		existingSecret     bool
+		expectedReason     string
+		expectedMessage    string
+	}{
+		{
+			name:               "new credentials request",
+			credentialsRequest: testCredentialsRequest,
+			expectedReason:     dryRunChangesPending,
+			expectedMessage:    "dry run: would provision the cloud credentials, then create secret myproject/test-secret",
+		},
+		{
+			name: "spec changed since the last sync",
+			credentialsRequest: func(t *testing.T) *minterv1.CredentialsRequest {
+				cr := testCredentialsRequestWithRecentLastSync(t)
+				cr.Status.LastSyncGeneration = cr.Generation - 1
+				return cr
+			},
+			existingSecret:  true,
+			expectedReason:  dryRunChangesPending,
+			expectedMessage: "dry run: would update the cloud credentials to generation 1 of the CredentialsRequest, generation 0 was last synced, then update secret myproject/test-secret",
+		},
+		{
+			name: "recently synced",
+			credentialsRequest: func(t *testing.T) *minterv1.CredentialsRequest {
+				cr := testCredentialsRequestWithRecentLastSync(t)
+				cr.Status.LastSyncCloudCredsSecretResourceVersion = testCredRootSecretResourceVersion
+				return cr
+			},
+			existingSecret:  true,
+			expectedReason:  dryRunNoChanges,
+			expectedMessage: "dry run: no changes would be made",
+		},
+		{
+			name: "being deleted",
+			credentialsRequest: func(t *testing.T) *minterv1.CredentialsRequest {
+				cr := testProvisionedCredentialsRequest(t)
+				now := metav1.Now()
+				cr.DeletionTimestamp = &now
+				return cr
+			},
+			existingSecret:  true,
+			expectedReason:  dryRunChangesPending,
+			expectedMessage: "dry run: would deprovision the cloud credentials, then delete secret myproject/test-secret, then remove finalizer " + minterv1.FinalizerDeprovision,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := test.credentialsRequest(t)
+			cr.Annotations[constants.DryRunAnnotation] = "true"
+			rootSecret := testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey)
+			rootSecret.ResourceVersion = testCredRootSecretResourceVersion
+			objects := []runtime.Object{
+				testOperatorConfig(""),
+				createTestNamespace(testNamespace),
+				createTestNamespace(testSecretNamespace),
+				cr,
+				rootSecret,
+				testClusterVersion(),
+				testInfrastructure(testInfraName),
+			}
+			if test.existingSecret {
+				objects = append(objects, testAWSCredsSecret(testSecretNamespace, testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey))
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithStatusSubresource(&minterv1.CredentialsRequest{}).
+				WithRuntimeObjects(objects...).Build()
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			// No AWS calls are expected during a dry run
+			mockAWSClient := mockaws.NewMockClient(mockCtrl)
+			eventRecorder := record.NewFakeRecorder(10)
+			rcr := &ReconcileCredentialsRequest{
+				Client: fakeClient,
+				Actuator: &actuator.AWSActuator{
+					Client: fakeClient,
+					Codec:  codec,
+					Scheme: scheme.Scheme,
+					AWSClientBuilder: func(accessKeyID, secretAccessKey []byte, c client.Client) (minteraws.Client, error) {
+						return mockAWSClient, nil
+					},
+				},
+				platformType:  configv1.AWSPlatformType,
+				eventRecorder: newDeduplicatingRecorder(eventRecorder, eventDeduplicationInterval),
+			}
+
+			_, err := rcr.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: testCRName, Namespace: testNamespace},
+			})
+			require.NoError(t, err, "unexpected error reconciling")
+
+			updated := &minterv1.CredentialsRequest{}
+			require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testCRName, Namespace: testNamespace}, updated))
+			condition := utils.FindCredentialsRequestCondition(updated.Status.Conditions, minterv1.DryRun)
+			require.NotNil(t, condition, "expected a DryRun condition")
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+			assert.Equal(t, test.expectedReason, condition.Reason)
+			assert.Equal(t, test.expectedMessage, condition.Message)
+			assert.Equal(t, cr.Status.Provisioned, updated.Status.Provisioned, "expected the provisioned status to be left untouched")
+			assert.Equal(t, cr.Finalizers, updated.Finalizers, "expected the finalizers to be left untouched")
+
+			secret := &corev1.Secret{}
+			err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: testSecretName, Namespace: testSecretNamespace}, secret)
+			if test.existingSecret {
+				require.NoError(t, err, "expected the secret to be left untouched")
+				assert.Equal(t, testAWSAccessKeyID, string(secret.Data["aws_access_key_id"]), "expected the secret to be left untouched")
+			} else {
+				assert.True(t, errors.IsNotFound(err), "expected no secret to be created")
+			}
+
+			require.Len(t, eventRecorder.Events, 1, "expected an event to be recorded")
+			assert.Equal(t, "Normal DryRun "+test.expectedMessage, <-eventRecorder.Events)
+		})
+	}
+}
+
+func TestSetDryRunCondition(t *testing.T) {
+	cr := &minterv1.CredentialsRequest{}
+	setDryRunCondition(cr, false, nil)
+	assert.Empty(t, cr.Status.Conditions, "expected no condition unless a dry run was requested")
+
+	setDryRunCondition(cr, true, []string{"provision the cloud credentials"})
+	setDryRunCondition(cr, false, nil)
+	condition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, minterv1.DryRun)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, dryRunDisabled, condition.Reason)
+}
@@ -0,0 +1,152 @@
+package credentialsrequest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/credentialsrequest/actuator"
+)
+
+const (
+	eventReasonCredentialsProvisioned       = "CredentialsProvisioned"
+	eventReasonCredentialsRotated           = "CredentialsRotated"
+	eventReasonCredentialsProvisionFailed   = "CredentialsProvisionFailed"
+	eventReasonCredentialsDeprovisionFailed = "CredentialsDeprovisionFailed"
+
+	// unknownCloudErrorCategory is the category of errors which the actuator did not classify
+	unknownCloudErrorCategory = "Unknown"
+)
+
+// eventDeduplicationInterval is the interval during which an event identical to one already recorded for a
+// CredentialsRequest is dropped, so that a CredentialsRequest failing on every retry doesn't flood the API with events
+var eventDeduplicationInterval = 30 * time.Minute
+
+// eventKey identifies identical events of an object
+type eventKey struct {
+	uid       types.UID
+	eventType string
+	reason    string
+	message   string
+}
+
+// deduplicatingRecorder is a record.EventRecorder which drops events identical to an event recorded for the same
+// object within the last interval
+type deduplicatingRecorder struct {
+	recorder record.EventRecorder
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	recorded map[eventKey]time.Time
+}
+
+var _ record.EventRecorder = &deduplicatingRecorder{}
+
+// newDeduplicatingRecorder returns a record.EventRecorder recording events with recorder, dropping events identical
+// to an event recorded for the same object within the last interval
+func newDeduplicatingRecorder(recorder record.EventRecorder, interval time.Duration) *deduplicatingRecorder {
+	return &deduplicatingRecorder{
+		recorder: recorder,
+		interval: interval,
+		now:      time.Now,
+		recorded: map[eventKey]time.Time{},
+	}
+}
+
+// shouldRecord returns whether an event identified by eventType, reason and message should be recorded for object,
+// remembering when it was so that identical events are dropped for the following interval
+func (r *deduplicatingRecorder) shouldRecord(object runtime.Object, eventType, reason, message string) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		// Objects without metadata can't be deduplicated
+		return true
+	}
+	key := eventKey{uid: accessor.GetUID(), eventType: eventType, reason: reason, message: message}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	for k, recordedAt := range r.recorded {
+		if now.Sub(recordedAt) >= r.interval {
+			delete(r.recorded, k)
+		}
+	}
+	if _, found := r.recorded[key]; found {
+		return false
+	}
+	r.recorded[key] = now
+	return true
+}
+
+func (r *deduplicatingRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if r.shouldRecord(object, eventType, reason, message) {
+		r.recorder.Event(object, eventType, reason, message)
+	}
+}
+
+func (r *deduplicatingRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *deduplicatingRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if r.shouldRecord(object, eventType, reason, message) {
+		r.recorder.AnnotatedEventf(object, annotations, eventType, reason, "%s", message)
+	}
+}
+
+// cloudErrorCategory returns the category into which the actuator classified err, eg. InsufficientCloudCredentials
+func cloudErrorCategory(err error) string {
+	if status, ok := err.(actuator.ActuatorStatus); ok {
+		return string(status.Reason())
+	}
+	return unknownCloudErrorCategory
+}
+
+// recordEvent records an event for cr, when the reconciler has an event recorder
+func (r *ReconcileCredentialsRequest) recordEvent(cr *minterv1.CredentialsRequest, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.eventRecorder == nil {
+		return
+	}
+	r.eventRecorder.Eventf(cr, eventType, reason, messageFmt, args...)
+}
+
+// recordSyncEvents records the outcome of syncing the credentials of cr as an event: the failure to provision the
+// credentials, their rotation, which is detected by the data of the target secret having changed from that of
+// origSecret, or their provisioning when the target secret did not exist or cr was not yet provisioned. Syncs which
+// left already provisioned credentials untouched are not recorded.
+func (r *ReconcileCredentialsRequest) recordSyncEvents(ctx context.Context, cr *minterv1.CredentialsRequest, wasProvisioned bool, origSecret *corev1.Secret, syncErr error, provisionErr bool, logger log.FieldLogger) {
+	secretRef := fmt.Sprintf("%s/%s", cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name)
+	if provisionErr {
+		r.recordEvent(cr, corev1.EventTypeWarning, eventReasonCredentialsProvisionFailed,
+			"Failed to provision credentials into secret %s (category %s): %v", secretRef, cloudErrorCategory(syncErr), syncErr)
+		return
+	}
+	if origSecret == nil {
+		r.recordEvent(cr, corev1.EventTypeNormal, eventReasonCredentialsProvisioned, "Provisioned credentials into secret %s", secretRef)
+		return
+	}
+	secret := &corev1.Secret{}
+	if err := r.getTargetSecret(ctx, cr, secret); err != nil {
+		logger.WithError(err).Warn("unable to determine whether the provisioned credentials were rotated")
+		return
+	}
+	switch {
+	case !reflect.DeepEqual(secret.Data, origSecret.Data):
+		r.recordEvent(cr, corev1.EventTypeNormal, eventReasonCredentialsRotated, "Rotated credentials within secret %s", secretRef)
+	case !wasProvisioned:
+		r.recordEvent(cr, corev1.EventTypeNormal, eventReasonCredentialsProvisioned, "Provisioned credentials into secret %s", secretRef)
+	}
+}
@@ -0,0 +1,43 @@
+package credentialsrequest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+func TestDeduplicatingRecorder(t *testing.T) {
+	crA := &minterv1.CredentialsRequest{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "uid-a"}}
+	crB := &minterv1.CredentialsRequest{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "uid-b"}}
+
+	fakeRecorder := record.NewFakeRecorder(10)
+	recorder := newDeduplicatingRecorder(fakeRecorder, time.Minute)
+	now := time.Now()
+	recorder.now = func() time.Time { return now }
+
+	recorder.Eventf(crA, corev1.EventTypeWarning, eventReasonCredentialsProvisionFailed, "failed: %s", "throttled")
+	recorder.Eventf(crA, corev1.EventTypeWarning, eventReasonCredentialsProvisionFailed, "failed: %s", "throttled")
+	recorder.Eventf(crB, corev1.EventTypeWarning, eventReasonCredentialsProvisionFailed, "failed: %s", "throttled")
+	recorder.Eventf(crA, corev1.EventTypeWarning, eventReasonCredentialsProvisionFailed, "failed: %s", "access denied")
+	now = now.Add(time.Minute)
+	recorder.Eventf(crA, corev1.EventTypeWarning, eventReasonCredentialsProvisionFailed, "failed: %s", "throttled")
+
+	close(fakeRecorder.Events)
+	events := []string{}
+	for event := range fakeRecorder.Events {
+		events = append(events, event)
+	}
+	assert.Equal(t, []string{
+		"Warning CredentialsProvisionFailed failed: throttled",
+		"Warning CredentialsProvisionFailed failed: throttled",
+		"Warning CredentialsProvisionFailed failed: access denied",
+		"Warning CredentialsProvisionFailed failed: throttled",
+	}, events, "expected identical events of an object to be dropped within the interval")
+}
@@ -0,0 +1,116 @@
+package credentialsrequest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/utils"
+)
+
+const (
+	secretRefConflict   = "SecretRefConflict"
+	noSecretRefConflict = "NoSecretRefConflict"
+
+	eventReasonSecretRefConflict = "SecretRefConflict"
+)
+
+// conflictingCredentialsRequests returns the names, as namespace/name, of the other CredentialsRequests for the
+// cluster's platform which target the same secret as cr, ordered by name. CredentialsRequests being deleted are not
+// considered, as they no longer sync the secret.
+func (r *ReconcileCredentialsRequest) conflictingCredentialsRequests(ctx context.Context, cr *minterv1.CredentialsRequest, logger log.FieldLogger) ([]string, error) {
+	crs := &minterv1.CredentialsRequestList{}
+	if err := r.List(ctx, crs); err != nil {
+		return nil, fmt.Errorf("error listing CredentialsRequests: %v", err)
+	}
+	conflicting := []string{}
+	for i := range crs.Items {
+		other := &crs.Items[i]
+		if other.UID == cr.UID || (other.Namespace == cr.Namespace && other.Name == cr.Name) {
+			continue
+		}
+		if other.DeletionTimestamp != nil || !sameSecretRef(other, cr) {
+			continue
+		}
+		infraMatch, err := crInfraMatches(other, r.platformType)
+		if err != nil {
+			logger.WithError(err).WithField("other", fmt.Sprintf("%s/%s", other.Namespace, other.Name)).Warn("failed to determine cloud platform type of CredentialsRequest targeting the same secret")
+		}
+		if !infraMatch {
+			continue
+		}
+		conflicting = append(conflicting, fmt.Sprintf("%s/%s", other.Namespace, other.Name))
+	}
+	sort.Strings(conflicting)
+	return conflicting, nil
+}
+
+// sameSecretRef returns whether a and b target the same secret
+func sameSecretRef(a, b *minterv1.CredentialsRequest) bool {
+	return a.Spec.SecretRef.Namespace == b.Spec.SecretRef.Namespace && a.Spec.SecretRef.Name == b.Spec.SecretRef.Name
+}
+
+func setConflictCondition(cr *minterv1.CredentialsRequest, conflicting []string) {
+	var (
+		msg, reason string
+		status      corev1.ConditionStatus
+		updateCheck utils.UpdateConditionCheck
+	)
+	if len(conflicting) > 0 {
+		msg = fmt.Sprintf("secret %s/%s is also targeted by CredentialsRequests %s, credentials are not minted until only one CredentialsRequest targets the secret",
+			cr.Spec.SecretRef.Namespace, cr.Spec.SecretRef.Name, strings.Join(conflicting, ", "))
+		status = corev1.ConditionTrue
+		reason = secretRefConflict
+		updateCheck = utils.UpdateConditionIfReasonOrMessageChange
+	} else {
+		msg = "no other CredentialsRequest targets the secret"
+		status = corev1.ConditionFalse
+		reason = noSecretRefConflict
+		updateCheck = utils.UpdateConditionNever
+	}
+	cr.Status.Conditions = utils.SetCredentialsRequestCondition(cr.Status.Conditions, minterv1.Conflict,
+		status, reason, msg, updateCheck)
+}
+
+// conflictMapFunc returns the requests to reconcile the CredentialsRequests affected by a change to the
+// CredentialsRequest a: those targeting the same secret, which may now conflict with it, and those reporting a
+// conflict, which may have been resolved by it changing its secretRef or being deleted.
+func conflictMapFunc(c client.Client) func(ctx context.Context, a client.Object) []reconcile.Request {
+	return func(ctx context.Context, a client.Object) []reconcile.Request {
+		changed, ok := a.(*minterv1.CredentialsRequest)
+		if !ok {
+			return nil
+		}
+		crs := &minterv1.CredentialsRequestList{}
+		if err := c.List(ctx, crs); err != nil {
+			log.WithError(err).Error("error listing CredentialsRequests for secretRef conflicts")
+			return nil
+		}
+		requests := []reconcile.Request{}
+		for _, cr := range crs.Items {
+			if cr.Namespace == changed.Namespace && cr.Name == changed.Name {
+				continue
+			}
+			conflictCondition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, minterv1.Conflict)
+			reportsConflict := conflictCondition != nil && conflictCondition.Status == corev1.ConditionTrue
+			if sameSecretRef(&cr, changed) || reportsConflict {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      cr.Name,
+						Namespace: cr.Namespace,
+					},
+				})
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,136 @@
+package credentialsrequest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	minterv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	minteraws "github.com/openshift/cloud-credential-operator/pkg/aws"
+	"github.com/openshift/cloud-credential-operator/pkg/aws/actuator"
+	mockaws "github.com/openshift/cloud-credential-operator/pkg/aws/mock"
+	"github.com/openshift/cloud-credential-operator/pkg/operator/utils"
+	schemeutils "github.com/openshift/cloud-credential-operator/pkg/util"
+)
+
+const testConflictingCRName = "openshift-component-b"
+
+func TestCredentialsRequestSecretRefConflict(t *testing.T) {
+	schemeutils.SetupScheme(scheme.Scheme)
+
+	codec, err := minterv1.NewCodec()
+	require.NoError(t, err, "error creating codec")
+
+	// The first CredentialsRequest was provisioned before the second, targeting the same secret, was created
+	first := testProvisionedCredentialsRequest(t)
+	second := testCredentialsRequest(t)
+	second.Name = testConflictingCRName
+	second.UID = types.UID("second-uid")
+
+	fakeClient := fake.NewClientBuilder().
+		WithStatusSubresource(&minterv1.CredentialsRequest{}).
+		WithRuntimeObjects(
+			testOperatorConfig(""),
+			createTestNamespace(testNamespace),
+			createTestNamespace(testSecretNamespace),
+			first,
+			second,
+			testAWSCredsSecret("kube-system", "aws-creds", testRootAWSAccessKeyID, testRootAWSSecretAccessKey),
+			testAWSCredsSecret(testSecretNamespace, testSecretName, testAWSAccessKeyID, testAWSSecretAccessKey),
+			testClusterVersion(),
+			testInfrastructure(testInfraName),
+		).Build()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	// No AWS calls are expected while the CredentialsRequests conflict
+	mockAWSClient := mockaws.NewMockClient(mockCtrl)
+	eventRecorder := record.NewFakeRecorder(10)
+	rcr := &ReconcileCredentialsRequest{
+		Client: fakeClient,
+		Actuator: &actuator.AWSActuator{
+			Client: fakeClient,
+			Codec:  codec,
+			Scheme: scheme.Scheme,
+			AWSClientBuilder: func(accessKeyID, secretAccessKey []byte, c client.Client) (minteraws.Client, error) {
+				return mockAWSClient, nil
+			},
+		},
+		platformType:  configv1.AWSPlatformType,
+		eventRecorder: newDeduplicatingRecorder(eventRecorder, eventDeduplicationInterval),
+	}
+
+	for _, name := range []string{testCRName, testConflictingCRName} {
+		_, err := rcr.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: testNamespace},
+		})
+		require.NoError(t, err, "unexpected error reconciling %s", name)
+	}
+
+	// Neither CredentialsRequest clobbered the secret
+	secret := &corev1.Secret{}
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testSecretName, Namespace: testSecretNamespace}, secret))
+	assert.Equal(t, testAWSAccessKeyID, string(secret.Data["aws_access_key_id"]), "expected the secret to be left untouched")
+	assert.Equal(t, testAWSSecretAccessKey, string(secret.Data["aws_secret_access_key"]), "expected the secret to be left untouched")
+
+	for name, other := range map[string]string{testCRName: testConflictingCRName, testConflictingCRName: testCRName} {
+		cr := &minterv1.CredentialsRequest{}
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: testNamespace}, cr))
+		condition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, minterv1.Conflict)
+		require.NotNil(t, condition, "expected a Conflict condition on %s", name)
+		assert.Equal(t, corev1.ConditionTrue, condition.Status)
+		assert.Equal(t, secretRefConflict, condition.Reason)
+		assert.Contains(t, condition.Message, testNamespace+"/"+other, "expected the condition of %s to name the conflicting CredentialsRequest", name)
+	}
+	first = &minterv1.CredentialsRequest{}
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testCRName, Namespace: testNamespace}, first))
+	assert.True(t, first.Status.Provisioned, "expected the provisioned status to be left untouched")
+
+	close(eventRecorder.Events)
+	events := []string{}
+	for event := range eventRecorder.Events {
+		events = append(events, event)
+	}
+	require.Len(t, events, 2, "unexpected events recorded: %v", events)
+	for _, event := range events {
+		assert.True(t, strings.HasPrefix(event, "Warning SecretRefConflict Secret myproject/test-secret is also targeted by CredentialsRequests"), "unexpected event %q", event)
+	}
+
+	// Changes to either CredentialsRequest reconcile the other
+	requests := conflictMapFunc(fakeClient)(context.TODO(), second)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: testCRName, Namespace: testNamespace}}}, requests)
+
+	// Once the second CredentialsRequest targets another secret, the conflict is resolved
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: testConflictingCRName, Namespace: testNamespace}, second))
+	second.Spec.SecretRef.Name = "other-secret"
+	require.NoError(t, fakeClient.Update(context.TODO(), second))
+	requests = conflictMapFunc(fakeClient)(context.TODO(), second)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: testCRName, Namespace: testNamespace}}}, requests,
+		"expected the CredentialsRequest reporting a conflict to be reconciled")
+	conflicting, err := rcr.conflictingCredentialsRequests(context.TODO(), first, log.WithField("test", t.Name()))
+	require.NoError(t, err)
+	assert.Empty(t, conflicting)
+
+	cr := first.DeepCopy()
+	setConflictCondition(cr, conflicting)
+	condition := utils.FindCredentialsRequestCondition(cr.Status.Conditions, minterv1.Conflict)
+	require.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, noSecretRefConflict, condition.Reason)
+}
@@ -59,6 +59,20 @@ var (
 		},
 		[]string{"controller"},
 	)
+
+	// MetricCredentialsRequestRevalidations tracks the periodic re-validations of provisioned credentials
+	// performed by the credentialsrequest controller when cloud re-validation is enabled.
+	MetricCredentialsRequestRevalidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cco_credentials_requests_revalidations_total",
+		Help: "Total number of periodic re-validations of provisioned credentials against the cloud.",
+	}, []string{"cloud_type"})
+
+	// MetricCredentialsRequestRevalidationCorrections tracks the periodic re-validations which found the
+	// provisioned credentials no longer valid in the cloud and had to re-mint or re-assert them.
+	MetricCredentialsRequestRevalidationCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cco_credentials_requests_revalidation_corrections_total",
+		Help: "Total number of periodic re-validations which corrected provisioned credentials.",
+	}, []string{"cloud_type"})
 )
 
 func init() {
@@ -67,6 +81,8 @@ func init() {
 	metrics.Registry.MustRegister(metricCredentialsMode)
 
 	metrics.Registry.MustRegister(MetricControllerReconcileTime)
+	metrics.Registry.MustRegister(MetricCredentialsRequestRevalidations)
+	metrics.Registry.MustRegister(MetricCredentialsRequestRevalidationCorrections)
 }
 
 // Add creates a new metrics Calculator and adds it to the Manager.
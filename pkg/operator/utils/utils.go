@@ -250,6 +250,29 @@ func GetOperatorConfiguration(kubeClient client.Client, logger log.FieldLogger)
 	return
 }
 
+// IsCredentialMintingPaused returns whether an admin has paused minting and rotating credentials by annotating
+// the operator config with the PauseMintingAnnotation. A missing operator config does not pause credential minting,
+// while an annotation value which cannot be parsed is reported as an error so that credentials are not minted.
+func IsCredentialMintingPaused(kubeClient client.Client, logger log.FieldLogger) (bool, error) {
+	conf, err := getOperatorConfiguration(kubeClient, logger)
+	if err != nil {
+		if errors.IsNotFound(err) || metaerrors.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	value, ok := conf.Annotations[constants.PauseMintingAnnotation]
+	if !ok {
+		return false, nil
+	}
+	paused, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q of annotation %s, must be true or false", value, constants.PauseMintingAnnotation)
+	}
+	return paused, nil
+}
+
 func GetLogLevel(kubeClient client.Client, logger log.FieldLogger) (operatorv1.LogLevel, error) {
 	conf, err := getOperatorConfiguration(kubeClient, logger)
 	if err != nil {